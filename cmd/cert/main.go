@@ -6,21 +6,41 @@ import (
 	"os"
 
 	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
 
 	"github.com/nestjam/goph-keeper/internal/utils"
 )
 
 func main() {
 	const (
-		certFile = "servercert.crt"
-		keyfile  = "servercert.key"
+		certFile   = "servercert.crt"
+		keyfile    = "servercert.key"
+		caCertFile = "clientca.crt"
+		caKeyFile  = "clientca.key"
 	)
 
+	client := pflag.String("client", "", "common name of the client certificate to issue by the client CA")
+	pflag.Parse()
+
 	if !exists(certFile) || !exists(keyfile) {
 		if err := generate(certFile, keyfile); err != nil {
 			log.Fatal(err)
 		}
 	}
+
+	if *client == "" {
+		return
+	}
+
+	if !exists(caCertFile) || !exists(caKeyFile) {
+		if err := generateCA(caCertFile, caKeyFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if err := generateClient(caCertFile, caKeyFile, *client); err != nil {
+		log.Fatal(err)
+	}
 }
 
 func generate(certFile, keyfile string) error {
@@ -41,6 +61,53 @@ func generate(certFile, keyfile string) error {
 	return nil
 }
 
+func generateCA(certFile, keyfile string) error {
+	const op = "generate client ca"
+	cert, key, err := utils.GenerateCA()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	if err = writeFile(certFile, cert); err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	if err = writeFile(keyfile, key); err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// generateClient writes the client certificate and key to the files named after the common name.
+func generateClient(caCertFile, caKeyFile, commonName string) error {
+	const op = "generate client certificate"
+	caCert, err := os.ReadFile(caCertFile)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	caKey, err := os.ReadFile(caKeyFile)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	cert, key, err := utils.GenerateClientCert(caCert, caKey, commonName)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	if err = writeFile(commonName+".crt", cert); err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	if err = writeFile(commonName+".key", key); err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
 func writeFile(name string, data bytes.Buffer) error {
 	const perm os.FileMode = 0600
 	err := os.WriteFile(name, data.Bytes(), perm)
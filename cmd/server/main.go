@@ -4,13 +4,17 @@ import (
 	"context"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/nestjam/goph-keeper/internal/app/server"
 )
 
 func main() {
-	ctx := context.Background()
-	if err := server.NewApp().Run(ctx, os.Args); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := server.NewApp().Run(ctx, os.Args)
+	stop()
+	if err != nil {
 		log.Fatal(err)
 	}
 }
@@ -2,6 +2,9 @@ package client
 
 import (
 	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/go-resty/resty/v2"
@@ -11,6 +14,8 @@ import (
 	"github.com/nestjam/goph-keeper/internal/tui/auth"
 )
 
+var errInvalidCA = errors.New("ca file has no certificates")
+
 type app struct {
 }
 
@@ -26,14 +31,23 @@ func (a *app) Run(buildVersion, buildDate string, args []string) error {
 		return errors.Wrap(err, op)
 	}
 
+	tlsConfig, err := getTLSConfig(conf)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
 	client := resty.New()
+	client.SetTLSClientConfig(tlsConfig)
 
-	//nolint:gosec // using self-signed certificate
-	client.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
+	cacheDir, err := getCacheDir(conf.CacheDir)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
 
 	m := auth.NewLoginModel(conf.ServerAddress, client)
 	m.BuildDate = buildDate
 	m.BuildVersion = buildVersion
+	m.CacheDir = cacheDir
 
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
@@ -53,3 +67,50 @@ func getConfig(args []string) (*config.Config, error) {
 
 	return conf, nil
 }
+
+// getTLSConfig returns the config that presents the client certificate for mutual TLS if it is set.
+// The server certificate is verified with the CA if it is set, self-signed certificates are accepted otherwise.
+func getTLSConfig(conf *config.Config) (*tls.Config, error) {
+	const op = "get tls config"
+
+	//nolint:gosec // using self-signed certificate
+	tlsConfig := &tls.Config{InsecureSkipVerify: true}
+
+	if conf.CAFile != "" {
+		caPEM, err := os.ReadFile(conf.CAFile)
+		if err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(caPEM) {
+			return nil, errors.Wrap(errInvalidCA, op)
+		}
+		tlsConfig = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+	}
+
+	if conf.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(conf.CertFile, conf.KeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// getCacheDir returns the directory of the local caches, the caches are kept in the user cache directory by default.
+func getCacheDir(dir string) (string, error) {
+	const op = "get cache dir"
+
+	if dir != "" {
+		return dir, nil
+	}
+
+	userDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", errors.Wrap(err, op)
+	}
+
+	return filepath.Join(userDir, "goph-keeper"), nil
+}
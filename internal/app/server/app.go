@@ -3,20 +3,22 @@ package server
 import (
 	"bytes"
 	"context"
+	"io"
 	"os"
 
 	"github.com/pkg/errors"
 
 	"github.com/nestjam/goph-keeper/internal/config"
 	"github.com/nestjam/goph-keeper/internal/server"
-	"github.com/nestjam/goph-keeper/migration"
+	"github.com/nestjam/goph-keeper/internal/storage"
 )
 
 type app struct {
+	out io.Writer
 }
 
 func NewApp() *app {
-	return &app{}
+	return &app{out: os.Stdout}
 }
 
 func (a *app) Run(ctx context.Context, args []string) error {
@@ -27,10 +29,42 @@ func (a *app) Run(ctx context.Context, args []string) error {
 		return errors.Wrap(err, op)
 	}
 
-	migrator := migration.NewDatabaseMigrator(conf.Postgres.DataSourceName)
-	if err := migrator.Up(); err != nil {
+	migrator, err := storage.NewMigrator(conf)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	cmdArgs, err := config.Args(args)
+	if err != nil {
 		return errors.Wrap(err, op)
 	}
+	// the first argument is the program name
+	if len(cmdArgs) > 1 && cmdArgs[1] == migrateCommand {
+		if err := runMigrate(migrator, cmdArgs[2:], a.out); err != nil {
+			return errors.Wrap(err, op)
+		}
+		return nil
+	}
+
+	if !conf.NoMigrate {
+		if err := migrator.Up(); err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	if len(cmdArgs) > 1 && (cmdArgs[1] == backupCommand || cmdArgs[1] == restoreCommand) {
+		if err := a.runBackupCommand(ctx, conf, cmdArgs[1], cmdArgs[2:]); err != nil {
+			return errors.Wrap(err, op)
+		}
+		return nil
+	}
+
+	if len(cmdArgs) > 1 && cmdArgs[1] == auditCommand {
+		if err := a.runAuditCommand(ctx, conf, cmdArgs[2:]); err != nil {
+			return errors.Wrap(err, op)
+		}
+		return nil
+	}
 
 	s := server.New(conf)
 
@@ -41,6 +75,50 @@ func (a *app) Run(ctx context.Context, args []string) error {
 	return nil
 }
 
+func (a *app) runBackupCommand(ctx context.Context, conf *config.Config, cmd string, args []string) error {
+	const op = "run backup command"
+
+	store, closer, err := storage.NewBackupStore(ctx, conf)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer closer()
+
+	blobs, closeBlobs, err := storage.NewBlobStore(ctx, conf)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer closeBlobs()
+
+	if cmd == backupCommand {
+		err = runBackup(ctx, store, blobs, args)
+	} else {
+		err = runRestore(ctx, store, blobs, args)
+	}
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (a *app) runAuditCommand(ctx context.Context, conf *config.Config, args []string) error {
+	const op = "run audit command"
+
+	repos, err := storage.NewRepositories(ctx, conf)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer repos.Close()
+
+	err = runAudit(ctx, repos.Events, args, a.out)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
 func getConfig(args []string) (*config.Config, error) {
 	const op = "run app"
 
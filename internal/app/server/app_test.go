@@ -0,0 +1,100 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+	"github.com/nestjam/goph-keeper/internal/auth/repository/sqlite"
+	sqlitestorage "github.com/nestjam/goph-keeper/internal/storage/sqlite"
+	"github.com/nestjam/goph-keeper/migration"
+)
+
+func TestRun(t *testing.T) {
+	t.Run("run migrate command instead of server", func(t *testing.T) {
+		ctx := context.Background()
+		path := filepath.Join(t.TempDir(), "goph-keeper.db")
+		args := []string{"server", "--storage.driver", "sqlite", "migrate", "up"}
+		args = append(args, "--config", writeSQLiteConfig(t, path))
+		var out bytes.Buffer
+		sut := &app{out: &out}
+
+		err := sut.Run(ctx, args)
+
+		require.NoError(t, err)
+		assertVersion(t, migration.NewSQLiteMigrator(path), latestVersion(t))
+	})
+	t.Run("run audit command instead of server", func(t *testing.T) {
+		ctx := context.Background()
+		path := filepath.Join(t.TempDir(), "goph-keeper.db")
+		args := []string{"server", "--storage.driver", "sqlite", "audit", "verify"}
+		args = append(args, "--config", writeSQLiteConfig(t, path))
+		var out bytes.Buffer
+		sut := &app{out: &out}
+
+		err := sut.Run(ctx, args)
+
+		require.NoError(t, err)
+		assert.Equal(t, "0 events verified\n", out.String())
+	})
+	t.Run("backup and restore storage", func(t *testing.T) {
+		ctx := context.Background()
+		identity := newIdentity(t)
+		path := filepath.Join(t.TempDir(), "goph-keeper.db")
+		userID := registerUser(t, path)
+		name := filepath.Join(t.TempDir(), "backup.age")
+		args := []string{"server", "--storage.driver", "sqlite", "backup", name, identity.Recipient().String()}
+		args = append(args, "--config", writeSQLiteConfig(t, path))
+		sut := &app{out: &bytes.Buffer{}}
+		err := sut.Run(ctx, args)
+		require.NoError(t, err)
+		restoredPath := filepath.Join(t.TempDir(), "restored.db")
+		args = []string{"server", "--storage.driver", "sqlite", "restore", name, writeIdentity(t, identity)}
+		args = append(args, "--config", writeSQLiteConfig(t, restoredPath))
+
+		err = sut.Run(ctx, args)
+
+		require.NoError(t, err)
+		db, err := sqlitestorage.Open(ctx, restoredPath)
+		require.NoError(t, err)
+		defer func() { _ = db.Close() }()
+		r := sqlite.NewUserRepository(db)
+		got, err := r.FindByEmail(ctx, "user@email.com")
+		require.NoError(t, err)
+		assert.Equal(t, userID, got.ID)
+	})
+}
+
+func registerUser(t *testing.T, path string) uuid.UUID {
+	t.Helper()
+
+	err := migration.NewSQLiteMigrator(path).Up()
+	require.NoError(t, err)
+	ctx := context.Background()
+	db, err := sqlitestorage.Open(ctx, path)
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+	r := sqlite.NewUserRepository(db)
+	userID, err := r.Register(ctx, &model.User{Email: "user@email.com", Password: "1"})
+	require.NoError(t, err)
+
+	return userID
+}
+
+func writeSQLiteConfig(t *testing.T, path string) string {
+	t.Helper()
+
+	name := filepath.Join(t.TempDir(), "config.yml")
+	conf := "sqlite:\n  path: " + path + "\nvault:\n  keyPath: " + filepath.Join(t.TempDir(), "keys") + "\n"
+	err := os.WriteFile(name, []byte(conf), 0o600)
+	require.NoError(t, err)
+
+	return name
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/audit"
+	"github.com/nestjam/goph-keeper/internal/audit/model"
+)
+
+const (
+	auditCommand = "audit"
+	auditUsage   = "usage: audit verify"
+)
+
+var errInvalidAuditCommand = errors.New("invalid audit command")
+
+// runAudit runs audit command with arguments:
+//
+//	verify   check the hash chain of the whole audit log, the first broken link is reported by the error
+func runAudit(ctx context.Context, events audit.EventRepository, args []string, out io.Writer) error {
+	const op = "audit"
+
+	if len(args) != 1 || args[0] != "verify" {
+		return errors.Wrap(errInvalidAuditCommand, auditUsage)
+	}
+
+	if err := verifyAuditLog(ctx, events, out); err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func verifyAuditLog(ctx context.Context, events audit.EventRepository, out io.Writer) error {
+	const op = "verify audit log"
+
+	chain, err := events.ListEvents(ctx, model.EventFilter{})
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	// events are listed the latest first
+	slices.Reverse(chain)
+
+	if err = model.VerifyLog(chain); err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	_, err = fmt.Fprintf(out, "%d events verified\n", len(chain))
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
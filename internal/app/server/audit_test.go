@@ -0,0 +1,77 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/audit/model"
+	"github.com/nestjam/goph-keeper/internal/audit/repository/inmemory"
+)
+
+func TestRunAudit(t *testing.T) {
+	t.Run("verify audit log", func(t *testing.T) {
+		ctx := context.Background()
+		events := inmemory.NewEventRepository()
+		for _, action := range []model.Action{model.ActionRegister, model.ActionLogin, model.ActionAddSecret} {
+			err := events.AppendEvent(ctx, newAuditEvent(action))
+			require.NoError(t, err)
+		}
+		var out bytes.Buffer
+
+		err := runAudit(ctx, events, []string{"verify"}, &out)
+
+		require.NoError(t, err)
+		assert.Equal(t, "3 events verified\n", out.String())
+	})
+	t.Run("verify changed audit log", func(t *testing.T) {
+		ctx := context.Background()
+		events := inmemory.NewEventRepository()
+		for _, action := range []model.Action{model.ActionRegister, model.ActionLogin, model.ActionAddSecret} {
+			err := events.AppendEvent(ctx, newAuditEvent(action))
+			require.NoError(t, err)
+		}
+		log, err := events.ListEvents(ctx, model.EventFilter{})
+		require.NoError(t, err)
+		log[1].Outcome = model.OutcomeFailure
+		var out bytes.Buffer
+
+		err = runAudit(ctx, eventRepositoryStub(log), []string{"verify"}, &out)
+
+		require.ErrorIs(t, err, model.ErrBrokenChain)
+		assert.Contains(t, err.Error(), "event 2 is changed")
+		assert.Empty(t, out.String())
+	})
+	t.Run("invalid arguments", func(t *testing.T) {
+		ctx := context.Background()
+		var out bytes.Buffer
+
+		err := runAudit(ctx, inmemory.NewEventRepository(), []string{"check"}, &out)
+
+		require.ErrorIs(t, err, errInvalidAuditCommand)
+	})
+}
+
+type eventRepositoryStub []*model.Event
+
+func (s eventRepositoryStub) AppendEvent(_ context.Context, _ *model.Event) error {
+	return nil
+}
+
+func (s eventRepositoryStub) ListEvents(_ context.Context, _ model.EventFilter) ([]*model.Event, error) {
+	return s, nil
+}
+
+func newAuditEvent(action model.Action) *model.Event {
+	return &model.Event{
+		UserID:  uuid.New(),
+		Action:  action,
+		Time:    time.Now(),
+		Outcome: model.OutcomeSuccess,
+	}
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"os"
+
+	"filippo.io/age"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/backup"
+)
+
+const (
+	backupCommand  = "backup"
+	backupUsage    = "usage: backup FILE RECIPIENT"
+	restoreCommand = "restore"
+	restoreUsage   = "usage: restore FILE IDENTITY_FILE"
+
+	backupFileMode = 0o600
+)
+
+var errInvalidBackupCommand = errors.New("invalid backup command")
+
+// runBackup writes the content of the storage to a new file encrypted to the age recipient,
+// such as the public key printed by age-keygen.
+func runBackup(ctx context.Context, store backup.Store, blobs backup.BlobStore, args []string) (err error) {
+	const op = "backup"
+
+	if len(args) != 2 {
+		return errors.Wrap(errInvalidBackupCommand, backupUsage)
+	}
+	name := args[0]
+
+	recipient, err := age.ParseX25519Recipient(args[1])
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, backupFileMode)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(name)
+		}
+	}()
+
+	err = backup.Backup(ctx, store, blobs, f, recipient)
+	if err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, op)
+	}
+
+	err = f.Close()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// runRestore loads the backup file decrypted with the identities from the identity file
+// into the empty storage.
+func runRestore(ctx context.Context, store backup.Store, blobs backup.BlobStore, args []string) error {
+	const op = "restore"
+
+	if len(args) != 2 {
+		return errors.Wrap(errInvalidBackupCommand, restoreUsage)
+	}
+
+	identities, err := readIdentities(args[1])
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = f.Close() }()
+
+	err = backup.Restore(ctx, store, blobs, f, identities...)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func readIdentities(name string) ([]age.Identity, error) {
+	const op = "read identities"
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	defer func() { _ = f.Close() }()
+
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return identities, nil
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/backup"
+)
+
+func TestRunBackup(t *testing.T) {
+	t.Run("backup to new file", func(t *testing.T) {
+		ctx := context.Background()
+		identity := newIdentity(t)
+		name := filepath.Join(t.TempDir(), "backup.age")
+		store := &storeStub{snapshot: newSnapshot()}
+
+		err := runBackup(ctx, store, nil, []string{name, identity.Recipient().String()})
+
+		require.NoError(t, err)
+		f, err := os.Open(name)
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+		got, err := backup.ReadArchive(ctx, f, nil, identity)
+		require.NoError(t, err)
+		assert.Equal(t, store.snapshot, got)
+	})
+	t.Run("backup does not overwrite existing file", func(t *testing.T) {
+		ctx := context.Background()
+		name := filepath.Join(t.TempDir(), "backup.age")
+		err := os.WriteFile(name, []byte("previous backup"), 0o600)
+		require.NoError(t, err)
+		store := &storeStub{snapshot: newSnapshot()}
+
+		err = runBackup(ctx, store, nil, []string{name, newIdentity(t).Recipient().String()})
+
+		require.Error(t, err)
+		content, err := os.ReadFile(name)
+		require.NoError(t, err)
+		assert.Equal(t, "previous backup", string(content))
+	})
+	t.Run("invalid recipient", func(t *testing.T) {
+		ctx := context.Background()
+		name := filepath.Join(t.TempDir(), "backup.age")
+		store := &storeStub{snapshot: newSnapshot()}
+
+		err := runBackup(ctx, store, nil, []string{name, "recipient"})
+
+		require.Error(t, err)
+		assert.NoFileExists(t, name)
+	})
+	t.Run("remove file on failure", func(t *testing.T) {
+		ctx := context.Background()
+		name := filepath.Join(t.TempDir(), "backup.age")
+		store := &storeStub{err: assert.AnError}
+
+		err := runBackup(ctx, store, nil, []string{name, newIdentity(t).Recipient().String()})
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoFileExists(t, name)
+	})
+	t.Run("invalid arguments", func(t *testing.T) {
+		ctx := context.Background()
+		store := &storeStub{}
+
+		err := runBackup(ctx, store, nil, []string{"backup.age"})
+
+		require.ErrorIs(t, err, errInvalidBackupCommand)
+	})
+}
+
+func TestRunRestore(t *testing.T) {
+	t.Run("restore from backup file", func(t *testing.T) {
+		ctx := context.Background()
+		identity := newIdentity(t)
+		want := newSnapshot()
+		name := writeBackup(t, want, identity.Recipient())
+		store := &storeStub{}
+
+		err := runRestore(ctx, store, nil, []string{name, writeIdentity(t, identity)})
+
+		require.NoError(t, err)
+		assert.Equal(t, want, store.snapshot)
+	})
+	t.Run("restore with wrong identity", func(t *testing.T) {
+		ctx := context.Background()
+		name := writeBackup(t, newSnapshot(), newIdentity(t).Recipient())
+		store := &storeStub{}
+
+		err := runRestore(ctx, store, nil, []string{name, writeIdentity(t, newIdentity(t))})
+
+		require.Error(t, err)
+		assert.Nil(t, store.snapshot)
+	})
+	t.Run("invalid arguments", func(t *testing.T) {
+		ctx := context.Background()
+		store := &storeStub{}
+
+		err := runRestore(ctx, store, nil, []string{"backup.age"})
+
+		require.ErrorIs(t, err, errInvalidBackupCommand)
+	})
+}
+
+type storeStub struct {
+	snapshot *backup.Snapshot
+	err      error
+}
+
+func (s *storeStub) Export(context.Context) (*backup.Snapshot, error) {
+	return s.snapshot, s.err
+}
+
+func (s *storeStub) Import(_ context.Context, snapshot *backup.Snapshot) error {
+	s.snapshot = snapshot
+	return s.err
+}
+
+func newSnapshot() *backup.Snapshot {
+	userID := uuid.New()
+	keyID := uuid.New()
+	return &backup.Snapshot{
+		Users:   []backup.User{{ID: userID, Email: "user@email.com", Password: "1"}},
+		Keys:    []backup.DataKey{{ID: keyID, UserID: &userID, Key: []byte("key")}},
+		Secrets: []backup.Secret{{ID: uuid.New(), UserID: userID, KeyID: keyID, Data: []byte("data"), Revision: 1}},
+	}
+}
+
+func newIdentity(t *testing.T) *age.X25519Identity {
+	t.Helper()
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	return identity
+}
+
+func writeIdentity(t *testing.T, identity *age.X25519Identity) string {
+	t.Helper()
+
+	name := filepath.Join(t.TempDir(), "key.txt")
+	err := os.WriteFile(name, []byte(identity.String()+"\n"), 0o600)
+	require.NoError(t, err)
+	return name
+}
+
+func writeBackup(t *testing.T, s *backup.Snapshot, recipient age.Recipient) string {
+	t.Helper()
+
+	name := filepath.Join(t.TempDir(), "backup.age")
+	f, err := os.Create(name)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	err = backup.WriteArchive(context.Background(), f, s, nil, recipient)
+	require.NoError(t, err)
+	return name
+}
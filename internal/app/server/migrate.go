@@ -0,0 +1,97 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/storage"
+)
+
+const (
+	migrateCommand = "migrate"
+	migrateUsage   = "usage: migrate up|down [N]|version|force V"
+)
+
+var errInvalidMigrateCommand = errors.New("invalid migrate command")
+
+// runMigrate runs migrate command with arguments:
+//
+//	up         apply all migrations
+//	down [N]   roll back N migrations, one by default
+//	version    print version of the last applied migration
+//	force V    set version V without running migrations
+func runMigrate(m storage.Migrator, args []string, out io.Writer) error {
+	const op = "migrate"
+
+	if len(args) == 0 {
+		return errors.Wrap(errInvalidMigrateCommand, migrateUsage)
+	}
+
+	var err error
+	switch cmd, cmdArgs := args[0], args[1:]; {
+	case cmd == "up" && len(cmdArgs) == 0:
+		err = m.Up()
+	case cmd == "down" && len(cmdArgs) <= 1:
+		steps := 1
+		if len(cmdArgs) == 1 {
+			steps, err = parsePositive(cmdArgs[0])
+			if err != nil {
+				break
+			}
+		}
+		err = m.Down(steps)
+	case cmd == "version" && len(cmdArgs) == 0:
+		err = printVersion(m, out)
+	case cmd == "force" && len(cmdArgs) == 1:
+		var version int
+		version, err = strconv.Atoi(cmdArgs[0])
+		if err != nil {
+			break
+		}
+		err = m.Force(version)
+	default:
+		err = errors.Wrap(errInvalidMigrateCommand, migrateUsage)
+	}
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func printVersion(m storage.Migrator, out io.Writer) error {
+	const op = "print version"
+
+	version, dirty, err := m.Version()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	if dirty {
+		_, err = fmt.Fprintf(out, "%d (dirty)\n", version)
+	} else {
+		_, err = fmt.Fprintf(out, "%d\n", version)
+	}
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func parsePositive(s string) (int, error) {
+	const op = "parse positive"
+
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.Wrap(err, op)
+	}
+	if n <= 0 {
+		return 0, errors.Wrap(errInvalidMigrateCommand, "N must be positive")
+	}
+
+	return n, nil
+}
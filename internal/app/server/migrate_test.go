@@ -0,0 +1,105 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/migration"
+)
+
+func TestRunMigrate(t *testing.T) {
+	t.Run("migrate up", func(t *testing.T) {
+		m := migration.NewSQLiteMigrator(filepath.Join(t.TempDir(), "goph-keeper.db"))
+		var out bytes.Buffer
+
+		err := runMigrate(m, []string{"up"}, &out)
+
+		require.NoError(t, err)
+		assertVersion(t, m, latestVersion(t))
+	})
+	t.Run("migrate down one step by default", func(t *testing.T) {
+		m := migration.NewSQLiteMigrator(filepath.Join(t.TempDir(), "goph-keeper.db"))
+		require.NoError(t, m.Up())
+		var out bytes.Buffer
+
+		err := runMigrate(m, []string{"down"}, &out)
+
+		require.NoError(t, err)
+		assertVersion(t, m, latestVersion(t)-1)
+	})
+	t.Run("migrate down n steps", func(t *testing.T) {
+		m := migration.NewSQLiteMigrator(filepath.Join(t.TempDir(), "goph-keeper.db"))
+		require.NoError(t, m.Up())
+		var out bytes.Buffer
+
+		err := runMigrate(m, []string{"down", "2"}, &out)
+
+		require.NoError(t, err)
+		assertVersion(t, m, latestVersion(t)-2)
+	})
+	t.Run("print version", func(t *testing.T) {
+		m := migration.NewSQLiteMigrator(filepath.Join(t.TempDir(), "goph-keeper.db"))
+		require.NoError(t, m.Up())
+		var out bytes.Buffer
+
+		err := runMigrate(m, []string{"version"}, &out)
+
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("%d\n", latestVersion(t)), out.String())
+	})
+	t.Run("force version", func(t *testing.T) {
+		m := migration.NewSQLiteMigrator(filepath.Join(t.TempDir(), "goph-keeper.db"))
+		require.NoError(t, m.Up())
+		var out bytes.Buffer
+
+		err := runMigrate(m, []string{"force", "1"}, &out)
+
+		require.NoError(t, err)
+		assertVersion(t, m, 1)
+	})
+	t.Run("invalid command", func(t *testing.T) {
+		tests := [][]string{
+			{},
+			{"sideways"},
+			{"up", "1"},
+			{"down", "0"},
+			{"down", "one"},
+			{"version", "1"},
+			{"force"},
+			{"force", "one"},
+		}
+		for _, args := range tests {
+			m := migration.NewSQLiteMigrator(filepath.Join(t.TempDir(), "goph-keeper.db"))
+			var out bytes.Buffer
+
+			err := runMigrate(m, args, &out)
+
+			assert.Error(t, err, args)
+		}
+	})
+}
+
+func assertVersion(t *testing.T, m *migration.DatabaseMigrator, want uint) {
+	t.Helper()
+
+	got, dirty, err := m.Version()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.False(t, dirty)
+}
+
+// latestVersion returns the schema version after all migrations are applied.
+func latestVersion(t *testing.T) uint {
+	t.Helper()
+
+	m := migration.NewSQLiteMigrator(filepath.Join(t.TempDir(), "goph-keeper.db"))
+	require.NoError(t, m.Up())
+	version, _, err := m.Version()
+	require.NoError(t, err)
+	return version
+}
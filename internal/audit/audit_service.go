@@ -0,0 +1,20 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/audit/model"
+)
+
+// Recorder records events of the audit log.
+type Recorder interface {
+	Record(ctx context.Context, event *model.Event) error
+}
+
+type AuditService interface {
+	Recorder
+	// ListEvents returns events of the user matching the filter, the latest first.
+	ListEvents(ctx context.Context, userID uuid.UUID, filter model.EventFilter) ([]*model.Event, error)
+}
@@ -0,0 +1,7 @@
+package audit
+
+import "net/http"
+
+type AuditHandlers interface {
+	ListEvents() http.HandlerFunc
+}
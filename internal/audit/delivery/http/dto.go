@@ -0,0 +1,15 @@
+package http
+
+type Event struct {
+	Time      string `json:"time"`
+	Action    string `json:"action"`
+	SecretID  string `json:"secret_id,omitempty"`
+	ClientIP  string `json:"client_ip,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+	Outcome   string `json:"outcome"`
+	Seq       int64  `json:"seq"`
+}
+
+type ListEventsResponse struct {
+	List []Event `json:"list,omitempty"`
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/audit"
+	"github.com/nestjam/goph-keeper/internal/audit/model"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+const (
+	contentTypeHeader = "Content-Type"
+	applicationJSON   = "application/json"
+
+	actionParam = "action"
+	secretParam = "secret"
+	sinceParam  = "since"
+	untilParam  = "until"
+	limitParam  = "limit"
+)
+
+var (
+	errInvalidLimit = errors.New("invalid limit")
+)
+
+type AuditHandlers struct {
+	service audit.AuditService
+}
+
+func NewAuditHandlers(service audit.AuditService) audit.AuditHandlers {
+	return &AuditHandlers{service}
+}
+
+// ListEvents returns audit events of the user. Query parameters action, secret,
+// since and until (RFC 3339) and limit filter the events.
+func (h *AuditHandlers) ListEvents() http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter, err := filterFromQuery(r.URL.Query())
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		ctx := r.Context()
+		userID, err := utils.UserFromContext(ctx)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		events, err := h.service.ListEvents(ctx, userID, filter)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		content, err := json.Marshal(newListEventsResponse(events))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set(contentTypeHeader, applicationJSON)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(content)
+	})
+}
+
+func filterFromQuery(query url.Values) (model.EventFilter, error) {
+	const op = "filter from query"
+
+	filter := model.EventFilter{
+		Action: model.Action(query.Get(actionParam)),
+	}
+
+	var err error
+	if s := query.Get(secretParam); s != "" {
+		filter.SecretID, err = uuid.Parse(s)
+		if err != nil {
+			return filter, errors.Wrap(err, op)
+		}
+	}
+	if s := query.Get(sinceParam); s != "" {
+		filter.Since, err = time.Parse(time.RFC3339, s)
+		if err != nil {
+			return filter, errors.Wrap(err, op)
+		}
+	}
+	if s := query.Get(untilParam); s != "" {
+		filter.Until, err = time.Parse(time.RFC3339, s)
+		if err != nil {
+			return filter, errors.Wrap(err, op)
+		}
+	}
+	if s := query.Get(limitParam); s != "" {
+		filter.Limit, err = strconv.Atoi(s)
+		if err != nil {
+			return filter, errors.Wrap(err, op)
+		}
+		if filter.Limit <= 0 {
+			return filter, errors.Wrap(errInvalidLimit, op)
+		}
+	}
+
+	return filter, nil
+}
+
+func newListEventsResponse(events []*model.Event) *ListEventsResponse {
+	resp := &ListEventsResponse{
+		List: make([]Event, len(events)),
+	}
+
+	for i, e := range events {
+		resp.List[i] = Event{
+			Seq:       e.Seq,
+			Time:      e.Time.Format(time.RFC3339Nano),
+			Action:    string(e.Action),
+			ClientIP:  e.ClientIP,
+			UserAgent: e.UserAgent,
+			Outcome:   string(e.Outcome),
+		}
+		if e.SecretID != uuid.Nil {
+			resp.List[i].SecretID = e.SecretID.String()
+		}
+	}
+
+	return resp
+}
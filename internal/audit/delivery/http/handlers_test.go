@@ -0,0 +1,121 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/audit"
+	"github.com/nestjam/goph-keeper/internal/audit/model"
+	"github.com/nestjam/goph-keeper/internal/audit/repository/inmemory"
+	"github.com/nestjam/goph-keeper/internal/audit/service"
+	"github.com/nestjam/goph-keeper/internal/config"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+func TestListEvents(t *testing.T) {
+	t.Run("events of user", func(t *testing.T) {
+		auditService := service.NewAuditService(inmemory.NewEventRepository())
+		sut := NewAuditHandlers(auditService)
+		userID := uuid.New()
+		secretID := uuid.New()
+		recordEvent(t, auditService, &model.Event{UserID: userID, Action: model.ActionGetSecret, SecretID: secretID})
+		recordEvent(t, auditService, &model.Event{UserID: uuid.New(), Action: model.ActionLogin})
+		r := newListEventsRequestWithUser(t, "/audit", userID)
+		w := httptest.NewRecorder()
+
+		sut.ListEvents().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, applicationJSON, w.Header().Get(contentTypeHeader))
+		got := listEventsFromResponse(t, w.Body)
+		require.Len(t, got, 1)
+		assert.Equal(t, string(model.ActionGetSecret), got[0].Action)
+		assert.Equal(t, secretID.String(), got[0].SecretID)
+	})
+	t.Run("events filtered by query", func(t *testing.T) {
+		auditService := service.NewAuditService(inmemory.NewEventRepository())
+		sut := NewAuditHandlers(auditService)
+		userID := uuid.New()
+		recordEvent(t, auditService, &model.Event{UserID: userID, Action: model.ActionLogin})
+		recordEvent(t, auditService, &model.Event{UserID: userID, Action: model.ActionListSecrets})
+		recordEvent(t, auditService, &model.Event{UserID: userID, Action: model.ActionLogin})
+		since := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+		r := newListEventsRequestWithUser(t, "/audit?action=login&limit=1&since="+since, userID)
+		w := httptest.NewRecorder()
+
+		sut.ListEvents().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		got := listEventsFromResponse(t, w.Body)
+		require.Len(t, got, 1)
+		assert.Equal(t, int64(3), got[0].Seq)
+	})
+	t.Run("invalid query", func(t *testing.T) {
+		tests := []string{
+			"/audit?secret=1",
+			"/audit?since=yesterday",
+			"/audit?until=tomorrow",
+			"/audit?limit=many",
+			"/audit?limit=0",
+		}
+		for _, target := range tests {
+			sut := NewAuditHandlers(service.NewAuditService(inmemory.NewEventRepository()))
+			r := newListEventsRequestWithUser(t, target, uuid.New())
+			w := httptest.NewRecorder()
+
+			sut.ListEvents().ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, target)
+		}
+	})
+	t.Run("user is not authenticated", func(t *testing.T) {
+		cfg := config.JWTAuthConfig{SignKey: "secret", TokenExpiryIn: time.Minute}
+		sut := chi.NewRouter()
+		MapAuditRoutes(sut, NewAuditHandlers(service.NewAuditService(inmemory.NewEventRepository())), cfg)
+		r := httptest.NewRequest(http.MethodGet, "/audit", http.NoBody)
+		w := httptest.NewRecorder()
+
+		sut.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func recordEvent(t *testing.T, s audit.AuditService, event *model.Event) {
+	t.Helper()
+
+	err := s.Record(context.Background(), event)
+	require.NoError(t, err)
+}
+
+func newListEventsRequestWithUser(t *testing.T, target string, userID uuid.UUID) *http.Request {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodGet, target, http.NoBody)
+	token := jwt.New()
+	err := token.Set(utils.UserIDClaim, userID.String())
+	require.NoError(t, err)
+	ctx := context.WithValue(r.Context(), jwtauth.TokenCtxKey, token)
+	return r.WithContext(ctx)
+}
+
+func listEventsFromResponse(t *testing.T, r io.Reader) []Event {
+	t.Helper()
+
+	var resp ListEventsResponse
+	err := json.NewDecoder(r).Decode(&resp)
+	require.NoError(t, err)
+	return resp.List
+}
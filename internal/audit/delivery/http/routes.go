@@ -0,0 +1,20 @@
+package http
+
+import (
+	"github.com/go-chi/chi/v5"
+
+	"github.com/nestjam/goph-keeper/internal/audit"
+	"github.com/nestjam/goph-keeper/internal/config"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+func MapAuditRoutes(r chi.Router, h audit.AuditHandlers, cfg config.JWTAuthConfig,
+	opts ...utils.AuthCookieBakerOption) {
+	cookieBaker := utils.NewAuthCookieBaker(cfg, opts...)
+
+	r.Group(func(r chi.Router) {
+		r.Use(cookieBaker.Middlewares()...)
+
+		r.Get("/audit", h.ListEvents())
+	})
+}
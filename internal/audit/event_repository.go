@@ -0,0 +1,15 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/nestjam/goph-keeper/internal/audit/model"
+)
+
+// EventRepository is an append-only storage of the audit log.
+type EventRepository interface {
+	// AppendEvent chains the event to the last one of the log and stores it.
+	AppendEvent(ctx context.Context, event *model.Event) error
+	// ListEvents returns events matching the filter, the latest first.
+	ListEvents(ctx context.Context, filter model.EventFilter) ([]*model.Event, error)
+}
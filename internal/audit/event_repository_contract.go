@@ -0,0 +1,178 @@
+package audit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/audit/model"
+)
+
+type EventRepositoryContract struct {
+	NewEventRepository func() (EventRepository, func())
+}
+
+func (c EventRepositoryContract) Test(t *testing.T) {
+	t.Run("append event", func(t *testing.T) {
+		sut, tearDown := c.NewEventRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		event := newEvent(uuid.New(), model.ActionGetSecret)
+
+		err := sut.AppendEvent(ctx, event)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.FirstSeq, event.Seq)
+		assert.Empty(t, event.PrevHash)
+		assert.NotEmpty(t, event.Hash)
+		got, err := sut.ListEvents(ctx, model.EventFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Event{event}, got)
+	})
+	t.Run("appended events make chain", func(t *testing.T) {
+		sut, tearDown := c.NewEventRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		userID := uuid.New()
+		for _, action := range []model.Action{model.ActionRegister, model.ActionAddSecret, model.ActionDeleteSecret} {
+			err := sut.AppendEvent(ctx, newEvent(userID, action))
+			require.NoError(t, err)
+		}
+
+		got, err := sut.ListEvents(ctx, model.EventFilter{})
+
+		require.NoError(t, err)
+		require.Len(t, got, 3)
+		assert.Equal(t, model.ActionDeleteSecret, got[0].Action)
+		err = model.VerifyChain(reverse(got))
+		require.NoError(t, err)
+	})
+	t.Run("concurrently appended events make chain", func(t *testing.T) {
+		sut, tearDown := c.NewEventRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		const count = 10
+		var wg sync.WaitGroup
+		errs := make(chan error, count)
+		for i := 0; i < count; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- sut.AppendEvent(ctx, newEvent(uuid.New(), model.ActionLogin))
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			require.NoError(t, err)
+		}
+
+		got, err := sut.ListEvents(ctx, model.EventFilter{})
+
+		require.NoError(t, err)
+		require.Len(t, got, count)
+		err = model.VerifyChain(reverse(got))
+		require.NoError(t, err)
+	})
+	t.Run("list events of user", func(t *testing.T) {
+		sut, tearDown := c.NewEventRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		userID := uuid.New()
+		want := newEvent(userID, model.ActionLogin)
+		err := sut.AppendEvent(ctx, want)
+		require.NoError(t, err)
+		err = sut.AppendEvent(ctx, newEvent(uuid.New(), model.ActionLogin))
+		require.NoError(t, err)
+
+		got, err := sut.ListEvents(ctx, model.EventFilter{UserID: userID})
+
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Event{want}, got)
+	})
+	t.Run("list events filtered by action and secret", func(t *testing.T) {
+		sut, tearDown := c.NewEventRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		userID := uuid.New()
+		secretID := uuid.New()
+		events := []*model.Event{
+			newEvent(userID, model.ActionGetSecret),
+			newEvent(userID, model.ActionGetSecret),
+			newEvent(userID, model.ActionUpdateSecret),
+		}
+		events[0].SecretID = secretID
+		events[2].SecretID = secretID
+		for _, e := range events {
+			err := sut.AppendEvent(ctx, e)
+			require.NoError(t, err)
+		}
+		filter := model.EventFilter{UserID: userID, Action: model.ActionGetSecret, SecretID: secretID}
+
+		got, err := sut.ListEvents(ctx, filter)
+
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Event{events[0]}, got)
+	})
+	t.Run("list events filtered by time", func(t *testing.T) {
+		sut, tearDown := c.NewEventRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		userID := uuid.New()
+		now := time.Now()
+		events := make([]*model.Event, 3)
+		for i := range events {
+			events[i] = newEvent(userID, model.ActionLogin)
+			events[i].Time = now.Add(time.Duration(i) * time.Hour)
+			err := sut.AppendEvent(ctx, events[i])
+			require.NoError(t, err)
+		}
+		filter := model.EventFilter{Since: events[1].Time, Until: events[1].Time.Add(time.Minute)}
+
+		got, err := sut.ListEvents(ctx, filter)
+
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Event{events[1]}, got)
+	})
+	t.Run("list limited number of latest events", func(t *testing.T) {
+		sut, tearDown := c.NewEventRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		userID := uuid.New()
+		events := make([]*model.Event, 3)
+		for i := range events {
+			events[i] = newEvent(userID, model.ActionLogin)
+			err := sut.AppendEvent(ctx, events[i])
+			require.NoError(t, err)
+		}
+
+		got, err := sut.ListEvents(ctx, model.EventFilter{Limit: 2})
+
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Event{events[2], events[1]}, got)
+	})
+}
+
+func newEvent(userID uuid.UUID, action model.Action) *model.Event {
+	return &model.Event{
+		UserID:    userID,
+		Action:    action,
+		Time:      time.Now(),
+		ClientIP:  "127.0.0.1",
+		UserAgent: "goph-keeper",
+		Outcome:   model.OutcomeSuccess,
+	}
+}
+
+func reverse(events []*model.Event) []*model.Event {
+	reversed := make([]*model.Event, len(events))
+	for i, e := range events {
+		reversed[len(events)-1-i] = e
+	}
+	return reversed
+}
@@ -0,0 +1,152 @@
+package model
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// FirstSeq is the sequence number of the first event of the log.
+const FirstSeq int64 = 1
+
+var ErrBrokenChain = errors.New("audit log hash chain is broken")
+
+type Action string
+
+const (
+	ActionListSecrets    Action = "list_secrets"
+	ActionAddSecret      Action = "add_secret"
+	ActionGetSecret      Action = "get_secret"
+	ActionUpdateSecret   Action = "update_secret"
+	ActionDeleteSecret   Action = "delete_secret"
+	ActionSyncSecrets    Action = "sync_secrets"
+	ActionRegister       Action = "register"
+	ActionLogin          Action = "login"
+	ActionDeleteAccount  Action = "delete_account"
+	ActionRefresh        Action = "refresh"
+	ActionLogout         Action = "logout"
+	ActionVerifyLogin    Action = "verify_login"
+	ActionEnrollTwoFA    Action = "enroll_2fa"
+	ActionConfirmTwoFA   Action = "confirm_2fa"
+	ActionChangePassword Action = "change_password"
+	ActionResetPassword  Action = "reset_password"
+	ActionCreateToken    Action = "create_token"
+	ActionRevokeToken    Action = "revoke_token"
+)
+
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+)
+
+// Event is a record of the audit log. Every event holds the hash of the previous one,
+// so a changed or removed event breaks the chain.
+type Event struct {
+	Time      time.Time
+	Action    Action
+	Outcome   Outcome
+	ClientIP  string
+	UserAgent string
+	PrevHash  []byte
+	Hash      []byte
+	Seq       int64
+	UserID    uuid.UUID
+	SecretID  uuid.UUID
+}
+
+// EventFilter selects events of the log. Zero fields match any event.
+type EventFilter struct {
+	Since    time.Time
+	Until    time.Time
+	Action   Action
+	Limit    int
+	UserID   uuid.UUID
+	SecretID uuid.UUID
+}
+
+// OutcomeOf returns outcome of the request answered with the status code.
+func OutcomeOf(statusCode int) Outcome {
+	if statusCode >= 400 {
+		return OutcomeFailure
+	}
+	return OutcomeSuccess
+}
+
+// Chain appends the event to the log ending with the event of the sequence number and hash.
+// Zero sequence number means the log is empty.
+func (e *Event) Chain(prevSeq int64, prevHash []byte) {
+	// the time is kept with precision supported by the storages
+	e.Time = e.Time.UTC().Truncate(time.Microsecond)
+	e.Seq = prevSeq + 1
+	e.PrevHash = prevHash
+	e.Hash = e.ComputeHash()
+}
+
+// ComputeHash returns SHA-256 hash of the previous event hash and the event fields.
+func (e *Event) ComputeHash() []byte {
+	h := sha256.New()
+	writeInt(h, e.Seq)
+	writeField(h, e.PrevHash)
+	writeField(h, e.UserID[:])
+	writeField(h, []byte(e.Action))
+	writeField(h, e.SecretID[:])
+	writeInt(h, e.Time.UnixMicro())
+	writeField(h, []byte(e.ClientIP))
+	writeField(h, []byte(e.UserAgent))
+	writeField(h, []byte(e.Outcome))
+	return h.Sum(nil)
+}
+
+// VerifyChain checks that the events ordered by sequence number follow each other
+// and are not changed.
+func VerifyChain(events []*Event) error {
+	const op = "verify chain"
+
+	for i, e := range events {
+		if !bytes.Equal(e.Hash, e.ComputeHash()) {
+			return errors.Wrapf(ErrBrokenChain, "%s: event %d is changed", op, e.Seq)
+		}
+		if i == 0 {
+			if e.Seq == FirstSeq && len(e.PrevHash) != 0 {
+				return errors.Wrapf(ErrBrokenChain, "%s: first event refers to previous one", op)
+			}
+			continue
+		}
+		prev := events[i-1]
+		if e.Seq != prev.Seq+1 || !bytes.Equal(e.PrevHash, prev.Hash) {
+			return errors.Wrapf(ErrBrokenChain, "%s: event %d does not follow event %d", op, e.Seq, prev.Seq)
+		}
+	}
+	return nil
+}
+
+// VerifyLog checks that the events ordered by sequence number make the whole log,
+// that is the chain starting with the first event.
+func VerifyLog(events []*Event) error {
+	const op = "verify log"
+
+	if len(events) > 0 && events[0].Seq != FirstSeq {
+		return errors.Wrapf(ErrBrokenChain, "%s: events before %d are missing", op, events[0].Seq)
+	}
+	if err := VerifyChain(events); err != nil {
+		return errors.Wrap(err, op)
+	}
+	return nil
+}
+
+// writeField writes length of the field before it, so that adjacent fields can not be shifted.
+func writeField(h hash.Hash, field []byte) {
+	_ = binary.Write(h, binary.BigEndian, uint32(len(field)))
+	_, _ = h.Write(field)
+}
+
+func writeInt(h hash.Hash, v int64) {
+	_ = binary.Write(h, binary.BigEndian, v)
+}
@@ -0,0 +1,98 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyChain(t *testing.T) {
+	t.Run("chained events", func(t *testing.T) {
+		events := newChain(3)
+
+		err := VerifyChain(events)
+
+		require.NoError(t, err)
+	})
+	t.Run("part of chain", func(t *testing.T) {
+		events := newChain(3)
+
+		err := VerifyChain(events[1:])
+
+		require.NoError(t, err)
+	})
+	t.Run("changed event", func(t *testing.T) {
+		events := newChain(3)
+		events[1].Outcome = OutcomeSuccess
+
+		err := VerifyChain(events)
+
+		require.ErrorIs(t, err, ErrBrokenChain)
+	})
+	t.Run("removed event", func(t *testing.T) {
+		events := newChain(3)
+		events = append(events[:1], events[2:]...)
+
+		err := VerifyChain(events)
+
+		require.ErrorIs(t, err, ErrBrokenChain)
+	})
+	t.Run("rechained event", func(t *testing.T) {
+		events := newChain(3)
+		events[1].UserID = uuid.New()
+		events[1].Chain(events[0].Seq, events[0].Hash)
+
+		err := VerifyChain(events)
+
+		require.ErrorIs(t, err, ErrBrokenChain)
+	})
+}
+
+func TestVerifyLog(t *testing.T) {
+	t.Run("whole log", func(t *testing.T) {
+		events := newChain(3)
+
+		err := VerifyLog(events)
+
+		require.NoError(t, err)
+	})
+	t.Run("empty log", func(t *testing.T) {
+		err := VerifyLog(nil)
+
+		require.NoError(t, err)
+	})
+	t.Run("log without first events", func(t *testing.T) {
+		events := newChain(3)
+
+		err := VerifyLog(events[1:])
+
+		require.ErrorIs(t, err, ErrBrokenChain)
+	})
+	t.Run("changed event", func(t *testing.T) {
+		events := newChain(3)
+		events[2].ClientIP = "192.0.2.1"
+
+		err := VerifyLog(events)
+
+		require.ErrorIs(t, err, ErrBrokenChain)
+	})
+}
+
+func newChain(n int) []*Event {
+	events := make([]*Event, n)
+	var prev Event
+	for i := range events {
+		e := &Event{
+			UserID:  uuid.New(),
+			Action:  ActionGetSecret,
+			Time:    time.Now(),
+			Outcome: OutcomeFailure,
+		}
+		e.Chain(prev.Seq, prev.Hash)
+		events[i] = e
+		prev = *e
+	}
+	return events
+}
@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/audit/model"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+type eventKey struct{}
+
+// Handler records the action performed by the handler to the audit log.
+// The event is recorded after the handler is done, its outcome follows the response status.
+// The handler can complete the event with SetUser and SetSecret.
+func Handler(recorder Recorder, action model.Action, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		event := &model.Event{
+			Action:    action,
+			Time:      time.Now(),
+			ClientIP:  clientIP(r),
+			UserAgent: r.UserAgent(),
+		}
+		ctx := context.WithValue(r.Context(), eventKey{}, event)
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next(ww, r.WithContext(ctx))
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		event.Outcome = model.OutcomeOf(status)
+		if event.UserID == uuid.Nil {
+			event.UserID, _ = utils.UserFromContext(ctx)
+		}
+
+		// the response is sent already, so failure to record the event is only logged
+		if err := recorder.Record(context.WithoutCancel(ctx), event); err != nil {
+			log.Printf("record %s event of user %s: %v", event.Action, event.UserID, err)
+		}
+	}
+}
+
+// SetUser sets the user of the event being recorded.
+func SetUser(ctx context.Context, userID uuid.UUID) {
+	if event, ok := ctx.Value(eventKey{}).(*model.Event); ok {
+		event.UserID = userID
+	}
+}
+
+// SetSecret sets the secret of the event being recorded.
+func SetSecret(ctx context.Context, secretID uuid.UUID) {
+	if event, ok := ctx.Value(eventKey{}).(*model.Event); ok {
+		event.SecretID = secretID
+	}
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/audit/model"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+type recorderFunc func(ctx context.Context, event *model.Event) error
+
+func (f recorderFunc) Record(ctx context.Context, event *model.Event) error {
+	return f(ctx, event)
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("record successful action of user", func(t *testing.T) {
+		var got *model.Event
+		recorder := recorderFunc(func(_ context.Context, event *model.Event) error {
+			got = event
+			return nil
+		})
+		secretID := uuid.New()
+		sut := Handler(recorder, model.ActionGetSecret, func(w http.ResponseWriter, r *http.Request) {
+			SetSecret(r.Context(), secretID)
+			w.WriteHeader(http.StatusOK)
+		})
+		userID := uuid.New()
+		r := newRequestWithUser(t, userID)
+
+		sut.ServeHTTP(httptest.NewRecorder(), r)
+
+		require.NotNil(t, got)
+		assert.Equal(t, model.ActionGetSecret, got.Action)
+		assert.Equal(t, model.OutcomeSuccess, got.Outcome)
+		assert.Equal(t, userID, got.UserID)
+		assert.Equal(t, secretID, got.SecretID)
+		assert.Equal(t, "192.0.2.1", got.ClientIP)
+		assert.Equal(t, "goph-keeper", got.UserAgent)
+		assert.False(t, got.Time.IsZero())
+	})
+	t.Run("record failed action", func(t *testing.T) {
+		var got *model.Event
+		recorder := recorderFunc(func(_ context.Context, event *model.Event) error {
+			got = event
+			return nil
+		})
+		sut := Handler(recorder, model.ActionLogin, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+		r := httptest.NewRequest(http.MethodPost, "/login", http.NoBody)
+
+		sut.ServeHTTP(httptest.NewRecorder(), r)
+
+		require.NotNil(t, got)
+		assert.Equal(t, model.OutcomeFailure, got.Outcome)
+		assert.Equal(t, uuid.Nil, got.UserID)
+	})
+	t.Run("user set by handler", func(t *testing.T) {
+		var got *model.Event
+		recorder := recorderFunc(func(_ context.Context, event *model.Event) error {
+			got = event
+			return nil
+		})
+		userID := uuid.New()
+		sut := Handler(recorder, model.ActionRegister, func(w http.ResponseWriter, r *http.Request) {
+			SetUser(r.Context(), userID)
+			w.WriteHeader(http.StatusCreated)
+		})
+		r := httptest.NewRequest(http.MethodPost, "/register", http.NoBody)
+
+		sut.ServeHTTP(httptest.NewRecorder(), r)
+
+		require.NotNil(t, got)
+		assert.Equal(t, userID, got.UserID)
+		assert.Equal(t, model.OutcomeSuccess, got.Outcome)
+	})
+	t.Run("log failure to record event", func(t *testing.T) {
+		recorder := recorderFunc(func(_ context.Context, _ *model.Event) error {
+			return errors.New("failed")
+		})
+		sut := Handler(recorder, model.ActionGetSecret, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		userID := uuid.New()
+		r := newRequestWithUser(t, userID)
+		var out bytes.Buffer
+		log.SetOutput(&out)
+		t.Cleanup(func() { log.SetOutput(os.Stderr) })
+		w := httptest.NewRecorder()
+
+		sut.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, out.String(), "record get_secret event of user "+userID.String()+": failed")
+	})
+}
+
+func newRequestWithUser(t *testing.T, userID uuid.UUID) *http.Request {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodGet, "/secrets", http.NoBody)
+	r.RemoteAddr = "192.0.2.1:1234"
+	r.Header.Set("User-Agent", "goph-keeper")
+
+	token := jwt.New()
+	err := token.Set(utils.UserIDClaim, userID.String())
+	require.NoError(t, err)
+	ctx := context.WithValue(r.Context(), jwtauth.TokenCtxKey, token)
+	return r.WithContext(ctx)
+}
@@ -0,0 +1,71 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/audit"
+	"github.com/nestjam/goph-keeper/internal/audit/model"
+)
+
+type eventRepository struct {
+	events []model.Event
+	mu     sync.Mutex
+}
+
+func NewEventRepository() audit.EventRepository {
+	return &eventRepository{}
+}
+
+func (r *eventRepository) AppendEvent(ctx context.Context, event *model.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var (
+		prevSeq  int64
+		prevHash []byte
+	)
+	if n := len(r.events); n > 0 {
+		prevSeq, prevHash = r.events[n-1].Seq, r.events[n-1].Hash
+	}
+	event.Chain(prevSeq, prevHash)
+	r.events = append(r.events, *event)
+
+	return nil
+}
+
+func (r *eventRepository) ListEvents(ctx context.Context, filter model.EventFilter) ([]*model.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var events []*model.Event
+	for i := len(r.events) - 1; i >= 0; i-- {
+		if filter.Limit > 0 && len(events) == filter.Limit {
+			break
+		}
+		e := r.events[i]
+		if matches(&e, filter) {
+			events = append(events, &e)
+		}
+	}
+
+	return events, nil
+}
+
+func matches(e *model.Event, filter model.EventFilter) bool {
+	switch {
+	case filter.UserID != uuid.Nil && e.UserID != filter.UserID:
+		return false
+	case filter.SecretID != uuid.Nil && e.SecretID != filter.SecretID:
+		return false
+	case filter.Action != "" && e.Action != filter.Action:
+		return false
+	case !filter.Since.IsZero() && e.Time.Before(filter.Since):
+		return false
+	case !filter.Until.IsZero() && !e.Time.Before(filter.Until):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,15 @@
+package inmemory
+
+import (
+	"testing"
+
+	"github.com/nestjam/goph-keeper/internal/audit"
+)
+
+func TestEventRepository(t *testing.T) {
+	audit.EventRepositoryContract{
+		NewEventRepository: func() (audit.EventRepository, func()) {
+			return NewEventRepository(), func() {}
+		},
+	}.Test(t)
+}
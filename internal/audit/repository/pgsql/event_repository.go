@@ -0,0 +1,130 @@
+package pgsql
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/audit/model"
+	pgstorage "github.com/nestjam/goph-keeper/internal/storage/pgsql"
+)
+
+type eventRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewEventRepository(pool *pgxpool.Pool) *eventRepository {
+	return &eventRepository{pool}
+}
+
+func (r *eventRepository) AppendEvent(ctx context.Context, event *model.Event) error {
+	const op = "append event"
+
+	tx, err := pgstorage.BeginTx(ctx, r.pool)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// the lock conflicts with itself, so events are chained one by one
+	_, err = tx.Exec(ctx, `LOCK TABLE audit_events IN SHARE ROW EXCLUSIVE MODE`)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	var (
+		prevSeq  int64
+		prevHash []byte
+	)
+	const lastQuery = `SELECT seq, hash FROM audit_events ORDER BY seq DESC LIMIT 1`
+	err = tx.QueryRow(ctx, lastQuery).Scan(&prevSeq, &prevHash)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return errors.Wrap(err, op)
+	}
+
+	event.Chain(prevSeq, prevHash)
+	const query = `INSERT INTO audit_events
+(seq, user_id, action, secret_id, created_at, client_ip, user_agent, outcome, prev_hash, hash)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	_, err = tx.Exec(ctx, query, event.Seq, nullID(event.UserID), event.Action, nullID(event.SecretID),
+		event.Time, event.ClientIP, event.UserAgent, event.Outcome, event.PrevHash, event.Hash)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (r *eventRepository) ListEvents(ctx context.Context, filter model.EventFilter) ([]*model.Event, error) {
+	const op = "list events"
+
+	var (
+		conds []string
+		args  []any
+	)
+	where := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, cond+"$"+strconv.Itoa(len(args)))
+	}
+	if filter.UserID != uuid.Nil {
+		where("user_id=", filter.UserID)
+	}
+	if filter.SecretID != uuid.Nil {
+		where("secret_id=", filter.SecretID)
+	}
+	if filter.Action != "" {
+		where("action=", filter.Action)
+	}
+	if !filter.Since.IsZero() {
+		where("created_at>=", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		where("created_at<", filter.Until)
+	}
+
+	query := `SELECT seq, user_id, action, secret_id, created_at, client_ip, user_agent, outcome, prev_hash, hash
+FROM audit_events`
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY seq DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += " LIMIT $" + strconv.Itoa(len(args))
+	}
+
+	conn := pgstorage.QuerierFromContext(ctx, r.pool)
+	rows, _ := conn.Query(ctx, query, args...)
+	events, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.Event, error) {
+		var (
+			e        model.Event
+			userID   uuid.NullUUID
+			secretID uuid.NullUUID
+		)
+		err := row.Scan(&e.Seq, &userID, &e.Action, &secretID, &e.Time, &e.ClientIP, &e.UserAgent, &e.Outcome,
+			&e.PrevHash, &e.Hash)
+		e.UserID = userID.UUID
+		e.SecretID = secretID.UUID
+		e.Time = e.Time.UTC()
+		return &e, err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return events, nil
+}
+
+func nullID(id uuid.UUID) uuid.NullUUID {
+	return uuid.NullUUID{UUID: id, Valid: id != uuid.Nil}
+}
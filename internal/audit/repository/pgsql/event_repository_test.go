@@ -0,0 +1,74 @@
+//go:build integration
+
+package pgsql
+
+import (
+	"context"
+	"testing"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/audit"
+	"github.com/nestjam/goph-keeper/internal/audit/model"
+	"github.com/nestjam/goph-keeper/internal/config"
+	pgstorage "github.com/nestjam/goph-keeper/internal/storage/pgsql"
+	"github.com/nestjam/goph-keeper/internal/utils"
+	"github.com/nestjam/goph-keeper/migration"
+)
+
+var h *utils.PGSQLRepositoryTestHelper
+
+func TestMain(m *testing.M) {
+	h = &utils.PGSQLRepositoryTestHelper{}
+	h.Run(m)
+}
+
+func TestEventRepository(t *testing.T) {
+	audit.EventRepositoryContract{
+		NewEventRepository: func() (audit.EventRepository, func()) {
+			t.Helper()
+
+			dsn := h.DataSourceName
+			migrator := migration.NewDatabaseMigrator(dsn)
+			err := migrator.Up()
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			pool, err := pgstorage.NewPool(ctx, config.PostgresConfig{DataSourceName: dsn})
+			require.NoError(t, err)
+			r := NewEventRepository(pool)
+
+			return r, func() {
+				pool.Close()
+
+				migrator := migration.NewDatabaseMigrator(dsn)
+				_ = migrator.Drop()
+			}
+		},
+	}.Test(t)
+}
+
+func TestEventRepository_AppendOnly(t *testing.T) {
+	dsn := h.DataSourceName
+	migrator := migration.NewDatabaseMigrator(dsn)
+	err := migrator.Up()
+	require.NoError(t, err)
+	ctx := context.Background()
+	pool, err := pgstorage.NewPool(ctx, config.PostgresConfig{DataSourceName: dsn})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		pool.Close()
+		_ = migration.NewDatabaseMigrator(dsn).Drop()
+	})
+	sut := NewEventRepository(pool)
+	err = sut.AppendEvent(ctx, &model.Event{Action: model.ActionLogin, Outcome: model.OutcomeSuccess})
+	require.NoError(t, err)
+
+	_, err = pool.Exec(ctx, `UPDATE audit_events SET outcome='failure'`)
+	require.Error(t, err)
+	_, err = pool.Exec(ctx, `DELETE FROM audit_events`)
+	require.Error(t, err)
+	_, err = pool.Exec(ctx, `TRUNCATE audit_events`)
+	require.Error(t, err)
+}
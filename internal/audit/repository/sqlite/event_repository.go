@@ -0,0 +1,137 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/audit/model"
+	sqlitestorage "github.com/nestjam/goph-keeper/internal/storage/sqlite"
+)
+
+type eventRepository struct {
+	db *sql.DB
+}
+
+func NewEventRepository(db *sql.DB) *eventRepository {
+	return &eventRepository{db}
+}
+
+func (r *eventRepository) AppendEvent(ctx context.Context, event *model.Event) error {
+	const op = "append event"
+
+	// the transaction takes the write lock at once, so events are chained one by one
+	tx, err := sqlitestorage.BeginTx(ctx, r.db)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var (
+		prevSeq  int64
+		prevHash []byte
+	)
+	const lastQuery = `SELECT seq, hash FROM audit_events ORDER BY seq DESC LIMIT 1`
+	err = tx.QueryRowContext(ctx, lastQuery).Scan(&prevSeq, &prevHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return errors.Wrap(err, op)
+	}
+
+	event.Chain(prevSeq, prevHash)
+	const query = `INSERT INTO audit_events
+(seq, user_id, action, secret_id, created_at, client_ip, user_agent, outcome, prev_hash, hash)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = tx.ExecContext(ctx, query, event.Seq, nullID(event.UserID), event.Action, nullID(event.SecretID),
+		event.Time.UnixMicro(), event.ClientIP, event.UserAgent, event.Outcome, event.PrevHash, event.Hash)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (r *eventRepository) ListEvents(ctx context.Context, filter model.EventFilter) ([]*model.Event, error) {
+	const op = "list events"
+
+	var (
+		conds []string
+		args  []any
+	)
+	if filter.UserID != uuid.Nil {
+		conds = append(conds, "user_id=?")
+		args = append(args, filter.UserID)
+	}
+	if filter.SecretID != uuid.Nil {
+		conds = append(conds, "secret_id=?")
+		args = append(args, filter.SecretID)
+	}
+	if filter.Action != "" {
+		conds = append(conds, "action=?")
+		args = append(args, filter.Action)
+	}
+	if !filter.Since.IsZero() {
+		conds = append(conds, "created_at>=?")
+		args = append(args, filter.Since.UnixMicro())
+	}
+	if !filter.Until.IsZero() {
+		conds = append(conds, "created_at<?")
+		args = append(args, filter.Until.UnixMicro())
+	}
+
+	query := `SELECT seq, user_id, action, secret_id, created_at, client_ip, user_agent, outcome, prev_hash, hash
+FROM audit_events`
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY seq DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var events []*model.Event
+	for rows.Next() {
+		var (
+			e         model.Event
+			userID    uuid.NullUUID
+			secretID  uuid.NullUUID
+			createdAt int64
+		)
+		err = rows.Scan(&e.Seq, &userID, &e.Action, &secretID, &createdAt, &e.ClientIP, &e.UserAgent, &e.Outcome,
+			&e.PrevHash, &e.Hash)
+		if err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+		e.UserID = userID.UUID
+		e.SecretID = secretID.UUID
+		e.Time = time.UnixMicro(createdAt).UTC()
+		if len(e.PrevHash) == 0 {
+			e.PrevHash = nil
+		}
+		events = append(events, &e)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return events, nil
+}
+
+func nullID(id uuid.UUID) uuid.NullUUID {
+	return uuid.NullUUID{UUID: id, Valid: id != uuid.Nil}
+}
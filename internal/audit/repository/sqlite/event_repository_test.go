@@ -0,0 +1,55 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/audit"
+	"github.com/nestjam/goph-keeper/internal/audit/model"
+	sqlitestorage "github.com/nestjam/goph-keeper/internal/storage/sqlite"
+	"github.com/nestjam/goph-keeper/migration"
+)
+
+func TestEventRepository(t *testing.T) {
+	audit.EventRepositoryContract{
+		NewEventRepository: func() (audit.EventRepository, func()) {
+			t.Helper()
+
+			path := filepath.Join(t.TempDir(), "goph-keeper.db")
+			migrator := migration.NewSQLiteMigrator(path)
+			err := migrator.Up()
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			db, err := sqlitestorage.Open(ctx, path)
+			require.NoError(t, err)
+			r := NewEventRepository(db)
+
+			return r, func() {
+				_ = db.Close()
+			}
+		},
+	}.Test(t)
+}
+
+func TestEventRepository_AppendOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "goph-keeper.db")
+	migrator := migration.NewSQLiteMigrator(path)
+	err := migrator.Up()
+	require.NoError(t, err)
+	ctx := context.Background()
+	db, err := sqlitestorage.Open(ctx, path)
+	require.NoError(t, err)
+	sut := NewEventRepository(db)
+	t.Cleanup(func() { _ = db.Close() })
+	err = sut.AppendEvent(ctx, &model.Event{Action: model.ActionLogin, Outcome: model.OutcomeSuccess})
+	require.NoError(t, err)
+
+	_, err = sut.db.ExecContext(ctx, `UPDATE audit_events SET outcome='failure'`)
+	require.Error(t, err)
+	_, err = sut.db.ExecContext(ctx, `DELETE FROM audit_events`)
+	require.Error(t, err)
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/audit"
+	"github.com/nestjam/goph-keeper/internal/audit/model"
+)
+
+const (
+	// DefaultListLimit is the number of events listed when the filter has no limit.
+	DefaultListLimit = 100
+	// MaxListLimit is the maximum number of events listed at once.
+	MaxListLimit = 1000
+)
+
+type auditService struct {
+	events audit.EventRepository
+}
+
+func NewAuditService(events audit.EventRepository) audit.AuditService {
+	return &auditService{events}
+}
+
+func (s *auditService) Record(ctx context.Context, event *model.Event) error {
+	const op = "record"
+
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	err := s.events.AppendEvent(ctx, event)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (s *auditService) ListEvents(ctx context.Context, userID uuid.UUID,
+	filter model.EventFilter) ([]*model.Event, error) {
+	const op = "list events"
+
+	filter.UserID = userID
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultListLimit
+	}
+	filter.Limit = min(filter.Limit, MaxListLimit)
+
+	events, err := s.events.ListEvents(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return events, nil
+}
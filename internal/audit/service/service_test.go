@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/audit/model"
+	"github.com/nestjam/goph-keeper/internal/audit/repository/inmemory"
+)
+
+func TestRecord(t *testing.T) {
+	t.Run("record event", func(t *testing.T) {
+		ctx := context.Background()
+		repo := inmemory.NewEventRepository()
+		sut := NewAuditService(repo)
+		event := &model.Event{UserID: uuid.New(), Action: model.ActionLogin, Outcome: model.OutcomeSuccess}
+
+		err := sut.Record(ctx, event)
+
+		require.NoError(t, err)
+		assert.False(t, event.Time.IsZero())
+		got, err := repo.ListEvents(ctx, model.EventFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Event{event}, got)
+	})
+}
+
+func TestListEvents(t *testing.T) {
+	t.Run("list events of user only", func(t *testing.T) {
+		ctx := context.Background()
+		sut := NewAuditService(inmemory.NewEventRepository())
+		userID := uuid.New()
+		want := &model.Event{UserID: userID, Action: model.ActionLogin}
+		err := sut.Record(ctx, want)
+		require.NoError(t, err)
+		err = sut.Record(ctx, &model.Event{UserID: uuid.New(), Action: model.ActionLogin})
+		require.NoError(t, err)
+
+		got, err := sut.ListEvents(ctx, userID, model.EventFilter{UserID: uuid.New()})
+
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Event{want}, got)
+	})
+	t.Run("list events with default limit", func(t *testing.T) {
+		ctx := context.Background()
+		sut := NewAuditService(inmemory.NewEventRepository())
+		userID := uuid.New()
+		for i := 0; i < DefaultListLimit+1; i++ {
+			err := sut.Record(ctx, &model.Event{UserID: userID, Action: model.ActionListSecrets})
+			require.NoError(t, err)
+		}
+
+		got, err := sut.ListEvents(ctx, userID, model.EventFilter{})
+
+		require.NoError(t, err)
+		assert.Len(t, got, DefaultListLimit)
+	})
+}
@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+)
+
+var ErrAccessTokenNotFound = errors.New("access token not found")
+
+type AccessTokenRepository interface {
+	CreateAccessToken(ctx context.Context, token *model.AccessToken) error
+	FindAccessToken(ctx context.Context, hash []byte) (*model.AccessToken, error)
+	ListAccessTokens(ctx context.Context, userID uuid.UUID) ([]*model.AccessToken, error)
+	// DeleteAccessToken revokes the token of the user, it fails with ErrAccessTokenNotFound
+	// if the user has no such token.
+	DeleteAccessToken(ctx context.Context, userID, tokenID uuid.UUID) error
+	// DeleteUserAccessTokens revokes all tokens of the user.
+	DeleteUserAccessTokens(ctx context.Context, userID uuid.UUID) error
+}
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+)
+
+type AccessTokenTestData struct {
+	Users uuid.UUIDs
+}
+
+type AccessTokenRepositoryContract struct {
+	NewAccessTokenRepository func() (AccessTokenRepository, func(), AccessTokenTestData)
+}
+
+func (c AccessTokenRepositoryContract) Test(t *testing.T) {
+	t.Run("create access token", func(t *testing.T) {
+		sut, tearDown, td := c.NewAccessTokenRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		token := newAccessToken(t, td.Users[0])
+		token.VaultID = uuid.New()
+		token.ExpiresAt = time.Now().Add(time.Hour).UTC().Truncate(time.Microsecond)
+
+		err := sut.CreateAccessToken(ctx, token)
+
+		require.NoError(t, err)
+		got, err := sut.FindAccessToken(ctx, token.Hash)
+		require.NoError(t, err)
+		assert.Equal(t, token, got)
+	})
+	t.Run("create access token that never expires", func(t *testing.T) {
+		sut, tearDown, td := c.NewAccessTokenRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		token := newAccessToken(t, td.Users[0])
+
+		err := sut.CreateAccessToken(ctx, token)
+
+		require.NoError(t, err)
+		got, err := sut.FindAccessToken(ctx, token.Hash)
+		require.NoError(t, err)
+		assert.Equal(t, token, got)
+	})
+	t.Run("find access token that does not exist", func(t *testing.T) {
+		sut, tearDown, _ := c.NewAccessTokenRepository()
+		t.Cleanup(tearDown)
+
+		_, err := sut.FindAccessToken(context.Background(), model.HashAccessToken("token"))
+
+		require.ErrorIs(t, err, ErrAccessTokenNotFound)
+	})
+	t.Run("list access tokens", func(t *testing.T) {
+		sut, tearDown, td := c.NewAccessTokenRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		token := newAccessToken(t, td.Users[0])
+		require.NoError(t, sut.CreateAccessToken(ctx, token))
+		another := newAccessToken(t, td.Users[1])
+		require.NoError(t, sut.CreateAccessToken(ctx, another))
+
+		got, err := sut.ListAccessTokens(ctx, td.Users[0])
+
+		require.NoError(t, err)
+		assert.Equal(t, []*model.AccessToken{token}, got)
+	})
+	t.Run("list access tokens of user without tokens", func(t *testing.T) {
+		sut, tearDown, td := c.NewAccessTokenRepository()
+		t.Cleanup(tearDown)
+
+		got, err := sut.ListAccessTokens(context.Background(), td.Users[0])
+
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+	t.Run("delete access token", func(t *testing.T) {
+		sut, tearDown, td := c.NewAccessTokenRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		token := newAccessToken(t, td.Users[0])
+		require.NoError(t, sut.CreateAccessToken(ctx, token))
+
+		err := sut.DeleteAccessToken(ctx, td.Users[0], token.ID)
+
+		require.NoError(t, err)
+		_, err = sut.FindAccessToken(ctx, token.Hash)
+		require.ErrorIs(t, err, ErrAccessTokenNotFound)
+	})
+	t.Run("delete access token of another user", func(t *testing.T) {
+		sut, tearDown, td := c.NewAccessTokenRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		token := newAccessToken(t, td.Users[0])
+		require.NoError(t, sut.CreateAccessToken(ctx, token))
+
+		err := sut.DeleteAccessToken(ctx, td.Users[1], token.ID)
+
+		require.ErrorIs(t, err, ErrAccessTokenNotFound)
+		_, err = sut.FindAccessToken(ctx, token.Hash)
+		require.NoError(t, err)
+	})
+	t.Run("delete user access tokens", func(t *testing.T) {
+		sut, tearDown, td := c.NewAccessTokenRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		first := newAccessToken(t, td.Users[0])
+		require.NoError(t, sut.CreateAccessToken(ctx, first))
+		second := newAccessToken(t, td.Users[0])
+		require.NoError(t, sut.CreateAccessToken(ctx, second))
+		other := newAccessToken(t, td.Users[1])
+		require.NoError(t, sut.CreateAccessToken(ctx, other))
+
+		err := sut.DeleteUserAccessTokens(ctx, td.Users[0])
+
+		require.NoError(t, err)
+		got, err := sut.ListAccessTokens(ctx, td.Users[0])
+		require.NoError(t, err)
+		assert.Empty(t, got)
+		_, err = sut.FindAccessToken(ctx, other.Hash)
+		require.NoError(t, err)
+	})
+	t.Run("delete access tokens of user without tokens", func(t *testing.T) {
+		sut, tearDown, td := c.NewAccessTokenRepository()
+		t.Cleanup(tearDown)
+
+		err := sut.DeleteUserAccessTokens(context.Background(), td.Users[0])
+
+		require.NoError(t, err)
+	})
+}
+
+func newAccessToken(t *testing.T, userID uuid.UUID) *model.AccessToken {
+	t.Helper()
+
+	token := &model.AccessToken{
+		UserID: userID,
+		Name:   "ci",
+		Scope:  model.ScopeRead,
+	}
+	_, err := model.NewAccessToken(token)
+	require.NoError(t, err)
+	return token
+}
@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+var (
+	ErrAccessTokenNameIsEmpty = errors.New("access token name is empty")
+	ErrInvalidAccessScope     = errors.New("invalid access scope")
+	ErrAccessTokenExpiry      = errors.New("access token expiry is in the past")
+)
+
+// AccessTokenService manages personal access tokens automation reads and writes secrets with
+// instead of the password of the user.
+type AccessTokenService interface {
+	// CreateAccessToken stores the token of the user and returns it, the token is never shown again.
+	// The user confirms the creation with the current password, it fails with ErrInvalidPassword otherwise.
+	CreateAccessToken(ctx context.Context, token *model.AccessToken, password string) (string, error)
+	ListAccessTokens(ctx context.Context, userID uuid.UUID) ([]*model.AccessToken, error)
+	RevokeAccessToken(ctx context.Context, userID, tokenID uuid.UUID) error
+	// VerifyAccessToken returns the grant of the token, it fails with utils.ErrInvalidAccessToken
+	// if the token is unknown, expired or its user is deleted.
+	VerifyAccessToken(ctx context.Context, token string) (*utils.AccessGrant, error)
+}
@@ -10,10 +10,67 @@ import (
 )
 
 var (
-	ErrInvalidPassword = errors.New("invalid password")
+	ErrInvalidPassword     = errors.New("invalid password")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrTwoFactorEnabled    = errors.New("two factor has already been enabled")
+	ErrInvalidCode         = errors.New("invalid code")
+	ErrOrganizationOwner   = errors.New("user owns organizations")
 )
 
 type AuthService interface {
 	Register(ctx context.Context, user *model.User) (uuid.UUID, error)
+	// Login returns ID of the user with the email and password. The ID is returned along with ErrInvalidPassword
+	// as well, so that the failed login is recorded to the audit log of the user.
 	Login(ctx context.Context, user *model.User) (uuid.UUID, error)
+	// DeleteAccount deletes the user and shreds data of the user. The user confirms the deletion by the password,
+	// and by the code of the second factor if it is enabled. It fails with ErrOrganizationOwner
+	// while the user owns an organization.
+	DeleteAccount(ctx context.Context, userID uuid.UUID, password, code string) error
+	// ChangePassword replaces the password of the user who knows the current one and revokes the sessions of the user.
+	ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error
+	// ResetPassword replaces the password of the user who has forgotten it by a recovery code of the second factor
+	// and revokes the sessions of the user. The code is used once.
+	ResetPassword(ctx context.Context, email, recoveryCode, newPassword string) (uuid.UUID, error)
+	// StartSession starts the session of the logged in user.
+	StartSession(ctx context.Context, userID uuid.UUID) (*model.SessionGrant, error)
+	// RefreshSession exchanges the refresh token for the next one of the same session.
+	RefreshSession(ctx context.Context, refreshToken string) (*model.SessionGrant, error)
+	EndSession(ctx context.Context, sessionID uuid.UUID) error
+	IsSessionRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error)
+	IsTwoFactorEnabled(ctx context.Context, userID uuid.UUID) (bool, error)
+	// EnrollTwoFactor starts enrollment of the second factor, it is enabled once the user confirms it.
+	EnrollTwoFactor(ctx context.Context, userID uuid.UUID) (*model.TwoFactorEnrollment, error)
+	ConfirmTwoFactor(ctx context.Context, userID uuid.UUID, code string) error
+	// VerifySecondFactor accepts the code of the authenticator app or a recovery code that has not been used.
+	VerifySecondFactor(ctx context.Context, userID uuid.UUID, code string) error
+}
+
+// UserDataShredder irreversibly destroys data owned by a user. The data of the storage is deleted
+// within the transaction of the context. The returned function destroys the rest of the data kept
+// out of the storage, such as blobs and keys, it is called once the transaction is committed.
+type UserDataShredder interface {
+	DeleteUserData(ctx context.Context, userID uuid.UUID) (func(ctx context.Context) error, error)
+}
+
+// UserDataShredders destroys data of a user by each of the shredders in turn.
+type UserDataShredders []UserDataShredder
+
+func (s UserDataShredders) DeleteUserData(ctx context.Context, userID uuid.UUID) (func(ctx context.Context) error,
+	error) {
+	cleanups := make([]func(ctx context.Context) error, 0, len(s))
+	for _, shredder := range s {
+		cleanup, err := shredder.DeleteUserData(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		cleanups = append(cleanups, cleanup)
+	}
+
+	return func(ctx context.Context) error {
+		errs := make([]error, 0, len(cleanups))
+		for _, cleanup := range cleanups {
+			errs = append(errs, cleanup(ctx))
+		}
+		return errors.Join(errs...)
+	}, nil
 }
@@ -0,0 +1,194 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	modelAudit "github.com/nestjam/goph-keeper/internal/audit/model"
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+const (
+	tokenParam = "token"
+
+	msgTokenNameEmpty = "token name is empty"
+	msgInvalidScope   = "invalid token scope"
+	msgTokenExpiry    = "token expiry is in the past"
+	msgTokenNotFound  = "token not found"
+)
+
+// CreateAccessTokenRequest describes the personal access token. The token is accepted in all vaults of the user
+// if the vault is not set and never expires if the expiry is not set. The current password of the user
+// confirms the creation.
+type CreateAccessTokenRequest struct {
+	ExpiresAt       time.Time         `json:"expires_at"`
+	CurrentPassword string            `json:"current_password"`
+	Name            string            `json:"name"`
+	Scope           model.AccessScope `json:"scope"`
+	VaultID         uuid.UUID         `json:"vault_id"`
+}
+
+type AccessTokenResponse struct {
+	CreatedAt time.Time         `json:"created_at"`
+	ExpiresAt *time.Time        `json:"expires_at,omitempty"`
+	VaultID   *uuid.UUID        `json:"vault_id,omitempty"`
+	Name      string            `json:"name"`
+	Scope     model.AccessScope `json:"scope"`
+	ID        uuid.UUID         `json:"id"`
+}
+
+// CreateAccessTokenResponse has the token itself, it is not shown again.
+type CreateAccessTokenResponse struct {
+	Token string `json:"token"`
+	AccessTokenResponse
+}
+
+// CreateAccessToken returns the new personal access token of the user who has confirmed it with the password.
+// Wrong passwords lock the creation along with the change of the password.
+func (h *AuthHandlers) CreateAccessToken() http.HandlerFunc {
+	return h.audited(modelAudit.ActionCreateToken, func(w http.ResponseWriter, r *http.Request) {
+		var req CreateAccessTokenRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, msgInvalidRequest)
+			return
+		}
+
+		ctx := r.Context()
+		userID, err := utils.UserFromContext(ctx)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		// the stolen access token does not let guess the current password
+		account := passwordKeyPrefix + userID.String()
+		if !h.reserveLogin(w, r, account) {
+			return
+		}
+
+		token := &model.AccessToken{
+			UserID:    userID,
+			Name:      req.Name,
+			Scope:     req.Scope,
+			VaultID:   req.VaultID,
+			ExpiresAt: req.ExpiresAt,
+		}
+		plain, err := h.tokens.CreateAccessToken(ctx, token, req.CurrentPassword)
+		switch {
+		case errors.Is(err, auth.ErrInvalidPassword):
+			// 401 would make the client refresh the access token, so the wrong password is forbidden
+			writeError(w, http.StatusForbidden, msgInvalidPassword)
+			return
+		case errors.Is(err, auth.ErrAccessTokenNameIsEmpty):
+			writeError(w, http.StatusBadRequest, msgTokenNameEmpty)
+			return
+		case errors.Is(err, auth.ErrInvalidAccessScope):
+			writeError(w, http.StatusBadRequest, msgInvalidScope)
+			return
+		case errors.Is(err, auth.ErrAccessTokenExpiry):
+			writeError(w, http.StatusBadRequest, msgTokenExpiry)
+			return
+		case err != nil:
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		err = h.resetLogin(r, account)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		resp := CreateAccessTokenResponse{
+			Token:               plain,
+			AccessTokenResponse: newAccessTokenResponse(token),
+		}
+		err = writeJSON(w, http.StatusCreated, resp)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+	})
+}
+
+// ListAccessTokens returns the personal access tokens of the user without the tokens themselves.
+func (h *AuthHandlers) ListAccessTokens() http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		userID, err := utils.UserFromContext(ctx)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		tokens, err := h.tokens.ListAccessTokens(ctx, userID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		resp := make([]AccessTokenResponse, 0, len(tokens))
+		for _, token := range tokens {
+			resp = append(resp, newAccessTokenResponse(token))
+		}
+		err = writeJSON(w, http.StatusOK, resp)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+	})
+}
+
+// RevokeAccessToken revokes the personal access token of the user, the token is rejected from now on.
+func (h *AuthHandlers) RevokeAccessToken() http.HandlerFunc {
+	return h.audited(modelAudit.ActionRevokeToken, func(w http.ResponseWriter, r *http.Request) {
+		tokenID, err := uuid.Parse(chi.URLParam(r, tokenParam))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, msgInvalidRequest)
+			return
+		}
+
+		ctx := r.Context()
+		userID, err := utils.UserFromContext(ctx)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		err = h.tokens.RevokeAccessToken(ctx, userID, tokenID)
+		if errors.Is(err, auth.ErrAccessTokenNotFound) {
+			writeError(w, http.StatusNotFound, msgTokenNotFound)
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func newAccessTokenResponse(token *model.AccessToken) AccessTokenResponse {
+	resp := AccessTokenResponse{
+		ID:        token.ID,
+		Name:      token.Name,
+		Scope:     token.Scope,
+		CreatedAt: token.CreatedAt,
+	}
+	if token.VaultID != uuid.Nil {
+		resp.VaultID = &token.VaultID
+	}
+	if !token.ExpiresAt.IsZero() {
+		resp.ExpiresAt = &token.ExpiresAt
+	}
+	return resp
+}
@@ -0,0 +1,206 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+	"github.com/nestjam/goph-keeper/internal/auth/repository/inmemory"
+	"github.com/nestjam/goph-keeper/internal/auth/service"
+	"github.com/nestjam/goph-keeper/internal/config"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+const tokenPassword = "1234"
+
+func TestCreateAccessToken(t *testing.T) {
+	config := config.JWTAuthConfig{
+		SignKey:       "secret",
+		TokenExpiryIn: time.Minute,
+	}
+
+	t.Run("create access token", func(t *testing.T) {
+		tokens, userID := newAccessTokenService(t)
+		sut := NewAuthHandlers(&authServiceMock{}, config, WithAccessTokens(tokens))
+		vaultID := uuid.New()
+		req := CreateAccessTokenRequest{
+			Name:            "ci",
+			Scope:           model.ScopeRead,
+			VaultID:         vaultID,
+			CurrentPassword: tokenPassword,
+		}
+		r := newCreateAccessTokenRequest(t, userID, req)
+		w := httptest.NewRecorder()
+
+		sut.CreateAccessToken().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var resp CreateAccessTokenResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.True(t, strings.HasPrefix(resp.Token, utils.AccessTokenPrefix))
+		assert.Equal(t, "ci", resp.Name)
+		assert.Equal(t, model.ScopeRead, resp.Scope)
+		assert.Equal(t, &vaultID, resp.VaultID)
+		assert.Nil(t, resp.ExpiresAt)
+		grant, err := tokens.VerifyAccessToken(context.Background(), resp.Token)
+		require.NoError(t, err)
+		assert.Equal(t, userID, grant.UserID)
+	})
+	t.Run("invalid token", func(t *testing.T) {
+		tests := []struct {
+			name string
+			msg  string
+			req  CreateAccessTokenRequest
+		}{
+			{
+				name: "name is empty",
+				req:  CreateAccessTokenRequest{Scope: model.ScopeRead, CurrentPassword: tokenPassword},
+				msg:  msgTokenNameEmpty,
+			},
+			{
+				name: "scope is invalid",
+				req:  CreateAccessTokenRequest{Name: "ci", Scope: "admin", CurrentPassword: tokenPassword},
+				msg:  msgInvalidScope,
+			},
+			{
+				name: "token is expired",
+				req: CreateAccessTokenRequest{
+					Name:            "ci",
+					Scope:           model.ScopeRead,
+					ExpiresAt:       time.Now(),
+					CurrentPassword: tokenPassword,
+				},
+				msg: msgTokenExpiry,
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tokens, userID := newAccessTokenService(t)
+				sut := NewAuthHandlers(&authServiceMock{}, config, WithAccessTokens(tokens))
+				r := newCreateAccessTokenRequest(t, userID, tt.req)
+				w := httptest.NewRecorder()
+
+				sut.CreateAccessToken().ServeHTTP(w, r)
+
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				assertErrorResponse(t, w, tt.msg)
+			})
+		}
+	})
+	t.Run("password is wrong", func(t *testing.T) {
+		tokens, userID := newAccessTokenService(t)
+		sut := NewAuthHandlers(&authServiceMock{}, config, WithAccessTokens(tokens))
+		req := CreateAccessTokenRequest{Name: "ci", Scope: model.ScopeRead, CurrentPassword: "0000"}
+		r := newCreateAccessTokenRequest(t, userID, req)
+		w := httptest.NewRecorder()
+
+		sut.CreateAccessToken().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assertErrorResponse(t, w, msgInvalidPassword)
+		got, err := tokens.ListAccessTokens(context.Background(), userID)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+	t.Run("guessing password is locked", func(t *testing.T) {
+		tokens, userID := newAccessTokenService(t)
+		policy := model.LoginPolicy{FreeFailures: 1, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour}
+		sut := NewAuthHandlers(&authServiceMock{}, config, WithAccessTokens(tokens),
+			withLoginLimiters(policy, model.AddressLoginPolicy))
+		wrong := CreateAccessTokenRequest{Name: "ci", Scope: model.ScopeRead, CurrentPassword: "0000"}
+		for i := 0; i < 2; i++ {
+			w := httptest.NewRecorder()
+			sut.CreateAccessToken().ServeHTTP(w, newCreateAccessTokenRequest(t, userID, wrong))
+			require.Equal(t, http.StatusForbidden, w.Code)
+		}
+		req := CreateAccessTokenRequest{Name: "ci", Scope: model.ScopeRead, CurrentPassword: tokenPassword}
+		r := newCreateAccessTokenRequest(t, userID, req)
+		w := httptest.NewRecorder()
+
+		sut.CreateAccessToken().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	})
+	t.Run("invalid request", func(t *testing.T) {
+		tokens, userID := newAccessTokenService(t)
+		sut := NewAuthHandlers(&authServiceMock{}, config, WithAccessTokens(tokens))
+		r := newRequestWithUser(t, strings.NewReader("{"), userID)
+		w := httptest.NewRecorder()
+
+		sut.CreateAccessToken().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assertErrorResponse(t, w, msgInvalidRequest)
+	})
+}
+
+func TestListAccessTokens(t *testing.T) {
+	config := config.JWTAuthConfig{
+		SignKey:       "secret",
+		TokenExpiryIn: time.Minute,
+	}
+
+	t.Run("list access tokens", func(t *testing.T) {
+		tokens, userID := newAccessTokenService(t)
+		expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Microsecond)
+		token := &model.AccessToken{UserID: userID, Name: "ci", Scope: model.ScopeReadWrite, ExpiresAt: expiresAt}
+		_, err := tokens.CreateAccessToken(context.Background(), token, tokenPassword)
+		require.NoError(t, err)
+		sut := NewAuthHandlers(&authServiceMock{}, config, WithAccessTokens(tokens))
+		r := newRequestWithUser(t, http.NoBody, userID)
+		w := httptest.NewRecorder()
+
+		sut.ListAccessTokens().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp []AccessTokenResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp, 1)
+		assert.Equal(t, token.ID, resp[0].ID)
+		assert.Equal(t, model.ScopeReadWrite, resp[0].Scope)
+		assert.Nil(t, resp[0].VaultID)
+		require.NotNil(t, resp[0].ExpiresAt)
+		assert.True(t, expiresAt.Equal(*resp[0].ExpiresAt))
+	})
+	t.Run("user has no tokens", func(t *testing.T) {
+		tokens, userID := newAccessTokenService(t)
+		sut := NewAuthHandlers(&authServiceMock{}, config, WithAccessTokens(tokens))
+		r := newRequestWithUser(t, http.NoBody, userID)
+		w := httptest.NewRecorder()
+
+		sut.ListAccessTokens().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, "[]", w.Body.String())
+	})
+}
+
+func newAccessTokenService(t *testing.T) (auth.AccessTokenService, uuid.UUID) {
+	t.Helper()
+
+	users := inmemory.NewUserRepository()
+	user := &model.User{Email: "user@email.com", Password: tokenPassword}
+	require.NoError(t, user.HashPassword())
+	userID, err := users.Register(context.Background(), user)
+	require.NoError(t, err)
+	return service.NewAccessTokenService(inmemory.NewAccessTokenRepository(), users), userID
+}
+
+func newCreateAccessTokenRequest(t *testing.T, userID uuid.UUID, req CreateAccessTokenRequest) *http.Request {
+	t.Helper()
+
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+	return newRequestWithUser(t, bytes.NewReader(body), userID)
+}
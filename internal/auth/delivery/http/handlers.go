@@ -3,11 +3,16 @@ package http
 import (
 	"encoding/json"
 	"io"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 
+	"github.com/nestjam/goph-keeper/internal/audit"
+	modelAudit "github.com/nestjam/goph-keeper/internal/audit/model"
 	"github.com/nestjam/goph-keeper/internal/auth"
 	"github.com/nestjam/goph-keeper/internal/auth/model"
 	"github.com/nestjam/goph-keeper/internal/config"
@@ -17,8 +22,35 @@ import (
 const (
 	applicationJSON   = "application/json"
 	contentTypeHeader = "Content-Type"
+	retryAfterHeader  = "Retry-After"
+
+	msgInvalidRequest      = "invalid request"
+	msgInvalidCredentials  = "invalid email or password"
+	msgEmailRegistered     = "email has already been registered"
+	msgInvalidChallenge    = "invalid challenge token"
+	msgInvalidPassword     = "invalid password"
+	msgPasswordEmpty       = "password is empty"
+	msgInvalidRecovery     = "invalid email or recovery code"
+	msgInvalidCode         = "invalid code"
+	msgTwoFactorEnabled    = "two factor has already been enabled"
+	msgTwoFactorNotFound   = "two factor has not been enrolled"
+	msgUserNotFound        = "user not found"
+	msgOrganizationOwner   = "user owns organizations"
+	msgInvalidRefreshToken = "invalid refresh token"
+	msgLoginLocked         = "too many failed logins"
+	msgInternalError       = "internal error"
+
+	accountKeyPrefix   = "account:"
+	twoFactorKeyPrefix = "2fa:"
+	passwordKeyPrefix  = "password:"
+	addressKeyPrefix   = "address:"
 )
 
+// ErrorResponse is the body of all error responses of the auth handlers.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
 type RegisterUserRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
@@ -29,37 +61,130 @@ type LoginUserRequest struct {
 	Password string `json:"password"`
 }
 
+// LoginChallengeResponse is returned by login if the user has enabled the second factor.
+// The session is started once the challenge token is verified along with the code.
+type LoginChallengeResponse struct {
+	ChallengeToken string `json:"challenge_token"`
+}
+
+type VerifyLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// DeleteAccountRequest confirms the deletion of the account by the password
+// and the code of the second factor if it is enabled.
+type DeleteAccountRequest struct {
+	CurrentPassword string `json:"current_password"`
+	Code            string `json:"code"`
+}
+
+// ResetPasswordRequest replaces the forgotten password by a recovery code of the second factor.
+type ResetPasswordRequest struct {
+	Email        string `json:"email"`
+	RecoveryCode string `json:"recovery_code"`
+	NewPassword  string `json:"new_password"`
+}
+
+type EnrollTwoFactorResponse struct {
+	OTPAuthURI    string   `json:"otpauth_uri"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type ConfirmTwoFactorRequest struct {
+	Code string `json:"code"`
+}
+
 type AuthHandlers struct {
 	service     auth.AuthService
+	recorder    audit.Recorder
 	cookieBaker *utils.AuthCookieBaker
+	keys        *utils.JWTKeys
+	accounts    auth.LoginLimiter
+	addresses   auth.LoginLimiter
+	tokens      auth.AccessTokenService
+}
+
+type AuthHandlersOption func(*AuthHandlers)
+
+// WithAuditRecorder makes the handlers record registrations, logins, logouts, account deletions and
+// changes of the second factor to the audit log.
+func WithAuditRecorder(recorder audit.Recorder) AuthHandlersOption {
+	return func(h *AuthHandlers) {
+		h.recorder = recorder
+	}
+}
+
+// WithJWTKeys makes the handlers sign access tokens with the active key and publish the public keys.
+func WithJWTKeys(keys *utils.JWTKeys) AuthHandlersOption {
+	return func(h *AuthHandlers) {
+		h.keys = keys
+	}
 }
 
-func NewAuthHandlers(service auth.AuthService, authConfig config.JWTAuthConfig) *AuthHandlers {
-	return &AuthHandlers{
-		service:     service,
-		cookieBaker: utils.NewAuthCookieBaker(authConfig),
+// WithLoginLimiters makes the handlers answer 429 to logins of an account or from a client address
+// that are locked after too many failures. The failed second factor codes lock the login of the account too.
+func WithLoginLimiters(accounts, addresses auth.LoginLimiter) AuthHandlersOption {
+	return func(h *AuthHandlers) {
+		h.accounts = accounts
+		h.addresses = addresses
 	}
 }
 
+// WithAccessTokens makes the handlers manage personal access tokens of the user.
+func WithAccessTokens(tokens auth.AccessTokenService) AuthHandlersOption {
+	return func(h *AuthHandlers) {
+		h.tokens = tokens
+	}
+}
+
+func NewAuthHandlers(service auth.AuthService, authConfig config.JWTAuthConfig,
+	opts ...AuthHandlersOption) *AuthHandlers {
+	h := &AuthHandlers{
+		service: service,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	// access tokens of the ended sessions are rejected before they expire
+	bakerOpts := []utils.AuthCookieBakerOption{utils.WithSessionChecker(service)}
+	if h.keys != nil {
+		bakerOpts = append(bakerOpts, utils.WithJWTKeys(h.keys))
+	}
+	h.cookieBaker = utils.NewAuthCookieBaker(authConfig, bakerOpts...)
+	return h
+}
+
 //nolint:dupl //register method
 func (h *AuthHandlers) Register() http.HandlerFunc {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	return h.audited(modelAudit.ActionRegister, func(w http.ResponseWriter, r *http.Request) {
 		user, err := getUser(r.Body)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, msgInvalidRequest)
 			return
 		}
 
 		ctx := r.Context()
 		userID, err := h.service.Register(ctx, user)
+		if errors.Is(err, auth.ErrUserWithEmailIsRegistered) {
+			writeError(w, http.StatusConflict, msgEmailRegistered)
+			return
+		}
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
+			writeError(w, http.StatusInternalServerError, msgInternalError)
 			return
 		}
+		audit.SetUser(ctx, userID)
 
-		err = setAuthCookie(w, userID, h.cookieBaker)
+		err = h.startSession(w, r, userID)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
+			writeError(w, http.StatusInternalServerError, msgInternalError)
 			return
 		}
 
@@ -69,23 +194,53 @@ func (h *AuthHandlers) Register() http.HandlerFunc {
 
 //nolint:dupl //login method
 func (h *AuthHandlers) Login() http.HandlerFunc {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	return h.audited(modelAudit.ActionLogin, func(w http.ResponseWriter, r *http.Request) {
 		user, err := getUser(r.Body)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, msgInvalidRequest)
+			return
+		}
+
+		account := accountKeyPrefix + user.Email
+		if !h.reserveLogin(w, r, account) {
 			return
 		}
 
 		ctx := r.Context()
 		userID, err := h.service.Login(ctx, user)
+		if errors.Is(err, auth.ErrInvalidPassword) {
+			audit.SetUser(ctx, userID)
+		}
+		// unknown email and wrong password are answered the same, so the response does not tell who is registered
+		if errors.Is(err, auth.ErrInvalidPassword) || errors.Is(err, auth.ErrUserIsNotRegistered) {
+			writeError(w, http.StatusUnauthorized, msgInvalidCredentials)
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+		audit.SetUser(ctx, userID)
+
+		err = h.resetLogin(r, account)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
+			writeError(w, http.StatusInternalServerError, msgInternalError)
 			return
 		}
 
-		err = setAuthCookie(w, userID, h.cookieBaker)
+		enabled, err := h.service.IsTwoFactorEnabled(ctx, userID)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+		if enabled {
+			h.challenge(w, userID)
+			return
+		}
+
+		err = h.startSession(w, r, userID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
 			return
 		}
 
@@ -93,18 +248,479 @@ func (h *AuthHandlers) Login() http.HandlerFunc {
 	})
 }
 
-func setAuthCookie(w http.ResponseWriter, userID uuid.UUID, baker *utils.AuthCookieBaker) error {
-	const op = "set auth cookie"
+// VerifyLogin completes the login of the user who has enabled the second factor.
+// The challenge token returned by login is exchanged for the session along with the code of the second factor.
+func (h *AuthHandlers) VerifyLogin() http.HandlerFunc {
+	return h.audited(modelAudit.ActionVerifyLogin, func(w http.ResponseWriter, r *http.Request) {
+		var req VerifyLoginRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, msgInvalidRequest)
+			return
+		}
+
+		userID, err := h.cookieBaker.ParseChallenge(req.ChallengeToken)
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, msgInvalidChallenge)
+			return
+		}
+		ctx := r.Context()
+		audit.SetUser(ctx, userID)
+
+		account := twoFactorKeyPrefix + userID.String()
+		if !h.reserveLogin(w, r, account) {
+			return
+		}
+
+		err = h.service.VerifySecondFactor(ctx, userID, req.Code)
+		if errors.Is(err, auth.ErrInvalidCode) {
+			writeError(w, http.StatusUnauthorized, msgInvalidCode)
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		err = h.resetLogin(r, account)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		err = h.startSession(w, r, userID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// EnrollTwoFactor returns the URI the authenticator app is set up by and the recovery codes of the user.
+// The second factor is required at login once the user confirms it.
+func (h *AuthHandlers) EnrollTwoFactor() http.HandlerFunc {
+	return h.audited(modelAudit.ActionEnrollTwoFA, func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		userID, err := utils.UserFromContext(ctx)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		enrollment, err := h.service.EnrollTwoFactor(ctx, userID)
+		if errors.Is(err, auth.ErrTwoFactorEnabled) {
+			writeError(w, http.StatusConflict, msgTwoFactorEnabled)
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		resp := EnrollTwoFactorResponse{
+			OTPAuthURI:    enrollment.URI,
+			RecoveryCodes: enrollment.RecoveryCodes,
+		}
+		err = writeJSON(w, http.StatusOK, resp)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+	})
+}
+
+// ConfirmTwoFactor enables the second factor of the user by the code of the authenticator app.
+func (h *AuthHandlers) ConfirmTwoFactor() http.HandlerFunc {
+	return h.audited(modelAudit.ActionConfirmTwoFA, func(w http.ResponseWriter, r *http.Request) {
+		var req ConfirmTwoFactorRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, msgInvalidRequest)
+			return
+		}
+
+		ctx := r.Context()
+		userID, err := utils.UserFromContext(ctx)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		err = h.service.ConfirmTwoFactor(ctx, userID, req.Code)
+		switch {
+		case errors.Is(err, auth.ErrInvalidCode):
+			writeError(w, http.StatusBadRequest, msgInvalidCode)
+		case errors.Is(err, auth.ErrTwoFactorNotFound):
+			writeError(w, http.StatusNotFound, msgTwoFactorNotFound)
+		case errors.Is(err, auth.ErrTwoFactorEnabled):
+			writeError(w, http.StatusConflict, msgTwoFactorEnabled)
+		case err != nil:
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	})
+}
+
+// ChangePassword replaces the password of the user. All sessions of the user are ended,
+// the new session is started for the client that has changed the password.
+func (h *AuthHandlers) ChangePassword() http.HandlerFunc {
+	return h.audited(modelAudit.ActionChangePassword, func(w http.ResponseWriter, r *http.Request) {
+		var req ChangePasswordRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, msgInvalidRequest)
+			return
+		}
+
+		ctx := r.Context()
+		userID, err := utils.UserFromContext(ctx)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		// the stolen access token does not let guess the current password
+		account := passwordKeyPrefix + userID.String()
+		if !h.reserveLogin(w, r, account) {
+			return
+		}
+
+		err = h.service.ChangePassword(ctx, userID, req.CurrentPassword, req.NewPassword)
+		if errors.Is(err, auth.ErrUserPasswordIsEmpty) {
+			writeError(w, http.StatusBadRequest, msgPasswordEmpty)
+			return
+		}
+		if errors.Is(err, auth.ErrInvalidPassword) {
+			// 401 would make the client refresh the access token, so the wrong password is forbidden
+			writeError(w, http.StatusForbidden, msgInvalidPassword)
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		h.completePasswordChange(w, r, account, userID)
+	})
+}
+
+// ResetPassword replaces the forgotten password of the user by a recovery code of the second factor.
+// All sessions of the user are ended. No session is started, the user logs in with the new password
+// and the second factor, so the recovery code alone does not let in.
+func (h *AuthHandlers) ResetPassword() http.HandlerFunc {
+	return h.audited(modelAudit.ActionResetPassword, func(w http.ResponseWriter, r *http.Request) {
+		var req ResetPasswordRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, msgInvalidRequest)
+			return
+		}
+
+		// the reset is limited along with the login, so it does not let guess recovery codes instead of passwords
+		account := accountKeyPrefix + req.Email
+		if !h.reserveLogin(w, r, account) {
+			return
+		}
+
+		ctx := r.Context()
+		userID, err := h.service.ResetPassword(ctx, req.Email, req.RecoveryCode, req.NewPassword)
+		if errors.Is(err, auth.ErrUserPasswordIsEmpty) {
+			writeError(w, http.StatusBadRequest, msgPasswordEmpty)
+			return
+		}
+		if errors.Is(err, auth.ErrInvalidCode) {
+			writeError(w, http.StatusUnauthorized, msgInvalidRecovery)
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+		audit.SetUser(ctx, userID)
 
-	cookie, err := baker.BakeCookie(userID)
+		err = h.resetLogin(r, account)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// DeleteAccount deletes the account of the user confirmed by the password, and by the code of the second factor
+// if it is enabled. A stolen access token alone does not let delete the account.
+func (h *AuthHandlers) DeleteAccount() http.HandlerFunc {
+	return h.audited(modelAudit.ActionDeleteAccount, func(w http.ResponseWriter, r *http.Request) {
+		var req DeleteAccountRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, msgInvalidRequest)
+			return
+		}
+
+		ctx := r.Context()
+		userID, err := utils.UserFromContext(ctx)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		// the deletion is limited along with the password change, so it does not let guess the password either
+		account := passwordKeyPrefix + userID.String()
+		if !h.reserveLogin(w, r, account) {
+			return
+		}
+
+		err = h.service.DeleteAccount(ctx, userID, req.CurrentPassword, req.Code)
+		if errors.Is(err, auth.ErrUserIsNotRegistered) {
+			writeError(w, http.StatusNotFound, msgUserNotFound)
+			return
+		}
+		if errors.Is(err, auth.ErrInvalidPassword) {
+			writeError(w, http.StatusForbidden, msgInvalidPassword)
+			return
+		}
+		if errors.Is(err, auth.ErrInvalidCode) {
+			writeError(w, http.StatusForbidden, msgInvalidCode)
+			return
+		}
+		if errors.Is(err, auth.ErrOrganizationOwner) {
+			writeError(w, http.StatusConflict, msgOrganizationOwner)
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		err = h.resetLogin(r, account)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		h.expireCookies(w)
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// Refresh exchanges the refresh token of the cookie for the next access and refresh tokens of the session.
+// The client drops the tokens if the refresh token is not valid anymore.
+func (h *AuthHandlers) Refresh() http.HandlerFunc {
+	return h.audited(modelAudit.ActionRefresh, func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(utils.RefreshCookieName)
+		if err != nil {
+			h.expireCookies(w)
+			writeError(w, http.StatusUnauthorized, msgInvalidRefreshToken)
+			return
+		}
+
+		ctx := r.Context()
+		grant, err := h.service.RefreshSession(ctx, cookie.Value)
+		if errors.Is(err, auth.ErrInvalidRefreshToken) {
+			h.expireCookies(w)
+			writeError(w, http.StatusUnauthorized, msgInvalidRefreshToken)
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+		audit.SetUser(ctx, grant.UserID)
+
+		err = h.setSessionCookies(w, grant)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// Logout ends the session of the access token, so neither the access token nor the refresh token work anymore.
+func (h *AuthHandlers) Logout() http.HandlerFunc {
+	return h.audited(modelAudit.ActionLogout, func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		sessionID, err := utils.SessionFromContext(ctx)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		err = h.service.EndSession(ctx, sessionID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+
+		h.expireCookies(w)
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// JWKS publishes the public keys access tokens are verified with, the set is empty for HS256 tokens.
+func (h *AuthHandlers) JWKS() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := writeJSON(w, http.StatusOK, h.cookieBaker.PublicKeys())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return
+		}
+	}
+}
+
+// audited records the action of the handler to the audit log if the recorder is set.
+func (h *AuthHandlers) audited(action modelAudit.Action, next http.HandlerFunc) http.HandlerFunc {
+	if h.recorder == nil {
+		return next
+	}
+	return audit.Handler(h.recorder, action, next)
+}
+
+// reserveLogin counts the login of the account and from the client address as failed before the credentials
+// are checked, so concurrent logins do not pass the limit. It answers 429 with the time to wait and returns false
+// if the login is locked.
+func (h *AuthHandlers) reserveLogin(w http.ResponseWriter, r *http.Request, account string) bool {
+	if h.accounts == nil {
+		return true
+	}
+
+	ctx := r.Context()
+	address := clientAddress(r)
+	wait, err := h.addresses.Reserve(ctx, address)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, msgInternalError)
+		return false
+	}
+	if wait == 0 {
+		wait, err = h.accounts.Reserve(ctx, account)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, msgInternalError)
+			return false
+		}
+		if wait != 0 {
+			// the login to the locked account is not counted from the address
+			err = h.addresses.Release(ctx, address)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, msgInternalError)
+				return false
+			}
+		}
+	}
+	if wait == 0 {
+		return true
+	}
+
+	seconds := int(math.Ceil(wait.Seconds()))
+	w.Header().Set(retryAfterHeader, strconv.Itoa(seconds))
+	writeError(w, http.StatusTooManyRequests, msgLoginLocked)
+	return false
+}
+
+// resetLogin forgets the failures of the account and uncounts the login from the client address. The earlier
+// failures from the address are kept, so a login to own account does not let guess passwords of others.
+func (h *AuthHandlers) resetLogin(r *http.Request, account string) error {
+	const op = "reset login"
+
+	if h.accounts == nil {
+		return nil
+	}
+
+	ctx := r.Context()
+	err := h.accounts.Reset(ctx, account)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	err = h.addresses.Release(ctx, clientAddress(r))
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// clientAddress returns the limiter key of the address the request is sent from.
+func clientAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return addressKeyPrefix + r.RemoteAddr
+	}
+	return addressKeyPrefix + host
+}
+
+// completePasswordChange forgets the failures of the account and starts the session with the new password.
+func (h *AuthHandlers) completePasswordChange(w http.ResponseWriter, r *http.Request, account string,
+	userID uuid.UUID) {
+	err := h.resetLogin(r, account)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, msgInternalError)
+		return
+	}
+
+	err = h.startSession(w, r, userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, msgInternalError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// startSession starts the session of the user and sets its cookies.
+func (h *AuthHandlers) startSession(w http.ResponseWriter, r *http.Request, userID uuid.UUID) error {
+	const op = "start session"
+
+	grant, err := h.service.StartSession(r.Context(), userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = h.setSessionCookies(w, grant)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// challenge writes the challenge token the user verifies the second factor with, the session is not started yet.
+func (h *AuthHandlers) challenge(w http.ResponseWriter, userID uuid.UUID) {
+	token, err := h.cookieBaker.BakeChallenge(userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, msgInternalError)
+		return
+	}
+
+	err = writeJSON(w, http.StatusAccepted, LoginChallengeResponse{ChallengeToken: token})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, msgInternalError)
+		return
+	}
+}
+
+func (h *AuthHandlers) setSessionCookies(w http.ResponseWriter, grant *model.SessionGrant) error {
+	const op = "set session cookies"
+
+	cookie, err := h.cookieBaker.BakeCookie(grant.UserID, grant.SessionID)
 	if err != nil {
 		return errors.Wrap(err, op)
 	}
 	http.SetCookie(w, cookie)
+	http.SetCookie(w, h.cookieBaker.BakeRefreshCookie(grant.RefreshToken))
 
 	return nil
 }
 
+func (h *AuthHandlers) expireCookies(w http.ResponseWriter) {
+	http.SetCookie(w, h.cookieBaker.ExpiredCookie())
+	http.SetCookie(w, h.cookieBaker.ExpiredRefreshCookie())
+}
+
 func getUser(r io.Reader) (*model.User, error) {
 	const op = "get user"
 	var userRequest RegisterUserRequest
@@ -119,3 +735,23 @@ func getUser(r io.Reader) (*model.User, error) {
 	}
 	return user, nil
 }
+
+func writeJSON(w http.ResponseWriter, statusCode int, v any) error {
+	const op = "write json"
+
+	content, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	w.Header().Set(contentTypeHeader, applicationJSON)
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(content)
+	return nil
+}
+
+// writeError writes the error response with the message the client can show to the user.
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	// the response of the struct is always marshaled
+	_ = writeJSON(w, statusCode, ErrorResponse{Error: message})
+}
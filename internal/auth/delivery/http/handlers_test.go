@@ -3,19 +3,34 @@ package http
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-chi/jwtauth/v5"
 	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pquerna/otp/totp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	modelAudit "github.com/nestjam/goph-keeper/internal/audit/model"
+	auditMemory "github.com/nestjam/goph-keeper/internal/audit/repository/inmemory"
+	auditService "github.com/nestjam/goph-keeper/internal/audit/service"
+	"github.com/nestjam/goph-keeper/internal/auth"
 	"github.com/nestjam/goph-keeper/internal/auth/model"
 	"github.com/nestjam/goph-keeper/internal/auth/repository/inmemory"
 	"github.com/nestjam/goph-keeper/internal/auth/service"
@@ -36,7 +51,8 @@ func TestRegister(t *testing.T) {
 
 	t.Run("regiser new user", func(t *testing.T) {
 		repo := inmemory.NewUserRepository()
-		service := service.NewAuthService(repo)
+		service := service.NewAuthService(repo, inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{})
 		sut := NewAuthHandlers(service, config)
 		r := newRegisterUserRequest(t, "/", email, password)
 		w := httptest.NewRecorder()
@@ -50,7 +66,8 @@ func TestRegister(t *testing.T) {
 	})
 	t.Run("add jwt cookie on success registration", func(t *testing.T) {
 		repo := inmemory.NewUserRepository()
-		service := service.NewAuthService(repo)
+		service := service.NewAuthService(repo, inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{})
 		sut := NewAuthHandlers(service, config)
 		r := newRegisterUserRequest(t, "/", email, password)
 		w := httptest.NewRecorder()
@@ -66,7 +83,8 @@ func TestRegister(t *testing.T) {
 	})
 	t.Run("register request contains invalid json", func(t *testing.T) {
 		repo := inmemory.NewUserRepository()
-		service := service.NewAuthService(repo)
+		service := service.NewAuthService(repo, inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{})
 		sut := NewAuthHandlers(service, config)
 		r := newRegisterUserInvalidRequest(t)
 		w := httptest.NewRecorder()
@@ -75,76 +93,1036 @@ func TestRegister(t *testing.T) {
 
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
+	t.Run("email has already been registered", func(t *testing.T) {
+		repo := inmemory.NewUserRepository()
+		registerUser(t, context.Background(), email, password, repo)
+		service := service.NewAuthService(repo, inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{})
+		sut := NewAuthHandlers(service, config)
+		r := newRegisterUserRequest(t, "/", email, password)
+		w := httptest.NewRecorder()
+
+		sut.Register().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assertErrorResponse(t, w, msgEmailRegistered)
+		assert.Empty(t, w.Header().Values("Set-Cookie"))
+	})
 	t.Run("register failed", func(t *testing.T) {
 		service := &authServiceMock{}
 		service.RegisterFunc = func(ctx context.Context, user *model.User) (uuid.UUID, error) {
 			return uuid.Nil, errors.New("failed to register")
 		}
 		sut := NewAuthHandlers(service, config)
-		r := newRegisterUserRequest(t, "/", email, password)
+		r := newRegisterUserRequest(t, "/", email, password)
+		w := httptest.NewRecorder()
+
+		sut.Register().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestLogin(t *testing.T) {
+	const (
+		email    = "user@email.com"
+		password = "1234"
+	)
+
+	config := config.JWTAuthConfig{
+		SignKey:       "secret",
+		TokenExpiryIn: time.Minute,
+	}
+
+	t.Run("login registered user", func(t *testing.T) {
+		ctx := context.Background()
+		user := &model.User{Email: email, Password: password}
+		err := user.HashPassword()
+		require.NoError(t, err)
+		repo := inmemory.NewUserRepository()
+		user.ID, err = repo.Register(ctx, user)
+		require.NoError(t, err)
+		service := service.NewAuthService(repo, inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{})
+		sut := NewAuthHandlers(service, config)
+		r := newLoginUserRequest(t, "/", email, password)
+		w := httptest.NewRecorder()
+
+		sut.Login().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assertAuthToken(t, w, config, user.ID)
+	})
+	t.Run("login request contains invalid json", func(t *testing.T) {
+		repo := inmemory.NewUserRepository()
+		service := service.NewAuthService(repo, inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{})
+		sut := NewAuthHandlers(service, config)
+		r := newLoginUserInvalidRequest(t)
+		w := httptest.NewRecorder()
+
+		sut.Login().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+	t.Run("unknown email and wrong password are answered the same", func(t *testing.T) {
+		repo := inmemory.NewUserRepository()
+		registerUser(t, context.Background(), email, password, repo)
+		service := service.NewAuthService(repo, inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{})
+		sut := NewAuthHandlers(service, config)
+		unknown := httptest.NewRecorder()
+		wrong := httptest.NewRecorder()
+
+		sut.Login().ServeHTTP(unknown, newLoginUserRequest(t, "/", "unknown@email.com", password))
+		sut.Login().ServeHTTP(wrong, newLoginUserRequest(t, "/", email, "wrong password"))
+
+		assert.Equal(t, http.StatusUnauthorized, unknown.Code)
+		assertErrorResponse(t, unknown, msgInvalidCredentials)
+		assert.Equal(t, http.StatusUnauthorized, wrong.Code)
+		assertErrorResponse(t, wrong, msgInvalidCredentials)
+		assert.Empty(t, wrong.Header().Values("Set-Cookie"))
+	})
+	t.Run("login failed", func(t *testing.T) {
+		service := &authServiceMock{}
+		service.LoginFunc = func(ctx context.Context, user *model.User) (uuid.UUID, error) {
+			return uuid.Nil, errors.New("failed to login")
+		}
+		sut := NewAuthHandlers(service, config)
+		r := newLoginUserRequest(t, "/", email, password)
+		w := httptest.NewRecorder()
+
+		sut.Login().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assertErrorResponse(t, w, msgInternalError)
+	})
+}
+
+func TestAuthHandlers_Audit(t *testing.T) {
+	const (
+		email    = "user@email.com"
+		password = "1234"
+	)
+
+	config := config.JWTAuthConfig{
+		SignKey:       "secret",
+		TokenExpiryIn: time.Minute,
+	}
+
+	t.Run("login is recorded", func(t *testing.T) {
+		ctx := context.Background()
+		user := &model.User{Email: email, Password: password}
+		err := user.HashPassword()
+		require.NoError(t, err)
+		repo := inmemory.NewUserRepository()
+		user.ID, err = repo.Register(ctx, user)
+		require.NoError(t, err)
+		events := auditMemory.NewEventRepository()
+		service := service.NewAuthService(repo, inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{})
+		sut := NewAuthHandlers(service, config, WithAuditRecorder(auditService.NewAuditService(events)))
+		r := newLoginUserRequest(t, "/", email, password)
+		w := httptest.NewRecorder()
+
+		sut.Login().ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		got, err := events.ListEvents(ctx, modelAudit.EventFilter{})
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, modelAudit.ActionLogin, got[0].Action)
+		assert.Equal(t, modelAudit.OutcomeSuccess, got[0].Outcome)
+		assert.Equal(t, user.ID, got[0].UserID)
+	})
+	t.Run("login with wrong password is recorded with user", func(t *testing.T) {
+		ctx := context.Background()
+		user := &model.User{Email: email, Password: password}
+		err := user.HashPassword()
+		require.NoError(t, err)
+		repo := inmemory.NewUserRepository()
+		user.ID, err = repo.Register(ctx, user)
+		require.NoError(t, err)
+		events := auditMemory.NewEventRepository()
+		service := service.NewAuthService(repo, inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{})
+		sut := NewAuthHandlers(service, config, WithAuditRecorder(auditService.NewAuditService(events)))
+		r := newLoginUserRequest(t, "/", email, "wrong password")
+		w := httptest.NewRecorder()
+
+		sut.Login().ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		got, err := events.ListEvents(ctx, modelAudit.EventFilter{})
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, modelAudit.OutcomeFailure, got[0].Outcome)
+		assert.Equal(t, user.ID, got[0].UserID)
+	})
+	t.Run("failed login is recorded", func(t *testing.T) {
+		service := &authServiceMock{}
+		service.LoginFunc = func(ctx context.Context, user *model.User) (uuid.UUID, error) {
+			return uuid.Nil, errors.New("failed to login")
+		}
+		events := auditMemory.NewEventRepository()
+		sut := NewAuthHandlers(service, config, WithAuditRecorder(auditService.NewAuditService(events)))
+		r := newLoginUserRequest(t, "/", email, password)
+		w := httptest.NewRecorder()
+
+		sut.Login().ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		got, err := events.ListEvents(context.Background(), modelAudit.EventFilter{})
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, modelAudit.OutcomeFailure, got[0].Outcome)
+	})
+}
+
+func TestDeleteAccount(t *testing.T) {
+	config := config.JWTAuthConfig{
+		SignKey:       "secret",
+		TokenExpiryIn: time.Minute,
+	}
+
+	t.Run("delete account", func(t *testing.T) {
+		ctx := context.Background()
+		repo := inmemory.NewUserRepository()
+		user := &model.User{Email: "user@email.com", Password: "1234"}
+		require.NoError(t, user.HashPassword())
+		userID, err := repo.Register(ctx, user)
+		require.NoError(t, err)
+		shredder := &auth.UserDataShredderMock{
+			DeleteUserDataFunc: func(ctx context.Context, userID uuid.UUID) (func(context.Context) error, error) {
+				return func(context.Context) error { return nil }, nil
+			},
+		}
+		service := service.NewAuthService(repo, inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), shredder)
+		sut := NewAuthHandlers(service, config)
+		r := newDeleteAccountRequestWithUser(t, userID, DeleteAccountRequest{CurrentPassword: "1234"})
+		w := httptest.NewRecorder()
+
+		sut.DeleteAccount().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assertExpiredAuthCookie(t, w)
+	})
+	t.Run("password and code are passed to service", func(t *testing.T) {
+		var gotPassword, gotCode string
+		service := &authServiceMock{
+			DeleteAccountFunc: func(ctx context.Context, userID uuid.UUID, password, code string) error {
+				gotPassword, gotCode = password, code
+				return nil
+			},
+		}
+		sut := NewAuthHandlers(service, config)
+		req := DeleteAccountRequest{CurrentPassword: "1234", Code: "123456"}
+		r := newDeleteAccountRequestWithUser(t, uuid.New(), req)
+		w := httptest.NewRecorder()
+
+		sut.DeleteAccount().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "1234", gotPassword)
+		assert.Equal(t, "123456", gotCode)
+	})
+	t.Run("invalid password", func(t *testing.T) {
+		service := &authServiceMock{
+			DeleteAccountFunc: func(ctx context.Context, userID uuid.UUID, password, code string) error {
+				return auth.ErrInvalidPassword
+			},
+		}
+		sut := NewAuthHandlers(service, config)
+		r := newDeleteAccountRequestWithUser(t, uuid.New(), DeleteAccountRequest{CurrentPassword: "5678"})
+		w := httptest.NewRecorder()
+
+		sut.DeleteAccount().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+	t.Run("invalid code of second factor", func(t *testing.T) {
+		service := &authServiceMock{
+			DeleteAccountFunc: func(ctx context.Context, userID uuid.UUID, password, code string) error {
+				return auth.ErrInvalidCode
+			},
+		}
+		sut := NewAuthHandlers(service, config)
+		req := DeleteAccountRequest{CurrentPassword: "1234", Code: "000000"}
+		r := newDeleteAccountRequestWithUser(t, uuid.New(), req)
+		w := httptest.NewRecorder()
+
+		sut.DeleteAccount().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+	t.Run("invalid request", func(t *testing.T) {
+		service := &authServiceMock{}
+		sut := NewAuthHandlers(service, config)
+		r := httptest.NewRequest(http.MethodDelete, "/", strings.NewReader("{"))
+		w := httptest.NewRecorder()
+
+		sut.DeleteAccount().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+	t.Run("user is not registered", func(t *testing.T) {
+		service := &authServiceMock{
+			DeleteAccountFunc: func(ctx context.Context, userID uuid.UUID, password, code string) error {
+				return auth.ErrUserIsNotRegistered
+			},
+		}
+		sut := NewAuthHandlers(service, config)
+		r := newDeleteAccountRequestWithUser(t, uuid.New(), DeleteAccountRequest{CurrentPassword: "1234"})
+		w := httptest.NewRecorder()
+
+		sut.DeleteAccount().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+	t.Run("user owns organizations", func(t *testing.T) {
+		service := &authServiceMock{
+			DeleteAccountFunc: func(ctx context.Context, userID uuid.UUID, password, code string) error {
+				return auth.ErrOrganizationOwner
+			},
+		}
+		sut := NewAuthHandlers(service, config)
+		r := newDeleteAccountRequestWithUser(t, uuid.New(), DeleteAccountRequest{CurrentPassword: "1234"})
+		w := httptest.NewRecorder()
+
+		sut.DeleteAccount().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+	t.Run("delete account failed", func(t *testing.T) {
+		service := &authServiceMock{
+			DeleteAccountFunc: func(ctx context.Context, userID uuid.UUID, password, code string) error {
+				return errors.New("failed")
+			},
+		}
+		sut := NewAuthHandlers(service, config)
+		r := newDeleteAccountRequestWithUser(t, uuid.New(), DeleteAccountRequest{CurrentPassword: "1234"})
+		w := httptest.NewRecorder()
+
+		sut.DeleteAccount().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+	t.Run("user is missing in context", func(t *testing.T) {
+		service := &authServiceMock{}
+		sut := NewAuthHandlers(service, config)
+		r := httptest.NewRequest(http.MethodDelete, "/", strings.NewReader("{}"))
+		w := httptest.NewRecorder()
+
+		sut.DeleteAccount().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestRefresh(t *testing.T) {
+	config := config.JWTAuthConfig{
+		SignKey:              "secret",
+		TokenExpiryIn:        time.Minute,
+		RefreshTokenExpiryIn: time.Hour,
+	}
+
+	t.Run("refresh session", func(t *testing.T) {
+		ctx := context.Background()
+		userID := uuid.New()
+		service := service.NewAuthService(inmemory.NewUserRepository(), inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{})
+		grant, err := service.StartSession(ctx, userID)
+		require.NoError(t, err)
+		sut := NewAuthHandlers(service, config)
+		r := newRefreshRequest(t, grant.RefreshToken)
+		w := httptest.NewRecorder()
+
+		sut.Refresh().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assertAuthToken(t, w, config, userID)
+		refreshCookie := findCookie(t, w, utils.RefreshCookieName)
+		assert.NotEqual(t, grant.RefreshToken, refreshCookie.Value)
+		assert.Equal(t, int(config.RefreshTokenExpiryIn/time.Second), refreshCookie.MaxAge)
+		assert.True(t, refreshCookie.HttpOnly)
+	})
+	t.Run("refresh token is missing", func(t *testing.T) {
+		service := &authServiceMock{}
+		sut := NewAuthHandlers(service, config)
+		r := httptest.NewRequest(http.MethodPost, "/", http.NoBody)
+		w := httptest.NewRecorder()
+
+		sut.Refresh().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+	t.Run("refresh token is invalid", func(t *testing.T) {
+		service := &authServiceMock{
+			RefreshSessionFunc: func(ctx context.Context, refreshToken string) (*model.SessionGrant, error) {
+				return nil, auth.ErrInvalidRefreshToken
+			},
+		}
+		sut := NewAuthHandlers(service, config)
+		r := newRefreshRequest(t, "token")
+		w := httptest.NewRecorder()
+
+		sut.Refresh().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assertExpiredAuthCookie(t, w)
+		refreshCookie := findCookie(t, w, utils.RefreshCookieName)
+		assert.Less(t, refreshCookie.MaxAge, 0)
+	})
+	t.Run("refresh failed", func(t *testing.T) {
+		service := &authServiceMock{
+			RefreshSessionFunc: func(ctx context.Context, refreshToken string) (*model.SessionGrant, error) {
+				return nil, errors.New("failed")
+			},
+		}
+		sut := NewAuthHandlers(service, config)
+		r := newRefreshRequest(t, "token")
+		w := httptest.NewRecorder()
+
+		sut.Refresh().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	config := config.JWTAuthConfig{
+		SignKey:       "secret",
+		TokenExpiryIn: time.Minute,
+	}
+
+	t.Run("logout ends session", func(t *testing.T) {
+		ctx := context.Background()
+		service := service.NewAuthService(inmemory.NewUserRepository(), inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{})
+		grant, err := service.StartSession(ctx, uuid.New())
+		require.NoError(t, err)
+		sut := NewAuthHandlers(service, config)
+		r := newLogoutRequestWithSession(t, grant.SessionID)
+		w := httptest.NewRecorder()
+
+		sut.Logout().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assertExpiredAuthCookie(t, w)
+		revoked, err := service.IsSessionRevoked(ctx, grant.SessionID)
+		require.NoError(t, err)
+		assert.True(t, revoked)
+		_, err = service.RefreshSession(ctx, grant.RefreshToken)
+		require.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+	})
+	t.Run("session is missing in context", func(t *testing.T) {
+		service := &authServiceMock{}
+		sut := NewAuthHandlers(service, config)
+		r := httptest.NewRequest(http.MethodPost, "/", http.NoBody)
+		w := httptest.NewRecorder()
+
+		sut.Logout().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+	t.Run("logout failed", func(t *testing.T) {
+		service := &authServiceMock{
+			EndSessionFunc: func(ctx context.Context, sessionID uuid.UUID) error {
+				return errors.New("failed")
+			},
+		}
+		sut := NewAuthHandlers(service, config)
+		r := newLogoutRequestWithSession(t, uuid.New())
+		w := httptest.NewRecorder()
+
+		sut.Logout().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestLogin_TwoFactor(t *testing.T) {
+	config := config.JWTAuthConfig{
+		SignKey:       "secret",
+		TokenExpiryIn: time.Minute,
+	}
+
+	t.Run("login returns challenge token", func(t *testing.T) {
+		service, userID := newTwoFactorService(t)
+		enableTwoFactor(t, service, userID)
+		sut := NewAuthHandlers(service, config)
+		r := newLoginUserRequest(t, "/", "user@email.com", "1234")
+		w := httptest.NewRecorder()
+
+		sut.Login().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Empty(t, w.Header().Values("Set-Cookie"))
+		var resp LoginChallengeResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		got, err := utils.NewAuthCookieBaker(config).ParseChallenge(resp.ChallengeToken)
+		require.NoError(t, err)
+		assert.Equal(t, userID, got)
+	})
+	t.Run("two factor is not confirmed", func(t *testing.T) {
+		service, userID := newTwoFactorService(t)
+		_, err := service.EnrollTwoFactor(context.Background(), userID)
+		require.NoError(t, err)
+		sut := NewAuthHandlers(service, config)
+		r := newLoginUserRequest(t, "/", "user@email.com", "1234")
+		w := httptest.NewRecorder()
+
+		sut.Login().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assertAuthToken(t, w, config, userID)
+	})
+}
+
+func TestLogin_Limits(t *testing.T) {
+	const (
+		email    = "user@email.com"
+		password = "1234"
+	)
+
+	config := config.JWTAuthConfig{
+		SignKey:       "secret",
+		TokenExpiryIn: time.Minute,
+	}
+	policy := model.LoginPolicy{
+		FreeFailures: 1,
+		BaseDelay:    time.Minute,
+		MaxDelay:     time.Hour,
+		Window:       time.Hour,
+	}
+
+	t.Run("account is locked after failures", func(t *testing.T) {
+		service, _ := newTwoFactorService(t)
+		sut := NewAuthHandlers(service, config, withLoginLimiters(policy, model.AddressLoginPolicy))
+		failLogins(t, sut, email, policy.FreeFailures+1)
+		r := newLoginUserRequest(t, "/", email, password)
+		w := httptest.NewRecorder()
+
+		sut.Login().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "60", w.Header().Get(retryAfterHeader))
+		assert.Empty(t, w.Header().Values("Set-Cookie"))
+	})
+	t.Run("unknown account is locked after failures", func(t *testing.T) {
+		service, _ := newTwoFactorService(t)
+		sut := NewAuthHandlers(service, config, withLoginLimiters(policy, model.AddressLoginPolicy))
+		const unknown = "unknown@email.com"
+		failLogins(t, sut, unknown, policy.FreeFailures+1)
+		r := newLoginUserRequest(t, "/", unknown, password)
+		w := httptest.NewRecorder()
+
+		sut.Login().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	})
+	t.Run("address is locked after failures", func(t *testing.T) {
+		service, _ := newTwoFactorService(t)
+		sut := NewAuthHandlers(service, config, withLoginLimiters(model.AccountLoginPolicy, policy))
+		failLogins(t, sut, "user1@email.com", 1)
+		failLogins(t, sut, "user2@email.com", 1)
+		r := newLoginUserRequest(t, "/", email, password)
+		w := httptest.NewRecorder()
+
+		sut.Login().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "60", w.Header().Get(retryAfterHeader))
+	})
+	t.Run("login resets failures of account", func(t *testing.T) {
+		service, userID := newTwoFactorService(t)
+		sut := NewAuthHandlers(service, config, withLoginLimiters(policy, model.AddressLoginPolicy))
+		failLogins(t, sut, email, policy.FreeFailures)
+		r := newLoginUserRequest(t, "/", email, password)
+		w := httptest.NewRecorder()
+
+		sut.Login().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assertAuthToken(t, w, config, userID)
+		failLogins(t, sut, email, policy.FreeFailures)
+		w = httptest.NewRecorder()
+		sut.Login().ServeHTTP(w, newLoginUserRequest(t, "/", email, password))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+	t.Run("successful logins are not counted from address", func(t *testing.T) {
+		service, _ := newTwoFactorService(t)
+		sut := NewAuthHandlers(service, config, withLoginLimiters(model.AccountLoginPolicy, policy))
+		for i := 0; i < policy.FreeFailures+2; i++ {
+			w := httptest.NewRecorder()
+			sut.Login().ServeHTTP(w, newLoginUserRequest(t, "/", email, password))
+			require.Equal(t, http.StatusOK, w.Code)
+		}
+		r := newLoginUserRequest(t, "/", email, password)
+		w := httptest.NewRecorder()
+
+		sut.Login().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+	t.Run("second factor is locked after invalid codes", func(t *testing.T) {
+		service, userID := newTwoFactorService(t)
+		enrollment := enableTwoFactor(t, service, userID)
+		sut := NewAuthHandlers(service, config, withLoginLimiters(policy, model.AddressLoginPolicy))
+		challenge, err := utils.NewAuthCookieBaker(config).BakeChallenge(userID)
+		require.NoError(t, err)
+		for i := 0; i < policy.FreeFailures+1; i++ {
+			w := httptest.NewRecorder()
+			sut.VerifyLogin().ServeHTTP(w, newVerifyLoginRequest(t, challenge, "000000"))
+			require.Equal(t, http.StatusUnauthorized, w.Code)
+		}
+		r := newVerifyLoginRequest(t, challenge, generateCode(t, enrollment))
+		w := httptest.NewRecorder()
+
+		sut.VerifyLogin().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Empty(t, w.Header().Values("Set-Cookie"))
+	})
+	t.Run("failed to release login", func(t *testing.T) {
+		service, _ := newTwoFactorService(t)
+		limiter := &loginLimiterMock{
+			ReserveFunc: func(ctx context.Context, key string) (time.Duration, error) {
+				return 0, nil
+			},
+			ReleaseFunc: func(ctx context.Context, key string) error {
+				return errors.New("failed")
+			},
+			ResetFunc: func(ctx context.Context, key string) error {
+				return nil
+			},
+		}
+		sut := NewAuthHandlers(service, config, WithLoginLimiters(limiter, limiter))
+		r := newLoginUserRequest(t, "/", email, password)
+		w := httptest.NewRecorder()
+
+		sut.Login().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Empty(t, w.Header().Values("Set-Cookie"))
+	})
+	t.Run("failed to reserve login", func(t *testing.T) {
+		limiter := &loginLimiterMock{
+			ReserveFunc: func(ctx context.Context, key string) (time.Duration, error) {
+				return 0, errors.New("failed")
+			},
+		}
+		sut := NewAuthHandlers(&authServiceMock{}, config, WithLoginLimiters(limiter, limiter))
+		r := newLoginUserRequest(t, "/", email, password)
+		w := httptest.NewRecorder()
+
+		sut.Login().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestVerifyLogin(t *testing.T) {
+	config := config.JWTAuthConfig{
+		SignKey:       "secret",
+		TokenExpiryIn: time.Minute,
+	}
+	baker := utils.NewAuthCookieBaker(config)
+
+	t.Run("verify login by code", func(t *testing.T) {
+		service, userID := newTwoFactorService(t)
+		enrollment := enableTwoFactor(t, service, userID)
+		challenge, err := baker.BakeChallenge(userID)
+		require.NoError(t, err)
+		sut := NewAuthHandlers(service, config)
+		r := newVerifyLoginRequest(t, challenge, generateCode(t, enrollment))
+		w := httptest.NewRecorder()
+
+		sut.VerifyLogin().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assertAuthToken(t, w, config, userID)
+	})
+	t.Run("verify login by recovery code", func(t *testing.T) {
+		service, userID := newTwoFactorService(t)
+		enrollment := enableTwoFactor(t, service, userID)
+		challenge, err := baker.BakeChallenge(userID)
+		require.NoError(t, err)
+		sut := NewAuthHandlers(service, config)
+		r := newVerifyLoginRequest(t, challenge, enrollment.RecoveryCodes[0])
+		w := httptest.NewRecorder()
+
+		sut.VerifyLogin().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+	t.Run("invalid code", func(t *testing.T) {
+		service, userID := newTwoFactorService(t)
+		enableTwoFactor(t, service, userID)
+		challenge, err := baker.BakeChallenge(userID)
+		require.NoError(t, err)
+		sut := NewAuthHandlers(service, config)
+		r := newVerifyLoginRequest(t, challenge, "code")
+		w := httptest.NewRecorder()
+
+		sut.VerifyLogin().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Empty(t, w.Header().Values("Set-Cookie"))
+	})
+	t.Run("invalid challenge token", func(t *testing.T) {
+		service := &authServiceMock{}
+		sut := NewAuthHandlers(service, config)
+		r := newVerifyLoginRequest(t, "token", "123456")
+		w := httptest.NewRecorder()
+
+		sut.VerifyLogin().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+	t.Run("request contains invalid json", func(t *testing.T) {
+		service := &authServiceMock{}
+		sut := NewAuthHandlers(service, config)
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{{verify}"))
+		w := httptest.NewRecorder()
+
+		sut.VerifyLogin().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+	t.Run("verification failed", func(t *testing.T) {
+		service := &authServiceMock{
+			VerifySecondFactorFunc: func(ctx context.Context, userID uuid.UUID, code string) error {
+				return errors.New("failed")
+			},
+		}
+		challenge, err := baker.BakeChallenge(uuid.New())
+		require.NoError(t, err)
+		sut := NewAuthHandlers(service, config)
+		r := newVerifyLoginRequest(t, challenge, "123456")
+		w := httptest.NewRecorder()
+
+		sut.VerifyLogin().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestEnrollTwoFactor(t *testing.T) {
+	config := config.JWTAuthConfig{
+		SignKey:       "secret",
+		TokenExpiryIn: time.Minute,
+	}
+
+	t.Run("enroll two factor", func(t *testing.T) {
+		service, userID := newTwoFactorService(t)
+		sut := NewAuthHandlers(service, config)
+		r := newRequestWithUser(t, http.NoBody, userID)
+		w := httptest.NewRecorder()
+
+		sut.EnrollTwoFactor().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp EnrollTwoFactorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.True(t, strings.HasPrefix(resp.OTPAuthURI, "otpauth://totp/"))
+		assert.Len(t, resp.RecoveryCodes, model.RecoveryCodesCount)
+	})
+	t.Run("two factor has already been enabled", func(t *testing.T) {
+		service := &authServiceMock{
+			EnrollTwoFactorFunc: func(ctx context.Context, userID uuid.UUID) (*model.TwoFactorEnrollment, error) {
+				return nil, auth.ErrTwoFactorEnabled
+			},
+		}
+		sut := NewAuthHandlers(service, config)
+		r := newRequestWithUser(t, http.NoBody, uuid.New())
 		w := httptest.NewRecorder()
 
-		sut.Register().ServeHTTP(w, r)
+		sut.EnrollTwoFactor().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+	t.Run("enrollment failed", func(t *testing.T) {
+		service := &authServiceMock{
+			EnrollTwoFactorFunc: func(ctx context.Context, userID uuid.UUID) (*model.TwoFactorEnrollment, error) {
+				return nil, errors.New("failed")
+			},
+		}
+		sut := NewAuthHandlers(service, config)
+		r := newRequestWithUser(t, http.NoBody, uuid.New())
+		w := httptest.NewRecorder()
+
+		sut.EnrollTwoFactor().ServeHTTP(w, r)
 
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
 	})
 }
 
-func TestLogin(t *testing.T) {
-	const (
-		email    = "user@email.com"
-		password = "1234"
-	)
-
+func TestConfirmTwoFactor(t *testing.T) {
 	config := config.JWTAuthConfig{
 		SignKey:       "secret",
 		TokenExpiryIn: time.Minute,
 	}
 
-	t.Run("login registered user", func(t *testing.T) {
+	t.Run("confirm two factor", func(t *testing.T) {
 		ctx := context.Background()
-		user := &model.User{Email: email, Password: password}
-		err := user.HashPassword()
+		service, userID := newTwoFactorService(t)
+		enrollment, err := service.EnrollTwoFactor(ctx, userID)
 		require.NoError(t, err)
-		repo := inmemory.NewUserRepository()
-		user.ID, err = repo.Register(ctx, user)
+		sut := NewAuthHandlers(service, config)
+		r := newConfirmTwoFactorRequest(t, userID, generateCode(t, enrollment))
+		w := httptest.NewRecorder()
+
+		sut.ConfirmTwoFactor().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		enabled, err := service.IsTwoFactorEnabled(ctx, userID)
+		require.NoError(t, err)
+		assert.True(t, enabled)
+	})
+	t.Run("invalid code", func(t *testing.T) {
+		service, userID := newTwoFactorService(t)
+		_, err := service.EnrollTwoFactor(context.Background(), userID)
 		require.NoError(t, err)
-		service := service.NewAuthService(repo)
 		sut := NewAuthHandlers(service, config)
-		r := newLoginUserRequest(t, "/", email, password)
+		r := newConfirmTwoFactorRequest(t, userID, "code")
 		w := httptest.NewRecorder()
 
-		sut.Login().ServeHTTP(w, r)
+		sut.ConfirmTwoFactor().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+	t.Run("two factor is not enrolled", func(t *testing.T) {
+		service, userID := newTwoFactorService(t)
+		sut := NewAuthHandlers(service, config)
+		r := newConfirmTwoFactorRequest(t, userID, "123456")
+		w := httptest.NewRecorder()
+
+		sut.ConfirmTwoFactor().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+	t.Run("two factor has already been enabled", func(t *testing.T) {
+		service, userID := newTwoFactorService(t)
+		enrollment := enableTwoFactor(t, service, userID)
+		sut := NewAuthHandlers(service, config)
+		r := newConfirmTwoFactorRequest(t, userID, generateCode(t, enrollment))
+		w := httptest.NewRecorder()
+
+		sut.ConfirmTwoFactor().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+	t.Run("request contains invalid json", func(t *testing.T) {
+		service := &authServiceMock{}
+		sut := NewAuthHandlers(service, config)
+		r := newRequestWithUser(t, strings.NewReader("{{confirm}"), uuid.New())
+		w := httptest.NewRecorder()
+
+		sut.ConfirmTwoFactor().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestChangePassword(t *testing.T) {
+	config := config.JWTAuthConfig{
+		SignKey:       "secret",
+		TokenExpiryIn: time.Minute,
+	}
+
+	t.Run("change password", func(t *testing.T) {
+		ctx := context.Background()
+		service, userID := newTwoFactorService(t)
+		grant, err := service.StartSession(ctx, userID)
+		require.NoError(t, err)
+		sut := NewAuthHandlers(service, config)
+		r := newChangePasswordRequest(t, userID, "1234", "5678")
+		w := httptest.NewRecorder()
+
+		sut.ChangePassword().ServeHTTP(w, r)
 
 		assert.Equal(t, http.StatusOK, w.Code)
-		assertAuthToken(t, w, config, user.ID)
+		assertAuthToken(t, w, config, userID)
+		revoked, err := service.IsSessionRevoked(ctx, grant.SessionID)
+		require.NoError(t, err)
+		assert.True(t, revoked)
+		_, err = service.Login(ctx, &model.User{Email: "user@email.com", Password: "5678"})
+		require.NoError(t, err)
 	})
-	t.Run("login request contains invalid json", func(t *testing.T) {
-		repo := inmemory.NewUserRepository()
-		service := service.NewAuthService(repo)
+	t.Run("current password is wrong", func(t *testing.T) {
+		service, userID := newTwoFactorService(t)
 		sut := NewAuthHandlers(service, config)
-		r := newLoginUserInvalidRequest(t)
+		r := newChangePasswordRequest(t, userID, "0000", "5678")
 		w := httptest.NewRecorder()
 
-		sut.Login().ServeHTTP(w, r)
+		sut.ChangePassword().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assertErrorResponse(t, w, msgInvalidPassword)
+		assert.Empty(t, w.Header().Values("Set-Cookie"))
+	})
+	t.Run("new password is empty", func(t *testing.T) {
+		service, userID := newTwoFactorService(t)
+		sut := NewAuthHandlers(service, config)
+		r := newChangePasswordRequest(t, userID, "1234", "")
+		w := httptest.NewRecorder()
+
+		sut.ChangePassword().ServeHTTP(w, r)
 
 		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assertErrorResponse(t, w, msgPasswordEmpty)
 	})
-	t.Run("login failed", func(t *testing.T) {
+	t.Run("guessing current password is locked", func(t *testing.T) {
+		service, userID := newTwoFactorService(t)
+		policy := model.LoginPolicy{FreeFailures: 1, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour}
+		sut := NewAuthHandlers(service, config, withLoginLimiters(policy, model.AddressLoginPolicy))
+		for i := 0; i < 2; i++ {
+			w := httptest.NewRecorder()
+			sut.ChangePassword().ServeHTTP(w, newChangePasswordRequest(t, userID, "0000", "5678"))
+			require.Equal(t, http.StatusForbidden, w.Code)
+		}
+		r := newChangePasswordRequest(t, userID, "1234", "5678")
+		w := httptest.NewRecorder()
+
+		sut.ChangePassword().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	})
+	t.Run("request contains invalid json", func(t *testing.T) {
 		service := &authServiceMock{}
-		service.LoginFunc = func(ctx context.Context, user *model.User) (uuid.UUID, error) {
-			return uuid.Nil, errors.New("failed to login")
+		sut := NewAuthHandlers(service, config)
+		r := newRequestWithUser(t, strings.NewReader("{{password}"), uuid.New())
+		w := httptest.NewRecorder()
+
+		sut.ChangePassword().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+	t.Run("change failed", func(t *testing.T) {
+		service := &authServiceMock{
+			ChangePasswordFunc: func(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error {
+				return errors.New("failed")
+			},
 		}
 		sut := NewAuthHandlers(service, config)
-		r := newLoginUserRequest(t, "/", email, password)
+		r := newChangePasswordRequest(t, uuid.New(), "1234", "5678")
 		w := httptest.NewRecorder()
 
-		sut.Login().ServeHTTP(w, r)
+		sut.ChangePassword().ServeHTTP(w, r)
 
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
 	})
 }
 
+func TestResetPassword(t *testing.T) {
+	config := config.JWTAuthConfig{
+		SignKey:       "secret",
+		TokenExpiryIn: time.Minute,
+	}
+
+	t.Run("reset password by recovery code", func(t *testing.T) {
+		ctx := context.Background()
+		service, userID := newTwoFactorService(t)
+		enrollment := enableTwoFactor(t, service, userID)
+		sut := NewAuthHandlers(service, config)
+		r := newResetPasswordRequest(t, "user@email.com", enrollment.RecoveryCodes[0], "5678")
+		w := httptest.NewRecorder()
+
+		sut.ResetPassword().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Values("Set-Cookie"))
+		_, err := service.Login(ctx, &model.User{Email: "user@email.com", Password: "5678"})
+		require.NoError(t, err)
+	})
+	t.Run("unknown email and invalid code are answered the same", func(t *testing.T) {
+		service, userID := newTwoFactorService(t)
+		enableTwoFactor(t, service, userID)
+		sut := NewAuthHandlers(service, config)
+		unknown := httptest.NewRecorder()
+		invalid := httptest.NewRecorder()
+
+		sut.ResetPassword().ServeHTTP(unknown, newResetPasswordRequest(t, "unknown@email.com", "code", "5678"))
+		sut.ResetPassword().ServeHTTP(invalid, newResetPasswordRequest(t, "user@email.com", "code", "5678"))
+
+		assert.Equal(t, http.StatusUnauthorized, unknown.Code)
+		assert.Equal(t, http.StatusUnauthorized, invalid.Code)
+		assert.Equal(t, unknown.Body.String(), invalid.Body.String())
+		assertErrorResponse(t, invalid, msgInvalidRecovery)
+	})
+	t.Run("new password is empty", func(t *testing.T) {
+		service, userID := newTwoFactorService(t)
+		enrollment := enableTwoFactor(t, service, userID)
+		sut := NewAuthHandlers(service, config)
+		r := newResetPasswordRequest(t, "user@email.com", enrollment.RecoveryCodes[0], "")
+		w := httptest.NewRecorder()
+
+		sut.ResetPassword().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+	t.Run("reset of locked account", func(t *testing.T) {
+		service, userID := newTwoFactorService(t)
+		enrollment := enableTwoFactor(t, service, userID)
+		policy := model.LoginPolicy{FreeFailures: 1, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour}
+		sut := NewAuthHandlers(service, config, withLoginLimiters(policy, model.AddressLoginPolicy))
+		failLogins(t, sut, "user@email.com", 2)
+		r := newResetPasswordRequest(t, "user@email.com", enrollment.RecoveryCodes[0], "5678")
+		w := httptest.NewRecorder()
+
+		sut.ResetPassword().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	})
+	t.Run("request contains invalid json", func(t *testing.T) {
+		service := &authServiceMock{}
+		sut := NewAuthHandlers(service, config)
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{{reset}"))
+		w := httptest.NewRecorder()
+
+		sut.ResetPassword().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestJWKS(t *testing.T) {
+	t.Run("public keys are published", func(t *testing.T) {
+		config := newEdDSAConfig(t, "key-1")
+		keys, err := utils.NewJWTKeys(config)
+		require.NoError(t, err)
+		sut := NewAuthHandlers(&authServiceMock{}, config, WithJWTKeys(keys))
+		r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		w := httptest.NewRecorder()
+
+		sut.JWKS().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, applicationJSON, w.Header().Get(contentTypeHeader))
+		set, err := jwk.Parse(w.Body.Bytes())
+		require.NoError(t, err)
+		key, ok := set.LookupKeyID("key-1")
+		require.True(t, ok)
+		_, isPrivate := key.(jwk.OKPPrivateKey)
+		assert.False(t, isPrivate)
+	})
+	t.Run("hs256 key is not published", func(t *testing.T) {
+		config := config.JWTAuthConfig{SignKey: "secret"}
+		sut := NewAuthHandlers(&authServiceMock{}, config)
+		r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		w := httptest.NewRecorder()
+
+		sut.JWKS().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		set, err := jwk.Parse(w.Body.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, 0, set.Len())
+	})
+}
+
 func newRegisterUserInvalidRequest(t *testing.T) *http.Request {
 	t.Helper()
 
@@ -205,3 +1183,197 @@ func assertAuthToken(t *testing.T, w *httptest.ResponseRecorder, cfg config.JWTA
 	require.True(t, ok)
 	assert.Equal(t, user.String(), id.(string))
 }
+
+func newDeleteAccountRequestWithUser(t *testing.T, userID uuid.UUID, req DeleteAccountRequest) *http.Request {
+	t.Helper()
+
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+	r := httptest.NewRequest(http.MethodDelete, "/", bytes.NewReader(body))
+	token := jwt.New()
+	err = token.Set(utils.UserIDClaim, userID.String())
+	require.NoError(t, err)
+	ctx := context.WithValue(r.Context(), jwtauth.TokenCtxKey, token)
+	return r.WithContext(ctx)
+}
+
+func assertExpiredAuthCookie(t *testing.T, w *httptest.ResponseRecorder) {
+	t.Helper()
+
+	r := w.Result()
+	defer func() { _ = r.Body.Close() }()
+	cookies := r.Cookies()
+	require.NotEmpty(t, cookies)
+	jwtCookie := cookies[0]
+	assert.Equal(t, utils.JWTCookieName, jwtCookie.Name)
+	assert.Empty(t, jwtCookie.Value)
+	assert.Less(t, jwtCookie.MaxAge, 0)
+}
+
+func newRefreshRequest(t *testing.T, refreshToken string) *http.Request {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodPost, "/", http.NoBody)
+	r.AddCookie(&http.Cookie{Name: utils.RefreshCookieName, Value: refreshToken})
+	return r
+}
+
+func newLogoutRequestWithSession(t *testing.T, sessionID uuid.UUID) *http.Request {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodPost, "/", http.NoBody)
+	token := jwt.New()
+	err := token.Set(utils.SessionIDClaim, sessionID.String())
+	require.NoError(t, err)
+	ctx := context.WithValue(r.Context(), jwtauth.TokenCtxKey, token)
+	return r.WithContext(ctx)
+}
+
+func findCookie(t *testing.T, w *httptest.ResponseRecorder, name string) *http.Cookie {
+	t.Helper()
+
+	r := w.Result()
+	defer func() { _ = r.Body.Close() }()
+	for _, c := range r.Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+	require.Failf(t, "cookie not found", "name %s", name)
+	return nil
+}
+
+func newTwoFactorService(t *testing.T) (auth.AuthService, uuid.UUID) {
+	t.Helper()
+
+	repo := inmemory.NewUserRepository()
+	user := &model.User{Email: "user@email.com", Password: "1234"}
+	require.NoError(t, user.HashPassword())
+	userID, err := repo.Register(context.Background(), user)
+	require.NoError(t, err)
+	service := service.NewAuthService(repo, inmemory.NewSessionRepository(), inmemory.NewTwoFactorRepository(),
+		&auth.UserDataShredderMock{})
+	return service, userID
+}
+
+func enableTwoFactor(t *testing.T, service auth.AuthService, userID uuid.UUID) *model.TwoFactorEnrollment {
+	t.Helper()
+
+	ctx := context.Background()
+	enrollment, err := service.EnrollTwoFactor(ctx, userID)
+	require.NoError(t, err)
+	// the code of the previous period confirms the second factor, so the current code is left for the login
+	err = service.ConfirmTwoFactor(ctx, userID, generateCodeAt(t, enrollment, time.Now().Add(-30*time.Second)))
+	require.NoError(t, err)
+	return enrollment
+}
+
+func generateCode(t *testing.T, enrollment *model.TwoFactorEnrollment) string {
+	t.Helper()
+
+	return generateCodeAt(t, enrollment, time.Now())
+}
+
+func generateCodeAt(t *testing.T, enrollment *model.TwoFactorEnrollment, at time.Time) string {
+	t.Helper()
+
+	u, err := url.Parse(enrollment.URI)
+	require.NoError(t, err)
+	code, err := totp.GenerateCode(u.Query().Get("secret"), at)
+	require.NoError(t, err)
+	return code
+}
+
+func newVerifyLoginRequest(t *testing.T, challenge, code string) *http.Request {
+	t.Helper()
+
+	body, err := json.Marshal(VerifyLoginRequest{ChallengeToken: challenge, Code: code})
+	require.NoError(t, err)
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	r.Header.Set(contentTypeHeader, applicationJSON)
+	return r
+}
+
+func newConfirmTwoFactorRequest(t *testing.T, userID uuid.UUID, code string) *http.Request {
+	t.Helper()
+
+	body, err := json.Marshal(ConfirmTwoFactorRequest{Code: code})
+	require.NoError(t, err)
+	r := newRequestWithUser(t, bytes.NewReader(body), userID)
+	r.Header.Set(contentTypeHeader, applicationJSON)
+	return r
+}
+
+func newChangePasswordRequest(t *testing.T, userID uuid.UUID, currentPassword, newPassword string) *http.Request {
+	t.Helper()
+
+	body, err := json.Marshal(ChangePasswordRequest{CurrentPassword: currentPassword, NewPassword: newPassword})
+	require.NoError(t, err)
+	r := newRequestWithUser(t, bytes.NewReader(body), userID)
+	r.Header.Set(contentTypeHeader, applicationJSON)
+	return r
+}
+
+func newResetPasswordRequest(t *testing.T, email, recoveryCode, newPassword string) *http.Request {
+	t.Helper()
+
+	body, err := json.Marshal(ResetPasswordRequest{Email: email, RecoveryCode: recoveryCode, NewPassword: newPassword})
+	require.NoError(t, err)
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	r.Header.Set(contentTypeHeader, applicationJSON)
+	return r
+}
+
+func newRequestWithUser(t *testing.T, body io.Reader, userID uuid.UUID) *http.Request {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodPost, "/", body)
+	token := jwt.New()
+	err := token.Set(utils.UserIDClaim, userID.String())
+	require.NoError(t, err)
+	ctx := context.WithValue(r.Context(), jwtauth.TokenCtxKey, token)
+	return r.WithContext(ctx)
+}
+
+func newEdDSAConfig(t *testing.T, keyID string) config.JWTAuthConfig {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	file := filepath.Join(t.TempDir(), keyID+".pem")
+	err = os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600)
+	require.NoError(t, err)
+
+	return config.JWTAuthConfig{
+		Alg:           config.EdDSA,
+		ActiveKey:     keyID,
+		Keys:          []config.JWTKeyConfig{{ID: keyID, File: file}},
+		TokenExpiryIn: time.Minute,
+	}
+}
+
+func withLoginLimiters(accounts, addresses model.LoginPolicy) AuthHandlersOption {
+	repo := inmemory.NewLoginAttemptRepository()
+	return WithLoginLimiters(service.NewLoginLimiter(repo, accounts), service.NewLoginLimiter(repo, addresses))
+}
+
+func failLogins(t *testing.T, h *AuthHandlers, email string, n int) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		w := httptest.NewRecorder()
+		h.Login().ServeHTTP(w, newLoginUserRequest(t, "/", email, "wrong password"))
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+}
+
+func assertErrorResponse(t *testing.T, w *httptest.ResponseRecorder, want string) {
+	t.Helper()
+
+	assert.Equal(t, applicationJSON, w.Header().Get(contentTypeHeader))
+	var resp ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, want, resp.Error)
+}
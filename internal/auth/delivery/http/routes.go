@@ -11,5 +11,26 @@ func MapAuthRoutes(r chi.Router, h *AuthHandlers) {
 
 		r.Post("/register", h.Register())
 		r.Post("/login", h.Login())
+		// the challenge token of the login is sent in the body instead of the auth cookie
+		r.Post("/login/verify", h.VerifyLogin())
+		r.Post("/password/reset", h.ResetPassword())
+	})
+	// the refresh token is sent in the cookie, so the request has no body
+	r.Post("/refresh", h.Refresh())
+	r.Get("/.well-known/jwks.json", h.JWKS())
+	r.Group(func(r chi.Router) {
+		r.Use(h.cookieBaker.Middlewares()...)
+
+		r.Post("/logout", h.Logout())
+		r.Delete("/account", h.DeleteAccount())
+		r.Post("/2fa", h.EnrollTwoFactor())
+		r.With(middleware.AllowContentType(applicationJSON)).Post("/2fa/confirm", h.ConfirmTwoFactor())
+		r.With(middleware.AllowContentType(applicationJSON)).Put("/password", h.ChangePassword())
+		// personal access tokens are not accepted here, so a leaked token can not issue others
+		if h.tokens != nil {
+			r.With(middleware.AllowContentType(applicationJSON)).Post("/tokens", h.CreateAccessToken())
+			r.Get("/tokens", h.ListAccessTokens())
+			r.Delete("/tokens/{"+tokenParam+"}", h.RevokeAccessToken())
+		}
 	})
 }
@@ -1,7 +1,9 @@
 package http
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -9,6 +11,9 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -17,6 +22,7 @@ import (
 	"github.com/nestjam/goph-keeper/internal/auth/repository/inmemory"
 	"github.com/nestjam/goph-keeper/internal/auth/service"
 	"github.com/nestjam/goph-keeper/internal/config"
+	"github.com/nestjam/goph-keeper/internal/utils"
 )
 
 func TestMapAuthRoutes(t *testing.T) {
@@ -25,6 +31,15 @@ func TestMapAuthRoutes(t *testing.T) {
 		password     = "password"
 		registerPath = "/register"
 		loginPath    = "/login"
+		accountPath  = "/account"
+		refreshPath  = "/refresh"
+		logoutPath   = "/logout"
+		verifyPath   = "/login/verify"
+		twoFAPath    = "/2fa"
+		jwksPath     = "/.well-known/jwks.json"
+		passwordPath = "/password"
+		resetPath    = "/password/reset"
+		tokensPath   = "/tokens"
 	)
 
 	config := config.JWTAuthConfig{
@@ -35,7 +50,8 @@ func TestMapAuthRoutes(t *testing.T) {
 	t.Run("register", func(t *testing.T) {
 		t.Run("regiser user", func(t *testing.T) {
 			repo := inmemory.NewUserRepository()
-			service := service.NewAuthService(repo)
+			service := service.NewAuthService(repo, inmemory.NewSessionRepository(),
+				inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{})
 			handlers := NewAuthHandlers(service, config)
 			sut := chi.NewRouter()
 
@@ -66,7 +82,8 @@ func TestMapAuthRoutes(t *testing.T) {
 			ctx := context.Background()
 			repo := inmemory.NewUserRepository()
 			registerUser(t, ctx, email, password, repo)
-			service := service.NewAuthService(repo)
+			service := service.NewAuthService(repo, inmemory.NewSessionRepository(),
+				inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{})
 			handlers := NewAuthHandlers(service, config)
 			sut := chi.NewRouter()
 
@@ -79,6 +96,246 @@ func TestMapAuthRoutes(t *testing.T) {
 			assert.Equal(t, http.StatusOK, w.Code)
 		})
 	})
+	t.Run("delete account", func(t *testing.T) {
+		t.Run("delete account of authenticated user", func(t *testing.T) {
+			ctx := context.Background()
+			shredder := &auth.UserDataShredderMock{
+				DeleteUserDataFunc: func(ctx context.Context, userID uuid.UUID) (func(context.Context) error, error) {
+					return func(context.Context) error { return nil }, nil
+				},
+			}
+			service := service.NewAuthService(inmemory.NewUserRepository(), inmemory.NewSessionRepository(),
+				inmemory.NewTwoFactorRepository(), shredder)
+			userID, err := service.Register(ctx, &model.User{Email: email, Password: password})
+			require.NoError(t, err)
+			grant, err := service.StartSession(ctx, userID)
+			require.NoError(t, err)
+			handlers := NewAuthHandlers(service, config)
+			sut := chi.NewRouter()
+
+			MapAuthRoutes(sut, handlers)
+			body, err := json.Marshal(DeleteAccountRequest{CurrentPassword: password})
+			require.NoError(t, err)
+			r := httptest.NewRequest(http.MethodDelete, accountPath, bytes.NewReader(body))
+			cookie, err := utils.NewAuthCookieBaker(config).BakeCookie(userID, grant.SessionID)
+			require.NoError(t, err)
+			r.AddCookie(cookie)
+			w := httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+		t.Run("unauthenticated request", func(t *testing.T) {
+			service := &authServiceMock{}
+			handlers := NewAuthHandlers(service, config)
+			sut := chi.NewRouter()
+
+			MapAuthRoutes(sut, handlers)
+			r := httptest.NewRequest(http.MethodDelete, accountPath, http.NoBody)
+			w := httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	})
+	t.Run("refresh", func(t *testing.T) {
+		t.Run("refresh session without authentication", func(t *testing.T) {
+			ctx := context.Background()
+			service := service.NewAuthService(inmemory.NewUserRepository(), inmemory.NewSessionRepository(),
+				inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{})
+			grant, err := service.StartSession(ctx, uuid.New())
+			require.NoError(t, err)
+			handlers := NewAuthHandlers(service, config)
+			sut := chi.NewRouter()
+
+			MapAuthRoutes(sut, handlers)
+			r := httptest.NewRequest(http.MethodPost, refreshPath, http.NoBody)
+			r.AddCookie(&http.Cookie{Name: utils.RefreshCookieName, Value: grant.RefreshToken})
+			w := httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+	})
+	t.Run("logout", func(t *testing.T) {
+		t.Run("access token is rejected after logout", func(t *testing.T) {
+			ctx := context.Background()
+			userID := uuid.New()
+			service := service.NewAuthService(inmemory.NewUserRepository(), inmemory.NewSessionRepository(),
+				inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{})
+			grant, err := service.StartSession(ctx, userID)
+			require.NoError(t, err)
+			handlers := NewAuthHandlers(service, config)
+			sut := chi.NewRouter()
+			MapAuthRoutes(sut, handlers)
+			cookie, err := utils.NewAuthCookieBaker(config).BakeCookie(userID, grant.SessionID)
+			require.NoError(t, err)
+			r := httptest.NewRequest(http.MethodPost, logoutPath, http.NoBody)
+			r.AddCookie(cookie)
+			w := httptest.NewRecorder()
+			sut.ServeHTTP(w, r)
+			require.Equal(t, http.StatusOK, w.Code)
+			r = httptest.NewRequest(http.MethodPost, logoutPath, http.NoBody)
+			r.AddCookie(cookie)
+			w = httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	})
+	t.Run("password", func(t *testing.T) {
+		t.Run("access token is rejected after password change", func(t *testing.T) {
+			ctx := context.Background()
+			service, userID := newTwoFactorService(t)
+			grant, err := service.StartSession(ctx, userID)
+			require.NoError(t, err)
+			handlers := NewAuthHandlers(service, config)
+			sut := chi.NewRouter()
+			MapAuthRoutes(sut, handlers)
+			cookie, err := utils.NewAuthCookieBaker(config).BakeCookie(userID, grant.SessionID)
+			require.NoError(t, err)
+			body := strings.NewReader(`{"current_password":"1234","new_password":"5678"}`)
+			r := httptest.NewRequest(http.MethodPut, passwordPath, body)
+			r.Header.Set(contentTypeHeader, applicationJSON)
+			r.AddCookie(cookie)
+			w := httptest.NewRecorder()
+			sut.ServeHTTP(w, r)
+			require.Equal(t, http.StatusOK, w.Code)
+			r = httptest.NewRequest(http.MethodPost, logoutPath, http.NoBody)
+			r.AddCookie(cookie)
+			w = httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+		t.Run("reset password without authentication", func(t *testing.T) {
+			service, userID := newTwoFactorService(t)
+			enrollment := enableTwoFactor(t, service, userID)
+			handlers := NewAuthHandlers(service, config)
+			sut := chi.NewRouter()
+
+			MapAuthRoutes(sut, handlers)
+			r := newResetPasswordRequest(t, "user@email.com", enrollment.RecoveryCodes[0], "5678")
+			r.URL.Path = resetPath
+			w := httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Empty(t, w.Header().Values("Set-Cookie"))
+		})
+	})
+	t.Run("two factor", func(t *testing.T) {
+		t.Run("login with second factor", func(t *testing.T) {
+			service, userID := newTwoFactorService(t)
+			enrollment := enableTwoFactor(t, service, userID)
+			handlers := NewAuthHandlers(service, config)
+			sut := chi.NewRouter()
+			MapAuthRoutes(sut, handlers)
+			r := newLoginUserRequest(t, loginPath, "user@email.com", "1234")
+			w := httptest.NewRecorder()
+			sut.ServeHTTP(w, r)
+			require.Equal(t, http.StatusAccepted, w.Code)
+			var resp LoginChallengeResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+			r = newVerifyLoginRequest(t, resp.ChallengeToken, generateCode(t, enrollment))
+			r.URL.Path = verifyPath
+			w = httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assertAuthToken(t, w, config, userID)
+		})
+		t.Run("enroll two factor without authentication", func(t *testing.T) {
+			service := &authServiceMock{}
+			handlers := NewAuthHandlers(service, config)
+			sut := chi.NewRouter()
+
+			MapAuthRoutes(sut, handlers)
+			r := httptest.NewRequest(http.MethodPost, twoFAPath, http.NoBody)
+			w := httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	})
+	t.Run("access tokens", func(t *testing.T) {
+		t.Run("revoke access token", func(t *testing.T) {
+			ctx := context.Background()
+			service, _ := newTwoFactorService(t)
+			tokens, userID := newAccessTokenService(t)
+			grant, err := service.StartSession(ctx, userID)
+			require.NoError(t, err)
+			cookie, err := utils.NewAuthCookieBaker(config).BakeCookie(userID, grant.SessionID)
+			require.NoError(t, err)
+			token := &model.AccessToken{UserID: userID, Name: "ci", Scope: model.ScopeRead}
+			plain, err := tokens.CreateAccessToken(ctx, token, tokenPassword)
+			require.NoError(t, err)
+			handlers := NewAuthHandlers(service, config, WithAccessTokens(tokens))
+			sut := chi.NewRouter()
+
+			MapAuthRoutes(sut, handlers)
+			r := httptest.NewRequest(http.MethodDelete, tokensPath+"/"+token.ID.String(), http.NoBody)
+			r.AddCookie(cookie)
+			w := httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			_, err = tokens.VerifyAccessToken(ctx, plain)
+			require.ErrorIs(t, err, utils.ErrInvalidAccessToken)
+		})
+		t.Run("access token does not manage tokens", func(t *testing.T) {
+			tokens, userID := newAccessTokenService(t)
+			token := &model.AccessToken{UserID: userID, Name: "ci", Scope: model.ScopeReadWrite}
+			plain, err := tokens.CreateAccessToken(context.Background(), token, tokenPassword)
+			require.NoError(t, err)
+			handlers := NewAuthHandlers(&authServiceMock{}, config, WithAccessTokens(tokens))
+			sut := chi.NewRouter()
+
+			MapAuthRoutes(sut, handlers)
+			r := httptest.NewRequest(http.MethodGet, tokensPath, http.NoBody)
+			r.Header.Set("Authorization", "Bearer "+plain)
+			w := httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	})
+	t.Run("jwks", func(t *testing.T) {
+		t.Run("access token is verified by published key", func(t *testing.T) {
+			config := newEdDSAConfig(t, "key-1")
+			keys, err := utils.NewJWTKeys(config)
+			require.NoError(t, err)
+			service, userID := newTwoFactorService(t)
+			handlers := NewAuthHandlers(service, config, WithJWTKeys(keys))
+			grant, err := service.StartSession(context.Background(), userID)
+			require.NoError(t, err)
+			cookie, err := utils.NewAuthCookieBaker(config, utils.WithJWTKeys(keys)).BakeCookie(userID, grant.SessionID)
+			require.NoError(t, err)
+			sut := chi.NewRouter()
+
+			MapAuthRoutes(sut, handlers)
+			r := httptest.NewRequest(http.MethodGet, jwksPath, http.NoBody)
+			w := httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			set, err := jwk.Parse(w.Body.Bytes())
+			require.NoError(t, err)
+			_, err = jwt.Parse([]byte(cookie.Value), jwt.WithKeySet(set))
+			require.NoError(t, err)
+		})
+	})
 }
 
 func registerUser(t *testing.T, ctx context.Context, email, password string, repo auth.UserRepository) {
@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -9,8 +10,19 @@ import (
 )
 
 type authServiceMock struct {
-	RegisterFunc func(ctx context.Context, user *model.User) (uuid.UUID, error)
-	LoginFunc    func(ctx context.Context, user *model.User) (uuid.UUID, error)
+	RegisterFunc           func(ctx context.Context, user *model.User) (uuid.UUID, error)
+	LoginFunc              func(ctx context.Context, user *model.User) (uuid.UUID, error)
+	DeleteAccountFunc      func(ctx context.Context, userID uuid.UUID, password, code string) error
+	ChangePasswordFunc     func(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error
+	ResetPasswordFunc      func(ctx context.Context, email, recoveryCode, newPassword string) (uuid.UUID, error)
+	StartSessionFunc       func(ctx context.Context, userID uuid.UUID) (*model.SessionGrant, error)
+	RefreshSessionFunc     func(ctx context.Context, refreshToken string) (*model.SessionGrant, error)
+	EndSessionFunc         func(ctx context.Context, sessionID uuid.UUID) error
+	IsSessionRevokedFunc   func(ctx context.Context, sessionID uuid.UUID) (bool, error)
+	IsTwoFactorEnabledFunc func(ctx context.Context, userID uuid.UUID) (bool, error)
+	EnrollTwoFactorFunc    func(ctx context.Context, userID uuid.UUID) (*model.TwoFactorEnrollment, error)
+	ConfirmTwoFactorFunc   func(ctx context.Context, userID uuid.UUID, code string) error
+	VerifySecondFactorFunc func(ctx context.Context, userID uuid.UUID, code string) error
 }
 
 func (s *authServiceMock) Register(ctx context.Context, user *model.User) (uuid.UUID, error) {
@@ -20,3 +32,73 @@ func (s *authServiceMock) Register(ctx context.Context, user *model.User) (uuid.
 func (s *authServiceMock) Login(ctx context.Context, user *model.User) (uuid.UUID, error) {
 	return s.LoginFunc(ctx, user)
 }
+
+func (s *authServiceMock) DeleteAccount(ctx context.Context, userID uuid.UUID, password, code string) error {
+	return s.DeleteAccountFunc(ctx, userID, password, code)
+}
+
+func (s *authServiceMock) ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword,
+	newPassword string) error {
+	return s.ChangePasswordFunc(ctx, userID, currentPassword, newPassword)
+}
+
+func (s *authServiceMock) ResetPassword(ctx context.Context, email, recoveryCode, newPassword string) (uuid.UUID,
+	error) {
+	return s.ResetPasswordFunc(ctx, email, recoveryCode, newPassword)
+}
+
+func (s *authServiceMock) StartSession(ctx context.Context, userID uuid.UUID) (*model.SessionGrant, error) {
+	return s.StartSessionFunc(ctx, userID)
+}
+
+func (s *authServiceMock) RefreshSession(ctx context.Context, refreshToken string) (*model.SessionGrant, error) {
+	return s.RefreshSessionFunc(ctx, refreshToken)
+}
+
+func (s *authServiceMock) EndSession(ctx context.Context, sessionID uuid.UUID) error {
+	return s.EndSessionFunc(ctx, sessionID)
+}
+
+func (s *authServiceMock) IsSessionRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	return s.IsSessionRevokedFunc(ctx, sessionID)
+}
+
+func (s *authServiceMock) IsTwoFactorEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	return s.IsTwoFactorEnabledFunc(ctx, userID)
+}
+
+func (s *authServiceMock) EnrollTwoFactor(ctx context.Context, userID uuid.UUID) (*model.TwoFactorEnrollment,
+	error) {
+	return s.EnrollTwoFactorFunc(ctx, userID)
+}
+
+func (s *authServiceMock) ConfirmTwoFactor(ctx context.Context, userID uuid.UUID, code string) error {
+	return s.ConfirmTwoFactorFunc(ctx, userID, code)
+}
+
+func (s *authServiceMock) VerifySecondFactor(ctx context.Context, userID uuid.UUID, code string) error {
+	return s.VerifySecondFactorFunc(ctx, userID, code)
+}
+
+type loginLimiterMock struct {
+	RetryAfterFunc func(ctx context.Context, key string) (time.Duration, error)
+	ReserveFunc    func(ctx context.Context, key string) (time.Duration, error)
+	ReleaseFunc    func(ctx context.Context, key string) error
+	ResetFunc      func(ctx context.Context, key string) error
+}
+
+func (m *loginLimiterMock) RetryAfter(ctx context.Context, key string) (time.Duration, error) {
+	return m.RetryAfterFunc(ctx, key)
+}
+
+func (m *loginLimiterMock) Reserve(ctx context.Context, key string) (time.Duration, error) {
+	return m.ReserveFunc(ctx, key)
+}
+
+func (m *loginLimiterMock) Release(ctx context.Context, key string) error {
+	return m.ReleaseFunc(ctx, key)
+}
+
+func (m *loginLimiterMock) Reset(ctx context.Context, key string) error {
+	return m.ResetFunc(ctx, key)
+}
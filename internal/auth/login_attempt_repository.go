@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+)
+
+var (
+	ErrLoginAttemptsNotFound = errors.New("login attempts not found")
+	ErrLoginLocked           = errors.New("login locked")
+)
+
+type LoginAttemptRepository interface {
+	GetLoginAttempts(ctx context.Context, key string) (*model.LoginAttempts, error)
+	// AddLoginFailure counts the failed login of the key at the time and returns the attempts of the key.
+	// It fails with ErrLoginLocked and counts nothing if the login is locked at the time.
+	// The count starts over if the last failure is before the window of the policy. Once the failures exceed
+	// the free ones, the login is locked for the base delay by the same statement, so concurrent logins
+	// do not pass the limit before LockLogin sets the actual delay.
+	AddLoginFailure(ctx context.Context, key string, at time.Time, policy model.LoginPolicy) (*model.LoginAttempts,
+		error)
+	// LockLogin locks the login of the key until the time, it fails if the key has no failures.
+	LockLogin(ctx context.Context, key string, until time.Time) error
+	// RemoveLoginFailure uncounts one failure of the key, e.g. of the login counted in advance that has succeeded.
+	RemoveLoginFailure(ctx context.Context, key string) error
+	DeleteLoginAttempts(ctx context.Context, key string) error
+}
@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+)
+
+type LoginAttemptRepositoryContract struct {
+	NewLoginAttemptRepository func() (LoginAttemptRepository, func())
+}
+
+func (c LoginAttemptRepositoryContract) Test(t *testing.T) {
+	const key = "account:user@email.com"
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	policy := model.LoginPolicy{FreeFailures: 1, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour}
+
+	t.Run("add first failure", func(t *testing.T) {
+		sut, tearDown := c.NewLoginAttemptRepository()
+		t.Cleanup(tearDown)
+
+		got, err := sut.AddLoginFailure(context.Background(), key, now, policy)
+
+		require.NoError(t, err)
+		assert.Equal(t, key, got.Key)
+		assert.Equal(t, 1, got.Failures)
+		assert.Equal(t, now, got.LastFailure)
+		assert.True(t, got.LockedUntil.IsZero())
+	})
+	t.Run("add failure within window", func(t *testing.T) {
+		sut, tearDown := c.NewLoginAttemptRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		_, err := sut.AddLoginFailure(ctx, key, now, policy)
+		require.NoError(t, err)
+		next := now.Add(time.Minute)
+
+		got, err := sut.AddLoginFailure(ctx, key, next, policy)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, got.Failures)
+		assert.Equal(t, next, got.LastFailure)
+	})
+	t.Run("add failure after window", func(t *testing.T) {
+		sut, tearDown := c.NewLoginAttemptRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		_, err := sut.AddLoginFailure(ctx, key, now, policy)
+		require.NoError(t, err)
+		next := now.Add(2 * time.Hour)
+
+		got, err := sut.AddLoginFailure(ctx, key, next, policy)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, got.Failures)
+	})
+	t.Run("add failure over free ones locks login for base delay", func(t *testing.T) {
+		sut, tearDown := c.NewLoginAttemptRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		_, err := sut.AddLoginFailure(ctx, key, now, policy)
+		require.NoError(t, err)
+		next := now.Add(time.Minute)
+
+		got, err := sut.AddLoginFailure(ctx, key, next, policy)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, got.Failures)
+		assert.Equal(t, next.Add(policy.BaseDelay), got.LockedUntil)
+	})
+	t.Run("add failure of locked login", func(t *testing.T) {
+		sut, tearDown := c.NewLoginAttemptRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		_, err := sut.AddLoginFailure(ctx, key, now, policy)
+		require.NoError(t, err)
+		require.NoError(t, sut.LockLogin(ctx, key, now.Add(time.Minute)))
+
+		_, err = sut.AddLoginFailure(ctx, key, now.Add(time.Second), policy)
+
+		require.ErrorIs(t, err, ErrLoginLocked)
+		got, err := sut.GetLoginAttempts(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, 1, got.Failures)
+		assert.Equal(t, now, got.LastFailure)
+	})
+	t.Run("add failure after lock expires", func(t *testing.T) {
+		sut, tearDown := c.NewLoginAttemptRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		_, err := sut.AddLoginFailure(ctx, key, now, policy)
+		require.NoError(t, err)
+		require.NoError(t, sut.LockLogin(ctx, key, now.Add(time.Minute)))
+
+		got, err := sut.AddLoginFailure(ctx, key, now.Add(time.Minute), policy)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, got.Failures)
+	})
+	t.Run("failures of keys are counted separately", func(t *testing.T) {
+		sut, tearDown := c.NewLoginAttemptRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		_, err := sut.AddLoginFailure(ctx, key, now, policy)
+		require.NoError(t, err)
+
+		got, err := sut.AddLoginFailure(ctx, "address:127.0.0.1", now, policy)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, got.Failures)
+	})
+	t.Run("get login attempts", func(t *testing.T) {
+		sut, tearDown := c.NewLoginAttemptRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		want, err := sut.AddLoginFailure(ctx, key, now, policy)
+		require.NoError(t, err)
+
+		got, err := sut.GetLoginAttempts(ctx, key)
+
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+	t.Run("get login attempts of key without failures", func(t *testing.T) {
+		sut, tearDown := c.NewLoginAttemptRepository()
+		t.Cleanup(tearDown)
+
+		_, err := sut.GetLoginAttempts(context.Background(), key)
+
+		require.ErrorIs(t, err, ErrLoginAttemptsNotFound)
+	})
+	t.Run("lock login", func(t *testing.T) {
+		sut, tearDown := c.NewLoginAttemptRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		_, err := sut.AddLoginFailure(ctx, key, now, policy)
+		require.NoError(t, err)
+		until := now.Add(time.Minute)
+
+		err = sut.LockLogin(ctx, key, until)
+
+		require.NoError(t, err)
+		got, err := sut.GetLoginAttempts(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, until, got.LockedUntil)
+		assert.Equal(t, 1, got.Failures)
+	})
+	t.Run("lock login of key without failures", func(t *testing.T) {
+		sut, tearDown := c.NewLoginAttemptRepository()
+		t.Cleanup(tearDown)
+
+		err := sut.LockLogin(context.Background(), key, now)
+
+		require.ErrorIs(t, err, ErrLoginAttemptsNotFound)
+	})
+	t.Run("remove login failure", func(t *testing.T) {
+		sut, tearDown := c.NewLoginAttemptRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		_, err := sut.AddLoginFailure(ctx, key, now, policy)
+		require.NoError(t, err)
+
+		err = sut.RemoveLoginFailure(ctx, key)
+
+		require.NoError(t, err)
+		got, err := sut.GetLoginAttempts(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, 0, got.Failures)
+		require.NoError(t, sut.RemoveLoginFailure(ctx, key))
+		got, err = sut.GetLoginAttempts(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, 0, got.Failures)
+	})
+	t.Run("remove login failure of key without failures", func(t *testing.T) {
+		sut, tearDown := c.NewLoginAttemptRepository()
+		t.Cleanup(tearDown)
+
+		err := sut.RemoveLoginFailure(context.Background(), key)
+
+		require.NoError(t, err)
+	})
+	t.Run("delete login attempts", func(t *testing.T) {
+		sut, tearDown := c.NewLoginAttemptRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		_, err := sut.AddLoginFailure(ctx, key, now, policy)
+		require.NoError(t, err)
+
+		err = sut.DeleteLoginAttempts(ctx, key)
+
+		require.NoError(t, err)
+		_, err = sut.GetLoginAttempts(ctx, key)
+		require.ErrorIs(t, err, ErrLoginAttemptsNotFound)
+	})
+	t.Run("delete login attempts of key without failures", func(t *testing.T) {
+		sut, tearDown := c.NewLoginAttemptRepository()
+		t.Cleanup(tearDown)
+
+		err := sut.DeleteLoginAttempts(context.Background(), key)
+
+		require.NoError(t, err)
+	})
+}
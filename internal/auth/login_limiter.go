@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// LoginLimiter slows down password guessing: the login of a key is locked for a growing time after failures.
+// The key identifies what is protected, e.g. an account or a client address.
+type LoginLimiter interface {
+	// RetryAfter returns how long the login of the key is locked, zero if the login is allowed.
+	RetryAfter(ctx context.Context, key string) (time.Duration, error)
+	// Reserve counts the login of the key as failed before the credentials are checked, so concurrent logins
+	// do not pass the limit. It returns how long the login is locked and counts nothing if it is locked.
+	Reserve(ctx context.Context, key string) (time.Duration, error)
+	// Release uncounts the reserved login of the key that has succeeded.
+	Release(ctx context.Context, key string) error
+	// Reset forgets the failures of the key once the login succeeds.
+	Reset(ctx context.Context, key string) error
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+const accessTokenSize = 32
+
+// AccessScope is what the personal access token is allowed to do with secrets.
+type AccessScope string
+
+const (
+	ScopeRead      AccessScope = "read"
+	ScopeReadWrite AccessScope = "read_write"
+)
+
+// IsValid reports whether the scope is known.
+func (s AccessScope) IsValid() bool {
+	return s == ScopeRead || s == ScopeReadWrite
+}
+
+// AccessToken is the personal access token automation presents instead of the password of the user.
+// Only the hash of the token is stored, the token itself is shown to the user once when it is created.
+type AccessToken struct {
+	CreatedAt time.Time
+	// ExpiresAt is zero if the token never expires.
+	ExpiresAt time.Time
+	Name      string
+	Scope     AccessScope
+	Hash      []byte
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	// VaultID limits the token to the shared vault, the token is accepted in all vaults of the user if it is nil.
+	VaultID uuid.UUID
+}
+
+// NewAccessToken returns the random token with the prefix that tells it from the access tokens of sessions
+// and fills the hash of the token along with its id and creation time.
+func NewAccessToken(token *AccessToken) (string, error) {
+	const op = "new access token"
+
+	b, err := utils.GenerateRandom(accessTokenSize)
+	if err != nil {
+		return "", errors.Wrap(err, op)
+	}
+
+	s := utils.AccessTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	token.ID = uuid.New()
+	token.Hash = HashAccessToken(s)
+	// the time is kept with precision supported by the storages
+	token.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	token.ExpiresAt = token.ExpiresAt.UTC().Truncate(time.Microsecond)
+	return s, nil
+}
+
+// HashAccessToken returns the hash the access token is stored by.
+func HashAccessToken(token string) []byte {
+	h := sha256.Sum256([]byte(token))
+	return h[:]
+}
+
+// IsExpired reports whether the token is expired at the time.
+func (t *AccessToken) IsExpired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt)
+}
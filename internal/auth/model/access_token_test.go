@@ -0,0 +1,52 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+func TestNewAccessToken(t *testing.T) {
+	stored := &AccessToken{Name: "ci", Scope: ScopeRead}
+
+	got, err := NewAccessToken(stored)
+
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(got, utils.AccessTokenPrefix))
+	assert.Equal(t, HashAccessToken(got), stored.Hash)
+	assert.NotEqual(t, uuid.Nil, stored.ID)
+	assert.False(t, stored.CreatedAt.IsZero())
+	assert.True(t, stored.ExpiresAt.IsZero())
+}
+
+func TestAccessToken_IsExpired(t *testing.T) {
+	now := time.Now()
+
+	t.Run("token is expired", func(t *testing.T) {
+		sut := &AccessToken{ExpiresAt: now}
+
+		assert.True(t, sut.IsExpired(now))
+	})
+	t.Run("token is not expired", func(t *testing.T) {
+		sut := &AccessToken{ExpiresAt: now.Add(time.Minute)}
+
+		assert.False(t, sut.IsExpired(now))
+	})
+	t.Run("token never expires", func(t *testing.T) {
+		sut := &AccessToken{}
+
+		assert.False(t, sut.IsExpired(now))
+	})
+}
+
+func TestAccessScope_IsValid(t *testing.T) {
+	assert.True(t, ScopeRead.IsValid())
+	assert.True(t, ScopeReadWrite.IsValid())
+	assert.False(t, AccessScope("admin").IsValid())
+}
@@ -0,0 +1,62 @@
+package model
+
+import "time"
+
+// LoginAttempts are the recent failed logins of an account or a client address identified by the key.
+type LoginAttempts struct {
+	LastFailure time.Time
+	LockedUntil time.Time
+	Key         string
+	Failures    int
+}
+
+// LoginPolicy tells how long the login is locked after a failure.
+type LoginPolicy struct {
+	// FreeFailures are the failures allowed before the login is locked.
+	FreeFailures int
+	// BaseDelay is the lock after the first failure over the free ones, it doubles with every next failure.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Window is how long failures are counted, the count starts over after the window without failures.
+	Window time.Duration
+}
+
+var (
+	// AccountLoginPolicy protects an account from password guessing.
+	AccountLoginPolicy = LoginPolicy{
+		FreeFailures: 5,
+		BaseDelay:    time.Second,
+		MaxDelay:     15 * time.Minute,
+		Window:       time.Hour,
+	}
+	// AddressLoginPolicy protects all accounts from password guessing from the same address,
+	// it allows more failures since users behind the same address share it.
+	AddressLoginPolicy = LoginPolicy{
+		FreeFailures: 20,
+		BaseDelay:    time.Second,
+		MaxDelay:     15 * time.Minute,
+		Window:       time.Hour,
+	}
+)
+
+// Delay returns how long the login is locked after the failures.
+func (p LoginPolicy) Delay(failures int) time.Duration {
+	n := failures - p.FreeFailures
+	if n <= 0 {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < n && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxDelay)
+}
+
+// RetryAfter returns how long the login is still locked at the time.
+func (a *LoginAttempts) RetryAfter(now time.Time) time.Duration {
+	if !now.Before(a.LockedUntil) {
+		return 0
+	}
+	return a.LockedUntil.Sub(now)
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginPolicy_Delay(t *testing.T) {
+	sut := LoginPolicy{
+		FreeFailures: 3,
+		BaseDelay:    time.Second,
+		MaxDelay:     time.Minute,
+	}
+
+	tests := []struct {
+		name     string
+		failures int
+		want     time.Duration
+	}{
+		{name: "no failures", failures: 0, want: 0},
+		{name: "free failures", failures: 3, want: 0},
+		{name: "first failure over free ones", failures: 4, want: time.Second},
+		{name: "delay doubles", failures: 6, want: 4 * time.Second},
+		{name: "delay is limited", failures: 10, want: time.Minute},
+		{name: "many failures", failures: 1000, want: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sut.Delay(tt.failures)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoginAttempts_RetryAfter(t *testing.T) {
+	now := time.Now()
+
+	t.Run("login is locked", func(t *testing.T) {
+		sut := &LoginAttempts{LockedUntil: now.Add(time.Minute)}
+
+		assert.Equal(t, time.Minute, sut.RetryAfter(now))
+	})
+	t.Run("lock is expired", func(t *testing.T) {
+		sut := &LoginAttempts{LockedUntil: now}
+
+		assert.Equal(t, time.Duration(0), sut.RetryAfter(now))
+	})
+	t.Run("login has never been locked", func(t *testing.T) {
+		sut := &LoginAttempts{}
+
+		assert.Equal(t, time.Duration(0), sut.RetryAfter(now))
+	})
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+const refreshTokenSize = 32
+
+// Session is a login of a user on a device. Access tokens of the session are accepted until it is revoked.
+type Session struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Revoked   bool
+}
+
+// RefreshToken is issued to the client of the session to get the next access token.
+// Every token is used once: the client gets the next refresh token along with the access token.
+// Only the hash of the token is stored, so stolen storage does not reveal the tokens.
+type RefreshToken struct {
+	ExpiresAt time.Time
+	Hash      []byte
+	SessionID uuid.UUID
+	Used      bool
+}
+
+// SessionGrant is the session started or continued for the client along with its new refresh token.
+type SessionGrant struct {
+	RefreshToken string
+	SessionID    uuid.UUID
+	UserID       uuid.UUID
+}
+
+// NewRefreshToken returns the random token of the session that expires in the given time and its stored form.
+func NewRefreshToken(sessionID uuid.UUID, expiresIn time.Duration) (string, *RefreshToken, error) {
+	const op = "new refresh token"
+
+	b, err := utils.GenerateRandom(refreshTokenSize)
+	if err != nil {
+		return "", nil, errors.Wrap(err, op)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+	stored := &RefreshToken{
+		Hash:      HashRefreshToken(token),
+		SessionID: sessionID,
+		// the time is kept with precision supported by the storages
+		ExpiresAt: time.Now().Add(expiresIn).UTC().Truncate(time.Microsecond),
+	}
+	return token, stored, nil
+}
+
+// HashRefreshToken returns the hash the refresh token is stored by.
+func HashRefreshToken(token string) []byte {
+	h := sha256.Sum256([]byte(token))
+	return h[:]
+}
+
+// IsExpired reports whether the token is expired at the time.
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,151 @@
+package model
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+const (
+	TOTPIssuer         = "GophKeeper"
+	RecoveryCodesCount = 10
+	recoveryCodeSize   = 10
+	totpPeriod         = 30
+	// totpSkew accepts the code of the previous and the next period, so clocks of the devices may differ a bit
+	totpSkew = 1
+	// sealedSecretPrefix tells the sealed secrets from the ones stored before the secrets were sealed
+	sealedSecretPrefix = "sealed:"
+)
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TwoFactor is the second factor of the user: the secret shared with the authenticator app of the user.
+// The second factor is required at login once the user confirms it with the code from the app.
+type TwoFactor struct {
+	Secret string
+	// LastTimeStep is the time step of the last accepted code, the codes of this and earlier steps are rejected.
+	LastTimeStep int64
+	UserID       uuid.UUID
+	Enabled      bool
+}
+
+// TwoFactorEnrollment is what the user needs to set up the authenticator app and to log in without it.
+type TwoFactorEnrollment struct {
+	URI           string
+	RecoveryCodes []string
+}
+
+// NewTwoFactor returns the new second factor of the user along with the enrollment shown to the user once
+// and the hashes of the recovery codes to store.
+func NewTwoFactor(userID uuid.UUID, accountName string) (*TwoFactor, *TwoFactorEnrollment, [][]byte, error) {
+	const op = "new two factor"
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      TOTPIssuer,
+		AccountName: accountName,
+		Period:      totpPeriod,
+	})
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, op)
+	}
+
+	codes := make([]string, RecoveryCodesCount)
+	hashes := make([][]byte, RecoveryCodesCount)
+	for i := 0; i < RecoveryCodesCount; i++ {
+		b, err := utils.GenerateRandom(recoveryCodeSize)
+		if err != nil {
+			return nil, nil, nil, errors.Wrap(err, op)
+		}
+		codes[i] = strings.ToLower(recoveryEncoding.EncodeToString(b))
+		hashes[i] = HashRecoveryCode(codes[i])
+	}
+
+	f := &TwoFactor{UserID: userID, Secret: key.Secret()}
+	enrollment := &TwoFactorEnrollment{URI: key.URL(), RecoveryCodes: codes}
+	return f, enrollment, hashes, nil
+}
+
+// HashRecoveryCode returns the hash the recovery code is stored by. The code is accepted in any case.
+func HashRecoveryCode(code string) []byte {
+	h := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(code))))
+	return h[:]
+}
+
+// ValidateCode returns the time step of the code if the code is generated by the authenticator app at the time
+// and the step is later than the step of the last accepted code, so the code is accepted once.
+func (f *TwoFactor) ValidateCode(code string, now time.Time) (int64, bool) {
+	opts := totp.ValidateOpts{
+		Period:    totpPeriod,
+		Skew:      totpSkew,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	}
+	code = strings.TrimSpace(code)
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= f.LastTimeStep {
+			continue
+		}
+		want, err := totp.GenerateCodeCustom(f.Secret, time.Unix(step*totpPeriod, 0), opts)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(want), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// Seal returns the copy of the second factor with the secret sealed with the key,
+// so the stored secret does not generate codes without the key.
+func (f *TwoFactor) Seal(key []byte) (*TwoFactor, error) {
+	const op = "seal two factor"
+
+	ciphertext, err := utils.NewBlockCipher(key).Seal([]byte(f.Secret))
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	sealed := *f
+	sealed.Secret = sealedSecretPrefix + base64.StdEncoding.EncodeToString(ciphertext)
+	return &sealed, nil
+}
+
+// IsSealed reports whether the secret is sealed. The secrets stored before the secrets were sealed are not.
+func (f *TwoFactor) IsSealed() bool {
+	return strings.HasPrefix(f.Secret, sealedSecretPrefix)
+}
+
+// Unseal returns the copy of the second factor with the secret unsealed with the key.
+// The secret that is not sealed is kept as is.
+func (f *TwoFactor) Unseal(key []byte) (*TwoFactor, error) {
+	const op = "unseal two factor"
+
+	unsealed := *f
+	if !f.IsSealed() {
+		return &unsealed, nil
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(f.Secret, sealedSecretPrefix))
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	plaintext, err := utils.NewBlockCipher(key).Unseal(ciphertext)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	unsealed.Secret = string(plaintext)
+	return &unsealed, nil
+}
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+func TestNewTwoFactor(t *testing.T) {
+	userID := uuid.New()
+
+	got, enrollment, hashes, err := NewTwoFactor(userID, "user@email.com")
+
+	require.NoError(t, err)
+	assert.Equal(t, userID, got.UserID)
+	assert.False(t, got.Enabled)
+	assert.True(t, strings.HasPrefix(enrollment.URI, "otpauth://totp/"+TOTPIssuer+":user@email.com?"))
+	assert.Contains(t, enrollment.URI, "secret="+got.Secret)
+	require.Len(t, enrollment.RecoveryCodes, RecoveryCodesCount)
+	require.Len(t, hashes, RecoveryCodesCount)
+	for i, code := range enrollment.RecoveryCodes {
+		assert.Equal(t, HashRecoveryCode(code), hashes[i])
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	assert.Equal(t, HashRecoveryCode("abcd"), HashRecoveryCode(" ABCD "))
+	assert.NotEqual(t, HashRecoveryCode("abcd"), HashRecoveryCode("abce"))
+}
+
+func TestTwoFactor_ValidateCode(t *testing.T) {
+	sut, _, _, err := NewTwoFactor(uuid.New(), "user@email.com")
+	require.NoError(t, err)
+	now := time.Now()
+	step := now.Unix() / totpPeriod
+
+	t.Run("code of current period", func(t *testing.T) {
+		code, err := totp.GenerateCode(sut.Secret, now)
+		require.NoError(t, err)
+
+		got, ok := sut.ValidateCode(code, now)
+
+		assert.True(t, ok)
+		assert.Equal(t, step, got)
+	})
+	t.Run("code of previous period", func(t *testing.T) {
+		code, err := totp.GenerateCode(sut.Secret, now.Add(-totpPeriod*time.Second))
+		require.NoError(t, err)
+
+		got, ok := sut.ValidateCode(code, now)
+
+		assert.True(t, ok)
+		assert.Equal(t, step-1, got)
+	})
+	t.Run("expired code", func(t *testing.T) {
+		code, err := totp.GenerateCode(sut.Secret, now.Add(-time.Hour))
+		require.NoError(t, err)
+
+		_, ok := sut.ValidateCode(code, now)
+
+		assert.False(t, ok)
+	})
+	t.Run("code is not a number", func(t *testing.T) {
+		_, ok := sut.ValidateCode("code", now)
+
+		assert.False(t, ok)
+	})
+	t.Run("code of accepted step", func(t *testing.T) {
+		used := *sut
+		used.LastTimeStep = step
+		code, err := totp.GenerateCode(sut.Secret, now)
+		require.NoError(t, err)
+
+		_, ok := used.ValidateCode(code, now)
+
+		assert.False(t, ok)
+	})
+}
+
+func TestTwoFactor_Seal(t *testing.T) {
+	key, err := utils.GenerateRandomAES256Key()
+	require.NoError(t, err)
+	f, _, _, err := NewTwoFactor(uuid.New(), "user@email.com")
+	require.NoError(t, err)
+
+	t.Run("sealed secret is unsealed", func(t *testing.T) {
+		sealed, err := f.Seal(key)
+		require.NoError(t, err)
+
+		got, err := sealed.Unseal(key)
+
+		require.NoError(t, err)
+		assert.True(t, sealed.IsSealed())
+		assert.NotContains(t, sealed.Secret, f.Secret)
+		assert.Equal(t, f, got)
+	})
+	t.Run("secret that is not sealed is kept", func(t *testing.T) {
+		got, err := f.Unseal(key)
+
+		require.NoError(t, err)
+		assert.False(t, f.IsSealed())
+		assert.Equal(t, f, got)
+	})
+	t.Run("secret sealed with another key", func(t *testing.T) {
+		another, err := utils.GenerateRandomAES256Key()
+		require.NoError(t, err)
+		sealed, err := f.Seal(another)
+		require.NoError(t, err)
+
+		_, err = sealed.Unseal(key)
+
+		require.Error(t, err)
+	})
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"sync"
+
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/bcrypt"
@@ -10,6 +12,12 @@ const (
 	PasswordMaxLengthInBytes = 72 // limitation from bcrypt.GenerateFromPassword
 )
 
+// dummyHash is the password hash of no user, it is computed once it is needed.
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+	return hash
+})
+
 type User struct {
 	Email    string
 	Password string
@@ -32,3 +40,9 @@ func (u *User) ComparePassword(password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
 	return err == nil
 }
+
+// CompareDummyPassword takes as long as the comparison of the password of a user,
+// so the login of an unknown user can not be told apart by the response time.
+func CompareDummyPassword(password string) {
+	_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+}
@@ -67,3 +67,11 @@ func assertEqualPasswords(t *testing.T, password, hashedPassword string) {
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 	assert.NoError(t, err)
 }
+
+func TestCompareDummyPassword(t *testing.T) {
+	// the comparison takes as long as the one of a user password if the hashes have the same cost
+	cost, err := bcrypt.Cost(dummyHash())
+
+	require.NoError(t, err)
+	assert.Equal(t, bcrypt.DefaultCost, cost)
+}
@@ -0,0 +1,85 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+)
+
+type accessTokenRepository struct {
+	tokens map[string]model.AccessToken
+	mu     sync.Mutex
+}
+
+func NewAccessTokenRepository() auth.AccessTokenRepository {
+	return &accessTokenRepository{
+		tokens: make(map[string]model.AccessToken),
+	}
+}
+
+func (r *accessTokenRepository) CreateAccessToken(ctx context.Context, token *model.AccessToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens[string(token.Hash)] = *token
+	return nil
+}
+
+func (r *accessTokenRepository) FindAccessToken(ctx context.Context, hash []byte) (*model.AccessToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[string(hash)]
+	if !ok {
+		return nil, auth.ErrAccessTokenNotFound
+	}
+	return &token, nil
+}
+
+func (r *accessTokenRepository) ListAccessTokens(ctx context.Context,
+	userID uuid.UUID) ([]*model.AccessToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tokens := make([]*model.AccessToken, 0)
+	for _, token := range r.tokens {
+		if token.UserID == userID {
+			token := token
+			tokens = append(tokens, &token)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+	})
+	return tokens, nil
+}
+
+func (r *accessTokenRepository) DeleteAccessToken(ctx context.Context, userID, tokenID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, token := range r.tokens {
+		if token.ID == tokenID && token.UserID == userID {
+			delete(r.tokens, hash)
+			return nil
+		}
+	}
+	return auth.ErrAccessTokenNotFound
+}
+
+func (r *accessTokenRepository) DeleteUserAccessTokens(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, token := range r.tokens {
+		if token.UserID == userID {
+			delete(r.tokens, hash)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package inmemory
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+)
+
+func TestAccessTokenRepository(t *testing.T) {
+	auth.AccessTokenRepositoryContract{
+		NewAccessTokenRepository: func() (auth.AccessTokenRepository, func(), auth.AccessTokenTestData) {
+			t.Helper()
+
+			r := NewAccessTokenRepository()
+			testData := auth.AccessTokenTestData{
+				Users: uuid.UUIDs{uuid.New(), uuid.New()},
+			}
+			return r, func() {}, testData
+		},
+	}.Test(t)
+}
@@ -0,0 +1,92 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+)
+
+type loginAttemptRepository struct {
+	attempts map[string]model.LoginAttempts
+	mu       sync.Mutex
+}
+
+func NewLoginAttemptRepository() auth.LoginAttemptRepository {
+	return &loginAttemptRepository{
+		attempts: make(map[string]model.LoginAttempts),
+	}
+}
+
+func (r *loginAttemptRepository) GetLoginAttempts(ctx context.Context, key string) (*model.LoginAttempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.attempts[key]
+	if !ok {
+		return nil, auth.ErrLoginAttemptsNotFound
+	}
+	return &a, nil
+}
+
+func (r *loginAttemptRepository) AddLoginFailure(ctx context.Context, key string,
+	at time.Time, policy model.LoginPolicy) (*model.LoginAttempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.attempts[key]
+	if !ok {
+		a = model.LoginAttempts{Key: key}
+	}
+	if a.RetryAfter(at) > 0 {
+		return nil, auth.ErrLoginLocked
+	}
+	if a.LastFailure.Before(at.Add(-policy.Window)) {
+		a.Failures = 0
+	}
+	a.Failures++
+	a.LastFailure = at
+	if a.Failures > policy.FreeFailures {
+		a.LockedUntil = at.Add(policy.BaseDelay)
+	}
+	r.attempts[key] = a
+	return &a, nil
+}
+
+func (r *loginAttemptRepository) LockLogin(ctx context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.attempts[key]
+	if !ok {
+		return auth.ErrLoginAttemptsNotFound
+	}
+
+	a.LockedUntil = until
+	r.attempts[key] = a
+	return nil
+}
+
+func (r *loginAttemptRepository) RemoveLoginFailure(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.attempts[key]
+	if !ok || a.Failures == 0 {
+		return nil
+	}
+
+	a.Failures--
+	r.attempts[key] = a
+	return nil
+}
+
+func (r *loginAttemptRepository) DeleteLoginAttempts(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+	return nil
+}
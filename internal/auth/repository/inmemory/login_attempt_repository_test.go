@@ -0,0 +1,17 @@
+package inmemory
+
+import (
+	"testing"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+)
+
+func TestLoginAttemptRepository(t *testing.T) {
+	auth.LoginAttemptRepositoryContract{
+		NewLoginAttemptRepository: func() (auth.LoginAttemptRepository, func()) {
+			t.Helper()
+
+			return NewLoginAttemptRepository(), func() {}
+		},
+	}.Test(t)
+}
@@ -0,0 +1,118 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+)
+
+type sessionRepository struct {
+	sessions map[uuid.UUID]model.Session
+	tokens   map[string]model.RefreshToken
+	mu       sync.Mutex
+}
+
+func NewSessionRepository() auth.SessionRepository {
+	return &sessionRepository{
+		sessions: make(map[uuid.UUID]model.Session),
+		tokens:   make(map[string]model.RefreshToken),
+	}
+}
+
+func (r *sessionRepository) CreateSession(ctx context.Context, session *model.Session,
+	token *model.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sessions[session.ID] = *session
+	r.tokens[string(token.Hash)] = *token
+	return nil
+}
+
+func (r *sessionRepository) GetSession(ctx context.Context, sessionID uuid.UUID) (*model.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[sessionID]
+	if !ok {
+		return nil, auth.ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) FindRefreshToken(ctx context.Context, hash []byte) (*model.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[string(hash)]
+	if !ok {
+		return nil, auth.ErrRefreshTokenNotFound
+	}
+	return &token, nil
+}
+
+func (r *sessionRepository) UseRefreshToken(ctx context.Context, hash []byte, next *model.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[string(hash)]
+	if !ok {
+		return auth.ErrRefreshTokenNotFound
+	}
+	if token.Used {
+		return auth.ErrRefreshTokenUsed
+	}
+
+	token.Used = true
+	r.tokens[string(hash)] = token
+	r.tokens[string(next.Hash)] = *next
+	return nil
+}
+
+func (r *sessionRepository) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[sessionID]
+	if !ok {
+		return auth.ErrSessionNotFound
+	}
+
+	session.Revoked = true
+	r.sessions[sessionID] = session
+	return nil
+}
+
+func (r *sessionRepository) RevokeUserSessions(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, session := range r.sessions {
+		if session.UserID == userID {
+			session.Revoked = true
+			r.sessions[id] = session
+		}
+	}
+	return nil
+}
+
+func (r *sessionRepository) DeleteUserSessions(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, session := range r.sessions {
+		if session.UserID == userID {
+			delete(r.sessions, id)
+		}
+	}
+	for hash, token := range r.tokens {
+		if _, ok := r.sessions[token.SessionID]; !ok {
+			delete(r.tokens, hash)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package inmemory
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+)
+
+func TestSessionRepository(t *testing.T) {
+	auth.SessionRepositoryContract{
+		NewSessionRepository: func() (auth.SessionRepository, func(), auth.SessionTestData) {
+			t.Helper()
+
+			r := NewSessionRepository()
+			testData := auth.SessionTestData{
+				Users: uuid.UUIDs{uuid.New(), uuid.New()},
+			}
+			return r, func() {}, testData
+		},
+	}.Test(t)
+}
@@ -0,0 +1,103 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+)
+
+type twoFactorRepository struct {
+	factors map[uuid.UUID]model.TwoFactor
+	codes   map[uuid.UUID]map[string]struct{}
+	mu      sync.Mutex
+}
+
+func NewTwoFactorRepository() auth.TwoFactorRepository {
+	return &twoFactorRepository{
+		factors: make(map[uuid.UUID]model.TwoFactor),
+		codes:   make(map[uuid.UUID]map[string]struct{}),
+	}
+}
+
+func (r *twoFactorRepository) SaveTwoFactor(ctx context.Context, f *model.TwoFactor, recoveryCodes [][]byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	codes := make(map[string]struct{}, len(recoveryCodes))
+	for _, hash := range recoveryCodes {
+		codes[string(hash)] = struct{}{}
+	}
+	r.factors[f.UserID] = *f
+	r.codes[f.UserID] = codes
+	return nil
+}
+
+func (r *twoFactorRepository) GetTwoFactor(ctx context.Context, userID uuid.UUID) (*model.TwoFactor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.factors[userID]
+	if !ok {
+		return nil, auth.ErrTwoFactorNotFound
+	}
+	return &f, nil
+}
+
+func (r *twoFactorRepository) EnableTwoFactor(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.factors[userID]
+	if !ok {
+		return auth.ErrTwoFactorNotFound
+	}
+
+	f.Enabled = true
+	r.factors[userID] = f
+	return nil
+}
+
+func (r *twoFactorRepository) UpdateTwoFactorSecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.factors[userID]
+	if !ok {
+		return auth.ErrTwoFactorNotFound
+	}
+
+	f.Secret = secret
+	r.factors[userID] = f
+	return nil
+}
+
+func (r *twoFactorRepository) UseTimeStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.factors[userID]
+	if !ok || f.LastTimeStep >= step {
+		return auth.ErrTimeStepUsed
+	}
+
+	f.LastTimeStep = step
+	r.factors[userID] = f
+	return nil
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	codes := r.codes[userID]
+	if _, ok := codes[string(hash)]; !ok {
+		return auth.ErrRecoveryCodeNotFound
+	}
+
+	delete(codes, string(hash))
+	return nil
+}
@@ -0,0 +1,23 @@
+package inmemory
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+)
+
+func TestTwoFactorRepository(t *testing.T) {
+	auth.TwoFactorRepositoryContract{
+		NewTwoFactorRepository: func() (auth.TwoFactorRepository, func(), auth.TwoFactorTestData) {
+			t.Helper()
+
+			r := NewTwoFactorRepository()
+			testData := auth.TwoFactorTestData{
+				Users: uuid.UUIDs{uuid.New(), uuid.New()},
+			}
+			return r, func() {}, testData
+		},
+	}.Test(t)
+}
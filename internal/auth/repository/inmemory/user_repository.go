@@ -56,3 +56,53 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (*model.
 
 	return nil, auth.ErrUserIsNotRegistered
 }
+
+func (r *userRepository) FindByID(ctx context.Context, userID uuid.UUID) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.ID == userID {
+			return user, nil
+		}
+	}
+
+	return nil, auth.ErrUserIsNotRegistered
+}
+
+func (r *userRepository) ChangePassword(ctx context.Context, userID uuid.UUID, password string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if password == "" {
+		return auth.ErrUserPasswordIsEmpty
+	}
+
+	for email, user := range r.users {
+		if user.ID == userID {
+			// the user found before keeps its password
+			r.users[email] = &model.User{
+				ID:       user.ID,
+				Email:    user.Email,
+				Password: password,
+			}
+			return nil
+		}
+	}
+
+	return auth.ErrUserIsNotRegistered
+}
+
+func (r *userRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for email, user := range r.users {
+		if user.ID == userID {
+			delete(r.users, email)
+			return nil
+		}
+	}
+
+	return auth.ErrUserIsNotRegistered
+}
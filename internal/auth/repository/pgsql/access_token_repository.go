@@ -0,0 +1,144 @@
+package pgsql
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+	pgstorage "github.com/nestjam/goph-keeper/internal/storage/pgsql"
+)
+
+const accessTokenColumns = `token_id, user_id, name, token_hash, scope, vault_id, created_at, expires_at`
+
+type accessTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAccessTokenRepository(pool *pgxpool.Pool) *accessTokenRepository {
+	return &accessTokenRepository{pool}
+}
+
+func (r *accessTokenRepository) CreateAccessToken(ctx context.Context, token *model.AccessToken) error {
+	const op = "create access token"
+
+	var (
+		vaultID   *uuid.UUID
+		expiresAt *time.Time
+	)
+	if token.VaultID != uuid.Nil {
+		vaultID = &token.VaultID
+	}
+	if !token.ExpiresAt.IsZero() {
+		expiresAt = &token.ExpiresAt
+	}
+
+	const sql = `INSERT INTO access_tokens (` + accessTokenColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err := r.querier(ctx).Exec(ctx, sql, token.ID, token.UserID, token.Name, token.Hash, token.Scope,
+		vaultID, token.CreatedAt, expiresAt)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (r *accessTokenRepository) FindAccessToken(ctx context.Context, hash []byte) (*model.AccessToken, error) {
+	const op = "find access token"
+
+	const sql = `SELECT ` + accessTokenColumns + ` FROM access_tokens WHERE token_hash=$1`
+	t, err := scanAccessToken(r.querier(ctx).QueryRow(ctx, sql, hash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, auth.ErrAccessTokenNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return t, nil
+}
+
+func (r *accessTokenRepository) ListAccessTokens(ctx context.Context,
+	userID uuid.UUID) ([]*model.AccessToken, error) {
+	const op = "list access tokens"
+
+	const sql = `SELECT ` + accessTokenColumns + ` FROM access_tokens WHERE user_id=$1 ORDER BY created_at`
+	rows, err := r.querier(ctx).Query(ctx, sql, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	defer rows.Close()
+
+	tokens := make([]*model.AccessToken, 0)
+	for rows.Next() {
+		t, err := scanAccessToken(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+		tokens = append(tokens, t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return tokens, nil
+}
+
+func (r *accessTokenRepository) DeleteAccessToken(ctx context.Context, userID, tokenID uuid.UUID) error {
+	const op = "delete access token"
+
+	const sql = `DELETE FROM access_tokens WHERE token_id=$1 AND user_id=$2`
+	tag, err := r.querier(ctx).Exec(ctx, sql, tokenID, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if tag.RowsAffected() == 0 {
+		return auth.ErrAccessTokenNotFound
+	}
+
+	return nil
+}
+
+func (r *accessTokenRepository) DeleteUserAccessTokens(ctx context.Context, userID uuid.UUID) error {
+	const op = "delete user access tokens"
+
+	_, err := r.querier(ctx).Exec(ctx, `DELETE FROM access_tokens WHERE user_id=$1`, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// querier returns transaction of the context if any.
+func (r *accessTokenRepository) querier(ctx context.Context) pgstorage.Querier {
+	return pgstorage.QuerierFromContext(ctx, r.pool)
+}
+
+func scanAccessToken(row pgx.Row) (*model.AccessToken, error) {
+	const op = "scan access token"
+
+	var (
+		t         model.AccessToken
+		vaultID   *uuid.UUID
+		expiresAt *time.Time
+	)
+	err := row.Scan(&t.ID, &t.UserID, &t.Name, &t.Hash, &t.Scope, &vaultID, &t.CreatedAt, &expiresAt)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	t.CreatedAt = t.CreatedAt.UTC()
+	if vaultID != nil {
+		t.VaultID = *vaultID
+	}
+	if expiresAt != nil {
+		t.ExpiresAt = expiresAt.UTC()
+	}
+	return &t, nil
+}
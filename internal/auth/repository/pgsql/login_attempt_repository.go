@@ -0,0 +1,127 @@
+package pgsql
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+	pgstorage "github.com/nestjam/goph-keeper/internal/storage/pgsql"
+)
+
+type loginAttemptRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLoginAttemptRepository(pool *pgxpool.Pool) *loginAttemptRepository {
+	return &loginAttemptRepository{pool}
+}
+
+func (r *loginAttemptRepository) GetLoginAttempts(ctx context.Context, key string) (*model.LoginAttempts, error) {
+	const op = "get login attempts"
+
+	const sql = `SELECT attempt_key, failures, last_failure, locked_until FROM login_attempts WHERE attempt_key=$1`
+	a, err := scanLoginAttempts(r.querier(ctx).QueryRow(ctx, sql, key))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, auth.ErrLoginAttemptsNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return a, nil
+}
+
+func (r *loginAttemptRepository) AddLoginFailure(ctx context.Context, key string,
+	at time.Time, policy model.LoginPolicy) (*model.LoginAttempts, error) {
+	const op = "add login failure"
+
+	// the lock is checked and the failure is counted in a single statement,
+	// so concurrent failures are not lost and do not pass the lock
+	const sql = `INSERT INTO login_attempts (attempt_key, failures, last_failure, locked_until)
+VALUES ($1, 1, $2, CASE WHEN 1 > $4 THEN $5::timestamptz END)
+ON CONFLICT (attempt_key) DO UPDATE SET
+failures=CASE WHEN login_attempts.last_failure < $3 THEN 1 ELSE login_attempts.failures + 1 END,
+last_failure=excluded.last_failure,
+locked_until=CASE WHEN (CASE WHEN login_attempts.last_failure < $3 THEN 1 ELSE login_attempts.failures + 1 END) > $4
+THEN $5 ELSE login_attempts.locked_until END
+WHERE login_attempts.locked_until IS NULL OR login_attempts.locked_until <= $2
+RETURNING attempt_key, failures, last_failure, locked_until`
+	row := r.querier(ctx).QueryRow(ctx, sql, key, at, at.Add(-policy.Window), policy.FreeFailures,
+		at.Add(policy.BaseDelay))
+	a, err := scanLoginAttempts(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, auth.ErrLoginLocked
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return a, nil
+}
+
+func (r *loginAttemptRepository) LockLogin(ctx context.Context, key string, until time.Time) error {
+	const op = "lock login"
+
+	const sql = `UPDATE login_attempts SET locked_until=$1 WHERE attempt_key=$2`
+	tag, err := r.querier(ctx).Exec(ctx, sql, until, key)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if tag.RowsAffected() == 0 {
+		return auth.ErrLoginAttemptsNotFound
+	}
+
+	return nil
+}
+
+func (r *loginAttemptRepository) RemoveLoginFailure(ctx context.Context, key string) error {
+	const op = "remove login failure"
+
+	const sql = `UPDATE login_attempts SET failures=failures - 1 WHERE attempt_key=$1 AND failures > 0`
+	_, err := r.querier(ctx).Exec(ctx, sql, key)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (r *loginAttemptRepository) DeleteLoginAttempts(ctx context.Context, key string) error {
+	const op = "delete login attempts"
+
+	_, err := r.querier(ctx).Exec(ctx, `DELETE FROM login_attempts WHERE attempt_key=$1`, key)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// querier returns transaction of the context if any.
+func (r *loginAttemptRepository) querier(ctx context.Context) pgstorage.Querier {
+	return pgstorage.QuerierFromContext(ctx, r.pool)
+}
+
+func scanLoginAttempts(row pgx.Row) (*model.LoginAttempts, error) {
+	const op = "scan login attempts"
+
+	var (
+		a           model.LoginAttempts
+		lockedUntil *time.Time
+	)
+	err := row.Scan(&a.Key, &a.Failures, &a.LastFailure, &lockedUntil)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	a.LastFailure = a.LastFailure.UTC()
+	if lockedUntil != nil {
+		a.LockedUntil = lockedUntil.UTC()
+	}
+	return &a, nil
+}
@@ -0,0 +1,40 @@
+//go:build integration
+
+package pgsql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/config"
+	pgstorage "github.com/nestjam/goph-keeper/internal/storage/pgsql"
+	"github.com/nestjam/goph-keeper/migration"
+)
+
+func TestLoginAttemptRepository(t *testing.T) {
+	auth.LoginAttemptRepositoryContract{
+		NewLoginAttemptRepository: func() (auth.LoginAttemptRepository, func()) {
+			t.Helper()
+
+			dsn := h.DataSourceName
+			migrator := migration.NewDatabaseMigrator(dsn)
+			err := migrator.Up()
+			require.NoError(t, err)
+
+			pool, err := pgstorage.NewPool(context.Background(), config.PostgresConfig{DataSourceName: dsn})
+			require.NoError(t, err)
+			r := NewLoginAttemptRepository(pool)
+
+			closer := func() {
+				pool.Close()
+
+				migrator := migration.NewDatabaseMigrator(dsn)
+				_ = migrator.Drop()
+			}
+			return r, closer
+		},
+	}.Test(t)
+}
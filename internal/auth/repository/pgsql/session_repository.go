@@ -0,0 +1,169 @@
+package pgsql
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+	pgstorage "github.com/nestjam/goph-keeper/internal/storage/pgsql"
+)
+
+type sessionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSessionRepository(pool *pgxpool.Pool) *sessionRepository {
+	return &sessionRepository{pool}
+}
+
+func (r *sessionRepository) CreateSession(ctx context.Context, session *model.Session,
+	token *model.RefreshToken) error {
+	const op = "create session"
+
+	tx, err := pgstorage.BeginTx(ctx, r.pool)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, err = tx.Exec(ctx, `INSERT INTO sessions (session_id, user_id, created_at) VALUES ($1, $2, $3)`,
+		session.ID, session.UserID, session.CreatedAt)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = insertRefreshToken(ctx, tx, token)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) GetSession(ctx context.Context, sessionID uuid.UUID) (*model.Session, error) {
+	const op = "get session"
+
+	var s model.Session
+	const sql = `SELECT session_id, user_id, created_at, revoked FROM sessions WHERE session_id=$1`
+	err := r.querier(ctx).QueryRow(ctx, sql, sessionID).Scan(&s.ID, &s.UserID, &s.CreatedAt, &s.Revoked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, auth.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	s.CreatedAt = s.CreatedAt.UTC()
+	return &s, nil
+}
+
+func (r *sessionRepository) FindRefreshToken(ctx context.Context, hash []byte) (*model.RefreshToken, error) {
+	const op = "find refresh token"
+
+	var t model.RefreshToken
+	const sql = `SELECT token_hash, session_id, expires_at, used FROM refresh_tokens WHERE token_hash=$1`
+	err := r.querier(ctx).QueryRow(ctx, sql, hash).Scan(&t.Hash, &t.SessionID, &t.ExpiresAt, &t.Used)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, auth.ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	t.ExpiresAt = t.ExpiresAt.UTC()
+	return &t, nil
+}
+
+func (r *sessionRepository) UseRefreshToken(ctx context.Context, hash []byte, next *model.RefreshToken) error {
+	const op = "use refresh token"
+
+	tx, err := pgstorage.BeginTx(ctx, r.pool)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// the token is marked used only once, so concurrent refreshes can not both succeed
+	const sql = `UPDATE refresh_tokens SET used=true WHERE token_hash=$1 AND NOT used`
+	tag, err := tx.Exec(ctx, sql, hash)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if tag.RowsAffected() == 0 {
+		_, err = r.FindRefreshToken(ctx, hash)
+		if err != nil {
+			return err
+		}
+		return auth.ErrRefreshTokenUsed
+	}
+
+	err = insertRefreshToken(ctx, tx, next)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	const op = "revoke session"
+
+	const sql = `UPDATE sessions SET revoked=true WHERE session_id=$1`
+	tag, err := r.querier(ctx).Exec(ctx, sql, sessionID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if tag.RowsAffected() == 0 {
+		return auth.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) RevokeUserSessions(ctx context.Context, userID uuid.UUID) error {
+	const op = "revoke user sessions"
+
+	_, err := r.querier(ctx).Exec(ctx, `UPDATE sessions SET revoked=true WHERE user_id=$1`, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) DeleteUserSessions(ctx context.Context, userID uuid.UUID) error {
+	const op = "delete user sessions"
+
+	_, err := r.querier(ctx).Exec(ctx, `DELETE FROM sessions WHERE user_id=$1`, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// querier returns transaction of the context if any.
+func (r *sessionRepository) querier(ctx context.Context) pgstorage.Querier {
+	return pgstorage.QuerierFromContext(ctx, r.pool)
+}
+
+func insertRefreshToken(ctx context.Context, q pgstorage.Querier, token *model.RefreshToken) error {
+	const sql = `INSERT INTO refresh_tokens (token_hash, session_id, expires_at, used) VALUES ($1, $2, $3, $4)`
+	_, err := q.Exec(ctx, sql, token.Hash, token.SessionID, token.ExpiresAt, token.Used)
+	return err
+}
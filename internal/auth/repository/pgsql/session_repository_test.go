@@ -0,0 +1,62 @@
+//go:build integration
+
+package pgsql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+	"github.com/nestjam/goph-keeper/internal/config"
+	pgstorage "github.com/nestjam/goph-keeper/internal/storage/pgsql"
+	"github.com/nestjam/goph-keeper/migration"
+)
+
+func TestSessionRepository(t *testing.T) {
+	auth.SessionRepositoryContract{
+		NewSessionRepository: func() (auth.SessionRepository, func(), auth.SessionTestData) {
+			t.Helper()
+
+			dsn := h.DataSourceName
+			migrator := migration.NewDatabaseMigrator(dsn)
+			err := migrator.Up()
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			pool, err := pgstorage.NewPool(ctx, config.PostgresConfig{DataSourceName: dsn})
+			require.NoError(t, err)
+			r := NewSessionRepository(pool)
+
+			closer := func() {
+				pool.Close()
+
+				migrator := migration.NewDatabaseMigrator(dsn)
+				_ = migrator.Drop()
+			}
+
+			testData := auth.SessionTestData{
+				Users: setupUsers(t, pool),
+			}
+			return r, closer, testData
+		},
+	}.Test(t)
+}
+
+func setupUsers(t *testing.T, pool *pgxpool.Pool) uuid.UUIDs {
+	t.Helper()
+
+	ctx := context.Background()
+	r := NewUserRepository(pool)
+
+	userID, err := r.Register(ctx, &model.User{Email: "user@email.com", Password: "1"})
+	require.NoError(t, err)
+	user2ID, err := r.Register(ctx, &model.User{Email: "user2@email.com", Password: "2"})
+	require.NoError(t, err)
+
+	return uuid.UUIDs{userID, user2ID}
+}
@@ -0,0 +1,137 @@
+package pgsql
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+	pgstorage "github.com/nestjam/goph-keeper/internal/storage/pgsql"
+)
+
+type twoFactorRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTwoFactorRepository(pool *pgxpool.Pool) *twoFactorRepository {
+	return &twoFactorRepository{pool}
+}
+
+func (r *twoFactorRepository) SaveTwoFactor(ctx context.Context, f *model.TwoFactor, recoveryCodes [][]byte) error {
+	const op = "save two factor"
+
+	tx, err := pgstorage.BeginTx(ctx, r.pool)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// the recovery codes of the replaced second factor are deleted along with it
+	_, err = tx.Exec(ctx, `DELETE FROM two_factor WHERE user_id=$1`, f.UserID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	const sql = `INSERT INTO two_factor (user_id, secret, enabled, last_time_step) VALUES ($1, $2, $3, $4)`
+	_, err = tx.Exec(ctx, sql, f.UserID, f.Secret, f.Enabled, f.LastTimeStep)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	for _, hash := range recoveryCodes {
+		_, err = tx.Exec(ctx, `INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)`, f.UserID, hash)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (r *twoFactorRepository) GetTwoFactor(ctx context.Context, userID uuid.UUID) (*model.TwoFactor, error) {
+	const op = "get two factor"
+
+	var f model.TwoFactor
+	const sql = `SELECT user_id, secret, enabled, last_time_step FROM two_factor WHERE user_id=$1`
+	err := r.querier(ctx).QueryRow(ctx, sql, userID).Scan(&f.UserID, &f.Secret, &f.Enabled, &f.LastTimeStep)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, auth.ErrTwoFactorNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return &f, nil
+}
+
+func (r *twoFactorRepository) EnableTwoFactor(ctx context.Context, userID uuid.UUID) error {
+	const op = "enable two factor"
+
+	tag, err := r.querier(ctx).Exec(ctx, `UPDATE two_factor SET enabled=true WHERE user_id=$1`, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if tag.RowsAffected() == 0 {
+		return auth.ErrTwoFactorNotFound
+	}
+
+	return nil
+}
+
+func (r *twoFactorRepository) UpdateTwoFactorSecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	const op = "update two factor secret"
+
+	tag, err := r.querier(ctx).Exec(ctx, `UPDATE two_factor SET secret=$1 WHERE user_id=$2`, secret, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if tag.RowsAffected() == 0 {
+		return auth.ErrTwoFactorNotFound
+	}
+
+	return nil
+}
+
+func (r *twoFactorRepository) UseTimeStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	const op = "use time step"
+
+	const sql = `UPDATE two_factor SET last_time_step=$1 WHERE user_id=$2 AND last_time_step<$1`
+	tag, err := r.querier(ctx).Exec(ctx, sql, step, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if tag.RowsAffected() == 0 {
+		return auth.ErrTimeStepUsed
+	}
+
+	return nil
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash []byte) error {
+	const op = "use recovery code"
+
+	const sql = `DELETE FROM recovery_codes WHERE user_id=$1 AND code_hash=$2`
+	tag, err := r.querier(ctx).Exec(ctx, sql, userID, hash)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if tag.RowsAffected() == 0 {
+		return auth.ErrRecoveryCodeNotFound
+	}
+
+	return nil
+}
+
+// querier returns transaction of the context if any.
+func (r *twoFactorRepository) querier(ctx context.Context) pgstorage.Querier {
+	return pgstorage.QuerierFromContext(ctx, r.pool)
+}
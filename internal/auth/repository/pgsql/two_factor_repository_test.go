@@ -0,0 +1,45 @@
+//go:build integration
+
+package pgsql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/config"
+	pgstorage "github.com/nestjam/goph-keeper/internal/storage/pgsql"
+	"github.com/nestjam/goph-keeper/migration"
+)
+
+func TestTwoFactorRepository(t *testing.T) {
+	auth.TwoFactorRepositoryContract{
+		NewTwoFactorRepository: func() (auth.TwoFactorRepository, func(), auth.TwoFactorTestData) {
+			t.Helper()
+
+			dsn := h.DataSourceName
+			migrator := migration.NewDatabaseMigrator(dsn)
+			err := migrator.Up()
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			pool, err := pgstorage.NewPool(ctx, config.PostgresConfig{DataSourceName: dsn})
+			require.NoError(t, err)
+			r := NewTwoFactorRepository(pool)
+
+			closer := func() {
+				pool.Close()
+
+				migrator := migration.NewDatabaseMigrator(dsn)
+				_ = migrator.Drop()
+			}
+
+			testData := auth.TwoFactorTestData{
+				Users: setupUsers(t, pool),
+			}
+			return r, closer, testData
+		},
+	}.Test(t)
+}
@@ -12,47 +12,21 @@ import (
 
 	"github.com/nestjam/goph-keeper/internal/auth"
 	"github.com/nestjam/goph-keeper/internal/auth/model"
+	pgstorage "github.com/nestjam/goph-keeper/internal/storage/pgsql"
 )
 
 type userRepository struct {
-	pool       *pgxpool.Pool
-	connString string
+	pool *pgxpool.Pool
 }
 
-func NewUserRepository(ctx context.Context, connString string) (*userRepository, error) {
-	const op = "new user repository"
-
-	var err error
-	pool, err := initPool(ctx, connString)
-	if err != nil {
-		return nil, errors.Wrapf(err, op)
-	}
-
-	r := &userRepository{
-		pool,
-		connString,
-	}
-	return r, nil
-}
-
-func (r *userRepository) Close() {
-	if r.pool == nil {
-		return
-	}
-	r.pool.Close()
+func NewUserRepository(pool *pgxpool.Pool) *userRepository {
+	return &userRepository{pool}
 }
 
 func (r *userRepository) Register(ctx context.Context, user *model.User) (uuid.UUID, error) {
 	const op = "register"
 
-	conn, err := r.pool.Acquire(ctx)
-	if err != nil {
-		return uuid.Nil, errors.Wrap(err, op)
-	}
-	defer conn.Release()
-
-	var txOptions pgx.TxOptions
-	tx, err := conn.BeginTx(ctx, txOptions)
+	tx, err := pgstorage.BeginTx(ctx, r.pool)
 	if err != nil {
 		return uuid.Nil, errors.Wrap(err, op)
 	}
@@ -88,16 +62,12 @@ func (r *userRepository) Register(ctx context.Context, user *model.User) (uuid.U
 func (r *userRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
 	const op = "find by email"
 
-	conn, err := r.pool.Acquire(ctx)
-	if err != nil {
-		return nil, errors.Wrap(err, op)
-	}
-	defer conn.Release()
+	conn := pgstorage.QuerierFromContext(ctx, r.pool)
 
 	var user model.User
 	const sql = "SELECT user_id, email, password FROM users WHERE email=$1"
 	row := conn.QueryRow(ctx, sql, email)
-	err = row.Scan(&user.ID, &user.Email, &user.Password)
+	err := row.Scan(&user.ID, &user.Email, &user.Password)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, auth.ErrUserIsNotRegistered
 	}
@@ -108,22 +78,70 @@ func (r *userRepository) FindByEmail(ctx context.Context, email string) (*model.
 	return &user, nil
 }
 
-func initPool(ctx context.Context, connString string) (*pgxpool.Pool, error) {
-	const op = "init pool"
+func (r *userRepository) FindByID(ctx context.Context, userID uuid.UUID) (*model.User, error) {
+	const op = "find by id"
+
+	conn := pgstorage.QuerierFromContext(ctx, r.pool)
 
-	poolCfg, err := pgxpool.ParseConfig(connString)
+	var user model.User
+	const sql = "SELECT user_id, email, password FROM users WHERE user_id=$1"
+	row := conn.QueryRow(ctx, sql, userID)
+	err := row.Scan(&user.ID, &user.Email, &user.Password)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, auth.ErrUserIsNotRegistered
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, op)
 	}
 
-	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	return &user, nil
+}
+
+func (r *userRepository) ChangePassword(ctx context.Context, userID uuid.UUID, password string) error {
+	const op = "change password"
+
+	conn := pgstorage.QuerierFromContext(ctx, r.pool)
+
+	const sql = `UPDATE users SET password=$2 WHERE user_id=$1;`
+	tag, err := conn.Exec(ctx, sql, userID, password)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.CheckViolation &&
+		pgErr.ConstraintName == "users_password_check" {
+		return auth.ErrUserPasswordIsEmpty
+	}
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if tag.RowsAffected() == 0 {
+		return auth.ErrUserIsNotRegistered
+	}
+
+	return nil
+}
+
+func (r *userRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	const op = "delete"
+
+	tx, err := pgstorage.BeginTx(ctx, r.pool)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	const sql = `DELETE FROM users WHERE user_id=$1;`
+	tag, err := tx.Exec(ctx, sql, userID)
 	if err != nil {
-		return nil, errors.Wrap(err, op)
+		return errors.Wrap(err, op)
+	}
+	if tag.RowsAffected() == 0 {
+		return auth.ErrUserIsNotRegistered
 	}
 
-	if err := pool.Ping(ctx); err != nil {
-		return nil, errors.Wrap(err, op)
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.Wrap(err, op)
 	}
 
-	return pool, nil
+	return nil
 }
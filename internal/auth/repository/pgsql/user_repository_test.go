@@ -10,6 +10,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/config"
+	pgstorage "github.com/nestjam/goph-keeper/internal/storage/pgsql"
 	"github.com/nestjam/goph-keeper/internal/utils"
 	"github.com/nestjam/goph-keeper/migration"
 )
@@ -32,11 +34,12 @@ func TestUserRepository(t *testing.T) {
 			require.NoError(t, err)
 
 			ctx := context.Background()
-			r, err := NewUserRepository(ctx, dsn)
+			pool, err := pgstorage.NewPool(ctx, config.PostgresConfig{DataSourceName: dsn})
 			require.NoError(t, err)
+			r := NewUserRepository(pool)
 
 			return r, func() {
-				r.Close()
+				pool.Close()
 
 				migrator := migration.NewDatabaseMigrator(dsn)
 				_ = migrator.Drop()
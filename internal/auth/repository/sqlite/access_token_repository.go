@@ -0,0 +1,145 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+	sqlitestorage "github.com/nestjam/goph-keeper/internal/storage/sqlite"
+)
+
+const accessTokenColumns = `token_id, user_id, name, token_hash, scope, vault_id, created_at, expires_at`
+
+type accessTokenRepository struct {
+	db *sql.DB
+}
+
+func NewAccessTokenRepository(db *sql.DB) *accessTokenRepository {
+	return &accessTokenRepository{db}
+}
+
+func (r *accessTokenRepository) CreateAccessToken(ctx context.Context, token *model.AccessToken) error {
+	const op = "create access token"
+
+	vaultID := uuid.NullUUID{UUID: token.VaultID, Valid: token.VaultID != uuid.Nil}
+	var expiresAt sql.NullInt64
+	if !token.ExpiresAt.IsZero() {
+		expiresAt = sql.NullInt64{Int64: token.ExpiresAt.UnixMicro(), Valid: true}
+	}
+
+	const query = `INSERT INTO access_tokens (` + accessTokenColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.executor(ctx).ExecContext(ctx, query, token.ID, token.UserID, token.Name, token.Hash, token.Scope,
+		vaultID, token.CreatedAt.UnixMicro(), expiresAt)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (r *accessTokenRepository) FindAccessToken(ctx context.Context, hash []byte) (*model.AccessToken, error) {
+	const op = "find access token"
+
+	const query = `SELECT ` + accessTokenColumns + ` FROM access_tokens WHERE token_hash=?`
+	t, err := scanAccessToken(r.executor(ctx).QueryRowContext(ctx, query, hash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, auth.ErrAccessTokenNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return t, nil
+}
+
+func (r *accessTokenRepository) ListAccessTokens(ctx context.Context,
+	userID uuid.UUID) ([]*model.AccessToken, error) {
+	const op = "list access tokens"
+
+	const query = `SELECT ` + accessTokenColumns + ` FROM access_tokens WHERE user_id=? ORDER BY created_at`
+	rows, err := r.executor(ctx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	defer func() { _ = rows.Close() }()
+
+	tokens := make([]*model.AccessToken, 0)
+	for rows.Next() {
+		t, err := scanAccessToken(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+		tokens = append(tokens, t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return tokens, nil
+}
+
+func (r *accessTokenRepository) DeleteAccessToken(ctx context.Context, userID, tokenID uuid.UUID) error {
+	const op = "delete access token"
+
+	const query = `DELETE FROM access_tokens WHERE token_id=? AND user_id=?`
+	res, err := r.executor(ctx).ExecContext(ctx, query, tokenID, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if n == 0 {
+		return auth.ErrAccessTokenNotFound
+	}
+
+	return nil
+}
+
+func (r *accessTokenRepository) DeleteUserAccessTokens(ctx context.Context, userID uuid.UUID) error {
+	const op = "delete user access tokens"
+
+	_, err := r.executor(ctx).ExecContext(ctx, `DELETE FROM access_tokens WHERE user_id=?`, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// executor returns transaction of the context if any.
+func (r *accessTokenRepository) executor(ctx context.Context) sqlitestorage.Executor {
+	return sqlitestorage.ExecutorFromContext(ctx, r.db)
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAccessToken(row rowScanner) (*model.AccessToken, error) {
+	const op = "scan access token"
+
+	var (
+		t         model.AccessToken
+		vaultID   uuid.NullUUID
+		createdAt int64
+		expiresAt sql.NullInt64
+	)
+	err := row.Scan(&t.ID, &t.UserID, &t.Name, &t.Hash, &t.Scope, &vaultID, &createdAt, &expiresAt)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	t.VaultID = vaultID.UUID
+	t.CreatedAt = time.UnixMicro(createdAt).UTC()
+	if expiresAt.Valid {
+		t.ExpiresAt = time.UnixMicro(expiresAt.Int64).UTC()
+	}
+	return &t, nil
+}
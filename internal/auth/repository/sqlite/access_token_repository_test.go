@@ -0,0 +1,36 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	sqlitestorage "github.com/nestjam/goph-keeper/internal/storage/sqlite"
+	"github.com/nestjam/goph-keeper/migration"
+)
+
+func TestAccessTokenRepository(t *testing.T) {
+	auth.AccessTokenRepositoryContract{
+		NewAccessTokenRepository: func() (auth.AccessTokenRepository, func(), auth.AccessTokenTestData) {
+			t.Helper()
+
+			path := filepath.Join(t.TempDir(), "goph-keeper.db")
+			migrator := migration.NewSQLiteMigrator(path)
+			err := migrator.Up()
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			db, err := sqlitestorage.Open(ctx, path)
+			require.NoError(t, err)
+			r := NewAccessTokenRepository(db)
+
+			testData := auth.AccessTokenTestData{
+				Users: setupUsers(t, path),
+			}
+			return r, func() { _ = db.Close() }, testData
+		},
+	}.Test(t)
+}
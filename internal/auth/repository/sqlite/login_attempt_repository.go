@@ -0,0 +1,131 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+	sqlitestorage "github.com/nestjam/goph-keeper/internal/storage/sqlite"
+)
+
+type loginAttemptRepository struct {
+	db *sql.DB
+}
+
+func NewLoginAttemptRepository(db *sql.DB) *loginAttemptRepository {
+	return &loginAttemptRepository{db}
+}
+
+func (r *loginAttemptRepository) GetLoginAttempts(ctx context.Context, key string) (*model.LoginAttempts, error) {
+	const op = "get login attempts"
+
+	const query = `SELECT attempt_key, failures, last_failure, locked_until FROM login_attempts WHERE attempt_key=?`
+	a, err := scanLoginAttempts(r.executor(ctx).QueryRowContext(ctx, query, key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, auth.ErrLoginAttemptsNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return a, nil
+}
+
+func (r *loginAttemptRepository) AddLoginFailure(ctx context.Context, key string,
+	at time.Time, policy model.LoginPolicy) (*model.LoginAttempts, error) {
+	const op = "add login failure"
+
+	// the lock is checked and the failure is counted in a single statement,
+	// so concurrent failures are not lost and do not pass the lock
+	const query = `INSERT INTO login_attempts (attempt_key, failures, last_failure, locked_until)
+VALUES (?1, 1, ?2, CASE WHEN 1 > ?4 THEN ?5 END)
+ON CONFLICT (attempt_key) DO UPDATE SET
+failures=CASE WHEN login_attempts.last_failure < ?3 THEN 1 ELSE login_attempts.failures + 1 END,
+last_failure=excluded.last_failure,
+locked_until=CASE WHEN (CASE WHEN login_attempts.last_failure < ?3 THEN 1 ELSE login_attempts.failures + 1 END) > ?4
+THEN ?5 ELSE login_attempts.locked_until END
+WHERE login_attempts.locked_until IS NULL OR login_attempts.locked_until <= ?2
+RETURNING attempt_key, failures, last_failure, locked_until`
+	row := r.executor(ctx).QueryRowContext(ctx, query, key, at.UnixMicro(), at.Add(-policy.Window).UnixMicro(),
+		policy.FreeFailures, at.Add(policy.BaseDelay).UnixMicro())
+	a, err := scanLoginAttempts(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, auth.ErrLoginLocked
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return a, nil
+}
+
+func (r *loginAttemptRepository) LockLogin(ctx context.Context, key string, until time.Time) error {
+	const op = "lock login"
+
+	const query = `UPDATE login_attempts SET locked_until=? WHERE attempt_key=?`
+	res, err := r.executor(ctx).ExecContext(ctx, query, until.UnixMicro(), key)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if n == 0 {
+		return auth.ErrLoginAttemptsNotFound
+	}
+
+	return nil
+}
+
+func (r *loginAttemptRepository) RemoveLoginFailure(ctx context.Context, key string) error {
+	const op = "remove login failure"
+
+	const query = `UPDATE login_attempts SET failures=failures - 1 WHERE attempt_key=? AND failures > 0`
+	_, err := r.executor(ctx).ExecContext(ctx, query, key)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (r *loginAttemptRepository) DeleteLoginAttempts(ctx context.Context, key string) error {
+	const op = "delete login attempts"
+
+	_, err := r.executor(ctx).ExecContext(ctx, `DELETE FROM login_attempts WHERE attempt_key=?`, key)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// executor returns transaction of the context if any.
+func (r *loginAttemptRepository) executor(ctx context.Context) sqlitestorage.Executor {
+	return sqlitestorage.ExecutorFromContext(ctx, r.db)
+}
+
+func scanLoginAttempts(row *sql.Row) (*model.LoginAttempts, error) {
+	const op = "scan login attempts"
+
+	var (
+		a           model.LoginAttempts
+		lastFailure int64
+		lockedUntil sql.NullInt64
+	)
+	err := row.Scan(&a.Key, &a.Failures, &lastFailure, &lockedUntil)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	a.LastFailure = time.UnixMicro(lastFailure).UTC()
+	if lockedUntil.Valid {
+		a.LockedUntil = time.UnixMicro(lockedUntil.Int64).UTC()
+	}
+	return &a, nil
+}
@@ -0,0 +1,31 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	sqlitestorage "github.com/nestjam/goph-keeper/internal/storage/sqlite"
+	"github.com/nestjam/goph-keeper/migration"
+)
+
+func TestLoginAttemptRepository(t *testing.T) {
+	auth.LoginAttemptRepositoryContract{
+		NewLoginAttemptRepository: func() (auth.LoginAttemptRepository, func()) {
+			t.Helper()
+
+			path := filepath.Join(t.TempDir(), "goph-keeper.db")
+			migrator := migration.NewSQLiteMigrator(path)
+			err := migrator.Up()
+			require.NoError(t, err)
+
+			db, err := sqlitestorage.Open(context.Background(), path)
+			require.NoError(t, err)
+			r := NewLoginAttemptRepository(db)
+			return r, func() { _ = db.Close() }
+		},
+	}.Test(t)
+}
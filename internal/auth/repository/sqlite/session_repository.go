@@ -0,0 +1,181 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+	sqlitestorage "github.com/nestjam/goph-keeper/internal/storage/sqlite"
+)
+
+type sessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) *sessionRepository {
+	return &sessionRepository{db}
+}
+
+func (r *sessionRepository) CreateSession(ctx context.Context, session *model.Session,
+	token *model.RefreshToken) error {
+	const op = "create session"
+
+	tx, err := sqlitestorage.BeginTx(ctx, r.db)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO sessions (session_id, user_id, created_at) VALUES (?, ?, ?)`,
+		session.ID, session.UserID, session.CreatedAt.UnixMicro())
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = insertRefreshToken(ctx, tx, token)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) GetSession(ctx context.Context, sessionID uuid.UUID) (*model.Session, error) {
+	const op = "get session"
+
+	var (
+		s         model.Session
+		createdAt int64
+	)
+	const query = `SELECT session_id, user_id, created_at, revoked FROM sessions WHERE session_id=?`
+	err := r.executor(ctx).QueryRowContext(ctx, query, sessionID).Scan(&s.ID, &s.UserID, &createdAt, &s.Revoked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, auth.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	s.CreatedAt = time.UnixMicro(createdAt).UTC()
+	return &s, nil
+}
+
+func (r *sessionRepository) FindRefreshToken(ctx context.Context, hash []byte) (*model.RefreshToken, error) {
+	const op = "find refresh token"
+
+	var (
+		t         model.RefreshToken
+		expiresAt int64
+	)
+	const query = `SELECT token_hash, session_id, expires_at, used FROM refresh_tokens WHERE token_hash=?`
+	err := r.executor(ctx).QueryRowContext(ctx, query, hash).Scan(&t.Hash, &t.SessionID, &expiresAt, &t.Used)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, auth.ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	t.ExpiresAt = time.UnixMicro(expiresAt).UTC()
+	return &t, nil
+}
+
+func (r *sessionRepository) UseRefreshToken(ctx context.Context, hash []byte, next *model.RefreshToken) error {
+	const op = "use refresh token"
+
+	tx, err := sqlitestorage.BeginTx(ctx, r.db)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// the token is marked used only once, so concurrent refreshes can not both succeed
+	res, err := tx.ExecContext(ctx, `UPDATE refresh_tokens SET used=1 WHERE token_hash=? AND used=0`, hash)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if n == 0 {
+		_, err = r.FindRefreshToken(ctx, hash)
+		if err != nil {
+			return err
+		}
+		return auth.ErrRefreshTokenUsed
+	}
+
+	err = insertRefreshToken(ctx, tx, next)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	const op = "revoke session"
+
+	res, err := r.executor(ctx).ExecContext(ctx, `UPDATE sessions SET revoked=1 WHERE session_id=?`, sessionID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if n == 0 {
+		return auth.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) RevokeUserSessions(ctx context.Context, userID uuid.UUID) error {
+	const op = "revoke user sessions"
+
+	_, err := r.executor(ctx).ExecContext(ctx, `UPDATE sessions SET revoked=1 WHERE user_id=?`, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) DeleteUserSessions(ctx context.Context, userID uuid.UUID) error {
+	const op = "delete user sessions"
+
+	_, err := r.executor(ctx).ExecContext(ctx, `DELETE FROM sessions WHERE user_id=?`, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// executor returns transaction of the context if any.
+func (r *sessionRepository) executor(ctx context.Context) sqlitestorage.Executor {
+	return sqlitestorage.ExecutorFromContext(ctx, r.db)
+}
+
+func insertRefreshToken(ctx context.Context, e sqlitestorage.Executor, token *model.RefreshToken) error {
+	const query = `INSERT INTO refresh_tokens (token_hash, session_id, expires_at, used) VALUES (?, ?, ?, ?)`
+	_, err := e.ExecContext(ctx, query, token.Hash, token.SessionID, token.ExpiresAt.UnixMicro(), token.Used)
+	return err
+}
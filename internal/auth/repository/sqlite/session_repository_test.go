@@ -0,0 +1,55 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+	sqlitestorage "github.com/nestjam/goph-keeper/internal/storage/sqlite"
+	"github.com/nestjam/goph-keeper/migration"
+)
+
+func TestSessionRepository(t *testing.T) {
+	auth.SessionRepositoryContract{
+		NewSessionRepository: func() (auth.SessionRepository, func(), auth.SessionTestData) {
+			t.Helper()
+
+			path := filepath.Join(t.TempDir(), "goph-keeper.db")
+			migrator := migration.NewSQLiteMigrator(path)
+			err := migrator.Up()
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			db, err := sqlitestorage.Open(ctx, path)
+			require.NoError(t, err)
+			r := NewSessionRepository(db)
+
+			testData := auth.SessionTestData{
+				Users: setupUsers(t, path),
+			}
+			return r, func() { _ = db.Close() }, testData
+		},
+	}.Test(t)
+}
+
+func setupUsers(t *testing.T, path string) uuid.UUIDs {
+	t.Helper()
+
+	ctx := context.Background()
+	db, err := sqlitestorage.Open(ctx, path)
+	require.NoError(t, err)
+	r := NewUserRepository(db)
+	defer func() { _ = db.Close() }()
+
+	userID, err := r.Register(ctx, &model.User{Email: "user@email.com", Password: "1"})
+	require.NoError(t, err)
+	user2ID, err := r.Register(ctx, &model.User{Email: "user2@email.com", Password: "2"})
+	require.NoError(t, err)
+
+	return uuid.UUIDs{userID, user2ID}
+}
@@ -0,0 +1,153 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+	sqlitestorage "github.com/nestjam/goph-keeper/internal/storage/sqlite"
+)
+
+type twoFactorRepository struct {
+	db *sql.DB
+}
+
+func NewTwoFactorRepository(db *sql.DB) *twoFactorRepository {
+	return &twoFactorRepository{db}
+}
+
+func (r *twoFactorRepository) SaveTwoFactor(ctx context.Context, f *model.TwoFactor, recoveryCodes [][]byte) error {
+	const op = "save two factor"
+
+	tx, err := sqlitestorage.BeginTx(ctx, r.db)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// the recovery codes of the replaced second factor are deleted along with it
+	_, err = tx.ExecContext(ctx, `DELETE FROM two_factor WHERE user_id=?`, f.UserID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	const query = `INSERT INTO two_factor (user_id, secret, enabled, last_time_step) VALUES (?, ?, ?, ?)`
+	_, err = tx.ExecContext(ctx, query, f.UserID, f.Secret, f.Enabled, f.LastTimeStep)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	for _, hash := range recoveryCodes {
+		_, err = tx.ExecContext(ctx, `INSERT INTO recovery_codes (user_id, code_hash) VALUES (?, ?)`, f.UserID, hash)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (r *twoFactorRepository) GetTwoFactor(ctx context.Context, userID uuid.UUID) (*model.TwoFactor, error) {
+	const op = "get two factor"
+
+	var f model.TwoFactor
+	const query = `SELECT user_id, secret, enabled, last_time_step FROM two_factor WHERE user_id=?`
+	err := r.executor(ctx).QueryRowContext(ctx, query, userID).Scan(&f.UserID, &f.Secret, &f.Enabled,
+		&f.LastTimeStep)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, auth.ErrTwoFactorNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return &f, nil
+}
+
+func (r *twoFactorRepository) EnableTwoFactor(ctx context.Context, userID uuid.UUID) error {
+	const op = "enable two factor"
+
+	res, err := r.executor(ctx).ExecContext(ctx, `UPDATE two_factor SET enabled=1 WHERE user_id=?`, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if n == 0 {
+		return auth.ErrTwoFactorNotFound
+	}
+
+	return nil
+}
+
+func (r *twoFactorRepository) UpdateTwoFactorSecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	const op = "update two factor secret"
+
+	res, err := r.executor(ctx).ExecContext(ctx, `UPDATE two_factor SET secret=? WHERE user_id=?`, secret, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if n == 0 {
+		return auth.ErrTwoFactorNotFound
+	}
+
+	return nil
+}
+
+func (r *twoFactorRepository) UseTimeStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	const op = "use time step"
+
+	const query = `UPDATE two_factor SET last_time_step=? WHERE user_id=? AND last_time_step<?`
+	res, err := r.executor(ctx).ExecContext(ctx, query, step, userID, step)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if n == 0 {
+		return auth.ErrTimeStepUsed
+	}
+
+	return nil
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash []byte) error {
+	const op = "use recovery code"
+
+	const query = `DELETE FROM recovery_codes WHERE user_id=? AND code_hash=?`
+	res, err := r.executor(ctx).ExecContext(ctx, query, userID, hash)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if n == 0 {
+		return auth.ErrRecoveryCodeNotFound
+	}
+
+	return nil
+}
+
+// executor returns transaction of the context if any.
+func (r *twoFactorRepository) executor(ctx context.Context) sqlitestorage.Executor {
+	return sqlitestorage.ExecutorFromContext(ctx, r.db)
+}
@@ -0,0 +1,155 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+	sqlitestorage "github.com/nestjam/goph-keeper/internal/storage/sqlite"
+)
+
+type userRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *userRepository {
+	return &userRepository{db}
+}
+
+func (r *userRepository) Register(ctx context.Context, user *model.User) (uuid.UUID, error) {
+	const op = "register"
+
+	tx, err := sqlitestorage.BeginTx(ctx, r.db)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	userID := uuid.New()
+	const query = `INSERT INTO users (user_id, email, password) VALUES (?, ?, ?);`
+	_, err = tx.ExecContext(ctx, query, userID, user.Email, user.Password)
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		if sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE &&
+			strings.Contains(sqliteErr.Error(), "users.email") {
+			return uuid.Nil, auth.ErrUserWithEmailIsRegistered
+		}
+		if sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_CHECK &&
+			strings.Contains(sqliteErr.Error(), "users_password_check") {
+			return uuid.Nil, auth.ErrUserPasswordIsEmpty
+		}
+	}
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	user.ID = userID
+	return user.ID, nil
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	const op = "find by email"
+
+	var user model.User
+	const query = "SELECT user_id, email, password FROM users WHERE email=?"
+	row := r.executor(ctx).QueryRowContext(ctx, query, email)
+	err := row.Scan(&user.ID, &user.Email, &user.Password)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, auth.ErrUserIsNotRegistered
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return &user, nil
+}
+
+func (r *userRepository) FindByID(ctx context.Context, userID uuid.UUID) (*model.User, error) {
+	const op = "find by id"
+
+	var user model.User
+	const query = "SELECT user_id, email, password FROM users WHERE user_id=?"
+	row := r.executor(ctx).QueryRowContext(ctx, query, userID)
+	err := row.Scan(&user.ID, &user.Email, &user.Password)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, auth.ErrUserIsNotRegistered
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return &user, nil
+}
+
+func (r *userRepository) ChangePassword(ctx context.Context, userID uuid.UUID, password string) error {
+	const op = "change password"
+
+	const query = `UPDATE users SET password=? WHERE user_id=?;`
+	res, err := r.executor(ctx).ExecContext(ctx, query, password, userID)
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_CHECK &&
+		strings.Contains(sqliteErr.Error(), "users_password_check") {
+		return auth.ErrUserPasswordIsEmpty
+	}
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if n == 0 {
+		return auth.ErrUserIsNotRegistered
+	}
+
+	return nil
+}
+
+func (r *userRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	const op = "delete"
+
+	tx, err := sqlitestorage.BeginTx(ctx, r.db)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	const query = `DELETE FROM users WHERE user_id=?;`
+	res, err := tx.ExecContext(ctx, query, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if n == 0 {
+		return auth.ErrUserIsNotRegistered
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// executor returns transaction of the context if any.
+func (r *userRepository) executor(ctx context.Context) sqlitestorage.Executor {
+	return sqlitestorage.ExecutorFromContext(ctx, r.db)
+}
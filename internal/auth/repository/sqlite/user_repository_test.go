@@ -0,0 +1,35 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	sqlitestorage "github.com/nestjam/goph-keeper/internal/storage/sqlite"
+	"github.com/nestjam/goph-keeper/migration"
+)
+
+func TestUserRepository(t *testing.T) {
+	auth.UserRepositoryContract{
+		NewUserRepository: func() (auth.UserRepository, func()) {
+			t.Helper()
+
+			path := filepath.Join(t.TempDir(), "goph-keeper.db")
+			migrator := migration.NewSQLiteMigrator(path)
+			err := migrator.Up()
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			db, err := sqlitestorage.Open(ctx, path)
+			require.NoError(t, err)
+			r := NewUserRepository(db)
+
+			return r, func() {
+				_ = db.Close()
+			}
+		},
+	}.Test(t)
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+type accessTokenService struct {
+	repo  auth.AccessTokenRepository
+	users auth.UserRepository
+	now   func() time.Time
+}
+
+// NewAccessTokenService returns the service of personal access tokens. Users are checked on every verification,
+// so tokens stop working along with the deleted account whatever storage keeps them.
+func NewAccessTokenService(repo auth.AccessTokenRepository, users auth.UserRepository) auth.AccessTokenService {
+	return &accessTokenService{
+		repo:  repo,
+		users: users,
+		now:   time.Now,
+	}
+}
+
+func (s *accessTokenService) CreateAccessToken(ctx context.Context, token *model.AccessToken,
+	password string) (string, error) {
+	const op = "create access token"
+
+	if token.Name == "" {
+		return "", auth.ErrAccessTokenNameIsEmpty
+	}
+	if !token.Scope.IsValid() {
+		return "", auth.ErrInvalidAccessScope
+	}
+	if token.IsExpired(s.now()) {
+		return "", auth.ErrAccessTokenExpiry
+	}
+
+	// the stolen session does not let create the token that outlives it
+	user, err := s.users.FindByID(ctx, token.UserID)
+	if err != nil {
+		return "", errors.Wrap(err, op)
+	}
+	if !user.ComparePassword(password) {
+		return "", auth.ErrInvalidPassword
+	}
+
+	plain, err := model.NewAccessToken(token)
+	if err != nil {
+		return "", errors.Wrap(err, op)
+	}
+
+	err = s.repo.CreateAccessToken(ctx, token)
+	if err != nil {
+		return "", errors.Wrap(err, op)
+	}
+
+	return plain, nil
+}
+
+func (s *accessTokenService) ListAccessTokens(ctx context.Context, userID uuid.UUID) ([]*model.AccessToken, error) {
+	const op = "list access tokens"
+
+	tokens, err := s.repo.ListAccessTokens(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return tokens, nil
+}
+
+func (s *accessTokenService) RevokeAccessToken(ctx context.Context, userID, tokenID uuid.UUID) error {
+	const op = "revoke access token"
+
+	err := s.repo.DeleteAccessToken(ctx, userID, tokenID)
+	if errors.Is(err, auth.ErrAccessTokenNotFound) {
+		return auth.ErrAccessTokenNotFound
+	}
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (s *accessTokenService) VerifyAccessToken(ctx context.Context, token string) (*utils.AccessGrant, error) {
+	const op = "verify access token"
+
+	stored, err := s.repo.FindAccessToken(ctx, model.HashAccessToken(token))
+	if errors.Is(err, auth.ErrAccessTokenNotFound) {
+		return nil, utils.ErrInvalidAccessToken
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	if stored.IsExpired(s.now()) {
+		return nil, utils.ErrInvalidAccessToken
+	}
+
+	_, err = s.users.FindByID(ctx, stored.UserID)
+	if errors.Is(err, auth.ErrUserIsNotRegistered) {
+		return nil, utils.ErrInvalidAccessToken
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	grant := &utils.AccessGrant{
+		UserID:   stored.UserID,
+		VaultID:  stored.VaultID,
+		ReadOnly: stored.Scope == model.ScopeRead,
+	}
+	return grant, nil
+}
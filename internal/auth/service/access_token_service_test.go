@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+	"github.com/nestjam/goph-keeper/internal/auth/repository/inmemory"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+const tokenPassword = "1234"
+
+func TestCreateAccessToken(t *testing.T) {
+	t.Run("create access token", func(t *testing.T) {
+		sut, userID := newAccessTokenService(t)
+		ctx := context.Background()
+		token := &model.AccessToken{UserID: userID, Name: "ci", Scope: model.ScopeRead}
+
+		got, err := sut.CreateAccessToken(ctx, token, tokenPassword)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, got)
+		tokens, err := sut.ListAccessTokens(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, []*model.AccessToken{token}, tokens)
+	})
+	t.Run("name is empty", func(t *testing.T) {
+		sut, userID := newAccessTokenService(t)
+		token := &model.AccessToken{UserID: userID, Scope: model.ScopeRead}
+
+		_, err := sut.CreateAccessToken(context.Background(), token, tokenPassword)
+
+		require.ErrorIs(t, err, auth.ErrAccessTokenNameIsEmpty)
+	})
+	t.Run("scope is invalid", func(t *testing.T) {
+		sut, userID := newAccessTokenService(t)
+		token := &model.AccessToken{UserID: userID, Name: "ci", Scope: "admin"}
+
+		_, err := sut.CreateAccessToken(context.Background(), token, tokenPassword)
+
+		require.ErrorIs(t, err, auth.ErrInvalidAccessScope)
+	})
+	t.Run("token is expired", func(t *testing.T) {
+		sut, userID := newAccessTokenService(t)
+		token := &model.AccessToken{
+			UserID:    userID,
+			Name:      "ci",
+			Scope:     model.ScopeRead,
+			ExpiresAt: time.Now().Add(-time.Minute),
+		}
+
+		_, err := sut.CreateAccessToken(context.Background(), token, tokenPassword)
+
+		require.ErrorIs(t, err, auth.ErrAccessTokenExpiry)
+	})
+	t.Run("password is wrong", func(t *testing.T) {
+		sut, userID := newAccessTokenService(t)
+		ctx := context.Background()
+		token := &model.AccessToken{UserID: userID, Name: "ci", Scope: model.ScopeRead}
+
+		_, err := sut.CreateAccessToken(ctx, token, "0000")
+
+		require.ErrorIs(t, err, auth.ErrInvalidPassword)
+		tokens, err := sut.ListAccessTokens(ctx, userID)
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+}
+
+func TestRevokeAccessToken(t *testing.T) {
+	t.Run("revoke access token", func(t *testing.T) {
+		sut, userID := newAccessTokenService(t)
+		ctx := context.Background()
+		token := &model.AccessToken{UserID: userID, Name: "ci", Scope: model.ScopeRead}
+		plain, err := sut.CreateAccessToken(ctx, token, tokenPassword)
+		require.NoError(t, err)
+
+		err = sut.RevokeAccessToken(ctx, userID, token.ID)
+
+		require.NoError(t, err)
+		_, err = sut.VerifyAccessToken(ctx, plain)
+		require.ErrorIs(t, err, utils.ErrInvalidAccessToken)
+	})
+	t.Run("token of another user", func(t *testing.T) {
+		sut, userID := newAccessTokenService(t)
+		ctx := context.Background()
+		token := &model.AccessToken{UserID: userID, Name: "ci", Scope: model.ScopeRead}
+		_, err := sut.CreateAccessToken(ctx, token, tokenPassword)
+		require.NoError(t, err)
+
+		err = sut.RevokeAccessToken(ctx, uuid.New(), token.ID)
+
+		require.ErrorIs(t, err, auth.ErrAccessTokenNotFound)
+	})
+}
+
+func TestVerifyAccessToken(t *testing.T) {
+	t.Run("read only token", func(t *testing.T) {
+		sut, userID := newAccessTokenService(t)
+		ctx := context.Background()
+		vaultID := uuid.New()
+		token := &model.AccessToken{UserID: userID, Name: "ci", Scope: model.ScopeRead, VaultID: vaultID}
+		plain, err := sut.CreateAccessToken(ctx, token, tokenPassword)
+		require.NoError(t, err)
+
+		got, err := sut.VerifyAccessToken(ctx, plain)
+
+		require.NoError(t, err)
+		want := &utils.AccessGrant{UserID: userID, VaultID: vaultID, ReadOnly: true}
+		assert.Equal(t, want, got)
+	})
+	t.Run("read write token", func(t *testing.T) {
+		sut, userID := newAccessTokenService(t)
+		ctx := context.Background()
+		token := &model.AccessToken{UserID: userID, Name: "ci", Scope: model.ScopeReadWrite}
+		plain, err := sut.CreateAccessToken(ctx, token, tokenPassword)
+		require.NoError(t, err)
+
+		got, err := sut.VerifyAccessToken(ctx, plain)
+
+		require.NoError(t, err)
+		assert.False(t, got.ReadOnly)
+	})
+	t.Run("unknown token", func(t *testing.T) {
+		sut, _ := newAccessTokenService(t)
+
+		_, err := sut.VerifyAccessToken(context.Background(), utils.AccessTokenPrefix+"token")
+
+		require.ErrorIs(t, err, utils.ErrInvalidAccessToken)
+	})
+	t.Run("expired token", func(t *testing.T) {
+		sut, userID := newAccessTokenService(t)
+		ctx := context.Background()
+		token := &model.AccessToken{
+			UserID:    userID,
+			Name:      "ci",
+			Scope:     model.ScopeRead,
+			ExpiresAt: time.Now().Add(time.Minute),
+		}
+		plain, err := sut.CreateAccessToken(ctx, token, tokenPassword)
+		require.NoError(t, err)
+		sut.now = func() time.Time { return token.ExpiresAt }
+
+		_, err = sut.VerifyAccessToken(ctx, plain)
+
+		require.ErrorIs(t, err, utils.ErrInvalidAccessToken)
+	})
+	t.Run("user is deleted", func(t *testing.T) {
+		sut, userID := newAccessTokenService(t)
+		ctx := context.Background()
+		token := &model.AccessToken{UserID: userID, Name: "ci", Scope: model.ScopeRead}
+		plain, err := sut.CreateAccessToken(ctx, token, tokenPassword)
+		require.NoError(t, err)
+		require.NoError(t, sut.users.Delete(ctx, userID))
+
+		_, err = sut.VerifyAccessToken(ctx, plain)
+
+		require.ErrorIs(t, err, utils.ErrInvalidAccessToken)
+	})
+}
+
+func newAccessTokenService(t *testing.T) (*accessTokenService, uuid.UUID) {
+	t.Helper()
+
+	users := inmemory.NewUserRepository()
+	user := &model.User{Email: "user@email.com", Password: tokenPassword}
+	require.NoError(t, user.HashPassword())
+	userID, err := users.Register(context.Background(), user)
+	require.NoError(t, err)
+	sut, _ := NewAccessTokenService(inmemory.NewAccessTokenRepository(), users).(*accessTokenService)
+	return sut, userID
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"crypto/x509"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+type certificateAuthenticator struct {
+	users  auth.UserRepository
+	emails map[string]string
+}
+
+// NewCertificateAuthenticator returns the authenticator of client certificates that maps the common names
+// of certificates to the emails of users. Subjects without email identify devices only.
+func NewCertificateAuthenticator(users auth.UserRepository, emails map[string]string) utils.CertificateAuthenticator {
+	return &certificateAuthenticator{
+		users:  users,
+		emails: emails,
+	}
+}
+
+func (a *certificateAuthenticator) CertificateUser(ctx context.Context, cert *x509.Certificate) (uuid.UUID, error) {
+	const op = "certificate user"
+
+	email := a.emails[cert.Subject.CommonName]
+	if email == "" {
+		return uuid.Nil, utils.ErrCertificateNotMapped
+	}
+
+	user, err := a.users.FindByEmail(ctx, email)
+	if errors.Is(err, auth.ErrUserIsNotRegistered) {
+		return uuid.Nil, utils.ErrCertificateNotMapped
+	}
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	return user.ID, nil
+}
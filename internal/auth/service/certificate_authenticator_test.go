@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+	"github.com/nestjam/goph-keeper/internal/auth/repository/inmemory"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+func TestCertificateUser(t *testing.T) {
+	users := inmemory.NewUserRepository()
+	userID, err := users.Register(context.Background(), &model.User{Email: "user@email.com", Password: "1"})
+	require.NoError(t, err)
+	emails := map[string]string{
+		"ci":     "user@email.com",
+		"laptop": "",
+		"old":    "deleted@email.com",
+	}
+	sut := NewCertificateAuthenticator(users, emails)
+
+	t.Run("certificate of user", func(t *testing.T) {
+		cert := newClientCertificate("ci")
+
+		got, err := sut.CertificateUser(context.Background(), cert)
+
+		require.NoError(t, err)
+		assert.Equal(t, userID, got)
+	})
+	t.Run("certificate of device", func(t *testing.T) {
+		cert := newClientCertificate("laptop")
+
+		_, err := sut.CertificateUser(context.Background(), cert)
+
+		require.ErrorIs(t, err, utils.ErrCertificateNotMapped)
+	})
+	t.Run("unknown subject", func(t *testing.T) {
+		cert := newClientCertificate("phone")
+
+		_, err := sut.CertificateUser(context.Background(), cert)
+
+		require.ErrorIs(t, err, utils.ErrCertificateNotMapped)
+	})
+	t.Run("user is not registered", func(t *testing.T) {
+		cert := newClientCertificate("old")
+
+		_, err := sut.CertificateUser(context.Background(), cert)
+
+		require.ErrorIs(t, err, utils.ErrCertificateNotMapped)
+	})
+}
+
+func newClientCertificate(commonName string) *x509.Certificate {
+	return &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+)
+
+type loginLimiter struct {
+	repo   auth.LoginAttemptRepository
+	now    func() time.Time
+	policy model.LoginPolicy
+}
+
+// NewLoginLimiter returns the limiter that locks the login with exponentially growing delay after the failures
+// allowed by the policy. The failures are kept in the repository, so the limit is shared by the server instances.
+func NewLoginLimiter(repo auth.LoginAttemptRepository, policy model.LoginPolicy) auth.LoginLimiter {
+	return &loginLimiter{
+		repo:   repo,
+		policy: policy,
+		now:    time.Now,
+	}
+}
+
+func (l *loginLimiter) RetryAfter(ctx context.Context, key string) (time.Duration, error) {
+	const op = "retry after"
+
+	a, err := l.repo.GetLoginAttempts(ctx, key)
+	if errors.Is(err, auth.ErrLoginAttemptsNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, op)
+	}
+
+	return a.RetryAfter(l.now()), nil
+}
+
+func (l *loginLimiter) Reserve(ctx context.Context, key string) (time.Duration, error) {
+	const op = "reserve login"
+
+	// the time is kept with precision supported by the storages
+	now := l.now().UTC().Truncate(time.Microsecond)
+	a, err := l.repo.AddLoginFailure(ctx, key, now, l.policy)
+	if errors.Is(err, auth.ErrLoginLocked) {
+		return l.lockedFor(ctx, key, now)
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, op)
+	}
+
+	// the repository has locked the login for the base delay, the lock grows with the failures
+	delay := l.policy.Delay(a.Failures)
+	if delay <= l.policy.BaseDelay {
+		return 0, nil
+	}
+
+	err = l.repo.LockLogin(ctx, key, now.Add(delay))
+	if err != nil {
+		return 0, errors.Wrap(err, op)
+	}
+
+	return 0, nil
+}
+
+func (l *loginLimiter) Release(ctx context.Context, key string) error {
+	const op = "release login"
+
+	err := l.repo.RemoveLoginFailure(ctx, key)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// lockedFor returns how long the locked login of the key is still locked at the time.
+func (l *loginLimiter) lockedFor(ctx context.Context, key string, now time.Time) (time.Duration, error) {
+	const op = "locked for"
+
+	a, err := l.repo.GetLoginAttempts(ctx, key)
+	if errors.Is(err, auth.ErrLoginAttemptsNotFound) {
+		// the failures are reset by the login that has just succeeded, the login is retried after the base delay
+		return l.policy.BaseDelay, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, op)
+	}
+
+	return max(a.RetryAfter(now), time.Microsecond), nil
+}
+
+func (l *loginLimiter) Reset(ctx context.Context, key string) error {
+	const op = "reset login attempts"
+
+	err := l.repo.DeleteLoginAttempts(ctx, key)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
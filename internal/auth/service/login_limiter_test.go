@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+	"github.com/nestjam/goph-keeper/internal/auth/repository/inmemory"
+)
+
+func TestLoginLimiter(t *testing.T) {
+	const key = "account:user@email.com"
+	policy := model.LoginPolicy{
+		FreeFailures: 2,
+		BaseDelay:    time.Second,
+		MaxDelay:     time.Minute,
+		Window:       time.Hour,
+	}
+
+	t.Run("login without failures is allowed", func(t *testing.T) {
+		sut := NewLoginLimiter(inmemory.NewLoginAttemptRepository(), policy)
+
+		got, err := sut.RetryAfter(context.Background(), key)
+
+		require.NoError(t, err)
+		assert.Zero(t, got)
+	})
+	t.Run("free failures do not lock login", func(t *testing.T) {
+		sut := NewLoginLimiter(inmemory.NewLoginAttemptRepository(), policy)
+		ctx := context.Background()
+		failLogin(t, sut, key, policy.FreeFailures)
+
+		got, err := sut.RetryAfter(ctx, key)
+
+		require.NoError(t, err)
+		assert.Zero(t, got)
+	})
+	t.Run("failures over free ones lock login with growing delay", func(t *testing.T) {
+		now := time.Now()
+		sut := newLoginLimiterAt(policy, now)
+		ctx := context.Background()
+		failLogin(t, sut, key, policy.FreeFailures+1)
+		sut.now = func() time.Time { return now.Add(time.Second) }
+		failLogin(t, sut, key, 1)
+
+		got, err := sut.RetryAfter(ctx, key)
+
+		require.NoError(t, err)
+		assert.InDelta(t, 2*time.Second, got, float64(time.Microsecond))
+	})
+	t.Run("lock expires", func(t *testing.T) {
+		now := time.Now()
+		sut := newLoginLimiterAt(policy, now)
+		ctx := context.Background()
+		failLogin(t, sut, key, policy.FreeFailures+1)
+		sut.now = func() time.Time { return now.Add(time.Second) }
+
+		got, err := sut.RetryAfter(ctx, key)
+
+		require.NoError(t, err)
+		assert.Zero(t, got)
+	})
+	t.Run("other key is not locked", func(t *testing.T) {
+		sut := NewLoginLimiter(inmemory.NewLoginAttemptRepository(), policy)
+		ctx := context.Background()
+		failLogin(t, sut, key, policy.FreeFailures+1)
+
+		got, err := sut.RetryAfter(ctx, "account:user2@email.com")
+
+		require.NoError(t, err)
+		assert.Zero(t, got)
+	})
+	t.Run("locked login is not reserved", func(t *testing.T) {
+		now := time.Now()
+		sut := newLoginLimiterAt(policy, now)
+		ctx := context.Background()
+		failLogin(t, sut, key, policy.FreeFailures+1)
+
+		got, err := sut.Reserve(ctx, key)
+
+		require.NoError(t, err)
+		assert.InDelta(t, time.Second, got, float64(time.Microsecond))
+		a, err := sut.repo.GetLoginAttempts(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, policy.FreeFailures+1, a.Failures)
+	})
+	t.Run("concurrent logins do not pass limit", func(t *testing.T) {
+		sut := newLoginLimiterAt(policy, time.Now())
+		ctx := context.Background()
+		const logins = 10
+		var (
+			wg      sync.WaitGroup
+			allowed atomic.Int32
+		)
+
+		for i := 0; i < logins; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				wait, err := sut.Reserve(ctx, key)
+				if err == nil && wait == 0 {
+					allowed.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(policy.FreeFailures+1), allowed.Load())
+	})
+	t.Run("release uncounts reserved login", func(t *testing.T) {
+		sut := NewLoginLimiter(inmemory.NewLoginAttemptRepository(), policy)
+		ctx := context.Background()
+		failLogin(t, sut, key, policy.FreeFailures)
+
+		err := sut.Release(ctx, key)
+
+		require.NoError(t, err)
+		failLogin(t, sut, key, 1)
+		got, err := sut.RetryAfter(ctx, key)
+		require.NoError(t, err)
+		assert.Zero(t, got)
+	})
+	t.Run("reset forgets failures", func(t *testing.T) {
+		sut := NewLoginLimiter(inmemory.NewLoginAttemptRepository(), policy)
+		ctx := context.Background()
+		failLogin(t, sut, key, policy.FreeFailures+1)
+
+		err := sut.Reset(ctx, key)
+
+		require.NoError(t, err)
+		got, err := sut.RetryAfter(ctx, key)
+		require.NoError(t, err)
+		assert.Zero(t, got)
+		failLogin(t, sut, key, policy.FreeFailures)
+		got, err = sut.RetryAfter(ctx, key)
+		require.NoError(t, err)
+		assert.Zero(t, got)
+	})
+}
+
+func newLoginLimiterAt(policy model.LoginPolicy, now time.Time) *loginLimiter {
+	l, _ := NewLoginLimiter(inmemory.NewLoginAttemptRepository(), policy).(*loginLimiter)
+	l.now = func() time.Time { return now }
+	return l
+}
+
+func failLogin(t *testing.T, limiter auth.LoginLimiter, key string, n int) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		wait, err := limiter.Reserve(context.Background(), key)
+		require.NoError(t, err)
+		require.Zero(t, wait)
+	}
+}
@@ -2,20 +2,76 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 
 	"github.com/nestjam/goph-keeper/internal/auth"
 	"github.com/nestjam/goph-keeper/internal/auth/model"
+	"github.com/nestjam/goph-keeper/internal/vault"
 )
 
+const defaultRefreshTokenExpiryIn = 30 * 24 * time.Hour
+
 type authService struct {
-	repo auth.UserRepository
+	repo                 auth.UserRepository
+	sessions             auth.SessionRepository
+	twoFactors           auth.TwoFactorRepository
+	shredder             auth.UserDataShredder
+	accessTokens         auth.AccessTokenRepository
+	transactor           vault.Transactor
+	masterKey            []byte
+	refreshTokenExpiryIn time.Duration
+}
+
+type AuthServiceOption func(*authService)
+
+// WithRefreshTokenExpiryIn sets the lifetime of refresh tokens, the session ends if it is not refreshed in time.
+func WithRefreshTokenExpiryIn(d time.Duration) AuthServiceOption {
+	return func(s *authService) {
+		s.refreshTokenExpiryIn = d
+	}
+}
+
+// WithMasterKey makes the service keep the secrets of the second factor sealed with the master key,
+// so the stored secrets and their backups do not generate codes without the key.
+func WithMasterKey(key []byte) AuthServiceOption {
+	return func(s *authService) {
+		s.masterKey = key
+	}
+}
+
+// WithAccessTokens makes the service revoke the personal access tokens of the user along with the sessions
+// once the password is changed or reset, so the tokens created by whoever has known the password stop working.
+func WithAccessTokens(repo auth.AccessTokenRepository) AuthServiceOption {
+	return func(s *authService) {
+		s.accessTokens = repo
+	}
+}
+
+// WithTransactor makes the service delete the account along with the data of the user in one transaction
+// of the storage, so that a failure never leaves the account without its data. The steps are run
+// one by one by default.
+func WithTransactor(transactor vault.Transactor) AuthServiceOption {
+	return func(s *authService) {
+		s.transactor = transactor
+	}
 }
 
-func NewAuthService(repo auth.UserRepository) auth.AuthService {
-	return &authService{repo: repo}
+func NewAuthService(repo auth.UserRepository, sessions auth.SessionRepository, twoFactors auth.TwoFactorRepository,
+	shredder auth.UserDataShredder, opts ...AuthServiceOption) auth.AuthService {
+	s := &authService{
+		repo:                 repo,
+		sessions:             sessions,
+		twoFactors:           twoFactors,
+		shredder:             shredder,
+		refreshTokenExpiryIn: defaultRefreshTokenExpiryIn,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *authService) Register(ctx context.Context, user *model.User) (uuid.UUID, error) {
@@ -38,13 +94,447 @@ func (s *authService) Login(ctx context.Context, user *model.User) (uuid.UUID, e
 	const op = "login"
 
 	foundUser, err := s.repo.FindByEmail(ctx, user.Email)
+	if errors.Is(err, auth.ErrUserIsNotRegistered) {
+		model.CompareDummyPassword(user.Password)
+		return uuid.Nil, errors.Wrap(err, op)
+	}
 	if err != nil {
 		return uuid.Nil, errors.Wrap(err, op)
 	}
 
 	if !foundUser.ComparePassword(user.Password) {
-		return uuid.Nil, auth.ErrInvalidPassword
+		return foundUser.ID, auth.ErrInvalidPassword
 	}
 
 	return foundUser.ID, nil
 }
+
+func (s *authService) ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error {
+	const op = "change password"
+
+	if newPassword == "" {
+		return auth.ErrUserPasswordIsEmpty
+	}
+
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if !user.ComparePassword(currentPassword) {
+		return auth.ErrInvalidPassword
+	}
+
+	err = s.setPassword(ctx, userID, newPassword)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (s *authService) ResetPassword(ctx context.Context, email, recoveryCode, newPassword string) (uuid.UUID, error) {
+	const op = "reset password"
+
+	if newPassword == "" {
+		return uuid.Nil, auth.ErrUserPasswordIsEmpty
+	}
+
+	// unknown email and wrong code are told the same, so the reset does not tell who is registered
+	user, err := s.repo.FindByEmail(ctx, email)
+	if errors.Is(err, auth.ErrUserIsNotRegistered) {
+		return uuid.Nil, auth.ErrInvalidCode
+	}
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	enabled, err := s.IsTwoFactorEnabled(ctx, user.ID)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+	if !enabled {
+		return uuid.Nil, auth.ErrInvalidCode
+	}
+
+	err = s.twoFactors.UseRecoveryCode(ctx, user.ID, model.HashRecoveryCode(recoveryCode))
+	if errors.Is(err, auth.ErrRecoveryCodeNotFound) {
+		return uuid.Nil, auth.ErrInvalidCode
+	}
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	err = s.setPassword(ctx, user.ID, newPassword)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	return user.ID, nil
+}
+
+// setPassword stores the hash of the password and revokes the sessions and the access tokens
+// obtained with the previous one.
+func (s *authService) setPassword(ctx context.Context, userID uuid.UUID, password string) error {
+	const op = "set password"
+
+	user := &model.User{Password: password}
+	err := user.HashPassword()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = s.repo.ChangePassword(ctx, userID, user.Password)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = s.sessions.RevokeUserSessions(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	if s.accessTokens == nil {
+		return nil
+	}
+	err = s.accessTokens.DeleteUserAccessTokens(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (s *authService) DeleteAccount(ctx context.Context, userID uuid.UUID, password, code string) error {
+	const op = "delete account"
+
+	err := s.confirmDeletion(ctx, userID, password, code)
+	if err != nil {
+		return err
+	}
+
+	var cleanup func(ctx context.Context) error
+	err = s.withinTransaction(ctx, func(ctx context.Context) error {
+		// user data goes first, as it refers to the user
+		var err error
+		cleanup, err = s.shredder.DeleteUserData(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		err = s.sessions.DeleteUserSessions(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		return s.repo.Delete(ctx, userID)
+	})
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	// blobs and keys are destroyed once the deletion is committed, as they can not be rolled back
+	err = cleanup(ctx)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (s *authService) withinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.transactor == nil {
+		return fn(ctx)
+	}
+	return s.transactor.WithinTransaction(ctx, fn)
+}
+
+// confirmDeletion checks the password of the user and the code of the second factor if it is enabled,
+// so that a stolen access token alone does not let delete the account.
+func (s *authService) confirmDeletion(ctx context.Context, userID uuid.UUID, password, code string) error {
+	const op = "confirm deletion"
+
+	user, err := s.repo.FindByID(ctx, userID)
+	if errors.Is(err, auth.ErrUserIsNotRegistered) {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if !user.ComparePassword(password) {
+		return auth.ErrInvalidPassword
+	}
+
+	enabled, err := s.IsTwoFactorEnabled(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if !enabled {
+		return nil
+	}
+
+	err = s.VerifySecondFactor(ctx, userID, code)
+	if errors.Is(err, auth.ErrInvalidCode) {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (s *authService) StartSession(ctx context.Context, userID uuid.UUID) (*model.SessionGrant, error) {
+	const op = "start session"
+
+	session := &model.Session{
+		ID:        uuid.New(),
+		UserID:    userID,
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	token, stored, err := model.NewRefreshToken(session.ID, s.refreshTokenExpiryIn)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	err = s.sessions.CreateSession(ctx, session, stored)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return &model.SessionGrant{SessionID: session.ID, UserID: userID, RefreshToken: token}, nil
+}
+
+func (s *authService) RefreshSession(ctx context.Context, refreshToken string) (*model.SessionGrant, error) {
+	const op = "refresh session"
+
+	hash := model.HashRefreshToken(refreshToken)
+	token, err := s.sessions.FindRefreshToken(ctx, hash)
+	if errors.Is(err, auth.ErrRefreshTokenNotFound) {
+		return nil, auth.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	session, err := s.sessions.GetSession(ctx, token.SessionID)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	if session.Revoked || token.IsExpired(time.Now()) {
+		return nil, auth.ErrInvalidRefreshToken
+	}
+
+	next, stored, err := model.NewRefreshToken(session.ID, s.refreshTokenExpiryIn)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	err = s.sessions.UseRefreshToken(ctx, hash, stored)
+	if errors.Is(err, auth.ErrRefreshTokenUsed) {
+		// the token is used twice only if it is stolen, so the session can not be trusted anymore
+		err = s.sessions.RevokeSession(ctx, session.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+		return nil, auth.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return &model.SessionGrant{SessionID: session.ID, UserID: session.UserID, RefreshToken: next}, nil
+}
+
+func (s *authService) EndSession(ctx context.Context, sessionID uuid.UUID) error {
+	const op = "end session"
+
+	err := s.sessions.RevokeSession(ctx, sessionID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (s *authService) IsSessionRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	const op = "is session revoked"
+
+	session, err := s.sessions.GetSession(ctx, sessionID)
+	if errors.Is(err, auth.ErrSessionNotFound) {
+		// sessions of deleted accounts are deleted along with them
+		return true, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, op)
+	}
+
+	return session.Revoked, nil
+}
+
+func (s *authService) IsTwoFactorEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	const op = "is two factor enabled"
+
+	f, err := s.twoFactors.GetTwoFactor(ctx, userID)
+	if errors.Is(err, auth.ErrTwoFactorNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, op)
+	}
+
+	return f.Enabled, nil
+}
+
+func (s *authService) EnrollTwoFactor(ctx context.Context, userID uuid.UUID) (*model.TwoFactorEnrollment, error) {
+	const op = "enroll two factor"
+
+	enabled, err := s.IsTwoFactorEnabled(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	if enabled {
+		return nil, auth.ErrTwoFactorEnabled
+	}
+
+	user, err := s.repo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	f, enrollment, recoveryCodes, err := model.NewTwoFactor(userID, user.Email)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	f, err = s.seal(f)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	// enrollment that has not been confirmed is started over
+	err = s.twoFactors.SaveTwoFactor(ctx, f, recoveryCodes)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return enrollment, nil
+}
+
+func (s *authService) ConfirmTwoFactor(ctx context.Context, userID uuid.UUID, code string) error {
+	const op = "confirm two factor"
+
+	f, err := s.twoFactor(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if f.Enabled {
+		return auth.ErrTwoFactorEnabled
+	}
+	err = s.useCode(ctx, f, code)
+	if errors.Is(err, auth.ErrInvalidCode) {
+		return auth.ErrInvalidCode
+	}
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = s.twoFactors.EnableTwoFactor(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (s *authService) VerifySecondFactor(ctx context.Context, userID uuid.UUID, code string) error {
+	const op = "verify second factor"
+
+	f, err := s.twoFactor(ctx, userID)
+	if errors.Is(err, auth.ErrTwoFactorNotFound) {
+		return auth.ErrInvalidCode
+	}
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if !f.Enabled {
+		return auth.ErrInvalidCode
+	}
+	err = s.useCode(ctx, f, code)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, auth.ErrInvalidCode) {
+		return errors.Wrap(err, op)
+	}
+
+	err = s.twoFactors.UseRecoveryCode(ctx, userID, model.HashRecoveryCode(code))
+	if errors.Is(err, auth.ErrRecoveryCodeNotFound) {
+		return auth.ErrInvalidCode
+	}
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// twoFactor returns the second factor of the user with the unsealed secret.
+// The secret stored before the secrets were sealed is sealed now.
+func (s *authService) twoFactor(ctx context.Context, userID uuid.UUID) (*model.TwoFactor, error) {
+	const op = "two factor"
+
+	f, err := s.twoFactors.GetTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	if s.masterKey == nil {
+		return f, nil
+	}
+	if f.IsSealed() {
+		f, err = f.Unseal(s.masterKey)
+		if err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+		return f, nil
+	}
+
+	sealed, err := f.Seal(s.masterKey)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	err = s.twoFactors.UpdateTwoFactorSecret(ctx, userID, sealed.Secret)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return f, nil
+}
+
+// seal returns the second factor with the secret sealed with the master key if the service has the key.
+func (s *authService) seal(f *model.TwoFactor) (*model.TwoFactor, error) {
+	if s.masterKey == nil {
+		return f, nil
+	}
+	return f.Seal(s.masterKey)
+}
+
+// useCode accepts the code of the authenticator app once: the time step of the accepted code is recorded,
+// so the same code is rejected even if it is sent concurrently.
+func (s *authService) useCode(ctx context.Context, f *model.TwoFactor, code string) error {
+	const op = "use code"
+
+	step, ok := f.ValidateCode(code, time.Now())
+	if !ok {
+		return auth.ErrInvalidCode
+	}
+
+	err := s.twoFactors.UseTimeStep(ctx, f.UserID, step)
+	if errors.Is(err, auth.ErrTimeStepUsed) {
+		return auth.ErrInvalidCode
+	}
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
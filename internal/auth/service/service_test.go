@@ -2,9 +2,14 @@ package service
 
 import (
 	"context"
+	"errors"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/pquerna/otp/totp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
@@ -12,6 +17,7 @@ import (
 	"github.com/nestjam/goph-keeper/internal/auth"
 	"github.com/nestjam/goph-keeper/internal/auth/model"
 	"github.com/nestjam/goph-keeper/internal/auth/repository/inmemory"
+	"github.com/nestjam/goph-keeper/internal/utils"
 )
 
 func TestRegister(t *testing.T) {
@@ -21,7 +27,8 @@ func TestRegister(t *testing.T) {
 			password = "1234"
 		)
 		repo := inmemory.NewUserRepository()
-		sut := NewAuthService(repo)
+		sut := NewAuthService(repo, inmemory.NewSessionRepository(), inmemory.NewTwoFactorRepository(),
+			&auth.UserDataShredderMock{})
 		user := &model.User{Email: email, Password: password}
 		ctx := context.Background()
 
@@ -36,7 +43,8 @@ func TestRegister(t *testing.T) {
 	t.Run("password is too long", func(t *testing.T) {
 		const email = "user@email.com"
 		repo := inmemory.NewUserRepository()
-		sut := NewAuthService(repo)
+		sut := NewAuthService(repo, inmemory.NewSessionRepository(), inmemory.NewTwoFactorRepository(),
+			&auth.UserDataShredderMock{})
 		user := &model.User{
 			Email:    email,
 			Password: strings.Repeat("0", model.PasswordMaxLengthInBytes+1),
@@ -55,7 +63,8 @@ func TestRegister(t *testing.T) {
 		repo := inmemory.NewUserRepository()
 		ctx := context.Background()
 		_, _ = repo.Register(ctx, &model.User{Email: email, Password: "psw"})
-		sut := NewAuthService(repo)
+		sut := NewAuthService(repo, inmemory.NewSessionRepository(), inmemory.NewTwoFactorRepository(),
+			&auth.UserDataShredderMock{})
 		user := &model.User{Email: email, Password: password}
 
 		_, err := sut.Register(ctx, user)
@@ -78,7 +87,8 @@ func TestLogin(t *testing.T) {
 		want.ID, err = repo.Register(ctx, want)
 		require.NoError(t, err)
 		user := &model.User{Email: email, Password: password}
-		sut := NewAuthService(repo)
+		sut := NewAuthService(repo, inmemory.NewSessionRepository(), inmemory.NewTwoFactorRepository(),
+			&auth.UserDataShredderMock{})
 
 		got, err := sut.Login(ctx, user)
 
@@ -94,15 +104,18 @@ func TestLogin(t *testing.T) {
 		repo := inmemory.NewUserRepository()
 		want := &model.User{Email: email, Password: password}
 		_ = want.HashPassword()
-		_, err := repo.Register(ctx, want)
+		var err error
+		want.ID, err = repo.Register(ctx, want)
 		require.NoError(t, err)
 		const invalidPassword = "4321"
 		user := &model.User{Email: email, Password: invalidPassword}
-		sut := NewAuthService(repo)
+		sut := NewAuthService(repo, inmemory.NewSessionRepository(), inmemory.NewTwoFactorRepository(),
+			&auth.UserDataShredderMock{})
 
-		_, err = sut.Login(ctx, user)
+		got, err := sut.Login(ctx, user)
 
 		require.ErrorIs(t, err, auth.ErrInvalidPassword)
+		assert.Equal(t, want.ID, got)
 	})
 	t.Run("user is not registered by email", func(t *testing.T) {
 		const (
@@ -110,7 +123,8 @@ func TestLogin(t *testing.T) {
 		)
 		ctx := context.Background()
 		repo := inmemory.NewUserRepository()
-		sut := NewAuthService(repo)
+		sut := NewAuthService(repo, inmemory.NewSessionRepository(), inmemory.NewTwoFactorRepository(),
+			&auth.UserDataShredderMock{})
 		user := &model.User{Email: email}
 
 		_, err := sut.Login(ctx, user)
@@ -118,3 +132,604 @@ func TestLogin(t *testing.T) {
 		require.ErrorIs(t, err, auth.ErrUserIsNotRegistered)
 	})
 }
+
+func TestDeleteAccount(t *testing.T) {
+	t.Run("delete account with user data", func(t *testing.T) {
+		const email = "user@mail.com"
+		ctx := context.Background()
+		repo := inmemory.NewUserRepository()
+		var shreddedUserID uuid.UUID
+		shredder := &auth.UserDataShredderMock{
+			DeleteUserDataFunc: func(ctx context.Context, userID uuid.UUID) (func(context.Context) error, error) {
+				shreddedUserID = userID
+				return func(context.Context) error { return nil }, nil
+			},
+		}
+		sut := NewAuthService(repo, inmemory.NewSessionRepository(), inmemory.NewTwoFactorRepository(), shredder)
+		userID, err := sut.Register(ctx, &model.User{Email: email, Password: "1234"})
+		require.NoError(t, err)
+
+		err = sut.DeleteAccount(ctx, userID, "1234", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, userID, shreddedUserID)
+		_, err = repo.FindByEmail(ctx, email)
+		require.ErrorIs(t, err, auth.ErrUserIsNotRegistered)
+	})
+	t.Run("delete account with second factor", func(t *testing.T) {
+		ctx := context.Background()
+		shredder := &auth.UserDataShredderMock{
+			DeleteUserDataFunc: func(ctx context.Context, userID uuid.UUID) (func(context.Context) error, error) {
+				return func(context.Context) error { return nil }, nil
+			},
+		}
+		sut := NewAuthService(inmemory.NewUserRepository(), inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), shredder)
+		userID, err := sut.Register(ctx, &model.User{Email: "user@mail.com", Password: "1234"})
+		require.NoError(t, err)
+		enrollment := enableTwoFactor(t, sut, userID)
+
+		err = sut.DeleteAccount(ctx, userID, "1234", generateCode(t, enrollment))
+
+		require.NoError(t, err)
+		_, err = sut.Login(ctx, &model.User{Email: "user@mail.com", Password: "1234"})
+		require.ErrorIs(t, err, auth.ErrUserIsNotRegistered)
+	})
+	t.Run("invalid password", func(t *testing.T) {
+		ctx := context.Background()
+		sut, userID, _ := newPasswordAuthService(t)
+
+		err := sut.DeleteAccount(ctx, userID, "5678", "")
+
+		require.ErrorIs(t, err, auth.ErrInvalidPassword)
+		_, err = sut.Login(ctx, &model.User{Email: "user@mail.com", Password: "1234"})
+		require.NoError(t, err)
+	})
+	t.Run("invalid code of second factor", func(t *testing.T) {
+		ctx := context.Background()
+		sut, userID, _ := newPasswordAuthService(t)
+		_ = enableTwoFactor(t, sut, userID)
+
+		err := sut.DeleteAccount(ctx, userID, "1234", "000000")
+
+		require.ErrorIs(t, err, auth.ErrInvalidCode)
+		_, err = sut.Login(ctx, &model.User{Email: "user@mail.com", Password: "1234"})
+		require.NoError(t, err)
+	})
+	t.Run("failed to delete user data", func(t *testing.T) {
+		const email = "user@mail.com"
+		ctx := context.Background()
+		repo := inmemory.NewUserRepository()
+		shredder := &auth.UserDataShredderMock{
+			DeleteUserDataFunc: func(ctx context.Context, userID uuid.UUID) (func(context.Context) error, error) {
+				return nil, errors.New("failed")
+			},
+		}
+		sut := NewAuthService(repo, inmemory.NewSessionRepository(), inmemory.NewTwoFactorRepository(), shredder)
+		userID, err := sut.Register(ctx, &model.User{Email: email, Password: "1234"})
+		require.NoError(t, err)
+
+		err = sut.DeleteAccount(ctx, userID, "1234", "")
+
+		require.Error(t, err)
+		_, err = repo.FindByEmail(ctx, email)
+		require.NoError(t, err)
+	})
+	t.Run("data out of storage is destroyed after deletion is committed", func(t *testing.T) {
+		ctx := context.Background()
+		var inTx, committed, cleanedUp bool
+		transactor := &transactorMock{
+			WithinTransactionFunc: func(ctx context.Context, fn func(ctx context.Context) error) error {
+				inTx = true
+				err := fn(ctx)
+				inTx = false
+				committed = err == nil
+				return err
+			},
+		}
+		shredder := &auth.UserDataShredderMock{
+			DeleteUserDataFunc: func(ctx context.Context, userID uuid.UUID) (func(context.Context) error, error) {
+				assert.True(t, inTx)
+				return func(context.Context) error {
+					assert.True(t, committed)
+					cleanedUp = true
+					return nil
+				}, nil
+			},
+		}
+		sut := NewAuthService(inmemory.NewUserRepository(), inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), shredder, WithTransactor(transactor))
+		userID, err := sut.Register(ctx, &model.User{Email: "user@mail.com", Password: "1234"})
+		require.NoError(t, err)
+
+		err = sut.DeleteAccount(ctx, userID, "1234", "")
+
+		require.NoError(t, err)
+		assert.True(t, cleanedUp)
+	})
+	t.Run("data out of storage is kept if deletion is not committed", func(t *testing.T) {
+		ctx := context.Background()
+		transactor := &transactorMock{
+			WithinTransactionFunc: func(ctx context.Context, fn func(ctx context.Context) error) error {
+				err := fn(ctx)
+				require.NoError(t, err)
+				return errors.New("commit failed")
+			},
+		}
+		var cleanedUp bool
+		shredder := &auth.UserDataShredderMock{
+			DeleteUserDataFunc: func(ctx context.Context, userID uuid.UUID) (func(context.Context) error, error) {
+				return func(context.Context) error {
+					cleanedUp = true
+					return nil
+				}, nil
+			},
+		}
+		sut := NewAuthService(inmemory.NewUserRepository(), inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), shredder, WithTransactor(transactor))
+		userID, err := sut.Register(ctx, &model.User{Email: "user@mail.com", Password: "1234"})
+		require.NoError(t, err)
+
+		err = sut.DeleteAccount(ctx, userID, "1234", "")
+
+		require.Error(t, err)
+		assert.False(t, cleanedUp)
+	})
+	t.Run("user is not registered", func(t *testing.T) {
+		ctx := context.Background()
+		repo := inmemory.NewUserRepository()
+		shredder := &auth.UserDataShredderMock{
+			DeleteUserDataFunc: func(ctx context.Context, userID uuid.UUID) (func(context.Context) error, error) {
+				return func(context.Context) error { return nil }, nil
+			},
+		}
+		sut := NewAuthService(repo, inmemory.NewSessionRepository(), inmemory.NewTwoFactorRepository(), shredder)
+
+		err := sut.DeleteAccount(ctx, uuid.New(), "1234", "")
+
+		require.ErrorIs(t, err, auth.ErrUserIsNotRegistered)
+	})
+}
+
+func TestChangePassword(t *testing.T) {
+	t.Run("change password", func(t *testing.T) {
+		ctx := context.Background()
+		sut, userID, sessionID := newPasswordAuthService(t)
+
+		err := sut.ChangePassword(ctx, userID, "1234", "5678")
+
+		require.NoError(t, err)
+		_, err = sut.Login(ctx, &model.User{Email: "user@mail.com", Password: "5678"})
+		require.NoError(t, err)
+		revoked, err := sut.IsSessionRevoked(ctx, sessionID)
+		require.NoError(t, err)
+		assert.True(t, revoked)
+	})
+	t.Run("change password revokes access tokens", func(t *testing.T) {
+		ctx := context.Background()
+		tokens := inmemory.NewAccessTokenRepository()
+		sut, userID, _ := newPasswordAuthService(t, WithAccessTokens(tokens))
+		createAccessToken(t, tokens, userID)
+
+		err := sut.ChangePassword(ctx, userID, "1234", "5678")
+
+		require.NoError(t, err)
+		got, err := tokens.ListAccessTokens(ctx, userID)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+	t.Run("current password is wrong", func(t *testing.T) {
+		ctx := context.Background()
+		sut, userID, sessionID := newPasswordAuthService(t)
+
+		err := sut.ChangePassword(ctx, userID, "0000", "5678")
+
+		require.ErrorIs(t, err, auth.ErrInvalidPassword)
+		revoked, err := sut.IsSessionRevoked(ctx, sessionID)
+		require.NoError(t, err)
+		assert.False(t, revoked)
+	})
+	t.Run("new password is empty", func(t *testing.T) {
+		sut, userID, _ := newPasswordAuthService(t)
+
+		err := sut.ChangePassword(context.Background(), userID, "1234", "")
+
+		require.ErrorIs(t, err, auth.ErrUserPasswordIsEmpty)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	t.Run("reset password by recovery code", func(t *testing.T) {
+		ctx := context.Background()
+		sut, userID, sessionID := newPasswordAuthService(t)
+		enrollment := enableTwoFactor(t, sut, userID)
+
+		got, err := sut.ResetPassword(ctx, "user@mail.com", enrollment.RecoveryCodes[0], "5678")
+
+		require.NoError(t, err)
+		assert.Equal(t, userID, got)
+		_, err = sut.Login(ctx, &model.User{Email: "user@mail.com", Password: "5678"})
+		require.NoError(t, err)
+		revoked, err := sut.IsSessionRevoked(ctx, sessionID)
+		require.NoError(t, err)
+		assert.True(t, revoked)
+	})
+	t.Run("reset password revokes access tokens", func(t *testing.T) {
+		ctx := context.Background()
+		tokens := inmemory.NewAccessTokenRepository()
+		sut, userID, _ := newPasswordAuthService(t, WithAccessTokens(tokens))
+		enrollment := enableTwoFactor(t, sut, userID)
+		createAccessToken(t, tokens, userID)
+
+		_, err := sut.ResetPassword(ctx, "user@mail.com", enrollment.RecoveryCodes[0], "5678")
+
+		require.NoError(t, err)
+		got, err := tokens.ListAccessTokens(ctx, userID)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+	t.Run("recovery code is used once", func(t *testing.T) {
+		ctx := context.Background()
+		sut, userID, _ := newPasswordAuthService(t)
+		enrollment := enableTwoFactor(t, sut, userID)
+		_, err := sut.ResetPassword(ctx, "user@mail.com", enrollment.RecoveryCodes[0], "5678")
+		require.NoError(t, err)
+
+		_, err = sut.ResetPassword(ctx, "user@mail.com", enrollment.RecoveryCodes[0], "0000")
+
+		require.ErrorIs(t, err, auth.ErrInvalidCode)
+	})
+	t.Run("invalid code", func(t *testing.T) {
+		sut, userID, _ := newPasswordAuthService(t)
+		enableTwoFactor(t, sut, userID)
+
+		_, err := sut.ResetPassword(context.Background(), "user@mail.com", "code", "5678")
+
+		require.ErrorIs(t, err, auth.ErrInvalidCode)
+	})
+	t.Run("two factor is not enabled", func(t *testing.T) {
+		ctx := context.Background()
+		sut, userID, _ := newPasswordAuthService(t)
+		enrollment, err := sut.EnrollTwoFactor(ctx, userID)
+		require.NoError(t, err)
+
+		_, err = sut.ResetPassword(ctx, "user@mail.com", enrollment.RecoveryCodes[0], "5678")
+
+		require.ErrorIs(t, err, auth.ErrInvalidCode)
+	})
+	t.Run("user is not registered", func(t *testing.T) {
+		sut, _, _ := newPasswordAuthService(t)
+
+		_, err := sut.ResetPassword(context.Background(), "unknown@mail.com", "code", "5678")
+
+		require.ErrorIs(t, err, auth.ErrInvalidCode)
+	})
+}
+
+func TestStartSession(t *testing.T) {
+	t.Run("start session of user", func(t *testing.T) {
+		ctx := context.Background()
+		sessions := inmemory.NewSessionRepository()
+		sut := NewAuthService(inmemory.NewUserRepository(), sessions, inmemory.NewTwoFactorRepository(),
+			&auth.UserDataShredderMock{})
+		userID := uuid.New()
+
+		got, err := sut.StartSession(ctx, userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, userID, got.UserID)
+		assert.NotEmpty(t, got.RefreshToken)
+		session, err := sessions.GetSession(ctx, got.SessionID)
+		require.NoError(t, err)
+		assert.Equal(t, userID, session.UserID)
+		token, err := sessions.FindRefreshToken(ctx, model.HashRefreshToken(got.RefreshToken))
+		require.NoError(t, err)
+		assert.Equal(t, got.SessionID, token.SessionID)
+	})
+}
+
+func TestRefreshSession(t *testing.T) {
+	t.Run("refresh token is rotated", func(t *testing.T) {
+		ctx := context.Background()
+		sut := NewAuthService(inmemory.NewUserRepository(), inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{})
+		grant, err := sut.StartSession(ctx, uuid.New())
+		require.NoError(t, err)
+
+		got, err := sut.RefreshSession(ctx, grant.RefreshToken)
+
+		require.NoError(t, err)
+		assert.Equal(t, grant.SessionID, got.SessionID)
+		assert.Equal(t, grant.UserID, got.UserID)
+		assert.NotEqual(t, grant.RefreshToken, got.RefreshToken)
+	})
+	t.Run("reused refresh token revokes session", func(t *testing.T) {
+		ctx := context.Background()
+		sut := NewAuthService(inmemory.NewUserRepository(), inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{})
+		grant, err := sut.StartSession(ctx, uuid.New())
+		require.NoError(t, err)
+		next, err := sut.RefreshSession(ctx, grant.RefreshToken)
+		require.NoError(t, err)
+
+		_, err = sut.RefreshSession(ctx, grant.RefreshToken)
+
+		require.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+		revoked, err := sut.IsSessionRevoked(ctx, grant.SessionID)
+		require.NoError(t, err)
+		assert.True(t, revoked)
+		_, err = sut.RefreshSession(ctx, next.RefreshToken)
+		require.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+	})
+	t.Run("refresh token is expired", func(t *testing.T) {
+		ctx := context.Background()
+		sut := NewAuthService(inmemory.NewUserRepository(), inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{}, WithRefreshTokenExpiryIn(-time.Second))
+		grant, err := sut.StartSession(ctx, uuid.New())
+		require.NoError(t, err)
+
+		_, err = sut.RefreshSession(ctx, grant.RefreshToken)
+
+		require.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+	})
+	t.Run("refresh token is unknown", func(t *testing.T) {
+		sut := NewAuthService(inmemory.NewUserRepository(), inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{})
+
+		_, err := sut.RefreshSession(context.Background(), "token")
+
+		require.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+	})
+	t.Run("session is ended", func(t *testing.T) {
+		ctx := context.Background()
+		sut := NewAuthService(inmemory.NewUserRepository(), inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{})
+		grant, err := sut.StartSession(ctx, uuid.New())
+		require.NoError(t, err)
+		require.NoError(t, sut.EndSession(ctx, grant.SessionID))
+
+		_, err = sut.RefreshSession(ctx, grant.RefreshToken)
+
+		require.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+	})
+}
+
+func TestIsSessionRevoked(t *testing.T) {
+	t.Run("session is active", func(t *testing.T) {
+		ctx := context.Background()
+		sut := NewAuthService(inmemory.NewUserRepository(), inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{})
+		grant, err := sut.StartSession(ctx, uuid.New())
+		require.NoError(t, err)
+
+		got, err := sut.IsSessionRevoked(ctx, grant.SessionID)
+
+		require.NoError(t, err)
+		assert.False(t, got)
+	})
+	t.Run("session does not exist", func(t *testing.T) {
+		sut := NewAuthService(inmemory.NewUserRepository(), inmemory.NewSessionRepository(),
+			inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{})
+
+		got, err := sut.IsSessionRevoked(context.Background(), uuid.New())
+
+		require.NoError(t, err)
+		assert.True(t, got)
+	})
+}
+
+func TestEnrollTwoFactor(t *testing.T) {
+	t.Run("enroll two factor", func(t *testing.T) {
+		ctx := context.Background()
+		sut, userID := newTwoFactorAuthService(t)
+
+		got, err := sut.EnrollTwoFactor(ctx, userID)
+
+		require.NoError(t, err)
+		assert.Contains(t, got.URI, "user@email.com")
+		assert.Len(t, got.RecoveryCodes, model.RecoveryCodesCount)
+		enabled, err := sut.IsTwoFactorEnabled(ctx, userID)
+		require.NoError(t, err)
+		assert.False(t, enabled)
+	})
+	t.Run("two factor has already been enabled", func(t *testing.T) {
+		ctx := context.Background()
+		sut, userID := newTwoFactorAuthService(t)
+		enableTwoFactor(t, sut, userID)
+
+		_, err := sut.EnrollTwoFactor(ctx, userID)
+
+		require.ErrorIs(t, err, auth.ErrTwoFactorEnabled)
+	})
+}
+
+func TestConfirmTwoFactor(t *testing.T) {
+	t.Run("confirm two factor", func(t *testing.T) {
+		ctx := context.Background()
+		sut, userID := newTwoFactorAuthService(t)
+		enrollment, err := sut.EnrollTwoFactor(ctx, userID)
+		require.NoError(t, err)
+
+		err = sut.ConfirmTwoFactor(ctx, userID, generateCode(t, enrollment))
+
+		require.NoError(t, err)
+		enabled, err := sut.IsTwoFactorEnabled(ctx, userID)
+		require.NoError(t, err)
+		assert.True(t, enabled)
+	})
+	t.Run("invalid code", func(t *testing.T) {
+		ctx := context.Background()
+		sut, userID := newTwoFactorAuthService(t)
+		_, err := sut.EnrollTwoFactor(ctx, userID)
+		require.NoError(t, err)
+
+		err = sut.ConfirmTwoFactor(ctx, userID, "code")
+
+		require.ErrorIs(t, err, auth.ErrInvalidCode)
+	})
+	t.Run("two factor is not enrolled", func(t *testing.T) {
+		sut, userID := newTwoFactorAuthService(t)
+
+		err := sut.ConfirmTwoFactor(context.Background(), userID, "123456")
+
+		require.ErrorIs(t, err, auth.ErrTwoFactorNotFound)
+	})
+}
+
+func TestVerifySecondFactor(t *testing.T) {
+	t.Run("code of authenticator app", func(t *testing.T) {
+		sut, userID := newTwoFactorAuthService(t)
+		enrollment := enableTwoFactor(t, sut, userID)
+
+		err := sut.VerifySecondFactor(context.Background(), userID, generateCode(t, enrollment))
+
+		require.NoError(t, err)
+	})
+	t.Run("code of authenticator app is used once", func(t *testing.T) {
+		ctx := context.Background()
+		sut, userID := newTwoFactorAuthService(t)
+		enrollment := enableTwoFactor(t, sut, userID)
+		code := generateCode(t, enrollment)
+
+		err := sut.VerifySecondFactor(ctx, userID, code)
+
+		require.NoError(t, err)
+		err = sut.VerifySecondFactor(ctx, userID, code)
+		require.ErrorIs(t, err, auth.ErrInvalidCode)
+	})
+	t.Run("recovery code is used once", func(t *testing.T) {
+		ctx := context.Background()
+		sut, userID := newTwoFactorAuthService(t)
+		enrollment := enableTwoFactor(t, sut, userID)
+
+		err := sut.VerifySecondFactor(ctx, userID, enrollment.RecoveryCodes[0])
+
+		require.NoError(t, err)
+		err = sut.VerifySecondFactor(ctx, userID, enrollment.RecoveryCodes[0])
+		require.ErrorIs(t, err, auth.ErrInvalidCode)
+	})
+	t.Run("invalid code", func(t *testing.T) {
+		sut, userID := newTwoFactorAuthService(t)
+		enableTwoFactor(t, sut, userID)
+
+		err := sut.VerifySecondFactor(context.Background(), userID, "code")
+
+		require.ErrorIs(t, err, auth.ErrInvalidCode)
+	})
+	t.Run("two factor is not confirmed", func(t *testing.T) {
+		ctx := context.Background()
+		sut, userID := newTwoFactorAuthService(t)
+		enrollment, err := sut.EnrollTwoFactor(ctx, userID)
+		require.NoError(t, err)
+
+		err = sut.VerifySecondFactor(ctx, userID, enrollment.RecoveryCodes[0])
+
+		require.ErrorIs(t, err, auth.ErrInvalidCode)
+	})
+}
+
+func TestTwoFactorSecretSealing(t *testing.T) {
+	t.Run("secret is stored sealed", func(t *testing.T) {
+		ctx := context.Background()
+		sut, twoFactors, userID := newSealingAuthService(t)
+		enrollment := enableTwoFactor(t, sut, userID)
+
+		err := sut.VerifySecondFactor(ctx, userID, generateCode(t, enrollment))
+
+		require.NoError(t, err)
+		got, err := twoFactors.GetTwoFactor(ctx, userID)
+		require.NoError(t, err)
+		assert.True(t, got.IsSealed())
+	})
+	t.Run("secret stored before secrets were sealed is sealed on use", func(t *testing.T) {
+		ctx := context.Background()
+		sut, twoFactors, userID := newSealingAuthService(t)
+		f, enrollment, hashes, err := model.NewTwoFactor(userID, "user@email.com")
+		require.NoError(t, err)
+		f.Enabled = true
+		require.NoError(t, twoFactors.SaveTwoFactor(ctx, f, hashes))
+
+		err = sut.VerifySecondFactor(ctx, userID, generateCode(t, enrollment))
+
+		require.NoError(t, err)
+		got, err := twoFactors.GetTwoFactor(ctx, userID)
+		require.NoError(t, err)
+		assert.True(t, got.IsSealed())
+	})
+}
+
+func newSealingAuthService(t *testing.T) (auth.AuthService, auth.TwoFactorRepository, uuid.UUID) {
+	t.Helper()
+
+	key, err := utils.GenerateRandomAES256Key()
+	require.NoError(t, err)
+	repo := inmemory.NewUserRepository()
+	userID, err := repo.Register(context.Background(), &model.User{Email: "user@email.com", Password: "1"})
+	require.NoError(t, err)
+	twoFactors := inmemory.NewTwoFactorRepository()
+	sut := NewAuthService(repo, inmemory.NewSessionRepository(), twoFactors, &auth.UserDataShredderMock{},
+		WithMasterKey(key))
+	return sut, twoFactors, userID
+}
+
+func newTwoFactorAuthService(t *testing.T) (auth.AuthService, uuid.UUID) {
+	t.Helper()
+
+	repo := inmemory.NewUserRepository()
+	userID, err := repo.Register(context.Background(), &model.User{Email: "user@email.com", Password: "1"})
+	require.NoError(t, err)
+	sut := NewAuthService(repo, inmemory.NewSessionRepository(), inmemory.NewTwoFactorRepository(),
+		&auth.UserDataShredderMock{})
+	return sut, userID
+}
+
+// newPasswordAuthService returns the service with the user registered with password 1234 and the session of the user.
+func newPasswordAuthService(t *testing.T, opts ...AuthServiceOption) (auth.AuthService, uuid.UUID, uuid.UUID) {
+	t.Helper()
+
+	ctx := context.Background()
+	sut := NewAuthService(inmemory.NewUserRepository(), inmemory.NewSessionRepository(),
+		inmemory.NewTwoFactorRepository(), &auth.UserDataShredderMock{}, opts...)
+	userID, err := sut.Register(ctx, &model.User{Email: "user@mail.com", Password: "1234"})
+	require.NoError(t, err)
+	grant, err := sut.StartSession(ctx, userID)
+	require.NoError(t, err)
+	return sut, userID, grant.SessionID
+}
+
+func createAccessToken(t *testing.T, tokens auth.AccessTokenRepository, userID uuid.UUID) {
+	t.Helper()
+
+	token := &model.AccessToken{UserID: userID, Name: "ci", Scope: model.ScopeRead}
+	_, err := model.NewAccessToken(token)
+	require.NoError(t, err)
+	require.NoError(t, tokens.CreateAccessToken(context.Background(), token))
+}
+
+func enableTwoFactor(t *testing.T, sut auth.AuthService, userID uuid.UUID) *model.TwoFactorEnrollment {
+	t.Helper()
+
+	ctx := context.Background()
+	enrollment, err := sut.EnrollTwoFactor(ctx, userID)
+	require.NoError(t, err)
+	// the code of the previous period confirms the second factor, so the current code is left for the login
+	err = sut.ConfirmTwoFactor(ctx, userID, generateCodeAt(t, enrollment, time.Now().Add(-30*time.Second)))
+	require.NoError(t, err)
+	return enrollment
+}
+
+func generateCode(t *testing.T, enrollment *model.TwoFactorEnrollment) string {
+	t.Helper()
+
+	return generateCodeAt(t, enrollment, time.Now())
+}
+
+func generateCodeAt(t *testing.T, enrollment *model.TwoFactorEnrollment, at time.Time) string {
+	t.Helper()
+
+	u, err := url.Parse(enrollment.URI)
+	require.NoError(t, err)
+	code, err := totp.GenerateCode(u.Query().Get("secret"), at)
+	require.NoError(t, err)
+	return code
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+)
+
+var (
+	ErrSessionNotFound      = errors.New("session not found")
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrRefreshTokenUsed     = errors.New("refresh token has already been used")
+)
+
+type SessionRepository interface {
+	// CreateSession stores the new session along with its first refresh token.
+	CreateSession(ctx context.Context, session *model.Session, token *model.RefreshToken) error
+	GetSession(ctx context.Context, sessionID uuid.UUID) (*model.Session, error)
+	FindRefreshToken(ctx context.Context, hash []byte) (*model.RefreshToken, error)
+	// UseRefreshToken marks the token used and stores the next token of the session.
+	// It fails with ErrRefreshTokenUsed if the token has been used already.
+	UseRefreshToken(ctx context.Context, hash []byte, next *model.RefreshToken) error
+	RevokeSession(ctx context.Context, sessionID uuid.UUID) error
+	RevokeUserSessions(ctx context.Context, userID uuid.UUID) error
+	DeleteUserSessions(ctx context.Context, userID uuid.UUID) error
+}
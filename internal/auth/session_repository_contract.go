@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+)
+
+type SessionTestData struct {
+	Users uuid.UUIDs
+}
+
+type SessionRepositoryContract struct {
+	NewSessionRepository func() (SessionRepository, func(), SessionTestData)
+}
+
+func (c SessionRepositoryContract) Test(t *testing.T) {
+	t.Run("create session", func(t *testing.T) {
+		sut, tearDown, td := c.NewSessionRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		session, token := newSession(t, td.Users[0])
+
+		err := sut.CreateSession(ctx, session, token)
+
+		require.NoError(t, err)
+		got, err := sut.GetSession(ctx, session.ID)
+		require.NoError(t, err)
+		assert.Equal(t, session.UserID, got.UserID)
+		assert.False(t, got.Revoked)
+		gotToken, err := sut.FindRefreshToken(ctx, token.Hash)
+		require.NoError(t, err)
+		assert.Equal(t, token, gotToken)
+	})
+	t.Run("get session that does not exist", func(t *testing.T) {
+		sut, tearDown, _ := c.NewSessionRepository()
+		t.Cleanup(tearDown)
+
+		_, err := sut.GetSession(context.Background(), uuid.New())
+
+		require.ErrorIs(t, err, ErrSessionNotFound)
+	})
+	t.Run("find refresh token that does not exist", func(t *testing.T) {
+		sut, tearDown, _ := c.NewSessionRepository()
+		t.Cleanup(tearDown)
+
+		_, err := sut.FindRefreshToken(context.Background(), model.HashRefreshToken("token"))
+
+		require.ErrorIs(t, err, ErrRefreshTokenNotFound)
+	})
+	t.Run("use refresh token", func(t *testing.T) {
+		sut, tearDown, td := c.NewSessionRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		session, token := newSession(t, td.Users[0])
+		require.NoError(t, sut.CreateSession(ctx, session, token))
+		_, next, err := model.NewRefreshToken(session.ID, time.Hour)
+		require.NoError(t, err)
+
+		err = sut.UseRefreshToken(ctx, token.Hash, next)
+
+		require.NoError(t, err)
+		used, err := sut.FindRefreshToken(ctx, token.Hash)
+		require.NoError(t, err)
+		assert.True(t, used.Used)
+		got, err := sut.FindRefreshToken(ctx, next.Hash)
+		require.NoError(t, err)
+		assert.Equal(t, next, got)
+	})
+	t.Run("use refresh token twice", func(t *testing.T) {
+		sut, tearDown, td := c.NewSessionRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		session, token := newSession(t, td.Users[0])
+		require.NoError(t, sut.CreateSession(ctx, session, token))
+		_, next, err := model.NewRefreshToken(session.ID, time.Hour)
+		require.NoError(t, err)
+		require.NoError(t, sut.UseRefreshToken(ctx, token.Hash, next))
+		_, another, err := model.NewRefreshToken(session.ID, time.Hour)
+		require.NoError(t, err)
+
+		err = sut.UseRefreshToken(ctx, token.Hash, another)
+
+		require.ErrorIs(t, err, ErrRefreshTokenUsed)
+		_, err = sut.FindRefreshToken(ctx, another.Hash)
+		require.ErrorIs(t, err, ErrRefreshTokenNotFound)
+	})
+	t.Run("use refresh token that does not exist", func(t *testing.T) {
+		sut, tearDown, td := c.NewSessionRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		session, _ := newSession(t, td.Users[0])
+		_, next, err := model.NewRefreshToken(session.ID, time.Hour)
+		require.NoError(t, err)
+
+		err = sut.UseRefreshToken(ctx, model.HashRefreshToken("token"), next)
+
+		require.ErrorIs(t, err, ErrRefreshTokenNotFound)
+	})
+	t.Run("revoke session", func(t *testing.T) {
+		sut, tearDown, td := c.NewSessionRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		session, token := newSession(t, td.Users[0])
+		require.NoError(t, sut.CreateSession(ctx, session, token))
+
+		err := sut.RevokeSession(ctx, session.ID)
+
+		require.NoError(t, err)
+		got, err := sut.GetSession(ctx, session.ID)
+		require.NoError(t, err)
+		assert.True(t, got.Revoked)
+	})
+	t.Run("revoke session that does not exist", func(t *testing.T) {
+		sut, tearDown, _ := c.NewSessionRepository()
+		t.Cleanup(tearDown)
+
+		err := sut.RevokeSession(context.Background(), uuid.New())
+
+		require.ErrorIs(t, err, ErrSessionNotFound)
+	})
+	t.Run("revoke user sessions", func(t *testing.T) {
+		sut, tearDown, td := c.NewSessionRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		session, token := newSession(t, td.Users[0])
+		require.NoError(t, sut.CreateSession(ctx, session, token))
+		another, anotherToken := newSession(t, td.Users[1])
+		require.NoError(t, sut.CreateSession(ctx, another, anotherToken))
+
+		err := sut.RevokeUserSessions(ctx, td.Users[0])
+
+		require.NoError(t, err)
+		got, err := sut.GetSession(ctx, session.ID)
+		require.NoError(t, err)
+		assert.True(t, got.Revoked)
+		got, err = sut.GetSession(ctx, another.ID)
+		require.NoError(t, err)
+		assert.False(t, got.Revoked)
+	})
+	t.Run("delete user sessions", func(t *testing.T) {
+		sut, tearDown, td := c.NewSessionRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		session, token := newSession(t, td.Users[0])
+		require.NoError(t, sut.CreateSession(ctx, session, token))
+		another, anotherToken := newSession(t, td.Users[1])
+		require.NoError(t, sut.CreateSession(ctx, another, anotherToken))
+
+		err := sut.DeleteUserSessions(ctx, td.Users[0])
+
+		require.NoError(t, err)
+		_, err = sut.GetSession(ctx, session.ID)
+		require.ErrorIs(t, err, ErrSessionNotFound)
+		_, err = sut.FindRefreshToken(ctx, token.Hash)
+		require.ErrorIs(t, err, ErrRefreshTokenNotFound)
+		_, err = sut.GetSession(ctx, another.ID)
+		require.NoError(t, err)
+	})
+}
+
+func newSession(t *testing.T, userID uuid.UUID) (*model.Session, *model.RefreshToken) {
+	t.Helper()
+
+	session := &model.Session{
+		ID:        uuid.New(),
+		UserID:    userID,
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	_, token, err := model.NewRefreshToken(session.ID, time.Hour)
+	require.NoError(t, err)
+	return session, token
+}
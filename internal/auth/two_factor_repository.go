@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+)
+
+var (
+	ErrTwoFactorNotFound    = errors.New("two factor not found")
+	ErrRecoveryCodeNotFound = errors.New("recovery code not found")
+	ErrTimeStepUsed         = errors.New("time step is used")
+)
+
+type TwoFactorRepository interface {
+	// SaveTwoFactor stores the second factor of the user along with the hashes of its recovery codes.
+	// The second factor the user had before is replaced with its recovery codes.
+	SaveTwoFactor(ctx context.Context, f *model.TwoFactor, recoveryCodes [][]byte) error
+	GetTwoFactor(ctx context.Context, userID uuid.UUID) (*model.TwoFactor, error)
+	EnableTwoFactor(ctx context.Context, userID uuid.UUID) error
+	// UpdateTwoFactorSecret replaces the secret of the second factor of the user, e.g. with the sealed one.
+	UpdateTwoFactorSecret(ctx context.Context, userID uuid.UUID, secret string) error
+	// UseTimeStep records the time step of the accepted code of the user. It fails with ErrTimeStepUsed
+	// if the code of this or a later step is accepted already, so the code can be used once.
+	UseTimeStep(ctx context.Context, userID uuid.UUID, step int64) error
+	// UseRecoveryCode deletes the recovery code of the user, so the code can be used once.
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash []byte) error
+}
@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+)
+
+type TwoFactorTestData struct {
+	Users uuid.UUIDs
+}
+
+type TwoFactorRepositoryContract struct {
+	NewTwoFactorRepository func() (TwoFactorRepository, func(), TwoFactorTestData)
+}
+
+func (c TwoFactorRepositoryContract) Test(t *testing.T) {
+	t.Run("save two factor", func(t *testing.T) {
+		sut, tearDown, td := c.NewTwoFactorRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		f, hashes := newTwoFactor(t, td.Users[0])
+
+		err := sut.SaveTwoFactor(ctx, f, hashes)
+
+		require.NoError(t, err)
+		got, err := sut.GetTwoFactor(ctx, td.Users[0])
+		require.NoError(t, err)
+		assert.Equal(t, f, got)
+	})
+	t.Run("get two factor that does not exist", func(t *testing.T) {
+		sut, tearDown, td := c.NewTwoFactorRepository()
+		t.Cleanup(tearDown)
+
+		_, err := sut.GetTwoFactor(context.Background(), td.Users[0])
+
+		require.ErrorIs(t, err, ErrTwoFactorNotFound)
+	})
+	t.Run("replace two factor", func(t *testing.T) {
+		sut, tearDown, td := c.NewTwoFactorRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		f, hashes := newTwoFactor(t, td.Users[0])
+		require.NoError(t, sut.SaveTwoFactor(ctx, f, hashes))
+		another, anotherHashes := newTwoFactor(t, td.Users[0])
+
+		err := sut.SaveTwoFactor(ctx, another, anotherHashes)
+
+		require.NoError(t, err)
+		got, err := sut.GetTwoFactor(ctx, td.Users[0])
+		require.NoError(t, err)
+		assert.Equal(t, another, got)
+		err = sut.UseRecoveryCode(ctx, td.Users[0], hashes[0])
+		require.ErrorIs(t, err, ErrRecoveryCodeNotFound)
+	})
+	t.Run("enable two factor", func(t *testing.T) {
+		sut, tearDown, td := c.NewTwoFactorRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		f, hashes := newTwoFactor(t, td.Users[0])
+		require.NoError(t, sut.SaveTwoFactor(ctx, f, hashes))
+
+		err := sut.EnableTwoFactor(ctx, td.Users[0])
+
+		require.NoError(t, err)
+		got, err := sut.GetTwoFactor(ctx, td.Users[0])
+		require.NoError(t, err)
+		assert.True(t, got.Enabled)
+	})
+	t.Run("enable two factor that does not exist", func(t *testing.T) {
+		sut, tearDown, td := c.NewTwoFactorRepository()
+		t.Cleanup(tearDown)
+
+		err := sut.EnableTwoFactor(context.Background(), td.Users[0])
+
+		require.ErrorIs(t, err, ErrTwoFactorNotFound)
+	})
+	t.Run("update two factor secret", func(t *testing.T) {
+		sut, tearDown, td := c.NewTwoFactorRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		f, hashes := newTwoFactor(t, td.Users[0])
+		require.NoError(t, sut.SaveTwoFactor(ctx, f, hashes))
+
+		err := sut.UpdateTwoFactorSecret(ctx, td.Users[0], "sealed")
+
+		require.NoError(t, err)
+		got, err := sut.GetTwoFactor(ctx, td.Users[0])
+		require.NoError(t, err)
+		assert.Equal(t, "sealed", got.Secret)
+		err = sut.UseRecoveryCode(ctx, td.Users[0], hashes[0])
+		require.NoError(t, err)
+	})
+	t.Run("update secret of two factor that does not exist", func(t *testing.T) {
+		sut, tearDown, td := c.NewTwoFactorRepository()
+		t.Cleanup(tearDown)
+
+		err := sut.UpdateTwoFactorSecret(context.Background(), td.Users[0], "sealed")
+
+		require.ErrorIs(t, err, ErrTwoFactorNotFound)
+	})
+	t.Run("use time step", func(t *testing.T) {
+		sut, tearDown, td := c.NewTwoFactorRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		f, hashes := newTwoFactor(t, td.Users[0])
+		require.NoError(t, sut.SaveTwoFactor(ctx, f, hashes))
+
+		err := sut.UseTimeStep(ctx, td.Users[0], 10)
+
+		require.NoError(t, err)
+		err = sut.UseTimeStep(ctx, td.Users[0], 10)
+		require.ErrorIs(t, err, ErrTimeStepUsed)
+		err = sut.UseTimeStep(ctx, td.Users[0], 9)
+		require.ErrorIs(t, err, ErrTimeStepUsed)
+		err = sut.UseTimeStep(ctx, td.Users[0], 11)
+		require.NoError(t, err)
+		got, err := sut.GetTwoFactor(ctx, td.Users[0])
+		require.NoError(t, err)
+		assert.Equal(t, int64(11), got.LastTimeStep)
+	})
+	t.Run("use time step of two factor that does not exist", func(t *testing.T) {
+		sut, tearDown, td := c.NewTwoFactorRepository()
+		t.Cleanup(tearDown)
+
+		err := sut.UseTimeStep(context.Background(), td.Users[0], 10)
+
+		require.ErrorIs(t, err, ErrTimeStepUsed)
+	})
+	t.Run("use recovery code", func(t *testing.T) {
+		sut, tearDown, td := c.NewTwoFactorRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		f, hashes := newTwoFactor(t, td.Users[0])
+		require.NoError(t, sut.SaveTwoFactor(ctx, f, hashes))
+
+		err := sut.UseRecoveryCode(ctx, td.Users[0], hashes[0])
+
+		require.NoError(t, err)
+		err = sut.UseRecoveryCode(ctx, td.Users[0], hashes[0])
+		require.ErrorIs(t, err, ErrRecoveryCodeNotFound)
+		err = sut.UseRecoveryCode(ctx, td.Users[0], hashes[1])
+		require.NoError(t, err)
+	})
+	t.Run("use recovery code of another user", func(t *testing.T) {
+		sut, tearDown, td := c.NewTwoFactorRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		f, hashes := newTwoFactor(t, td.Users[0])
+		require.NoError(t, sut.SaveTwoFactor(ctx, f, hashes))
+
+		err := sut.UseRecoveryCode(ctx, td.Users[1], hashes[0])
+
+		require.ErrorIs(t, err, ErrRecoveryCodeNotFound)
+	})
+}
+
+func newTwoFactor(t *testing.T, userID uuid.UUID) (*model.TwoFactor, [][]byte) {
+	t.Helper()
+
+	f, _, hashes, err := model.NewTwoFactor(userID, "user@email.com")
+	require.NoError(t, err)
+	return f, hashes
+}
@@ -0,0 +1,17 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// UserDataShredderMock is the shredder of the tests of the auth service and its handlers.
+type UserDataShredderMock struct {
+	DeleteUserDataFunc func(ctx context.Context, userID uuid.UUID) (func(ctx context.Context) error, error)
+}
+
+func (m *UserDataShredderMock) DeleteUserData(ctx context.Context, userID uuid.UUID) (func(ctx context.Context) error,
+	error) {
+	return m.DeleteUserDataFunc(ctx, userID)
+}
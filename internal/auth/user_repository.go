@@ -18,4 +18,8 @@ var (
 type UserRepository interface {
 	Register(ctx context.Context, user *model.User) (uuid.UUID, error)
 	FindByEmail(ctx context.Context, email string) (*model.User, error)
+	FindByID(ctx context.Context, userID uuid.UUID) (*model.User, error)
+	// ChangePassword replaces the password hash of the user.
+	ChangePassword(ctx context.Context, userID uuid.UUID, password string) error
+	Delete(ctx context.Context, userID uuid.UUID) error
 }
@@ -91,4 +91,105 @@ func (c UserRepositoryContract) Test(t *testing.T) {
 			require.ErrorIs(t, err, ErrUserIsNotRegistered)
 		})
 	})
+
+	t.Run("find user by id", func(t *testing.T) {
+		t.Run("find existing user", func(t *testing.T) {
+			sut, tearDown := c.NewUserRepository()
+			t.Cleanup(tearDown)
+			user := &model.User{
+				Email:    "user@email.com",
+				Password: "123",
+			}
+			ctx := context.Background()
+			userID, err := sut.Register(ctx, user)
+			require.NoError(t, err)
+
+			got, err := sut.FindByID(ctx, userID)
+
+			require.NoError(t, err)
+			assert.Equal(t, userID, got.ID)
+			assert.Equal(t, user.Email, got.Email)
+		})
+		t.Run("find user that does not exist", func(t *testing.T) {
+			sut, tearDown := c.NewUserRepository()
+			t.Cleanup(tearDown)
+
+			_, err := sut.FindByID(context.Background(), uuid.New())
+
+			require.ErrorIs(t, err, ErrUserIsNotRegistered)
+		})
+	})
+
+	t.Run("change password", func(t *testing.T) {
+		t.Run("change password of registered user", func(t *testing.T) {
+			sut, tearDown := c.NewUserRepository()
+			t.Cleanup(tearDown)
+			user := &model.User{
+				Email:    "user@email.com",
+				Password: "123",
+			}
+			ctx := context.Background()
+			userID, err := sut.Register(ctx, user)
+			require.NoError(t, err)
+
+			err = sut.ChangePassword(ctx, userID, "456")
+
+			require.NoError(t, err)
+			got, err := sut.FindByEmail(ctx, user.Email)
+			require.NoError(t, err)
+			assert.Equal(t, "456", got.Password)
+		})
+		t.Run("change password to empty", func(t *testing.T) {
+			sut, tearDown := c.NewUserRepository()
+			t.Cleanup(tearDown)
+			user := &model.User{
+				Email:    "user@email.com",
+				Password: "123",
+			}
+			ctx := context.Background()
+			userID, err := sut.Register(ctx, user)
+			require.NoError(t, err)
+
+			err = sut.ChangePassword(ctx, userID, "")
+
+			require.ErrorIs(t, err, ErrUserPasswordIsEmpty)
+		})
+		t.Run("change password of user that does not exist", func(t *testing.T) {
+			sut, tearDown := c.NewUserRepository()
+			t.Cleanup(tearDown)
+
+			err := sut.ChangePassword(context.Background(), uuid.New(), "456")
+
+			require.ErrorIs(t, err, ErrUserIsNotRegistered)
+		})
+	})
+
+	t.Run("delete user", func(t *testing.T) {
+		t.Run("delete registered user", func(t *testing.T) {
+			sut, tearDown := c.NewUserRepository()
+			t.Cleanup(tearDown)
+			user := &model.User{
+				Email:    "user@email.com",
+				Password: "123",
+			}
+			ctx := context.Background()
+			userID, err := sut.Register(ctx, user)
+			require.NoError(t, err)
+
+			err = sut.Delete(ctx, userID)
+
+			require.NoError(t, err)
+			_, err = sut.FindByEmail(ctx, user.Email)
+			require.ErrorIs(t, err, ErrUserIsNotRegistered)
+		})
+		t.Run("delete user that does not exist", func(t *testing.T) {
+			sut, tearDown := c.NewUserRepository()
+			t.Cleanup(tearDown)
+			ctx := context.Background()
+
+			err := sut.Delete(ctx, uuid.New())
+
+			require.ErrorIs(t, err, ErrUserIsNotRegistered)
+		})
+	})
 }
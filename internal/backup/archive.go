@@ -0,0 +1,345 @@
+package backup
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// Archive is a tar file encrypted with age. The first file of the archive is the manifest
+// that holds the format version and checksums of the files that follow it. The blobs
+// referenced by the secrets follow the snapshot files in the blobs directory.
+const (
+	FormatVersion = 1
+
+	manifestFile      = "manifest.json"
+	usersFile         = "users.json"
+	keysFile          = "keys.json"
+	secretsFile       = "secrets.json"
+	orgsFile          = "organizations.json"
+	membersFile       = "members.json"
+	vaultsFile        = "vaults.json"
+	twoFactorsFile    = "two_factors.json"
+	recoveryCodesFile = "recovery_codes.json"
+	accessTokensFile  = "access_tokens.json"
+	auditEventsFile   = "audit_events.json"
+	blobsDir          = "blobs/"
+
+	fileMode = 0o600
+)
+
+var (
+	ErrUnsupportedVersion = errors.New("unsupported backup format version")
+	ErrCorruptedArchive   = errors.New("backup archive is corrupted")
+)
+
+type manifest struct {
+	CreatedAt time.Time   `json:"created_at"`
+	Files     []fileEntry `json:"files"`
+	Version   int         `json:"version"`
+}
+
+type fileEntry struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// WriteArchive writes the snapshot and the blobs referenced by its secrets to the archive
+// encrypted to the recipient. The blob store may be nil if no secret references a blob.
+func WriteArchive(ctx context.Context, w io.Writer, s *Snapshot, blobs BlobStore, recipient age.Recipient) error {
+	const op = "write archive"
+
+	files, err := marshalFiles(s)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	m := manifest{
+		Version:   FormatVersion,
+		CreatedAt: time.Now().UTC(),
+	}
+	for _, f := range files {
+		m.Files = append(m.Files, newFileEntry(f.name, f.content))
+	}
+
+	// blobs are read twice, for the manifest and for the archive itself,
+	// so that only one of them is kept in memory at a time
+	blobIDs := referencedBlobs(s)
+	if len(blobIDs) != 0 && blobs == nil {
+		return errors.Wrap(ErrBlobStoreNotSet, op)
+	}
+	for _, blobID := range blobIDs {
+		data, err := blobs.GetBlob(ctx, blobID)
+		if err != nil {
+			return errors.Wrapf(err, "%s: blob %s", op, blobID)
+		}
+		m.Files = append(m.Files, newFileEntry(blobFile(blobID), data))
+	}
+	blobEntries := m.Files[len(files):]
+
+	content, err := json.Marshal(m)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	files = append([]file{{manifestFile, content}}, files...)
+
+	encrypted, err := age.Encrypt(w, recipient)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	tw := tar.NewWriter(encrypted)
+	for _, f := range files {
+		err = writeFile(tw, f, m.CreatedAt)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+	for i, blobID := range blobIDs {
+		data, err := blobs.GetBlob(ctx, blobID)
+		if err != nil {
+			return errors.Wrapf(err, "%s: blob %s", op, blobID)
+		}
+		f := file{blobFile(blobID), data}
+		if newFileEntry(f.name, f.content) != blobEntries[i] {
+			return errors.Errorf("%s: blob %s changed during backup", op, blobID)
+		}
+		err = writeFile(tw, f, m.CreatedAt)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	if err = tw.Close(); err != nil {
+		return errors.Wrap(err, op)
+	}
+	if err = encrypted.Close(); err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// ReadArchive reads the snapshot from the archive decrypted with one of the identities
+// and puts the blobs of the archive to the blob store. The archive is rejected if any
+// of its files does not match the manifest. The blob store may be nil if the archive
+// has no blobs. The blobs read before the archive is rejected are left in the store.
+func ReadArchive(ctx context.Context, r io.Reader, blobs BlobStore, identities ...age.Identity) (*Snapshot, error) {
+	const op = "read archive"
+
+	decrypted, err := age.Decrypt(r, identities...)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	tr := tar.NewReader(decrypted)
+	content, err := readFile(tr, manifestFile)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	var m manifest
+	if err = json.Unmarshal(content, &m); err != nil {
+		return nil, errors.Wrap(ErrCorruptedArchive, op)
+	}
+	if m.Version != FormatVersion {
+		return nil, errors.Wrapf(ErrUnsupportedVersion, "%s: version %d", op, m.Version)
+	}
+
+	files := make(map[string][]byte, len(m.Files))
+	for _, entry := range m.Files {
+		content, err := readFile(tr, entry.Name)
+		if err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+		if newFileEntry(entry.Name, content) != entry {
+			return nil, errors.Wrapf(ErrCorruptedArchive, "%s: checksum mismatch of %s", op, entry.Name)
+		}
+		if strings.HasPrefix(entry.Name, blobsDir) {
+			if err = putBlob(ctx, blobs, entry.Name, content); err != nil {
+				return nil, errors.Wrap(err, op)
+			}
+			continue
+		}
+		files[entry.Name] = content
+	}
+	if _, err = tr.Next(); !errors.Is(err, io.EOF) {
+		return nil, errors.Wrapf(ErrCorruptedArchive, "%s: unexpected file", op)
+	}
+
+	s, err := unmarshalFiles(files)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return s, nil
+}
+
+type file struct {
+	name    string
+	content []byte
+}
+
+func marshalFiles(s *Snapshot) ([]file, error) {
+	const op = "marshal files"
+
+	data := []struct {
+		v    any
+		name string
+	}{
+		{s.Users, usersFile},
+		{s.Keys, keysFile},
+		{s.Secrets, secretsFile},
+		{s.Organizations, orgsFile},
+		{s.Members, membersFile},
+		{s.Vaults, vaultsFile},
+		{s.TwoFactors, twoFactorsFile},
+		{s.RecoveryCodes, recoveryCodesFile},
+		{s.AccessTokens, accessTokensFile},
+		{s.AuditEvents, auditEventsFile},
+	}
+
+	files := make([]file, len(data))
+	for i, d := range data {
+		content, err := json.Marshal(d.v)
+		if err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+		files[i] = file{d.name, content}
+	}
+
+	return files, nil
+}
+
+func unmarshalFiles(files map[string][]byte) (*Snapshot, error) {
+	const op = "unmarshal files"
+
+	s := &Snapshot{}
+	data := []struct {
+		v        any
+		name     string
+		optional bool
+	}{
+		{&s.Users, usersFile, false},
+		{&s.Keys, keysFile, false},
+		{&s.Secrets, secretsFile, false},
+		// archives written before organizations were introduced have no files of them
+		{&s.Organizations, orgsFile, true},
+		{&s.Members, membersFile, true},
+		{&s.Vaults, vaultsFile, true},
+		// as well as archives written before second factors and access tokens were backed up
+		{&s.TwoFactors, twoFactorsFile, true},
+		{&s.RecoveryCodes, recoveryCodesFile, true},
+		{&s.AccessTokens, accessTokensFile, true},
+		{&s.AuditEvents, auditEventsFile, true},
+	}
+
+	for _, d := range data {
+		content, ok := files[d.name]
+		if !ok && d.optional {
+			continue
+		}
+		if !ok {
+			return nil, errors.Wrapf(ErrCorruptedArchive, "%s: %s is missing", op, d.name)
+		}
+		if err := json.Unmarshal(content, d.v); err != nil {
+			return nil, errors.Wrap(ErrCorruptedArchive, op)
+		}
+	}
+
+	return s, nil
+}
+
+func referencedBlobs(s *Snapshot) []uuid.UUID {
+	var blobIDs []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, secret := range s.Secrets {
+		if secret.BlobID == nil || seen[*secret.BlobID] {
+			continue
+		}
+		seen[*secret.BlobID] = true
+		blobIDs = append(blobIDs, *secret.BlobID)
+	}
+	return blobIDs
+}
+
+func blobFile(blobID uuid.UUID) string {
+	return blobsDir + blobID.String()
+}
+
+func putBlob(ctx context.Context, blobs BlobStore, name string, data []byte) error {
+	const op = "put blob"
+
+	blobID, err := uuid.Parse(strings.TrimPrefix(name, blobsDir))
+	if err != nil {
+		return errors.Wrapf(ErrCorruptedArchive, "%s: invalid name %s", op, name)
+	}
+	if blobs == nil {
+		return errors.Wrap(ErrBlobStoreNotSet, op)
+	}
+	if err = blobs.PutBlob(ctx, blobID, data); err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func newFileEntry(name string, content []byte) fileEntry {
+	sum := sha256.Sum256(content)
+	return fileEntry{
+		Name:   name,
+		Size:   int64(len(content)),
+		SHA256: hex.EncodeToString(sum[:]),
+	}
+}
+
+func writeFile(tw *tar.Writer, f file, modTime time.Time) error {
+	const op = "write file"
+
+	hdr := &tar.Header{
+		Name:    f.name,
+		Mode:    fileMode,
+		Size:    int64(len(f.content)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return errors.Wrap(err, op)
+	}
+	if _, err := tw.Write(f.content); err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func readFile(tr *tar.Reader, name string) ([]byte, error) {
+	const op = "read file"
+
+	hdr, err := tr.Next()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.Wrapf(ErrCorruptedArchive, "%s: %s is missing", op, name)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	if hdr.Name != name {
+		return nil, errors.Wrapf(ErrCorruptedArchive, "%s: unexpected file %s", op, hdr.Name)
+	}
+
+	content, err := io.ReadAll(tr)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return content, nil
+}
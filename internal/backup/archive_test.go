@@ -0,0 +1,237 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchive(t *testing.T) {
+	t.Run("read written archive", func(t *testing.T) {
+		ctx := context.Background()
+		identity := newIdentity(t)
+		want := newSnapshot()
+		blobs := newBlobStoreStub(want)
+		var buf bytes.Buffer
+		err := WriteArchive(ctx, &buf, want, blobs, identity.Recipient())
+		require.NoError(t, err)
+		restored := blobStoreStub{}
+
+		got, err := ReadArchive(ctx, &buf, restored, identity)
+
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.Equal(t, blobs, restored)
+	})
+	t.Run("archive is encrypted", func(t *testing.T) {
+		identity := newIdentity(t)
+		snapshot := newSnapshot()
+		var buf bytes.Buffer
+		err := WriteArchive(context.Background(), &buf, snapshot, newBlobStoreStub(snapshot), identity.Recipient())
+		require.NoError(t, err)
+
+		assert.NotContains(t, buf.String(), "user@email.com")
+		assert.NotContains(t, buf.String(), "blob data")
+	})
+	t.Run("read archive with wrong identity", func(t *testing.T) {
+		ctx := context.Background()
+		snapshot := newSnapshot()
+		var buf bytes.Buffer
+		err := WriteArchive(ctx, &buf, snapshot, newBlobStoreStub(snapshot), newIdentity(t).Recipient())
+		require.NoError(t, err)
+
+		_, err = ReadArchive(ctx, &buf, blobStoreStub{}, newIdentity(t))
+
+		require.Error(t, err)
+	})
+	t.Run("write archive without blob store", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := WriteArchive(context.Background(), &buf, newSnapshot(), nil, newIdentity(t).Recipient())
+
+		require.ErrorIs(t, err, ErrBlobStoreNotSet)
+	})
+	t.Run("write archive with missing blob", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := WriteArchive(context.Background(), &buf, newSnapshot(), blobStoreStub{}, newIdentity(t).Recipient())
+
+		require.ErrorIs(t, err, errBlobNotFound)
+	})
+	t.Run("read archive with blobs without blob store", func(t *testing.T) {
+		ctx := context.Background()
+		identity := newIdentity(t)
+		snapshot := newSnapshot()
+		var buf bytes.Buffer
+		err := WriteArchive(ctx, &buf, snapshot, newBlobStoreStub(snapshot), identity.Recipient())
+		require.NoError(t, err)
+
+		_, err = ReadArchive(ctx, &buf, nil, identity)
+
+		require.ErrorIs(t, err, ErrBlobStoreNotSet)
+	})
+	t.Run("read archive with blob checksum mismatch", func(t *testing.T) {
+		identity := newIdentity(t)
+		files, err := marshalFiles(newSnapshot())
+		require.NoError(t, err)
+		blob := file{blobFile(uuid.New()), []byte("blob data")}
+		m := newManifest(append(files, blob))
+		blob.content = []byte("another blob data")
+		archive := writeRawArchive(t, identity.Recipient(), append([]file{manifestToFile(t, m)}, append(files, blob)...))
+		blobs := blobStoreStub{}
+
+		_, err = ReadArchive(context.Background(), archive, blobs, identity)
+
+		require.ErrorIs(t, err, ErrCorruptedArchive)
+		assert.Empty(t, blobs)
+	})
+	t.Run("read archive of unsupported version", func(t *testing.T) {
+		identity := newIdentity(t)
+		files, err := marshalFiles(newSnapshot())
+		require.NoError(t, err)
+		m := newManifest(files)
+		m.Version = FormatVersion + 1
+		archive := writeRawArchive(t, identity.Recipient(), append([]file{manifestToFile(t, m)}, files...))
+
+		_, err = ReadArchive(context.Background(), archive, nil, identity)
+
+		require.ErrorIs(t, err, ErrUnsupportedVersion)
+	})
+	t.Run("read archive with checksum mismatch", func(t *testing.T) {
+		identity := newIdentity(t)
+		files, err := marshalFiles(newSnapshot())
+		require.NoError(t, err)
+		m := newManifest(files)
+		files[0].content = []byte("[]")
+		archive := writeRawArchive(t, identity.Recipient(), append([]file{manifestToFile(t, m)}, files...))
+
+		_, err = ReadArchive(context.Background(), archive, nil, identity)
+
+		require.ErrorIs(t, err, ErrCorruptedArchive)
+	})
+	t.Run("read archive with missing file", func(t *testing.T) {
+		identity := newIdentity(t)
+		files, err := marshalFiles(newSnapshot())
+		require.NoError(t, err)
+		files = removeFile(files, secretsFile)
+		m := newManifest(files)
+		archive := writeRawArchive(t, identity.Recipient(), append([]file{manifestToFile(t, m)}, files...))
+
+		_, err = ReadArchive(context.Background(), archive, nil, identity)
+
+		require.ErrorIs(t, err, ErrCorruptedArchive)
+	})
+	t.Run("read archive written before organizations", func(t *testing.T) {
+		identity := newIdentity(t)
+		want := newSnapshot()
+		want.Organizations, want.Members, want.Vaults = nil, nil, nil
+		files, err := marshalFiles(want)
+		require.NoError(t, err)
+		files = removeFile(removeFile(removeFile(files, orgsFile), membersFile), vaultsFile)
+		m := newManifest(files)
+		archive := writeRawArchive(t, identity.Recipient(), append([]file{manifestToFile(t, m)}, files...))
+
+		got, err := ReadArchive(context.Background(), archive, nil, identity)
+
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+	t.Run("read archive with unexpected file", func(t *testing.T) {
+		identity := newIdentity(t)
+		files, err := marshalFiles(newSnapshot())
+		require.NoError(t, err)
+		m := newManifest(files)
+		files = append(files, file{"extra.json", []byte("{}")})
+		archive := writeRawArchive(t, identity.Recipient(), append([]file{manifestToFile(t, m)}, files...))
+
+		_, err = ReadArchive(context.Background(), archive, nil, identity)
+
+		require.ErrorIs(t, err, ErrCorruptedArchive)
+	})
+}
+
+var errBlobNotFound = errors.New("blob not found")
+
+type blobStoreStub map[uuid.UUID][]byte
+
+func newBlobStoreStub(s *Snapshot) blobStoreStub {
+	blobs := blobStoreStub{}
+	for _, secret := range s.Secrets {
+		if secret.BlobID != nil {
+			blobs[*secret.BlobID] = []byte("blob data " + secret.BlobID.String())
+		}
+	}
+	return blobs
+}
+
+func (s blobStoreStub) GetBlob(_ context.Context, blobID uuid.UUID) ([]byte, error) {
+	data, ok := s[blobID]
+	if !ok {
+		return nil, errBlobNotFound
+	}
+	return data, nil
+}
+
+func (s blobStoreStub) PutBlob(_ context.Context, blobID uuid.UUID, data []byte) error {
+	s[blobID] = data
+	return nil
+}
+
+func removeFile(files []file, name string) []file {
+	var rest []file
+	for _, f := range files {
+		if f.name != name {
+			rest = append(rest, f)
+		}
+	}
+	return rest
+}
+
+func newIdentity(t *testing.T) *age.X25519Identity {
+	t.Helper()
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	return identity
+}
+
+func newManifest(files []file) manifest {
+	m := manifest{Version: FormatVersion, CreatedAt: time.Now().UTC()}
+	for _, f := range files {
+		m.Files = append(m.Files, newFileEntry(f.name, f.content))
+	}
+	return m
+}
+
+func manifestToFile(t *testing.T, m manifest) file {
+	t.Helper()
+
+	content, err := json.Marshal(m)
+	require.NoError(t, err)
+	return file{manifestFile, content}
+}
+
+func writeRawArchive(t *testing.T, recipient age.Recipient, files []file) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	encrypted, err := age.Encrypt(&buf, recipient)
+	require.NoError(t, err)
+	tw := tar.NewWriter(encrypted)
+	for _, f := range files {
+		err = writeFile(tw, f, time.Now())
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, encrypted.Close())
+	return &buf
+}
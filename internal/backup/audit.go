@@ -0,0 +1,42 @@
+package backup
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/audit/model"
+)
+
+// VerifyAuditLog orders the events of the audit log by sequence number and checks that
+// they make the whole hash chain, so that a changed log is not restored.
+func VerifyAuditLog(events []AuditEvent) error {
+	const op = "verify audit log"
+
+	slices.SortFunc(events, func(a, b AuditEvent) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+
+	chain := make([]*model.Event, len(events))
+	for i, e := range events {
+		chain[i] = &model.Event{
+			Seq:       e.Seq,
+			PrevHash:  e.PrevHash,
+			Hash:      e.Hash,
+			UserID:    e.UserID,
+			Action:    model.Action(e.Action),
+			SecretID:  e.SecretID,
+			Time:      e.Time,
+			ClientIP:  e.ClientIP,
+			UserAgent: e.UserAgent,
+			Outcome:   model.Outcome(e.Outcome),
+		}
+	}
+
+	if err := model.VerifyLog(chain); err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
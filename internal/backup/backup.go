@@ -0,0 +1,45 @@
+package backup
+
+import (
+	"context"
+	"io"
+
+	"filippo.io/age"
+	"github.com/pkg/errors"
+)
+
+// Backup writes the content of the store and the blobs referenced by it to the archive
+// encrypted to the recipient.
+func Backup(ctx context.Context, store Store, blobs BlobStore, w io.Writer, recipient age.Recipient) error {
+	const op = "backup"
+
+	s, err := store.Export(ctx)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = WriteArchive(ctx, w, s, blobs, recipient)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// Restore loads the archive decrypted with one of the identities into the empty store.
+// The blobs of the archive are put to the blob store before the store is loaded.
+func Restore(ctx context.Context, store Store, blobs BlobStore, r io.Reader, identities ...age.Identity) error {
+	const op = "restore"
+
+	s, err := ReadArchive(ctx, r, blobs, identities...)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = store.Import(ctx, s)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package backup
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Snapshot is the content of the storage. Data keys, secrets and secrets of second factors are kept sealed
+// and only hashes of recovery codes and access tokens are kept, so the snapshot does not expose any secret
+// without the master key.
+type Snapshot struct {
+	Users         []User         `json:"users"`
+	Keys          []DataKey      `json:"keys"`
+	Secrets       []Secret       `json:"secrets"`
+	Organizations []Organization `json:"organizations"`
+	Members       []Member       `json:"members"`
+	Vaults        []Vault        `json:"vaults"`
+	TwoFactors    []TwoFactor    `json:"two_factors"`
+	RecoveryCodes []RecoveryCode `json:"recovery_codes"`
+	AccessTokens  []AccessToken  `json:"access_tokens"`
+	AuditEvents   []AuditEvent   `json:"audit_events"`
+}
+
+type User struct {
+	Email    string    `json:"email"`
+	Password string    `json:"password"`
+	ID       uuid.UUID `json:"id"`
+}
+
+type DataKey struct {
+	UserID            *uuid.UUID `json:"user_id"` // keys created before they were bound to users have no user
+	Key               []byte     `json:"key"`
+	EncryptionsCount  int64      `json:"encryptions_count"`
+	EncryptedDataSize int64      `json:"encrypted_data_size"`
+	ID                uuid.UUID  `json:"id"`
+	IsDisposed        bool       `json:"is_disposed"`
+}
+
+// Secret keeps only the reference to the sealed data that is stored in the blob store.
+// The blobs themselves are written to the archive next to the snapshot.
+type Secret struct {
+	BlobID       *uuid.UUID `json:"blob_id,omitempty"`
+	Name         string     `json:"name"`
+	Data         []byte     `json:"data"`
+	BlobChecksum []byte     `json:"blob_checksum,omitempty"`
+	Revision     int64      `json:"revision"`
+	ID           uuid.UUID  `json:"id"`
+	UserID       uuid.UUID  `json:"user_id"`
+	KeyID        uuid.UUID  `json:"key_id"`
+}
+
+type Organization struct {
+	Name string    `json:"name"`
+	ID   uuid.UUID `json:"id"`
+}
+
+type Member struct {
+	Role   string    `json:"role"`
+	OrgID  uuid.UUID `json:"org_id"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// Vault is a team vault, its keys and secrets are owned by the vault ID.
+type Vault struct {
+	Name  string    `json:"name"`
+	ID    uuid.UUID `json:"id"`
+	OrgID uuid.UUID `json:"org_id"`
+}
+
+// TwoFactor is the second factor of the user, the secrets stored before they were sealed are kept as is.
+type TwoFactor struct {
+	Secret       string    `json:"secret"`
+	LastTimeStep int64     `json:"last_time_step"`
+	UserID       uuid.UUID `json:"user_id"`
+	Enabled      bool      `json:"enabled"`
+}
+
+type RecoveryCode struct {
+	Hash   []byte    `json:"hash"`
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// AccessToken is the personal access token, only the hash of the token is kept.
+type AccessToken struct {
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	VaultID   *uuid.UUID `json:"vault_id,omitempty"`
+	Name      string     `json:"name"`
+	Scope     string     `json:"scope"`
+	Hash      []byte     `json:"hash"`
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+}
+
+// AuditEvent is the event of the audit log kept with its sequence number and hashes,
+// so that the restored log is verified and chained further.
+type AuditEvent struct {
+	Time      time.Time `json:"time"`
+	Action    string    `json:"action"`
+	Outcome   string    `json:"outcome"`
+	ClientIP  string    `json:"client_ip"`
+	UserAgent string    `json:"user_agent"`
+	PrevHash  []byte    `json:"prev_hash,omitempty"`
+	Hash      []byte    `json:"hash"`
+	Seq       int64     `json:"seq"`
+	UserID    uuid.UUID `json:"user_id"`
+	SecretID  uuid.UUID `json:"secret_id"`
+}
@@ -0,0 +1,29 @@
+package backup
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrStorageNotEmpty = errors.New("storage is not empty")
+	ErrBlobStoreNotSet = errors.New("blob store is not set")
+)
+
+// Store gives access to the whole content of the storage.
+type Store interface {
+	// Export returns consistent snapshot of the storage.
+	Export(ctx context.Context) (*Snapshot, error)
+	// Import loads the snapshot into the storage. The storage must be empty.
+	Import(ctx context.Context, s *Snapshot) error
+}
+
+// BlobStore gives access to the sealed data of the secrets kept out of the storage.
+type BlobStore interface {
+	// GetBlob returns the blob by its ID.
+	GetBlob(ctx context.Context, blobID uuid.UUID) ([]byte, error)
+	// PutBlob stores the blob by its ID.
+	PutBlob(ctx context.Context, blobID uuid.UUID, data []byte) error
+}
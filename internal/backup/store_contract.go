@@ -0,0 +1,191 @@
+package backup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/audit/model"
+)
+
+type StoreContract struct {
+	NewStore func() (Store, func())
+}
+
+func (c StoreContract) Test(t *testing.T) {
+	t.Run("export imported snapshot", func(t *testing.T) {
+		sut, tearDown := c.NewStore()
+		t.Cleanup(tearDown)
+		want := newSnapshot()
+		ctx := context.Background()
+		err := sut.Import(ctx, want)
+		require.NoError(t, err)
+
+		got, err := sut.Export(ctx)
+
+		require.NoError(t, err)
+		assert.ElementsMatch(t, want.Users, got.Users)
+		assert.ElementsMatch(t, want.Keys, got.Keys)
+		assert.ElementsMatch(t, want.Secrets, got.Secrets)
+		assert.ElementsMatch(t, want.Organizations, got.Organizations)
+		assert.ElementsMatch(t, want.Members, got.Members)
+		assert.ElementsMatch(t, want.Vaults, got.Vaults)
+		assert.ElementsMatch(t, want.TwoFactors, got.TwoFactors)
+		assert.ElementsMatch(t, want.RecoveryCodes, got.RecoveryCodes)
+		assert.ElementsMatch(t, want.AccessTokens, got.AccessTokens)
+		assert.Equal(t, want.AuditEvents, got.AuditEvents)
+	})
+	t.Run("export empty storage", func(t *testing.T) {
+		sut, tearDown := c.NewStore()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+
+		got, err := sut.Export(ctx)
+
+		require.NoError(t, err)
+		assert.Empty(t, got.Users)
+		assert.Empty(t, got.Keys)
+		assert.Empty(t, got.Secrets)
+		assert.Empty(t, got.Organizations)
+		assert.Empty(t, got.TwoFactors)
+		assert.Empty(t, got.AccessTokens)
+		assert.Empty(t, got.AuditEvents)
+	})
+	t.Run("import into storage that is not empty", func(t *testing.T) {
+		sut, tearDown := c.NewStore()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		err := sut.Import(ctx, newSnapshot())
+		require.NoError(t, err)
+		another := &Snapshot{
+			Users: []User{{ID: uuid.New(), Email: "another@email.com", Password: "3"}},
+		}
+
+		err = sut.Import(ctx, another)
+
+		require.ErrorIs(t, err, ErrStorageNotEmpty)
+		got, err := sut.Export(ctx)
+		require.NoError(t, err)
+		assert.Len(t, got.Users, 2)
+	})
+	t.Run("import audit log in order", func(t *testing.T) {
+		sut, tearDown := c.NewStore()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		want := newSnapshot()
+		s := *want
+		s.AuditEvents = []AuditEvent{want.AuditEvents[1], want.AuditEvents[0]}
+
+		err := sut.Import(ctx, &s)
+
+		require.NoError(t, err)
+		got, err := sut.Export(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, want.AuditEvents, got.AuditEvents)
+	})
+	t.Run("import snapshot with changed audit log", func(t *testing.T) {
+		sut, tearDown := c.NewStore()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		s := newSnapshot()
+		s.AuditEvents[0].Outcome = string(model.OutcomeSuccess)
+
+		err := sut.Import(ctx, s)
+
+		require.ErrorIs(t, err, model.ErrBrokenChain)
+		got, err := sut.Export(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, got.Users)
+		assert.Empty(t, got.AuditEvents)
+	})
+}
+
+func newSnapshot() *Snapshot {
+	userID := uuid.New()
+	user2ID := uuid.New()
+	keyID := uuid.New()
+	legacyKeyID := uuid.New()
+	blobID := uuid.New()
+	orgID := uuid.New()
+	vaultID := uuid.New()
+	vaultKeyID := uuid.New()
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	expiresAt := createdAt.Add(24 * time.Hour)
+	return &Snapshot{
+		Users: []User{
+			{ID: userID, Email: "user@email.com", Password: "1"},
+			{ID: user2ID, Email: "user2@email.com", Password: "2"},
+		},
+		Keys: []DataKey{
+			{ID: legacyKeyID, Key: []byte("legacy key"), EncryptionsCount: 3, EncryptedDataSize: 30, IsDisposed: true},
+			{ID: keyID, UserID: &userID, Key: []byte("key"), EncryptionsCount: 2, EncryptedDataSize: 20},
+			{ID: vaultKeyID, UserID: &vaultID, Key: []byte("vault key"), EncryptionsCount: 1, EncryptedDataSize: 10},
+		},
+		Secrets: []Secret{
+			{ID: uuid.New(), UserID: userID, KeyID: keyID, Name: "secret", Data: []byte("data"), Revision: 3},
+			{ID: uuid.New(), UserID: user2ID, KeyID: legacyKeyID, Data: []byte("data2"), Revision: 1},
+			{ID: uuid.New(), UserID: userID, KeyID: keyID, BlobID: &blobID, BlobChecksum: []byte("checksum"), Revision: 2},
+			{ID: uuid.New(), UserID: vaultID, KeyID: vaultKeyID, Name: "shared", Data: []byte("data3"), Revision: 1},
+		},
+		Organizations: []Organization{
+			{ID: orgID, Name: "team"},
+		},
+		Members: []Member{
+			{OrgID: orgID, UserID: userID, Role: "owner"},
+			{OrgID: orgID, UserID: user2ID, Role: "viewer"},
+		},
+		Vaults: []Vault{
+			{ID: vaultID, OrgID: orgID, Name: "servers"},
+		},
+		TwoFactors: []TwoFactor{
+			{UserID: userID, Secret: "sealed:secret", Enabled: true, LastTimeStep: 57000000},
+		},
+		RecoveryCodes: []RecoveryCode{
+			{UserID: userID, Hash: []byte("hash1")},
+			{UserID: userID, Hash: []byte("hash2")},
+		},
+		AccessTokens: []AccessToken{
+			{ID: uuid.New(), UserID: userID, Name: "ci", Hash: []byte("token1"), Scope: "read", CreatedAt: createdAt},
+			{
+				ID: uuid.New(), UserID: user2ID, Name: "deploy", Hash: []byte("token2"), Scope: "read_write",
+				VaultID: &vaultID, CreatedAt: createdAt, ExpiresAt: &expiresAt,
+			},
+		},
+		AuditEvents: newAuditEvents(
+			&model.Event{Action: model.ActionLogin, Outcome: model.OutcomeFailure, Time: createdAt},
+			&model.Event{
+				UserID: userID, Action: model.ActionGetSecret, SecretID: uuid.New(), Outcome: model.OutcomeSuccess,
+				ClientIP: "192.0.2.1", UserAgent: "goph-keeper", Time: createdAt.Add(time.Minute),
+			},
+		),
+	}
+}
+
+func newAuditEvents(events ...*model.Event) []AuditEvent {
+	chain := make([]AuditEvent, len(events))
+	var (
+		prevSeq  int64
+		prevHash []byte
+	)
+	for i, e := range events {
+		e.Chain(prevSeq, prevHash)
+		prevSeq, prevHash = e.Seq, e.Hash
+		chain[i] = AuditEvent{
+			Seq:       e.Seq,
+			PrevHash:  e.PrevHash,
+			Hash:      e.Hash,
+			UserID:    e.UserID,
+			Action:    string(e.Action),
+			SecretID:  e.SecretID,
+			Time:      e.Time,
+			ClientIP:  e.ClientIP,
+			UserAgent: e.UserAgent,
+			Outcome:   string(e.Outcome),
+		}
+	}
+	return chain
+}
@@ -8,10 +8,21 @@ import (
 
 const (
 	serverAddress = "serveraddress"
+	cacheDir      = "cachedir"
+	certFile      = "certfile"
+	keyFile       = "keyfile"
+	caFile        = "cafile"
 )
 
 type Config struct {
 	ServerAddress string
+	// CacheDir is the directory of the encrypted local caches of the users.
+	CacheDir string
+	// CertFile and KeyFile are the client certificate presented to the server with mutual TLS.
+	CertFile string
+	KeyFile  string
+	// CAFile is the certificate the server certificate is verified with, it is not verified if the file is not set.
+	CAFile string
 }
 
 type ConfigOption func(*viper.Viper) error
@@ -60,5 +71,9 @@ func FromArgs(args []string) ConfigOption {
 func setupFlagSet() *pflag.FlagSet {
 	flagSet := pflag.NewFlagSet("", pflag.ContinueOnError)
 	flagSet.StringP(serverAddress, "s", "", "server address")
+	flagSet.StringP(cacheDir, "c", "", "directory of the local cache")
+	flagSet.String(certFile, "", "client certificate file for mutual TLS")
+	flagSet.String(keyFile, "", "client certificate key file for mutual TLS")
+	flagSet.String(caFile, "", "CA certificate file the server certificate is verified with")
 	return flagSet
 }
@@ -49,6 +49,42 @@ func TestFromArgs(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, want, got)
 	})
+	t.Run("cache dir", func(t *testing.T) {
+		args := []string{
+			"app",
+			"-c",
+			"/tmp/cache",
+		}
+		want := &Config{
+			CacheDir: "/tmp/cache",
+		}
+
+		got, err := NewConfig(FromArgs(args))
+
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+	t.Run("client certificate", func(t *testing.T) {
+		args := []string{
+			"app",
+			"--certfile",
+			"ci.crt",
+			"--keyfile",
+			"ci.key",
+			"--cafile",
+			"servercert.crt",
+		}
+		want := &Config{
+			CertFile: "ci.crt",
+			KeyFile:  "ci.key",
+			CAFile:   "servercert.crt",
+		}
+
+		got, err := NewConfig(FromArgs(args))
+
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
 	t.Run("failed to parse flags", func(t *testing.T) {
 		args := []string{
 			"app",
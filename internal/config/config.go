@@ -14,36 +14,147 @@ const (
 	serverCertFile = "server.certfile"
 	serverKeyFile  = "server.keyfile"
 	vaultMasterKey = "vault.masterkey"
+	vaultKeyPath   = "vault.keypath"
+	storageDriver  = "storage.driver"
+	noMigrate      = "no-migrate"
+	blobThreshold  = "blob.threshold"
+	jwtAlg         = "jwtauth.alg"
+	jwtSignKey     = "jwtauth.signkey"
+	tokenExpiry    = "jwtauth.tokenexpiryin"
+	refreshExpiry  = "jwtauth.refreshtokenexpiryin"
 
 	defaultServerAddress = "localhost:8080"
 	defaultCertFile      = "servercert.crt"
 	defaultKeyFile       = "servercert.key"
+	defaultStorageDriver = PostgresDriver
+	defaultVaultKeyPath  = "keys"
+	defaultBlobThreshold = 1024 * 1024 // 1MB
+	defaultJWTAlg        = HS256
+	defaultTokenExpiry   = 15 * time.Minute
+	defaultRefreshExpiry = 30 * 24 * time.Hour
+)
+
+// Storage drivers.
+const (
+	PostgresDriver = "postgres"
+	SQLiteDriver   = "sqlite"
+	MemoryDriver   = "memory"
+)
+
+// JWT signing algorithms.
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+// Blob store drivers.
+const (
+	LocalBlobDriver = "local"
+	S3BlobDriver    = "s3"
 )
 
 type Config struct {
 	Server   ServerConfig
-	Postgres PostgresConfig
+	Storage  StorageConfig
+	SQLite   SQLiteConfig
 	Vault    VaultConfig
 	JWTAuth  JWTAuthConfig
+	Blob     BlobConfig
+	Postgres PostgresConfig
+	// NoMigrate disables migrations at startup, so that they are applied
+	// explicitly with migrate command.
+	NoMigrate bool `mapstructure:"no-migrate"`
 }
 
 type ServerConfig struct {
 	Address  string
 	CertFile string
 	KeyFile  string
+	// AdminAddress is the address of the plain HTTP listener of operational endpoints, e.g. storage statistics.
+	// The listener is disabled if it is not set, it is meant to be bound to the loopback interface.
+	AdminAddress string
+	// ClientAuth enables mutual TLS, it is disabled if the client CA is not set.
+	ClientAuth ClientAuthConfig
+}
+
+// ClientAuthConfig sets how client certificates issued by the client CA are verified.
+// The certificate is verified if the client presents it unless it is required.
+type ClientAuthConfig struct {
+	CAFile     string
+	Identities []ClientIdentityConfig
+	Required   bool
+}
+
+// ClientIdentityConfig maps the common name of client certificates to the user they authenticate.
+// The certificate only identifies the device if the email is not set, its requests still need the access token.
+// Certificates of other subjects are rejected once any identity is configured.
+type ClientIdentityConfig struct {
+	Subject string
+	Email   string
+}
+
+type StorageConfig struct {
+	Driver string
 }
 
 type PostgresConfig struct {
-	DataSourceName string
+	DataSourceName   string
+	MaxConns         int32
+	MinConns         int32
+	MaxConnLifetime  time.Duration
+	MaxConnIdleTime  time.Duration
+	StatementTimeout time.Duration
 }
 
+type SQLiteConfig struct {
+	Path string
+}
+
+// JWTAuthConfig sets how access tokens are signed. HS256 tokens are signed with SignKey.
+// EdDSA and RS256 tokens are signed with the active key and verified with any of the keys,
+// so the signing key is rotated by adding the new key and making it active.
 type JWTAuthConfig struct {
-	SignKey       string
-	TokenExpiryIn time.Duration
+	SignKey   string
+	Alg       string
+	ActiveKey string
+	Keys      []JWTKeyConfig
+	// TokenExpiryIn is the lifetime of access tokens. A retired key is kept at least that long.
+	TokenExpiryIn        time.Duration
+	RefreshTokenExpiryIn time.Duration
+}
+
+// JWTKeyConfig is the key identified by kid in the header of the tokens it signs.
+// The file holds PEM encoded private key, the public key is enough for a retired key.
+type JWTKeyConfig struct {
+	ID   string
+	File string
 }
 
 type VaultConfig struct {
 	MasterKey string
+	// KeyPath is the directory of the keys of the vault owners. It is never backed up along with the storage,
+	// so that deleted accounts can not be recovered from backups, and it must be shared by all servers.
+	KeyPath string
+}
+
+// BlobConfig sets where sealed data of large secrets is stored.
+// Secrets are kept in the storage along with their data when the driver is not set.
+type BlobConfig struct {
+	Driver string
+	Path   string
+	S3     S3Config
+	// Threshold is the size of sealed data starting from which it is moved to the blob store.
+	Threshold int64
+}
+
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
 }
 
 type ConfigOption func(*viper.Viper) error
@@ -74,6 +185,12 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault(serverAddress, defaultServerAddress)
 	v.SetDefault(serverCertFile, defaultCertFile)
 	v.SetDefault(serverKeyFile, defaultKeyFile)
+	v.SetDefault(storageDriver, defaultStorageDriver)
+	v.SetDefault(vaultKeyPath, defaultVaultKeyPath)
+	v.SetDefault(blobThreshold, defaultBlobThreshold)
+	v.SetDefault(jwtAlg, defaultJWTAlg)
+	v.SetDefault(tokenExpiry, defaultTokenExpiry)
+	v.SetDefault(refreshExpiry, defaultRefreshExpiry)
 }
 
 func FromYaml(in io.Reader) ConfigOption {
@@ -109,10 +226,27 @@ func FromArgs(args []string) ConfigOption {
 	}
 }
 
+// Args returns arguments left after flags, i.e. the command and its arguments.
+func Args(args []string) ([]string, error) {
+	const op = "args"
+
+	flagSet := setupFlagSet()
+
+	err := flagSet.Parse(args)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return flagSet.Args(), nil
+}
+
 func setupFlagSet() *pflag.FlagSet {
 	flagSet := pflag.NewFlagSet("", pflag.ContinueOnError)
 	flagSet.StringP(configTag, "c", "", "config file path")
 	flagSet.StringP(serverAddress, "a", "", "server address")
 	flagSet.StringP(vaultMasterKey, "k", "", "vault master key")
+	flagSet.String(jwtSignKey, "", "HS256 key of access tokens")
+	flagSet.String(storageDriver, "", "storage driver (postgres, sqlite, memory)")
+	flagSet.Bool(noMigrate, false, "do not apply migrations at startup")
 	return flagSet
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -15,6 +16,20 @@ func TestNewConfig(t *testing.T) {
 			CertFile: defaultCertFile,
 			KeyFile:  defaultKeyFile,
 		},
+		Vault: VaultConfig{
+			KeyPath: defaultVaultKeyPath,
+		},
+		Storage: StorageConfig{
+			Driver: defaultStorageDriver,
+		},
+		Blob: BlobConfig{
+			Threshold: defaultBlobThreshold,
+		},
+		JWTAuth: JWTAuthConfig{
+			Alg:                  defaultJWTAlg,
+			TokenExpiryIn:        defaultTokenExpiry,
+			RefreshTokenExpiryIn: defaultRefreshExpiry,
+		},
 	}
 
 	got, err := New()
@@ -38,8 +53,20 @@ func TestFromArgs(t *testing.T) {
 				CertFile: defaultCertFile,
 				KeyFile:  defaultKeyFile,
 			},
+			Storage: StorageConfig{
+				Driver: defaultStorageDriver,
+			},
+			Blob: BlobConfig{
+				Threshold: defaultBlobThreshold,
+			},
+			JWTAuth: JWTAuthConfig{
+				Alg:                  defaultJWTAlg,
+				TokenExpiryIn:        defaultTokenExpiry,
+				RefreshTokenExpiryIn: defaultRefreshExpiry,
+			},
 			Vault: VaultConfig{
 				MasterKey: "1234",
+				KeyPath:   defaultVaultKeyPath,
 			},
 		}
 
@@ -55,6 +82,8 @@ func TestFromArgs(t *testing.T) {
 			"http://localhost:8080",
 			"--vault.masterkey",
 			"psw",
+			"--jwtauth.signkey",
+			"key",
 		}
 		want := &Config{
 			Server: ServerConfig{
@@ -62,9 +91,55 @@ func TestFromArgs(t *testing.T) {
 				CertFile: defaultCertFile,
 				KeyFile:  defaultKeyFile,
 			},
+			Storage: StorageConfig{
+				Driver: defaultStorageDriver,
+			},
+			Blob: BlobConfig{
+				Threshold: defaultBlobThreshold,
+			},
+			JWTAuth: JWTAuthConfig{
+				Alg:                  defaultJWTAlg,
+				SignKey:              "key",
+				TokenExpiryIn:        defaultTokenExpiry,
+				RefreshTokenExpiryIn: defaultRefreshExpiry,
+			},
 			Vault: VaultConfig{
 				MasterKey: "psw",
+				KeyPath:   defaultVaultKeyPath,
+			},
+		}
+
+		got, err := New(FromArgs(args))
+
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+	t.Run("disable migrations at startup", func(t *testing.T) {
+		args := []string{
+			"app",
+			"--no-migrate",
+		}
+		want := &Config{
+			Server: ServerConfig{
+				Address:  defaultServerAddress,
+				CertFile: defaultCertFile,
+				KeyFile:  defaultKeyFile,
+			},
+			Vault: VaultConfig{
+				KeyPath: defaultVaultKeyPath,
+			},
+			Storage: StorageConfig{
+				Driver: defaultStorageDriver,
+			},
+			Blob: BlobConfig{
+				Threshold: defaultBlobThreshold,
+			},
+			JWTAuth: JWTAuthConfig{
+				Alg:                  defaultJWTAlg,
+				TokenExpiryIn:        defaultTokenExpiry,
+				RefreshTokenExpiryIn: defaultRefreshExpiry,
 			},
+			NoMigrate: true,
 		}
 
 		got, err := New(FromArgs(args))
@@ -98,6 +173,20 @@ func TestFromYaml(t *testing.T) {
 				CertFile: defaultCertFile,
 				KeyFile:  defaultKeyFile,
 			},
+			Vault: VaultConfig{
+				KeyPath: defaultVaultKeyPath,
+			},
+			Storage: StorageConfig{
+				Driver: defaultStorageDriver,
+			},
+			Blob: BlobConfig{
+				Threshold: defaultBlobThreshold,
+			},
+			JWTAuth: JWTAuthConfig{
+				Alg:                  defaultJWTAlg,
+				TokenExpiryIn:        defaultTokenExpiry,
+				RefreshTokenExpiryIn: defaultRefreshExpiry,
+			},
 			Postgres: PostgresConfig{
 				DataSourceName: "postgres://user:psw/db",
 			},
@@ -108,6 +197,129 @@ func TestFromYaml(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, want, got)
 	})
+	t.Run("read sqlite storage config from yaml", func(t *testing.T) {
+		yml :=
+			`storage:
+  driver: sqlite
+sqlite:
+  path: /var/lib/goph-keeper/goph-keeper.db
+`
+		r := strings.NewReader(yml)
+		want := &Config{
+			Server: ServerConfig{
+				Address:  defaultServerAddress,
+				CertFile: defaultCertFile,
+				KeyFile:  defaultKeyFile,
+			},
+			Vault: VaultConfig{
+				KeyPath: defaultVaultKeyPath,
+			},
+			Storage: StorageConfig{
+				Driver: SQLiteDriver,
+			},
+			Blob: BlobConfig{
+				Threshold: defaultBlobThreshold,
+			},
+			JWTAuth: JWTAuthConfig{
+				Alg:                  defaultJWTAlg,
+				TokenExpiryIn:        defaultTokenExpiry,
+				RefreshTokenExpiryIn: defaultRefreshExpiry,
+			},
+			SQLite: SQLiteConfig{
+				Path: "/var/lib/goph-keeper/goph-keeper.db",
+			},
+		}
+
+		got, err := New(FromYaml(r))
+
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+	t.Run("read s3 blob store config from yaml", func(t *testing.T) {
+		yml :=
+			`blob:
+  driver: s3
+  threshold: 4096
+  s3:
+    endpoint: localhost:9000
+    bucket: secrets
+    accessKey: access
+    secretKey: secret
+    useSSL: true
+`
+		r := strings.NewReader(yml)
+		want := BlobConfig{
+			Driver:    S3BlobDriver,
+			Threshold: 4096,
+			S3: S3Config{
+				Endpoint:  "localhost:9000",
+				Bucket:    "secrets",
+				AccessKey: "access",
+				SecretKey: "secret",
+				UseSSL:    true,
+			},
+		}
+
+		got, err := New(FromYaml(r))
+
+		require.NoError(t, err)
+		assert.Equal(t, want, got.Blob)
+	})
+	t.Run("read jwt auth config from yaml", func(t *testing.T) {
+		yml :=
+			`jwtAuth:
+  alg: EdDSA
+  activeKey: "2"
+  keys:
+    - id: "2"
+      file: jwt-2.pem
+    - id: "1"
+      file: jwt-1.pem
+  tokenExpiryIn: 5m
+`
+		r := strings.NewReader(yml)
+		want := JWTAuthConfig{
+			Alg:       EdDSA,
+			ActiveKey: "2",
+			Keys: []JWTKeyConfig{
+				{ID: "2", File: "jwt-2.pem"},
+				{ID: "1", File: "jwt-1.pem"},
+			},
+			TokenExpiryIn:        5 * time.Minute,
+			RefreshTokenExpiryIn: defaultRefreshExpiry,
+		}
+
+		got, err := New(FromYaml(r))
+
+		require.NoError(t, err)
+		assert.Equal(t, want, got.JWTAuth)
+	})
+	t.Run("read client auth config from yaml", func(t *testing.T) {
+		yml :=
+			`server:
+  clientAuth:
+    caFile: clientca.crt
+    required: true
+    identities:
+      - subject: ci
+        email: user@email.com
+      - subject: laptop
+`
+		r := strings.NewReader(yml)
+		want := ClientAuthConfig{
+			CAFile:   "clientca.crt",
+			Required: true,
+			Identities: []ClientIdentityConfig{
+				{Subject: "ci", Email: "user@email.com"},
+				{Subject: "laptop"},
+			},
+		}
+
+		got, err := New(FromYaml(r))
+
+		require.NoError(t, err)
+		assert.Equal(t, want, got.Server.ClientAuth)
+	})
 	t.Run("invalid yaml file", func(t *testing.T) {
 		yml :=
 			`- postgres:
@@ -120,3 +332,33 @@ dataSourceName: postgres://user:psw/db
 		assert.Error(t, err)
 	})
 }
+
+func TestArgs(t *testing.T) {
+	t.Run("args left after flags", func(t *testing.T) {
+		args := []string{
+			"app",
+			"-c",
+			"config.yml",
+			"migrate",
+			"down",
+			"--no-migrate",
+			"2",
+		}
+		want := []string{"app", "migrate", "down", "2"}
+
+		got, err := Args(args)
+
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+	t.Run("failed to parse flags", func(t *testing.T) {
+		args := []string{
+			"app",
+			"--flag",
+		}
+
+		_, err := Args(args)
+
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,13 @@
+package org
+
+import "net/http"
+
+type OrganizationHandlers interface {
+	CreateOrganization() http.HandlerFunc
+	ListOrganizations() http.HandlerFunc
+	AddMember() http.HandlerFunc
+	RemoveMember() http.HandlerFunc
+	DeleteOrganization() http.HandlerFunc
+	AddVault() http.HandlerFunc
+	ListVaults() http.HandlerFunc
+}
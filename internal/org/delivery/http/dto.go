@@ -0,0 +1,48 @@
+package http
+
+type Organization struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	Role string `json:"role,omitempty"`
+}
+
+type Member struct {
+	Email string `json:"email,omitempty"`
+	Role  string `json:"role,omitempty"`
+}
+
+type Vault struct {
+	ID      string `json:"id,omitempty"`
+	Name    string `json:"name,omitempty"`
+	OrgID   string `json:"org_id,omitempty"`
+	OrgName string `json:"org_name,omitempty"`
+	Role    string `json:"role,omitempty"`
+}
+
+type CreateOrganizationRequest struct {
+	Organization Organization `json:"organization"`
+}
+
+type CreateOrganizationResponse struct {
+	Organization Organization `json:"organization"`
+}
+
+type ListOrganizationsResponse struct {
+	List []Organization `json:"list,omitempty"`
+}
+
+type AddMemberRequest struct {
+	Member Member `json:"member"`
+}
+
+type AddVaultRequest struct {
+	Vault Vault `json:"vault"`
+}
+
+type AddVaultResponse struct {
+	Vault Vault `json:"vault"`
+}
+
+type ListVaultsResponse struct {
+	List []Vault `json:"list,omitempty"`
+}
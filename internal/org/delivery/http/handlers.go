@@ -0,0 +1,317 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/org"
+	"github.com/nestjam/goph-keeper/internal/org/model"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+const (
+	contentTypeHeader = "Content-Type"
+	applicationJSON   = "application/json"
+	orgParam          = "org"
+	userParam         = "user"
+)
+
+type OrganizationHandlers struct {
+	service org.OrganizationService
+}
+
+func NewOrganizationHandlers(service org.OrganizationService) org.OrganizationHandlers {
+	return &OrganizationHandlers{service}
+}
+
+func (h *OrganizationHandlers) CreateOrganization() http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		userID, err := utils.UserFromContext(ctx)
+		if err != nil {
+			writeInternalServerError(w)
+			return
+		}
+
+		var req CreateOrganizationRequest
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			writeBadRequest(w)
+			return
+		}
+
+		orgID, err := h.service.CreateOrganization(ctx, req.Organization.Name, userID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		resp := &CreateOrganizationResponse{
+			Organization: Organization{
+				ID:   orgID.String(),
+				Name: req.Organization.Name,
+				Role: string(model.RoleOwner),
+			},
+		}
+		err = writeJSON(w, http.StatusCreated, resp)
+		if err != nil {
+			writeInternalServerError(w)
+			return
+		}
+	})
+}
+
+func (h *OrganizationHandlers) ListOrganizations() http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		userID, err := utils.UserFromContext(ctx)
+		if err != nil {
+			writeInternalServerError(w)
+			return
+		}
+
+		orgs, err := h.service.ListOrganizations(ctx, userID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		err = writeJSON(w, http.StatusOK, newListOrganizationsResponse(orgs))
+		if err != nil {
+			writeInternalServerError(w)
+			return
+		}
+	})
+}
+
+// AddMember adds the user with the email to the organization or changes the role of the member.
+func (h *OrganizationHandlers) AddMember() http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orgID, err := uuid.Parse(chi.URLParam(r, orgParam))
+		if err != nil {
+			writeBadRequest(w)
+			return
+		}
+
+		ctx := r.Context()
+		userID, err := utils.UserFromContext(ctx)
+		if err != nil {
+			writeInternalServerError(w)
+			return
+		}
+
+		var req AddMemberRequest
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			writeBadRequest(w)
+			return
+		}
+
+		err = h.service.AddMember(ctx, orgID, req.Member.Email, model.Role(req.Member.Role), userID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (h *OrganizationHandlers) RemoveMember() http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orgID, err := uuid.Parse(chi.URLParam(r, orgParam))
+		if err != nil {
+			writeBadRequest(w)
+			return
+		}
+		memberID, err := uuid.Parse(chi.URLParam(r, userParam))
+		if err != nil {
+			writeBadRequest(w)
+			return
+		}
+
+		ctx := r.Context()
+		userID, err := utils.UserFromContext(ctx)
+		if err != nil {
+			writeInternalServerError(w)
+			return
+		}
+
+		err = h.service.RemoveMember(ctx, orgID, memberID, userID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// DeleteOrganization deletes the organization of the owner along with the secrets of its vaults.
+func (h *OrganizationHandlers) DeleteOrganization() http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orgID, err := uuid.Parse(chi.URLParam(r, orgParam))
+		if err != nil {
+			writeBadRequest(w)
+			return
+		}
+
+		ctx := r.Context()
+		userID, err := utils.UserFromContext(ctx)
+		if err != nil {
+			writeInternalServerError(w)
+			return
+		}
+
+		err = h.service.DeleteOrganization(ctx, orgID, userID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (h *OrganizationHandlers) AddVault() http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orgID, err := uuid.Parse(chi.URLParam(r, orgParam))
+		if err != nil {
+			writeBadRequest(w)
+			return
+		}
+
+		ctx := r.Context()
+		userID, err := utils.UserFromContext(ctx)
+		if err != nil {
+			writeInternalServerError(w)
+			return
+		}
+
+		var req AddVaultRequest
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			writeBadRequest(w)
+			return
+		}
+
+		vaultID, err := h.service.AddVault(ctx, orgID, req.Vault.Name, userID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		resp := &AddVaultResponse{
+			Vault: Vault{
+				ID:    vaultID.String(),
+				Name:  req.Vault.Name,
+				OrgID: orgID.String(),
+				Role:  string(model.RoleOwner),
+			},
+		}
+		err = writeJSON(w, http.StatusCreated, resp)
+		if err != nil {
+			writeInternalServerError(w)
+			return
+		}
+	})
+}
+
+// ListVaults returns team vaults shared with the user.
+func (h *OrganizationHandlers) ListVaults() http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		userID, err := utils.UserFromContext(ctx)
+		if err != nil {
+			writeInternalServerError(w)
+			return
+		}
+
+		vaults, err := h.service.ListVaults(ctx, userID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		err = writeJSON(w, http.StatusOK, newListVaultsResponse(vaults))
+		if err != nil {
+			writeInternalServerError(w)
+			return
+		}
+	})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v any) error {
+	const op = "write json"
+
+	content, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	w.Header().Set(contentTypeHeader, applicationJSON)
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(content)
+	return nil
+}
+
+// writeError writes status of the error returned by the service.
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, org.ErrNameIsEmpty), errors.Is(err, org.ErrInvalidRole):
+		writeBadRequest(w)
+	case errors.Is(err, org.ErrPermissionDenied):
+		w.WriteHeader(http.StatusForbidden)
+	case errors.Is(err, org.ErrOrganizationNotFound), errors.Is(err, org.ErrMemberNotFound),
+		errors.Is(err, org.ErrUserNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		writeInternalServerError(w)
+	}
+}
+
+func writeBadRequest(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusBadRequest)
+}
+
+func writeInternalServerError(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+func newListOrganizationsResponse(orgs []*model.Organization) *ListOrganizationsResponse {
+	resp := &ListOrganizationsResponse{
+		List: make([]Organization, len(orgs)),
+	}
+
+	for i, o := range orgs {
+		resp.List[i] = Organization{
+			ID:   o.ID.String(),
+			Name: o.Name,
+			Role: string(o.Role),
+		}
+	}
+
+	return resp
+}
+
+func newListVaultsResponse(vaults []*model.Vault) *ListVaultsResponse {
+	resp := &ListVaultsResponse{
+		List: make([]Vault, len(vaults)),
+	}
+
+	for i, v := range vaults {
+		resp.List[i] = Vault{
+			ID:      v.ID.String(),
+			Name:    v.Name,
+			OrgID:   v.OrgID.String(),
+			OrgName: v.OrgName,
+			Role:    string(v.Role),
+		}
+	}
+
+	return resp
+}
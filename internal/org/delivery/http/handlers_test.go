@@ -0,0 +1,341 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	authModel "github.com/nestjam/goph-keeper/internal/auth/model"
+	authInmemory "github.com/nestjam/goph-keeper/internal/auth/repository/inmemory"
+	"github.com/nestjam/goph-keeper/internal/config"
+	"github.com/nestjam/goph-keeper/internal/org"
+	"github.com/nestjam/goph-keeper/internal/org/model"
+	"github.com/nestjam/goph-keeper/internal/org/repository/inmemory"
+	"github.com/nestjam/goph-keeper/internal/org/service"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+const memberEmail = "member@email.com"
+
+func TestCreateOrganization(t *testing.T) {
+	t.Run("create organization", func(t *testing.T) {
+		sut, svc, _ := newRouter(t)
+		userID := uuid.New()
+		r := newJSONRequest(t, http.MethodPost, "/orgs", CreateOrganizationRequest{Organization{Name: "team"}}, userID)
+		w := httptest.NewRecorder()
+
+		sut.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var resp CreateOrganizationResponse
+		decodeResponse(t, w, &resp)
+		orgs, err := svc.ListOrganizations(context.Background(), userID)
+		require.NoError(t, err)
+		require.Len(t, orgs, 1)
+		want := Organization{ID: orgs[0].ID.String(), Name: "team", Role: string(model.RoleOwner)}
+		assert.Equal(t, want, resp.Organization)
+	})
+	t.Run("name is empty", func(t *testing.T) {
+		sut, _, _ := newRouter(t)
+		r := newJSONRequest(t, http.MethodPost, "/orgs", CreateOrganizationRequest{}, uuid.New())
+		w := httptest.NewRecorder()
+
+		sut.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+	t.Run("user is not authenticated", func(t *testing.T) {
+		sut, _, _ := newRouter(t)
+		r := httptest.NewRequest(http.MethodGet, "/orgs", nil)
+		w := httptest.NewRecorder()
+
+		sut.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestListOrganizations(t *testing.T) {
+	sut, svc, _ := newRouter(t)
+	userID := uuid.New()
+	orgID, err := svc.CreateOrganization(context.Background(), "team", userID)
+	require.NoError(t, err)
+	r := newRequest(t, http.MethodGet, "/orgs", userID)
+	w := httptest.NewRecorder()
+
+	sut.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp ListOrganizationsResponse
+	decodeResponse(t, w, &resp)
+	want := []Organization{{ID: orgID.String(), Name: "team", Role: string(model.RoleOwner)}}
+	assert.Equal(t, want, resp.List)
+}
+
+func TestAddMember(t *testing.T) {
+	tests := []struct {
+		name  string
+		email string
+		role  model.Role
+		want  int
+	}{
+		{
+			name:  "add member",
+			email: memberEmail,
+			role:  model.RoleEditor,
+			want:  http.StatusNoContent,
+		},
+		{
+			name:  "invalid role",
+			email: memberEmail,
+			role:  model.Role("admin"),
+			want:  http.StatusBadRequest,
+		},
+		{
+			name:  "user is not registered",
+			email: "unknown@email.com",
+			role:  model.RoleViewer,
+			want:  http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sut, svc, _ := newRouter(t)
+			ownerID := uuid.New()
+			orgID, err := svc.CreateOrganization(context.Background(), "team", ownerID)
+			require.NoError(t, err)
+			req := AddMemberRequest{Member{Email: tt.email, Role: string(tt.role)}}
+			r := newJSONRequest(t, http.MethodPut, "/orgs/"+orgID.String()+"/members", req, ownerID)
+			w := httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+	t.Run("editor can not add members", func(t *testing.T) {
+		sut, svc, memberID := newRouter(t)
+		ctx := context.Background()
+		ownerID := uuid.New()
+		orgID, err := svc.CreateOrganization(ctx, "team", ownerID)
+		require.NoError(t, err)
+		err = svc.AddMember(ctx, orgID, memberEmail, model.RoleEditor, ownerID)
+		require.NoError(t, err)
+		req := AddMemberRequest{Member{Email: memberEmail, Role: string(model.RoleViewer)}}
+		r := newJSONRequest(t, http.MethodPut, "/orgs/"+orgID.String()+"/members", req, memberID)
+		w := httptest.NewRecorder()
+
+		sut.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestRemoveMember(t *testing.T) {
+	t.Run("remove member", func(t *testing.T) {
+		sut, svc, memberID := newRouter(t)
+		ctx := context.Background()
+		ownerID := uuid.New()
+		orgID, err := svc.CreateOrganization(ctx, "team", ownerID)
+		require.NoError(t, err)
+		err = svc.AddMember(ctx, orgID, memberEmail, model.RoleViewer, ownerID)
+		require.NoError(t, err)
+		r := newRequest(t, http.MethodDelete, "/orgs/"+orgID.String()+"/members/"+memberID.String(), ownerID)
+		w := httptest.NewRecorder()
+
+		sut.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		orgs, err := svc.ListOrganizations(ctx, memberID)
+		require.NoError(t, err)
+		assert.Empty(t, orgs)
+	})
+	t.Run("invalid member id", func(t *testing.T) {
+		sut, _, _ := newRouter(t)
+		r := newRequest(t, http.MethodDelete, "/orgs/"+uuid.NewString()+"/members/abc", uuid.New())
+		w := httptest.NewRecorder()
+
+		sut.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+	t.Run("organization not found", func(t *testing.T) {
+		sut, _, _ := newRouter(t)
+		path := "/orgs/" + uuid.NewString() + "/members/" + uuid.NewString()
+		r := newRequest(t, http.MethodDelete, path, uuid.New())
+		w := httptest.NewRecorder()
+
+		sut.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestDeleteOrganization(t *testing.T) {
+	t.Run("delete organization", func(t *testing.T) {
+		sut, svc, _ := newRouter(t)
+		ctx := context.Background()
+		ownerID := uuid.New()
+		orgID, err := svc.CreateOrganization(ctx, "team", ownerID)
+		require.NoError(t, err)
+		_, err = svc.AddVault(ctx, orgID, "servers", ownerID)
+		require.NoError(t, err)
+		r := newRequest(t, http.MethodDelete, "/orgs/"+orgID.String(), ownerID)
+		w := httptest.NewRecorder()
+
+		sut.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		orgs, err := svc.ListOrganizations(ctx, ownerID)
+		require.NoError(t, err)
+		assert.Empty(t, orgs)
+	})
+	t.Run("member can not delete organization", func(t *testing.T) {
+		sut, svc, memberID := newRouter(t)
+		ctx := context.Background()
+		ownerID := uuid.New()
+		orgID, err := svc.CreateOrganization(ctx, "team", ownerID)
+		require.NoError(t, err)
+		err = svc.AddMember(ctx, orgID, memberEmail, model.RoleEditor, ownerID)
+		require.NoError(t, err)
+		r := newRequest(t, http.MethodDelete, "/orgs/"+orgID.String(), memberID)
+		w := httptest.NewRecorder()
+
+		sut.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestAddVault(t *testing.T) {
+	t.Run("add vault", func(t *testing.T) {
+		sut, svc, _ := newRouter(t)
+		ctx := context.Background()
+		ownerID := uuid.New()
+		orgID, err := svc.CreateOrganization(ctx, "team", ownerID)
+		require.NoError(t, err)
+		r := newJSONRequest(t, http.MethodPost, "/orgs/"+orgID.String()+"/vaults", AddVaultRequest{Vault{Name: "servers"}},
+			ownerID)
+		w := httptest.NewRecorder()
+
+		sut.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var resp AddVaultResponse
+		decodeResponse(t, w, &resp)
+		vaults, err := svc.ListVaults(ctx, ownerID)
+		require.NoError(t, err)
+		require.Len(t, vaults, 1)
+		assert.Equal(t, vaults[0].ID.String(), resp.Vault.ID)
+	})
+	t.Run("invalid organization id", func(t *testing.T) {
+		sut, _, _ := newRouter(t)
+		r := newJSONRequest(t, http.MethodPost, "/orgs/abc/vaults", AddVaultRequest{Vault{Name: "servers"}}, uuid.New())
+		w := httptest.NewRecorder()
+
+		sut.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestListVaults(t *testing.T) {
+	sut, svc, memberID := newRouter(t)
+	ctx := context.Background()
+	ownerID := uuid.New()
+	orgID, err := svc.CreateOrganization(ctx, "team", ownerID)
+	require.NoError(t, err)
+	err = svc.AddMember(ctx, orgID, memberEmail, model.RoleViewer, ownerID)
+	require.NoError(t, err)
+	vaultID, err := svc.AddVault(ctx, orgID, "servers", ownerID)
+	require.NoError(t, err)
+	r := newRequest(t, http.MethodGet, "/vaults", memberID)
+	w := httptest.NewRecorder()
+
+	sut.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp ListVaultsResponse
+	decodeResponse(t, w, &resp)
+	want := []Vault{
+		{
+			ID:      vaultID.String(),
+			Name:    "servers",
+			OrgID:   orgID.String(),
+			OrgName: "team",
+			Role:    string(model.RoleViewer),
+		},
+	}
+	assert.Equal(t, want, resp.List)
+}
+
+// newRouter returns the router with organization routes, their service
+// and the registered user who is not member of any organization.
+func newRouter(t *testing.T) (*chi.Mux, org.OrganizationService, uuid.UUID) {
+	t.Helper()
+
+	users := authInmemory.NewUserRepository()
+	memberID, err := users.Register(context.Background(), &authModel.User{Email: memberEmail, Password: "secret"})
+	require.NoError(t, err)
+	svc := service.NewOrganizationService(inmemory.NewOrganizationRepository(), users,
+		&auth.UserDataShredderMock{
+			DeleteUserDataFunc: func(context.Context, uuid.UUID) (func(context.Context) error, error) {
+				return func(context.Context) error { return nil }, nil
+			},
+		})
+	r := chi.NewRouter()
+	MapOrganizationRoutes(r, NewOrganizationHandlers(svc), newConfig())
+	return r, svc, memberID
+}
+
+func newConfig() config.JWTAuthConfig {
+	return config.JWTAuthConfig{
+		SignKey:       "secret",
+		TokenExpiryIn: time.Minute,
+	}
+}
+
+func newRequest(t *testing.T, method, target string, userID uuid.UUID) *http.Request {
+	t.Helper()
+
+	r := httptest.NewRequest(method, target, nil)
+	setAuthCookie(t, r, userID)
+	return r
+}
+
+func newJSONRequest(t *testing.T, method, target string, v any, userID uuid.UUID) *http.Request {
+	t.Helper()
+
+	content, err := json.Marshal(v)
+	require.NoError(t, err)
+	r := httptest.NewRequest(method, target, bytes.NewReader(content))
+	r.Header.Set(contentTypeHeader, applicationJSON)
+	setAuthCookie(t, r, userID)
+	return r
+}
+
+func setAuthCookie(t *testing.T, r *http.Request, userID uuid.UUID) {
+	t.Helper()
+
+	cookie, err := utils.NewAuthCookieBaker(newConfig()).BakeCookie(userID, uuid.New())
+	require.NoError(t, err)
+	r.AddCookie(cookie)
+}
+
+func decodeResponse(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+
+	assert.Equal(t, applicationJSON, w.Header().Get(contentTypeHeader))
+	err := json.NewDecoder(w.Body).Decode(v)
+	require.NoError(t, err)
+}
@@ -0,0 +1,37 @@
+package http
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/nestjam/goph-keeper/internal/config"
+	"github.com/nestjam/goph-keeper/internal/org"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+func MapOrganizationRoutes(r chi.Router, h org.OrganizationHandlers, cfg config.JWTAuthConfig,
+	opts ...utils.AuthCookieBakerOption) {
+	const (
+		orgsPath   = "/orgs"
+		orgPattern = "/orgs/{" + orgParam + "}"
+	)
+
+	cookieBaker := utils.NewAuthCookieBaker(cfg, opts...)
+
+	r.Group(func(r chi.Router) {
+		r.Use(cookieBaker.Middlewares()...)
+
+		r.Get(orgsPath, h.ListOrganizations())
+		r.Delete(orgPattern, h.DeleteOrganization())
+		r.Delete(orgPattern+"/members/{"+userParam+"}", h.RemoveMember())
+		r.Get("/vaults", h.ListVaults())
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.AllowContentType(applicationJSON))
+		r.Use(cookieBaker.Middlewares()...)
+
+		r.Post(orgsPath, h.CreateOrganization())
+		r.Put(orgPattern+"/members", h.AddMember())
+		r.Post(orgPattern+"/vaults", h.AddVault())
+	})
+}
@@ -0,0 +1,47 @@
+package model
+
+import "github.com/google/uuid"
+
+// Role is a role of a member in an organization.
+type Role string
+
+const (
+	// RoleOwner manages members and vaults of the organization and changes secrets.
+	RoleOwner Role = "owner"
+	// RoleEditor reads and changes secrets of the organization vaults.
+	RoleEditor Role = "editor"
+	// RoleViewer reads secrets of the organization vaults.
+	RoleViewer Role = "viewer"
+)
+
+// IsValid reports whether the role is one of the known roles.
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleOwner, RoleEditor, RoleViewer:
+		return true
+	default:
+		return false
+	}
+}
+
+// CanWrite reports whether the member of the role changes secrets.
+func (r Role) CanWrite() bool {
+	return r == RoleOwner || r == RoleEditor
+}
+
+type Organization struct {
+	Name string
+	// Role is the role of the user the organization is listed for.
+	Role Role
+	ID   uuid.UUID
+}
+
+// Vault is a team vault owned by an organization.
+type Vault struct {
+	Name    string
+	OrgName string
+	// Role is the role of the user the vault is listed for.
+	Role  Role
+	ID    uuid.UUID
+	OrgID uuid.UUID
+}
@@ -0,0 +1,32 @@
+package org
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/org/model"
+)
+
+var (
+	ErrOrganizationNotFound = errors.New("organization not found")
+	ErrMemberNotFound       = errors.New("member not found")
+	ErrVaultNotFound        = errors.New("vault not found")
+)
+
+type OrganizationRepository interface {
+	// CreateOrganization creates the organization with the user as its owner.
+	CreateOrganization(ctx context.Context, org *model.Organization, ownerID uuid.UUID) (uuid.UUID, error)
+	ListOrganizations(ctx context.Context, userID uuid.UUID) ([]*model.Organization, error)
+	GetRole(ctx context.Context, orgID, userID uuid.UUID) (model.Role, error)
+	// SetMember adds the user to the organization or changes the role of the member.
+	SetMember(ctx context.Context, orgID, userID uuid.UUID, role model.Role) error
+	RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error
+	AddVault(ctx context.Context, vault *model.Vault) (uuid.UUID, error)
+	// ListVaults returns vaults of the organizations the user is member of.
+	ListVaults(ctx context.Context, userID uuid.UUID) ([]*model.Vault, error)
+	GetVault(ctx context.Context, vaultID uuid.UUID) (*model.Vault, error)
+	// DeleteOrganization deletes the organization along with its members and vaults.
+	DeleteOrganization(ctx context.Context, orgID uuid.UUID) error
+}
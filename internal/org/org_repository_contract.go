@@ -0,0 +1,184 @@
+package org
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/org/model"
+)
+
+type OrganizationTestData struct {
+	Users uuid.UUIDs
+}
+
+type OrganizationRepositoryContract struct {
+	NewOrganizationRepository func() (OrganizationRepository, func(), OrganizationTestData)
+}
+
+func (c OrganizationRepositoryContract) Test(t *testing.T) {
+	t.Run("create organization", func(t *testing.T) {
+		sut, tearDown, td := c.NewOrganizationRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		ownerID := td.Users[0]
+
+		orgID, err := sut.CreateOrganization(ctx, &model.Organization{Name: "team"}, ownerID)
+
+		require.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, orgID)
+		role, err := sut.GetRole(ctx, orgID, ownerID)
+		require.NoError(t, err)
+		assert.Equal(t, model.RoleOwner, role)
+	})
+	t.Run("list organizations of user", func(t *testing.T) {
+		sut, tearDown, td := c.NewOrganizationRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		userID := td.Users[0]
+		orgID, err := sut.CreateOrganization(ctx, &model.Organization{Name: "team"}, td.Users[1])
+		require.NoError(t, err)
+		err = sut.SetMember(ctx, orgID, userID, model.RoleViewer)
+		require.NoError(t, err)
+		_, err = sut.CreateOrganization(ctx, &model.Organization{Name: "another team"}, td.Users[1])
+		require.NoError(t, err)
+
+		got, err := sut.ListOrganizations(ctx, userID)
+
+		require.NoError(t, err)
+		want := []*model.Organization{{ID: orgID, Name: "team", Role: model.RoleViewer}}
+		assert.Equal(t, want, got)
+	})
+	t.Run("role of user who is not member", func(t *testing.T) {
+		sut, tearDown, td := c.NewOrganizationRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		orgID, err := sut.CreateOrganization(ctx, &model.Organization{Name: "team"}, td.Users[0])
+		require.NoError(t, err)
+
+		_, err = sut.GetRole(ctx, orgID, td.Users[1])
+
+		require.ErrorIs(t, err, ErrMemberNotFound)
+	})
+	t.Run("change role of member", func(t *testing.T) {
+		sut, tearDown, td := c.NewOrganizationRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		orgID, err := sut.CreateOrganization(ctx, &model.Organization{Name: "team"}, td.Users[0])
+		require.NoError(t, err)
+		err = sut.SetMember(ctx, orgID, td.Users[1], model.RoleViewer)
+		require.NoError(t, err)
+
+		err = sut.SetMember(ctx, orgID, td.Users[1], model.RoleEditor)
+
+		require.NoError(t, err)
+		role, err := sut.GetRole(ctx, orgID, td.Users[1])
+		require.NoError(t, err)
+		assert.Equal(t, model.RoleEditor, role)
+	})
+	t.Run("remove member", func(t *testing.T) {
+		sut, tearDown, td := c.NewOrganizationRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		orgID, err := sut.CreateOrganization(ctx, &model.Organization{Name: "team"}, td.Users[0])
+		require.NoError(t, err)
+		err = sut.SetMember(ctx, orgID, td.Users[1], model.RoleEditor)
+		require.NoError(t, err)
+
+		err = sut.RemoveMember(ctx, orgID, td.Users[1])
+
+		require.NoError(t, err)
+		_, err = sut.GetRole(ctx, orgID, td.Users[1])
+		require.ErrorIs(t, err, ErrMemberNotFound)
+	})
+	t.Run("remove user who is not member", func(t *testing.T) {
+		sut, tearDown, td := c.NewOrganizationRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		orgID, err := sut.CreateOrganization(ctx, &model.Organization{Name: "team"}, td.Users[0])
+		require.NoError(t, err)
+
+		err = sut.RemoveMember(ctx, orgID, td.Users[1])
+
+		require.ErrorIs(t, err, ErrMemberNotFound)
+	})
+	t.Run("add vault", func(t *testing.T) {
+		sut, tearDown, td := c.NewOrganizationRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		orgID, err := sut.CreateOrganization(ctx, &model.Organization{Name: "team"}, td.Users[0])
+		require.NoError(t, err)
+		vault := &model.Vault{OrgID: orgID, Name: "servers"}
+
+		vaultID, err := sut.AddVault(ctx, vault)
+
+		require.NoError(t, err)
+		got, err := sut.GetVault(ctx, vaultID)
+		require.NoError(t, err)
+		assert.Equal(t, &model.Vault{ID: vaultID, OrgID: orgID, OrgName: "team", Name: "servers"}, got)
+	})
+	t.Run("get vault that does not exist", func(t *testing.T) {
+		sut, tearDown, _ := c.NewOrganizationRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+
+		_, err := sut.GetVault(ctx, uuid.New())
+
+		require.ErrorIs(t, err, ErrVaultNotFound)
+	})
+	t.Run("list vaults of user", func(t *testing.T) {
+		sut, tearDown, td := c.NewOrganizationRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		userID := td.Users[0]
+		orgID, err := sut.CreateOrganization(ctx, &model.Organization{Name: "team"}, td.Users[1])
+		require.NoError(t, err)
+		err = sut.SetMember(ctx, orgID, userID, model.RoleEditor)
+		require.NoError(t, err)
+		vaultID, err := sut.AddVault(ctx, &model.Vault{OrgID: orgID, Name: "servers"})
+		require.NoError(t, err)
+		anotherOrgID, err := sut.CreateOrganization(ctx, &model.Organization{Name: "another team"}, td.Users[1])
+		require.NoError(t, err)
+		_, err = sut.AddVault(ctx, &model.Vault{OrgID: anotherOrgID, Name: "sites"})
+		require.NoError(t, err)
+
+		got, err := sut.ListVaults(ctx, userID)
+
+		require.NoError(t, err)
+		want := []*model.Vault{{ID: vaultID, OrgID: orgID, OrgName: "team", Name: "servers", Role: model.RoleEditor}}
+		assert.Equal(t, want, got)
+	})
+	t.Run("delete organization", func(t *testing.T) {
+		sut, tearDown, td := c.NewOrganizationRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		orgID, err := sut.CreateOrganization(ctx, &model.Organization{Name: "team"}, td.Users[0])
+		require.NoError(t, err)
+		err = sut.SetMember(ctx, orgID, td.Users[1], model.RoleViewer)
+		require.NoError(t, err)
+		vaultID, err := sut.AddVault(ctx, &model.Vault{OrgID: orgID, Name: "servers"})
+		require.NoError(t, err)
+
+		err = sut.DeleteOrganization(ctx, orgID)
+
+		require.NoError(t, err)
+		_, err = sut.GetVault(ctx, vaultID)
+		require.ErrorIs(t, err, ErrVaultNotFound)
+		_, err = sut.GetRole(ctx, orgID, td.Users[1])
+		require.ErrorIs(t, err, ErrMemberNotFound)
+		got, err := sut.ListOrganizations(ctx, td.Users[0])
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+	t.Run("delete organization that does not exist", func(t *testing.T) {
+		sut, tearDown, _ := c.NewOrganizationRepository()
+		t.Cleanup(tearDown)
+
+		err := sut.DeleteOrganization(context.Background(), uuid.New())
+
+		require.ErrorIs(t, err, ErrOrganizationNotFound)
+	})
+}
@@ -0,0 +1,36 @@
+package org
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/org/model"
+	"github.com/nestjam/goph-keeper/internal/vault"
+)
+
+var (
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrInvalidRole      = errors.New("invalid role")
+	ErrNameIsEmpty      = errors.New("name is empty")
+	ErrUserNotFound     = errors.New("user not found")
+)
+
+type OrganizationService interface {
+	vault.AccessPolicy
+	// UserDataShredder removes the user from the organizations. It fails with auth.ErrOrganizationOwner
+	// while the user owns an organization.
+	auth.UserDataShredder
+	CreateOrganization(ctx context.Context, name string, userID uuid.UUID) (uuid.UUID, error)
+	ListOrganizations(ctx context.Context, userID uuid.UUID) ([]*model.Organization, error)
+	// AddMember adds the registered user with the email to the organization or changes the role of the member.
+	AddMember(ctx context.Context, orgID uuid.UUID, email string, role model.Role, userID uuid.UUID) error
+	RemoveMember(ctx context.Context, orgID, memberID, userID uuid.UUID) error
+	AddVault(ctx context.Context, orgID uuid.UUID, name string, userID uuid.UUID) (uuid.UUID, error)
+	// ListVaults returns vaults shared with the user.
+	ListVaults(ctx context.Context, userID uuid.UUID) ([]*model.Vault, error)
+	// DeleteOrganization deletes the organization of the owner and shreds data of its vaults.
+	DeleteOrganization(ctx context.Context, orgID, userID uuid.UUID) error
+}
@@ -0,0 +1,165 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/org"
+	"github.com/nestjam/goph-keeper/internal/org/model"
+)
+
+type member struct {
+	orgID  uuid.UUID
+	userID uuid.UUID
+}
+
+type organizationRepository struct {
+	orgs    map[uuid.UUID]model.Organization
+	members map[member]model.Role
+	vaults  map[uuid.UUID]model.Vault
+	mu      sync.Mutex
+}
+
+func NewOrganizationRepository() org.OrganizationRepository {
+	return &organizationRepository{
+		orgs:    make(map[uuid.UUID]model.Organization),
+		members: make(map[member]model.Role),
+		vaults:  make(map[uuid.UUID]model.Vault),
+	}
+}
+
+func (r *organizationRepository) CreateOrganization(ctx context.Context, o *model.Organization,
+	ownerID uuid.UUID) (uuid.UUID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := uuid.New()
+	r.orgs[id] = model.Organization{ID: id, Name: o.Name}
+	r.members[member{id, ownerID}] = model.RoleOwner
+
+	return id, nil
+}
+
+func (r *organizationRepository) ListOrganizations(ctx context.Context, userID uuid.UUID) ([]*model.Organization,
+	error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var orgs []*model.Organization
+	for m, role := range r.members {
+		if m.userID != userID {
+			continue
+		}
+		o := r.orgs[m.orgID]
+		o.Role = role
+		orgs = append(orgs, &o)
+	}
+
+	return orgs, nil
+}
+
+func (r *organizationRepository) GetRole(ctx context.Context, orgID, userID uuid.UUID) (model.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	role, ok := r.members[member{orgID, userID}]
+	if !ok {
+		return "", org.ErrMemberNotFound
+	}
+
+	return role, nil
+}
+
+func (r *organizationRepository) SetMember(ctx context.Context, orgID, userID uuid.UUID, role model.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.orgs[orgID]; !ok {
+		return org.ErrOrganizationNotFound
+	}
+	r.members[member{orgID, userID}] = role
+
+	return nil
+}
+
+func (r *organizationRepository) RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m := member{orgID, userID}
+	if _, ok := r.members[m]; !ok {
+		return org.ErrMemberNotFound
+	}
+	delete(r.members, m)
+
+	return nil
+}
+
+func (r *organizationRepository) AddVault(ctx context.Context, vault *model.Vault) (uuid.UUID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	o, ok := r.orgs[vault.OrgID]
+	if !ok {
+		return uuid.Nil, org.ErrOrganizationNotFound
+	}
+
+	id := uuid.New()
+	r.vaults[id] = model.Vault{ID: id, OrgID: o.ID, OrgName: o.Name, Name: vault.Name}
+
+	return id, nil
+}
+
+func (r *organizationRepository) ListVaults(ctx context.Context, userID uuid.UUID) ([]*model.Vault, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var vaults []*model.Vault
+	for _, v := range r.vaults {
+		role, ok := r.members[member{v.OrgID, userID}]
+		if !ok {
+			continue
+		}
+		vault := v
+		vault.Role = role
+		vaults = append(vaults, &vault)
+	}
+
+	return vaults, nil
+}
+
+func (r *organizationRepository) GetVault(ctx context.Context, vaultID uuid.UUID) (*model.Vault, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, ok := r.vaults[vaultID]
+	if !ok {
+		return nil, org.ErrVaultNotFound
+	}
+
+	return &v, nil
+}
+
+func (r *organizationRepository) DeleteOrganization(ctx context.Context, orgID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.orgs[orgID]; !ok {
+		return org.ErrOrganizationNotFound
+	}
+	for m := range r.members {
+		if m.orgID == orgID {
+			delete(r.members, m)
+		}
+	}
+	for id, v := range r.vaults {
+		if v.OrgID == orgID {
+			delete(r.vaults, id)
+		}
+	}
+	delete(r.orgs, orgID)
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package inmemory
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/org"
+)
+
+func TestOrganizationRepository(t *testing.T) {
+	org.OrganizationRepositoryContract{
+		NewOrganizationRepository: func() (org.OrganizationRepository, func(), org.OrganizationTestData) {
+			t.Helper()
+
+			r := NewOrganizationRepository()
+			testData := org.OrganizationTestData{
+				Users: uuid.UUIDs{uuid.New(), uuid.New()},
+			}
+			return r, func() {}, testData
+		},
+	}.Test(t)
+}
@@ -0,0 +1,214 @@
+package pgsql
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/org"
+	"github.com/nestjam/goph-keeper/internal/org/model"
+	pgstorage "github.com/nestjam/goph-keeper/internal/storage/pgsql"
+)
+
+type organizationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewOrganizationRepository(pool *pgxpool.Pool) *organizationRepository {
+	return &organizationRepository{pool}
+}
+
+func (r *organizationRepository) CreateOrganization(ctx context.Context, o *model.Organization,
+	ownerID uuid.UUID) (uuid.UUID, error) {
+	const op = "create organization"
+
+	tx, err := pgstorage.BeginTx(ctx, r.pool)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var orgID uuid.UUID
+	err = tx.QueryRow(ctx, `INSERT INTO organizations (name) VALUES ($1) RETURNING org_id`, o.Name).Scan(&orgID)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO org_members (org_id, user_id, role) VALUES ($1, $2, $3)`,
+		orgID, ownerID, model.RoleOwner)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	return orgID, nil
+}
+
+func (r *organizationRepository) ListOrganizations(ctx context.Context, userID uuid.UUID) ([]*model.Organization,
+	error) {
+	const op = "list organizations"
+
+	const sql = `SELECT o.org_id, o.name, m.role FROM organizations o
+JOIN org_members m ON m.org_id = o.org_id WHERE m.user_id=$1 ORDER BY o.name`
+	rows, _ := r.querier(ctx).Query(ctx, sql, userID)
+	orgs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.Organization, error) {
+		var o model.Organization
+		err := row.Scan(&o.ID, &o.Name, &o.Role)
+		return &o, err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return orgs, nil
+}
+
+func (r *organizationRepository) GetRole(ctx context.Context, orgID, userID uuid.UUID) (model.Role, error) {
+	const op = "get role"
+
+	var role model.Role
+	const sql = `SELECT role FROM org_members WHERE org_id=$1 AND user_id=$2`
+	err := r.querier(ctx).QueryRow(ctx, sql, orgID, userID).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", org.ErrMemberNotFound
+	}
+	if err != nil {
+		return "", errors.Wrap(err, op)
+	}
+
+	return role, nil
+}
+
+func (r *organizationRepository) SetMember(ctx context.Context, orgID, userID uuid.UUID, role model.Role) error {
+	const op = "set member"
+
+	const sql = `INSERT INTO org_members (org_id, user_id, role) VALUES ($1, $2, $3)
+ON CONFLICT (org_id, user_id) DO UPDATE SET role=excluded.role`
+	_, err := r.querier(ctx).Exec(ctx, sql, orgID, userID, role)
+	if isOrganizationViolation(err) {
+		return org.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (r *organizationRepository) RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error {
+	const op = "remove member"
+
+	const sql = `DELETE FROM org_members WHERE org_id=$1 AND user_id=$2`
+	tag, err := r.querier(ctx).Exec(ctx, sql, orgID, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if tag.RowsAffected() == 0 {
+		return org.ErrMemberNotFound
+	}
+
+	return nil
+}
+
+func (r *organizationRepository) AddVault(ctx context.Context, vault *model.Vault) (uuid.UUID, error) {
+	const op = "add vault"
+
+	var vaultID uuid.UUID
+	const sql = `INSERT INTO vaults (org_id, name) VALUES ($1, $2) RETURNING vault_id`
+	err := r.querier(ctx).QueryRow(ctx, sql, vault.OrgID, vault.Name).Scan(&vaultID)
+	if isOrganizationViolation(err) {
+		return uuid.Nil, org.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	return vaultID, nil
+}
+
+func (r *organizationRepository) ListVaults(ctx context.Context, userID uuid.UUID) ([]*model.Vault, error) {
+	const op = "list vaults"
+
+	const sql = `SELECT v.vault_id, v.org_id, o.name, v.name, m.role FROM vaults v
+JOIN organizations o ON o.org_id = v.org_id
+JOIN org_members m ON m.org_id = v.org_id WHERE m.user_id=$1 ORDER BY o.name, v.name`
+	rows, _ := r.querier(ctx).Query(ctx, sql, userID)
+	vaults, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.Vault, error) {
+		var v model.Vault
+		err := row.Scan(&v.ID, &v.OrgID, &v.OrgName, &v.Name, &v.Role)
+		return &v, err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return vaults, nil
+}
+
+func (r *organizationRepository) GetVault(ctx context.Context, vaultID uuid.UUID) (*model.Vault, error) {
+	const op = "get vault"
+
+	var v model.Vault
+	const sql = `SELECT v.vault_id, v.org_id, o.name, v.name FROM vaults v
+JOIN organizations o ON o.org_id = v.org_id WHERE v.vault_id=$1`
+	err := r.querier(ctx).QueryRow(ctx, sql, vaultID).Scan(&v.ID, &v.OrgID, &v.OrgName, &v.Name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, org.ErrVaultNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return &v, nil
+}
+
+func (r *organizationRepository) DeleteOrganization(ctx context.Context, orgID uuid.UUID) error {
+	const op = "delete organization"
+
+	tx, err := pgstorage.BeginTx(ctx, r.pool)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// members are deleted along with the organization
+	_, err = tx.Exec(ctx, `DELETE FROM vaults WHERE org_id=$1`, orgID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM organizations WHERE org_id=$1`, orgID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if tag.RowsAffected() == 0 {
+		return org.ErrOrganizationNotFound
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// querier returns transaction of the context if any.
+func (r *organizationRepository) querier(ctx context.Context) pgstorage.Querier {
+	return pgstorage.QuerierFromContext(ctx, r.pool)
+}
+
+func isOrganizationViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation &&
+		(pgErr.ConstraintName == "org_members_org_id_fkey" || pgErr.ConstraintName == "vaults_org_id_fkey")
+}
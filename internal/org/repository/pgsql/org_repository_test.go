@@ -0,0 +1,72 @@
+//go:build integration
+
+package pgsql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	modelAuth "github.com/nestjam/goph-keeper/internal/auth/model"
+	"github.com/nestjam/goph-keeper/internal/auth/repository/pgsql"
+	"github.com/nestjam/goph-keeper/internal/config"
+	"github.com/nestjam/goph-keeper/internal/org"
+	pgstorage "github.com/nestjam/goph-keeper/internal/storage/pgsql"
+	"github.com/nestjam/goph-keeper/internal/utils"
+	"github.com/nestjam/goph-keeper/migration"
+)
+
+var h *utils.PGSQLRepositoryTestHelper
+
+func TestMain(m *testing.M) {
+	h = &utils.PGSQLRepositoryTestHelper{}
+	h.Run(m)
+}
+
+func TestOrganizationRepository(t *testing.T) {
+	org.OrganizationRepositoryContract{
+		NewOrganizationRepository: func() (org.OrganizationRepository, func(), org.OrganizationTestData) {
+			t.Helper()
+
+			dsn := h.DataSourceName
+			migrator := migration.NewDatabaseMigrator(dsn)
+			err := migrator.Up()
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			pool, err := pgstorage.NewPool(ctx, config.PostgresConfig{DataSourceName: dsn})
+			require.NoError(t, err)
+			r := NewOrganizationRepository(pool)
+
+			closer := func() {
+				pool.Close()
+
+				migrator := migration.NewDatabaseMigrator(dsn)
+				_ = migrator.Drop()
+			}
+
+			testData := org.OrganizationTestData{
+				Users: setupUsers(t, pool),
+			}
+			return r, closer, testData
+		},
+	}.Test(t)
+}
+
+func setupUsers(t *testing.T, pool *pgxpool.Pool) uuid.UUIDs {
+	t.Helper()
+
+	ctx := context.Background()
+	r := pgsql.NewUserRepository(pool)
+
+	userID, err := r.Register(ctx, &modelAuth.User{Email: "user@email.com", Password: "1"})
+	require.NoError(t, err)
+	user2ID, err := r.Register(ctx, &modelAuth.User{Email: "user2@email.com", Password: "2"})
+	require.NoError(t, err)
+
+	return uuid.UUIDs{userID, user2ID}
+}
@@ -0,0 +1,236 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/nestjam/goph-keeper/internal/org"
+	"github.com/nestjam/goph-keeper/internal/org/model"
+	sqlitestorage "github.com/nestjam/goph-keeper/internal/storage/sqlite"
+)
+
+type organizationRepository struct {
+	db *sql.DB
+}
+
+func NewOrganizationRepository(db *sql.DB) *organizationRepository {
+	return &organizationRepository{db}
+}
+
+func (r *organizationRepository) CreateOrganization(ctx context.Context, o *model.Organization,
+	ownerID uuid.UUID) (uuid.UUID, error) {
+	const op = "create organization"
+
+	tx, err := sqlitestorage.BeginTx(ctx, r.db)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	orgID := uuid.New()
+	_, err = tx.ExecContext(ctx, `INSERT INTO organizations (org_id, name) VALUES (?, ?)`, orgID, o.Name)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO org_members (org_id, user_id, role) VALUES (?, ?, ?)`,
+		orgID, ownerID, model.RoleOwner)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	return orgID, nil
+}
+
+func (r *organizationRepository) ListOrganizations(ctx context.Context, userID uuid.UUID) ([]*model.Organization,
+	error) {
+	const op = "list organizations"
+
+	const query = `SELECT o.org_id, o.name, m.role FROM organizations o
+JOIN org_members m ON m.org_id = o.org_id WHERE m.user_id=? ORDER BY o.name`
+	rows, err := r.executor(ctx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var orgs []*model.Organization
+	for rows.Next() {
+		var o model.Organization
+		if err = rows.Scan(&o.ID, &o.Name, &o.Role); err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+		orgs = append(orgs, &o)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return orgs, nil
+}
+
+func (r *organizationRepository) GetRole(ctx context.Context, orgID, userID uuid.UUID) (model.Role, error) {
+	const op = "get role"
+
+	var role model.Role
+	const query = `SELECT role FROM org_members WHERE org_id=? AND user_id=?`
+	err := r.executor(ctx).QueryRowContext(ctx, query, orgID, userID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", org.ErrMemberNotFound
+	}
+	if err != nil {
+		return "", errors.Wrap(err, op)
+	}
+
+	return role, nil
+}
+
+func (r *organizationRepository) SetMember(ctx context.Context, orgID, userID uuid.UUID, role model.Role) error {
+	const op = "set member"
+
+	const query = `INSERT INTO org_members (org_id, user_id, role) VALUES (?, ?, ?)
+ON CONFLICT (org_id, user_id) DO UPDATE SET role=excluded.role`
+	_, err := r.executor(ctx).ExecContext(ctx, query, orgID, userID, role)
+	if isForeignKeyViolation(err) {
+		return org.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (r *organizationRepository) RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error {
+	const op = "remove member"
+
+	const query = `DELETE FROM org_members WHERE org_id=? AND user_id=?`
+	res, err := r.executor(ctx).ExecContext(ctx, query, orgID, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if n == 0 {
+		return org.ErrMemberNotFound
+	}
+
+	return nil
+}
+
+func (r *organizationRepository) AddVault(ctx context.Context, vault *model.Vault) (uuid.UUID, error) {
+	const op = "add vault"
+
+	vaultID := uuid.New()
+	const query = `INSERT INTO vaults (vault_id, org_id, name) VALUES (?, ?, ?)`
+	_, err := r.executor(ctx).ExecContext(ctx, query, vaultID, vault.OrgID, vault.Name)
+	if isForeignKeyViolation(err) {
+		return uuid.Nil, org.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	return vaultID, nil
+}
+
+func (r *organizationRepository) ListVaults(ctx context.Context, userID uuid.UUID) ([]*model.Vault, error) {
+	const op = "list vaults"
+
+	const query = `SELECT v.vault_id, v.org_id, o.name, v.name, m.role FROM vaults v
+JOIN organizations o ON o.org_id = v.org_id
+JOIN org_members m ON m.org_id = v.org_id WHERE m.user_id=? ORDER BY o.name, v.name`
+	rows, err := r.executor(ctx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var vaults []*model.Vault
+	for rows.Next() {
+		var v model.Vault
+		if err = rows.Scan(&v.ID, &v.OrgID, &v.OrgName, &v.Name, &v.Role); err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+		vaults = append(vaults, &v)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return vaults, nil
+}
+
+func (r *organizationRepository) GetVault(ctx context.Context, vaultID uuid.UUID) (*model.Vault, error) {
+	const op = "get vault"
+
+	var v model.Vault
+	const query = `SELECT v.vault_id, v.org_id, o.name, v.name FROM vaults v
+JOIN organizations o ON o.org_id = v.org_id WHERE v.vault_id=?`
+	err := r.executor(ctx).QueryRowContext(ctx, query, vaultID).Scan(&v.ID, &v.OrgID, &v.OrgName, &v.Name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, org.ErrVaultNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return &v, nil
+}
+
+func (r *organizationRepository) DeleteOrganization(ctx context.Context, orgID uuid.UUID) error {
+	const op = "delete organization"
+
+	tx, err := sqlitestorage.BeginTx(ctx, r.db)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// members are deleted along with the organization
+	_, err = tx.ExecContext(ctx, `DELETE FROM vaults WHERE org_id=?`, orgID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM organizations WHERE org_id=?`, orgID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if n == 0 {
+		return org.ErrOrganizationNotFound
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// executor returns transaction of the context if any.
+func (r *organizationRepository) executor(ctx context.Context) sqlitestorage.Executor {
+	return sqlitestorage.ExecutorFromContext(ctx, r.db)
+}
+
+func isForeignKeyViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY
+}
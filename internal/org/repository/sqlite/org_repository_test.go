@@ -0,0 +1,56 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	modelAuth "github.com/nestjam/goph-keeper/internal/auth/model"
+	"github.com/nestjam/goph-keeper/internal/auth/repository/sqlite"
+	"github.com/nestjam/goph-keeper/internal/org"
+	sqlitestorage "github.com/nestjam/goph-keeper/internal/storage/sqlite"
+	"github.com/nestjam/goph-keeper/migration"
+)
+
+func TestOrganizationRepository(t *testing.T) {
+	org.OrganizationRepositoryContract{
+		NewOrganizationRepository: func() (org.OrganizationRepository, func(), org.OrganizationTestData) {
+			t.Helper()
+
+			path := filepath.Join(t.TempDir(), "goph-keeper.db")
+			migrator := migration.NewSQLiteMigrator(path)
+			err := migrator.Up()
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			db, err := sqlitestorage.Open(ctx, path)
+			require.NoError(t, err)
+			r := NewOrganizationRepository(db)
+
+			testData := org.OrganizationTestData{
+				Users: setupUsers(t, path),
+			}
+			return r, func() { _ = db.Close() }, testData
+		},
+	}.Test(t)
+}
+
+func setupUsers(t *testing.T, path string) uuid.UUIDs {
+	t.Helper()
+
+	ctx := context.Background()
+	db, err := sqlitestorage.Open(ctx, path)
+	require.NoError(t, err)
+	r := sqlite.NewUserRepository(db)
+	defer func() { _ = db.Close() }()
+
+	userID, err := r.Register(ctx, &modelAuth.User{Email: "user@email.com", Password: "1"})
+	require.NoError(t, err)
+	user2ID, err := r.Register(ctx, &modelAuth.User{Email: "user2@email.com", Password: "2"})
+	require.NoError(t, err)
+
+	return uuid.UUIDs{userID, user2ID}
+}
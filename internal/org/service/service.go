@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	"github.com/nestjam/goph-keeper/internal/org"
+	"github.com/nestjam/goph-keeper/internal/org/model"
+	"github.com/nestjam/goph-keeper/internal/vault"
+)
+
+type organizationService struct {
+	vault.AccessPolicy
+	orgs       org.OrganizationRepository
+	users      auth.UserRepository
+	shredder   auth.UserDataShredder
+	transactor vault.Transactor
+}
+
+type OrganizationServiceOption func(*organizationService)
+
+// WithTransactor makes the service delete the organization along with the data of its vaults in one
+// transaction of the storage, so that a failure never leaves the vaults half shredded. The steps are run
+// one by one by default.
+func WithTransactor(transactor vault.Transactor) OrganizationServiceOption {
+	return func(s *organizationService) {
+		s.transactor = transactor
+	}
+}
+
+// NewOrganizationService creates the service of organizations. The shredder destroys data of the vaults
+// of the deleted organizations.
+func NewOrganizationService(orgs org.OrganizationRepository, users auth.UserRepository,
+	shredder auth.UserDataShredder, opts ...OrganizationServiceOption) org.OrganizationService {
+	s := &organizationService{
+		AccessPolicy: NewVaultAccessPolicy(orgs),
+		orgs:         orgs,
+		users:        users,
+		shredder:     shredder,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+type vaultAccessPolicy struct {
+	orgs org.OrganizationRepository
+}
+
+// NewVaultAccessPolicy creates the access policy of the organization vaults. The policy is created
+// apart from the organization service, as the service shreds the vaults by the vault service that
+// depends on the policy.
+func NewVaultAccessPolicy(orgs org.OrganizationRepository) vault.AccessPolicy {
+	return &vaultAccessPolicy{orgs}
+}
+
+func (s *organizationService) CreateOrganization(ctx context.Context, name string, userID uuid.UUID) (uuid.UUID,
+	error) {
+	const op = "create organization"
+
+	if name == "" {
+		return uuid.Nil, errors.Wrap(org.ErrNameIsEmpty, op)
+	}
+
+	orgID, err := s.orgs.CreateOrganization(ctx, &model.Organization{Name: name}, userID)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	return orgID, nil
+}
+
+func (s *organizationService) ListOrganizations(ctx context.Context, userID uuid.UUID) ([]*model.Organization,
+	error) {
+	const op = "list organizations"
+
+	orgs, err := s.orgs.ListOrganizations(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return orgs, nil
+}
+
+func (s *organizationService) AddMember(ctx context.Context, orgID uuid.UUID, email string, role model.Role,
+	userID uuid.UUID) error {
+	const op = "add member"
+
+	if !role.IsValid() || role == model.RoleOwner {
+		return errors.Wrap(org.ErrInvalidRole, op)
+	}
+
+	err := s.checkOwner(ctx, orgID, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	user, err := s.users.FindByEmail(ctx, email)
+	if errors.Is(err, auth.ErrUserIsNotRegistered) {
+		return errors.Wrap(org.ErrUserNotFound, op)
+	}
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if user.ID == userID {
+		// the owner can not give up the organization
+		return errors.Wrap(org.ErrPermissionDenied, op)
+	}
+
+	err = s.orgs.SetMember(ctx, orgID, user.ID, role)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (s *organizationService) RemoveMember(ctx context.Context, orgID, memberID, userID uuid.UUID) error {
+	const op = "remove member"
+
+	err := s.checkOwner(ctx, orgID, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if memberID == userID {
+		return errors.Wrap(org.ErrPermissionDenied, op)
+	}
+
+	err = s.orgs.RemoveMember(ctx, orgID, memberID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (s *organizationService) AddVault(ctx context.Context, orgID uuid.UUID, name string, userID uuid.UUID) (uuid.UUID,
+	error) {
+	const op = "add vault"
+
+	if name == "" {
+		return uuid.Nil, errors.Wrap(org.ErrNameIsEmpty, op)
+	}
+
+	err := s.checkOwner(ctx, orgID, userID)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	vaultID, err := s.orgs.AddVault(ctx, &model.Vault{OrgID: orgID, Name: name})
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	return vaultID, nil
+}
+
+func (s *organizationService) ListVaults(ctx context.Context, userID uuid.UUID) ([]*model.Vault, error) {
+	const op = "list vaults"
+
+	vaults, err := s.orgs.ListVaults(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return vaults, nil
+}
+
+func (s *organizationService) DeleteOrganization(ctx context.Context, orgID, userID uuid.UUID) error {
+	const op = "delete organization"
+
+	err := s.checkOwner(ctx, orgID, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	vaults, err := s.orgs.ListVaults(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	var cleanups []func(ctx context.Context) error
+	err = s.withinTransaction(ctx, func(ctx context.Context) error {
+		// vault data goes first, as it refers to the vaults
+		for _, v := range vaults {
+			if v.OrgID != orgID {
+				continue
+			}
+			cleanup, err := s.shredder.DeleteUserData(ctx, v.ID)
+			if err != nil {
+				return err
+			}
+			cleanups = append(cleanups, cleanup)
+		}
+
+		return s.orgs.DeleteOrganization(ctx, orgID)
+	})
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	// blobs and keys are destroyed once the deletion is committed, as they can not be rolled back
+	errs := make([]error, 0, len(cleanups))
+	for _, cleanup := range cleanups {
+		errs = append(errs, cleanup(ctx))
+	}
+	if err = stderrors.Join(errs...); err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (s *organizationService) withinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.transactor == nil {
+		return fn(ctx)
+	}
+	return s.transactor.WithinTransaction(ctx, fn)
+}
+
+// DeleteUserData removes the user from the organizations. The owner has to delete the organizations first,
+// so that their vaults are never left without the owner.
+func (s *organizationService) DeleteUserData(ctx context.Context, userID uuid.UUID) (func(ctx context.Context) error,
+	error) {
+	const op = "delete user data"
+
+	orgs, err := s.orgs.ListOrganizations(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	for _, o := range orgs {
+		if o.Role == model.RoleOwner {
+			return nil, errors.Wrap(auth.ErrOrganizationOwner, op)
+		}
+	}
+
+	for _, o := range orgs {
+		err = s.orgs.RemoveMember(ctx, o.ID, userID)
+		if err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+	}
+
+	// memberships are kept in the storage only, so there is nothing to destroy after the commit
+	return func(context.Context) error { return nil }, nil
+}
+
+// VaultAccess grants members of the organization access to its vaults according to their role.
+// The vault is not found for users who are not members.
+func (p *vaultAccessPolicy) VaultAccess(ctx context.Context, vaultID, userID uuid.UUID) (vault.Access, error) {
+	const op = "vault access"
+
+	v, err := p.orgs.GetVault(ctx, vaultID)
+	if errors.Is(err, org.ErrVaultNotFound) {
+		return vault.AccessNone, errors.Wrap(vault.ErrVaultNotFound, op)
+	}
+	if err != nil {
+		return vault.AccessNone, errors.Wrap(err, op)
+	}
+
+	role, err := p.orgs.GetRole(ctx, v.OrgID, userID)
+	if errors.Is(err, org.ErrMemberNotFound) {
+		return vault.AccessNone, errors.Wrap(vault.ErrVaultNotFound, op)
+	}
+	if err != nil {
+		return vault.AccessNone, errors.Wrap(err, op)
+	}
+
+	if role.CanWrite() {
+		return vault.AccessWrite, nil
+	}
+	return vault.AccessRead, nil
+}
+
+// checkOwner checks that the user owns the organization.
+// The organization is not found for users who are not members.
+func (s *organizationService) checkOwner(ctx context.Context, orgID, userID uuid.UUID) error {
+	role, err := s.orgs.GetRole(ctx, orgID, userID)
+	if errors.Is(err, org.ErrMemberNotFound) {
+		return org.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if role != model.RoleOwner {
+		return org.ErrPermissionDenied
+	}
+
+	return nil
+}
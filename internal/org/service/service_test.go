@@ -0,0 +1,398 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/auth"
+	authModel "github.com/nestjam/goph-keeper/internal/auth/model"
+	authInmemory "github.com/nestjam/goph-keeper/internal/auth/repository/inmemory"
+	"github.com/nestjam/goph-keeper/internal/org"
+	"github.com/nestjam/goph-keeper/internal/org/model"
+	"github.com/nestjam/goph-keeper/internal/org/repository/inmemory"
+	"github.com/nestjam/goph-keeper/internal/vault"
+)
+
+const memberEmail = "member@email.com"
+
+func TestCreateOrganization(t *testing.T) {
+	t.Run("create organization", func(t *testing.T) {
+		ctx := context.Background()
+		sut := NewOrganizationService(inmemory.NewOrganizationRepository(), authInmemory.NewUserRepository(),
+			&auth.UserDataShredderMock{})
+		userID := uuid.New()
+
+		orgID, err := sut.CreateOrganization(ctx, "team", userID)
+
+		require.NoError(t, err)
+		got, err := sut.ListOrganizations(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Organization{{ID: orgID, Name: "team", Role: model.RoleOwner}}, got)
+	})
+	t.Run("name is empty", func(t *testing.T) {
+		sut := NewOrganizationService(inmemory.NewOrganizationRepository(), authInmemory.NewUserRepository(),
+			&auth.UserDataShredderMock{})
+
+		_, err := sut.CreateOrganization(context.Background(), "", uuid.New())
+
+		require.ErrorIs(t, err, org.ErrNameIsEmpty)
+	})
+}
+
+func TestAddMember(t *testing.T) {
+	t.Run("add member", func(t *testing.T) {
+		ctx := context.Background()
+		sut, orgID, ownerID, memberID := newOrganization(t)
+
+		err := sut.AddMember(ctx, orgID, memberEmail, model.RoleEditor, ownerID)
+
+		require.NoError(t, err)
+		got, err := sut.ListOrganizations(ctx, memberID)
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Organization{{ID: orgID, Name: "team", Role: model.RoleEditor}}, got)
+	})
+	t.Run("invalid role", func(t *testing.T) {
+		sut, orgID, ownerID, _ := newOrganization(t)
+
+		err := sut.AddMember(context.Background(), orgID, memberEmail, model.Role("admin"), ownerID)
+
+		require.ErrorIs(t, err, org.ErrInvalidRole)
+	})
+	t.Run("member can not be made owner", func(t *testing.T) {
+		sut, orgID, ownerID, _ := newOrganization(t)
+
+		err := sut.AddMember(context.Background(), orgID, memberEmail, model.RoleOwner, ownerID)
+
+		require.ErrorIs(t, err, org.ErrInvalidRole)
+	})
+	t.Run("user is not registered", func(t *testing.T) {
+		sut, orgID, ownerID, _ := newOrganization(t)
+
+		err := sut.AddMember(context.Background(), orgID, "unknown@email.com", model.RoleViewer, ownerID)
+
+		require.ErrorIs(t, err, org.ErrUserNotFound)
+	})
+	t.Run("editor can not add members", func(t *testing.T) {
+		ctx := context.Background()
+		sut, orgID, ownerID, memberID := newOrganization(t)
+		err := sut.AddMember(ctx, orgID, memberEmail, model.RoleEditor, ownerID)
+		require.NoError(t, err)
+
+		err = sut.AddMember(ctx, orgID, memberEmail, model.RoleViewer, memberID)
+
+		require.ErrorIs(t, err, org.ErrPermissionDenied)
+	})
+	t.Run("user is not member", func(t *testing.T) {
+		sut, orgID, _, memberID := newOrganization(t)
+
+		err := sut.AddMember(context.Background(), orgID, memberEmail, model.RoleViewer, memberID)
+
+		require.ErrorIs(t, err, org.ErrOrganizationNotFound)
+	})
+}
+
+func TestRemoveMember(t *testing.T) {
+	t.Run("remove member", func(t *testing.T) {
+		ctx := context.Background()
+		sut, orgID, ownerID, memberID := newOrganization(t)
+		err := sut.AddMember(ctx, orgID, memberEmail, model.RoleViewer, ownerID)
+		require.NoError(t, err)
+
+		err = sut.RemoveMember(ctx, orgID, memberID, ownerID)
+
+		require.NoError(t, err)
+		got, err := sut.ListOrganizations(ctx, memberID)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+	t.Run("owner can not be removed", func(t *testing.T) {
+		sut, orgID, ownerID, _ := newOrganization(t)
+
+		err := sut.RemoveMember(context.Background(), orgID, ownerID, ownerID)
+
+		require.ErrorIs(t, err, org.ErrPermissionDenied)
+	})
+}
+
+func TestAddVault(t *testing.T) {
+	t.Run("add vault", func(t *testing.T) {
+		ctx := context.Background()
+		sut, orgID, ownerID, _ := newOrganization(t)
+
+		vaultID, err := sut.AddVault(ctx, orgID, "servers", ownerID)
+
+		require.NoError(t, err)
+		got, err := sut.ListVaults(ctx, ownerID)
+		require.NoError(t, err)
+		want := []*model.Vault{{ID: vaultID, OrgID: orgID, OrgName: "team", Name: "servers", Role: model.RoleOwner}}
+		assert.Equal(t, want, got)
+	})
+	t.Run("name is empty", func(t *testing.T) {
+		sut, orgID, ownerID, _ := newOrganization(t)
+
+		_, err := sut.AddVault(context.Background(), orgID, "", ownerID)
+
+		require.ErrorIs(t, err, org.ErrNameIsEmpty)
+	})
+	t.Run("viewer can not add vaults", func(t *testing.T) {
+		ctx := context.Background()
+		sut, orgID, ownerID, memberID := newOrganization(t)
+		err := sut.AddMember(ctx, orgID, memberEmail, model.RoleViewer, ownerID)
+		require.NoError(t, err)
+
+		_, err = sut.AddVault(ctx, orgID, "servers", memberID)
+
+		require.ErrorIs(t, err, org.ErrPermissionDenied)
+	})
+}
+
+func TestDeleteOrganization(t *testing.T) {
+	t.Run("delete organization", func(t *testing.T) {
+		ctx := context.Background()
+		var shredded uuid.UUIDs
+		shredder := &auth.UserDataShredderMock{
+			DeleteUserDataFunc: func(_ context.Context, vaultID uuid.UUID) (func(context.Context) error, error) {
+				shredded = append(shredded, vaultID)
+				return func(context.Context) error { return nil }, nil
+			},
+		}
+		sut, orgID, ownerID, _ := newOrganizationWithShredder(t, shredder)
+		vaultID, err := sut.AddVault(ctx, orgID, "servers", ownerID)
+		require.NoError(t, err)
+		anotherOrgID, err := sut.CreateOrganization(ctx, "another team", ownerID)
+		require.NoError(t, err)
+		_, err = sut.AddVault(ctx, anotherOrgID, "sites", ownerID)
+		require.NoError(t, err)
+
+		err = sut.DeleteOrganization(ctx, orgID, ownerID)
+
+		require.NoError(t, err)
+		assert.Equal(t, uuid.UUIDs{vaultID}, shredded)
+		_, err = sut.VaultAccess(ctx, vaultID, ownerID)
+		require.ErrorIs(t, err, vault.ErrVaultNotFound)
+		got, err := sut.ListOrganizations(ctx, ownerID)
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Organization{{ID: anotherOrgID, Name: "another team", Role: model.RoleOwner}}, got)
+	})
+	t.Run("vaults are not shredded", func(t *testing.T) {
+		ctx := context.Background()
+		shredder := &auth.UserDataShredderMock{
+			DeleteUserDataFunc: func(context.Context, uuid.UUID) (func(context.Context) error, error) {
+				return nil, assert.AnError
+			},
+		}
+		sut, orgID, ownerID, _ := newOrganizationWithShredder(t, shredder)
+		_, err := sut.AddVault(ctx, orgID, "servers", ownerID)
+		require.NoError(t, err)
+
+		err = sut.DeleteOrganization(ctx, orgID, ownerID)
+
+		require.ErrorIs(t, err, assert.AnError)
+		got, err := sut.ListOrganizations(ctx, ownerID)
+		require.NoError(t, err)
+		assert.Len(t, got, 1)
+	})
+	t.Run("vault data out of storage is destroyed after deletion is committed", func(t *testing.T) {
+		ctx := context.Background()
+		var inTx, committed bool
+		transactor := &transactorMock{
+			WithinTransactionFunc: func(ctx context.Context, fn func(ctx context.Context) error) error {
+				inTx = true
+				err := fn(ctx)
+				inTx = false
+				committed = err == nil
+				return err
+			},
+		}
+		var cleanedUp uuid.UUIDs
+		shredder := &auth.UserDataShredderMock{
+			DeleteUserDataFunc: func(_ context.Context, vaultID uuid.UUID) (func(context.Context) error, error) {
+				assert.True(t, inTx)
+				return func(context.Context) error {
+					assert.True(t, committed)
+					cleanedUp = append(cleanedUp, vaultID)
+					return nil
+				}, nil
+			},
+		}
+		users := authInmemory.NewUserRepository()
+		sut := NewOrganizationService(inmemory.NewOrganizationRepository(), users, shredder, WithTransactor(transactor))
+		ownerID := uuid.New()
+		orgID, err := sut.CreateOrganization(ctx, "team", ownerID)
+		require.NoError(t, err)
+		vaultID, err := sut.AddVault(ctx, orgID, "servers", ownerID)
+		require.NoError(t, err)
+		vault2ID, err := sut.AddVault(ctx, orgID, "sites", ownerID)
+		require.NoError(t, err)
+
+		err = sut.DeleteOrganization(ctx, orgID, ownerID)
+
+		require.NoError(t, err)
+		assert.ElementsMatch(t, uuid.UUIDs{vaultID, vault2ID}, cleanedUp)
+	})
+	t.Run("vault data out of storage is kept if deletion is not committed", func(t *testing.T) {
+		ctx := context.Background()
+		transactor := &transactorMock{
+			WithinTransactionFunc: func(ctx context.Context, fn func(ctx context.Context) error) error {
+				err := fn(ctx)
+				require.NoError(t, err)
+				return errors.New("commit failed")
+			},
+		}
+		var cleanedUp bool
+		shredder := &auth.UserDataShredderMock{
+			DeleteUserDataFunc: func(context.Context, uuid.UUID) (func(context.Context) error, error) {
+				return func(context.Context) error {
+					cleanedUp = true
+					return nil
+				}, nil
+			},
+		}
+		users := authInmemory.NewUserRepository()
+		sut := NewOrganizationService(inmemory.NewOrganizationRepository(), users, shredder, WithTransactor(transactor))
+		ownerID := uuid.New()
+		orgID, err := sut.CreateOrganization(ctx, "team", ownerID)
+		require.NoError(t, err)
+		_, err = sut.AddVault(ctx, orgID, "servers", ownerID)
+		require.NoError(t, err)
+
+		err = sut.DeleteOrganization(ctx, orgID, ownerID)
+
+		require.Error(t, err)
+		assert.False(t, cleanedUp)
+	})
+	t.Run("editor can not delete organization", func(t *testing.T) {
+		ctx := context.Background()
+		sut, orgID, ownerID, memberID := newOrganization(t)
+		err := sut.AddMember(ctx, orgID, memberEmail, model.RoleEditor, ownerID)
+		require.NoError(t, err)
+
+		err = sut.DeleteOrganization(ctx, orgID, memberID)
+
+		require.ErrorIs(t, err, org.ErrPermissionDenied)
+	})
+}
+
+func TestDeleteUserData(t *testing.T) {
+	t.Run("member is removed from organizations", func(t *testing.T) {
+		ctx := context.Background()
+		sut, orgID, ownerID, memberID := newOrganization(t)
+		err := sut.AddMember(ctx, orgID, memberEmail, model.RoleViewer, ownerID)
+		require.NoError(t, err)
+
+		_, err = sut.DeleteUserData(ctx, memberID)
+
+		require.NoError(t, err)
+		got, err := sut.ListOrganizations(ctx, memberID)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+	t.Run("owner of organization", func(t *testing.T) {
+		ctx := context.Background()
+		sut, _, ownerID, _ := newOrganization(t)
+
+		_, err := sut.DeleteUserData(ctx, ownerID)
+
+		require.ErrorIs(t, err, auth.ErrOrganizationOwner)
+		got, err := sut.ListOrganizations(ctx, ownerID)
+		require.NoError(t, err)
+		assert.Len(t, got, 1)
+	})
+}
+
+func TestVaultAccess(t *testing.T) {
+	tests := []struct {
+		name string
+		role model.Role
+		want vault.Access
+	}{
+		{
+			name: "owner writes",
+			role: model.RoleOwner,
+			want: vault.AccessWrite,
+		},
+		{
+			name: "editor writes",
+			role: model.RoleEditor,
+			want: vault.AccessWrite,
+		},
+		{
+			name: "viewer reads",
+			role: model.RoleViewer,
+			want: vault.AccessRead,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			sut, orgID, ownerID, memberID := newOrganization(t)
+			userID := ownerID
+			if tt.role != model.RoleOwner {
+				err := sut.AddMember(ctx, orgID, memberEmail, tt.role, ownerID)
+				require.NoError(t, err)
+				userID = memberID
+			}
+			vaultID, err := sut.AddVault(ctx, orgID, "servers", ownerID)
+			require.NoError(t, err)
+
+			got, err := sut.VaultAccess(ctx, vaultID, userID)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	t.Run("user is not member", func(t *testing.T) {
+		ctx := context.Background()
+		sut, orgID, ownerID, memberID := newOrganization(t)
+		vaultID, err := sut.AddVault(ctx, orgID, "servers", ownerID)
+		require.NoError(t, err)
+
+		_, err = sut.VaultAccess(ctx, vaultID, memberID)
+
+		require.ErrorIs(t, err, vault.ErrVaultNotFound)
+	})
+	t.Run("vault does not exist", func(t *testing.T) {
+		sut, _, ownerID, _ := newOrganization(t)
+
+		_, err := sut.VaultAccess(context.Background(), uuid.New(), ownerID)
+
+		require.ErrorIs(t, err, vault.ErrVaultNotFound)
+	})
+}
+
+// newOrganization returns the service with the organization of the owner
+// and the registered user who is not member yet.
+func newOrganization(t *testing.T) (org.OrganizationService, uuid.UUID, uuid.UUID, uuid.UUID) {
+	t.Helper()
+
+	return newOrganizationWithShredder(t, &auth.UserDataShredderMock{})
+}
+
+func newOrganizationWithShredder(t *testing.T, shredder auth.UserDataShredder) (org.OrganizationService, uuid.UUID,
+	uuid.UUID, uuid.UUID) {
+	t.Helper()
+
+	ctx := context.Background()
+	users := authInmemory.NewUserRepository()
+	memberID := registerUser(t, users, memberEmail)
+	sut := NewOrganizationService(inmemory.NewOrganizationRepository(), users, shredder)
+	ownerID := uuid.New()
+	orgID, err := sut.CreateOrganization(ctx, "team", ownerID)
+	require.NoError(t, err)
+
+	return sut, orgID, ownerID, memberID
+}
+
+func registerUser(t *testing.T, users auth.UserRepository, email string) uuid.UUID {
+	t.Helper()
+
+	userID, err := users.Register(context.Background(), &authModel.User{Email: email, Password: "secret"})
+	require.NoError(t, err)
+
+	return userID
+}
@@ -3,50 +3,81 @@ package server
 import (
 	"context"
 	"net/http"
-	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/pkg/errors"
 
+	httpAudit "github.com/nestjam/goph-keeper/internal/audit/delivery/http"
+	serviceAudit "github.com/nestjam/goph-keeper/internal/audit/service"
+	"github.com/nestjam/goph-keeper/internal/auth"
 	httpAuth "github.com/nestjam/goph-keeper/internal/auth/delivery/http"
-	users "github.com/nestjam/goph-keeper/internal/auth/repository/pgsql"
+	modelAuth "github.com/nestjam/goph-keeper/internal/auth/model"
 	serviceAuth "github.com/nestjam/goph-keeper/internal/auth/service"
-	"github.com/nestjam/goph-keeper/internal/config"
+	httpOrg "github.com/nestjam/goph-keeper/internal/org/delivery/http"
+	serviceOrg "github.com/nestjam/goph-keeper/internal/org/service"
+	"github.com/nestjam/goph-keeper/internal/storage"
+	"github.com/nestjam/goph-keeper/internal/utils"
 	httpVault "github.com/nestjam/goph-keeper/internal/vault/delivery/http"
-	keys "github.com/nestjam/goph-keeper/internal/vault/repository/pgsql/key"
-	secrets "github.com/nestjam/goph-keeper/internal/vault/repository/pgsql/secret"
 	serviceVault "github.com/nestjam/goph-keeper/internal/vault/service"
 )
 
 func (s *Server) mapHandlers(ctx context.Context) (http.Handler, error) {
 	const op = "map handlers"
-	jwtAuthConfig := config.JWTAuthConfig{
-		SignKey:       "supersecret",
-		TokenExpiryIn: time.Hour,
-	}
-
-	authRepo, err := users.NewUserRepository(ctx, s.conf.Postgres.DataSourceName)
+	jwtAuthConfig := s.conf.JWTAuth
+	keys, err := utils.NewJWTKeys(jwtAuthConfig)
 	if err != nil {
 		return nil, errors.Wrap(err, op)
 	}
 
-	authService := serviceAuth.NewAuthService(authRepo)
-	authHandlers := httpAuth.NewAuthHandlers(authService, jwtAuthConfig)
-
-	secretRepo, err := secrets.NewSecretRepository(ctx, s.conf.Postgres.DataSourceName)
+	repos, err := storage.NewRepositories(ctx, s.conf)
 	if err != nil {
 		return nil, errors.Wrap(err, op)
 	}
-	keyRepo, err := keys.NewDataKeyRepository(ctx, s.conf.Postgres.DataSourceName)
-	if err != nil {
-		return nil, errors.Wrap(err, op)
+	s.repos = repos
+
+	vaultOpts := []serviceVault.VaultServiceOption{serviceVault.WithOwnerKeys(repos.OwnerKeys)}
+	if repos.Blobs != nil {
+		vaultOpts = append(vaultOpts, serviceVault.WithBlobStore(repos.Blobs, s.conf.Blob.Threshold))
 	}
 
-	vaultService := serviceVault.NewVaultService(secretRepo, keyRepo, s.rootKey)
-	vaultHandlers := httpVault.NewVaultHandlers(vaultService, jwtAuthConfig)
+	auditService := serviceAudit.NewAuditService(repos.Events)
+	auditHandlers := httpAudit.NewAuditHandlers(auditService)
+
+	access := serviceOrg.NewVaultAccessPolicy(repos.Organizations)
+	vaultOpts = append(vaultOpts, serviceVault.WithAccessPolicy(access))
+
+	vaultService := serviceVault.NewVaultService(repos.Secrets, repos.Keys, repos.Transactor, s.rootKey, vaultOpts...)
+	vaultHandlers := httpVault.NewVaultHandlers(vaultService, jwtAuthConfig,
+		httpVault.WithAuditRecorder(auditService), httpVault.WithShutdownContext(ctx))
+
+	orgService := serviceOrg.NewOrganizationService(repos.Organizations, repos.Users, vaultService,
+		serviceOrg.WithTransactor(repos.Transactor))
+	orgHandlers := httpOrg.NewOrganizationHandlers(orgService)
+
+	shredders := auth.UserDataShredders{orgService, vaultService}
+	authService := serviceAuth.NewAuthService(repos.Users, repos.Sessions, repos.TwoFactors, shredders,
+		serviceAuth.WithRefreshTokenExpiryIn(jwtAuthConfig.RefreshTokenExpiryIn),
+		serviceAuth.WithMasterKey([]byte(s.conf.Vault.MasterKey)), serviceAuth.WithAccessTokens(repos.AccessTokens),
+		serviceAuth.WithTransactor(repos.Transactor))
+	accounts := serviceAuth.NewLoginLimiter(repos.LoginAttempts, modelAuth.AccountLoginPolicy)
+	addresses := serviceAuth.NewLoginLimiter(repos.LoginAttempts, modelAuth.AddressLoginPolicy)
+	tokenService := serviceAuth.NewAccessTokenService(repos.AccessTokens, repos.Users)
+	authHandlers := httpAuth.NewAuthHandlers(authService, jwtAuthConfig, httpAuth.WithAuditRecorder(auditService),
+		httpAuth.WithJWTKeys(keys), httpAuth.WithLoginLimiters(accounts, addresses),
+		httpAuth.WithAccessTokens(tokenService))
+	sessions := utils.WithSessionChecker(authService)
+	jwtKeys := utils.WithJWTKeys(keys)
+	opts := []utils.AuthCookieBakerOption{sessions, jwtKeys}
+	secretRoutes := append([]utils.AuthCookieBakerOption{utils.WithAccessTokenVerifier(tokenService)}, opts...)
+	if clientAuth := s.conf.Server.ClientAuth; clientAuth.CAFile != "" {
+		certs := serviceAuth.NewCertificateAuthenticator(repos.Users, certificateUsers(clientAuth))
+		secretRoutes = append(secretRoutes, utils.WithCertificateAuthenticator(certs))
+	}
 
 	r := chi.NewRouter()
 	httpAuth.MapAuthRoutes(r, authHandlers)
-	httpVault.MapVaultRoutes(r, vaultHandlers, jwtAuthConfig)
+	httpVault.MapVaultRoutes(r, vaultHandlers, jwtAuthConfig, secretRoutes...)
+	httpAudit.MapAuditRoutes(r, auditHandlers, jwtAuthConfig, opts...)
+	httpOrg.MapOrganizationRoutes(r, orgHandlers, jwtAuthConfig, opts...)
 	return r, nil
 }
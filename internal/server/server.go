@@ -2,17 +2,26 @@ package server
 
 import (
 	"context"
+	stderrors "errors"
 	"net/http"
+	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/nestjam/goph-keeper/internal/config"
+	"github.com/nestjam/goph-keeper/internal/storage"
 	"github.com/nestjam/goph-keeper/internal/vault/model"
 )
 
+const (
+	readHeaderTimeout = 10 * time.Second
+	shutdownTimeout   = 30 * time.Second
+)
+
 type Server struct {
 	conf    *config.Config
 	rootKey *model.MasterKey
+	repos   *storage.Repositories
 }
 
 func New(conf *config.Config) *Server {
@@ -25,13 +34,57 @@ func New(conf *config.Config) *Server {
 func (s *Server) Run(ctx context.Context) error {
 	const op = "run server"
 
+	c := s.conf.Server
+	tlsConfig, err := newTLSConfig(c.ClientAuth)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
 	h, err := s.mapHandlers(ctx)
 	if err != nil {
 		return errors.Wrap(err, op)
 	}
+	defer s.repos.Close()
 
-	c := s.conf.Server
-	if err := http.ListenAndServeTLS(c.Address, c.CertFile, c.KeyFile, h); err != nil {
+	srv := &http.Server{
+		Addr:              c.Address,
+		Handler:           h,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	servers := []*http.Server{srv}
+	errc := make(chan error, 2)
+	go func() {
+		errc <- srv.ListenAndServeTLS(c.CertFile, c.KeyFile)
+	}()
+	if c.AdminAddress != "" {
+		admin := &http.Server{
+			Addr:              c.AdminAddress,
+			Handler:           mapAdminHandlers(s.repos),
+			ReadHeaderTimeout: readHeaderTimeout,
+		}
+		servers = append(servers, admin)
+		go func() {
+			errc <- admin.ListenAndServe()
+		}()
+	}
+
+	var serveErr error
+	select {
+	case serveErr = <-errc:
+	case <-ctx.Done():
+	}
+
+	// every server is shut down, even if only one of them has failed, so that
+	// requests in flight are completed before storage is closed
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	errs := []error{serveErr}
+	for _, srv := range servers {
+		errs = append(errs, srv.Shutdown(shutdownCtx))
+	}
+	if err := stderrors.Join(errs...); err != nil {
 		return errors.Wrap(err, op)
 	}
 
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/config"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+func TestRun(t *testing.T) {
+	t.Run("shut down server when context is done", func(t *testing.T) {
+		certFile, keyFile := writeCert(t)
+		conf := &config.Config{
+			Server: config.ServerConfig{
+				Address:  "localhost:0",
+				CertFile: certFile,
+				KeyFile:  keyFile,
+			},
+			Storage: config.StorageConfig{Driver: config.MemoryDriver},
+			JWTAuth: config.JWTAuthConfig{SignKey: "secret"},
+		}
+		sut := New(conf)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := sut.Run(ctx)
+
+		require.NoError(t, err)
+	})
+	t.Run("serve storage stats on admin address", func(t *testing.T) {
+		certFile, keyFile := writeCert(t)
+		adminAddress := freeAddress(t)
+		conf := &config.Config{
+			Server: config.ServerConfig{
+				Address:      "localhost:0",
+				CertFile:     certFile,
+				KeyFile:      keyFile,
+				AdminAddress: adminAddress,
+			},
+			Storage: config.StorageConfig{Driver: config.MemoryDriver},
+			JWTAuth: config.JWTAuthConfig{SignKey: "secret"},
+		}
+		sut := New(conf)
+		ctx, cancel := context.WithCancel(context.Background())
+		errc := make(chan error, 1)
+		go func() { errc <- sut.Run(ctx) }()
+
+		var resp *http.Response
+		require.Eventually(t, func() bool {
+			var err error
+			resp, err = http.Get("http://" + adminAddress + statsPath) //nolint:noctx // test request
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+
+		defer resp.Body.Close()
+		// the memory storage has no stats
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		cancel()
+		require.NoError(t, <-errc)
+	})
+	t.Run("shut down server when admin server fails", func(t *testing.T) {
+		certFile, keyFile := writeCert(t)
+		address := freeAddress(t)
+		l, err := net.Listen("tcp", "localhost:0")
+		require.NoError(t, err)
+		defer l.Close()
+		conf := &config.Config{
+			Server: config.ServerConfig{
+				Address:      address,
+				CertFile:     certFile,
+				KeyFile:      keyFile,
+				AdminAddress: l.Addr().String(),
+			},
+			Storage: config.StorageConfig{Driver: config.MemoryDriver},
+			JWTAuth: config.JWTAuthConfig{SignKey: "secret"},
+		}
+		sut := New(conf)
+
+		err = sut.Run(context.Background())
+
+		require.Error(t, err)
+		assert.Never(t, func() bool {
+			conn, err := net.Dial("tcp", address)
+			if err != nil {
+				return false
+			}
+			_ = conn.Close()
+			return true
+		}, 200*time.Millisecond, 10*time.Millisecond)
+	})
+	t.Run("sign key is not set", func(t *testing.T) {
+		conf := &config.Config{
+			Storage: config.StorageConfig{Driver: config.MemoryDriver},
+		}
+		sut := New(conf)
+
+		err := sut.Run(context.Background())
+
+		require.ErrorIs(t, err, utils.ErrSignKeyNotSet)
+	})
+}
+
+func freeAddress(t *testing.T) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	return l.Addr().String()
+}
+
+func writeCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+
+	cert, key, err := utils.GenerateCert()
+	require.NoError(t, err)
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "servercert.crt")
+	keyFile = filepath.Join(dir, "servercert.key")
+	require.NoError(t, os.WriteFile(certFile, cert.Bytes(), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, key.Bytes(), 0o600))
+
+	return certFile, keyFile
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/nestjam/goph-keeper/internal/storage"
+)
+
+const statsPath = "/stats/storage"
+
+// mapAdminHandlers returns the handler of the admin listener, it is not exposed with the public API.
+func mapAdminHandlers(repos *storage.Repositories) http.Handler {
+	r := chi.NewRouter()
+	r.Get(statsPath, storageStats(repos))
+	return r
+}
+
+// storageStats writes statistics of storage connections, e.g. PostgreSQL pool state.
+func storageStats(repos *storage.Repositories) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stats, ok := repos.Stats()
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		if err := encoder.Encode(stats); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	})
+}
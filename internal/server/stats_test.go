@@ -0,0 +1,31 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/config"
+	"github.com/nestjam/goph-keeper/internal/storage"
+)
+
+func TestStorageStats(t *testing.T) {
+	t.Run("storage has no stats", func(t *testing.T) {
+		conf := &config.Config{
+			Storage: config.StorageConfig{Driver: config.MemoryDriver},
+		}
+		repos, err := storage.NewRepositories(context.Background(), conf)
+		require.NoError(t, err)
+		sut := mapAdminHandlers(repos)
+		r := httptest.NewRequest(http.MethodGet, statsPath, http.NoBody)
+		w := httptest.NewRecorder()
+
+		sut.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
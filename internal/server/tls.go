@@ -0,0 +1,74 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/config"
+)
+
+var (
+	ErrInvalidClientCA      = errors.New("client ca file has no certificates")
+	ErrUnknownClientSubject = errors.New("client certificate subject is unknown")
+)
+
+// newTLSConfig returns the config of mutual TLS that verifies client certificates by the client CA,
+// it is nil if mutual TLS is disabled. Certificates of subjects not listed in the identities are rejected
+// during the handshake once any identity is configured.
+func newTLSConfig(c config.ClientAuthConfig) (*tls.Config, error) {
+	const op = "new tls config"
+
+	if c.CAFile == "" {
+		return nil, nil
+	}
+
+	caPEM, err := os.ReadFile(c.CAFile)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.Wrap(ErrInvalidClientCA, op)
+	}
+
+	conf := &tls.Config{
+		ClientCAs:  pool,
+		ClientAuth: tls.VerifyClientCertIfGiven,
+		MinVersion: tls.VersionTLS12,
+	}
+	if c.Required {
+		conf.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	if len(c.Identities) == 0 {
+		return conf, nil
+	}
+
+	subjects := make(map[string]struct{}, len(c.Identities))
+	for _, id := range c.Identities {
+		subjects[id.Subject] = struct{}{}
+	}
+	conf.VerifyConnection = func(cs tls.ConnectionState) error {
+		// the certificate is not given, the client auth mode has allowed it already
+		if len(cs.PeerCertificates) == 0 {
+			return nil
+		}
+		if _, ok := subjects[cs.PeerCertificates[0].Subject.CommonName]; !ok {
+			return ErrUnknownClientSubject
+		}
+		return nil
+	}
+
+	return conf, nil
+}
+
+// certificateUsers returns the emails of users by the common names of their certificates.
+func certificateUsers(c config.ClientAuthConfig) map[string]string {
+	emails := make(map[string]string, len(c.Identities))
+	for _, id := range c.Identities {
+		emails[id.Subject] = id.Email
+	}
+	return emails
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/config"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+func TestNewTLSConfig(t *testing.T) {
+	caFile, ca, caKey := writeClientCA(t)
+
+	t.Run("mutual tls is disabled", func(t *testing.T) {
+		got, err := newTLSConfig(config.ClientAuthConfig{})
+
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+	t.Run("certificate of known subject", func(t *testing.T) {
+		c := config.ClientAuthConfig{
+			CAFile:     caFile,
+			Required:   true,
+			Identities: []config.ClientIdentityConfig{{Subject: "ci"}},
+		}
+		srv := newMutualTLSServer(t, c)
+		client := newMutualTLSClient(t, srv, newClientCert(t, ca, caKey, "ci"))
+
+		resp, err := client.Get(srv.URL)
+
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+	t.Run("certificate of unknown subject", func(t *testing.T) {
+		c := config.ClientAuthConfig{
+			CAFile:     caFile,
+			Identities: []config.ClientIdentityConfig{{Subject: "ci"}},
+		}
+		srv := newMutualTLSServer(t, c)
+		client := newMutualTLSClient(t, srv, newClientCert(t, ca, caKey, "phone"))
+
+		_, err := client.Get(srv.URL) //nolint:bodyclose // the request fails
+
+		require.Error(t, err)
+	})
+	t.Run("certificate of another ca", func(t *testing.T) {
+		c := config.ClientAuthConfig{CAFile: caFile}
+		srv := newMutualTLSServer(t, c)
+		_, anotherCA, anotherKey := writeClientCA(t)
+		client := newMutualTLSClient(t, srv, newClientCert(t, anotherCA, anotherKey, "ci"))
+
+		_, err := client.Get(srv.URL) //nolint:bodyclose // the request fails
+
+		require.Error(t, err)
+	})
+	t.Run("certificate is required", func(t *testing.T) {
+		c := config.ClientAuthConfig{CAFile: caFile, Required: true}
+		srv := newMutualTLSServer(t, c)
+		client := newMutualTLSClient(t, srv)
+
+		_, err := client.Get(srv.URL) //nolint:bodyclose // the request fails
+
+		require.Error(t, err)
+	})
+	t.Run("certificate is optional", func(t *testing.T) {
+		c := config.ClientAuthConfig{
+			CAFile:     caFile,
+			Identities: []config.ClientIdentityConfig{{Subject: "ci"}},
+		}
+		srv := newMutualTLSServer(t, c)
+		client := newMutualTLSClient(t, srv)
+
+		resp, err := client.Get(srv.URL)
+
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+	t.Run("invalid client ca", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "clientca.crt")
+		require.NoError(t, os.WriteFile(file, []byte("ca"), 0o600))
+
+		_, err := newTLSConfig(config.ClientAuthConfig{CAFile: file})
+
+		require.ErrorIs(t, err, ErrInvalidClientCA)
+	})
+	t.Run("client ca file not found", func(t *testing.T) {
+		c := config.ClientAuthConfig{CAFile: filepath.Join(t.TempDir(), "clientca.crt")}
+
+		_, err := newTLSConfig(c)
+
+		require.Error(t, err)
+	})
+}
+
+func writeClientCA(t *testing.T) (file string, cert, key []byte) {
+	t.Helper()
+
+	certPEM, keyPEM, err := utils.GenerateCA()
+	require.NoError(t, err)
+	file = filepath.Join(t.TempDir(), "clientca.crt")
+	require.NoError(t, os.WriteFile(file, certPEM.Bytes(), 0o600))
+
+	return file, certPEM.Bytes(), keyPEM.Bytes()
+}
+
+func newClientCert(t *testing.T, ca, caKey []byte, commonName string) tls.Certificate {
+	t.Helper()
+
+	certPEM, keyPEM, err := utils.GenerateClientCert(ca, caKey, commonName)
+	require.NoError(t, err)
+	cert, err := tls.X509KeyPair(certPEM.Bytes(), keyPEM.Bytes())
+	require.NoError(t, err)
+
+	return cert
+}
+
+func newMutualTLSServer(t *testing.T, c config.ClientAuthConfig) *httptest.Server {
+	t.Helper()
+
+	tlsConfig, err := newTLSConfig(c)
+	require.NoError(t, err)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = tlsConfig
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func newMutualTLSClient(t *testing.T, srv *httptest.Server, certs ...tls.Certificate) *http.Client {
+	t.Helper()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	client := srv.Client()
+	client.Transport = &http.Transport{
+		TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			Certificates: certs,
+			MinVersion:   tls.VersionTLS12,
+		},
+	}
+
+	return client
+}
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/config"
+	"github.com/nestjam/goph-keeper/internal/vault"
+	"github.com/nestjam/goph-keeper/internal/vault/repository/blob/local"
+	"github.com/nestjam/goph-keeper/internal/vault/repository/blob/s3"
+)
+
+var ErrUnknownBlobDriver = errors.New("unknown blob store driver")
+
+// NewBlobStore creates blob store of the driver set in config.
+// It returns nil when the driver is not set, so that secrets are kept along with their data.
+// The returned function releases the store.
+func NewBlobStore(ctx context.Context, conf *config.Config) (vault.BlobStore, func(), error) {
+	const op = "new blob store"
+
+	switch conf.Blob.Driver {
+	case "":
+		return nil, func() {}, nil
+	case config.LocalBlobDriver:
+		blobs, err := local.NewBlobStore(conf.Blob.Path)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, op)
+		}
+		return blobs, func() {}, nil
+	case config.S3BlobDriver:
+		blobs, err := s3.NewBlobStore(ctx, conf.Blob.S3)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, op)
+		}
+		return blobs, blobs.Close, nil
+	default:
+		return nil, nil, errors.Wrap(ErrUnknownBlobDriver, conf.Blob.Driver)
+	}
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/config"
+)
+
+func TestNewBlobStore(t *testing.T) {
+	t.Run("blob store is not set", func(t *testing.T) {
+		ctx := context.Background()
+		conf := &config.Config{}
+
+		got, closer, err := NewBlobStore(ctx, conf)
+
+		require.NoError(t, err)
+		assert.Nil(t, got)
+		closer()
+	})
+	t.Run("local blob store", func(t *testing.T) {
+		ctx := context.Background()
+		conf := &config.Config{
+			Blob: config.BlobConfig{
+				Driver: config.LocalBlobDriver,
+				Path:   filepath.Join(t.TempDir(), "blobs"),
+			},
+		}
+
+		got, closer, err := NewBlobStore(ctx, conf)
+
+		require.NoError(t, err)
+		t.Cleanup(closer)
+		assert.NotNil(t, got)
+		assert.DirExists(t, conf.Blob.Path)
+	})
+	t.Run("unknown blob store driver", func(t *testing.T) {
+		ctx := context.Background()
+		conf := &config.Config{
+			Blob: config.BlobConfig{Driver: "ftp"},
+		}
+
+		_, _, err := NewBlobStore(ctx, conf)
+
+		require.ErrorIs(t, err, ErrUnknownBlobDriver)
+	})
+}
@@ -0,0 +1,15 @@
+package memory
+
+import "context"
+
+type transactor struct{}
+
+// NewTransactor returns transactor of in-memory repositories. They have no
+// rollback, so the function is simply called.
+func NewTransactor() *transactor {
+	return &transactor{}
+}
+
+func (t *transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
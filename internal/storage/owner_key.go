@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/config"
+	"github.com/nestjam/goph-keeper/internal/vault"
+	vaultMemory "github.com/nestjam/goph-keeper/internal/vault/repository/inmemory"
+	"github.com/nestjam/goph-keeper/internal/vault/repository/ownerkey/local"
+)
+
+// NewOwnerKeyStore creates store of the keys of the vault owners. The keys are kept in memory along with
+// the memory storage, and in the directory set in config otherwise.
+func NewOwnerKeyStore(conf *config.Config) (vault.OwnerKeyStore, error) {
+	const op = "new owner key store"
+
+	if conf.Storage.Driver == config.MemoryDriver {
+		return vaultMemory.NewOwnerKeyStore(), nil
+	}
+
+	keys, err := local.NewOwnerKeyStore(conf.Vault.KeyPath)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return keys, nil
+}
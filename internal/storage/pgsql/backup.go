@@ -0,0 +1,283 @@
+package pgsql
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/backup"
+)
+
+type backupStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewBackupStore(pool *pgxpool.Pool) *backupStore {
+	return &backupStore{pool}
+}
+
+func (s *backupStore) Export(ctx context.Context) (*backup.Snapshot, error) {
+	const op = "export"
+
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.RepeatableRead,
+		AccessMode: pgx.ReadOnly,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	snapshot := &backup.Snapshot{}
+
+	rows, _ := tx.Query(ctx, `SELECT user_id, email, password FROM users ORDER BY user_id`)
+	snapshot.Users, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (backup.User, error) {
+		var u backup.User
+		err := row.Scan(&u.ID, &u.Email, &u.Password)
+		return u, err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	rows, _ = tx.Query(ctx, `SELECT key_id, user_id, key_data, COALESCE(encriptions_count, 0),
+COALESCE(encrypted_data_size, 0), COALESCE(is_disposed, false) FROM keys ORDER BY key_id`)
+	snapshot.Keys, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (backup.DataKey, error) {
+		var k backup.DataKey
+		err := row.Scan(&k.ID, &k.UserID, &k.Key, &k.EncryptionsCount, &k.EncryptedDataSize, &k.IsDisposed)
+		return k, err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	rows, _ = tx.Query(ctx, `SELECT secret_id, user_id, key_id, COALESCE(name, ''), data, revision,
+blob_id, blob_checksum FROM secrets ORDER BY secret_id`)
+	snapshot.Secrets, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (backup.Secret, error) {
+		var sc backup.Secret
+		err := row.Scan(&sc.ID, &sc.UserID, &sc.KeyID, &sc.Name, &sc.Data, &sc.Revision, &sc.BlobID, &sc.BlobChecksum)
+		return sc, err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	rows, _ = tx.Query(ctx, `SELECT org_id, name FROM organizations ORDER BY org_id`)
+	snapshot.Organizations, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (backup.Organization, error) {
+		var o backup.Organization
+		err := row.Scan(&o.ID, &o.Name)
+		return o, err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	rows, _ = tx.Query(ctx, `SELECT org_id, user_id, role FROM org_members ORDER BY org_id, user_id`)
+	snapshot.Members, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (backup.Member, error) {
+		var m backup.Member
+		err := row.Scan(&m.OrgID, &m.UserID, &m.Role)
+		return m, err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	rows, _ = tx.Query(ctx, `SELECT vault_id, org_id, name FROM vaults ORDER BY vault_id`)
+	snapshot.Vaults, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (backup.Vault, error) {
+		var v backup.Vault
+		err := row.Scan(&v.ID, &v.OrgID, &v.Name)
+		return v, err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	rows, _ = tx.Query(ctx, `SELECT user_id, secret, enabled, last_time_step FROM two_factor ORDER BY user_id`)
+	snapshot.TwoFactors, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (backup.TwoFactor, error) {
+		var f backup.TwoFactor
+		err := row.Scan(&f.UserID, &f.Secret, &f.Enabled, &f.LastTimeStep)
+		return f, err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	rows, _ = tx.Query(ctx, `SELECT user_id, code_hash FROM recovery_codes ORDER BY user_id, code_hash`)
+	snapshot.RecoveryCodes, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (backup.RecoveryCode, error) {
+		var c backup.RecoveryCode
+		err := row.Scan(&c.UserID, &c.Hash)
+		return c, err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	rows, _ = tx.Query(ctx, `SELECT token_id, user_id, name, token_hash, scope, vault_id, created_at, expires_at
+FROM access_tokens ORDER BY token_id`)
+	snapshot.AccessTokens, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (backup.AccessToken, error) {
+		var a backup.AccessToken
+		err := row.Scan(&a.ID, &a.UserID, &a.Name, &a.Hash, &a.Scope, &a.VaultID, &a.CreatedAt, &a.ExpiresAt)
+		a.CreatedAt = a.CreatedAt.UTC()
+		if a.ExpiresAt != nil {
+			*a.ExpiresAt = a.ExpiresAt.UTC()
+		}
+		return a, err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	rows, _ = tx.Query(ctx, `SELECT seq, user_id, action, secret_id, created_at, client_ip, user_agent, outcome,
+prev_hash, hash FROM audit_events ORDER BY seq`)
+	snapshot.AuditEvents, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (backup.AuditEvent, error) {
+		var (
+			e        backup.AuditEvent
+			userID   uuid.NullUUID
+			secretID uuid.NullUUID
+		)
+		err := row.Scan(&e.Seq, &userID, &e.Action, &secretID, &e.Time, &e.ClientIP, &e.UserAgent, &e.Outcome,
+			&e.PrevHash, &e.Hash)
+		e.UserID = userID.UUID
+		e.SecretID = secretID.UUID
+		e.Time = e.Time.UTC()
+		if len(e.PrevHash) == 0 {
+			e.PrevHash = nil
+		}
+		return e, err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return snapshot, nil
+}
+
+func (s *backupStore) Import(ctx context.Context, snapshot *backup.Snapshot) error {
+	const op = "import"
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var hasData bool
+	const query = `SELECT EXISTS(SELECT 1 FROM users) OR EXISTS(SELECT 1 FROM keys) OR EXISTS(SELECT 1 FROM secrets)
+OR EXISTS(SELECT 1 FROM organizations) OR EXISTS(SELECT 1 FROM audit_events)`
+	err = tx.QueryRow(ctx, query).Scan(&hasData)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if hasData {
+		return backup.ErrStorageNotEmpty
+	}
+
+	err = backup.VerifyAuditLog(snapshot.AuditEvents)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	for _, u := range snapshot.Users {
+		_, err = tx.Exec(ctx, `INSERT INTO users (user_id, email, password) VALUES ($1, $2, $3)`,
+			u.ID, u.Email, u.Password)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	for _, k := range snapshot.Keys {
+		_, err = tx.Exec(ctx, `INSERT INTO keys
+(key_id, user_id, key_data, encriptions_count, encrypted_data_size, is_disposed) VALUES ($1, $2, $3, $4, $5, $6)`,
+			k.ID, k.UserID, k.Key, k.EncryptionsCount, k.EncryptedDataSize, k.IsDisposed)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	for _, sc := range snapshot.Secrets {
+		_, err = tx.Exec(ctx, `INSERT INTO secrets
+(secret_id, user_id, key_id, name, data, revision, blob_id, blob_checksum) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			sc.ID, sc.UserID, sc.KeyID, sc.Name, sc.Data, sc.Revision, sc.BlobID, sc.BlobChecksum)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	// imported secrets are changes clients have not seen yet, as the migration seeds them
+	_, err = tx.Exec(ctx, `INSERT INTO secret_changes (user_id, secret_id, name, revision)
+SELECT user_id, secret_id, name, revision FROM secrets ORDER BY secret_id`)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	for _, o := range snapshot.Organizations {
+		_, err = tx.Exec(ctx, `INSERT INTO organizations (org_id, name) VALUES ($1, $2)`, o.ID, o.Name)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	for _, m := range snapshot.Members {
+		_, err = tx.Exec(ctx, `INSERT INTO org_members (org_id, user_id, role) VALUES ($1, $2, $3)`,
+			m.OrgID, m.UserID, m.Role)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	for _, v := range snapshot.Vaults {
+		_, err = tx.Exec(ctx, `INSERT INTO vaults (vault_id, org_id, name) VALUES ($1, $2, $3)`,
+			v.ID, v.OrgID, v.Name)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	for _, f := range snapshot.TwoFactors {
+		_, err = tx.Exec(ctx, `INSERT INTO two_factor (user_id, secret, enabled, last_time_step)
+VALUES ($1, $2, $3, $4)`, f.UserID, f.Secret, f.Enabled, f.LastTimeStep)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	for _, c := range snapshot.RecoveryCodes {
+		_, err = tx.Exec(ctx, `INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)`, c.UserID, c.Hash)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	for _, a := range snapshot.AccessTokens {
+		_, err = tx.Exec(ctx, `INSERT INTO access_tokens
+(token_id, user_id, name, token_hash, scope, vault_id, created_at, expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			a.ID, a.UserID, a.Name, a.Hash, a.Scope, a.VaultID, a.CreatedAt, a.ExpiresAt)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	for _, e := range snapshot.AuditEvents {
+		_, err = tx.Exec(ctx, `INSERT INTO audit_events
+(seq, user_id, action, secret_id, created_at, client_ip, user_agent, outcome, prev_hash, hash)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`, e.Seq, nullID(e.UserID), e.Action, nullID(e.SecretID), e.Time,
+			e.ClientIP, e.UserAgent, e.Outcome, e.PrevHash, e.Hash)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func nullID(id uuid.UUID) uuid.NullUUID {
+	return uuid.NullUUID{UUID: id, Valid: id != uuid.Nil}
+}
@@ -0,0 +1,38 @@
+//go:build integration
+
+package pgsql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/backup"
+	"github.com/nestjam/goph-keeper/internal/config"
+	"github.com/nestjam/goph-keeper/migration"
+)
+
+func TestBackupStore(t *testing.T) {
+	backup.StoreContract{
+		NewStore: func() (backup.Store, func()) {
+			t.Helper()
+
+			dsn := h.DataSourceName
+			migrator := migration.NewDatabaseMigrator(dsn)
+			err := migrator.Up()
+			require.NoError(t, err)
+
+			pool, err := NewPool(context.Background(), config.PostgresConfig{DataSourceName: dsn})
+			require.NoError(t, err)
+
+			closer := func() {
+				pool.Close()
+
+				migrator := migration.NewDatabaseMigrator(dsn)
+				_ = migrator.Drop()
+			}
+			return NewBackupStore(pool), closer
+		},
+	}.Test(t)
+}
@@ -0,0 +1,82 @@
+package pgsql
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/config"
+)
+
+const statementTimeoutParam = "statement_timeout"
+
+// PoolStats is a snapshot of connection pool state.
+type PoolStats struct {
+	AcquireCount         int64 `json:"acquire_count"`
+	AcquireDurationMs    int64 `json:"acquire_duration_ms"`
+	CanceledAcquireCount int64 `json:"canceled_acquire_count"`
+	EmptyAcquireCount    int64 `json:"empty_acquire_count"`
+	AcquiredConns        int32 `json:"acquired_conns"`
+	ConstructingConns    int32 `json:"constructing_conns"`
+	IdleConns            int32 `json:"idle_conns"`
+	MaxConns             int32 `json:"max_conns"`
+	TotalConns           int32 `json:"total_conns"`
+}
+
+// NewPool creates connection pool shared by repositories. Zero settings are
+// left to pgx defaults.
+func NewPool(ctx context.Context, conf config.PostgresConfig) (*pgxpool.Pool, error) {
+	const op = "new pool"
+
+	poolCfg, err := pgxpool.ParseConfig(conf.DataSourceName)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	if conf.MaxConns > 0 {
+		poolCfg.MaxConns = conf.MaxConns
+	}
+	if conf.MinConns > 0 {
+		poolCfg.MinConns = conf.MinConns
+	}
+	if conf.MaxConnLifetime > 0 {
+		poolCfg.MaxConnLifetime = conf.MaxConnLifetime
+	}
+	if conf.MaxConnIdleTime > 0 {
+		poolCfg.MaxConnIdleTime = conf.MaxConnIdleTime
+	}
+	if conf.StatementTimeout > 0 {
+		timeout := strconv.FormatInt(conf.StatementTimeout.Milliseconds(), 10)
+		poolCfg.ConnConfig.RuntimeParams[statementTimeoutParam] = timeout
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, errors.Wrap(err, op)
+	}
+
+	return pool, nil
+}
+
+// Stats returns current state of the pool.
+func Stats(pool *pgxpool.Pool) PoolStats {
+	s := pool.Stat()
+	return PoolStats{
+		AcquireCount:         s.AcquireCount(),
+		AcquireDurationMs:    s.AcquireDuration().Milliseconds(),
+		AcquiredConns:        s.AcquiredConns(),
+		CanceledAcquireCount: s.CanceledAcquireCount(),
+		ConstructingConns:    s.ConstructingConns(),
+		EmptyAcquireCount:    s.EmptyAcquireCount(),
+		IdleConns:            s.IdleConns(),
+		MaxConns:             s.MaxConns(),
+		TotalConns:           s.TotalConns(),
+	}
+}
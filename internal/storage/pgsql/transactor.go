@@ -0,0 +1,78 @@
+package pgsql
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+)
+
+type txKey struct{}
+
+// Querier is implemented by both pool and transaction.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type transactor struct {
+	pool *pgxpool.Pool
+}
+
+func NewTransactor(pool *pgxpool.Pool) *transactor {
+	return &transactor{pool}
+}
+
+func (t *transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	const op = "within transaction"
+
+	tx, err := BeginTx(ctx, t.pool)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	err = fn(context.WithValue(ctx, txKey{}, tx))
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// BeginTx starts a transaction. If the context already holds a transaction,
+// a nested one is started within it, so that it commits along with the outer one.
+func BeginTx(ctx context.Context, pool *pgxpool.Pool) (pgx.Tx, error) {
+	const op = "begin tx"
+
+	var (
+		tx  pgx.Tx
+		err error
+	)
+	if outer, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		tx, err = outer.Begin(ctx)
+	} else {
+		tx, err = pool.BeginTx(ctx, pgx.TxOptions{})
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return tx, nil
+}
+
+// QuerierFromContext returns the transaction held by the context or the pool otherwise.
+func QuerierFromContext(ctx context.Context, pool *pgxpool.Pool) Querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return pool
+}
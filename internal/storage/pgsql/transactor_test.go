@@ -0,0 +1,121 @@
+//go:build integration
+
+package pgsql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/config"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+var h *utils.PGSQLRepositoryTestHelper
+
+func TestMain(m *testing.M) {
+	h = &utils.PGSQLRepositoryTestHelper{}
+	h.Run(m)
+}
+
+func TestTransactor(t *testing.T) {
+	t.Run("commit changes made within transaction", func(t *testing.T) {
+		ctx := context.Background()
+		setupTable(t)
+		pool := newPool(t)
+		sut := NewTransactor(pool)
+
+		err := sut.WithinTransaction(ctx, func(ctx context.Context) error {
+			return insertItems(ctx, "a", "b")
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, countItems(t))
+	})
+	t.Run("rollback changes made within transaction on failure", func(t *testing.T) {
+		ctx := context.Background()
+		setupTable(t)
+		pool := newPool(t)
+		sut := NewTransactor(pool)
+
+		err := sut.WithinTransaction(ctx, func(ctx context.Context) error {
+			err := insertItems(ctx, "a")
+			require.NoError(t, err)
+			return errors.New("failed")
+		})
+
+		require.Error(t, err)
+		assert.Equal(t, 0, countItems(t))
+	})
+}
+
+func newPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	pool, err := NewPool(context.Background(), config.PostgresConfig{DataSourceName: h.DataSourceName})
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	return pool
+}
+
+func setupTable(t *testing.T) {
+	t.Helper()
+
+	ctx := context.Background()
+	pool, err := NewPool(ctx, config.PostgresConfig{DataSourceName: h.DataSourceName})
+	require.NoError(t, err)
+	defer pool.Close()
+	_, err = pool.Exec(ctx, `CREATE TABLE items(name TEXT PRIMARY KEY);`)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		pool, err := NewPool(ctx, config.PostgresConfig{DataSourceName: h.DataSourceName})
+		require.NoError(t, err)
+		defer pool.Close()
+		_, _ = pool.Exec(ctx, `DROP TABLE items;`)
+	})
+}
+
+// insertItems mimics a repository that writes in its own transaction.
+func insertItems(ctx context.Context, names ...string) error {
+	pool, err := NewPool(ctx, config.PostgresConfig{DataSourceName: h.DataSourceName})
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	tx, err := BeginTx(ctx, pool)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	for _, name := range names {
+		_, err = tx.Exec(ctx, `INSERT INTO items (name) VALUES ($1)`, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func countItems(t *testing.T) int {
+	t.Helper()
+
+	ctx := context.Background()
+	pool, err := NewPool(ctx, config.PostgresConfig{DataSourceName: h.DataSourceName})
+	require.NoError(t, err)
+	defer pool.Close()
+
+	var count int
+	err = pool.QueryRow(ctx, `SELECT COUNT(*) FROM items`).Scan(&count)
+	require.NoError(t, err)
+	return count
+}
@@ -0,0 +1,324 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/backup"
+)
+
+type backupStore struct {
+	db *sql.DB
+}
+
+func NewBackupStore(ctx context.Context, path string) (*backupStore, error) {
+	const op = "new backup store"
+
+	db, err := Open(ctx, path)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return &backupStore{db}, nil
+}
+
+func (s *backupStore) Close() {
+	if s.db == nil {
+		return
+	}
+	_ = s.db.Close()
+}
+
+func (s *backupStore) Export(ctx context.Context) (*backup.Snapshot, error) {
+	const op = "export"
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	snapshot := &backup.Snapshot{}
+
+	const usersQuery = `SELECT user_id, email, password FROM users ORDER BY user_id`
+	snapshot.Users, err = collectRows(ctx, tx, usersQuery, func(rows *sql.Rows, u *backup.User) error {
+		return rows.Scan(&u.ID, &u.Email, &u.Password)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	const keysQuery = `SELECT key_id, user_id, key_data, encriptions_count, encrypted_data_size, is_disposed
+FROM keys ORDER BY key_id`
+	snapshot.Keys, err = collectRows(ctx, tx, keysQuery, func(rows *sql.Rows, k *backup.DataKey) error {
+		return rows.Scan(&k.ID, &k.UserID, &k.Key, &k.EncryptionsCount, &k.EncryptedDataSize, &k.IsDisposed)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	const secretsQuery = `SELECT secret_id, user_id, key_id, COALESCE(name, ''), data, revision,
+blob_id, blob_checksum FROM secrets ORDER BY secret_id`
+	snapshot.Secrets, err = collectRows(ctx, tx, secretsQuery, func(rows *sql.Rows, sc *backup.Secret) error {
+		return rows.Scan(&sc.ID, &sc.UserID, &sc.KeyID, &sc.Name, &sc.Data, &sc.Revision, &sc.BlobID, &sc.BlobChecksum)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	const orgsQuery = `SELECT org_id, name FROM organizations ORDER BY org_id`
+	snapshot.Organizations, err = collectRows(ctx, tx, orgsQuery, func(rows *sql.Rows, o *backup.Organization) error {
+		return rows.Scan(&o.ID, &o.Name)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	const membersQuery = `SELECT org_id, user_id, role FROM org_members ORDER BY org_id, user_id`
+	snapshot.Members, err = collectRows(ctx, tx, membersQuery, func(rows *sql.Rows, m *backup.Member) error {
+		return rows.Scan(&m.OrgID, &m.UserID, &m.Role)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	const vaultsQuery = `SELECT vault_id, org_id, name FROM vaults ORDER BY vault_id`
+	snapshot.Vaults, err = collectRows(ctx, tx, vaultsQuery, func(rows *sql.Rows, v *backup.Vault) error {
+		return rows.Scan(&v.ID, &v.OrgID, &v.Name)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	const twoFactorsQuery = `SELECT user_id, secret, enabled, last_time_step FROM two_factor ORDER BY user_id`
+	snapshot.TwoFactors, err = collectRows(ctx, tx, twoFactorsQuery, func(rows *sql.Rows, f *backup.TwoFactor) error {
+		return rows.Scan(&f.UserID, &f.Secret, &f.Enabled, &f.LastTimeStep)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	const codesQuery = `SELECT user_id, code_hash FROM recovery_codes ORDER BY user_id, code_hash`
+	snapshot.RecoveryCodes, err = collectRows(ctx, tx, codesQuery, func(rows *sql.Rows, c *backup.RecoveryCode) error {
+		return rows.Scan(&c.UserID, &c.Hash)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	const tokensQuery = `SELECT token_id, user_id, name, token_hash, scope, vault_id, created_at, expires_at
+FROM access_tokens ORDER BY token_id`
+	snapshot.AccessTokens, err = collectRows(ctx, tx, tokensQuery, scanAccessToken)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	const eventsQuery = `SELECT seq, user_id, action, secret_id, created_at, client_ip, user_agent, outcome,
+prev_hash, hash FROM audit_events ORDER BY seq`
+	snapshot.AuditEvents, err = collectRows(ctx, tx, eventsQuery, scanAuditEvent)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return snapshot, nil
+}
+
+// scanAccessToken scans the access token with the times stored as microseconds since the epoch.
+func scanAccessToken(rows *sql.Rows, a *backup.AccessToken) error {
+	var (
+		createdAt int64
+		expiresAt sql.NullInt64
+	)
+	err := rows.Scan(&a.ID, &a.UserID, &a.Name, &a.Hash, &a.Scope, &a.VaultID, &createdAt, &expiresAt)
+	if err != nil {
+		return err
+	}
+
+	a.CreatedAt = time.UnixMicro(createdAt).UTC()
+	if expiresAt.Valid {
+		t := time.UnixMicro(expiresAt.Int64).UTC()
+		a.ExpiresAt = &t
+	}
+	return nil
+}
+
+// scanAuditEvent scans the audit event with the time stored as microseconds since the epoch.
+func scanAuditEvent(rows *sql.Rows, e *backup.AuditEvent) error {
+	var (
+		userID    uuid.NullUUID
+		secretID  uuid.NullUUID
+		createdAt int64
+	)
+	err := rows.Scan(&e.Seq, &userID, &e.Action, &secretID, &createdAt, &e.ClientIP, &e.UserAgent, &e.Outcome,
+		&e.PrevHash, &e.Hash)
+	if err != nil {
+		return err
+	}
+
+	e.UserID = userID.UUID
+	e.SecretID = secretID.UUID
+	e.Time = time.UnixMicro(createdAt).UTC()
+	if len(e.PrevHash) == 0 {
+		e.PrevHash = nil
+	}
+	return nil
+}
+
+func (s *backupStore) Import(ctx context.Context, snapshot *backup.Snapshot) error {
+	const op = "import"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var hasData bool
+	const query = `SELECT EXISTS(SELECT 1 FROM users) OR EXISTS(SELECT 1 FROM keys) OR EXISTS(SELECT 1 FROM secrets)
+OR EXISTS(SELECT 1 FROM organizations) OR EXISTS(SELECT 1 FROM audit_events)`
+	err = tx.QueryRowContext(ctx, query).Scan(&hasData)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if hasData {
+		return backup.ErrStorageNotEmpty
+	}
+
+	err = backup.VerifyAuditLog(snapshot.AuditEvents)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	for _, u := range snapshot.Users {
+		_, err = tx.ExecContext(ctx, `INSERT INTO users (user_id, email, password) VALUES (?, ?, ?)`,
+			u.ID, u.Email, u.Password)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	for _, k := range snapshot.Keys {
+		_, err = tx.ExecContext(ctx, `INSERT INTO keys
+(key_id, user_id, key_data, encriptions_count, encrypted_data_size, is_disposed) VALUES (?, ?, ?, ?, ?, ?)`,
+			k.ID, k.UserID, k.Key, k.EncryptionsCount, k.EncryptedDataSize, k.IsDisposed)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	for _, sc := range snapshot.Secrets {
+		_, err = tx.ExecContext(ctx, `INSERT INTO secrets
+(secret_id, user_id, key_id, name, data, revision, blob_id, blob_checksum) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			sc.ID, sc.UserID, sc.KeyID, sc.Name, sc.Data, sc.Revision, sc.BlobID, sc.BlobChecksum)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	// imported secrets are changes clients have not seen yet, as the migration seeds them
+	_, err = tx.ExecContext(ctx, `INSERT INTO secret_changes (user_id, secret_id, name, revision)
+SELECT user_id, secret_id, name, revision FROM secrets ORDER BY secret_id`)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	for _, o := range snapshot.Organizations {
+		_, err = tx.ExecContext(ctx, `INSERT INTO organizations (org_id, name) VALUES (?, ?)`, o.ID, o.Name)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	for _, m := range snapshot.Members {
+		_, err = tx.ExecContext(ctx, `INSERT INTO org_members (org_id, user_id, role) VALUES (?, ?, ?)`,
+			m.OrgID, m.UserID, m.Role)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	for _, v := range snapshot.Vaults {
+		_, err = tx.ExecContext(ctx, `INSERT INTO vaults (vault_id, org_id, name) VALUES (?, ?, ?)`,
+			v.ID, v.OrgID, v.Name)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	for _, f := range snapshot.TwoFactors {
+		_, err = tx.ExecContext(ctx, `INSERT INTO two_factor (user_id, secret, enabled, last_time_step)
+VALUES (?, ?, ?, ?)`, f.UserID, f.Secret, f.Enabled, f.LastTimeStep)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	for _, c := range snapshot.RecoveryCodes {
+		_, err = tx.ExecContext(ctx, `INSERT INTO recovery_codes (user_id, code_hash) VALUES (?, ?)`, c.UserID, c.Hash)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	for _, a := range snapshot.AccessTokens {
+		var expiresAt sql.NullInt64
+		if a.ExpiresAt != nil {
+			expiresAt = sql.NullInt64{Int64: a.ExpiresAt.UnixMicro(), Valid: true}
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO access_tokens
+(token_id, user_id, name, token_hash, scope, vault_id, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			a.ID, a.UserID, a.Name, a.Hash, a.Scope, a.VaultID, a.CreatedAt.UnixMicro(), expiresAt)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	for _, e := range snapshot.AuditEvents {
+		_, err = tx.ExecContext(ctx, `INSERT INTO audit_events
+(seq, user_id, action, secret_id, created_at, client_ip, user_agent, outcome, prev_hash, hash)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, e.Seq, nullID(e.UserID), e.Action, nullID(e.SecretID), e.Time.UnixMicro(),
+			e.ClientIP, e.UserAgent, e.Outcome, e.PrevHash, e.Hash)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func nullID(id uuid.UUID) uuid.NullUUID {
+	return uuid.NullUUID{UUID: id, Valid: id != uuid.Nil}
+}
+
+func collectRows[T any](ctx context.Context, e Executor, query string, scan func(*sql.Rows, *T) error) ([]T, error) {
+	const op = "collect rows"
+
+	rows, err := e.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var items []T
+	for rows.Next() {
+		var item T
+		if err := scan(rows, &item); err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,30 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/backup"
+	"github.com/nestjam/goph-keeper/migration"
+)
+
+func TestBackupStore(t *testing.T) {
+	backup.StoreContract{
+		NewStore: func() (backup.Store, func()) {
+			t.Helper()
+
+			path := filepath.Join(t.TempDir(), "goph-keeper.db")
+			migrator := migration.NewSQLiteMigrator(path)
+			err := migrator.Up()
+			require.NoError(t, err)
+
+			s, err := NewBackupStore(context.Background(), path)
+			require.NoError(t, err)
+
+			return s, s.Close
+		},
+	}.Test(t)
+}
@@ -0,0 +1,39 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+
+	"github.com/pkg/errors"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	driverName = "sqlite"
+
+	// transactions take the write lock at once, as concurrent writers would fail
+	// to upgrade their locks otherwise
+	pragmas = "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate"
+)
+
+// Open opens SQLite database stored in the file.
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	const op = "open db"
+
+	// the path is escaped, as SQLite decodes the URI and "?" or "#" in the path
+	// would start the query
+	escaped := (&url.URL{Path: path}).EscapedPath()
+	dsn := url.URL{Scheme: "file", Opaque: escaped, RawQuery: pragmas}
+	db, err := sql.Open(driverName, dsn.String())
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, errors.Wrap(err, op)
+	}
+
+	return db, nil
+}
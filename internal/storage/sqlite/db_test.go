@@ -0,0 +1,26 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen(t *testing.T) {
+	t.Run("open db stored in file with special characters in path", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "test?mode=ro#%20.db")
+
+		db, err := Open(context.Background(), path)
+
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		_, err = db.Exec(`CREATE TABLE items(name TEXT PRIMARY KEY);`)
+		require.NoError(t, err)
+		_, err = os.Stat(path)
+		assert.NoError(t, err)
+	})
+}
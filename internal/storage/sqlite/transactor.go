@@ -0,0 +1,91 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+type txKey struct{}
+
+// Executor is implemented by both database and transaction.
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Tx is a transaction started by BeginTx.
+type Tx interface {
+	Executor
+	Commit() error
+	Rollback() error
+}
+
+type transactor struct {
+	db *sql.DB
+}
+
+func NewTransactor(db *sql.DB) *transactor {
+	return &transactor{db}
+}
+
+func (t *transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	const op = "within transaction"
+
+	tx, err := BeginTx(ctx, t.db)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	err = fn(context.WithValue(ctx, txKey{}, tx))
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// BeginTx starts a transaction. If the context already holds a transaction, it is
+// returned instead and is committed or rolled back by its owner.
+func BeginTx(ctx context.Context, db *sql.DB) (Tx, error) {
+	const op = "begin tx"
+
+	if tx, ok := ctx.Value(txKey{}).(Tx); ok {
+		return nestedTx{tx}, nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return tx, nil
+}
+
+// ExecutorFromContext returns the transaction held by the context or the database otherwise.
+func ExecutorFromContext(ctx context.Context, db *sql.DB) Executor {
+	if tx, ok := ctx.Value(txKey{}).(Tx); ok {
+		return tx
+	}
+	return db
+}
+
+type nestedTx struct {
+	Executor
+}
+
+func (nestedTx) Commit() error {
+	return nil
+}
+
+func (nestedTx) Rollback() error {
+	return nil
+}
@@ -0,0 +1,97 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactor(t *testing.T) {
+	t.Run("commit changes made within transaction", func(t *testing.T) {
+		ctx := context.Background()
+		db := setupDB(t)
+		sut := NewTransactor(db)
+
+		err := sut.WithinTransaction(ctx, func(ctx context.Context) error {
+			return insertItems(ctx, db, "a", "b")
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, countItems(t, db))
+	})
+	t.Run("rollback changes made within transaction on failure", func(t *testing.T) {
+		ctx := context.Background()
+		db := setupDB(t)
+		sut := NewTransactor(db)
+
+		err := sut.WithinTransaction(ctx, func(ctx context.Context) error {
+			err := insertItems(ctx, db, "a")
+			require.NoError(t, err)
+			return errors.New("failed")
+		})
+
+		require.Error(t, err)
+		assert.Equal(t, 0, countItems(t, db))
+	})
+	t.Run("failure of nested transaction rolls back outer one", func(t *testing.T) {
+		ctx := context.Background()
+		db := setupDB(t)
+		sut := NewTransactor(db)
+
+		err := sut.WithinTransaction(ctx, func(ctx context.Context) error {
+			err := insertItems(ctx, db, "a")
+			require.NoError(t, err)
+			err = insertItems(ctx, db, "a") // violates unique constraint
+			require.Error(t, err)
+			return err
+		})
+
+		require.Error(t, err)
+		assert.Equal(t, 0, countItems(t, db))
+	})
+}
+
+func setupDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := Open(context.Background(), path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	_, err = db.Exec(`CREATE TABLE items(name TEXT PRIMARY KEY);`)
+	require.NoError(t, err)
+
+	return db
+}
+
+// insertItems mimics a repository that writes in its own transaction.
+func insertItems(ctx context.Context, db *sql.DB, names ...string) error {
+	tx, err := BeginTx(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, name := range names {
+		_, err = tx.ExecContext(ctx, `INSERT INTO items (name) VALUES (?)`, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func countItems(t *testing.T, db *sql.DB) int {
+	t.Helper()
+
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM items`).Scan(&count)
+	require.NoError(t, err)
+	return count
+}
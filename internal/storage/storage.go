@@ -0,0 +1,286 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/audit"
+	eventsMemory "github.com/nestjam/goph-keeper/internal/audit/repository/inmemory"
+	eventsPG "github.com/nestjam/goph-keeper/internal/audit/repository/pgsql"
+	eventsSQLite "github.com/nestjam/goph-keeper/internal/audit/repository/sqlite"
+	"github.com/nestjam/goph-keeper/internal/auth"
+	usersMemory "github.com/nestjam/goph-keeper/internal/auth/repository/inmemory"
+	usersPG "github.com/nestjam/goph-keeper/internal/auth/repository/pgsql"
+	usersSQLite "github.com/nestjam/goph-keeper/internal/auth/repository/sqlite"
+	"github.com/nestjam/goph-keeper/internal/backup"
+	"github.com/nestjam/goph-keeper/internal/config"
+	"github.com/nestjam/goph-keeper/internal/org"
+	orgsMemory "github.com/nestjam/goph-keeper/internal/org/repository/inmemory"
+	orgsPG "github.com/nestjam/goph-keeper/internal/org/repository/pgsql"
+	orgsSQLite "github.com/nestjam/goph-keeper/internal/org/repository/sqlite"
+	"github.com/nestjam/goph-keeper/internal/storage/memory"
+	pgstorage "github.com/nestjam/goph-keeper/internal/storage/pgsql"
+	sqlitestorage "github.com/nestjam/goph-keeper/internal/storage/sqlite"
+	"github.com/nestjam/goph-keeper/internal/vault"
+	vaultMemory "github.com/nestjam/goph-keeper/internal/vault/repository/inmemory"
+	keysPG "github.com/nestjam/goph-keeper/internal/vault/repository/pgsql/key"
+	secretsPG "github.com/nestjam/goph-keeper/internal/vault/repository/pgsql/secret"
+	keysSQLite "github.com/nestjam/goph-keeper/internal/vault/repository/sqlite/key"
+	secretsSQLite "github.com/nestjam/goph-keeper/internal/vault/repository/sqlite/secret"
+	"github.com/nestjam/goph-keeper/migration"
+)
+
+var (
+	ErrUnknownDriver      = errors.New("unknown storage driver")
+	ErrBackupNotSupported = errors.New("storage driver does not support backup")
+)
+
+// Repositories are repositories of the storage selected in config.
+type Repositories struct {
+	Users         auth.UserRepository
+	Sessions      auth.SessionRepository
+	TwoFactors    auth.TwoFactorRepository
+	LoginAttempts auth.LoginAttemptRepository
+	AccessTokens  auth.AccessTokenRepository
+	Secrets       vault.SecretRepository
+	Keys          vault.DataKeyRepository
+	Transactor    vault.Transactor
+	Events        audit.EventRepository
+	Organizations org.OrganizationRepository
+	Blobs         vault.BlobStore
+	OwnerKeys     vault.OwnerKeyStore
+	stats         func() any
+	closers       []func()
+}
+
+// Migrator applies schema migrations to the storage.
+type Migrator interface {
+	Up() error
+	Down(steps int) error
+	Version() (version uint, dirty bool, err error)
+	Force(version int) error
+}
+
+type driver struct {
+	newRepositories func(ctx context.Context, conf *config.Config) (*Repositories, error)
+	newMigrator     func(conf *config.Config) Migrator
+	newBackupStore  func(ctx context.Context, conf *config.Config) (backup.Store, func(), error)
+}
+
+var drivers = map[string]driver{
+	config.PostgresDriver: {
+		newRepositories: newPostgresRepositories,
+		newMigrator: func(conf *config.Config) Migrator {
+			return migration.NewDatabaseMigrator(conf.Postgres.DataSourceName)
+		},
+		newBackupStore: newPostgresBackupStore,
+	},
+	config.SQLiteDriver: {
+		newRepositories: newSQLiteRepositories,
+		newMigrator: func(conf *config.Config) Migrator {
+			return migration.NewSQLiteMigrator(conf.SQLite.Path)
+		},
+		newBackupStore: newSQLiteBackupStore,
+	},
+	config.MemoryDriver: {
+		newRepositories: newMemoryRepositories,
+		newMigrator: func(conf *config.Config) Migrator {
+			return noMigrator{}
+		},
+		newBackupStore: func(context.Context, *config.Config) (backup.Store, func(), error) {
+			return nil, nil, ErrBackupNotSupported
+		},
+	},
+}
+
+// NewRepositories creates repositories of the storage driver set in config.
+func NewRepositories(ctx context.Context, conf *config.Config) (*Repositories, error) {
+	const op = "new repositories"
+
+	d, err := getDriver(conf)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	repos, err := d.newRepositories(ctx, conf)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	blobs, closer, err := NewBlobStore(ctx, conf)
+	if err != nil {
+		repos.Close()
+		return nil, errors.Wrap(err, op)
+	}
+	repos.Blobs = blobs
+	repos.closers = append(repos.closers, closer)
+
+	ownerKeys, err := NewOwnerKeyStore(conf)
+	if err != nil {
+		repos.Close()
+		return nil, errors.Wrap(err, op)
+	}
+	repos.OwnerKeys = ownerKeys
+
+	return repos, nil
+}
+
+// NewMigrator creates migrator of the storage driver set in config.
+func NewMigrator(conf *config.Config) (Migrator, error) {
+	const op = "new migrator"
+
+	d, err := getDriver(conf)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return d.newMigrator(conf), nil
+}
+
+// NewBackupStore creates backup store of the storage driver set in config.
+// The returned function releases the store.
+func NewBackupStore(ctx context.Context, conf *config.Config) (backup.Store, func(), error) {
+	const op = "new backup store"
+
+	d, err := getDriver(conf)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, op)
+	}
+
+	store, closer, err := d.newBackupStore(ctx, conf)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, op)
+	}
+
+	return store, closer, nil
+}
+
+// Stats returns statistics of the storage connections, if the storage has them.
+func (r *Repositories) Stats() (any, bool) {
+	if r.stats == nil {
+		return nil, false
+	}
+	return r.stats(), true
+}
+
+func (r *Repositories) Close() {
+	for _, closer := range r.closers {
+		closer()
+	}
+}
+
+func getDriver(conf *config.Config) (driver, error) {
+	d, ok := drivers[conf.Storage.Driver]
+	if !ok {
+		return driver{}, errors.Wrap(ErrUnknownDriver, conf.Storage.Driver)
+	}
+	return d, nil
+}
+
+func newPostgresRepositories(ctx context.Context, conf *config.Config) (*Repositories, error) {
+	const op = "new postgres repositories"
+
+	pool, err := pgstorage.NewPool(ctx, conf.Postgres)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return &Repositories{
+		Users:         usersPG.NewUserRepository(pool),
+		Sessions:      usersPG.NewSessionRepository(pool),
+		TwoFactors:    usersPG.NewTwoFactorRepository(pool),
+		LoginAttempts: usersPG.NewLoginAttemptRepository(pool),
+		AccessTokens:  usersPG.NewAccessTokenRepository(pool),
+		Secrets:       secretsPG.NewSecretRepository(pool),
+		Keys:          keysPG.NewDataKeyRepository(pool),
+		Transactor:    pgstorage.NewTransactor(pool),
+		Events:        eventsPG.NewEventRepository(pool),
+		Organizations: orgsPG.NewOrganizationRepository(pool),
+		closers:       []func(){pool.Close},
+		stats: func() any {
+			return pgstorage.Stats(pool)
+		},
+	}, nil
+}
+
+func newSQLiteRepositories(ctx context.Context, conf *config.Config) (*Repositories, error) {
+	const op = "new sqlite repositories"
+
+	db, err := sqlitestorage.Open(ctx, conf.SQLite.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return &Repositories{
+		Users:         usersSQLite.NewUserRepository(db),
+		Sessions:      usersSQLite.NewSessionRepository(db),
+		TwoFactors:    usersSQLite.NewTwoFactorRepository(db),
+		LoginAttempts: usersSQLite.NewLoginAttemptRepository(db),
+		AccessTokens:  usersSQLite.NewAccessTokenRepository(db),
+		Secrets:       secretsSQLite.NewSecretRepository(db),
+		Keys:          keysSQLite.NewDataKeyRepository(db),
+		Transactor:    sqlitestorage.NewTransactor(db),
+		Events:        eventsSQLite.NewEventRepository(db),
+		Organizations: orgsSQLite.NewOrganizationRepository(db),
+		closers: []func(){func() {
+			_ = db.Close()
+		}},
+	}, nil
+}
+
+func newPostgresBackupStore(ctx context.Context, conf *config.Config) (backup.Store, func(), error) {
+	const op = "new postgres backup store"
+
+	pool, err := pgstorage.NewPool(ctx, conf.Postgres)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, op)
+	}
+
+	return pgstorage.NewBackupStore(pool), pool.Close, nil
+}
+
+func newSQLiteBackupStore(ctx context.Context, conf *config.Config) (backup.Store, func(), error) {
+	const op = "new sqlite backup store"
+
+	store, err := sqlitestorage.NewBackupStore(ctx, conf.SQLite.Path)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, op)
+	}
+
+	return store, store.Close, nil
+}
+
+func newMemoryRepositories(_ context.Context, _ *config.Config) (*Repositories, error) {
+	return &Repositories{
+		Users:         usersMemory.NewUserRepository(),
+		Sessions:      usersMemory.NewSessionRepository(),
+		TwoFactors:    usersMemory.NewTwoFactorRepository(),
+		LoginAttempts: usersMemory.NewLoginAttemptRepository(),
+		AccessTokens:  usersMemory.NewAccessTokenRepository(),
+		Secrets:       vaultMemory.NewSecretRepository(),
+		Keys:          vaultMemory.NewDataKeyRepository(),
+		Transactor:    memory.NewTransactor(),
+		Events:        eventsMemory.NewEventRepository(),
+		Organizations: orgsMemory.NewOrganizationRepository(),
+	}, nil
+}
+
+// noMigrator is used by storages that have no schema.
+type noMigrator struct{}
+
+func (noMigrator) Up() error {
+	return nil
+}
+
+func (noMigrator) Down(int) error {
+	return nil
+}
+
+func (noMigrator) Version() (uint, bool, error) {
+	return 0, false, nil
+}
+
+func (noMigrator) Force(int) error {
+	return nil
+}
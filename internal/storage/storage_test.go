@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	modelAudit "github.com/nestjam/goph-keeper/internal/audit/model"
+	"github.com/nestjam/goph-keeper/internal/auth/model"
+	"github.com/nestjam/goph-keeper/internal/config"
+	"github.com/nestjam/goph-keeper/internal/vault"
+)
+
+func TestNewRepositories(t *testing.T) {
+	t.Run("memory storage", func(t *testing.T) {
+		ctx := context.Background()
+		conf := &config.Config{
+			Storage: config.StorageConfig{Driver: config.MemoryDriver},
+		}
+
+		got, err := NewRepositories(ctx, conf)
+
+		require.NoError(t, err)
+		t.Cleanup(got.Close)
+		assertRepositories(t, got)
+	})
+	t.Run("sqlite storage", func(t *testing.T) {
+		ctx := context.Background()
+		conf := &config.Config{
+			Storage: config.StorageConfig{Driver: config.SQLiteDriver},
+			SQLite:  config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "goph-keeper.db")},
+			Vault:   config.VaultConfig{KeyPath: filepath.Join(t.TempDir(), "keys")},
+		}
+		migrator, err := NewMigrator(conf)
+		require.NoError(t, err)
+		err = migrator.Up()
+		require.NoError(t, err)
+
+		got, err := NewRepositories(ctx, conf)
+
+		require.NoError(t, err)
+		t.Cleanup(got.Close)
+		assertRepositories(t, got)
+	})
+	t.Run("unknown storage driver", func(t *testing.T) {
+		ctx := context.Background()
+		conf := &config.Config{
+			Storage: config.StorageConfig{Driver: "mongo"},
+		}
+
+		_, err := NewRepositories(ctx, conf)
+
+		require.ErrorIs(t, err, ErrUnknownDriver)
+	})
+}
+
+func TestNewMigrator(t *testing.T) {
+	t.Run("memory storage has nothing to migrate", func(t *testing.T) {
+		conf := &config.Config{
+			Storage: config.StorageConfig{Driver: config.MemoryDriver},
+		}
+		sut, err := NewMigrator(conf)
+		require.NoError(t, err)
+
+		err = sut.Up()
+
+		require.NoError(t, err)
+	})
+	t.Run("unknown storage driver", func(t *testing.T) {
+		conf := &config.Config{
+			Storage: config.StorageConfig{Driver: "mongo"},
+		}
+
+		_, err := NewMigrator(conf)
+
+		require.ErrorIs(t, err, ErrUnknownDriver)
+	})
+}
+
+func TestNewBackupStore(t *testing.T) {
+	t.Run("sqlite storage", func(t *testing.T) {
+		ctx := context.Background()
+		conf := &config.Config{
+			Storage: config.StorageConfig{Driver: config.SQLiteDriver},
+			SQLite:  config.SQLiteConfig{Path: filepath.Join(t.TempDir(), "goph-keeper.db")},
+		}
+		migrator, err := NewMigrator(conf)
+		require.NoError(t, err)
+		err = migrator.Up()
+		require.NoError(t, err)
+
+		got, closer, err := NewBackupStore(ctx, conf)
+
+		require.NoError(t, err)
+		t.Cleanup(closer)
+		_, err = got.Export(ctx)
+		require.NoError(t, err)
+	})
+	t.Run("memory storage does not support backup", func(t *testing.T) {
+		ctx := context.Background()
+		conf := &config.Config{
+			Storage: config.StorageConfig{Driver: config.MemoryDriver},
+		}
+
+		_, _, err := NewBackupStore(ctx, conf)
+
+		require.ErrorIs(t, err, ErrBackupNotSupported)
+	})
+	t.Run("unknown storage driver", func(t *testing.T) {
+		ctx := context.Background()
+		conf := &config.Config{
+			Storage: config.StorageConfig{Driver: "mongo"},
+		}
+
+		_, _, err := NewBackupStore(ctx, conf)
+
+		require.ErrorIs(t, err, ErrUnknownDriver)
+	})
+}
+
+func assertRepositories(t *testing.T, repos *Repositories) {
+	t.Helper()
+
+	ctx := context.Background()
+	user := &model.User{Email: "user@email.com", Password: "1234"}
+	userID, err := repos.Users.Register(ctx, user)
+	require.NoError(t, err)
+	assert.NotNil(t, repos.Secrets)
+	key, err := repos.Keys.GetKey(ctx, userID)
+	require.NoError(t, err)
+	assert.Nil(t, key)
+	events, err := repos.Events.ListEvents(ctx, modelAudit.EventFilter{UserID: userID})
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	orgs, err := repos.Organizations.ListOrganizations(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, orgs)
+	_, err = repos.OwnerKeys.GetOwnerKey(ctx, userID)
+	require.ErrorIs(t, err, vault.ErrOwnerKeyNotFound)
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"net/http"
+	"net/url"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-resty/resty/v2"
+
+	httpAuth "github.com/nestjam/goph-keeper/internal/auth/delivery/http"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+const passwordURL = "password"
+
+type changePasswordCommand struct {
+	client          *resty.Client
+	jwtCookie       *http.Cookie
+	address         string
+	currentPassword string
+	newPassword     string
+}
+
+func newChangePasswordCommand(address string, jwt *http.Cookie, currentPassword, newPassword string,
+	client *resty.Client) changePasswordCommand {
+	return changePasswordCommand{
+		address:         address,
+		jwtCookie:       jwt,
+		currentPassword: currentPassword,
+		newPassword:     newPassword,
+		client:          client,
+	}
+}
+
+func (c changePasswordCommand) execute() tea.Msg {
+	url, err := url.JoinPath(c.address, passwordURL)
+	if err != nil {
+		return errMsg{err}
+	}
+
+	req := httpAuth.ChangePasswordRequest{
+		CurrentPassword: c.currentPassword,
+		NewPassword:     c.newPassword,
+	}
+	resp, err := c.client.R().SetBody(req).SetCookie(c.jwtCookie).SetError(&httpAuth.ErrorResponse{}).Put(url)
+	if err != nil {
+		return errMsg{err}
+	}
+
+	return passwordResult(resp)
+}
+
+// passwordResult returns the message of the response to the change of the password.
+func passwordResult(resp *resty.Response) tea.Msg {
+	if resp.StatusCode() == http.StatusTooManyRequests {
+		return loginLockedMsg{retryAfter: retryAfter(resp)}
+	}
+
+	if resp.IsSuccess() {
+		jwtCookie := findCookie(resp.Cookies(), utils.JWTCookieName)
+		if jwtCookie == nil {
+			return errMsg{ErrAuthTokenNotFound}
+		}
+
+		return passwordChangedMsg{
+			jwtCookie:     jwtCookie,
+			refreshCookie: findCookie(resp.Cookies(), utils.RefreshCookieName),
+		}
+	}
+
+	return passwordFailedMsg{statusCode: resp.StatusCode(), message: errorMessage(resp)}
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	httpAuth "github.com/nestjam/goph-keeper/internal/auth/delivery/http"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+func TestChangePasswordCommand(t *testing.T) {
+	jwt := &http.Cookie{Name: utils.JWTCookieName, Value: "jwt"}
+
+	t.Run("password changed", func(t *testing.T) {
+		var (
+			gotURL    string
+			gotMethod string
+			gotCookie *http.Cookie
+			gotReq    httpAuth.ChangePasswordRequest
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotURL = r.URL.String()
+			gotMethod = r.Method
+			gotCookie, _ = r.Cookie(utils.JWTCookieName)
+			_ = json.NewDecoder(r.Body).Decode(&gotReq)
+
+			http.SetCookie(w, &http.Cookie{Name: utils.JWTCookieName, Value: "new jwt"})
+			http.SetCookie(w, &http.Cookie{Name: utils.RefreshCookieName, Value: "refresh"})
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		sut := newChangePasswordCommand(server.URL, jwt, "1234", "5678", resty.New())
+
+		got := sut.execute()
+
+		assert.Equal(t, "/password", gotURL)
+		assert.Equal(t, http.MethodPut, gotMethod)
+		assert.Equal(t, "jwt", gotCookie.Value)
+		assert.Equal(t, httpAuth.ChangePasswordRequest{CurrentPassword: "1234", NewPassword: "5678"}, gotReq)
+		msg, ok := got.(passwordChangedMsg)
+		require.True(t, ok)
+		assert.Equal(t, "new jwt", msg.jwtCookie.Value)
+		assert.Equal(t, "refresh", msg.refreshCookie.Value)
+	})
+	t.Run("current password is wrong", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":"invalid password"}`))
+		}))
+		defer server.Close()
+		sut := newChangePasswordCommand(server.URL, jwt, "1234", "5678", resty.New())
+
+		got := sut.execute()
+
+		assert.Equal(t, passwordFailedMsg{statusCode: http.StatusForbidden, message: "invalid password"}, got)
+	})
+	t.Run("change is locked", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+		sut := newChangePasswordCommand(server.URL, jwt, "1234", "5678", resty.New())
+
+		got := sut.execute()
+
+		assert.Equal(t, loginLockedMsg{retryAfter: time.Minute}, got)
+	})
+	t.Run("jwt cookie not found", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		sut := newChangePasswordCommand(server.URL, jwt, "1234", "5678", resty.New())
+
+		got := sut.execute()
+
+		assert.Equal(t, errMsg{ErrAuthTokenNotFound}, got)
+	})
+	t.Run("failed to connect server", func(t *testing.T) {
+		server := httptest.NewServer(nil)
+		serverURL := server.URL
+		server.Close()
+		sut := newChangePasswordCommand(serverURL, jwt, "1234", "5678", resty.New())
+
+		got := sut.execute()
+
+		assert.IsType(t, errMsg{}, got)
+	})
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"net/http"
+	"net/url"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-resty/resty/v2"
+
+	httpAuth "github.com/nestjam/goph-keeper/internal/auth/delivery/http"
+)
+
+const confirmTwoFactorURL = "2fa/confirm"
+
+type confirmTwoFactorCommand struct {
+	client    *resty.Client
+	jwtCookie *http.Cookie
+	address   string
+	code      string
+}
+
+func newConfirmTwoFactorCommand(address string, jwt *http.Cookie, code string,
+	client *resty.Client) confirmTwoFactorCommand {
+	return confirmTwoFactorCommand{
+		address:   address,
+		jwtCookie: jwt,
+		code:      code,
+		client:    client,
+	}
+}
+
+func (c confirmTwoFactorCommand) execute() tea.Msg {
+	url, err := url.JoinPath(c.address, confirmTwoFactorURL)
+	if err != nil {
+		return errMsg{err}
+	}
+
+	req := httpAuth.ConfirmTwoFactorRequest{Code: c.code}
+	resp, err := c.client.R().SetBody(req).SetCookie(c.jwtCookie).SetError(&httpAuth.ErrorResponse{}).Post(url)
+	if err != nil {
+		return errMsg{err}
+	}
+
+	if resp.IsSuccess() {
+		return twoFactorConfirmedMsg{}
+	}
+
+	return twoFactorFailedMsg{statusCode: resp.StatusCode(), message: errorMessage(resp)}
+}
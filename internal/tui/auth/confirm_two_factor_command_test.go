@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+
+	httpAuth "github.com/nestjam/goph-keeper/internal/auth/delivery/http"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+func TestConfirmTwoFactorCommand(t *testing.T) {
+	t.Run("two factor confirmed", func(t *testing.T) {
+		var (
+			gotURL string
+			gotReq httpAuth.ConfirmTwoFactorRequest
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotURL = r.URL.String()
+			_ = json.NewDecoder(r.Body).Decode(&gotReq)
+
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		jwt := &http.Cookie{Name: utils.JWTCookieName, Value: "jwt"}
+		sut := newConfirmTwoFactorCommand(server.URL, jwt, "123456", resty.New())
+
+		got := sut.execute()
+
+		assert.Equal(t, "/2fa/confirm", gotURL)
+		assert.Equal(t, "123456", gotReq.Code)
+		assert.Equal(t, twoFactorConfirmedMsg{}, got)
+	})
+	t.Run("invalid code", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+		sut := newConfirmTwoFactorCommand(server.URL, &http.Cookie{Name: utils.JWTCookieName}, "1", resty.New())
+
+		got := sut.execute()
+
+		assert.Equal(t, twoFactorFailedMsg{statusCode: http.StatusBadRequest, message: "bad request"}, got)
+	})
+	t.Run("failed to connect server", func(t *testing.T) {
+		server := httptest.NewServer(nil)
+		serverURL := server.URL
+		server.Close()
+		sut := newConfirmTwoFactorCommand(serverURL, &http.Cookie{Name: utils.JWTCookieName}, "1", resty.New())
+
+		got := sut.execute()
+
+		assert.IsType(t, errMsg{}, got)
+	})
+}
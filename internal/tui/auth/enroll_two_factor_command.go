@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"net/http"
+	"net/url"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-resty/resty/v2"
+
+	httpAuth "github.com/nestjam/goph-keeper/internal/auth/delivery/http"
+)
+
+const twoFactorURL = "2fa"
+
+type enrollTwoFactorCommand struct {
+	client    *resty.Client
+	jwtCookie *http.Cookie
+	address   string
+}
+
+func newEnrollTwoFactorCommand(address string, jwt *http.Cookie, client *resty.Client) enrollTwoFactorCommand {
+	return enrollTwoFactorCommand{
+		address:   address,
+		jwtCookie: jwt,
+		client:    client,
+	}
+}
+
+func (c enrollTwoFactorCommand) execute() tea.Msg {
+	url, err := url.JoinPath(c.address, twoFactorURL)
+	if err != nil {
+		return errMsg{err}
+	}
+
+	var res httpAuth.EnrollTwoFactorResponse
+	resp, err := c.client.R().SetResult(&res).SetCookie(c.jwtCookie).SetError(&httpAuth.ErrorResponse{}).Post(url)
+	if err != nil {
+		return errMsg{err}
+	}
+
+	if resp.IsSuccess() {
+		return twoFactorEnrolledMsg{uri: res.OTPAuthURI, recoveryCodes: res.RecoveryCodes}
+	}
+
+	return twoFactorFailedMsg{statusCode: resp.StatusCode(), message: errorMessage(resp)}
+}
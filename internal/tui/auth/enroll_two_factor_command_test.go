@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+func TestEnrollTwoFactorCommand(t *testing.T) {
+	t.Run("two factor enrolled", func(t *testing.T) {
+		var (
+			gotURL    string
+			gotCookie *http.Cookie
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotURL = r.URL.String()
+			gotCookie, _ = r.Cookie(utils.JWTCookieName)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"otpauth_uri":"otpauth://totp/test","recovery_codes":["code"]}`))
+		}))
+		defer server.Close()
+		jwt := &http.Cookie{Name: utils.JWTCookieName, Value: "jwt"}
+		sut := newEnrollTwoFactorCommand(server.URL, jwt, resty.New())
+
+		got := sut.execute()
+
+		assert.Equal(t, "/2fa", gotURL)
+		assert.Equal(t, "jwt", gotCookie.Value)
+		want := twoFactorEnrolledMsg{uri: "otpauth://totp/test", recoveryCodes: []string{"code"}}
+		assert.Equal(t, want, got)
+	})
+	t.Run("two factor has already been enabled", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusConflict)
+		}))
+		defer server.Close()
+		sut := newEnrollTwoFactorCommand(server.URL, &http.Cookie{Name: utils.JWTCookieName}, resty.New())
+
+		got := sut.execute()
+
+		assert.Equal(t, twoFactorFailedMsg{statusCode: http.StatusConflict, message: "conflict"}, got)
+	})
+	t.Run("failed to connect server", func(t *testing.T) {
+		server := httptest.NewServer(nil)
+		serverURL := server.URL
+		server.Close()
+		sut := newEnrollTwoFactorCommand(serverURL, &http.Cookie{Name: utils.JWTCookieName}, resty.New())
+
+		got := sut.execute()
+
+		assert.IsType(t, errMsg{}, got)
+	})
+}
@@ -4,4 +4,5 @@ import "errors"
 
 var (
 	ErrAuthTokenNotFound = errors.New("auth cookie not found")
+	ErrLoginLocked       = errors.New("too many failed logins")
 )
@@ -3,6 +3,9 @@ package auth
 import (
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/go-resty/resty/v2"
@@ -38,11 +41,20 @@ func (c loginCommand) execute() tea.Msg {
 		return errMsg{err}
 	}
 
-	resp, err := c.client.R().SetBody(req).Post(url)
+	var res httpAuth.LoginChallengeResponse
+	resp, err := c.client.R().SetBody(req).SetResult(&res).SetError(&httpAuth.ErrorResponse{}).Post(url)
 	if err != nil {
 		return errMsg{err}
 	}
 
+	// the session is started once the user verifies the second factor
+	if resp.StatusCode() == http.StatusAccepted {
+		return secondFactorRequiredMsg{challengeToken: res.ChallengeToken}
+	}
+	if resp.StatusCode() == http.StatusTooManyRequests {
+		return loginLockedMsg{retryAfter: retryAfter(resp)}
+	}
+
 	if resp.IsSuccess() {
 		jwtCookie := findCookie(resp.Cookies(), utils.JWTCookieName)
 		if jwtCookie == nil {
@@ -50,11 +62,29 @@ func (c loginCommand) execute() tea.Msg {
 		}
 
 		return loginCompletedMsg{
-			jwtCookie: jwtCookie,
+			jwtCookie:     jwtCookie,
+			refreshCookie: findCookie(resp.Cookies(), utils.RefreshCookieName),
 		}
 	}
 
-	return loginFailedMsg{resp.StatusCode()}
+	return loginFailedMsg{statusCode: resp.StatusCode(), message: errorMessage(resp)}
+}
+
+// errorMessage returns the message of the error response, the status text if the response has no message.
+func errorMessage(resp *resty.Response) string {
+	if res, ok := resp.Error().(*httpAuth.ErrorResponse); ok && res.Error != "" {
+		return res.Error
+	}
+	return strings.ToLower(http.StatusText(resp.StatusCode()))
+}
+
+// retryAfter returns how long the server asks to wait before the next login.
+func retryAfter(resp *resty.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header().Get("Retry-After"))
+	if err != nil {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
 }
 
 func findCookie(cookies []*http.Cookie, name string) *http.Cookie {
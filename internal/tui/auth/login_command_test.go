@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/stretchr/testify/assert"
@@ -21,6 +22,7 @@ func TestLoginCommand(t *testing.T) {
 				Name: utils.JWTCookieName,
 			}
 			http.SetCookie(w, jwtCookie)
+			http.SetCookie(w, &http.Cookie{Name: utils.RefreshCookieName, Value: "refresh"})
 
 			w.WriteHeader(http.StatusOK)
 		}))
@@ -34,6 +36,20 @@ func TestLoginCommand(t *testing.T) {
 		msg, ok := got.(loginCompletedMsg)
 		assert.True(t, ok)
 		assert.NotNil(t, msg.jwtCookie)
+		assert.Equal(t, "refresh", msg.refreshCookie.Value)
+	})
+	t.Run("second factor required", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"challenge_token":"challenge"}`))
+		}))
+		defer server.Close()
+		sut := newLoginCommand(server.URL, "user@email.com", "1234", resty.New())
+
+		got := sut.execute()
+
+		assert.Equal(t, secondFactorRequiredMsg{challengeToken: "challenge"}, got)
 	})
 	t.Run("invalid server address", func(t *testing.T) {
 		address := string([]byte{0x7f}) // ASCII control character
@@ -65,6 +81,33 @@ func TestLoginCommand(t *testing.T) {
 		msg, ok := got.(loginFailedMsg)
 		assert.True(t, ok)
 		assert.Equal(t, http.StatusInternalServerError, msg.statusCode)
+		assert.Equal(t, "internal server error", msg.message)
+	})
+	t.Run("invalid credentials", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid email or password"}`))
+		}))
+		defer server.Close()
+		sut := newLoginCommand(server.URL, "user@email.com", "1234", resty.New())
+
+		got := sut.execute()
+
+		want := loginFailedMsg{statusCode: http.StatusUnauthorized, message: "invalid email or password"}
+		assert.Equal(t, want, got)
+	})
+	t.Run("login is locked", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+		sut := newLoginCommand(server.URL, "user@email.com", "1234", resty.New())
+
+		got := sut.execute()
+
+		assert.Equal(t, loginLockedMsg{retryAfter: 30 * time.Second}, got)
 	})
 	t.Run("jwt cookie not found", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
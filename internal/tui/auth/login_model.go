@@ -3,6 +3,7 @@ package auth
 import (
 	"fmt"
 	"net/http"
+	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/help"
@@ -10,6 +11,7 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
 
 	"github.com/nestjam/goph-keeper/internal/tui/vault"
 	"github.com/nestjam/goph-keeper/internal/tui/vault/cache"
@@ -18,9 +20,18 @@ import (
 const (
 	enterEmail         = "Enter email"
 	enterServerAddress = "Enter server address"
+	enterLoginCode     = "Enter code from authenticator app or recovery code"
 )
 
-var choices = []string{"login", "regiser"}
+const (
+	loginChoice = iota
+	registerChoice
+	enableTwoFactorChoice
+	changePasswordChoice
+	resetPasswordChoice
+)
+
+var choices = []string{"login", "regiser", "login and enable 2fa", "login and change password", "reset password"}
 
 type loginKeyMap struct {
 	Quit     key.Binding
@@ -38,17 +49,22 @@ func (k loginKeyMap) FullHelp() [][]key.Binding {
 }
 
 type loginModel struct {
-	err          error
-	client       *resty.Client
-	help         help.Model
-	address      string
-	email        string
-	password     string
-	BuildVersion string
-	BuildDate    string
-	keys         loginKeyMap
-	textinput    textinput.Model
-	cursor       int
+	err     error
+	client  *resty.Client
+	tokens  *tokenRefresher
+	help    help.Model
+	address string
+	// challengeToken is set while the login waits for the code of the second factor.
+	challengeToken string
+	email          string
+	password       string
+	BuildVersion   string
+	BuildDate      string
+	// CacheDir is the directory of the local caches of the users, the cache is kept in memory only if it is empty.
+	CacheDir  string
+	keys      loginKeyMap
+	textinput textinput.Model
+	cursor    int
 }
 
 func NewLoginModel(address string, client *resty.Client) loginModel {
@@ -81,6 +97,7 @@ func NewLoginModel(address string, client *resty.Client) loginModel {
 	return loginModel{
 		address:   address,
 		client:    client,
+		tokens:    newTokenRefresher(client),
 		keys:      keys,
 		help:      help.New(),
 		textinput: ti,
@@ -97,23 +114,49 @@ func (m loginModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.help.Width = msg.Width
 	case tea.KeyMsg:
 		return handleKeyMsg(msg, m)
+	case secondFactorRequiredMsg:
+		m.challengeToken = msg.challengeToken
+		m.textinput.SetValue("")
+		m.textinput.Placeholder = enterLoginCode
+		return m, nil
 	case loginCompletedMsg:
-		cache := cache.New()
-		cmd := listSecrets(m.address, msg.jwtCookie, m.client)
-		return vault.NewSecretsModel(m.address, msg.jwtCookie, cache, m.client), cmd
+		m.challengeToken = ""
+		m.startSession(msg.jwtCookie, msg.refreshCookie)
+		if m.cursor == enableTwoFactorChoice {
+			model := newTwoFactorModel(m, msg.jwtCookie)
+			return model, model.Init()
+		}
+		if m.cursor == changePasswordChoice {
+			model := newChangePasswordModel(m, msg.jwtCookie)
+			return model, model.Init()
+		}
+		return m, openStore(m.storePath(), m.password, msg.jwtCookie)
 	case registerCompletedMsg:
-		cache := cache.New()
-		cmd := listSecrets(m.address, msg.jwtCookie, m.client)
-		return vault.NewSecretsModel(m.address, msg.jwtCookie, cache, m.client), cmd
-	case loginFailedMsg, registerFailedMsg:
+		m.startSession(msg.jwtCookie, msg.refreshCookie)
+		return m, openStore(m.storePath(), m.password, msg.jwtCookie)
+	case storeOpenedMsg:
+		model := vault.NewVaultsModel(m.address, msg.jwtCookie, msg.store, m.client)
+		return model, model.Init()
+	case openStoreFailedMsg:
 		{
-			m.password = ""
-			m.email = ""
-			acceptServerAddress(&m, m.address)
+			m.err = msg.err
+			return m, nil
 		}
+	case loginFailedMsg:
+		m.fail(errors.New(msg.message))
+	case registerFailedMsg:
+		m.fail(errors.New(msg.message))
+	case verifyLoginFailedMsg:
+		m.fail(errors.New(msg.message))
+	case loginLockedMsg:
+		m.fail(errors.Wrapf(ErrLoginLocked, "try again in %s", msg.retryAfter))
 	case errMsg:
 		{
 			m.err = msg.err
+			if m.CacheDir != "" && isValid(m.address, m.email, m.password) {
+				// secrets of the user are available from the local cache while the server is unavailable
+				return m, openOfflineStore(m.storePath(), m.password, msg.err)
+			}
 			return m, nil
 		}
 	}
@@ -129,13 +172,25 @@ func handleKeyMsg(msg tea.KeyMsg, m loginModel) (tea.Model, tea.Cmd) {
 		return m, tea.Quit
 	case key.Matches(msg, m.keys.Continue):
 		input := m.textinput.Value()
+		if m.challengeToken != "" {
+			if input == "" {
+				return m, nil
+			}
+			m.textinput.SetValue("")
+			return m, verifyLogin(m.address, m.challengeToken, input, m.client)
+		}
 		acceptInput(&m, input)
 
+		// the forgotten password is reset instead of entering it
+		if m.cursor == resetPasswordChoice && m.address != "" && m.email != "" {
+			model := newResetPasswordModel(m)
+			return model, model.Init()
+		}
 		if isValid(m.address, m.email, m.password) {
-			if m.cursor == 0 {
+			if m.cursor == loginChoice || m.cursor == enableTwoFactorChoice || m.cursor == changePasswordChoice {
 				return m, login(m.address, m.email, m.password, m.client)
 			}
-			if m.cursor == 1 {
+			if m.cursor == registerChoice {
 				return m, register(m.address, m.email, m.password, m.client)
 			}
 		}
@@ -213,14 +268,49 @@ func login(addr, email, password string, client *resty.Client) tea.Cmd {
 	return cmd.execute
 }
 
+func verifyLogin(addr, challengeToken, code string, client *resty.Client) tea.Cmd {
+	cmd := newVerifyLoginCommand(addr, challengeToken, code, client)
+	return cmd.execute
+}
+
 func register(addr, email, password string, client *resty.Client) tea.Cmd {
 	cmd := newRegisterCommand(addr, email, password, client)
 	return cmd.execute
 }
 
-func listSecrets(addr string, jwt *http.Cookie, client *resty.Client) tea.Cmd {
-	cmd := vault.NewListSecretsCommand(addr, jwt, client)
-	return cmd.Execute
+func openStore(path, password string, jwt *http.Cookie) tea.Cmd {
+	cmd := newOpenStoreCommand(path, password, jwt)
+	return cmd.execute
+}
+
+func openOfflineStore(path, password string, loginErr error) tea.Cmd {
+	cmd := newOpenOfflineStoreCommand(path, password, loginErr)
+	return cmd.execute
+}
+
+// fail shows why the login failed and starts the login over.
+func (m *loginModel) fail(err error) {
+	m.err = err
+	m.challengeToken = ""
+	m.password = ""
+	m.email = ""
+	acceptServerAddress(m, m.address)
+}
+
+// startSession makes the client refresh the access token of the session while the app runs.
+func (m loginModel) startSession(jwt, refresh *http.Cookie) {
+	if refresh == nil {
+		return
+	}
+	m.tokens.start(m.address, jwt, refresh)
+}
+
+// storePath returns the file of the local cache of the user.
+func (m loginModel) storePath() string {
+	if m.CacheDir == "" {
+		return ""
+	}
+	return filepath.Join(m.CacheDir, cache.FileName(m.address, m.email))
 }
 
 func acceptInput(m *loginModel, input string) {
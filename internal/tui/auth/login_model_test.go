@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -13,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/nestjam/goph-keeper/internal/tui/vault"
+	"github.com/nestjam/goph-keeper/internal/tui/vault/cache"
 )
 
 func TestNewLoginModel(t *testing.T) {
@@ -137,10 +139,10 @@ func TestLoginModel_Update(t *testing.T) {
 
 		model, cmd := sut.Update(msg)
 
-		_, ok := model.(vault.SecretsModel)
+		_, ok := model.(loginModel)
 		assert.True(t, ok)
-		listCommand := vault.NewListSecretsCommand("", nil, client)
-		assertEqualCmd(t, listCommand.Execute, cmd)
+		openCommand := openStoreCommand{}
+		assertEqualCmd(t, openCommand.execute, cmd)
 	})
 	t.Run("register completed", func(t *testing.T) {
 		client := resty.New()
@@ -150,20 +152,60 @@ func TestLoginModel_Update(t *testing.T) {
 
 		model, cmd := sut.Update(msg)
 
-		_, ok := model.(vault.SecretsModel)
+		_, ok := model.(loginModel)
 		assert.True(t, ok)
-		listCommand := vault.NewListSecretsCommand("", nil, client)
-		assertEqualCmd(t, listCommand.Execute, cmd)
+		openCommand := openStoreCommand{}
+		assertEqualCmd(t, openCommand.execute, cmd)
+	})
+	t.Run("store opened", func(t *testing.T) {
+		client := resty.New()
+		m := NewLoginModel(address, client)
+		sut := tea.Model(m)
+		msg := storeOpenedMsg{store: cache.NewStore(), jwtCookie: &http.Cookie{}}
+
+		model, cmd := sut.Update(msg)
+
+		_, ok := model.(vault.VaultsModel)
+		assert.True(t, ok)
+		assert.NotNil(t, cmd)
+	})
+	t.Run("failed to open store", func(t *testing.T) {
+		client := resty.New()
+		sut := NewLoginModel(address, client)
+		msg := openStoreFailedMsg{cache.ErrInvalidPassword}
+
+		model, cmd := sut.Update(msg)
+
+		got, _ := model.(loginModel)
+		assert.Equal(t, msg.err, got.err)
+		assert.Nil(t, cmd)
 	})
 	t.Run("error on login", func(t *testing.T) {
 		client := resty.New()
 		sut := NewLoginModel(address, client)
 		msg := errMsg{errors.New("error")}
 
-		model, _ := sut.Update(msg)
+		model, cmd := sut.Update(msg)
+
+		got, _ := model.(loginModel)
+		assert.Equal(t, msg.err, got.err)
+		assert.Nil(t, cmd)
+	})
+	t.Run("error on login with local cache", func(t *testing.T) {
+		client := resty.New()
+		m := NewLoginModel(address, client)
+		m.email = "user@mail.com"
+		m.password = "1234"
+		m.CacheDir = t.TempDir()
+		sut := tea.Model(m)
+		msg := errMsg{errors.New("error")}
+
+		model, cmd := sut.Update(msg)
 
 		got, _ := model.(loginModel)
 		assert.Equal(t, msg.err, got.err)
+		openCommand := openStoreCommand{}
+		assertEqualCmd(t, openCommand.execute, cmd)
 	})
 	t.Run("failed to login", func(t *testing.T) {
 		client := resty.New()
@@ -172,13 +214,31 @@ func TestLoginModel_Update(t *testing.T) {
 		m.email = "user@mail.com"
 		m.password = "1234"
 		sut := tea.Model(m)
-		msg := loginFailedMsg{statusCode: http.StatusUnauthorized}
+		msg := loginFailedMsg{statusCode: http.StatusUnauthorized, message: "invalid email or password"}
 
 		model, _ := sut.Update(msg)
 
 		got, _ := model.(loginModel)
 		assert.Empty(t, got.email)
 		assert.Empty(t, got.password)
+		assert.Contains(t, got.View(), "invalid email or password")
+	})
+	t.Run("login is locked", func(t *testing.T) {
+		client := resty.New()
+		m := NewLoginModel(address, client)
+		m.address = "localhost:8080"
+		m.email = "user@mail.com"
+		m.password = "1234"
+		sut := tea.Model(m)
+		msg := loginLockedMsg{retryAfter: time.Minute}
+
+		model, _ := sut.Update(msg)
+
+		got, _ := model.(loginModel)
+		assert.Empty(t, got.email)
+		assert.Empty(t, got.password)
+		require.ErrorIs(t, got.err, ErrLoginLocked)
+		assert.Contains(t, got.View(), "try again in 1m0s")
 	})
 	t.Run("failed to register", func(t *testing.T) {
 		client := resty.New()
@@ -187,13 +247,14 @@ func TestLoginModel_Update(t *testing.T) {
 		m.email = "user@mail.com"
 		m.password = "1234"
 		sut := tea.Model(m)
-		msg := registerFailedMsg{statusCode: http.StatusUnauthorized}
+		msg := registerFailedMsg{statusCode: http.StatusConflict, message: "email has already been registered"}
 
 		model, _ := sut.Update(msg)
 
 		got, _ := model.(loginModel)
 		assert.Empty(t, got.email)
 		assert.Empty(t, got.password)
+		assert.Contains(t, got.View(), "email has already been registered")
 	})
 	t.Run("key down pressed from login choice", func(t *testing.T) {
 		msg := tea.KeyMsg{Type: tea.KeyDown}
@@ -211,7 +272,7 @@ func TestLoginModel_Update(t *testing.T) {
 	})
 	t.Run("key up pressed from login choice", func(t *testing.T) {
 		msg := tea.KeyMsg{Type: tea.KeyUp}
-		const want = 1
+		const want = resetPasswordChoice
 		client := resty.New()
 		sut := NewLoginModel(address, client)
 
@@ -225,10 +286,25 @@ func TestLoginModel_Update(t *testing.T) {
 	})
 	t.Run("key down pressed from register choice", func(t *testing.T) {
 		msg := tea.KeyMsg{Type: tea.KeyDown}
-		const want = 0
+		const want = enableTwoFactorChoice
 		client := resty.New()
 		sut := NewLoginModel(address, client)
-		sut.cursor = 1
+		sut.cursor = registerChoice
+
+		model, cmd := sut.Update(msg)
+
+		m, ok := model.(loginModel)
+		assert.True(t, ok)
+		got := m.cursor
+		assert.Equal(t, want, got)
+		assert.Nil(t, cmd)
+	})
+	t.Run("key down pressed from last choice", func(t *testing.T) {
+		msg := tea.KeyMsg{Type: tea.KeyDown}
+		const want = loginChoice
+		client := resty.New()
+		sut := NewLoginModel(address, client)
+		sut.cursor = resetPasswordChoice
 
 		model, cmd := sut.Update(msg)
 
@@ -270,6 +346,96 @@ func TestLoginModel_Update(t *testing.T) {
 		registerCmd := registerCommand{}
 		assertEqualCmd(t, registerCmd.execute, cmd)
 	})
+	t.Run("second factor required", func(t *testing.T) {
+		client := resty.New()
+		sut := NewLoginModel(address, client)
+		msg := secondFactorRequiredMsg{challengeToken: "challenge"}
+
+		model, cmd := sut.Update(msg)
+
+		got, _ := model.(loginModel)
+		assert.Equal(t, "challenge", got.challengeToken)
+		assert.Equal(t, enterLoginCode, got.textinput.Placeholder)
+		assert.Nil(t, cmd)
+	})
+	t.Run("user entered code of second factor", func(t *testing.T) {
+		client := resty.New()
+		m := NewLoginModel(address, client)
+		m.email = "user@mail.com"
+		m.password = "1234"
+		m.challengeToken = "challenge"
+		sut := tea.Model(m)
+		sut, _ = sut.Update(tea.KeyMsg{Runes: []rune("123456")})
+		msg := tea.KeyMsg{Type: tea.KeyEnter}
+
+		model, cmd := sut.Update(msg)
+
+		got, _ := model.(loginModel)
+		assert.Empty(t, got.textinput.Value())
+		verifyCmd := verifyLoginCommand{}
+		assertEqualCmd(t, verifyCmd.execute, cmd)
+	})
+	t.Run("failed to verify login", func(t *testing.T) {
+		client := resty.New()
+		m := NewLoginModel(address, client)
+		m.email = "user@mail.com"
+		m.password = "1234"
+		m.challengeToken = "challenge"
+		sut := tea.Model(m)
+		msg := verifyLoginFailedMsg{statusCode: http.StatusUnauthorized, message: "invalid code"}
+
+		model, _ := sut.Update(msg)
+
+		got, _ := model.(loginModel)
+		assert.Empty(t, got.challengeToken)
+		assert.Empty(t, got.email)
+		assert.Empty(t, got.password)
+		assert.EqualError(t, got.err, "invalid code")
+	})
+	t.Run("login completed with enabling second factor", func(t *testing.T) {
+		client := resty.New()
+		m := NewLoginModel(address, client)
+		m.cursor = enableTwoFactorChoice
+		sut := tea.Model(m)
+		msg := loginCompletedMsg{jwtCookie: &http.Cookie{}}
+
+		model, cmd := sut.Update(msg)
+
+		_, ok := model.(twoFactorModel)
+		assert.True(t, ok)
+		assert.NotNil(t, cmd)
+	})
+	t.Run("login completed with changing password", func(t *testing.T) {
+		client := resty.New()
+		m := NewLoginModel(address, client)
+		m.cursor = changePasswordChoice
+		sut := tea.Model(m)
+		msg := loginCompletedMsg{jwtCookie: &http.Cookie{}}
+
+		model, cmd := sut.Update(msg)
+
+		got, ok := model.(passwordModel)
+		require.True(t, ok)
+		assert.True(t, got.isChange())
+		assert.NotNil(t, cmd)
+	})
+	t.Run("user entered email to reset password", func(t *testing.T) {
+		client := resty.New()
+		m := NewLoginModel(address, client)
+		m.cursor = resetPasswordChoice
+		sut := tea.Model(m)
+		sut, _ = sut.Update(tea.KeyMsg{Runes: []rune("user@email.com")})
+		msg := tea.KeyMsg{Type: tea.KeyEnter}
+
+		model, cmd := sut.Update(msg)
+
+		got, ok := model.(passwordModel)
+		require.True(t, ok)
+		assert.False(t, got.isChange())
+		assert.Equal(t, "user@email.com", got.login.email)
+		assert.Equal(t, enterRecoveryCode, got.textinput.Placeholder)
+		assert.NotNil(t, cmd)
+	})
 	t.Run("window size changed", func(t *testing.T) {
 		client := resty.New()
 		sut := NewLoginModel(address, client)
@@ -1,23 +1,81 @@
 package auth
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+
+	"github.com/nestjam/goph-keeper/internal/tui/vault/cache"
+)
 
 type registerCompletedMsg struct {
-	jwtCookie *http.Cookie
+	jwtCookie     *http.Cookie
+	refreshCookie *http.Cookie
 }
 
 type loginCompletedMsg struct {
+	jwtCookie     *http.Cookie
+	refreshCookie *http.Cookie
+}
+
+type storeOpenedMsg struct {
+	store     *cache.Store
 	jwtCookie *http.Cookie
 }
 
+type openStoreFailedMsg struct {
+	err error
+}
+
 type errMsg struct {
 	err error
 }
 
 type loginFailedMsg struct {
+	message    string
 	statusCode int
 }
 
 type registerFailedMsg struct {
+	message    string
+	statusCode int
+}
+
+type secondFactorRequiredMsg struct {
+	challengeToken string
+}
+
+type verifyLoginFailedMsg struct {
+	message    string
+	statusCode int
+}
+
+// loginLockedMsg tells that the server locked the login after too many failures.
+type loginLockedMsg struct {
+	retryAfter time.Duration
+}
+
+// passwordChangedMsg carries the cookies of the session started with the new password.
+type passwordChangedMsg struct {
+	jwtCookie     *http.Cookie
+	refreshCookie *http.Cookie
+}
+
+// passwordResetMsg tells that the password is reset, the user logs in with the new one.
+type passwordResetMsg struct{}
+
+type passwordFailedMsg struct {
+	message    string
+	statusCode int
+}
+
+type twoFactorEnrolledMsg struct {
+	uri           string
+	recoveryCodes []string
+}
+
+type twoFactorConfirmedMsg struct{}
+
+type twoFactorFailedMsg struct {
+	message    string
 	statusCode int
 }
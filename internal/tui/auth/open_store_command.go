@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"net/http"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/tui/vault/cache"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+type openStoreCommand struct {
+	jwtCookie *http.Cookie
+	loginErr  error
+	path      string
+	password  string
+	// previousPassword is set if the user has changed the password, the cache is encrypted with it.
+	previousPassword string
+}
+
+// newOpenStoreCommand returns the command to open the local cache of the user authenticated by the server.
+func newOpenStoreCommand(path, password string, jwt *http.Cookie) openStoreCommand {
+	return openStoreCommand{
+		path:      path,
+		password:  password,
+		jwtCookie: jwt,
+	}
+}
+
+// newChangedPasswordStoreCommand returns the command to open the local cache of the user who has changed
+// the password. The cache encrypted with the previous password is encrypted with the new one.
+func newChangedPasswordStoreCommand(path, previousPassword, password string, jwt *http.Cookie) openStoreCommand {
+	return openStoreCommand{
+		path:             path,
+		password:         password,
+		previousPassword: previousPassword,
+		jwtCookie:        jwt,
+	}
+}
+
+// newOpenOfflineStoreCommand returns the command to open the local cache when the server is unavailable.
+// The password is checked by decrypting the cache, the login error is reported if there is no cache.
+func newOpenOfflineStoreCommand(path, password string, loginErr error) openStoreCommand {
+	return openStoreCommand{
+		path:     path,
+		password: password,
+		loginErr: loginErr,
+	}
+}
+
+func (c openStoreCommand) execute() tea.Msg {
+	if c.path == "" {
+		return storeOpenedMsg{store: cache.NewStore(), jwtCookie: c.jwtCookie}
+	}
+
+	store, err := cache.OpenStore(c.path, c.password)
+	if c.jwtCookie == nil {
+		return c.offline(store, err)
+	}
+
+	if errors.Is(err, cache.ErrInvalidPassword) && c.previousPassword != "" {
+		store, err = c.changePassword()
+	}
+	if err != nil {
+		// the server has authenticated the user, so the cache that can not be opened is replaced
+		store, err = c.replace(err)
+		if err != nil {
+			return openStoreFailedMsg{err}
+		}
+	}
+
+	return storeOpenedMsg{store: store, jwtCookie: c.jwtCookie}
+}
+
+// replace creates the store in place of the one that can not be opened. The file of the previous store
+// is set aside rather than overwritten, as it may keep the changes that are not sent to the server.
+func (c openStoreCommand) replace(openErr error) (*cache.Store, error) {
+	const op = "replace store"
+
+	if !errors.Is(openErr, cache.ErrStoreNotFound) {
+		_, err := cache.SetAside(c.path)
+		if err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+	}
+
+	store, err := cache.CreateStore(c.path, c.password)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return store, nil
+}
+
+func (c openStoreCommand) changePassword() (*cache.Store, error) {
+	const op = "change password"
+
+	store, err := cache.OpenStore(c.path, c.previousPassword)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	err = store.ChangePassword(c.password)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return store, nil
+}
+
+func (c openStoreCommand) offline(store *cache.Store, err error) tea.Msg {
+	if errors.Is(err, cache.ErrStoreNotFound) {
+		return openStoreFailedMsg{c.loginErr}
+	}
+	if err != nil {
+		return openStoreFailedMsg{err}
+	}
+
+	// the user is not authenticated by the server until it is available
+	jwtCookie := &http.Cookie{Name: utils.JWTCookieName}
+	return storeOpenedMsg{store: store, jwtCookie: jwtCookie}
+}
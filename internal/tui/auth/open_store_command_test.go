@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/tui/vault/cache"
+	vault "github.com/nestjam/goph-keeper/internal/vault/delivery/http"
+)
+
+func TestOpenStoreCommand(t *testing.T) {
+	const password = "1234"
+
+	t.Run("store is kept in memory", func(t *testing.T) {
+		jwt := &http.Cookie{}
+		sut := newOpenStoreCommand("", password, jwt)
+
+		got := sut.execute()
+
+		msg, ok := got.(storeOpenedMsg)
+		require.True(t, ok)
+		assert.NotNil(t, msg.store)
+		assert.Equal(t, jwt, msg.jwtCookie)
+	})
+	t.Run("store is created", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "user.cache")
+		sut := newOpenStoreCommand(path, password, &http.Cookie{})
+
+		got := sut.execute()
+
+		msg, ok := got.(storeOpenedMsg)
+		require.True(t, ok)
+		assert.NotNil(t, msg.store)
+		require.NoError(t, msg.store.Save())
+		assert.FileExists(t, path)
+	})
+	t.Run("store is opened", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "user.cache")
+		createStore(t, path, password)
+		sut := newOpenStoreCommand(path, password, &http.Cookie{})
+
+		got := sut.execute()
+
+		msg, ok := got.(storeOpenedMsg)
+		require.True(t, ok)
+		assert.Equal(t, 1, len(msg.store.Cache("").ListSecrets()))
+	})
+	t.Run("store of changed password is replaced", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "user.cache")
+		createStore(t, path, "old password")
+		sut := newOpenStoreCommand(path, password, &http.Cookie{})
+
+		got := sut.execute()
+
+		msg, ok := got.(storeOpenedMsg)
+		require.True(t, ok)
+		assert.Empty(t, msg.store.Cache("").ListSecrets())
+		require.NoError(t, msg.store.Save())
+		aside, err := filepath.Glob(path + ".*.old")
+		require.NoError(t, err)
+		require.Len(t, aside, 1)
+		previous, err := cache.OpenStore(aside[0], "old password")
+		require.NoError(t, err)
+		assert.Len(t, previous.Cache("").ListSecrets(), 1)
+	})
+	t.Run("store is encrypted with changed password", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "user.cache")
+		createStore(t, path, "old password")
+		sut := newChangedPasswordStoreCommand(path, "old password", password, &http.Cookie{})
+
+		got := sut.execute()
+
+		msg, ok := got.(storeOpenedMsg)
+		require.True(t, ok)
+		assert.Equal(t, 1, len(msg.store.Cache("").ListSecrets()))
+		_, err := cache.OpenStore(path, password)
+		require.NoError(t, err)
+	})
+	t.Run("store is opened offline", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "user.cache")
+		createStore(t, path, password)
+		sut := newOpenOfflineStoreCommand(path, password, errors.New("connection refused"))
+
+		got := sut.execute()
+
+		msg, ok := got.(storeOpenedMsg)
+		require.True(t, ok)
+		assert.Equal(t, 1, len(msg.store.Cache("").ListSecrets()))
+		assert.NotNil(t, msg.jwtCookie)
+	})
+	t.Run("store is not found offline", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "user.cache")
+		loginErr := errors.New("connection refused")
+		sut := newOpenOfflineStoreCommand(path, password, loginErr)
+
+		got := sut.execute()
+
+		assert.Equal(t, openStoreFailedMsg{loginErr}, got)
+	})
+	t.Run("invalid password offline", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "user.cache")
+		createStore(t, path, "another password")
+		sut := newOpenOfflineStoreCommand(path, password, errors.New("connection refused"))
+
+		got := sut.execute()
+
+		msg, ok := got.(openStoreFailedMsg)
+		require.True(t, ok)
+		assert.ErrorIs(t, msg.err, cache.ErrInvalidPassword)
+	})
+}
+
+func createStore(t *testing.T, path, password string) {
+	t.Helper()
+
+	store, err := cache.CreateStore(path, password)
+	require.NoError(t, err)
+	store.Cache("").CacheSecret(&vault.Secret{ID: "1", Name: "card"})
+	require.NoError(t, store.Save())
+}
@@ -0,0 +1,242 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+const (
+	enterNewPassword  = "Enter new password"
+	enterRecoveryCode = "Enter recovery code"
+)
+
+type passwordKeyMap struct {
+	Quit     key.Binding
+	Cancel   key.Binding
+	Continue key.Binding
+}
+
+func (k passwordKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Continue, k.Cancel, k.Quit}
+}
+
+func (k passwordKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{}
+}
+
+// passwordModel changes the password of the logged in user or resets the forgotten password by a recovery code
+// of the second factor. The server ends all sessions of the user. The changed password starts the new session
+// and the vaults are opened with it, the user logs in with the reset one.
+type passwordModel struct {
+	err    error
+	client *resty.Client
+	// jwtCookie is set if the logged in user changes the password, the password is reset otherwise.
+	jwtCookie    *http.Cookie
+	help         help.Model
+	recoveryCode string
+	newPassword  string
+	keys         passwordKeyMap
+	textinput    textinput.Model
+	login        loginModel
+}
+
+// newChangePasswordModel returns the model to change the password the user has logged in with.
+func newChangePasswordModel(login loginModel, jwt *http.Cookie) passwordModel {
+	m := newPasswordModel(login)
+	m.jwtCookie = jwt
+	m.textinput.Placeholder = enterNewPassword
+	return m
+}
+
+// newResetPasswordModel returns the model to reset the password of the email entered to the login.
+func newResetPasswordModel(login loginModel) passwordModel {
+	m := newPasswordModel(login)
+	m.textinput.Placeholder = enterRecoveryCode
+	return m
+}
+
+func newPasswordModel(login loginModel) passwordModel {
+	ti := textinput.New()
+	ti.Focus()
+
+	keys := passwordKeyMap{
+		Quit: key.NewBinding(
+			key.WithKeys(tea.KeyCtrlC.String()),
+			key.WithHelp("ctr+c", "quit"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys(tea.KeyEsc.String()),
+			key.WithHelp("esc", "cancel"),
+		),
+		Continue: key.NewBinding(
+			key.WithKeys(tea.KeyEnter.String()),
+			key.WithHelp("enter", "continue"),
+		),
+	}
+
+	return passwordModel{
+		login:     login,
+		client:    login.client,
+		keys:      keys,
+		help:      help.New(),
+		textinput: ti,
+	}
+}
+
+func (m passwordModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m passwordModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.help.Width = msg.Width
+	case tea.KeyMsg:
+		return m.handleKeyMsg(msg)
+	case passwordChangedMsg:
+		return m.finish(msg)
+	case passwordResetMsg:
+		return m.loginWithNewPassword()
+	case passwordFailedMsg:
+		m.fail(errors.New(msg.message))
+		return m, nil
+	case loginLockedMsg:
+		m.fail(errors.Wrapf(ErrLoginLocked, "try again in %s", msg.retryAfter))
+		return m, nil
+	case errMsg:
+		m.fail(msg.err)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.textinput, cmd = m.textinput.Update(msg)
+	return m, cmd
+}
+
+func (m passwordModel) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Cancel):
+		return m.cancel()
+	case key.Matches(msg, m.keys.Continue):
+		input := m.textinput.Value()
+		if input == "" {
+			return m, nil
+		}
+		m.textinput.SetValue("")
+		m.err = nil
+		if !m.isChange() && m.recoveryCode == "" {
+			m.recoveryCode = input
+			m.textinput.Placeholder = enterNewPassword
+			return m, nil
+		}
+		m.newPassword = input
+		return m, m.submit()
+	}
+
+	var cmd tea.Cmd
+	m.textinput, cmd = m.textinput.Update(msg)
+	return m, cmd
+}
+
+func (m passwordModel) View() string {
+	s := strings.Builder{}
+
+	if m.isChange() {
+		s.WriteString("Change password of ")
+	} else {
+		s.WriteString("Reset password by recovery code of ")
+	}
+	s.WriteString(m.login.email)
+	s.WriteString("\n\n")
+
+	if m.recoveryCode != "" {
+		s.WriteString("recovery code: ")
+		s.WriteString(m.recoveryCode)
+		s.WriteString("\n")
+	}
+	if m.err != nil {
+		s.WriteString("error: ")
+		s.WriteString(m.err.Error())
+		s.WriteString("\n")
+	}
+
+	s.WriteString(m.textinput.View())
+
+	s.WriteString("\n\n")
+	s.WriteString(m.help.View(m.keys))
+
+	return s.String()
+}
+
+func (m passwordModel) isChange() bool {
+	return m.jwtCookie != nil
+}
+
+func (m passwordModel) submit() tea.Cmd {
+	if m.isChange() {
+		return changePassword(m.login.address, m.jwtCookie, m.login.password, m.newPassword, m.client)
+	}
+	return resetPassword(m.login.address, m.login.email, m.recoveryCode, m.newPassword, m.client)
+}
+
+// fail shows why the password is not changed and asks for the password again,
+// the recovery code is asked again as well since the code may be wrong.
+func (m *passwordModel) fail(err error) {
+	m.err = err
+	m.newPassword = ""
+	m.recoveryCode = ""
+	m.textinput.Placeholder = enterNewPassword
+	if !m.isChange() {
+		m.textinput.Placeholder = enterRecoveryCode
+	}
+}
+
+// cancel opens the vaults of the logged in user with the current password, the login starts over otherwise.
+func (m passwordModel) cancel() (tea.Model, tea.Cmd) {
+	if m.isChange() {
+		return m.login, openStore(m.login.storePath(), m.login.password, m.jwtCookie)
+	}
+	m.login.fail(nil)
+	return m.login, nil
+}
+
+// finish opens the vaults of the user with the new password in the session started with it.
+// The local cache is encrypted with the new password.
+func (m passwordModel) finish(msg passwordChangedMsg) (tea.Model, tea.Cmd) {
+	previous := m.login.password
+	m.login.password = m.newPassword
+	m.login.startSession(msg.jwtCookie, msg.refreshCookie)
+	return m.login, reopenStore(m.login.storePath(), previous, m.newPassword, msg.jwtCookie)
+}
+
+// loginWithNewPassword logs in with the reset password, the server asks for the code of the second factor.
+// The local cache encrypted with the forgotten password is replaced once the vaults are opened.
+func (m passwordModel) loginWithNewPassword() (tea.Model, tea.Cmd) {
+	m.login.password = m.newPassword
+	return m.login, login(m.login.address, m.login.email, m.newPassword, m.client)
+}
+
+func changePassword(addr string, jwt *http.Cookie, currentPassword, newPassword string,
+	client *resty.Client) tea.Cmd {
+	cmd := newChangePasswordCommand(addr, jwt, currentPassword, newPassword, client)
+	return cmd.execute
+}
+
+func resetPassword(addr, email, recoveryCode, newPassword string, client *resty.Client) tea.Cmd {
+	cmd := newResetPasswordCommand(addr, email, recoveryCode, newPassword, client)
+	return cmd.execute
+}
+
+func reopenStore(path, previousPassword, password string, jwt *http.Cookie) tea.Cmd {
+	cmd := newChangedPasswordStoreCommand(path, previousPassword, password, jwt)
+	return cmd.execute
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordModel_Update(t *testing.T) {
+	const address = "localhost:8080"
+	jwt := &http.Cookie{}
+
+	t.Run("user entered new password", func(t *testing.T) {
+		sut := tea.Model(newChangePasswordModel(newLoggedInModel(address), jwt))
+		sut, _ = sut.Update(tea.KeyMsg{Runes: []rune("5678")})
+		msg := tea.KeyMsg{Type: tea.KeyEnter}
+
+		model, cmd := sut.Update(msg)
+
+		got, _ := model.(passwordModel)
+		assert.Equal(t, "5678", got.newPassword)
+		assert.Empty(t, got.textinput.Value())
+		changeCmd := changePasswordCommand{}
+		assertEqualCmd(t, changeCmd.execute, cmd)
+	})
+	t.Run("user entered recovery code", func(t *testing.T) {
+		sut := tea.Model(newResetPasswordModel(newLoggedInModel(address)))
+		sut, _ = sut.Update(tea.KeyMsg{Runes: []rune("code")})
+		msg := tea.KeyMsg{Type: tea.KeyEnter}
+
+		model, cmd := sut.Update(msg)
+
+		got, _ := model.(passwordModel)
+		assert.Equal(t, "code", got.recoveryCode)
+		assert.Equal(t, enterNewPassword, got.textinput.Placeholder)
+		assert.Nil(t, cmd)
+	})
+	t.Run("user entered new password after recovery code", func(t *testing.T) {
+		m := newResetPasswordModel(newLoggedInModel(address))
+		m.recoveryCode = "code"
+		sut := tea.Model(m)
+		sut, _ = sut.Update(tea.KeyMsg{Runes: []rune("5678")})
+		msg := tea.KeyMsg{Type: tea.KeyEnter}
+
+		_, cmd := sut.Update(msg)
+
+		resetCmd := resetPasswordCommand{}
+		assertEqualCmd(t, resetCmd.execute, cmd)
+	})
+	t.Run("user pressed enter with empty input", func(t *testing.T) {
+		sut := newChangePasswordModel(newLoggedInModel(address), jwt)
+		msg := tea.KeyMsg{Type: tea.KeyEnter}
+
+		_, cmd := sut.Update(msg)
+
+		assert.Nil(t, cmd)
+	})
+	t.Run("password changed", func(t *testing.T) {
+		m := newChangePasswordModel(newLoggedInModel(address), jwt)
+		m.newPassword = "5678"
+		msg := passwordChangedMsg{jwtCookie: &http.Cookie{}}
+
+		model, cmd := m.Update(msg)
+
+		got, ok := model.(loginModel)
+		require.True(t, ok)
+		assert.Equal(t, "5678", got.password)
+		openCommand := openStoreCommand{}
+		assertEqualCmd(t, openCommand.execute, cmd)
+	})
+	t.Run("password reset", func(t *testing.T) {
+		m := newResetPasswordModel(newLoggedInModel(address))
+		m.recoveryCode = "code"
+		m.newPassword = "5678"
+		msg := passwordResetMsg{}
+
+		model, cmd := m.Update(msg)
+
+		got, ok := model.(loginModel)
+		require.True(t, ok)
+		assert.Equal(t, "5678", got.password)
+		loginCmd := loginCommand{}
+		assertEqualCmd(t, loginCmd.execute, cmd)
+	})
+	t.Run("failed to reset password", func(t *testing.T) {
+		m := newResetPasswordModel(newLoggedInModel(address))
+		m.recoveryCode = "code"
+		m.newPassword = "5678"
+		msg := passwordFailedMsg{statusCode: http.StatusUnauthorized, message: "invalid email or recovery code"}
+
+		model, cmd := m.Update(msg)
+
+		got, _ := model.(passwordModel)
+		assert.Empty(t, got.recoveryCode)
+		assert.Empty(t, got.newPassword)
+		assert.Equal(t, enterRecoveryCode, got.textinput.Placeholder)
+		assert.Contains(t, got.View(), "invalid email or recovery code")
+		assert.Nil(t, cmd)
+	})
+	t.Run("password change is locked", func(t *testing.T) {
+		sut := newChangePasswordModel(newLoggedInModel(address), jwt)
+		msg := loginLockedMsg{retryAfter: time.Minute}
+
+		model, _ := sut.Update(msg)
+
+		got, _ := model.(passwordModel)
+		require.ErrorIs(t, got.err, ErrLoginLocked)
+	})
+	t.Run("user canceled password change", func(t *testing.T) {
+		sut := newChangePasswordModel(newLoggedInModel(address), jwt)
+		msg := tea.KeyMsg{Type: tea.KeyEsc}
+
+		model, cmd := sut.Update(msg)
+
+		got, ok := model.(loginModel)
+		require.True(t, ok)
+		assert.Equal(t, "1234", got.password)
+		openCommand := openStoreCommand{}
+		assertEqualCmd(t, openCommand.execute, cmd)
+	})
+	t.Run("user canceled password reset", func(t *testing.T) {
+		sut := newResetPasswordModel(newLoggedInModel(address))
+		msg := tea.KeyMsg{Type: tea.KeyEsc}
+
+		model, cmd := sut.Update(msg)
+
+		got, ok := model.(loginModel)
+		require.True(t, ok)
+		assert.Empty(t, got.email)
+		assert.Nil(t, cmd)
+	})
+	t.Run("user exited by ctrl+c", func(t *testing.T) {
+		sut := newChangePasswordModel(newLoggedInModel(address), jwt)
+		msg := tea.KeyMsg{Type: tea.KeyCtrlC}
+
+		_, cmd := sut.Update(msg)
+
+		assertEqualCmd(t, tea.Quit, cmd)
+	})
+}
+
+func newLoggedInModel(address string) loginModel {
+	m := NewLoginModel(address, resty.New())
+	m.email = "user@email.com"
+	m.password = "1234"
+	return m
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"image/color"
+	"strings"
+
+	"github.com/boombuler/barcode/qr"
+	"github.com/pkg/errors"
+)
+
+// qrQuietZone is the light border around the code, scanners do not find the code without it.
+const qrQuietZone = 2
+
+// renderQR renders the QR code of the content with half blocks, so every line of the text holds two rows of modules.
+// Dark modules are left blank and light ones are filled, so the code is scanned from a terminal with a dark background.
+func renderQR(content string) (string, error) {
+	const op = "render qr"
+
+	code, err := qr.Encode(content, qr.L, qr.Auto)
+	if err != nil {
+		return "", errors.Wrap(err, op)
+	}
+
+	size := code.Bounds().Dx()
+	isLight := func(x, y int) bool {
+		x -= qrQuietZone
+		y -= qrQuietZone
+		if x < 0 || y < 0 || x >= size || y >= size {
+			return true
+		}
+		return !isDark(code.At(x, y))
+	}
+
+	s := strings.Builder{}
+	width := size + 2*qrQuietZone
+	for y := 0; y < width; y += 2 {
+		for x := 0; x < width; x++ {
+			top, bottom := isLight(x, y), isLight(x, y+1) && y+1 < width
+			switch {
+			case top && bottom:
+				s.WriteString("█")
+			case top:
+				s.WriteString("▀")
+			case bottom:
+				s.WriteString("▄")
+			default:
+				s.WriteString(" ")
+			}
+		}
+		s.WriteString("\n")
+	}
+
+	return s.String(), nil
+}
+
+func isDark(c color.Color) bool {
+	const half = 0x8000
+	r, g, b, _ := c.RGBA()
+	return r < half && g < half && b < half
+}
@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderQR(t *testing.T) {
+	// the smallest code is 21 modules wide
+	const width = 21 + 2*qrQuietZone
+
+	got, err := renderQR("otpauth")
+
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	assert.Len(t, lines, (width+1)/2)
+	for _, line := range lines {
+		assert.Equal(t, width, utf8.RuneCountInString(line))
+	}
+	// the quiet zone is light
+	assert.Equal(t, strings.Repeat("█", width), lines[0])
+}
@@ -37,7 +37,7 @@ func (c registerCommand) execute() tea.Msg {
 		return errMsg{err}
 	}
 
-	resp, err := c.client.R().SetBody(req).Post(url)
+	resp, err := c.client.R().SetBody(req).SetError(&httpAuth.ErrorResponse{}).Post(url)
 	if err != nil {
 		return errMsg{err}
 	}
@@ -49,9 +49,10 @@ func (c registerCommand) execute() tea.Msg {
 		}
 
 		return registerCompletedMsg{
-			jwtCookie: jwtCookie,
+			jwtCookie:     jwtCookie,
+			refreshCookie: findCookie(resp.Cookies(), utils.RefreshCookieName),
 		}
 	}
 
-	return registerFailedMsg{resp.StatusCode()}
+	return registerFailedMsg{statusCode: resp.StatusCode(), message: errorMessage(resp)}
 }
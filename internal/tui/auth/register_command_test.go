@@ -70,6 +70,20 @@ func TestRegisterCommand(t *testing.T) {
 		assert.True(t, ok)
 		assert.Equal(t, http.StatusInternalServerError, msg.statusCode)
 	})
+	t.Run("email has already been registered", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error":"email has already been registered"}`))
+		}))
+		defer server.Close()
+		sut := newRegisterCommand(server.URL, "user@email.com", "1234", resty.New())
+
+		got := sut.execute()
+
+		want := registerFailedMsg{statusCode: http.StatusConflict, message: "email has already been registered"}
+		assert.Equal(t, want, got)
+	})
 	t.Run("jwt cookie not found", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"net/http"
+	"net/url"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-resty/resty/v2"
+
+	httpAuth "github.com/nestjam/goph-keeper/internal/auth/delivery/http"
+)
+
+const resetPasswordURL = "password/reset"
+
+type resetPasswordCommand struct {
+	client       *resty.Client
+	address      string
+	email        string
+	recoveryCode string
+	newPassword  string
+}
+
+func newResetPasswordCommand(address, email, recoveryCode, newPassword string,
+	client *resty.Client) resetPasswordCommand {
+	return resetPasswordCommand{
+		address:      address,
+		email:        email,
+		recoveryCode: recoveryCode,
+		newPassword:  newPassword,
+		client:       client,
+	}
+}
+
+func (c resetPasswordCommand) execute() tea.Msg {
+	url, err := url.JoinPath(c.address, resetPasswordURL)
+	if err != nil {
+		return errMsg{err}
+	}
+
+	req := httpAuth.ResetPasswordRequest{
+		Email:        c.email,
+		RecoveryCode: c.recoveryCode,
+		NewPassword:  c.newPassword,
+	}
+	resp, err := c.client.R().SetBody(req).SetError(&httpAuth.ErrorResponse{}).Post(url)
+	if err != nil {
+		return errMsg{err}
+	}
+
+	return resetResult(resp)
+}
+
+// resetResult returns the message of the response to the reset of the password.
+func resetResult(resp *resty.Response) tea.Msg {
+	if resp.StatusCode() == http.StatusTooManyRequests {
+		return loginLockedMsg{retryAfter: retryAfter(resp)}
+	}
+
+	if resp.IsSuccess() {
+		return passwordResetMsg{}
+	}
+
+	return passwordFailedMsg{statusCode: resp.StatusCode(), message: errorMessage(resp)}
+}
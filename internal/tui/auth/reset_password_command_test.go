@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+
+	httpAuth "github.com/nestjam/goph-keeper/internal/auth/delivery/http"
+)
+
+func TestResetPasswordCommand(t *testing.T) {
+	t.Run("password reset", func(t *testing.T) {
+		var (
+			gotURL string
+			gotReq httpAuth.ResetPasswordRequest
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotURL = r.URL.String()
+			_ = json.NewDecoder(r.Body).Decode(&gotReq)
+
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		sut := newResetPasswordCommand(server.URL, "user@email.com", "code", "5678", resty.New())
+
+		got := sut.execute()
+
+		assert.Equal(t, "/password/reset", gotURL)
+		want := httpAuth.ResetPasswordRequest{Email: "user@email.com", RecoveryCode: "code", NewPassword: "5678"}
+		assert.Equal(t, want, gotReq)
+		assert.Equal(t, passwordResetMsg{}, got)
+	})
+	t.Run("invalid recovery code", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid email or recovery code"}`))
+		}))
+		defer server.Close()
+		sut := newResetPasswordCommand(server.URL, "user@email.com", "code", "5678", resty.New())
+
+		got := sut.execute()
+
+		want := passwordFailedMsg{statusCode: http.StatusUnauthorized, message: "invalid email or recovery code"}
+		assert.Equal(t, want, got)
+	})
+	t.Run("reset is locked", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+		sut := newResetPasswordCommand(server.URL, "user@email.com", "code", "5678", resty.New())
+
+		got := sut.execute()
+
+		assert.Equal(t, loginLockedMsg{retryAfter: time.Minute}, got)
+	})
+	t.Run("failed to connect server", func(t *testing.T) {
+		server := httptest.NewServer(nil)
+		serverURL := server.URL
+		server.Close()
+		sut := newResetPasswordCommand(serverURL, "user@email.com", "code", "5678", resty.New())
+
+		got := sut.execute()
+
+		assert.IsType(t, errMsg{}, got)
+	})
+}
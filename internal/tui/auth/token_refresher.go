@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+const (
+	refreshURL   = "refresh"
+	cookieHeader = "Cookie"
+)
+
+// tokenRefresher keeps the access token of the session fresh. The access token is short-lived, so the request
+// rejected with the expired token is sent again once the token is refreshed with the refresh token of the session.
+// The requests carry the cookie they were created with, the refresher replaces it with the current one.
+type tokenRefresher struct {
+	client        *resty.Client
+	jwtCookie     *http.Cookie
+	refreshCookie *http.Cookie
+	address       string
+	mu            sync.Mutex
+	// refreshMu makes concurrent requests rejected with the same token refresh it once
+	refreshMu sync.Mutex
+}
+
+// newTokenRefresher installs the refresher to the client. The refresher is idle until the session is started.
+func newTokenRefresher(client *resty.Client) *tokenRefresher {
+	r := &tokenRefresher{client: client}
+	client.OnBeforeRequest(r.setCurrentToken)
+	client.AddRetryCondition(r.refreshRejectedToken)
+	// the only retry is the one of the request rejected with the expired token
+	client.SetRetryCount(1)
+	return r
+}
+
+// start makes the refresher keep the tokens of the session started on the server.
+func (r *tokenRefresher) start(address string, jwt, refresh *http.Cookie) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.address = address
+	r.jwtCookie = jwt
+	r.refreshCookie = refresh
+}
+
+func (r *tokenRefresher) tokens() (jwt, refresh *http.Cookie) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.jwtCookie, r.refreshCookie
+}
+
+func (r *tokenRefresher) setCurrentToken(_ *resty.Client, req *resty.Request) error {
+	jwt, _ := r.tokens()
+	if jwt == nil {
+		return nil
+	}
+
+	// the header keeps the cookies of the previous attempt, the cookies are added to it again anyway
+	req.Header.Del(cookieHeader)
+	for i := 0; i < len(req.Cookies); i++ {
+		if req.Cookies[i].Name == utils.JWTCookieName {
+			req.Cookies[i] = jwt
+		}
+	}
+	return nil
+}
+
+// refreshRejectedToken refreshes the access token the request is rejected with and tells to send the request again.
+// The request fails as usual if the session can not be refreshed. The condition replaces the retry of resty
+// on transport errors: the request failed without the response may be handled by the server already,
+// so sending it again may e.g. add the secret twice.
+func (r *tokenRefresher) refreshRejectedToken(resp *resty.Response, err error) bool {
+	if err != nil || resp == nil {
+		return false
+	}
+	if resp.StatusCode() != http.StatusUnauthorized {
+		return false
+	}
+	rejected := findCookie(resp.Request.Cookies, utils.JWTCookieName)
+	if rejected == nil {
+		return false
+	}
+
+	r.refreshMu.Lock()
+	defer r.refreshMu.Unlock()
+
+	jwt, refresh := r.tokens()
+	if jwt == nil || refresh == nil {
+		return false
+	}
+	// the token is refreshed already by another request
+	if jwt.Value != rejected.Value {
+		closeRawBody(resp)
+		return true
+	}
+
+	if !r.refresh(refresh) {
+		return false
+	}
+	closeRawBody(resp)
+	return true
+}
+
+func (r *tokenRefresher) refresh(refresh *http.Cookie) bool {
+	r.mu.Lock()
+	address := r.address
+	r.mu.Unlock()
+
+	url, err := url.JoinPath(address, refreshURL)
+	if err != nil {
+		return false
+	}
+
+	resp, err := r.client.R().SetCookie(refresh).Post(url)
+	if err != nil || !resp.IsSuccess() {
+		return false
+	}
+
+	jwt := findCookie(resp.Cookies(), utils.JWTCookieName)
+	next := findCookie(resp.Cookies(), utils.RefreshCookieName)
+	if jwt == nil || next == nil {
+		return false
+	}
+
+	r.start(address, jwt, next)
+	return true
+}
+
+// closeRawBody closes the body of the response that is not parsed, the response is dropped for the next attempt.
+func closeRawBody(resp *resty.Response) {
+	if resp.RawResponse != nil {
+		_ = resp.RawBody().Close()
+	}
+}
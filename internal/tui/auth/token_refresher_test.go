@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+func TestTokenRefresher(t *testing.T) {
+	t.Run("request rejected with expired token is sent again with refreshed token", func(t *testing.T) {
+		refreshes := 0
+		server := newRefreshingServer(t, &refreshes)
+		defer server.Close()
+		client := resty.New()
+		sut := newTokenRefresher(client)
+		expired := &http.Cookie{Name: utils.JWTCookieName, Value: "expired"}
+		sut.start(server.URL, expired, &http.Cookie{Name: utils.RefreshCookieName, Value: "refresh"})
+
+		resp, err := client.R().SetCookie(expired).Get(server.URL + "/secrets")
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Equal(t, 1, refreshes)
+		jwt, refresh := sut.tokens()
+		assert.Equal(t, "fresh", jwt.Value)
+		assert.Equal(t, "next", refresh.Value)
+	})
+	t.Run("refreshed token is used by next requests", func(t *testing.T) {
+		refreshes := 0
+		server := newRefreshingServer(t, &refreshes)
+		defer server.Close()
+		client := resty.New()
+		sut := newTokenRefresher(client)
+		expired := &http.Cookie{Name: utils.JWTCookieName, Value: "expired"}
+		sut.start(server.URL, expired, &http.Cookie{Name: utils.RefreshCookieName, Value: "refresh"})
+		_, err := client.R().SetCookie(expired).Get(server.URL + "/secrets")
+		require.NoError(t, err)
+
+		resp, err := client.R().SetCookie(expired).Get(server.URL + "/secrets")
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode())
+		assert.Equal(t, 1, refreshes)
+	})
+	t.Run("session can not be refreshed", func(t *testing.T) {
+		refreshes := 0
+		server := newRefreshingServer(t, &refreshes)
+		defer server.Close()
+		client := resty.New()
+		sut := newTokenRefresher(client)
+		expired := &http.Cookie{Name: utils.JWTCookieName, Value: "expired"}
+		sut.start(server.URL, expired, &http.Cookie{Name: utils.RefreshCookieName, Value: "revoked"})
+
+		resp, err := client.R().SetCookie(expired).Get(server.URL + "/secrets")
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+		assert.Equal(t, 1, refreshes)
+	})
+	t.Run("session is not started", func(t *testing.T) {
+		refreshes := 0
+		server := newRefreshingServer(t, &refreshes)
+		defer server.Close()
+		client := resty.New()
+		_ = newTokenRefresher(client)
+		expired := &http.Cookie{Name: utils.JWTCookieName, Value: "expired"}
+
+		resp, err := client.R().SetCookie(expired).Get(server.URL + "/secrets")
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+		assert.Zero(t, refreshes)
+	})
+	t.Run("request failed in transport is not sent again", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			_ = conn.Close()
+		}))
+		defer server.Close()
+		client := resty.New()
+		sut := newTokenRefresher(client)
+		jwt := &http.Cookie{Name: utils.JWTCookieName, Value: "fresh"}
+		sut.start(server.URL, jwt, &http.Cookie{Name: utils.RefreshCookieName, Value: "refresh"})
+
+		_, err := client.R().SetCookie(jwt).SetBody(`{"name":"secret"}`).Post(server.URL + "/secrets")
+
+		require.Error(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+// newRefreshingServer accepts the fresh token only and exchanges the refresh token for it.
+func newRefreshingServer(t *testing.T, refreshes *int) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/"+refreshURL {
+			*refreshes++
+			cookie, err := r.Cookie(utils.RefreshCookieName)
+			if err != nil || cookie.Value != "refresh" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: utils.JWTCookieName, Value: "fresh"})
+			http.SetCookie(w, &http.Cookie{Name: utils.RefreshCookieName, Value: "next"})
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		cookie, err := r.Cookie(utils.JWTCookieName)
+		if err != nil || cookie.Value != "fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+}
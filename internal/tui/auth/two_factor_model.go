@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+const (
+	enterCode      = "Enter code from authenticator app"
+	zeroStatusCode = 0
+)
+
+type twoFactorKeyMap struct {
+	Quit    key.Binding
+	Skip    key.Binding
+	Confirm key.Binding
+}
+
+func (k twoFactorKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Confirm, k.Skip, k.Quit}
+}
+
+func (k twoFactorKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{}
+}
+
+// twoFactorModel enrolls the second factor of the logged in user. It shows the QR code the authenticator app
+// is set up by along with the recovery codes, the second factor is enabled once the user enters the code of the app.
+// The vaults are opened as soon as the second factor is enabled or the user skips it.
+type twoFactorModel struct {
+	err           error
+	client        *resty.Client
+	jwtCookie     *http.Cookie
+	help          help.Model
+	uri           string
+	qrCode        string
+	keys          twoFactorKeyMap
+	recoveryCodes []string
+	textinput     textinput.Model
+	login         loginModel
+	statusCode    int
+}
+
+func newTwoFactorModel(login loginModel, jwt *http.Cookie) twoFactorModel {
+	ti := textinput.New()
+	ti.Placeholder = enterCode
+	ti.Focus()
+
+	keys := twoFactorKeyMap{
+		Quit: key.NewBinding(
+			key.WithKeys(tea.KeyCtrlC.String()),
+			key.WithHelp("ctr+c", "quit"),
+		),
+		Skip: key.NewBinding(
+			key.WithKeys(tea.KeyEsc.String()),
+			key.WithHelp("esc", "skip"),
+		),
+		Confirm: key.NewBinding(
+			key.WithKeys(tea.KeyEnter.String()),
+			key.WithHelp("enter", "confirm"),
+		),
+	}
+
+	return twoFactorModel{
+		login:     login,
+		client:    login.client,
+		jwtCookie: jwt,
+		keys:      keys,
+		help:      help.New(),
+		textinput: ti,
+	}
+}
+
+func (m twoFactorModel) Init() tea.Cmd {
+	return tea.Batch(textinput.Blink, enrollTwoFactor(m.login.address, m.jwtCookie, m.client))
+}
+
+func (m twoFactorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.help.Width = msg.Width
+	case tea.KeyMsg:
+		return m.handleKeyMsg(msg)
+	case twoFactorEnrolledMsg:
+		m.uri = msg.uri
+		m.recoveryCodes = msg.recoveryCodes
+		m.qrCode, m.err = renderQR(msg.uri)
+		return m, nil
+	case twoFactorConfirmedMsg:
+		return m.finish()
+	case twoFactorFailedMsg:
+		m.statusCode = msg.statusCode
+		m.err = errors.New(msg.message)
+		return m, nil
+	case errMsg:
+		m.err = msg.err
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.textinput, cmd = m.textinput.Update(msg)
+	return m, cmd
+}
+
+func (m twoFactorModel) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Skip):
+		return m.finish()
+	case key.Matches(msg, m.keys.Confirm):
+		code := m.textinput.Value()
+		if code == "" || m.uri == "" {
+			return m, nil
+		}
+		m.textinput.SetValue("")
+		m.err = nil
+		m.statusCode = zeroStatusCode
+		return m, confirmTwoFactor(m.login.address, m.jwtCookie, code, m.client)
+	}
+
+	var cmd tea.Cmd
+	m.textinput, cmd = m.textinput.Update(msg)
+	return m, cmd
+}
+
+func (m twoFactorModel) View() string {
+	s := strings.Builder{}
+
+	if m.uri != "" {
+		s.WriteString("Scan the QR code with the authenticator app:\n\n")
+		s.WriteString(m.qrCode)
+		s.WriteString("\n")
+		s.WriteString(fmt.Sprintf("or add the key manually: %s\n\n", m.uri))
+		s.WriteString("Recovery codes, each of them logs in once without the app:\n")
+		for _, code := range m.recoveryCodes {
+			s.WriteString(code)
+			s.WriteString("\n")
+		}
+		s.WriteString("\n")
+	}
+	if m.statusCode != zeroStatusCode {
+		s.WriteString(fmt.Sprintf("code: %d\n", m.statusCode))
+	}
+	if m.err != nil {
+		s.WriteString("error: ")
+		s.WriteString(m.err.Error())
+		s.WriteString("\n")
+	}
+
+	s.WriteString(m.textinput.View())
+
+	s.WriteString("\n\n")
+	s.WriteString(m.help.View(m.keys))
+
+	return s.String()
+}
+
+// finish opens the vaults of the user.
+func (m twoFactorModel) finish() (tea.Model, tea.Cmd) {
+	return m.login, openStore(m.login.storePath(), m.login.password, m.jwtCookie)
+}
+
+func enrollTwoFactor(addr string, jwt *http.Cookie, client *resty.Client) tea.Cmd {
+	cmd := newEnrollTwoFactorCommand(addr, jwt, client)
+	return cmd.execute
+}
+
+func confirmTwoFactor(addr string, jwt *http.Cookie, code string, client *resty.Client) tea.Cmd {
+	cmd := newConfirmTwoFactorCommand(addr, jwt, code, client)
+	return cmd.execute
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoFactorModel_Update(t *testing.T) {
+	const address = "localhost:8080"
+	jwt := &http.Cookie{}
+
+	t.Run("two factor enrolled", func(t *testing.T) {
+		sut := newTwoFactorModel(NewLoginModel(address, resty.New()), jwt)
+		msg := twoFactorEnrolledMsg{uri: "otpauth://totp/test", recoveryCodes: []string{"code"}}
+
+		model, cmd := sut.Update(msg)
+
+		got, _ := model.(twoFactorModel)
+		assert.Equal(t, msg.uri, got.uri)
+		assert.Equal(t, msg.recoveryCodes, got.recoveryCodes)
+		assert.NotEmpty(t, got.qrCode)
+		assert.Contains(t, got.View(), "code")
+		assert.Nil(t, cmd)
+	})
+	t.Run("user entered code", func(t *testing.T) {
+		m := newTwoFactorModel(NewLoginModel(address, resty.New()), jwt)
+		m.uri = "otpauth://totp/test"
+		sut := tea.Model(m)
+		sut, _ = sut.Update(tea.KeyMsg{Runes: []rune("123456")})
+		msg := tea.KeyMsg{Type: tea.KeyEnter}
+
+		model, cmd := sut.Update(msg)
+
+		got, _ := model.(twoFactorModel)
+		assert.Empty(t, got.textinput.Value())
+		confirmCmd := confirmTwoFactorCommand{}
+		assertEqualCmd(t, confirmCmd.execute, cmd)
+	})
+	t.Run("user entered code before enrollment", func(t *testing.T) {
+		sut := tea.Model(newTwoFactorModel(NewLoginModel(address, resty.New()), jwt))
+		sut, _ = sut.Update(tea.KeyMsg{Runes: []rune("123456")})
+		msg := tea.KeyMsg{Type: tea.KeyEnter}
+
+		_, cmd := sut.Update(msg)
+
+		assert.Nil(t, cmd)
+	})
+	t.Run("two factor confirmed", func(t *testing.T) {
+		sut := newTwoFactorModel(NewLoginModel(address, resty.New()), jwt)
+
+		model, cmd := sut.Update(twoFactorConfirmedMsg{})
+
+		_, ok := model.(loginModel)
+		assert.True(t, ok)
+		openCommand := openStoreCommand{}
+		assertEqualCmd(t, openCommand.execute, cmd)
+	})
+	t.Run("user skipped two factor", func(t *testing.T) {
+		sut := newTwoFactorModel(NewLoginModel(address, resty.New()), jwt)
+		msg := tea.KeyMsg{Type: tea.KeyEsc}
+
+		model, cmd := sut.Update(msg)
+
+		_, ok := model.(loginModel)
+		assert.True(t, ok)
+		openCommand := openStoreCommand{}
+		assertEqualCmd(t, openCommand.execute, cmd)
+	})
+	t.Run("user exited by ctrl+c", func(t *testing.T) {
+		sut := newTwoFactorModel(NewLoginModel(address, resty.New()), jwt)
+		msg := tea.KeyMsg{Type: tea.KeyCtrlC}
+
+		_, cmd := sut.Update(msg)
+
+		assertEqualCmd(t, tea.Quit, cmd)
+	})
+	t.Run("failed to confirm two factor", func(t *testing.T) {
+		sut := newTwoFactorModel(NewLoginModel(address, resty.New()), jwt)
+		msg := twoFactorFailedMsg{statusCode: http.StatusBadRequest, message: "invalid code"}
+
+		model, cmd := sut.Update(msg)
+
+		got, _ := model.(twoFactorModel)
+		assert.Equal(t, http.StatusBadRequest, got.statusCode)
+		assert.Contains(t, got.View(), "invalid code")
+		assert.Nil(t, cmd)
+	})
+	t.Run("error", func(t *testing.T) {
+		sut := newTwoFactorModel(NewLoginModel(address, resty.New()), jwt)
+		msg := errMsg{errors.New("error")}
+
+		model, cmd := sut.Update(msg)
+
+		got, _ := model.(twoFactorModel)
+		assert.Equal(t, msg.err, got.err)
+		assert.Nil(t, cmd)
+	})
+}
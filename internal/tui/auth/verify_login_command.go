@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"net/http"
+	"net/url"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-resty/resty/v2"
+
+	httpAuth "github.com/nestjam/goph-keeper/internal/auth/delivery/http"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+const verifyLoginURL = "login/verify"
+
+// verifyLoginCommand completes the login by the code of the second factor.
+type verifyLoginCommand struct {
+	client         *resty.Client
+	address        string
+	challengeToken string
+	code           string
+}
+
+func newVerifyLoginCommand(address, challengeToken, code string, client *resty.Client) verifyLoginCommand {
+	return verifyLoginCommand{
+		address:        address,
+		challengeToken: challengeToken,
+		code:           code,
+		client:         client,
+	}
+}
+
+func (c verifyLoginCommand) execute() tea.Msg {
+	req := httpAuth.VerifyLoginRequest{
+		ChallengeToken: c.challengeToken,
+		Code:           c.code,
+	}
+	url, err := url.JoinPath(c.address, verifyLoginURL)
+	if err != nil {
+		return errMsg{err}
+	}
+
+	resp, err := c.client.R().SetBody(req).SetError(&httpAuth.ErrorResponse{}).Post(url)
+	if err != nil {
+		return errMsg{err}
+	}
+
+	if resp.StatusCode() == http.StatusTooManyRequests {
+		return loginLockedMsg{retryAfter: retryAfter(resp)}
+	}
+
+	if resp.IsSuccess() {
+		jwtCookie := findCookie(resp.Cookies(), utils.JWTCookieName)
+		if jwtCookie == nil {
+			return errMsg{ErrAuthTokenNotFound}
+		}
+
+		return loginCompletedMsg{
+			jwtCookie:     jwtCookie,
+			refreshCookie: findCookie(resp.Cookies(), utils.RefreshCookieName),
+		}
+	}
+
+	return verifyLoginFailedMsg{statusCode: resp.StatusCode(), message: errorMessage(resp)}
+}
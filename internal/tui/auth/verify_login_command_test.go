@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	httpAuth "github.com/nestjam/goph-keeper/internal/auth/delivery/http"
+	"github.com/nestjam/goph-keeper/internal/utils"
+)
+
+func TestVerifyLoginCommand(t *testing.T) {
+	t.Run("login verified", func(t *testing.T) {
+		var (
+			gotURL string
+			gotReq httpAuth.VerifyLoginRequest
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotURL = r.URL.String()
+			_ = json.NewDecoder(r.Body).Decode(&gotReq)
+
+			http.SetCookie(w, &http.Cookie{Name: utils.JWTCookieName, Value: "jwt"})
+			http.SetCookie(w, &http.Cookie{Name: utils.RefreshCookieName, Value: "refresh"})
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		sut := newVerifyLoginCommand(server.URL, "challenge", "123456", resty.New())
+
+		got := sut.execute()
+
+		assert.Equal(t, "/login/verify", gotURL)
+		assert.Equal(t, httpAuth.VerifyLoginRequest{ChallengeToken: "challenge", Code: "123456"}, gotReq)
+		msg, ok := got.(loginCompletedMsg)
+		require.True(t, ok)
+		assert.Equal(t, "jwt", msg.jwtCookie.Value)
+		assert.Equal(t, "refresh", msg.refreshCookie.Value)
+	})
+	t.Run("invalid code", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+		sut := newVerifyLoginCommand(server.URL, "challenge", "123456", resty.New())
+
+		got := sut.execute()
+
+		assert.Equal(t, verifyLoginFailedMsg{statusCode: http.StatusUnauthorized, message: "unauthorized"}, got)
+	})
+	t.Run("second factor is locked", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+		sut := newVerifyLoginCommand(server.URL, "challenge", "123456", resty.New())
+
+		got := sut.execute()
+
+		assert.Equal(t, loginLockedMsg{retryAfter: time.Minute}, got)
+	})
+	t.Run("jwt cookie not found", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		sut := newVerifyLoginCommand(server.URL, "challenge", "123456", resty.New())
+
+		got := sut.execute()
+
+		assert.IsType(t, errMsg{}, got)
+	})
+	t.Run("failed to connect server", func(t *testing.T) {
+		server := httptest.NewServer(nil)
+		serverURL := server.URL
+		server.Close()
+		sut := newVerifyLoginCommand(serverURL, "challenge", "123456", resty.New())
+
+		got := sut.execute()
+
+		assert.IsType(t, errMsg{}, got)
+	})
+}
@@ -0,0 +1,9 @@
+package cache
+
+import "errors"
+
+var (
+	ErrStoreNotFound   = errors.New("local cache not found")
+	ErrInvalidPassword = errors.New("local cache can not be decrypted with the password")
+	ErrInvalidFormat   = errors.New("local cache has invalid format")
+)
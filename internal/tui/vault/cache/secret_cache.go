@@ -1,6 +1,8 @@
 package cache
 
 import (
+	"sort"
+
 	vault "github.com/nestjam/goph-keeper/internal/vault/delivery/http"
 )
 
@@ -11,6 +13,7 @@ type secretCache struct {
 
 type SecretsCache struct {
 	secrets map[string]secretCache
+	cursor  int64
 }
 
 func New() *SecretsCache {
@@ -24,7 +27,7 @@ func (c *SecretsCache) CacheSecrets(secrets []*vault.Secret) {
 
 	for i := 0; i < len(secrets); i++ {
 		secret := secrets[i]
-		if cached, ok := c.secrets[secret.ID]; ok {
+		if cached, ok := c.secrets[secret.ID]; ok && cached.Revision == secret.Revision {
 			newCache[secret.ID] = cached
 			continue
 		}
@@ -43,6 +46,14 @@ func (c *SecretsCache) ListSecrets() []*vault.Secret {
 		i++
 	}
 
+	// map order is random, so secrets are sorted to keep rows of the list in place
+	sort.Slice(secrets, func(i, j int) bool {
+		if secrets[i].Name != secrets[j].Name {
+			return secrets[i].Name < secrets[j].Name
+		}
+		return secrets[i].ID < secrets[j].ID
+	})
+
 	return secrets
 }
 
@@ -66,3 +77,33 @@ func (c *SecretsCache) GetSecret(id string) (secret *vault.Secret, dataCached bo
 func (c *SecretsCache) RemoveSecret(id string) {
 	delete(c.secrets, id)
 }
+
+// Cursor returns the cursor of the changes applied to the cache.
+func (c *SecretsCache) Cursor() int64 {
+	return c.cursor
+}
+
+// ApplyChanges updates the cache with the changes of the secrets and moves the cursor.
+// Data of the secret is kept cached unless its revision is changed.
+func (c *SecretsCache) ApplyChanges(changes []vault.Change, cursor int64) {
+	if cursor < c.cursor {
+		// the changes are synced by the request sent before the one applied already
+		return
+	}
+
+	for i := 0; i < len(changes); i++ {
+		change := changes[i]
+		if change.Deleted {
+			delete(c.secrets, change.ID)
+			continue
+		}
+		if cached, ok := c.secrets[change.ID]; ok && cached.Revision == change.Revision {
+			continue
+		}
+		c.secrets[change.ID] = secretCache{
+			Secret: &vault.Secret{ID: change.ID, Name: change.Name, Revision: change.Revision},
+		}
+	}
+
+	c.cursor = cursor
+}
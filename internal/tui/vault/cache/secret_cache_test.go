@@ -55,6 +55,20 @@ func TestCacheSecrets(t *testing.T) {
 		got := sut.ListSecrets()
 		assert.ElementsMatch(t, want, got)
 	})
+	t.Run("replace cached secret of stale revision", func(t *testing.T) {
+		want := []*vault.Secret{
+			{ID: "1", Revision: 2},
+		}
+		sut := New()
+		sut.CacheSecret(&vault.Secret{ID: "1", Data: "data", Revision: 1})
+
+		sut.CacheSecrets(want)
+
+		got, dataCached, ok := sut.GetSecret("1")
+		assert.True(t, ok)
+		assert.False(t, dataCached)
+		assert.Equal(t, want[0], got)
+	})
 }
 
 func TestCacheSecret(t *testing.T) {
@@ -112,3 +126,65 @@ func TestRemoveSecret(t *testing.T) {
 		assert.Empty(t, sut.ListSecrets())
 	})
 }
+
+func TestApplyChanges(t *testing.T) {
+	t.Run("add changed secrets", func(t *testing.T) {
+		sut := New()
+		changes := []vault.Change{
+			{ID: "1", Name: "first", Revision: 1},
+			{ID: "2", Name: "second", Revision: 1},
+		}
+
+		sut.ApplyChanges(changes, 2)
+
+		want := []*vault.Secret{
+			{ID: "1", Name: "first", Revision: 1},
+			{ID: "2", Name: "second", Revision: 1},
+		}
+		assert.ElementsMatch(t, want, sut.ListSecrets())
+		assert.Equal(t, int64(2), sut.Cursor())
+	})
+	t.Run("remove deleted secret", func(t *testing.T) {
+		sut := New()
+		sut.CacheSecret(&vault.Secret{ID: "1", Data: "data", Revision: 1})
+
+		sut.ApplyChanges([]vault.Change{{ID: "1", Revision: 1, Deleted: true}}, 3)
+
+		assert.Empty(t, sut.ListSecrets())
+		assert.Equal(t, int64(3), sut.Cursor())
+	})
+	t.Run("keep data of secret of same revision", func(t *testing.T) {
+		sut := New()
+		want := &vault.Secret{ID: "1", Name: "secret", Data: "data", Revision: 2}
+		sut.CacheSecret(want)
+
+		sut.ApplyChanges([]vault.Change{{ID: "1", Name: "secret", Revision: 2}}, 4)
+
+		got, dataCached, ok := sut.GetSecret("1")
+		assert.True(t, ok)
+		assert.True(t, dataCached)
+		assert.Equal(t, want, got)
+	})
+	t.Run("replace secret of stale revision", func(t *testing.T) {
+		sut := New()
+		sut.CacheSecret(&vault.Secret{ID: "1", Name: "secret", Data: "data", Revision: 1})
+
+		sut.ApplyChanges([]vault.Change{{ID: "1", Name: "renamed", Revision: 2}}, 5)
+
+		got, dataCached, ok := sut.GetSecret("1")
+		assert.True(t, ok)
+		assert.False(t, dataCached)
+		assert.Equal(t, &vault.Secret{ID: "1", Name: "renamed", Revision: 2}, got)
+	})
+	t.Run("ignore changes synced before applied ones", func(t *testing.T) {
+		sut := New()
+		sut.ApplyChanges([]vault.Change{{ID: "1", Name: "renamed", Revision: 2}}, 5)
+
+		sut.ApplyChanges([]vault.Change{{ID: "1", Name: "secret", Revision: 1}}, 4)
+
+		got, _, ok := sut.GetSecret("1")
+		assert.True(t, ok)
+		assert.Equal(t, &vault.Secret{ID: "1", Name: "renamed", Revision: 2}, got)
+		assert.Equal(t, int64(5), sut.Cursor())
+	})
+}
@@ -0,0 +1,337 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/argon2"
+
+	"github.com/nestjam/goph-keeper/internal/tui/vault/queue"
+	"github.com/nestjam/goph-keeper/internal/utils"
+	vault "github.com/nestjam/goph-keeper/internal/vault/delivery/http"
+)
+
+const (
+	fileMode = 0o600
+	dirMode  = 0o700
+	saltSize = 16
+	asideExt = ".old"
+
+	// argon2id parameters recommended by RFC 9106 for memory constrained environments
+	argonTime    = 3
+	argonMemory  = 64 * 1024
+	argonThreads = 4
+	argonKeySize = 32
+)
+
+// magic marks the file of the store and the version of its format.
+var magic = []byte("GKCACHE1")
+
+// Store keeps the caches of the vaults and the queue of the changes made offline.
+// The store is saved to the file encrypted with the key derived from the user password,
+// so the secrets are available when the server is unavailable.
+type Store struct {
+	caches map[string]*SecretsCache
+	queue  *queue.Queue
+	path   string
+	key    []byte
+	salt   []byte
+	saved  []byte
+}
+
+type cachedSecret struct {
+	Secret     *vault.Secret `json:"secret"`
+	DataCached bool          `json:"data_cached"`
+}
+
+type cacheSnapshot struct {
+	Secrets []cachedSecret `json:"secrets"`
+	Cursor  int64          `json:"cursor"`
+}
+
+type storeSnapshot struct {
+	Caches map[string]cacheSnapshot `json:"caches"`
+	Queue  []queue.Operation        `json:"queue"`
+}
+
+// NewStore returns the store kept in memory only.
+func NewStore() *Store {
+	return &Store{
+		caches: make(map[string]*SecretsCache),
+		queue:  queue.New(),
+	}
+}
+
+// CreateStore returns the empty store saved to the file at the path. The file is replaced if it exists.
+func CreateStore(path, password string) (*Store, error) {
+	const op = "create store"
+
+	salt, err := utils.GenerateRandom(saltSize)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	s := NewStore()
+	s.path = path
+	s.salt = salt
+	s.key = deriveKey(password, salt)
+	return s, nil
+}
+
+// OpenStore returns the store saved to the file at the path.
+func OpenStore(path, password string) (*Store, error) {
+	const op = "open store"
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrStoreNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	if len(content) < len(magic)+saltSize || !bytes.Equal(content[:len(magic)], magic) {
+		return nil, ErrInvalidFormat
+	}
+	salt := content[len(magic) : len(magic)+saltSize]
+	key := deriveKey(password, salt)
+
+	plaintext, err := utils.NewBlockCipher(key).Unseal(content[len(magic)+saltSize:])
+	if err != nil {
+		return nil, ErrInvalidPassword
+	}
+
+	var snapshot storeSnapshot
+	if err = json.Unmarshal(plaintext, &snapshot); err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	s := &Store{
+		caches: make(map[string]*SecretsCache, len(snapshot.Caches)),
+		queue:  queue.New(snapshot.Queue...),
+		path:   path,
+		key:    key,
+		salt:   salt,
+		saved:  plaintext,
+	}
+	for vaultID, c := range snapshot.Caches {
+		s.caches[vaultID] = newFromSnapshot(c)
+	}
+
+	return s, nil
+}
+
+// SetAside renames the file of the store that can not be opened, so that the changes queued in it are not lost
+// when the store is replaced. The client does not open the renamed file, it can be opened by OpenStore with
+// the password it is encrypted with until the store that replaced it is wiped.
+func SetAside(path string) (string, error) {
+	const op = "set aside store"
+
+	aside := path + "." + strconv.FormatInt(time.Now().Unix(), 10) + asideExt
+	err := os.Rename(path, aside)
+	if err != nil {
+		return "", errors.Wrap(err, op)
+	}
+
+	return aside, nil
+}
+
+// FileName returns the name of the file of the store of the user at the server.
+func FileName(address, email string) string {
+	sum := sha256.Sum256([]byte(address + "\n" + email))
+	return hex.EncodeToString(sum[:]) + ".cache"
+}
+
+// Cache returns the cache of the vault, the personal vault has empty id.
+func (s *Store) Cache(vaultID string) *SecretsCache {
+	c, ok := s.caches[vaultID]
+	if !ok {
+		c = New()
+		s.caches[vaultID] = c
+	}
+	return c
+}
+
+// Queue returns the queue of the changes made offline.
+func (s *Store) Queue() *queue.Queue {
+	return s.queue
+}
+
+// Save writes the store to the file if the store is changed since it is saved last.
+func (s *Store) Save() error {
+	const op = "save store"
+
+	if s.path == "" {
+		return nil
+	}
+
+	plaintext, err := json.Marshal(s.snapshot())
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if bytes.Equal(plaintext, s.saved) {
+		return nil
+	}
+
+	ciphertext, err := utils.NewBlockCipher(s.key).Seal(plaintext)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	content := make([]byte, 0, len(magic)+len(s.salt)+len(ciphertext))
+	content = append(content, magic...)
+	content = append(content, s.salt...)
+	content = append(content, ciphertext...)
+
+	if err = writeFile(s.path, content); err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	s.saved = plaintext
+	return nil
+}
+
+// ChangePassword saves the store encrypted with the key derived from the new password of the user.
+func (s *Store) ChangePassword(password string) error {
+	const op = "change password"
+
+	salt, err := utils.GenerateRandom(saltSize)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	s.salt = salt
+	s.key = deriveKey(password, salt)
+	// the store is saved even if it is not changed, so it is not left encrypted with the previous password
+	s.saved = nil
+	if err = s.Save(); err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// Wipe removes the file of the store along with the files set aside in place of it and clears the store.
+// The store is kept in memory only afterwards.
+func (s *Store) Wipe() error {
+	const op = "wipe store"
+
+	s.caches = make(map[string]*SecretsCache)
+	s.queue = queue.New()
+	s.saved = nil
+
+	if s.path == "" {
+		return nil
+	}
+
+	// the wiped store is not saved any more
+	path := s.path
+	s.path = ""
+
+	err := os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Wrap(err, op)
+	}
+
+	if err = removeAside(path); err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// removeAside removes the files set aside in place of the file of the store.
+func removeAside(path string) error {
+	const op = "remove aside"
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	prefix := filepath.Base(path) + "."
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, asideExt) {
+			continue
+		}
+		err = os.Remove(filepath.Join(filepath.Dir(path), name))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return errors.Wrap(err, op)
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) snapshot() storeSnapshot {
+	snapshot := storeSnapshot{
+		Caches: make(map[string]cacheSnapshot, len(s.caches)),
+		Queue:  s.queue.Operations(),
+	}
+	for vaultID, c := range s.caches {
+		snapshot.Caches[vaultID] = c.snapshot()
+	}
+	return snapshot
+}
+
+func (c *SecretsCache) snapshot() cacheSnapshot {
+	snapshot := cacheSnapshot{
+		Secrets: make([]cachedSecret, 0, len(c.secrets)),
+		Cursor:  c.cursor,
+	}
+	for _, secret := range c.ListSecrets() {
+		snapshot.Secrets = append(snapshot.Secrets, cachedSecret{
+			Secret:     secret,
+			DataCached: c.secrets[secret.ID].dataCached,
+		})
+	}
+	return snapshot
+}
+
+func newFromSnapshot(snapshot cacheSnapshot) *SecretsCache {
+	c := New()
+	c.cursor = snapshot.Cursor
+	for _, s := range snapshot.Secrets {
+		c.secrets[s.Secret.ID] = secretCache{Secret: s.Secret, dataCached: s.DataCached}
+	}
+	return c
+}
+
+func deriveKey(password string, salt []byte) []byte {
+	return argon2.IDKey([]byte(password), salt, argonTime, argonMemory, argonThreads, argonKeySize)
+}
+
+// writeFile writes the file to a temporary file first, so that the file is never left partially written.
+func writeFile(path string, content []byte) error {
+	const op = "write file"
+
+	err := os.MkdirAll(filepath.Dir(path), dirMode)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	tmp := path + ".tmp"
+	err = os.WriteFile(tmp, content, fileMode)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = os.Rename(tmp, path)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/tui/vault/queue"
+	vault "github.com/nestjam/goph-keeper/internal/vault/delivery/http"
+)
+
+const password = "password"
+
+func TestStore(t *testing.T) {
+	t.Run("store is saved encrypted", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "user", FileName("https://localhost", "user@mail.com"))
+		sut, err := CreateStore(path, password)
+		require.NoError(t, err)
+		secret := &vault.Secret{ID: "1", Name: "secret", Data: "sensitive data", Revision: 2}
+		sut.Cache("").CacheSecret(secret)
+		sut.Cache("").ApplyChanges(nil, 5)
+		sut.Cache("vault").CacheSecrets([]*vault.Secret{{ID: "2", Name: "shared"}})
+		op := queue.Operation{Kind: queue.KindDelete, Address: "https://localhost", Secret: vault.Secret{ID: "3"}}
+		sut.Queue().Push(op)
+
+		err = sut.Save()
+
+		require.NoError(t, err)
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.NotContains(t, string(content), secret.Data)
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(fileMode), info.Mode().Perm())
+
+		got, err := OpenStore(path, password)
+
+		require.NoError(t, err)
+		gotSecret, dataCached, ok := got.Cache("").GetSecret(secret.ID)
+		assert.True(t, ok)
+		assert.True(t, dataCached)
+		assert.Equal(t, secret, gotSecret)
+		assert.Equal(t, int64(5), got.Cache("").Cursor())
+		_, dataCached, ok = got.Cache("vault").GetSecret("2")
+		assert.True(t, ok)
+		assert.False(t, dataCached)
+		assert.Equal(t, []queue.Operation{op}, got.Queue().Operations())
+	})
+	t.Run("invalid password", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "store.cache")
+		sut, err := CreateStore(path, password)
+		require.NoError(t, err)
+		sut.Cache("").CacheSecret(&vault.Secret{ID: "1"})
+		require.NoError(t, sut.Save())
+
+		_, err = OpenStore(path, "another password")
+
+		require.ErrorIs(t, err, ErrInvalidPassword)
+	})
+	t.Run("change password", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "store.cache")
+		sut, err := CreateStore(path, password)
+		require.NoError(t, err)
+		sut.Cache("").CacheSecret(&vault.Secret{ID: "1"})
+		require.NoError(t, sut.Save())
+
+		err = sut.ChangePassword("new password")
+
+		require.NoError(t, err)
+		_, err = OpenStore(path, password)
+		require.ErrorIs(t, err, ErrInvalidPassword)
+		got, err := OpenStore(path, "new password")
+		require.NoError(t, err)
+		_, _, ok := got.Cache("").GetSecret("1")
+		assert.True(t, ok)
+	})
+	t.Run("store not found", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "store.cache")
+
+		_, err := OpenStore(path, password)
+
+		require.ErrorIs(t, err, ErrStoreNotFound)
+	})
+	t.Run("invalid format", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "store.cache")
+		require.NoError(t, os.WriteFile(path, []byte("cache"), fileMode))
+
+		_, err := OpenStore(path, password)
+
+		require.ErrorIs(t, err, ErrInvalidFormat)
+	})
+	t.Run("unchanged store is not saved", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "store.cache")
+		sut, err := CreateStore(path, password)
+		require.NoError(t, err)
+		require.NoError(t, sut.Save())
+		require.NoError(t, os.Remove(path))
+
+		err = sut.Save()
+
+		require.NoError(t, err)
+		assert.NoFileExists(t, path)
+	})
+	t.Run("wipe store", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "store.cache")
+		sut, err := CreateStore(path, password)
+		require.NoError(t, err)
+		sut.Cache("").CacheSecret(&vault.Secret{ID: "1"})
+		sut.Queue().Push(queue.Operation{Kind: queue.KindDelete, Secret: vault.Secret{ID: "2"}})
+		require.NoError(t, sut.Save())
+
+		err = sut.Wipe()
+
+		require.NoError(t, err)
+		assert.NoFileExists(t, path)
+		assert.Empty(t, sut.Cache("").ListSecrets())
+		assert.Empty(t, sut.Queue().Operations())
+	})
+	t.Run("set aside store", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "store.cache")
+		store, err := CreateStore(path, password)
+		require.NoError(t, err)
+		store.Cache("").CacheSecret(&vault.Secret{ID: "1"})
+		require.NoError(t, store.Save())
+
+		aside, err := SetAside(path)
+
+		require.NoError(t, err)
+		assert.NoFileExists(t, path)
+		got, err := OpenStore(aside, password)
+		require.NoError(t, err)
+		assert.Len(t, got.Cache("").ListSecrets(), 1)
+	})
+	t.Run("wipe store removes files set aside", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "store.cache")
+		previous, err := CreateStore(path, password)
+		require.NoError(t, err)
+		previous.Queue().Push(queue.Operation{Kind: queue.KindDelete, Secret: vault.Secret{ID: "1"}})
+		require.NoError(t, previous.Save())
+		aside, err := SetAside(path)
+		require.NoError(t, err)
+		another := filepath.Join(dir, "another.cache")
+		require.NoError(t, os.WriteFile(another+".1700000000"+asideExt, []byte("another"), fileMode))
+		sut, err := CreateStore(path, password)
+		require.NoError(t, err)
+		require.NoError(t, sut.Save())
+
+		err = sut.Wipe()
+
+		require.NoError(t, err)
+		assert.NoFileExists(t, path)
+		assert.NoFileExists(t, aside)
+		assert.FileExists(t, another+".1700000000"+asideExt)
+	})
+	t.Run("store kept in memory", func(t *testing.T) {
+		sut := NewStore()
+		sut.Cache("").CacheSecret(&vault.Secret{ID: "1"})
+
+		require.NoError(t, sut.Save())
+		require.NoError(t, sut.Wipe())
+
+		assert.Empty(t, sut.Cache("").ListSecrets())
+	})
+}
+
+func TestFileName(t *testing.T) {
+	got := FileName("https://localhost", "user@mail.com")
+
+	assert.NotEqual(t, FileName("https://localhost", "another@mail.com"), got)
+	assert.NotContains(t, got, "user@mail.com")
+}
@@ -0,0 +1,20 @@
+package vault
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	httpVault "github.com/nestjam/goph-keeper/internal/vault/delivery/http"
+)
+
+type cachedSecretCommand struct {
+	secret httpVault.Secret
+}
+
+func newCachedSecretCommand(secret httpVault.Secret) cachedSecretCommand {
+	return cachedSecretCommand{secret}
+}
+
+// execute shows the cached secret, which is newer than the one on the server until its changes are sent.
+func (c cachedSecretCommand) execute() tea.Msg {
+	return getSecretCompletedMsg{c.secret}
+}
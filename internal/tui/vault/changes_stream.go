@@ -0,0 +1,53 @@
+package vault
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// changesStream reads server-sent events of changes of the secrets in the vault.
+type changesStream struct {
+	body    io.ReadCloser
+	reader  *bufio.Reader
+	address string
+}
+
+func newChangesStream(addr string, body io.ReadCloser) *changesStream {
+	return &changesStream{
+		address: addr,
+		body:    body,
+		reader:  bufio.NewReader(body),
+	}
+}
+
+// next reads the next event and reports whether the secrets are changed.
+// Heartbeats that keep the idle stream open are skipped.
+func (s *changesStream) next() (bool, error) {
+	var name string
+	var hasData bool
+
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return false, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "":
+			// blank line ends the event
+			if hasData || name != "" {
+				return name == changeEvent, nil
+			}
+		case strings.HasPrefix(line, "event:"):
+			name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			hasData = true
+		}
+	}
+}
+
+func (s *changesStream) close() {
+	_ = s.body.Close()
+}
@@ -2,9 +2,25 @@ package vault
 
 const (
 	baseURL      = "secrets"
+	vaultsURL    = "vaults"
+	syncURL      = "sync"
+	eventsURL    = "events"
+	sinceParam   = "since"
+	personal     = "personal"
 	errTemplate  = "error: %s\n\n"
 	codeTemplate = "code: %d\n\n"
 	quitApp      = "quit"
+	logoutUser   = "logout"
 	offlineMode  = "offline mode"
 	noCachedData = "no cached data"
+
+	acceptHeader    = "Accept"
+	textEventStream = "text/event-stream"
+	changeEvent     = "change"
+
+	pendingTemplate = "%d changes are waiting to be sent to the server\n\n"
+	savedLocally    = "saved locally, the changes are sent when the server is available"
+
+	conflictTemplate = "secret was changed on another device (revision %d)\n" +
+		"ctrl+r to load the changes, ctrl+o to overwrite them\n\n"
 )
@@ -6,6 +6,8 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/go-resty/resty/v2"
+
+	httpVault "github.com/nestjam/goph-keeper/internal/vault/delivery/http"
 )
 
 type deleteSecretCommand struct {
@@ -13,13 +15,21 @@ type deleteSecretCommand struct {
 	jwtCookie *http.Cookie
 	address   string
 	secretID  string
+	revision  int64
 }
 
-func newDeleteSecretCommand(secretID, addr string, jwt *http.Cookie, client *resty.Client) deleteSecretCommand {
+func newDeleteSecretCommand(
+	secretID string,
+	revision int64,
+	addr string,
+	jwt *http.Cookie,
+	client *resty.Client,
+) deleteSecretCommand {
 	return deleteSecretCommand{
 		jwtCookie: jwt,
 		address:   addr,
 		secretID:  secretID,
+		revision:  revision,
 		client:    client,
 	}
 }
@@ -29,7 +39,10 @@ func (c deleteSecretCommand) execute() tea.Msg {
 	if err != nil {
 		return errMsg{err}
 	}
-	resp, err := c.client.R().SetCookie(c.jwtCookie).Delete(url)
+	resp, err := c.client.R().
+		SetCookie(c.jwtCookie).
+		SetHeader(httpVault.IfMatchHeader, httpVault.FormatETag(c.revision)).
+		Delete(url)
 	if err != nil {
 		return errMsg{err}
 	}
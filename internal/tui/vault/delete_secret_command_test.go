@@ -7,11 +7,16 @@ import (
 
 	"github.com/go-resty/resty/v2"
 	"github.com/stretchr/testify/assert"
+
+	httpVault "github.com/nestjam/goph-keeper/internal/vault/delivery/http"
 )
 
 func TestDeleteSecretCommand(t *testing.T) {
 	t.Run("delete secret", func(t *testing.T) {
-		const secretID = "1"
+		const (
+			secretID = "1"
+			revision = 1
+		)
 		wantURL := "/secrets/" + secretID
 		wantCookie := &http.Cookie{
 			Name: "jwt",
@@ -19,19 +24,22 @@ func TestDeleteSecretCommand(t *testing.T) {
 		want := deleteSecretCompletedMsg{secretID}
 		var gotURL string
 		var gotCookie *http.Cookie
+		var gotIfMatch string
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			gotURL = r.URL.String()
 			gotCookie = findCookie(r.Cookies(), "jwt")
+			gotIfMatch = r.Header.Get(httpVault.IfMatchHeader)
 			w.WriteHeader(http.StatusOK)
 		}))
 		defer server.Close()
 		client := resty.New()
-		sut := newDeleteSecretCommand(secretID, server.URL, wantCookie, client)
+		sut := newDeleteSecretCommand(secretID, revision, server.URL, wantCookie, client)
 
 		got := sut.execute()
 
 		assert.Equal(t, wantURL, gotURL)
 		assert.Equal(t, wantCookie, gotCookie)
+		assert.Equal(t, httpVault.FormatETag(revision), gotIfMatch)
 		assert.Equal(t, want, got)
 	})
 	t.Run("invalid server address", func(t *testing.T) {
@@ -44,25 +52,31 @@ func TestDeleteSecretCommand(t *testing.T) {
 		assert.IsType(t, errMsg{}, got)
 	})
 	t.Run("failed to connect server", func(t *testing.T) {
-		const secretID = "1"
+		const (
+			secretID = "1"
+			revision = 1
+		)
 		server := httptest.NewServer(nil)
 		serverURL := server.URL
 		server.Close()
 		client := resty.New()
-		sut := newDeleteSecretCommand(secretID, serverURL, &http.Cookie{}, client)
+		sut := newDeleteSecretCommand(secretID, revision, serverURL, &http.Cookie{}, client)
 
 		got := sut.execute()
 
 		assert.IsType(t, errMsg{}, got)
 	})
 	t.Run("delete secret failed", func(t *testing.T) {
-		const secretID = "1"
+		const (
+			secretID = "1"
+			revision = 1
+		)
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusNotFound)
 		}))
 		defer server.Close()
 		client := resty.New()
-		sut := newDeleteSecretCommand(secretID, server.URL, &http.Cookie{}, client)
+		sut := newDeleteSecretCommand(secretID, revision, server.URL, &http.Cookie{}, client)
 
 		got := sut.execute()
 
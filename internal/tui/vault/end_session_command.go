@@ -0,0 +1,45 @@
+package vault
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	logoutURL     = "logout"
+	logoutTimeout = 5 * time.Second
+)
+
+// endSessionCommand ends the session on the server and quits. The app quits anyway if the server is unavailable,
+// the session is left to expire then.
+type endSessionCommand struct {
+	client    *resty.Client
+	jwtCookie *http.Cookie
+	address   string
+}
+
+func newEndSessionCommand(addr string, jwt *http.Cookie, client *resty.Client) endSessionCommand {
+	return endSessionCommand{
+		address:   addr,
+		jwtCookie: jwt,
+		client:    client,
+	}
+}
+
+func (c endSessionCommand) execute() tea.Msg {
+	url, err := url.JoinPath(c.address, logoutURL)
+	if err != nil {
+		return tea.Quit()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), logoutTimeout)
+	defer cancel()
+	_, _ = c.client.R().SetContext(ctx).SetCookie(c.jwtCookie).Post(url)
+
+	return tea.Quit()
+}
@@ -0,0 +1,46 @@
+package vault
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndSessionCommand(t *testing.T) {
+	jwtCookie := &http.Cookie{Name: "jwt", Value: "token"}
+
+	t.Run("session is ended", func(t *testing.T) {
+		var gotMethod, gotURL, gotCookie string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotMethod = r.Method
+			gotURL = r.URL.String()
+			if c, err := r.Cookie(jwtCookie.Name); err == nil {
+				gotCookie = c.Value
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		sut := newEndSessionCommand(server.URL, jwtCookie, resty.New())
+
+		got := sut.execute()
+
+		assert.IsType(t, tea.QuitMsg{}, got)
+		assert.Equal(t, http.MethodPost, gotMethod)
+		assert.Equal(t, "/logout", gotURL)
+		assert.Equal(t, jwtCookie.Value, gotCookie)
+	})
+	t.Run("app quits if server is unavailable", func(t *testing.T) {
+		server := httptest.NewServer(nil)
+		serverURL := server.URL
+		server.Close()
+		sut := newEndSessionCommand(serverURL, jwtCookie, resty.New())
+
+		got := sut.execute()
+
+		assert.IsType(t, tea.QuitMsg{}, got)
+	})
+}
@@ -0,0 +1,8 @@
+package vault
+
+import "errors"
+
+var (
+	ErrSecretChanged  = errors.New("secret was changed on another device")
+	ErrChangesNotSent = errors.New("changes are not sent to the server yet, logout again to discard them")
+)
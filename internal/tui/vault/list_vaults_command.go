@@ -0,0 +1,44 @@
+package vault
+
+import (
+	"net/http"
+	"net/url"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-resty/resty/v2"
+
+	httpOrg "github.com/nestjam/goph-keeper/internal/org/delivery/http"
+)
+
+type listVaultsCommand struct {
+	client    *resty.Client
+	jwtCookie *http.Cookie
+	address   string
+}
+
+func newListVaultsCommand(addr string, jwt *http.Cookie, client *resty.Client) listVaultsCommand {
+	return listVaultsCommand{
+		address:   addr,
+		jwtCookie: jwt,
+		client:    client,
+	}
+}
+
+func (c listVaultsCommand) execute() tea.Msg {
+	url, err := url.JoinPath(c.address, vaultsURL)
+	if err != nil {
+		return listVaultsFailedMsg{err: err}
+	}
+
+	var res httpOrg.ListVaultsResponse
+	resp, err := c.client.R().SetResult(&res).SetCookie(c.jwtCookie).Get(url)
+	if err != nil {
+		return listVaultsFailedMsg{err: err}
+	}
+
+	if resp.IsSuccess() {
+		return listVaultsCompletedMsg{res.List}
+	}
+
+	return listVaultsFailedMsg{statusCode: resp.StatusCode()}
+}
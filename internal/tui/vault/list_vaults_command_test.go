@@ -0,0 +1,63 @@
+package vault
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+
+	httpOrg "github.com/nestjam/goph-keeper/internal/org/delivery/http"
+)
+
+func TestListVaultsCommand(t *testing.T) {
+	t.Run("list vaults shared with user", func(t *testing.T) {
+		wantVaults := []httpOrg.Vault{
+			{ID: "1", Name: "servers", OrgName: "team", Role: "viewer"},
+		}
+		wantCookie := &http.Cookie{
+			Name: "auth",
+		}
+		var gotURL string
+		var gotCookie *http.Cookie
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotURL = r.URL.String()
+			gotCookie = findCookie(r.Cookies(), "auth")
+			_ = writeJSON(w, http.StatusOK, httpOrg.ListVaultsResponse{List: wantVaults})
+		}))
+		defer server.Close()
+		sut := newListVaultsCommand(server.URL, wantCookie, resty.New())
+
+		got := sut.execute()
+
+		assert.Equal(t, "/vaults", gotURL)
+		assert.Equal(t, wantCookie, gotCookie)
+		assert.Equal(t, listVaultsCompletedMsg{wantVaults}, got)
+	})
+	t.Run("failed to connect server", func(t *testing.T) {
+		server := httptest.NewServer(nil)
+		serverURL := server.URL
+		server.Close()
+		sut := newListVaultsCommand(serverURL, &http.Cookie{}, resty.New())
+
+		msg := sut.execute()
+
+		got, ok := msg.(listVaultsFailedMsg)
+		assert.True(t, ok)
+		assert.NotNil(t, got.err)
+	})
+	t.Run("request is not successful", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+		sut := newListVaultsCommand(server.URL, &http.Cookie{}, resty.New())
+
+		msg := sut.execute()
+
+		got, ok := msg.(listVaultsFailedMsg)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusInternalServerError, got.statusCode)
+	})
+}
@@ -0,0 +1,16 @@
+package vault
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type logoutCommand struct {
+}
+
+func newLogoutCommand() logoutCommand {
+	return logoutCommand{}
+}
+
+func (c logoutCommand) execute() tea.Msg {
+	return logoutRequestedMsg{}
+}
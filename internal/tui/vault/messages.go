@@ -1,6 +1,10 @@
 package vault
 
 import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	httpOrg "github.com/nestjam/goph-keeper/internal/org/delivery/http"
+	"github.com/nestjam/goph-keeper/internal/tui/vault/queue"
 	httpVault "github.com/nestjam/goph-keeper/internal/vault/delivery/http"
 )
 
@@ -13,6 +17,11 @@ type listSecretsFailedMsg struct {
 	statusCode int
 }
 
+type syncSecretsCompletedMsg struct {
+	changes []httpVault.Change
+	cursor  int64
+}
+
 type errMsg struct {
 	err error
 }
@@ -45,3 +54,54 @@ type saveSecretCompletedMsg struct {
 type saveSecretFailedMsg struct {
 	statusCode int
 }
+
+type saveSecretConflictMsg struct {
+	local  httpVault.Secret
+	remote httpVault.Secret
+}
+
+type showVaultsRequestedMsg struct {
+}
+
+type logoutRequestedMsg struct {
+}
+
+type listVaultsCompletedMsg struct {
+	vaults []httpOrg.Vault
+}
+
+type listVaultsFailedMsg struct {
+	err        error
+	statusCode int
+}
+
+type operationReplayedMsg struct {
+	result tea.Msg
+	op     queue.Operation
+}
+
+type retrySyncMsg struct {
+}
+
+type changesWatchedMsg struct {
+	stream *changesStream
+}
+
+type watchChangesFailedMsg struct {
+	err        error
+	address    string
+	statusCode int
+}
+
+type changesEventMsg struct {
+	stream  *changesStream
+	changed bool
+}
+
+type changesStreamClosedMsg struct {
+	stream *changesStream
+}
+
+type rewatchChangesMsg struct {
+	address string
+}
@@ -0,0 +1,119 @@
+package queue
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+
+	vault "github.com/nestjam/goph-keeper/internal/vault/delivery/http"
+)
+
+const localIDPrefix = "local-"
+
+// Kind is the kind of the operation made to the secret.
+type Kind string
+
+const (
+	KindSave   Kind = "save"
+	KindDelete Kind = "delete"
+)
+
+// Operation is the change of the secret waiting to be sent to the vault at the address.
+type Operation struct {
+	Kind    Kind         `json:"kind"`
+	Address string       `json:"address"`
+	Secret  vault.Secret `json:"secret"`
+}
+
+// Queue keeps the operations made while the server is unavailable in the order they are made.
+type Queue struct {
+	ops []Operation
+}
+
+// New returns the queue of the operations.
+func New(ops ...Operation) *Queue {
+	return &Queue{ops: ops}
+}
+
+// NewLocalID returns id of the secret created while the server is unavailable.
+func NewLocalID() string {
+	return localIDPrefix + uuid.NewString()
+}
+
+// IsLocalID reports whether the secret is not created on the server yet.
+func IsLocalID(id string) bool {
+	return strings.HasPrefix(id, localIDPrefix)
+}
+
+// Push adds the operation to the queue. The operation replaces the pending one of the same secret,
+// keeping the revision the secret was changed from, so the server detects changes made on other devices.
+func (q *Queue) Push(o Operation) {
+	i := q.find(o.Address, o.Secret.ID)
+	switch {
+	case i < 0:
+		q.ops = append(q.ops, o)
+	case o.Kind == KindDelete && IsLocalID(o.Secret.ID):
+		// the secret is not created on the server, so there is nothing to send
+		q.ops = append(q.ops[:i], q.ops[i+1:]...)
+	default:
+		o.Secret.Revision = q.ops[i].Secret.Revision
+		q.ops[i] = o
+	}
+}
+
+// Next returns the first pending operation of the vault at the address.
+func (q *Queue) Next(addr string) (Operation, bool) {
+	for i := 0; i < len(q.ops); i++ {
+		if q.ops[i].Address == addr {
+			return q.ops[i], true
+		}
+	}
+	return Operation{}, false
+}
+
+// Done removes the first pending operation of the vault at the address.
+func (q *Queue) Done(addr string) {
+	for i := 0; i < len(q.ops); i++ {
+		if q.ops[i].Address == addr {
+			q.ops = append(q.ops[:i], q.ops[i+1:]...)
+			return
+		}
+	}
+}
+
+// Pending returns the number of the pending operations of the vault at the address.
+func (q *Queue) Pending(addr string) int {
+	n := 0
+	for i := 0; i < len(q.ops); i++ {
+		if q.ops[i].Address == addr {
+			n++
+		}
+	}
+	return n
+}
+
+// Len returns the number of the pending operations of all vaults.
+func (q *Queue) Len() int {
+	return len(q.ops)
+}
+
+// Has reports whether the secret has the pending operation.
+func (q *Queue) Has(addr, secretID string) bool {
+	return q.find(addr, secretID) >= 0
+}
+
+// Operations returns the pending operations in the order they are made.
+func (q *Queue) Operations() []Operation {
+	ops := make([]Operation, len(q.ops))
+	copy(ops, q.ops)
+	return ops
+}
+
+func (q *Queue) find(addr, secretID string) int {
+	for i := 0; i < len(q.ops); i++ {
+		if q.ops[i].Address == addr && q.ops[i].Secret.ID == secretID {
+			return i
+		}
+	}
+	return -1
+}
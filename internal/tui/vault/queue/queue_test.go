@@ -0,0 +1,102 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	vault "github.com/nestjam/goph-keeper/internal/vault/delivery/http"
+)
+
+const address = "https://localhost/vaults/1"
+
+func TestPush(t *testing.T) {
+	t.Run("operations are kept in order", func(t *testing.T) {
+		sut := New()
+		first := Operation{Kind: KindSave, Address: address, Secret: vault.Secret{ID: "1"}}
+		second := Operation{Kind: KindDelete, Address: address, Secret: vault.Secret{ID: "2"}}
+
+		sut.Push(first)
+		sut.Push(second)
+
+		assert.Equal(t, 2, sut.Pending(address))
+		got, ok := sut.Next(address)
+		assert.True(t, ok)
+		assert.Equal(t, first, got)
+	})
+	t.Run("operation replaces pending one of same secret", func(t *testing.T) {
+		sut := New()
+		first := Operation{Kind: KindSave, Address: address, Secret: vault.Secret{ID: "1", Data: "a", Revision: 2}}
+		second := Operation{Kind: KindSave, Address: address, Secret: vault.Secret{ID: "1", Data: "b", Revision: 3}}
+		sut.Push(first)
+
+		sut.Push(second)
+
+		assert.Equal(t, 1, sut.Pending(address))
+		got, _ := sut.Next(address)
+		want := Operation{Kind: KindSave, Address: address, Secret: vault.Secret{ID: "1", Data: "b", Revision: 2}}
+		assert.Equal(t, want, got)
+	})
+	t.Run("delete of secret created offline removes it", func(t *testing.T) {
+		sut := New()
+		id := NewLocalID()
+		sut.Push(Operation{Kind: KindSave, Address: address, Secret: vault.Secret{ID: id}})
+
+		sut.Push(Operation{Kind: KindDelete, Address: address, Secret: vault.Secret{ID: id}})
+
+		assert.Zero(t, sut.Pending(address))
+	})
+	t.Run("operations of vaults are separate", func(t *testing.T) {
+		sut := New()
+		secret := vault.Secret{ID: "1"}
+		sut.Push(Operation{Kind: KindSave, Address: address, Secret: secret})
+
+		sut.Push(Operation{Kind: KindSave, Address: "https://localhost", Secret: secret})
+
+		assert.Equal(t, 1, sut.Pending(address))
+		assert.Equal(t, 1, sut.Pending("https://localhost"))
+	})
+}
+
+func TestDone(t *testing.T) {
+	t.Run("remove first operation of vault", func(t *testing.T) {
+		another := Operation{Kind: KindSave, Address: "https://localhost", Secret: vault.Secret{ID: "1"}}
+		first := Operation{Kind: KindSave, Address: address, Secret: vault.Secret{ID: "2"}}
+		second := Operation{Kind: KindSave, Address: address, Secret: vault.Secret{ID: "3"}}
+		sut := New(another, first, second)
+
+		sut.Done(address)
+
+		got, ok := sut.Next(address)
+		assert.True(t, ok)
+		assert.Equal(t, second, got)
+		assert.True(t, sut.Has("https://localhost", "1"))
+	})
+	t.Run("no operations", func(t *testing.T) {
+		sut := New()
+
+		sut.Done(address)
+
+		_, ok := sut.Next(address)
+		assert.False(t, ok)
+	})
+}
+
+func TestOperations(t *testing.T) {
+	want := []Operation{
+		{Kind: KindSave, Address: address, Secret: vault.Secret{ID: "1"}},
+		{Kind: KindDelete, Address: address, Secret: vault.Secret{ID: "2"}},
+	}
+	sut := New(want...)
+
+	got := sut.Operations()
+
+	assert.Equal(t, want, got)
+	got[0].Kind = KindDelete
+	assert.Equal(t, want, sut.Operations())
+}
+
+func TestIsLocalID(t *testing.T) {
+	assert.True(t, IsLocalID(NewLocalID()))
+	assert.False(t, IsLocalID("f47ac10b-58cc-4372-a567-0e02b2c3d479"))
+}
@@ -0,0 +1,45 @@
+package vault
+
+import (
+	"net/http"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-resty/resty/v2"
+
+	"github.com/nestjam/goph-keeper/internal/tui/vault/queue"
+)
+
+type replayOperationCommand struct {
+	client    *resty.Client
+	jwtCookie *http.Cookie
+	op        queue.Operation
+}
+
+func newReplayOperationCommand(op queue.Operation, jwt *http.Cookie, client *resty.Client) replayOperationCommand {
+	return replayOperationCommand{
+		op:        op,
+		jwtCookie: jwt,
+		client:    client,
+	}
+}
+
+// execute sends the operation made offline to the vault it was made in.
+func (c replayOperationCommand) execute() tea.Msg {
+	var result tea.Msg
+
+	switch c.op.Kind {
+	case queue.KindDelete:
+		cmd := newDeleteSecretCommand(c.op.Secret.ID, c.op.Secret.Revision, c.op.Address, c.jwtCookie, c.client)
+		result = cmd.execute()
+	default:
+		secret := c.op.Secret
+		if queue.IsLocalID(secret.ID) {
+			// the secret is created on the server with id of its own
+			secret.ID = ""
+		}
+		cmd := newSaveSecretCommand(secret, c.op.Address, c.jwtCookie, c.client)
+		result = cmd.execute()
+	}
+
+	return operationReplayedMsg{op: c.op, result: result}
+}
@@ -0,0 +1,82 @@
+package vault
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nestjam/goph-keeper/internal/tui/vault/queue"
+	httpVault "github.com/nestjam/goph-keeper/internal/vault/delivery/http"
+)
+
+func TestReplayOperationCommand(t *testing.T) {
+	t.Run("secret created offline", func(t *testing.T) {
+		var gotMethod, gotURL string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotMethod = r.Method
+			gotURL = r.URL.String()
+			s := httpVault.Secret{ID: "1", Revision: 1}
+			_ = writeJSON(w, http.StatusCreated, httpVault.AddSecretResponse{Secret: s})
+		}))
+		defer server.Close()
+		op := queue.Operation{
+			Kind:    queue.KindSave,
+			Address: server.URL,
+			Secret:  httpVault.Secret{ID: queue.NewLocalID(), Data: "data"},
+		}
+		sut := newReplayOperationCommand(op, &http.Cookie{}, resty.New())
+
+		got := sut.execute()
+
+		assert.Equal(t, http.MethodPost, gotMethod)
+		assert.Equal(t, "/secrets", gotURL)
+		want := operationReplayedMsg{
+			op:     op,
+			result: saveSecretCompletedMsg{httpVault.Secret{ID: "1", Data: "data", Revision: 1}},
+		}
+		assert.Equal(t, want, got)
+	})
+	t.Run("secret deleted offline", func(t *testing.T) {
+		var gotMethod, gotURL, gotIfMatch string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotMethod = r.Method
+			gotURL = r.URL.String()
+			gotIfMatch = r.Header.Get(httpVault.IfMatchHeader)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		op := queue.Operation{
+			Kind:    queue.KindDelete,
+			Address: server.URL,
+			Secret:  httpVault.Secret{ID: "1", Revision: 2},
+		}
+		sut := newReplayOperationCommand(op, &http.Cookie{}, resty.New())
+
+		got := sut.execute()
+
+		assert.Equal(t, http.MethodDelete, gotMethod)
+		assert.Equal(t, "/secrets/1", gotURL)
+		assert.Equal(t, httpVault.FormatETag(2), gotIfMatch)
+		assert.Equal(t, operationReplayedMsg{op: op, result: deleteSecretCompletedMsg{"1"}}, got)
+	})
+	t.Run("server is unavailable", func(t *testing.T) {
+		server := httptest.NewServer(nil)
+		serverURL := server.URL
+		server.Close()
+		op := queue.Operation{
+			Kind:    queue.KindDelete,
+			Address: serverURL,
+			Secret:  httpVault.Secret{ID: "1"},
+		}
+		sut := newReplayOperationCommand(op, &http.Cookie{}, resty.New())
+
+		got := sut.execute()
+
+		msg, ok := got.(operationReplayedMsg)
+		assert.True(t, ok)
+		assert.IsType(t, errMsg{}, msg.result)
+	})
+}
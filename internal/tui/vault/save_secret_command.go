@@ -1,7 +1,6 @@
 package vault
 
 import (
-	"crypto/tls"
 	"net/http"
 	"net/url"
 
@@ -60,11 +59,6 @@ func (c saveSecretCommand) addSecret() tea.Msg {
 }
 
 func (c saveSecretCommand) updateSecret() tea.Msg {
-	client := resty.New()
-
-	//nolint:gosec // using self-signed certificate
-	client.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
-
 	url, err := url.JoinPath(c.address, baseURL, c.secret.ID)
 	if err != nil {
 		return errMsg{err}
@@ -73,14 +67,40 @@ func (c saveSecretCommand) updateSecret() tea.Msg {
 	req := httpVault.UpdateSecretRequest{
 		Secret: c.secret,
 	}
-	resp, err := client.R().SetBody(req).SetCookie(c.jwtCookie).Patch(url)
+	resp, err := c.client.R().
+		SetBody(req).
+		SetCookie(c.jwtCookie).
+		SetHeader(httpVault.IfMatchHeader, httpVault.FormatETag(c.secret.Revision)).
+		Patch(url)
 	if err != nil {
 		return errMsg{err}
 	}
 
 	if resp.IsSuccess() {
-		return saveSecretCompletedMsg{c.secret}
+		secret := c.secret
+		if revision, err := httpVault.ParseETag(resp.Header().Get(httpVault.ETagHeader)); err == nil {
+			secret.Revision = revision
+		}
+		return saveSecretCompletedMsg{secret}
+	}
+
+	if resp.StatusCode() == http.StatusPreconditionFailed {
+		return c.conflict()
 	}
 
 	return saveSecretFailedMsg{resp.StatusCode()}
 }
+
+// conflict fetches the secret changed by another client to let user resolve the conflict.
+func (c saveSecretCommand) conflict() tea.Msg {
+	cmd := newGetSecretCommand(c.secret.ID, c.address, c.jwtCookie, c.client)
+	msg, ok := cmd.execute().(getSecretCompletedMsg)
+	if !ok {
+		return saveSecretFailedMsg{http.StatusPreconditionFailed}
+	}
+
+	return saveSecretConflictMsg{
+		local:  c.secret,
+		remote: msg.secret,
+	}
+}
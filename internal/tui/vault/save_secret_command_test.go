@@ -99,22 +99,27 @@ func TestSaveSecretCommand(t *testing.T) {
 			Name: "jwt",
 		}
 		secret := httpVault.Secret{
-			ID:   secretID,
-			Data: "data",
+			ID:       secretID,
+			Data:     "data",
+			Revision: 1,
 		}
 		want := saveSecretCompletedMsg{
 			secret: httpVault.Secret{
-				ID:   secretID,
-				Data: secret.Data,
+				ID:       secretID,
+				Data:     secret.Data,
+				Revision: 2,
 			},
 		}
 		var gotURL string
 		var gotCookie *http.Cookie
 		var gotMethod string
+		var gotIfMatch string
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			gotMethod = r.Method
 			gotURL = r.URL.String()
 			gotCookie = findCookie(r.Cookies(), "jwt")
+			gotIfMatch = r.Header.Get(httpVault.IfMatchHeader)
+			w.Header().Set(httpVault.ETagHeader, httpVault.FormatETag(2))
 			w.WriteHeader(http.StatusOK)
 		}))
 		defer server.Close()
@@ -126,8 +131,58 @@ func TestSaveSecretCommand(t *testing.T) {
 		assert.Equal(t, wantURL, gotURL)
 		assert.Equal(t, wantMethod, gotMethod)
 		assert.Equal(t, wantCookie, gotCookie)
+		assert.Equal(t, httpVault.FormatETag(secret.Revision), gotIfMatch)
+		assert.Equal(t, want, got)
+	})
+	t.Run("edited secret was changed by another client", func(t *testing.T) {
+		secret := httpVault.Secret{
+			ID:       "1",
+			Data:     "local data",
+			Revision: 1,
+		}
+		remote := httpVault.Secret{
+			ID:       secret.ID,
+			Data:     "remote data",
+			Revision: 2,
+		}
+		want := saveSecretConflictMsg{
+			local:  secret,
+			remote: remote,
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				_ = writeJSON(w, http.StatusOK, httpVault.GetSecretResponse{Secret: remote})
+				return
+			}
+			w.WriteHeader(http.StatusPreconditionFailed)
+		}))
+		defer server.Close()
+		client := resty.New()
+		sut := newSaveSecretCommand(secret, server.URL, &http.Cookie{}, client)
+
+		got := sut.execute()
+
 		assert.Equal(t, want, got)
 	})
+	t.Run("failed to get secret changed by another client", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusPreconditionFailed)
+		}))
+		defer server.Close()
+		secret := httpVault.Secret{ID: "1", Revision: 1}
+		client := resty.New()
+		sut := newSaveSecretCommand(secret, server.URL, &http.Cookie{}, client)
+
+		got := sut.execute()
+
+		msg, ok := got.(saveSecretFailedMsg)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusPreconditionFailed, msg.statusCode)
+	})
 	t.Run("update secret: invalid server address", func(t *testing.T) {
 		sut := saveSecretCommand{
 			address: string([]byte{0x7f}), // ASCII control character
@@ -12,17 +12,20 @@ import (
 	"github.com/go-resty/resty/v2"
 
 	"github.com/nestjam/goph-keeper/internal/tui/vault/cache"
+	"github.com/nestjam/goph-keeper/internal/tui/vault/queue"
 	vault "github.com/nestjam/goph-keeper/internal/vault/delivery/http"
 )
 
 type secretKeyMap struct {
-	Quit   key.Binding
-	Save   key.Binding
-	Return key.Binding
+	Quit      key.Binding
+	Save      key.Binding
+	Return    key.Binding
+	Reload    key.Binding
+	Overwrite key.Binding
 }
 
 func (k secretKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Save, k.Return, k.Quit}
+	return []key.Binding{k.Save, k.Reload, k.Overwrite, k.Return, k.Quit}
 }
 
 func (k secretKeyMap) FullHelp() [][]key.Binding {
@@ -35,17 +38,27 @@ type secretModel struct {
 	client             *resty.Client
 	jwtCookie          *http.Cookie
 	cache              *cache.SecretsCache
+	queue              *queue.Queue
 	help               help.Model
 	secret             vault.Secret
+	remoteSecret       vault.Secret
 	address            string
 	keys               secretKeyMap
 	failtureStatusCode int
 	isNew              bool
 	isOffline          bool
 	dataCached         bool
+	hasConflict        bool
+	isQueued           bool
 }
 
-func NewSecretModel(addr string, jwt *http.Cookie, cache *cache.SecretsCache, client *resty.Client) secretModel {
+func NewSecretModel(
+	addr string,
+	jwt *http.Cookie,
+	cache *cache.SecretsCache,
+	q *queue.Queue,
+	client *resty.Client,
+) secretModel {
 	ti := textarea.New()
 	ti.Focus()
 
@@ -62,6 +75,16 @@ func NewSecretModel(addr string, jwt *http.Cookie, cache *cache.SecretsCache, cl
 			key.WithKeys(tea.KeyEsc.String()),
 			key.WithHelp("esc", "return"),
 		),
+		Reload: key.NewBinding(
+			key.WithKeys(tea.KeyCtrlR.String()),
+			key.WithHelp("ctrl+r", "reload"),
+			key.WithDisabled(),
+		),
+		Overwrite: key.NewBinding(
+			key.WithKeys(tea.KeyCtrlO.String()),
+			key.WithHelp("ctrl+o", "overwrite"),
+			key.WithDisabled(),
+		),
 	}
 
 	return secretModel{
@@ -71,6 +94,7 @@ func NewSecretModel(addr string, jwt *http.Cookie, cache *cache.SecretsCache, cl
 		address:   addr,
 		jwtCookie: jwt,
 		cache:     cache,
+		queue:     q,
 		client:    client,
 	}
 }
@@ -106,7 +130,11 @@ func (m secretModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.textarea.Placeholder = noCachedData
 				}
 			}
-			m.textarea.Blur()
+			if !m.dataCached {
+				// the secret can not be edited offline without its data
+				m.textarea.Blur()
+				m.keys.Save.SetEnabled(false)
+			}
 		}
 	case createSecretRequestedMsg:
 		{
@@ -119,12 +147,20 @@ func (m secretModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.cache.CacheSecret(&msg.secret)
 			m.textarea.SetValue(msg.secret.Data)
 			m.isNew = false
+			m.isQueued = false
+		}
+	case saveSecretConflictMsg:
+		{
+			m.secret = msg.local
+			m.textarea.SetValue(msg.local.Data)
+			m.remoteSecret = msg.remote
+			m.setConflict(true)
 		}
 	case errMsg:
 		{
+			// the secret can be saved locally once the server is known to be unavailable
 			m.err = msg.err
 			m.setOfflineMode(true)
-			m.textarea.Blur()
 		}
 	default:
 	}
@@ -149,6 +185,15 @@ func (m secretModel) View() string {
 		s.WriteString(fmt.Sprintf(codeTemplate, m.failtureStatusCode))
 	}
 
+	if m.hasConflict {
+		s.WriteString(fmt.Sprintf(conflictTemplate, m.remoteSecret.Revision))
+	}
+
+	if m.isQueued {
+		s.WriteString(savedLocally)
+		s.WriteString("\n\n")
+	}
+
 	s.WriteString(fmt.Sprintf("id: %s", m.secret.ID))
 	s.WriteString("\n\n")
 
@@ -163,7 +208,42 @@ func (m secretModel) View() string {
 
 func (m *secretModel) setOfflineMode(v bool) {
 	m.isOffline = v
+}
+
+// queued reports whether the secret is to be saved locally and sent to the server later.
+func (m secretModel) queued() bool {
+	return m.isOffline || m.queue.Pending(m.address) > 0
+}
+
+// queueSave saves the secret to the cache and queues it to send it later.
+// The secret created offline gets the local id until the server gives it the id.
+func (m secretModel) queueSave() (tea.Model, tea.Cmd) {
+	secret := m.secret
+	secret.Data = m.textarea.Value()
+	if secret.ID == "" {
+		secret.ID = queue.NewLocalID()
+	}
+
+	op := queue.Operation{
+		Kind:    queue.KindSave,
+		Address: m.address,
+		Secret:  secret,
+	}
+	m.queue.Push(op)
+
+	m.secret = secret
+	m.cache.CacheSecret(&secret)
+	m.isNew = false
+	m.dataCached = true
+	m.isQueued = true
+	return m, nil
+}
+
+func (m *secretModel) setConflict(v bool) {
+	m.hasConflict = v
 	m.keys.Save.SetEnabled(!v)
+	m.keys.Reload.SetEnabled(v)
+	m.keys.Overwrite.SetEnabled(v)
 }
 
 func (m secretModel) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
@@ -171,12 +251,28 @@ func (m secretModel) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, m.keys.Quit):
 		return m, tea.Quit
 	case key.Matches(msg, m.keys.Return):
-		model := NewSecretsModel(m.address, m.jwtCookie, m.cache, m.client)
-		cmd := listSecrets(m.address, m.jwtCookie, m.client)
+		model := NewSecretsModel(m.address, m.jwtCookie, m.cache, m.queue, m.client)
+		cmd := syncSecrets(m.cache, m.address, m.jwtCookie, m.client)
 		return model, cmd
 	case key.Matches(msg, m.keys.Save):
+		if m.queued() {
+			return m.queueSave()
+		}
+		secret := m.secret
+		secret.Data = m.textarea.Value()
+		cmd := saveSecret(secret, m.address, m.jwtCookie, m.client)
+		return m, cmd
+	case key.Matches(msg, m.keys.Reload):
+		m.secret = m.remoteSecret
+		m.cache.CacheSecret(&m.remoteSecret)
+		m.textarea.SetValue(m.remoteSecret.Data)
+		m.setConflict(false)
+		return m, nil
+	case key.Matches(msg, m.keys.Overwrite):
 		secret := m.secret
 		secret.Data = m.textarea.Value()
+		secret.Revision = m.remoteSecret.Revision
+		m.setConflict(false)
 		cmd := saveSecret(secret, m.address, m.jwtCookie, m.client)
 		return m, cmd
 	default:
@@ -186,9 +282,10 @@ func (m secretModel) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 }
 
-func listSecrets(addr string, jwt *http.Cookie, client *resty.Client) tea.Cmd {
-	cmd := NewListSecretsCommand(addr, jwt, client)
-	return cmd.Execute
+// syncSecrets requests the changes of the secrets made after the ones applied to the cache.
+func syncSecrets(c *cache.SecretsCache, addr string, jwt *http.Cookie, client *resty.Client) tea.Cmd {
+	cmd := newSyncSecretsCommand(c.Cursor(), addr, jwt, client)
+	return cmd.execute
 }
 
 func saveSecret(secret vault.Secret, addr string, jwt *http.Cookie, client *resty.Client) tea.Cmd {
@@ -2,6 +2,7 @@ package vault
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/nestjam/goph-keeper/internal/tui/vault/cache"
+	"github.com/nestjam/goph-keeper/internal/tui/vault/queue"
 	vault "github.com/nestjam/goph-keeper/internal/vault/delivery/http"
 )
 
@@ -21,7 +23,7 @@ func TestSecretModel_Init(t *testing.T) {
 	)
 	cache := cache.New()
 	client := resty.New()
-	sut := NewSecretModel(address, jwtCookie, cache, client)
+	sut := NewSecretModel(address, jwtCookie, cache, queue.New(), client)
 
 	got := sut.Init()
 
@@ -37,7 +39,7 @@ func TestSecretModel_Update(t *testing.T) {
 	t.Run("user enter text", func(t *testing.T) {
 		cache := cache.New()
 		client := resty.New()
-		sut := NewSecretModel(address, jwtCookie, cache, client)
+		sut := NewSecretModel(address, jwtCookie, cache, queue.New(), client)
 		msg := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune("text")}
 
 		model, cmd := sut.Update(msg)
@@ -49,7 +51,7 @@ func TestSecretModel_Update(t *testing.T) {
 	t.Run("user exited by ctrl+c", func(t *testing.T) {
 		cache := cache.New()
 		client := resty.New()
-		sut := NewSecretModel(address, jwtCookie, cache, client)
+		sut := NewSecretModel(address, jwtCookie, cache, queue.New(), client)
 		msg := tea.KeyMsg{Type: tea.KeyCtrlC}
 
 		_, cmd := sut.Update(msg)
@@ -59,7 +61,7 @@ func TestSecretModel_Update(t *testing.T) {
 	t.Run("get secret request completed", func(t *testing.T) {
 		cache := cache.New()
 		client := resty.New()
-		sut := tea.Model(NewSecretModel(address, jwtCookie, cache, client))
+		sut := tea.Model(NewSecretModel(address, jwtCookie, cache, queue.New(), client))
 		wantSecret := vault.Secret{ID: "1", Data: "data"}
 		msg := getSecretCompletedMsg{
 			secret: wantSecret,
@@ -79,7 +81,7 @@ func TestSecretModel_Update(t *testing.T) {
 	t.Run("error on get secret", func(t *testing.T) {
 		cache := cache.New()
 		client := resty.New()
-		sut := NewSecretModel(address, jwtCookie, cache, client)
+		sut := NewSecretModel(address, jwtCookie, cache, queue.New(), client)
 		msg := getSecretFailedMsg{err: errors.New("error")}
 
 		model, _ := sut.Update(msg)
@@ -97,7 +99,7 @@ func TestSecretModel_Update(t *testing.T) {
 		cache := cache.New()
 		cache.CacheSecret(secret)
 		client := resty.New()
-		sut := NewSecretModel(address, jwtCookie, cache, client)
+		sut := NewSecretModel(address, jwtCookie, cache, queue.New(), client)
 		const want = http.StatusBadRequest
 		msg := getSecretFailedMsg{
 			statusCode: want,
@@ -110,7 +112,7 @@ func TestSecretModel_Update(t *testing.T) {
 		gotStatusCode := got.failtureStatusCode
 		assert.Equal(t, want, gotStatusCode)
 		assert.True(t, got.isOffline)
-		assert.False(t, got.keys.Save.Enabled())
+		assert.True(t, got.keys.Save.Enabled())
 		assert.Equal(t, secret.Data, got.textarea.Value())
 		assert.True(t, got.dataCached)
 	})
@@ -121,7 +123,7 @@ func TestSecretModel_Update(t *testing.T) {
 		cache := cache.New()
 		cache.CacheSecrets(secrets)
 		client := resty.New()
-		sut := NewSecretModel(address, jwtCookie, cache, client)
+		sut := NewSecretModel(address, jwtCookie, cache, queue.New(), client)
 		const want = http.StatusBadRequest
 		msg := getSecretFailedMsg{
 			secretID:   secrets[0].ID,
@@ -138,7 +140,7 @@ func TestSecretModel_Update(t *testing.T) {
 	t.Run("clear text if failed to get secret and secret is not cached", func(t *testing.T) {
 		cache := cache.New()
 		client := resty.New()
-		sut := NewSecretModel(address, jwtCookie, cache, client)
+		sut := NewSecretModel(address, jwtCookie, cache, queue.New(), client)
 		const want = http.StatusBadRequest
 		msg := getSecretFailedMsg{statusCode: want}
 
@@ -151,7 +153,7 @@ func TestSecretModel_Update(t *testing.T) {
 	t.Run("error", func(t *testing.T) {
 		cache := cache.New()
 		client := resty.New()
-		sut := NewSecretModel(address, jwtCookie, cache, client)
+		sut := NewSecretModel(address, jwtCookie, cache, queue.New(), client)
 		msg := errMsg{err: errors.New("error")}
 
 		model, _ := sut.Update(msg)
@@ -159,14 +161,14 @@ func TestSecretModel_Update(t *testing.T) {
 		got, _ := model.(secretModel)
 		assert.Equal(t, msg.err, got.err)
 		assert.True(t, got.isOffline)
-		assert.False(t, got.keys.Save.Enabled())
+		assert.True(t, got.keys.Save.Enabled())
 	})
 	t.Run("view cached secret when failed to get secret", func(t *testing.T) {
 		cache := cache.New()
 		secret := &vault.Secret{ID: "1", Data: "123"}
 		cache.CacheSecret(secret)
 		client := resty.New()
-		sut := NewSecretModel(address, jwtCookie, cache, client)
+		sut := NewSecretModel(address, jwtCookie, cache, queue.New(), client)
 		const want = http.StatusBadRequest
 		msg := getSecretFailedMsg{
 			statusCode: want,
@@ -182,22 +184,22 @@ func TestSecretModel_Update(t *testing.T) {
 	t.Run("return to list of secrets on esc", func(t *testing.T) {
 		cache := cache.New()
 		client := resty.New()
-		sut := NewSecretModel(address, jwtCookie, cache, client)
+		sut := NewSecretModel(address, jwtCookie, cache, queue.New(), client)
 		msg := tea.KeyMsg{Type: tea.KeyEsc}
 
 		model, cmd := sut.Update(msg)
 
 		_, ok := model.(SecretsModel)
 		assert.True(t, ok)
-		listSecretsCommand := NewListSecretsCommand(address, jwtCookie, client)
-		assertEqualCmd(t, listSecretsCommand.Execute, cmd)
+		syncSecretsCommand := newSyncSecretsCommand(cache.Cursor(), address, jwtCookie, client)
+		assertEqualCmd(t, syncSecretsCommand.execute, cmd)
 	})
 	t.Run("create new secret requested", func(t *testing.T) {
 		want := vault.Secret{}
 		msg := createSecretRequestedMsg{}
 		cache := cache.New()
 		client := resty.New()
-		sut := NewSecretModel(address, jwtCookie, cache, client)
+		sut := NewSecretModel(address, jwtCookie, cache, queue.New(), client)
 
 		model, cmd := sut.Update(msg)
 
@@ -210,7 +212,7 @@ func TestSecretModel_Update(t *testing.T) {
 	t.Run("save secret by ctrl+s", func(t *testing.T) {
 		cache := cache.New()
 		client := resty.New()
-		sut := NewSecretModel(address, jwtCookie, cache, client)
+		sut := NewSecretModel(address, jwtCookie, cache, queue.New(), client)
 		secret := vault.Secret{}
 		sut.secret = secret
 		sut.textarea.SetValue("data")
@@ -223,12 +225,53 @@ func TestSecretModel_Update(t *testing.T) {
 		saveSecretCommand := newSaveSecretCommand(secret, address, jwtCookie, client)
 		assertEqualCmd(t, saveSecretCommand.execute, cmd)
 	})
+	t.Run("save secret offline", func(t *testing.T) {
+		cache := cache.New()
+		queue := queue.New()
+		client := resty.New()
+		sut := NewSecretModel(address, jwtCookie, cache, queue, client)
+		sut.isNew = true
+		sut.setOfflineMode(true)
+		sut.textarea.SetValue("data")
+
+		model, cmd := sut.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+
+		got, ok := model.(secretModel)
+		assert.True(t, ok)
+		assert.Nil(t, cmd)
+		assert.False(t, got.isNew)
+		assert.True(t, got.isQueued)
+		assert.Contains(t, got.View(), savedLocally)
+		op, ok := queue.Next(address)
+		require.True(t, ok)
+		assert.Equal(t, got.secret, op.Secret)
+		assert.Equal(t, "data", op.Secret.Data)
+		cachedSecret, dataCached, ok := cache.GetSecret(op.Secret.ID)
+		assert.True(t, ok)
+		assert.True(t, dataCached)
+		assert.Equal(t, op.Secret, *cachedSecret)
+	})
+	t.Run("save secret while changes are waiting to be sent", func(t *testing.T) {
+		queue := queue.New()
+		pending := vault.Secret{ID: "2", Data: "pending", Revision: 3}
+		queue.Push(newSaveOperation(address, pending))
+		sut := NewSecretModel(address, jwtCookie, cache.New(), queue, resty.New())
+		sut.secret = vault.Secret{ID: "1", Revision: 1}
+		sut.textarea.SetValue("data")
+
+		model, cmd := sut.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+
+		_, ok := model.(secretModel)
+		assert.True(t, ok)
+		assert.Nil(t, cmd)
+		assert.Equal(t, 2, queue.Pending(address))
+	})
 	t.Run("save secret completed", func(t *testing.T) {
 		want := vault.Secret{ID: "1", Data: "data"}
 		msg := saveSecretCompletedMsg{want}
 		cache := cache.New()
 		client := resty.New()
-		sut := NewSecretModel(address, jwtCookie, cache, client)
+		sut := NewSecretModel(address, jwtCookie, cache, queue.New(), client)
 		sut.isNew = true
 
 		model, cmd := sut.Update(msg)
@@ -243,10 +286,75 @@ func TestSecretModel_Update(t *testing.T) {
 		assert.True(t, ok)
 		assert.Equal(t, want, *cachedSecret)
 	})
+	t.Run("secret was changed on another device", func(t *testing.T) {
+		local := vault.Secret{ID: "1", Data: "local data", Revision: 1}
+		remote := vault.Secret{ID: "1", Data: "remote data", Revision: 2}
+		msg := saveSecretConflictMsg{local: local, remote: remote}
+		cache := cache.New()
+		client := resty.New()
+		sut := NewSecretModel(address, jwtCookie, cache, queue.New(), client)
+		sut.secret = local
+		sut.textarea.SetValue(local.Data)
+
+		model, _ := sut.Update(msg)
+
+		got, ok := model.(secretModel)
+		assert.True(t, ok)
+		assert.True(t, got.hasConflict)
+		assert.Equal(t, local.Data, got.textarea.Value())
+		assert.False(t, got.keys.Save.Enabled())
+		assert.True(t, got.keys.Reload.Enabled())
+		assert.True(t, got.keys.Overwrite.Enabled())
+		assert.Contains(t, got.View(), fmt.Sprintf(conflictTemplate, remote.Revision))
+	})
+	t.Run("reload secret changed on another device by ctrl+r", func(t *testing.T) {
+		local := vault.Secret{ID: "1", Data: "local data", Revision: 1}
+		remote := vault.Secret{ID: "1", Data: "remote data", Revision: 2}
+		cache := cache.New()
+		client := resty.New()
+		sut := NewSecretModel(address, jwtCookie, cache, queue.New(), client)
+		sut.secret = local
+		sut.remoteSecret = remote
+		sut.setConflict(true)
+		msg := tea.KeyMsg{Type: tea.KeyCtrlR}
+
+		model, cmd := sut.Update(msg)
+
+		got, ok := model.(secretModel)
+		assert.True(t, ok)
+		assert.Nil(t, cmd)
+		assert.False(t, got.hasConflict)
+		assert.Equal(t, remote, got.secret)
+		assert.Equal(t, remote.Data, got.textarea.Value())
+		cachedSecret, _, ok := cache.GetSecret(remote.ID)
+		assert.True(t, ok)
+		assert.Equal(t, remote, *cachedSecret)
+	})
+	t.Run("overwrite secret changed on another device by ctrl+o", func(t *testing.T) {
+		local := vault.Secret{ID: "1", Data: "local data", Revision: 1}
+		remote := vault.Secret{ID: "1", Data: "remote data", Revision: 2}
+		cache := cache.New()
+		client := resty.New()
+		sut := NewSecretModel(address, jwtCookie, cache, queue.New(), client)
+		sut.secret = local
+		sut.textarea.SetValue(local.Data)
+		sut.remoteSecret = remote
+		sut.setConflict(true)
+		msg := tea.KeyMsg{Type: tea.KeyCtrlO}
+
+		model, cmd := sut.Update(msg)
+
+		got, ok := model.(secretModel)
+		assert.True(t, ok)
+		assert.False(t, got.hasConflict)
+		secret := vault.Secret{ID: local.ID, Data: local.Data, Revision: remote.Revision}
+		saveSecretCommand := newSaveSecretCommand(secret, address, jwtCookie, client)
+		assertEqualCmd(t, saveSecretCommand.execute, cmd)
+	})
 	t.Run("window size changed", func(t *testing.T) {
 		cache := cache.New()
 		client := resty.New()
-		sut := NewSecretModel(address, jwtCookie, cache, client)
+		sut := NewSecretModel(address, jwtCookie, cache, queue.New(), client)
 		msg := tea.WindowSizeMsg{Width: 100}
 		require.NotEqual(t, msg.Width, sut.help.Width)
 
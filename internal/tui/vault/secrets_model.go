@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
@@ -14,12 +15,14 @@ import (
 	"github.com/go-resty/resty/v2"
 
 	"github.com/nestjam/goph-keeper/internal/tui/vault/cache"
+	"github.com/nestjam/goph-keeper/internal/tui/vault/queue"
 	vault "github.com/nestjam/goph-keeper/internal/vault/delivery/http"
 )
 
 const (
 	idColumnIndex  = 1
 	zeroStatusCode = 0
+	retryInterval  = 5 * time.Second
 )
 
 var baseStyle = lipgloss.NewStyle().
@@ -33,10 +36,12 @@ type secretsKeyMap struct {
 	Edit   key.Binding
 	Delete key.Binding
 	Add    key.Binding
+	Vaults key.Binding
+	Logout key.Binding
 }
 
 func (k secretsKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Add, k.Edit, k.Delete, k.Quit}
+	return []key.Binding{k.Add, k.Edit, k.Delete, k.Vaults, k.Logout, k.Quit}
 }
 
 func (k secretsKeyMap) FullHelp() [][]key.Binding {
@@ -48,6 +53,7 @@ type SecretsModel struct {
 	client             *resty.Client
 	jwtCookie          *http.Cookie
 	cache              *cache.SecretsCache
+	queue              *queue.Queue
 	help               help.Model
 	address            string
 	keys               secretsKeyMap
@@ -56,7 +62,13 @@ type SecretsModel struct {
 	isOffline          bool
 }
 
-func NewSecretsModel(addr string, jwt *http.Cookie, cache *cache.SecretsCache, c *resty.Client) SecretsModel {
+func NewSecretsModel(
+	addr string,
+	jwt *http.Cookie,
+	cache *cache.SecretsCache,
+	q *queue.Queue,
+	c *resty.Client,
+) SecretsModel {
 	const (
 		numWidth    = 4
 		idWidth     = 30
@@ -104,6 +116,14 @@ func NewSecretsModel(addr string, jwt *http.Cookie, cache *cache.SecretsCache, c
 			key.WithKeys(tea.KeyDelete.String()),
 			key.WithHelp("del", "delete"),
 		),
+		Vaults: key.NewBinding(
+			key.WithKeys(tea.KeyCtrlT.String()),
+			key.WithHelp("ctrl+t", "vaults"),
+		),
+		Logout: key.NewBinding(
+			key.WithKeys(tea.KeyCtrlL.String()),
+			key.WithHelp("ctrl+l", logoutUser),
+		),
 		Up: key.NewBinding(
 			key.WithKeys(tea.KeyUp.String()),
 			key.WithHelp("↑", "move up"),
@@ -122,6 +142,7 @@ func NewSecretsModel(addr string, jwt *http.Cookie, cache *cache.SecretsCache, c
 		jwtCookie: jwt,
 		table:     t,
 		cache:     cache,
+		queue:     q,
 	}
 }
 
@@ -144,6 +165,16 @@ func (m SecretsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			m.setOfflineMode(false)
 			m.failtureStatusCode = zeroStatusCode
+			return m, m.replay()
+		}
+	case syncSecretsCompletedMsg:
+		{
+			m.cache.ApplyChanges(msg.changes, msg.cursor)
+			m.refreshRows()
+
+			m.setOfflineMode(false)
+			m.failtureStatusCode = zeroStatusCode
+			return m, m.replay()
 		}
 	case listSecretsFailedMsg:
 		{
@@ -154,7 +185,16 @@ func (m SecretsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			secrets := m.cache.ListSecrets()
 			rows := newRows(secrets)
 			m.table.SetRows(rows)
+
+			if m.queue.Pending(m.address) > 0 && isUnavailable(msg.statusCode) {
+				// the changes are sent as soon as the server is available again
+				return m, retrySync()
+			}
 		}
+	case retrySyncMsg:
+		return m, syncSecrets(m.cache, m.address, m.jwtCookie, m.client)
+	case operationReplayedMsg:
+		return m.handleReplayedMsg(msg)
 	case deleteSecretCompletedMsg:
 		{
 			rows := m.table.Rows()
@@ -162,6 +202,15 @@ func (m SecretsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.table.SetRows(rows)
 			m.cache.RemoveSecret(msg.secretID)
 		}
+	case deleteSecretFailedMsg:
+		{
+			m.failtureStatusCode = msg.statusCode
+			if msg.statusCode == http.StatusPreconditionFailed {
+				// secret was changed on another device, so its revision is refreshed
+				m.err = ErrSecretChanged
+				return m, syncSecrets(m.cache, m.address, m.jwtCookie, m.client)
+			}
+		}
 	case errMsg:
 		{
 			m.err = msg.err
@@ -188,6 +237,9 @@ func (m SecretsModel) View() string {
 	if m.failtureStatusCode != zeroStatusCode {
 		s.WriteString(fmt.Sprintf(codeTemplate, m.failtureStatusCode))
 	}
+	if n := m.queue.Pending(m.address); n > 0 {
+		s.WriteString(fmt.Sprintf(pendingTemplate, n))
+	}
 
 	s.WriteString(baseStyle.Render(m.table.View()) + "\n")
 
@@ -199,8 +251,95 @@ func (m SecretsModel) View() string {
 
 func (m *SecretsModel) setOfflineMode(v bool) {
 	m.isOffline = v
-	m.keys.Add.SetEnabled(!v)
-	m.keys.Delete.SetEnabled(!v)
+}
+
+// queued reports whether the changes are to be queued. The changes are queued while the server is unavailable
+// and until the changes queued before are sent, so the changes reach the server in the order they are made.
+func (m SecretsModel) queued() bool {
+	return m.isOffline || m.queue.Pending(m.address) > 0
+}
+
+// replay sends the next change made while the server was unavailable.
+func (m SecretsModel) replay() tea.Cmd {
+	op, ok := m.queue.Next(m.address)
+	if !ok {
+		return nil
+	}
+	return replayOperation(op, m.jwtCookie, m.client)
+}
+
+// handleReplayedMsg removes the sent change from the queue and sends the next one.
+// The change rejected because of the changes made on another device is shown to the user to resolve the conflict.
+// The change stays in the queue if the server is unavailable.
+func (m SecretsModel) handleReplayedMsg(msg operationReplayedMsg) (tea.Model, tea.Cmd) {
+	switch result := msg.result.(type) {
+	case saveSecretCompletedMsg:
+		if queue.IsLocalID(msg.op.Secret.ID) {
+			m.cache.RemoveSecret(msg.op.Secret.ID)
+		}
+		m.cache.CacheSecret(&result.secret)
+	case deleteSecretCompletedMsg:
+		m.cache.RemoveSecret(result.secretID)
+	case saveSecretConflictMsg:
+		m.queue.Done(m.address)
+		model := NewSecretModel(m.address, m.jwtCookie, m.cache, m.queue, m.client)
+		return model.Update(result)
+	case deleteSecretFailedMsg:
+		if !m.dropRejected(result.statusCode) {
+			return m, retrySync()
+		}
+		if result.statusCode == http.StatusPreconditionFailed {
+			m.err = ErrSecretChanged
+			return m, syncSecrets(m.cache, m.address, m.jwtCookie, m.client)
+		}
+		return m, m.replay()
+	case saveSecretFailedMsg:
+		if !m.dropRejected(result.statusCode) {
+			return m, retrySync()
+		}
+		return m, m.replay()
+	case errMsg:
+		m.err = result.err
+		m.setOfflineMode(true)
+		return m, retrySync()
+	}
+
+	m.queue.Done(m.address)
+	m.table.SetRows(newRows(m.cache.ListSecrets()))
+	return m, m.replay()
+}
+
+// isUnavailable reports whether the request failed because the server is unavailable.
+func isUnavailable(statusCode int) bool {
+	return statusCode == zeroStatusCode || statusCode >= http.StatusInternalServerError
+}
+
+// dropRejected removes the change rejected by the server from the queue. The change is kept if the server failed.
+func (m *SecretsModel) dropRejected(statusCode int) bool {
+	m.failtureStatusCode = statusCode
+	if isUnavailable(statusCode) {
+		m.setOfflineMode(true)
+		return false
+	}
+
+	m.queue.Done(m.address)
+	return true
+}
+
+// refreshRows shows the cached secrets in place of the rows shown before.
+// The selection stays on the same secret when the secrets are added or deleted above it.
+func (m *SecretsModel) refreshRows() {
+	var selectedID string
+	if row := m.table.SelectedRow(); row != nil {
+		selectedID = row[idColumnIndex]
+	}
+
+	rows := newRows(m.cache.ListSecrets())
+	m.table.SetRows(rows)
+
+	if i := findIndex(selectedID, rows); i >= 0 {
+		m.table.SetCursor(i)
+	}
 }
 
 func newRows(secrets []*vault.Secret) []table.Row {
@@ -221,23 +360,37 @@ func (m SecretsModel) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, m.keys.Edit):
 		{
 			id := m.getSelectedSecretID()
-			model := NewSecretModel(m.address, m.jwtCookie, m.cache, m.client)
+			model := NewSecretModel(m.address, m.jwtCookie, m.cache, m.queue, m.client)
+			if secret, _, ok := m.cache.GetSecret(id); ok && m.queue.Has(m.address, id) {
+				return model, cachedSecret(*secret)
+			}
 			cmd := getSecret(id, m.address, m.jwtCookie, m.client)
 			return model, cmd
 		}
 	case key.Matches(msg, m.keys.Delete):
 		{
 			id := m.getSelectedSecretID()
+			var revision int64
+			if secret, _, ok := m.cache.GetSecret(id); ok {
+				revision = secret.Revision
+			}
+			if m.queued() {
+				return m.queueDelete(id, revision)
+			}
 			model := m
-			cmd := deleteSecret(id, m.address, m.jwtCookie, m.client)
+			cmd := deleteSecret(id, revision, m.address, m.jwtCookie, m.client)
 			return model, cmd
 		}
 	case key.Matches(msg, m.keys.Add):
 		{
-			model := NewSecretModel(m.address, m.jwtCookie, m.cache, m.client)
+			model := NewSecretModel(m.address, m.jwtCookie, m.cache, m.queue, m.client)
 			cmd := createSecret()
 			return model, cmd
 		}
+	case key.Matches(msg, m.keys.Vaults):
+		return m, showVaults()
+	case key.Matches(msg, m.keys.Logout):
+		return m, logout()
 	default:
 		{
 			var cmd tea.Cmd
@@ -247,6 +400,19 @@ func (m SecretsModel) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 }
 
+// queueDelete deletes the secret locally and queues the delete to send it later.
+func (m SecretsModel) queueDelete(id string, revision int64) (tea.Model, tea.Cmd) {
+	op := queue.Operation{
+		Kind:    queue.KindDelete,
+		Address: m.address,
+		Secret:  vault.Secret{ID: id, Revision: revision},
+	}
+	m.queue.Push(op)
+	m.cache.RemoveSecret(id)
+	m.table.SetRows(deleteRow(m.table.Rows(), id))
+	return m, nil
+}
+
 func (m *SecretsModel) getSelectedSecretID() string {
 	return m.table.SelectedRow()[idColumnIndex]
 }
@@ -256,8 +422,8 @@ func getSecret(id string, addr string, jwt *http.Cookie, client *resty.Client) t
 	return cmd.execute
 }
 
-func deleteSecret(id string, addr string, jwt *http.Cookie, client *resty.Client) tea.Cmd {
-	cmd := newDeleteSecretCommand(id, addr, jwt, client)
+func deleteSecret(id string, revision int64, addr string, jwt *http.Cookie, client *resty.Client) tea.Cmd {
+	cmd := newDeleteSecretCommand(id, revision, addr, jwt, client)
 	return cmd.execute
 }
 
@@ -266,6 +432,32 @@ func createSecret() tea.Cmd {
 	return cmd.execute
 }
 
+func cachedSecret(secret vault.Secret) tea.Cmd {
+	cmd := newCachedSecretCommand(secret)
+	return cmd.execute
+}
+
+func replayOperation(op queue.Operation, jwt *http.Cookie, client *resty.Client) tea.Cmd {
+	cmd := newReplayOperationCommand(op, jwt, client)
+	return cmd.execute
+}
+
+func retrySync() tea.Cmd {
+	return tea.Tick(retryInterval, func(time.Time) tea.Msg {
+		return retrySyncMsg{}
+	})
+}
+
+func logout() tea.Cmd {
+	cmd := newLogoutCommand()
+	return cmd.execute
+}
+
+func showVaults() tea.Cmd {
+	cmd := newShowVaultsCommand()
+	return cmd.execute
+}
+
 func deleteRow(rows []table.Row, id string) []table.Row {
 	i := findIndex(id, rows)
 	if i < 0 {
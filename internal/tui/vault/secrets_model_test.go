@@ -2,6 +2,7 @@ package vault
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"reflect"
 	"testing"
@@ -13,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/nestjam/goph-keeper/internal/tui/vault/cache"
+	"github.com/nestjam/goph-keeper/internal/tui/vault/queue"
 	vault "github.com/nestjam/goph-keeper/internal/vault/delivery/http"
 )
 
@@ -23,7 +25,7 @@ func TestSecretsModel_Init(t *testing.T) {
 		cache     = cache.New()
 		client    = resty.New()
 	)
-	sut := NewSecretsModel(address, jwtCookie, cache, client)
+	sut := NewSecretsModel(address, jwtCookie, cache, queue.New(), client)
 
 	got := sut.Init()
 
@@ -39,7 +41,7 @@ func TestSecretsModel_Update(t *testing.T) {
 	t.Run("user exited by ctrl+c", func(t *testing.T) {
 		cache := cache.New()
 		client := resty.New()
-		sut := NewSecretsModel(address, jwtCookie, cache, client)
+		sut := NewSecretsModel(address, jwtCookie, cache, queue.New(), client)
 		msg := tea.KeyMsg{Type: tea.KeyCtrlC}
 
 		_, cmd := sut.Update(msg)
@@ -49,7 +51,7 @@ func TestSecretsModel_Update(t *testing.T) {
 	t.Run("user exited by esc", func(t *testing.T) {
 		cache := cache.New()
 		client := resty.New()
-		sut := NewSecretsModel(address, jwtCookie, cache, client)
+		sut := NewSecretsModel(address, jwtCookie, cache, queue.New(), client)
 		msg := tea.KeyMsg{Type: tea.KeyEsc}
 
 		_, cmd := sut.Update(msg)
@@ -59,7 +61,7 @@ func TestSecretsModel_Update(t *testing.T) {
 	t.Run("get secrets request completed", func(t *testing.T) {
 		cache := cache.New()
 		client := resty.New()
-		sut := tea.Model(NewSecretsModel(address, jwtCookie, cache, client))
+		sut := tea.Model(NewSecretsModel(address, jwtCookie, cache, queue.New(), client))
 		wantSecrets := []*vault.Secret{
 			{ID: "2", Name: "secret2"},
 			{ID: "3", Name: "secret3"},
@@ -81,10 +83,48 @@ func TestSecretsModel_Update(t *testing.T) {
 		assert.ElementsMatch(t, wantRows, got.table.Rows())
 		assert.False(t, got.isOffline)
 	})
+	t.Run("changes of secrets are synced", func(t *testing.T) {
+		cache := cache.New()
+		client := resty.New()
+		cache.CacheSecrets([]*vault.Secret{{ID: "1", Name: "secret1"}, {ID: "2", Name: "secret2"}})
+		sut := tea.Model(NewSecretsModel(address, jwtCookie, cache, queue.New(), client))
+		msg := syncSecretsCompletedMsg{
+			changes: []vault.Change{
+				{ID: "1", Deleted: true},
+				{ID: "3", Name: "secret3", Revision: 1},
+			},
+			cursor: 7,
+		}
+		wantRows := []table.Row{
+			{"1", "2", "secret2"},
+			{"2", "3", "secret3"},
+		}
+
+		model, cmd := sut.Update(msg)
+
+		got, _ := model.(SecretsModel)
+		assert.Nil(t, cmd)
+		assert.Equal(t, wantRows, got.table.Rows())
+		assert.Equal(t, int64(7), got.cache.Cursor())
+		assert.False(t, got.isOffline)
+	})
+	t.Run("selected secret stays selected after sync", func(t *testing.T) {
+		cache := cache.New()
+		cache.CacheSecrets([]*vault.Secret{{ID: "1", Name: "b"}, {ID: "2", Name: "c"}})
+		sut := NewSecretsModel(address, jwtCookie, cache, queue.New(), resty.New())
+		sut.table.SetRows(newRows(cache.ListSecrets()))
+		sut.table.SetCursor(1)
+		msg := syncSecretsCompletedMsg{changes: []vault.Change{{ID: "3", Name: "a", Revision: 1}}, cursor: 1}
+
+		model, _ := sut.Update(msg)
+
+		got, _ := model.(SecretsModel)
+		assert.Equal(t, "2", got.getSelectedSecretID())
+	})
 	t.Run("user pressed enter on selected row", func(t *testing.T) {
 		cache := cache.New()
 		client := resty.New()
-		sut := NewSecretsModel(address, jwtCookie, cache, client)
+		sut := NewSecretsModel(address, jwtCookie, cache, queue.New(), client)
 		secrets := []*vault.Secret{
 			{ID: "2"},
 			{ID: "3"},
@@ -111,7 +151,7 @@ func TestSecretsModel_Update(t *testing.T) {
 	t.Run("error on get secrets", func(t *testing.T) {
 		cache := cache.New()
 		client := resty.New()
-		sut := NewSecretsModel(address, jwtCookie, cache, client)
+		sut := NewSecretsModel(address, jwtCookie, cache, queue.New(), client)
 		msg := listSecretsFailedMsg{err: errors.New("error")}
 
 		model, _ := sut.Update(msg)
@@ -120,8 +160,8 @@ func TestSecretsModel_Update(t *testing.T) {
 		assert.Equal(t, msg.err, got.err)
 		assert.Equal(t, zeroStatusCode, got.failtureStatusCode)
 		assert.True(t, got.isOffline)
-		assert.False(t, got.keys.Add.Enabled())
-		assert.False(t, got.keys.Delete.Enabled())
+		assert.True(t, got.keys.Add.Enabled())
+		assert.True(t, got.keys.Delete.Enabled())
 	})
 	t.Run("failed to list secrets", func(t *testing.T) {
 		wantRows := []table.Row{
@@ -133,7 +173,7 @@ func TestSecretsModel_Update(t *testing.T) {
 		cache := cache.New()
 		client := resty.New()
 		cache.CacheSecrets(secrets)
-		sut := NewSecretsModel(address, jwtCookie, cache, client)
+		sut := NewSecretsModel(address, jwtCookie, cache, queue.New(), client)
 		const wantStatusCode = http.StatusBadRequest
 		msg := listSecretsFailedMsg{statusCode: wantStatusCode}
 
@@ -143,14 +183,14 @@ func TestSecretsModel_Update(t *testing.T) {
 		assert.Nil(t, got.err)
 		assert.Equal(t, wantStatusCode, got.failtureStatusCode)
 		assert.True(t, got.isOffline)
-		assert.False(t, got.keys.Add.Enabled())
-		assert.False(t, got.keys.Delete.Enabled())
+		assert.True(t, got.keys.Add.Enabled())
+		assert.True(t, got.keys.Delete.Enabled())
 		assert.ElementsMatch(t, wantRows, got.table.Rows())
 	})
 	t.Run("success retry after failed to list secrets", func(t *testing.T) {
 		cache := cache.New()
 		client := resty.New()
-		sut := tea.Model(NewSecretsModel(address, jwtCookie, cache, client))
+		sut := tea.Model(NewSecretsModel(address, jwtCookie, cache, queue.New(), client))
 		secrets := []*vault.Secret{}
 		var msg tea.Msg = listSecretsFailedMsg{statusCode: http.StatusTooManyRequests}
 		sut, _ = sut.Update(msg)
@@ -169,24 +209,27 @@ func TestSecretsModel_Update(t *testing.T) {
 	t.Run("delete selected secret on del", func(t *testing.T) {
 		cache := cache.New()
 		client := resty.New()
-		sut := NewSecretsModel(address, jwtCookie, cache, client)
+		sut := NewSecretsModel(address, jwtCookie, cache, queue.New(), client)
+		const (
+			wantID       = "2"
+			wantRevision = 3
+		)
 		secrets := []*vault.Secret{
-			{ID: "2"},
+			{ID: wantID, Revision: wantRevision},
 		}
 		cache.CacheSecrets(secrets)
 		rows := []table.Row{
-			{"1", "2"},
+			{"1", wantID},
 		}
 		sut.table.SetRows(rows)
 		sut.table.GotoTop()
-		const wantID = "2"
 		msg := tea.KeyMsg{Type: tea.KeyDelete}
 
 		model, cmd := sut.Update(msg)
 
 		_, ok := model.(SecretsModel)
 		assert.True(t, ok)
-		deleteSecretCommand := newDeleteSecretCommand(wantID, address, jwtCookie, client)
+		deleteSecretCommand := newDeleteSecretCommand(wantID, wantRevision, address, jwtCookie, client)
 		assertEqualCmd(t, deleteSecretCommand.execute, cmd)
 	})
 	t.Run("selected secret deleted", func(t *testing.T) {
@@ -197,7 +240,7 @@ func TestSecretsModel_Update(t *testing.T) {
 		cache := cache.New()
 		cache.CacheSecrets(secrets)
 		client := resty.New()
-		sut := NewSecretsModel(address, jwtCookie, cache, client)
+		sut := NewSecretsModel(address, jwtCookie, cache, queue.New(), client)
 		rows := []table.Row{
 			{"1", secretID},
 		}
@@ -213,10 +256,197 @@ func TestSecretsModel_Update(t *testing.T) {
 		cachedSecrets := cache.ListSecrets()
 		assert.Empty(t, cachedSecrets)
 	})
+	t.Run("deleted secret was changed on another device", func(t *testing.T) {
+		cache := cache.New()
+		client := resty.New()
+		sut := NewSecretsModel(address, jwtCookie, cache, queue.New(), client)
+		msg := deleteSecretFailedMsg{http.StatusPreconditionFailed}
+
+		model, cmd := sut.Update(msg)
+
+		got, ok := model.(SecretsModel)
+		assert.True(t, ok)
+		assert.Equal(t, ErrSecretChanged, got.err)
+		assert.Equal(t, http.StatusPreconditionFailed, got.failtureStatusCode)
+		syncSecretsCommand := newSyncSecretsCommand(cache.Cursor(), address, jwtCookie, client)
+		assertEqualCmd(t, syncSecretsCommand.execute, cmd)
+	})
+	t.Run("delete secret offline", func(t *testing.T) {
+		cache := cache.New()
+		queue := queue.New()
+		cache.CacheSecrets([]*vault.Secret{{ID: "1", Revision: 2}, {ID: "2"}})
+		sut := NewSecretsModel(address, jwtCookie, cache, queue, resty.New())
+		sut.setOfflineMode(true)
+		sut.table.SetRows([]table.Row{{"1", "1"}, {"2", "2"}})
+		sut.table.GotoTop()
+
+		model, cmd := sut.Update(tea.KeyMsg{Type: tea.KeyDelete})
+
+		got, _ := model.(SecretsModel)
+		assert.Nil(t, cmd)
+		assert.Equal(t, []table.Row{{"2", "2"}}, got.table.Rows())
+		_, _, ok := cache.GetSecret("1")
+		assert.False(t, ok)
+		op, ok := queue.Next(address)
+		require.True(t, ok)
+		want := newDeleteOperation(address, vault.Secret{ID: "1", Revision: 2})
+		assert.Equal(t, want, op)
+		assert.Contains(t, got.View(), fmt.Sprintf(pendingTemplate, 1))
+	})
+	t.Run("view secret changed offline", func(t *testing.T) {
+		cache := cache.New()
+		queue := queue.New()
+		secret := vault.Secret{ID: "1", Data: "data"}
+		cache.CacheSecret(&secret)
+		queue.Push(newSaveOperation(address, secret))
+		sut := NewSecretsModel(address, jwtCookie, cache, queue, resty.New())
+		sut.table.SetRows([]table.Row{{"1", "1"}})
+
+		model, cmd := sut.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		_, ok := model.(secretModel)
+		assert.True(t, ok)
+		assertEqualCmd(t, newCachedSecretCommand(secret).execute, cmd)
+		assert.Equal(t, getSecretCompletedMsg{secret}, cmd())
+	})
+	t.Run("changes are sent when server is available", func(t *testing.T) {
+		queue := queue.New()
+		client := resty.New()
+		op := newSaveOperation(address, vault.Secret{ID: "1"})
+		queue.Push(op)
+		sut := NewSecretsModel(address, jwtCookie, cache.New(), queue, client)
+		sut.setOfflineMode(true)
+
+		model, cmd := sut.Update(syncSecretsCompletedMsg{})
+
+		got, _ := model.(SecretsModel)
+		assert.False(t, got.isOffline)
+		assertEqualCmd(t, newReplayOperationCommand(op, jwtCookie, client).execute, cmd)
+	})
+	t.Run("sync is retried while changes are waiting to be sent", func(t *testing.T) {
+		queue := queue.New()
+		queue.Push(newSaveOperation(address, vault.Secret{ID: "1"}))
+		sut := NewSecretsModel(address, jwtCookie, cache.New(), queue, resty.New())
+
+		_, cmd := sut.Update(listSecretsFailedMsg{statusCode: http.StatusBadGateway})
+
+		assert.NotNil(t, cmd)
+	})
+	t.Run("sync on retry", func(t *testing.T) {
+		cache := cache.New()
+		client := resty.New()
+		sut := NewSecretsModel(address, jwtCookie, cache, queue.New(), client)
+
+		_, cmd := sut.Update(retrySyncMsg{})
+
+		assertEqualCmd(t, newSyncSecretsCommand(cache.Cursor(), address, jwtCookie, client).execute, cmd)
+	})
+	t.Run("secret created offline is sent", func(t *testing.T) {
+		cache := cache.New()
+		queue := queue.New()
+		local := vault.Secret{ID: "local-1", Name: "secret", Data: "data"}
+		cache.CacheSecret(&local)
+		op := newSaveOperation(address, local)
+		queue.Push(op)
+		sut := NewSecretsModel(address, jwtCookie, cache, queue, resty.New())
+		remote := vault.Secret{ID: "1", Name: "secret", Data: "data", Revision: 1}
+		msg := operationReplayedMsg{op: op, result: saveSecretCompletedMsg{remote}}
+
+		model, cmd := sut.Update(msg)
+
+		got, _ := model.(SecretsModel)
+		assert.Nil(t, cmd)
+		assert.Zero(t, queue.Pending(address))
+		assert.Equal(t, []*vault.Secret{&remote}, cache.ListSecrets())
+		assert.Equal(t, []table.Row{{"1", "1", "secret"}}, got.table.Rows())
+	})
+	t.Run("secret deleted offline is sent", func(t *testing.T) {
+		queue := queue.New()
+		op := newDeleteOperation(address, vault.Secret{ID: "1", Revision: 1})
+		queue.Push(op)
+		sut := NewSecretsModel(address, jwtCookie, cache.New(), queue, resty.New())
+		msg := operationReplayedMsg{op: op, result: deleteSecretCompletedMsg{"1"}}
+
+		_, cmd := sut.Update(msg)
+
+		assert.Nil(t, cmd)
+		assert.Zero(t, queue.Pending(address))
+	})
+	t.Run("secret changed offline was changed on another device", func(t *testing.T) {
+		queue := queue.New()
+		local := vault.Secret{ID: "1", Data: "local data", Revision: 1}
+		remote := vault.Secret{ID: "1", Data: "remote data", Revision: 2}
+		op := newSaveOperation(address, local)
+		queue.Push(op)
+		sut := NewSecretsModel(address, jwtCookie, cache.New(), queue, resty.New())
+		msg := operationReplayedMsg{op: op, result: saveSecretConflictMsg{local: local, remote: remote}}
+
+		model, _ := sut.Update(msg)
+
+		got, ok := model.(secretModel)
+		require.True(t, ok)
+		assert.True(t, got.hasConflict)
+		assert.Equal(t, local, got.secret)
+		assert.Equal(t, local.Data, got.textarea.Value())
+		assert.Equal(t, remote, got.remoteSecret)
+		assert.Zero(t, queue.Pending(address))
+	})
+	t.Run("secret deleted offline was changed on another device", func(t *testing.T) {
+		cache := cache.New()
+		queue := queue.New()
+		client := resty.New()
+		op := newDeleteOperation(address, vault.Secret{ID: "1", Revision: 1})
+		queue.Push(op)
+		sut := NewSecretsModel(address, jwtCookie, cache, queue, client)
+		msg := operationReplayedMsg{op: op, result: deleteSecretFailedMsg{http.StatusPreconditionFailed}}
+
+		model, cmd := sut.Update(msg)
+
+		got, _ := model.(SecretsModel)
+		assert.Equal(t, ErrSecretChanged, got.err)
+		assert.Zero(t, queue.Pending(address))
+		assertEqualCmd(t, newSyncSecretsCommand(cache.Cursor(), address, jwtCookie, client).execute, cmd)
+	})
+	t.Run("server is unavailable to send changes", func(t *testing.T) {
+		queue := queue.New()
+		op := newSaveOperation(address, vault.Secret{ID: "1"})
+		queue.Push(op)
+		sut := NewSecretsModel(address, jwtCookie, cache.New(), queue, resty.New())
+		msg := operationReplayedMsg{op: op, result: errMsg{errors.New("failed")}}
+
+		model, cmd := sut.Update(msg)
+
+		got, _ := model.(SecretsModel)
+		assert.True(t, got.isOffline)
+		assert.NotNil(t, cmd)
+		assert.Equal(t, 1, queue.Pending(address))
+	})
+	t.Run("server rejected changes", func(t *testing.T) {
+		queue := queue.New()
+		op := newSaveOperation(address, vault.Secret{ID: "1"})
+		queue.Push(op)
+		sut := NewSecretsModel(address, jwtCookie, cache.New(), queue, resty.New())
+		msg := operationReplayedMsg{op: op, result: saveSecretFailedMsg{http.StatusBadRequest}}
+
+		model, cmd := sut.Update(msg)
+
+		got, _ := model.(SecretsModel)
+		assert.Nil(t, cmd)
+		assert.Equal(t, http.StatusBadRequest, got.failtureStatusCode)
+		assert.Zero(t, queue.Pending(address))
+	})
+	t.Run("logout by ctrl+l", func(t *testing.T) {
+		sut := NewSecretsModel(address, jwtCookie, cache.New(), queue.New(), resty.New())
+
+		_, cmd := sut.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+
+		assertEqualCmd(t, newLogoutCommand().execute, cmd)
+		assert.Equal(t, logoutRequestedMsg{}, cmd())
+	})
 	t.Run("add new secret by ctrl+n", func(t *testing.T) {
 		cache := cache.New()
 		client := resty.New()
-		sut := NewSecretsModel(address, jwtCookie, cache, client)
+		sut := NewSecretsModel(address, jwtCookie, cache, queue.New(), client)
 		msg := tea.KeyMsg{Type: tea.KeyCtrlN}
 
 		model, cmd := sut.Update(msg)
@@ -229,7 +459,7 @@ func TestSecretsModel_Update(t *testing.T) {
 	t.Run("window size changed", func(t *testing.T) {
 		cache := cache.New()
 		client := resty.New()
-		sut := NewSecretsModel(address, jwtCookie, cache, client)
+		sut := NewSecretsModel(address, jwtCookie, cache, queue.New(), client)
 		msg := tea.WindowSizeMsg{Width: 100}
 		require.NotEqual(t, msg.Width, sut.help.Width)
 
@@ -241,6 +471,14 @@ func TestSecretsModel_Update(t *testing.T) {
 	})
 }
 
+func newSaveOperation(addr string, secret vault.Secret) queue.Operation {
+	return queue.Operation{Kind: queue.KindSave, Address: addr, Secret: secret}
+}
+
+func newDeleteOperation(addr string, secret vault.Secret) queue.Operation {
+	return queue.Operation{Kind: queue.KindDelete, Address: addr, Secret: secret}
+}
+
 func assertEqualCmd(t *testing.T, want, got tea.Cmd) {
 	t.Helper()
 
@@ -0,0 +1,16 @@
+package vault
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type showVaultsCommand struct {
+}
+
+func newShowVaultsCommand() showVaultsCommand {
+	return showVaultsCommand{}
+}
+
+func (c showVaultsCommand) execute() tea.Msg {
+	return showVaultsRequestedMsg{}
+}
@@ -0,0 +1,51 @@
+package vault
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-resty/resty/v2"
+
+	httpVault "github.com/nestjam/goph-keeper/internal/vault/delivery/http"
+)
+
+type syncSecretsCommand struct {
+	client    *resty.Client
+	jwtCookie *http.Cookie
+	address   string
+	cursor    int64
+}
+
+func newSyncSecretsCommand(cursor int64, addr string, jwt *http.Cookie, client *resty.Client) syncSecretsCommand {
+	return syncSecretsCommand{
+		cursor:    cursor,
+		address:   addr,
+		jwtCookie: jwt,
+		client:    client,
+	}
+}
+
+func (c syncSecretsCommand) execute() tea.Msg {
+	url, err := url.JoinPath(c.address, syncURL)
+	if err != nil {
+		return listSecretsFailedMsg{err: err}
+	}
+
+	var res httpVault.SyncResponse
+	resp, err := c.client.R().
+		SetResult(&res).
+		SetCookie(c.jwtCookie).
+		SetQueryParam(sinceParam, strconv.FormatInt(c.cursor, 10)).
+		Get(url)
+	if err != nil {
+		return listSecretsFailedMsg{err: err}
+	}
+
+	if resp.IsSuccess() {
+		return syncSecretsCompletedMsg{changes: res.Changes, cursor: res.Cursor}
+	}
+
+	return listSecretsFailedMsg{statusCode: resp.StatusCode()}
+}
@@ -0,0 +1,83 @@
+package vault
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+
+	vaultHttp "github.com/nestjam/goph-keeper/internal/vault/delivery/http"
+)
+
+func TestSyncSecretsCommand(t *testing.T) {
+	t.Run("sync changes since cursor", func(t *testing.T) {
+		wantChanges := []vaultHttp.Change{
+			{ID: "1", Name: "secret", Revision: 2},
+			{ID: "2", Deleted: true},
+		}
+		const wantURL = "/sync?since=5"
+		wantCookie := &http.Cookie{
+			Name: "auth",
+		}
+		var gotURL string
+		var gotCookie *http.Cookie
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotURL = r.URL.String()
+			gotCookie = findCookie(r.Cookies(), "auth")
+			resp := vaultHttp.SyncResponse{Changes: wantChanges, Cursor: 9}
+			_ = writeJSON(w, http.StatusOK, resp)
+		}))
+		defer server.Close()
+		client := resty.New()
+		sut := newSyncSecretsCommand(5, server.URL, wantCookie, client)
+
+		got := sut.execute()
+
+		assert.Equal(t, wantURL, gotURL)
+		assert.Equal(t, wantCookie, gotCookie)
+		want := syncSecretsCompletedMsg{changes: wantChanges, cursor: 9}
+		assert.Equal(t, want, got)
+	})
+	t.Run("invalid server address", func(t *testing.T) {
+		sut := syncSecretsCommand{
+			address: string([]byte{0x7f}), // ASCII control character
+		}
+
+		msg := sut.execute()
+
+		got, ok := msg.(listSecretsFailedMsg)
+		assert.True(t, ok)
+		assert.NotNil(t, got.err)
+		assert.Equal(t, zeroStatusCode, got.statusCode)
+	})
+	t.Run("failed to connect server", func(t *testing.T) {
+		server := httptest.NewServer(nil)
+		serverURL := server.URL
+		server.Close()
+		client := resty.New()
+		sut := newSyncSecretsCommand(0, serverURL, &http.Cookie{}, client)
+
+		msg := sut.execute()
+
+		got, ok := msg.(listSecretsFailedMsg)
+		assert.True(t, ok)
+		assert.NotNil(t, got.err)
+		assert.Equal(t, zeroStatusCode, got.statusCode)
+	})
+	t.Run("request is not successful", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+		client := resty.New()
+		sut := newSyncSecretsCommand(0, server.URL, &http.Cookie{}, client)
+
+		got := sut.execute()
+
+		msg, ok := got.(listSecretsFailedMsg)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusInternalServerError, msg.statusCode)
+	})
+}
@@ -0,0 +1,367 @@
+package vault
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/go-resty/resty/v2"
+
+	httpOrg "github.com/nestjam/goph-keeper/internal/org/delivery/http"
+	"github.com/nestjam/goph-keeper/internal/tui/vault/cache"
+)
+
+type vaultsKeyMap struct {
+	Quit   key.Binding
+	Up     key.Binding
+	Down   key.Binding
+	Open   key.Binding
+	Logout key.Binding
+}
+
+func (k vaultsKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Open, k.Logout, k.Quit}
+}
+
+func (k vaultsKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{}
+}
+
+// VaultsModel navigates between the personal vault of the user and team vaults shared with the user.
+// Secrets of the open vault are shown by the child model. The store of the vaults is saved on every change.
+// The model watches changes of the open vault made on other devices and syncs them as soon as they are made.
+type VaultsModel struct {
+	err                error
+	child              tea.Model
+	client             *resty.Client
+	jwtCookie          *http.Cookie
+	store              *cache.Store
+	stream             *changesStream
+	help               help.Model
+	address            string
+	vaultID            string
+	vaultAddress       string
+	keys               vaultsKeyMap
+	table              table.Model
+	failtureStatusCode int
+	// confirmingLogout is set once the user is warned that logout discards the changes not sent to the server.
+	confirmingLogout bool
+}
+
+// NewVaultsModel returns the model with the personal vault open.
+// The store keeps the caches of the vaults and the changes made while the server is unavailable.
+func NewVaultsModel(addr string, jwt *http.Cookie, store *cache.Store, c *resty.Client) VaultsModel {
+	const (
+		numWidth    = 4
+		idWidth     = 36
+		orgWidth    = 20
+		nameWidth   = 20
+		roleWidth   = 8
+		tableHeight = 10
+	)
+	columns := []table.Column{
+		{Title: "#", Width: numWidth},
+		{Title: "ID", Width: idWidth},
+		{Title: "Organization", Width: orgWidth},
+		{Title: "Vault", Width: nameWidth},
+		{Title: "Role", Width: roleWidth},
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithFocused(true),
+		table.WithHeight(tableHeight),
+	)
+
+	s := table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(false)
+	s.Selected = s.Selected.
+		Foreground(lipgloss.Color("229")).
+		Background(lipgloss.Color("57")).
+		Bold(false)
+	t.SetStyles(s)
+
+	keys := vaultsKeyMap{
+		Quit: key.NewBinding(
+			key.WithKeys(tea.KeyEsc.String(), tea.KeyCtrlC.String()),
+			key.WithHelp("ctr+c", quitApp),
+		),
+		Open: key.NewBinding(
+			key.WithKeys(tea.KeyEnter.String()),
+			key.WithHelp("enter", "open"),
+		),
+		Logout: key.NewBinding(
+			key.WithKeys(tea.KeyCtrlL.String()),
+			key.WithHelp("ctrl+l", logoutUser),
+		),
+		Up: key.NewBinding(
+			key.WithKeys(tea.KeyUp.String()),
+			key.WithHelp("↑", "move up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys(tea.KeyDown.String()),
+			key.WithHelp("↓", "move down"),
+		),
+	}
+
+	return VaultsModel{
+		// the personal vault is keyed by empty id
+		child:        NewSecretsModel(addr, jwt, store.Cache(""), store.Queue(), c),
+		keys:         keys,
+		help:         help.New(),
+		address:      addr,
+		vaultAddress: addr,
+		client:       c,
+		jwtCookie:    jwt,
+		table:        t,
+		store:        store,
+	}
+}
+
+// Init syncs the personal vault and watches its changes.
+func (m VaultsModel) Init() tea.Cmd {
+	return tea.Batch(m.sync(), watchChanges(m.vaultAddress, m.jwtCookie, m.client))
+}
+
+func (m VaultsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+
+	// the changes are saved as soon as they are made, so they outlive the client
+	if err := m.store.Save(); err != nil {
+		model.err = err
+	}
+
+	return model, cmd
+}
+
+func (m VaultsModel) update(msg tea.Msg) (VaultsModel, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && !key.Matches(msg, m.keys.Logout) {
+		m.confirmingLogout = false
+	}
+
+	switch msg := msg.(type) {
+	case showVaultsRequestedMsg:
+		m.closeStream()
+		m.vaultAddress = ""
+		m.child = nil
+		m.err = nil
+		m.failtureStatusCode = zeroStatusCode
+		return m, listVaults(m.address, m.jwtCookie, m.client)
+	case logoutRequestedMsg:
+		return m.logout()
+	case changesWatchedMsg:
+		return m.openStream(msg.stream)
+	case changesEventMsg:
+		return m.handleChangesEvent(msg)
+	case changesStreamClosedMsg:
+		if msg.stream != m.stream {
+			return m, nil
+		}
+		m.stream = nil
+		return m, rewatchChanges(m.vaultAddress)
+	case watchChangesFailedMsg:
+		if msg.address != m.vaultAddress || m.stream != nil || !isUnavailable(msg.statusCode) {
+			return m, nil
+		}
+		return m, rewatchChanges(msg.address)
+	case rewatchChangesMsg:
+		if msg.address != m.vaultAddress || m.stream != nil {
+			return m, nil
+		}
+		return m, watchChanges(msg.address, m.jwtCookie, m.client)
+	}
+
+	if m.child != nil {
+		var cmd tea.Cmd
+		m.child, cmd = m.child.Update(msg)
+		return m, cmd
+	}
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.help.Width = msg.Width
+	case tea.KeyMsg:
+		return m.handleKeyMsg(msg)
+	case listVaultsCompletedMsg:
+		m.table.SetRows(newVaultRows(msg.vaults))
+	case listVaultsFailedMsg:
+		{
+			m.err = msg.err
+			m.failtureStatusCode = msg.statusCode
+			// the personal vault is available anyway
+			m.table.SetRows(newVaultRows(nil))
+		}
+	}
+
+	return m, nil
+}
+
+func (m VaultsModel) View() string {
+	s := strings.Builder{}
+
+	if m.err != nil {
+		s.WriteString(fmt.Sprintf(errTemplate, m.err.Error()))
+	}
+
+	if m.child != nil {
+		s.WriteString(m.child.View())
+		return s.String()
+	}
+
+	if m.failtureStatusCode != zeroStatusCode {
+		s.WriteString(fmt.Sprintf(codeTemplate, m.failtureStatusCode))
+	}
+
+	s.WriteString(baseStyle.Render(m.table.View()) + "\n")
+
+	s.WriteString("\n")
+	s.WriteString(m.help.View(m.keys))
+
+	return s.String()
+}
+
+func (m VaultsModel) handleKeyMsg(msg tea.KeyMsg) (VaultsModel, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Logout):
+		return m.logout()
+	case key.Matches(msg, m.keys.Open):
+		row := m.table.SelectedRow()
+		if row == nil {
+			return m, nil
+		}
+		return m.openVault(row[idColumnIndex])
+	default:
+		{
+			var cmd tea.Cmd
+			m.table, cmd = m.table.Update(msg)
+			return m, cmd
+		}
+	}
+}
+
+// logout wipes the secrets kept locally, ends the session on the server and quits.
+// The changes not sent to the server are wiped only if the user requests logout once again.
+func (m VaultsModel) logout() (VaultsModel, tea.Cmd) {
+	if m.store.Queue().Len() > 0 && !m.confirmingLogout {
+		m.confirmingLogout = true
+		m.err = ErrChangesNotSent
+		return m, nil
+	}
+
+	if err := m.store.Wipe(); err != nil {
+		m.err = err
+		return m, nil
+	}
+	m.closeStream()
+	return m, endSession(m.address, m.jwtCookie, m.client)
+}
+
+// openVault shows secrets of the vault. Secrets of every vault are cached separately.
+func (m VaultsModel) openVault(id string) (VaultsModel, tea.Cmd) {
+	addr := m.address
+	if id != "" {
+		var err error
+		addr, err = url.JoinPath(m.address, vaultsURL, id)
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+	}
+
+	m.closeStream()
+	m.vaultID = id
+	m.vaultAddress = addr
+	m.child = NewSecretsModel(addr, m.jwtCookie, m.store.Cache(id), m.store.Queue(), m.client)
+	return m, tea.Batch(m.sync(), watchChanges(addr, m.jwtCookie, m.client))
+}
+
+// sync syncs the secrets of the open vault.
+func (m VaultsModel) sync() tea.Cmd {
+	return syncSecrets(m.store.Cache(m.vaultID), m.vaultAddress, m.jwtCookie, m.client)
+}
+
+// openStream starts reading the stream of changes of the open vault. The vault may be closed or have the stream
+// already by the time the stream is opened, so the vault keeps a single stream and closes the others.
+func (m VaultsModel) openStream(stream *changesStream) (VaultsModel, tea.Cmd) {
+	if m.stream != nil || stream.address != m.vaultAddress {
+		stream.close()
+		return m, nil
+	}
+
+	m.stream = stream
+	// the changes made while the vault was not watched are synced
+	return m, tea.Batch(m.sync(), nextChange(stream))
+}
+
+// handleChangesEvent syncs the changes of the open vault, so the child model refreshes the secrets.
+func (m VaultsModel) handleChangesEvent(msg changesEventMsg) (VaultsModel, tea.Cmd) {
+	if msg.stream != m.stream {
+		return m, nil
+	}
+	if !msg.changed {
+		return m, nextChange(m.stream)
+	}
+	return m, tea.Batch(m.sync(), nextChange(m.stream))
+}
+
+func (m *VaultsModel) closeStream() {
+	if m.stream == nil {
+		return
+	}
+	m.stream.close()
+	m.stream = nil
+}
+
+func listVaults(addr string, jwt *http.Cookie, client *resty.Client) tea.Cmd {
+	cmd := newListVaultsCommand(addr, jwt, client)
+	return cmd.execute
+}
+
+func watchChanges(addr string, jwt *http.Cookie, client *resty.Client) tea.Cmd {
+	cmd := newWatchChangesCommand(addr, jwt, client)
+	return cmd.execute
+}
+
+func endSession(addr string, jwt *http.Cookie, client *resty.Client) tea.Cmd {
+	cmd := newEndSessionCommand(addr, jwt, client)
+	return cmd.execute
+}
+
+func nextChange(stream *changesStream) tea.Cmd {
+	cmd := newNextChangeCommand(stream)
+	return cmd.execute
+}
+
+// rewatchChanges watches changes of the vault again after the stream is broken.
+func rewatchChanges(addr string) tea.Cmd {
+	return tea.Tick(retryInterval, func(time.Time) tea.Msg {
+		return rewatchChangesMsg{address: addr}
+	})
+}
+
+// newVaultRows returns rows of the personal vault followed by the team vaults.
+func newVaultRows(vaults []httpOrg.Vault) []table.Row {
+	rows := make([]table.Row, 0, len(vaults)+1)
+	rows = append(rows, table.Row{"1", "", "", personal, ""})
+
+	for i := 0; i < len(vaults); i++ {
+		v := vaults[i]
+		rows = append(rows, table.Row{strconv.Itoa(i + 2), v.ID, v.OrgName, v.Name, v.Role})
+	}
+
+	return rows
+}
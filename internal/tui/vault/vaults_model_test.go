@@ -0,0 +1,287 @@
+package vault
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	httpOrg "github.com/nestjam/goph-keeper/internal/org/delivery/http"
+	"github.com/nestjam/goph-keeper/internal/tui/vault/cache"
+	"github.com/nestjam/goph-keeper/internal/tui/vault/queue"
+	vault "github.com/nestjam/goph-keeper/internal/vault/delivery/http"
+)
+
+func TestVaultsModel_Update(t *testing.T) {
+	const address = "http://localhost"
+	jwtCookie := &http.Cookie{}
+
+	t.Run("personal vault is open", func(t *testing.T) {
+		sut := NewVaultsModel(address, jwtCookie, cache.NewStore(), resty.New())
+		msg := listSecretsCompletedMsg{secrets: []*vault.Secret{{ID: "1", Name: "secret"}}}
+
+		model, _ := sut.Update(msg)
+
+		got := model.(VaultsModel)
+		child, ok := got.child.(SecretsModel)
+		require.True(t, ok)
+		assert.Equal(t, address, child.address)
+		assert.Len(t, got.store.Cache("").ListSecrets(), 1)
+	})
+	t.Run("user requested vaults", func(t *testing.T) {
+		client := resty.New()
+		sut := tea.Model(NewVaultsModel(address, jwtCookie, cache.NewStore(), client))
+
+		model, cmd := sut.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+		model, cmd = model.Update(cmd())
+
+		got := model.(VaultsModel)
+		assert.Nil(t, got.child)
+		assertEqualCmd(t, newListVaultsCommand(address, jwtCookie, client).execute, cmd)
+	})
+	t.Run("list vaults completed", func(t *testing.T) {
+		sut := NewVaultsModel(address, jwtCookie, cache.NewStore(), resty.New())
+		sut.child = nil
+		msg := listVaultsCompletedMsg{
+			vaults: []httpOrg.Vault{{ID: "1", Name: "servers", OrgName: "team", Role: "editor"}},
+		}
+
+		model, _ := sut.Update(msg)
+
+		got := model.(VaultsModel)
+		want := []table.Row{
+			{"1", "", "", personal, ""},
+			{"2", "1", "team", "servers", "editor"},
+		}
+		assert.Equal(t, want, got.table.Rows())
+	})
+	t.Run("list vaults failed", func(t *testing.T) {
+		sut := NewVaultsModel(address, jwtCookie, cache.NewStore(), resty.New())
+		sut.child = nil
+		msg := listVaultsFailedMsg{statusCode: http.StatusInternalServerError}
+
+		model, _ := sut.Update(msg)
+
+		got := model.(VaultsModel)
+		assert.Equal(t, http.StatusInternalServerError, got.failtureStatusCode)
+		assert.Equal(t, []table.Row{{"1", "", "", personal, ""}}, got.table.Rows())
+	})
+	t.Run("user opened team vault", func(t *testing.T) {
+		client := resty.New()
+		sut := NewVaultsModel(address, jwtCookie, cache.NewStore(), client)
+		sut.child = nil
+		sut.table.SetRows(newVaultRows([]httpOrg.Vault{{ID: "id"}}))
+		sut.table.MoveDown(1)
+
+		model, cmd := sut.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		got := model.(VaultsModel)
+		child, ok := got.child.(SecretsModel)
+		require.True(t, ok)
+		assert.Equal(t, address+"/vaults/id", child.address)
+		assert.Same(t, got.store.Cache("id"), child.cache)
+		assert.NotSame(t, got.store.Cache(""), child.cache)
+		cmds := cmd().(tea.BatchMsg)
+		require.Len(t, cmds, 2)
+		assertEqualCmd(t, newSyncSecretsCommand(0, "", nil, client).execute, cmds[0])
+		assertEqualCmd(t, newWatchChangesCommand("", nil, client).execute, cmds[1])
+	})
+	t.Run("user exited", func(t *testing.T) {
+		sut := NewVaultsModel(address, jwtCookie, cache.NewStore(), resty.New())
+		sut.child = nil
+
+		_, cmd := sut.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+
+		assertEqualCmd(t, tea.Quit, cmd)
+	})
+	t.Run("changes are saved to store", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "store.cache")
+		store, err := cache.CreateStore(path, "password")
+		require.NoError(t, err)
+		sut := NewVaultsModel(address, jwtCookie, store, resty.New())
+		msg := syncSecretsCompletedMsg{changes: []vault.Change{{ID: "1", Name: "secret", Revision: 1}}, cursor: 1}
+
+		_, _ = sut.Update(msg)
+
+		got, err := cache.OpenStore(path, "password")
+		require.NoError(t, err)
+		assert.Len(t, got.Cache("").ListSecrets(), 1)
+	})
+	t.Run("user logged out", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "store.cache")
+		store, err := cache.CreateStore(path, "password")
+		require.NoError(t, err)
+		store.Cache("").CacheSecret(&vault.Secret{ID: "1"})
+		require.NoError(t, store.Save())
+		client := resty.New()
+		sut := tea.Model(NewVaultsModel(address, jwtCookie, store, client))
+
+		model, cmd := sut.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+		_, cmd = model.Update(cmd())
+
+		assertEqualCmd(t, newEndSessionCommand("", nil, client).execute, cmd)
+		assert.NoFileExists(t, path)
+		assert.Empty(t, store.Cache("").ListSecrets())
+	})
+	t.Run("logout with changes not sent is confirmed", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "store.cache")
+		store, err := cache.CreateStore(path, "password")
+		require.NoError(t, err)
+		store.Queue().Push(queue.Operation{Kind: queue.KindSave, Address: address, Secret: vault.Secret{ID: "1"}})
+		require.NoError(t, store.Save())
+		client := resty.New()
+		sut := tea.Model(NewVaultsModel(address, jwtCookie, store, client))
+
+		model, cmd := sut.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+		model, cmd = model.Update(cmd())
+
+		assert.Nil(t, cmd)
+		assert.ErrorIs(t, model.(VaultsModel).err, ErrChangesNotSent)
+		assert.FileExists(t, path)
+
+		model, cmd = model.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+		_, cmd = model.Update(cmd())
+
+		assertEqualCmd(t, newEndSessionCommand("", nil, client).execute, cmd)
+		assert.NoFileExists(t, path)
+	})
+	t.Run("logout is not confirmed by other keys", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "store.cache")
+		store, err := cache.CreateStore(path, "password")
+		require.NoError(t, err)
+		store.Queue().Push(queue.Operation{Kind: queue.KindSave, Address: address, Secret: vault.Secret{ID: "1"}})
+		require.NoError(t, store.Save())
+		sut := tea.Model(NewVaultsModel(address, jwtCookie, store, resty.New()))
+
+		model, cmd := sut.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+		model, _ = model.Update(cmd())
+		model, _ = model.Update(tea.KeyMsg{Type: tea.KeyDown})
+		model, cmd = model.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+		_, cmd = model.Update(cmd())
+
+		assert.Nil(t, cmd)
+		assert.FileExists(t, path)
+	})
+	t.Run("changes of open vault are synced", func(t *testing.T) {
+		client := resty.New()
+		sut := NewVaultsModel(address, jwtCookie, cache.NewStore(), client)
+		stream := newChangesStream(address, io.NopCloser(strings.NewReader("")))
+		model, _ := sut.Update(changesWatchedMsg{stream})
+
+		model, cmd := model.Update(changesEventMsg{stream: stream, changed: true})
+
+		got := model.(VaultsModel)
+		assert.Same(t, stream, got.stream)
+		cmds := cmd().(tea.BatchMsg)
+		require.Len(t, cmds, 2)
+		assertEqualCmd(t, newSyncSecretsCommand(0, "", nil, client).execute, cmds[0])
+		assertEqualCmd(t, newNextChangeCommand(nil).execute, cmds[1])
+	})
+	t.Run("event other than change", func(t *testing.T) {
+		sut := NewVaultsModel(address, jwtCookie, cache.NewStore(), resty.New())
+		stream := newChangesStream(address, io.NopCloser(strings.NewReader("")))
+		sut.stream = stream
+
+		_, cmd := sut.Update(changesEventMsg{stream: stream})
+
+		assertEqualCmd(t, newNextChangeCommand(nil).execute, cmd)
+	})
+	t.Run("stream of closed vault is closed", func(t *testing.T) {
+		sut := NewVaultsModel(address, jwtCookie, cache.NewStore(), resty.New())
+		sut.child = nil
+		sut.vaultAddress = ""
+		body := &bodySpy{}
+
+		model, cmd := sut.Update(changesWatchedMsg{newChangesStream(address, body)})
+
+		got := model.(VaultsModel)
+		assert.Nil(t, got.stream)
+		assert.True(t, body.closed)
+		assert.Nil(t, cmd)
+	})
+	t.Run("stream is closed when vaults are shown", func(t *testing.T) {
+		sut := NewVaultsModel(address, jwtCookie, cache.NewStore(), resty.New())
+		body := &bodySpy{}
+		sut.stream = newChangesStream(address, body)
+
+		model, _ := sut.Update(showVaultsRequestedMsg{})
+
+		got := model.(VaultsModel)
+		assert.Nil(t, got.stream)
+		assert.True(t, body.closed)
+	})
+	t.Run("broken stream is watched again", func(t *testing.T) {
+		sut := NewVaultsModel(address, jwtCookie, cache.NewStore(), resty.New())
+		stream := newChangesStream(address, io.NopCloser(strings.NewReader("")))
+		sut.stream = stream
+
+		model, cmd := sut.Update(changesStreamClosedMsg{stream})
+
+		got := model.(VaultsModel)
+		assert.Nil(t, got.stream)
+		assert.NotNil(t, cmd)
+	})
+	t.Run("vault is watched again when server is available", func(t *testing.T) {
+		client := resty.New()
+		sut := NewVaultsModel(address, jwtCookie, cache.NewStore(), client)
+
+		_, cmd := sut.Update(rewatchChangesMsg{address: address})
+
+		assertEqualCmd(t, newWatchChangesCommand("", nil, client).execute, cmd)
+	})
+	t.Run("vault is not watched again if access is denied", func(t *testing.T) {
+		sut := NewVaultsModel(address, jwtCookie, cache.NewStore(), resty.New())
+
+		_, cmd := sut.Update(watchChangesFailedMsg{address: address, statusCode: http.StatusUnauthorized})
+
+		assert.Nil(t, cmd)
+	})
+	t.Run("user logged out from list of vaults", func(t *testing.T) {
+		store := cache.NewStore()
+		store.Cache("").CacheSecret(&vault.Secret{ID: "1"})
+		client := resty.New()
+		sut := NewVaultsModel(address, jwtCookie, store, client)
+		sut.child = nil
+
+		_, cmd := sut.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+
+		assertEqualCmd(t, newEndSessionCommand("", nil, client).execute, cmd)
+		assert.Empty(t, store.Cache("").ListSecrets())
+	})
+}
+
+func TestVaultsModel_Init(t *testing.T) {
+	const address = "http://localhost"
+	jwtCookie := &http.Cookie{}
+	store := cache.NewStore()
+	store.Cache("").ApplyChanges(nil, 3)
+	client := resty.New()
+	sut := NewVaultsModel(address, jwtCookie, store, client)
+
+	got := sut.Init()
+
+	cmds := got().(tea.BatchMsg)
+	require.Len(t, cmds, 2)
+	assertEqualCmd(t, newSyncSecretsCommand(3, address, jwtCookie, client).execute, cmds[0])
+	assertEqualCmd(t, newWatchChangesCommand(address, jwtCookie, client).execute, cmds[1])
+}
+
+type bodySpy struct {
+	closed bool
+}
+
+func (b *bodySpy) Read([]byte) (int, error) {
+	return 0, io.EOF
+}
+
+func (b *bodySpy) Close() error {
+	b.closed = true
+	return nil
+}
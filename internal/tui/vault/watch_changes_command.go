@@ -0,0 +1,63 @@
+package vault
+
+import (
+	"net/http"
+	"net/url"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/go-resty/resty/v2"
+)
+
+type watchChangesCommand struct {
+	client    *resty.Client
+	jwtCookie *http.Cookie
+	address   string
+}
+
+func newWatchChangesCommand(addr string, jwt *http.Cookie, client *resty.Client) watchChangesCommand {
+	return watchChangesCommand{
+		address:   addr,
+		jwtCookie: jwt,
+		client:    client,
+	}
+}
+
+func (c watchChangesCommand) execute() tea.Msg {
+	url, err := url.JoinPath(c.address, eventsURL)
+	if err != nil {
+		return watchChangesFailedMsg{address: c.address, err: err}
+	}
+
+	resp, err := c.client.R().
+		SetCookie(c.jwtCookie).
+		SetHeader(acceptHeader, textEventStream).
+		SetDoNotParseResponse(true).
+		Get(url)
+	if err != nil {
+		return watchChangesFailedMsg{address: c.address, err: err}
+	}
+
+	if !resp.IsSuccess() {
+		_ = resp.RawBody().Close()
+		return watchChangesFailedMsg{address: c.address, statusCode: resp.StatusCode()}
+	}
+
+	return changesWatchedMsg{newChangesStream(c.address, resp.RawBody())}
+}
+
+type nextChangeCommand struct {
+	stream *changesStream
+}
+
+func newNextChangeCommand(stream *changesStream) nextChangeCommand {
+	return nextChangeCommand{stream: stream}
+}
+
+func (c nextChangeCommand) execute() tea.Msg {
+	changed, err := c.stream.next()
+	if err != nil {
+		return changesStreamClosedMsg{stream: c.stream}
+	}
+
+	return changesEventMsg{stream: c.stream, changed: changed}
+}
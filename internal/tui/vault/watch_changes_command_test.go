@@ -0,0 +1,118 @@
+package vault
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchChangesCommand(t *testing.T) {
+	t.Run("stream of changes is opened", func(t *testing.T) {
+		wantCookie := &http.Cookie{
+			Name: "auth",
+		}
+		var gotURL, gotAccept string
+		var gotCookie *http.Cookie
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotURL = r.URL.String()
+			gotAccept = r.Header.Get(acceptHeader)
+			gotCookie = findCookie(r.Cookies(), "auth")
+			w.Header().Set("Content-Type", textEventStream)
+			_, _ = io.WriteString(w, "event: change\ndata: {\"id\":\"1\"}\n\n")
+		}))
+		defer server.Close()
+		sut := newWatchChangesCommand(server.URL, wantCookie, resty.New())
+
+		got := sut.execute()
+
+		msg, ok := got.(changesWatchedMsg)
+		require.True(t, ok)
+		t.Cleanup(msg.stream.close)
+		assert.Equal(t, "/events", gotURL)
+		assert.Equal(t, textEventStream, gotAccept)
+		assert.Equal(t, wantCookie, gotCookie)
+		assert.Equal(t, server.URL, msg.stream.address)
+		changed, err := msg.stream.next()
+		require.NoError(t, err)
+		assert.True(t, changed)
+	})
+	t.Run("invalid server address", func(t *testing.T) {
+		address := string([]byte{0x7f}) // ASCII control character
+		sut := newWatchChangesCommand(address, &http.Cookie{}, resty.New())
+
+		msg := sut.execute()
+
+		got, ok := msg.(watchChangesFailedMsg)
+		require.True(t, ok)
+		assert.Error(t, got.err)
+		assert.Equal(t, address, got.address)
+	})
+	t.Run("failed to connect server", func(t *testing.T) {
+		server := httptest.NewServer(nil)
+		serverURL := server.URL
+		server.Close()
+		sut := newWatchChangesCommand(serverURL, &http.Cookie{}, resty.New())
+
+		msg := sut.execute()
+
+		got, ok := msg.(watchChangesFailedMsg)
+		require.True(t, ok)
+		assert.Error(t, got.err)
+	})
+	t.Run("watch is not successful", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+		sut := newWatchChangesCommand(server.URL, &http.Cookie{}, resty.New())
+
+		got := sut.execute()
+
+		want := watchChangesFailedMsg{address: server.URL, statusCode: http.StatusForbidden}
+		assert.Equal(t, want, got)
+	})
+}
+
+func TestNextChangeCommand(t *testing.T) {
+	t.Run("change event", func(t *testing.T) {
+		body := "event: change\r\ndata: {\"id\":\"1\"}\r\n\r\n"
+		stream := newChangesStream("", io.NopCloser(strings.NewReader(body)))
+		sut := newNextChangeCommand(stream)
+
+		got := sut.execute()
+
+		assert.Equal(t, changesEventMsg{stream: stream, changed: true}, got)
+	})
+	t.Run("heartbeat", func(t *testing.T) {
+		body := ": heartbeat\n\nevent: change\ndata: {}\n\n"
+		stream := newChangesStream("", io.NopCloser(strings.NewReader(body)))
+		sut := newNextChangeCommand(stream)
+
+		got := sut.execute()
+
+		assert.Equal(t, changesEventMsg{stream: stream, changed: true}, got)
+	})
+	t.Run("unknown event", func(t *testing.T) {
+		body := "event: unknown\ndata: {}\n\n"
+		stream := newChangesStream("", io.NopCloser(strings.NewReader(body)))
+		sut := newNextChangeCommand(stream)
+
+		got := sut.execute()
+
+		assert.Equal(t, changesEventMsg{stream: stream}, got)
+	})
+	t.Run("stream is closed", func(t *testing.T) {
+		stream := newChangesStream("", io.NopCloser(strings.NewReader("event: change\n")))
+		sut := newNextChangeCommand(stream)
+
+		got := sut.execute()
+
+		assert.Equal(t, changesStreamClosedMsg{stream: stream}, got)
+	})
+}
@@ -2,6 +2,8 @@ package utils
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -14,6 +16,8 @@ import (
 	"github.com/pkg/errors"
 )
 
+var ErrInvalidPEM = errors.New("invalid pem block")
+
 // GenerateCert generates self-signed certificate and private key.
 func GenerateCert() (certPEM bytes.Buffer, privateKeyPEM bytes.Buffer, err error) {
 	const (
@@ -76,3 +80,165 @@ func GenerateCert() (certPEM bytes.Buffer, privateKeyPEM bytes.Buffer, err error
 
 	return
 }
+
+// GenerateCA generates self-signed certificate and private key of the CA that issues client certificates.
+func GenerateCA() (certPEM bytes.Buffer, privateKeyPEM bytes.Buffer, err error) {
+	const (
+		op    = "generate ca"
+		years = 10
+	)
+
+	serialNumber, err := newSerialNumber()
+	if err != nil {
+		err = errors.Wrap(err, op)
+		return
+	}
+
+	cert := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName:   "goph-keeper client CA",
+			Organization: []string{"None"},
+			Country:      []string{"RU"},
+		},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().AddDate(years, 0, 0),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		err = errors.Wrap(err, op)
+		return
+	}
+
+	certPEM, privateKeyPEM, err = encodeCert(cert, cert, privateKey, privateKey)
+	if err != nil {
+		err = errors.Wrap(err, op)
+		return
+	}
+
+	return
+}
+
+// GenerateClientCert generates the client certificate of the subject with the common name and its private key.
+// The certificate is issued by the CA of PEM encoded certificate and private key.
+func GenerateClientCert(caCertPEM, caKeyPEM []byte, commonName string) (
+	certPEM bytes.Buffer, privateKeyPEM bytes.Buffer, err error) {
+	const (
+		op    = "generate client cert"
+		years = 1
+	)
+
+	ca, caKey, err := parseCA(caCertPEM, caKeyPEM)
+	if err != nil {
+		err = errors.Wrap(err, op)
+		return
+	}
+
+	serialNumber, err := newSerialNumber()
+	if err != nil {
+		err = errors.Wrap(err, op)
+		return
+	}
+
+	cert := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName: commonName,
+		},
+		NotBefore:   time.Now(),
+		NotAfter:    time.Now().AddDate(years, 0, 0),
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+	}
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		err = errors.Wrap(err, op)
+		return
+	}
+
+	certPEM, privateKeyPEM, err = encodeCert(cert, ca, privateKey, caKey)
+	if err != nil {
+		err = errors.Wrap(err, op)
+		return
+	}
+
+	return
+}
+
+func newSerialNumber() (*big.Int, error) {
+	const bits = 128
+	limit := new(big.Int).Lsh(big.NewInt(1), bits)
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "new serial number")
+	}
+
+	return n, nil
+}
+
+func parseCA(certPEM, keyPEM []byte) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	const op = "parse ca"
+
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, nil, errors.Wrap(ErrInvalidPEM, op)
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, op)
+	}
+
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, nil, errors.Wrap(ErrInvalidPEM, op)
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, op)
+	}
+
+	return cert, key, nil
+}
+
+// encodeCert signs the certificate by the parent and returns it PEM encoded along with its private key.
+func encodeCert(cert, parent *x509.Certificate, privateKey, parentKey *ecdsa.PrivateKey) (
+	certPEM bytes.Buffer, privateKeyPEM bytes.Buffer, err error) {
+	const op = "encode cert"
+
+	certBytes, err := x509.CreateCertificate(rand.Reader, cert, parent, &privateKey.PublicKey, parentKey)
+	if err != nil {
+		err = errors.Wrap(err, op)
+		return
+	}
+
+	keyBytes, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		err = errors.Wrap(err, op)
+		return
+	}
+
+	err = pem.Encode(&certPEM, &pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: certBytes,
+	})
+	if err != nil {
+		err = errors.Wrap(err, op)
+		return
+	}
+
+	err = pem.Encode(&privateKeyPEM, &pem.Block{
+		Type:  "EC PRIVATE KEY",
+		Bytes: keyBytes,
+	})
+	if err != nil {
+		err = errors.Wrap(err, op)
+		return
+	}
+
+	return
+}
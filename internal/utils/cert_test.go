@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateClientCert(t *testing.T) {
+	t.Run("client cert is issued by ca", func(t *testing.T) {
+		caCert, caKey, err := GenerateCA()
+		require.NoError(t, err)
+
+		certPEM, keyPEM, err := GenerateClientCert(caCert.Bytes(), caKey.Bytes(), "ci")
+
+		require.NoError(t, err)
+		_, err = tls.X509KeyPair(certPEM.Bytes(), keyPEM.Bytes())
+		require.NoError(t, err)
+		block, _ := pem.Decode(certPEM.Bytes())
+		require.NotNil(t, block)
+		cert, err := x509.ParseCertificate(block.Bytes)
+		require.NoError(t, err)
+		assert.Equal(t, "ci", cert.Subject.CommonName)
+		roots := x509.NewCertPool()
+		require.True(t, roots.AppendCertsFromPEM(caCert.Bytes()))
+		opts := x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}
+		_, err = cert.Verify(opts)
+		assert.NoError(t, err)
+	})
+	t.Run("invalid ca", func(t *testing.T) {
+		_, _, err := GenerateClientCert([]byte("cert"), []byte("key"), "ci")
+
+		require.ErrorIs(t, err, ErrInvalidPEM)
+	})
+}
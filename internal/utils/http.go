@@ -2,51 +2,358 @@ package utils
 
 import (
 	"context"
+	"crypto/x509"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/jwtauth/v5"
 	"github.com/google/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/pkg/errors"
 
 	"github.com/nestjam/goph-keeper/internal/config"
 )
 
 const (
-	JWTCookieName = "jwt"
-	UserIDClaim   = "user_id"
-	JWTAlg        = "HS256"
+	JWTCookieName     = "jwt"
+	RefreshCookieName = "refresh_token"
+	UserIDClaim       = "user_id"
+	SessionIDClaim    = "session_id"
+	TokenUseClaim     = "token_use"
+	JWTAlg            = "HS256"
+	// AccessTokenPrefix tells personal access tokens from the access tokens of sessions.
+	AccessTokenPrefix = "gkp_"
+
+	challengeTokenUse = "challenge"
+	challengeExpiryIn = 5 * time.Minute
 )
 
 var (
-	ErrUserIDNotFound = errors.New("user id not found")
-	ErrInvalidUserID  = errors.New("invalid user id")
+	ErrUserIDNotFound       = errors.New("user id not found")
+	ErrInvalidUserID        = errors.New("invalid user id")
+	ErrSessionIDNotFound    = errors.New("session id not found")
+	ErrInvalidChallenge     = errors.New("invalid challenge token")
+	ErrInvalidAccessToken   = errors.New("invalid access token")
+	ErrCertificateNotMapped = errors.New("certificate is not mapped to user")
 )
 
+// SessionChecker tells whether the session the access token is issued for is revoked.
+type SessionChecker interface {
+	IsSessionRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error)
+}
+
+// AccessGrant is what the personal access token of the request allows.
+type AccessGrant struct {
+	UserID uuid.UUID
+	// VaultID limits the token to the shared vault, the token is accepted in all vaults of the user if it is nil.
+	VaultID  uuid.UUID
+	ReadOnly bool
+}
+
+// AccessTokenVerifier returns the grant of the personal access token.
+// It fails with ErrInvalidAccessToken if the token is unknown or expired.
+type AccessTokenVerifier interface {
+	VerifyAccessToken(ctx context.Context, token string) (*AccessGrant, error)
+}
+
+// CertificateAuthenticator returns the user the verified client certificate of mutual TLS authenticates.
+// It fails with ErrCertificateNotMapped if the certificate only identifies the device.
+type CertificateAuthenticator interface {
+	CertificateUser(ctx context.Context, cert *x509.Certificate) (uuid.UUID, error)
+}
+
+type accessGrantKey struct{}
+
 type AuthCookieBaker struct {
-	auth   *jwtauth.JWTAuth
-	config config.JWTAuthConfig
+	keys     *JWTKeys
+	sessions SessionChecker
+	tokens   AccessTokenVerifier
+	certs    CertificateAuthenticator
+	config   config.JWTAuthConfig
+}
+
+type AuthCookieBakerOption func(*AuthCookieBaker)
+
+// WithSessionChecker makes the middlewares reject access tokens of revoked sessions,
+// so the tokens of the ended sessions are not accepted until they expire.
+func WithSessionChecker(sessions SessionChecker) AuthCookieBakerOption {
+	return func(h *AuthCookieBaker) {
+		h.sessions = sessions
+	}
+}
+
+// WithJWTKeys makes the baker sign and verify tokens with the keys instead of the sign key of the config.
+// Tokens signed with any of the keys are accepted, so the active key is rotated without ending sessions.
+func WithJWTKeys(keys *JWTKeys) AuthCookieBakerOption {
+	return func(h *AuthCookieBaker) {
+		h.keys = keys
+	}
+}
+
+// WithAccessTokenVerifier makes the middlewares accept personal access tokens in the authorization header.
+// It is meant for the routes of secrets only, automation does not manage the account, audit or organizations.
+func WithAccessTokenVerifier(tokens AccessTokenVerifier) AuthCookieBakerOption {
+	return func(h *AuthCookieBaker) {
+		h.tokens = tokens
+	}
 }
 
-func NewAuthCookieBaker(config config.JWTAuthConfig) *AuthCookieBaker {
-	return &AuthCookieBaker{
-		auth:   jwtauth.New(JWTAlg, []byte(config.SignKey), nil),
+// WithCertificateAuthenticator makes the middlewares authenticate requests without tokens
+// by the client certificate of mutual TLS. Like personal access tokens, it is meant for the routes of secrets only.
+func WithCertificateAuthenticator(certs CertificateAuthenticator) AuthCookieBakerOption {
+	return func(h *AuthCookieBaker) {
+		h.certs = certs
+	}
+}
+
+func NewAuthCookieBaker(config config.JWTAuthConfig, opts ...AuthCookieBakerOption) *AuthCookieBaker {
+	h := &AuthCookieBaker{
+		keys:   newHMACKeys(config.SignKey),
 		config: config,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
-func (h *AuthCookieBaker) JWTAuth() *jwtauth.JWTAuth {
-	return h.auth
+// PublicKeys returns the keys clients verify access tokens with.
+func (h *AuthCookieBaker) PublicKeys() jwk.Set {
+	return h.keys.PublicKeys()
 }
 
-func (h *AuthCookieBaker) BakeCookie(userID uuid.UUID) (*http.Cookie, error) {
+// Middlewares authenticate requests by the access token of the auth cookie,
+// by the personal access token if the baker has the verifier of them
+// or by the client certificate if the baker has the authenticator of them.
+func (h *AuthCookieBaker) Middlewares() chi.Middlewares {
+	return chi.Middlewares{h.authenticator}
+}
+
+// authenticator passes requests with personal access tokens to their verifier, the rest to the session chain.
+func (h *AuthCookieBaker) authenticator(next http.Handler) http.Handler {
+	session := h.verifier(accessToken(h.activeSession(next)))
+	if h.certs != nil {
+		session = h.clientCertificate(session, next)
+	}
+	if h.tokens == nil {
+		return session
+	}
+
+	personal := h.personalToken(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(jwtauth.TokenFromHeader(r), AccessTokenPrefix) {
+			personal.ServeHTTP(w, r)
+			return
+		}
+		session.ServeHTTP(w, r)
+	})
+}
+
+// personalToken puts the grant of the personal access token to the request context along with the token
+// of its user.
+func (h *AuthCookieBaker) personalToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		grant, err := h.tokens.VerifyAccessToken(ctx, jwtauth.TokenFromHeader(r))
+		if errors.Is(err, ErrInvalidAccessToken) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		ctx, err = contextWithUser(ctx, grant.UserID)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		ctx = context.WithValue(ctx, accessGrantKey{}, grant)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// clientCertificate authenticates requests without tokens by the verified client certificate
+// if it is mapped to the user, the rest are passed to the session chain.
+func (h *AuthCookieBaker) clientCertificate(session, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hasToken := jwtauth.TokenFromHeader(r) != "" || jwtauth.TokenFromCookie(r) != ""
+		if hasToken || r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			session.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		userID, err := h.certs.CertificateUser(ctx, r.TLS.VerifiedChains[0][0])
+		if errors.Is(err, ErrCertificateNotMapped) {
+			session.ServeHTTP(w, r)
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		ctx, err = contextWithUser(ctx, userID)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// contextWithUser puts the token of the user to the context, so handlers get the user
+// the same way as for the access token of a session.
+func contextWithUser(ctx context.Context, userID uuid.UUID) (context.Context, error) {
+	token := jwt.New()
+	if err := token.Set(UserIDClaim, userID.String()); err != nil {
+		return nil, errors.Wrap(err, "context with user")
+	}
+
+	return jwtauth.NewContext(ctx, token, nil), nil
+}
+
+// verifier puts the verified token of the authorization header or the auth cookie to the request context.
+func (h *AuthCookieBaker) verifier(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := jwtauth.TokenFromHeader(r)
+		if s == "" {
+			s = jwtauth.TokenFromCookie(r)
+		}
+		if s == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		token, err := h.keys.Verify(s)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		ctx := jwtauth.NewContext(r.Context(), token, nil)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// accessToken rejects tokens issued for other uses, e.g. the challenge token can not be used as the access token.
+func accessToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, claims, err := jwtauth.FromContext(r.Context())
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if _, ok := claims[TokenUseClaim]; ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// activeSession rejects the access token if its session is revoked, the token stays valid otherwise until it expires.
+func (h *AuthCookieBaker) activeSession(next http.Handler) http.Handler {
+	if h.sessions == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		sessionID, err := SessionFromContext(ctx)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		revoked, err := h.sessions.IsSessionRevoked(ctx, sessionID)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if revoked {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Reauthenticated cancels the context of the long-lived request, e.g. the event stream, once the request
+// is no longer authenticated: its access token expires, the session is revoked or the personal access token
+// is deleted. The request is authenticated again with the interval.
+func (h *AuthCookieBaker) Reauthenticated(interval time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithCancel(r.Context())
+			defer cancel()
+
+			go func() {
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						if !h.authenticated(r.WithContext(ctx)) {
+							cancel()
+							return
+						}
+					}
+				}
+			}()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// authenticated tells whether the authenticator passes the request.
+func (h *AuthCookieBaker) authenticated(r *http.Request) bool {
+	passed := false
+	pass := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		passed = true
+	})
+
+	h.authenticator(pass).ServeHTTP(discardWriter{header: make(http.Header)}, r)
+	return passed
+}
+
+// discardWriter drops the response of the request authenticated again.
+type discardWriter struct {
+	header http.Header
+}
+
+func (w discardWriter) Header() http.Header {
+	return w.header
+}
+
+func (w discardWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w discardWriter) WriteHeader(int) {}
+
+// BakeCookie returns auth cookie with the access token of the user session.
+func (h *AuthCookieBaker) BakeCookie(userID, sessionID uuid.UUID) (*http.Cookie, error) {
 	const op = "bake cookie"
 
 	claims := make(map[string]interface{})
 	claims[UserIDClaim] = userID.String()
+	claims[SessionIDClaim] = sessionID.String()
 	jwtauth.SetExpiryIn(claims, h.config.TokenExpiryIn)
 
-	_, token, err := h.auth.Encode(claims)
+	token, err := h.keys.Sign(claims)
 	if err != nil {
 		return nil, errors.Wrap(err, op)
 	}
@@ -60,6 +367,73 @@ func (h *AuthCookieBaker) BakeCookie(userID uuid.UUID) (*http.Cookie, error) {
 	return cookie, nil
 }
 
+// BakeChallenge returns the short-lived token the user exchanges for the session along with the second factor code.
+func (h *AuthCookieBaker) BakeChallenge(userID uuid.UUID) (string, error) {
+	const op = "bake challenge"
+
+	claims := make(map[string]interface{})
+	claims[UserIDClaim] = userID.String()
+	claims[TokenUseClaim] = challengeTokenUse
+	jwtauth.SetExpiryIn(claims, challengeExpiryIn)
+
+	token, err := h.keys.Sign(claims)
+	if err != nil {
+		return "", errors.Wrap(err, op)
+	}
+
+	return token, nil
+}
+
+// ParseChallenge returns the user the challenge token is issued for if the token is valid.
+func (h *AuthCookieBaker) ParseChallenge(challenge string) (uuid.UUID, error) {
+	token, err := h.keys.Verify(challenge)
+	if err != nil {
+		return uuid.Nil, ErrInvalidChallenge
+	}
+
+	use, _ := token.Get(TokenUseClaim)
+	if use != challengeTokenUse {
+		return uuid.Nil, ErrInvalidChallenge
+	}
+
+	claim, _ := token.Get(UserIDClaim)
+	s, _ := claim.(string)
+	userID, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.Nil, ErrInvalidChallenge
+	}
+
+	return userID, nil
+}
+
+// BakeRefreshCookie returns cookie with the refresh token the client exchanges for the next access token.
+func (h *AuthCookieBaker) BakeRefreshCookie(token string) *http.Cookie {
+	return &http.Cookie{
+		Name:     RefreshCookieName,
+		Value:    token,
+		MaxAge:   int(h.config.RefreshTokenExpiryIn / time.Second),
+		HttpOnly: true,
+	}
+}
+
+// ExpiredCookie returns auth cookie that makes a client drop the token.
+func (h *AuthCookieBaker) ExpiredCookie() *http.Cookie {
+	return &http.Cookie{
+		Name:     JWTCookieName,
+		MaxAge:   -1,
+		HttpOnly: true,
+	}
+}
+
+// ExpiredRefreshCookie returns refresh cookie that makes a client drop the refresh token.
+func (h *AuthCookieBaker) ExpiredRefreshCookie() *http.Cookie {
+	return &http.Cookie{
+		Name:     RefreshCookieName,
+		MaxAge:   -1,
+		HttpOnly: true,
+	}
+}
+
 func UserFromContext(ctx context.Context) (uuid.UUID, error) {
 	const op = "user from context"
 	_, claims, err := jwtauth.FromContext(ctx)
@@ -80,3 +454,32 @@ func UserFromContext(ctx context.Context) (uuid.UUID, error) {
 
 	return userID, nil
 }
+
+// SessionFromContext returns the session the access token of the request is issued for.
+func SessionFromContext(ctx context.Context) (uuid.UUID, error) {
+	const op = "session from context"
+	_, claims, err := jwtauth.FromContext(ctx)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	claim, ok := claims[SessionIDClaim]
+	if !ok {
+		return uuid.Nil, errors.Wrap(ErrSessionIDNotFound, op)
+	}
+
+	s, _ := claim.(string)
+	sessionID, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	return sessionID, nil
+}
+
+// AccessGrantFromContext returns the grant of the personal access token the request is authenticated by.
+// It reports false if the request is authenticated by the access token of a session.
+func AccessGrantFromContext(ctx context.Context) (*AccessGrant, bool) {
+	grant, ok := ctx.Value(accessGrantKey{}).(*AccessGrant)
+	return grant, ok
+}
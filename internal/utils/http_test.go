@@ -2,7 +2,13 @@ package utils
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -72,15 +78,383 @@ func TestBakeCookie(t *testing.T) {
 	}
 	sut := NewAuthCookieBaker(config)
 	userID := uuid.New()
+	sessionID := uuid.New()
 	wantMaxAge := int(config.TokenExpiryIn / time.Second)
 
-	cookie, err := sut.BakeCookie(userID)
+	cookie, err := sut.BakeCookie(userID, sessionID)
 
 	require.NoError(t, err)
 	assert.Equal(t, true, cookie.HttpOnly)
 	assert.Equal(t, JWTCookieName, cookie.Name)
 	assert.Equal(t, wantMaxAge, cookie.MaxAge)
 	assertAuthToken(t, userID, cookie.Value, config.SignKey)
+	token, err := sut.keys.Verify(cookie.Value)
+	require.NoError(t, err)
+	assert.Equal(t, sessionID.String(), token.PrivateClaims()[SessionIDClaim])
+}
+
+func TestSessionFromContext(t *testing.T) {
+	t.Run("context contains jwt token with session id", func(t *testing.T) {
+		ctx := context.Background()
+		want := uuid.New()
+		token := jwt.New()
+		err := token.Set(SessionIDClaim, want.String())
+		require.NoError(t, err)
+		ctx = context.WithValue(ctx, jwtauth.TokenCtxKey, token)
+
+		got, err := SessionFromContext(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+	t.Run("jwt claims does not contain session id", func(t *testing.T) {
+		ctx := context.Background()
+		token := jwt.New()
+		ctx = context.WithValue(ctx, jwtauth.TokenCtxKey, token)
+
+		_, got := SessionFromContext(ctx)
+
+		require.ErrorIs(t, got, ErrSessionIDNotFound)
+	})
+}
+
+func TestMiddlewares(t *testing.T) {
+	config := config.JWTAuthConfig{
+		SignKey:       "secret",
+		TokenExpiryIn: time.Minute,
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("session is active", func(t *testing.T) {
+		sessions := sessionCheckerFunc(func(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+			return false, nil
+		})
+		sut := NewAuthCookieBaker(config, WithSessionChecker(sessions))
+		r := newAuthenticatedRequest(t, sut)
+		w := httptest.NewRecorder()
+
+		sut.Middlewares().Handler(ok).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+	t.Run("session is revoked", func(t *testing.T) {
+		sessions := sessionCheckerFunc(func(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+			return true, nil
+		})
+		sut := NewAuthCookieBaker(config, WithSessionChecker(sessions))
+		r := newAuthenticatedRequest(t, sut)
+		w := httptest.NewRecorder()
+
+		sut.Middlewares().Handler(ok).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+	t.Run("failed to check session", func(t *testing.T) {
+		sessions := sessionCheckerFunc(func(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+			return false, errors.New("failed")
+		})
+		sut := NewAuthCookieBaker(config, WithSessionChecker(sessions))
+		r := newAuthenticatedRequest(t, sut)
+		w := httptest.NewRecorder()
+
+		sut.Middlewares().Handler(ok).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+	t.Run("request is not authenticated", func(t *testing.T) {
+		sut := NewAuthCookieBaker(config)
+		r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		w := httptest.NewRecorder()
+
+		sut.Middlewares().Handler(ok).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+	t.Run("challenge token is not access token", func(t *testing.T) {
+		sut := NewAuthCookieBaker(config)
+		challenge, err := sut.BakeChallenge(uuid.New())
+		require.NoError(t, err)
+		r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		r.Header.Set("Authorization", "Bearer "+challenge)
+		w := httptest.NewRecorder()
+
+		sut.Middlewares().Handler(ok).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+	t.Run("personal access token", func(t *testing.T) {
+		want := &AccessGrant{UserID: uuid.New(), VaultID: uuid.New(), ReadOnly: true}
+		tokens := accessTokenVerifierFunc(func(ctx context.Context, token string) (*AccessGrant, error) {
+			return want, nil
+		})
+		sut := NewAuthCookieBaker(config, WithAccessTokenVerifier(tokens))
+		r := newPersonalTokenRequest(t)
+		w := httptest.NewRecorder()
+		var (
+			got    *AccessGrant
+			userID uuid.UUID
+		)
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, _ = AccessGrantFromContext(r.Context())
+			userID, _ = UserFromContext(r.Context())
+		})
+
+		sut.Middlewares().Handler(handler).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, want, got)
+		assert.Equal(t, want.UserID, userID)
+	})
+	t.Run("personal access token is invalid", func(t *testing.T) {
+		tokens := accessTokenVerifierFunc(func(ctx context.Context, token string) (*AccessGrant, error) {
+			return nil, ErrInvalidAccessToken
+		})
+		sut := NewAuthCookieBaker(config, WithAccessTokenVerifier(tokens))
+		r := newPersonalTokenRequest(t)
+		w := httptest.NewRecorder()
+
+		sut.Middlewares().Handler(ok).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+	t.Run("failed to verify personal access token", func(t *testing.T) {
+		tokens := accessTokenVerifierFunc(func(ctx context.Context, token string) (*AccessGrant, error) {
+			return nil, errors.New("failed")
+		})
+		sut := NewAuthCookieBaker(config, WithAccessTokenVerifier(tokens))
+		r := newPersonalTokenRequest(t)
+		w := httptest.NewRecorder()
+
+		sut.Middlewares().Handler(ok).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+	t.Run("personal access tokens are not accepted without verifier", func(t *testing.T) {
+		sut := NewAuthCookieBaker(config)
+		r := newPersonalTokenRequest(t)
+		w := httptest.NewRecorder()
+
+		sut.Middlewares().Handler(ok).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+	t.Run("session is authenticated along with personal access tokens", func(t *testing.T) {
+		tokens := accessTokenVerifierFunc(func(ctx context.Context, token string) (*AccessGrant, error) {
+			return nil, ErrInvalidAccessToken
+		})
+		sut := NewAuthCookieBaker(config, WithAccessTokenVerifier(tokens))
+		r := newAuthenticatedRequest(t, sut)
+		w := httptest.NewRecorder()
+		var personal bool
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, personal = AccessGrantFromContext(r.Context())
+		})
+
+		sut.Middlewares().Handler(handler).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.False(t, personal)
+	})
+	t.Run("client certificate of user", func(t *testing.T) {
+		want := uuid.New()
+		var subject string
+		certs := certificateAuthenticatorFunc(func(ctx context.Context, cert *x509.Certificate) (uuid.UUID, error) {
+			subject = cert.Subject.CommonName
+			return want, nil
+		})
+		sut := NewAuthCookieBaker(config, WithCertificateAuthenticator(certs))
+		r := newClientCertificateRequest(t, "ci")
+		w := httptest.NewRecorder()
+		var userID uuid.UUID
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, _ = UserFromContext(r.Context())
+		})
+
+		sut.Middlewares().Handler(handler).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "ci", subject)
+		assert.Equal(t, want, userID)
+	})
+	t.Run("client certificate of device", func(t *testing.T) {
+		certs := certificateAuthenticatorFunc(func(ctx context.Context, cert *x509.Certificate) (uuid.UUID, error) {
+			return uuid.Nil, ErrCertificateNotMapped
+		})
+		sut := NewAuthCookieBaker(config, WithCertificateAuthenticator(certs))
+		r := newClientCertificateRequest(t, "laptop")
+		w := httptest.NewRecorder()
+
+		sut.Middlewares().Handler(ok).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+	t.Run("failed to authenticate client certificate", func(t *testing.T) {
+		certs := certificateAuthenticatorFunc(func(ctx context.Context, cert *x509.Certificate) (uuid.UUID, error) {
+			return uuid.Nil, errors.New("failed")
+		})
+		sut := NewAuthCookieBaker(config, WithCertificateAuthenticator(certs))
+		r := newClientCertificateRequest(t, "ci")
+		w := httptest.NewRecorder()
+
+		sut.Middlewares().Handler(ok).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+	t.Run("access token takes precedence over client certificate", func(t *testing.T) {
+		var called bool
+		certs := certificateAuthenticatorFunc(func(ctx context.Context, cert *x509.Certificate) (uuid.UUID, error) {
+			called = true
+			return uuid.New(), nil
+		})
+		sut := NewAuthCookieBaker(config, WithCertificateAuthenticator(certs))
+		r := newAuthenticatedRequest(t, sut)
+		r.TLS = newClientCertificateRequest(t, "ci").TLS
+		w := httptest.NewRecorder()
+
+		sut.Middlewares().Handler(ok).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.False(t, called)
+	})
+	t.Run("client certificates are not accepted without authenticator", func(t *testing.T) {
+		sut := NewAuthCookieBaker(config)
+		r := newClientCertificateRequest(t, "ci")
+		w := httptest.NewRecorder()
+
+		sut.Middlewares().Handler(ok).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestReauthenticated(t *testing.T) {
+	config := config.JWTAuthConfig{
+		SignKey:       "secret",
+		TokenExpiryIn: time.Minute,
+	}
+	const interval = time.Millisecond
+
+	t.Run("request ends when session is revoked", func(t *testing.T) {
+		var revoked atomic.Bool
+		sessions := sessionCheckerFunc(func(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+			return revoked.Load(), nil
+		})
+		sut := NewAuthCookieBaker(config, WithSessionChecker(sessions))
+		r := newAuthenticatedRequest(t, sut)
+		w := httptest.NewRecorder()
+		stream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			revoked.Store(true)
+			<-r.Context().Done()
+		})
+
+		sut.Middlewares().Handler(sut.Reauthenticated(interval)(stream)).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+	t.Run("request goes on while it is authenticated", func(t *testing.T) {
+		sut := NewAuthCookieBaker(config)
+		r := newAuthenticatedRequest(t, sut)
+		w := httptest.NewRecorder()
+		var err error
+		stream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+				err = r.Context().Err()
+			case <-time.After(10 * interval):
+			}
+		})
+
+		sut.Middlewares().Handler(sut.Reauthenticated(interval)(stream)).ServeHTTP(w, r)
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestParseChallenge(t *testing.T) {
+	config := config.JWTAuthConfig{
+		SignKey:       "secret",
+		TokenExpiryIn: time.Minute,
+	}
+
+	t.Run("challenge token of user", func(t *testing.T) {
+		sut := NewAuthCookieBaker(config)
+		want := uuid.New()
+		challenge, err := sut.BakeChallenge(want)
+		require.NoError(t, err)
+
+		got, err := sut.ParseChallenge(challenge)
+
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+	t.Run("access token is not challenge token", func(t *testing.T) {
+		sut := NewAuthCookieBaker(config)
+		cookie, err := sut.BakeCookie(uuid.New(), uuid.New())
+		require.NoError(t, err)
+
+		_, err = sut.ParseChallenge(cookie.Value)
+
+		require.ErrorIs(t, err, ErrInvalidChallenge)
+	})
+	t.Run("challenge token is signed by another key", func(t *testing.T) {
+		another := NewAuthCookieBaker(config)
+		challenge, err := another.BakeChallenge(uuid.New())
+		require.NoError(t, err)
+		anotherConfig := config
+		anotherConfig.SignKey = "another secret"
+		sut := NewAuthCookieBaker(anotherConfig)
+
+		_, err = sut.ParseChallenge(challenge)
+
+		require.ErrorIs(t, err, ErrInvalidChallenge)
+	})
+}
+
+type sessionCheckerFunc func(ctx context.Context, sessionID uuid.UUID) (bool, error)
+
+func (f sessionCheckerFunc) IsSessionRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	return f(ctx, sessionID)
+}
+
+type accessTokenVerifierFunc func(ctx context.Context, token string) (*AccessGrant, error)
+
+func (f accessTokenVerifierFunc) VerifyAccessToken(ctx context.Context, token string) (*AccessGrant, error) {
+	return f(ctx, token)
+}
+
+type certificateAuthenticatorFunc func(ctx context.Context, cert *x509.Certificate) (uuid.UUID, error)
+
+func (f certificateAuthenticatorFunc) CertificateUser(ctx context.Context, cert *x509.Certificate) (uuid.UUID, error) {
+	return f(ctx, cert)
+}
+
+func newClientCertificateRequest(t *testing.T, commonName string) *http.Request {
+	t.Helper()
+
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+	r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	return r
+}
+
+func newPersonalTokenRequest(t *testing.T) *http.Request {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	r.Header.Set("Authorization", "Bearer "+AccessTokenPrefix+"token")
+	return r
+}
+
+func newAuthenticatedRequest(t *testing.T, baker *AuthCookieBaker) *http.Request {
+	t.Helper()
+
+	cookie, err := baker.BakeCookie(uuid.New(), uuid.New())
+	require.NoError(t, err)
+	r := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	r.AddCookie(cookie)
+	return r
 }
 
 func assertAuthToken(t *testing.T, want uuid.UUID, tkn, key string) {
@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"os"
+	"slices"
+
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/config"
+)
+
+var (
+	ErrSignKeyNotSet       = errors.New("sign key is not set")
+	ErrSampleSignKey       = errors.New("sign key is the sample key")
+	ErrUnknownJWTAlg       = errors.New("unknown jwt algorithm")
+	ErrActiveKeyNotFound   = errors.New("active key not found")
+	ErrActiveKeyNotPrivate = errors.New("active key is not private key")
+	ErrKeyAlgMismatch      = errors.New("key does not match jwt algorithm")
+)
+
+// sampleSignKeys are the keys published in the samples of the config, anyone could sign tokens with them.
+var sampleSignKeys = []string{"supersecret"}
+
+// JWTKeys signs tokens with the active key and verifies them with any of the keys,
+// so the tokens signed with the previous key stay valid after the key is rotated.
+type JWTKeys struct {
+	signKey interface{}
+	verify  jwt.ParseOption
+	public  jwk.Set
+	alg     jwa.SignatureAlgorithm
+}
+
+// NewJWTKeys reads the keys of the config. HS256 keys have no public part, so no keys are published for them.
+func NewJWTKeys(cfg config.JWTAuthConfig) (*JWTKeys, error) {
+	const op = "new jwt keys"
+
+	switch cfg.Alg {
+	case "", config.HS256:
+		if cfg.SignKey == "" {
+			return nil, errors.Wrap(ErrSignKeyNotSet, op)
+		}
+		if slices.Contains(sampleSignKeys, cfg.SignKey) {
+			return nil, errors.Wrap(ErrSampleSignKey, op)
+		}
+		return newHMACKeys(cfg.SignKey), nil
+	case config.EdDSA, config.RS256:
+	default:
+		return nil, errors.Wrapf(ErrUnknownJWTAlg, "%s: %s", op, cfg.Alg)
+	}
+
+	alg := jwa.SignatureAlgorithm(cfg.Alg)
+	public := jwk.NewSet()
+	var active jwk.Key
+	for _, keyConfig := range cfg.Keys {
+		key, err := readJWTKey(keyConfig, alg)
+		if err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+		if keyConfig.ID == cfg.ActiveKey {
+			active = key
+		}
+
+		publicKey, err := key.PublicKey()
+		if err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+		if err = public.AddKey(publicKey); err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+	}
+
+	if active == nil {
+		return nil, errors.Wrapf(ErrActiveKeyNotFound, "%s: %s", op, cfg.ActiveKey)
+	}
+	switch active.(type) {
+	case jwk.OKPPrivateKey, jwk.RSAPrivateKey:
+	default:
+		return nil, errors.Wrapf(ErrActiveKeyNotPrivate, "%s: %s", op, cfg.ActiveKey)
+	}
+
+	keys := &JWTKeys{
+		alg:     alg,
+		signKey: active,
+		verify:  jwt.WithKeySet(public),
+		public:  public,
+	}
+	return keys, nil
+}
+
+func newHMACKeys(signKey string) *JWTKeys {
+	key := []byte(signKey)
+	return &JWTKeys{
+		alg:     jwa.HS256,
+		signKey: key,
+		verify:  jwt.WithKey(jwa.HS256, key),
+		public:  jwk.NewSet(),
+	}
+}
+
+func readJWTKey(keyConfig config.JWTKeyConfig, alg jwa.SignatureAlgorithm) (jwk.Key, error) {
+	const op = "read jwt key"
+
+	data, err := os.ReadFile(keyConfig.File)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	key, err := jwk.ParseKey(data, jwk.WithPEM(true))
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	keyType := jwa.RSA
+	if alg == jwa.EdDSA {
+		keyType = jwa.OKP
+	}
+	if key.KeyType() != keyType {
+		return nil, errors.Wrapf(ErrKeyAlgMismatch, "%s: %s", op, keyConfig.ID)
+	}
+
+	if err = key.Set(jwk.KeyIDKey, keyConfig.ID); err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	if err = key.Set(jwk.AlgorithmKey, alg); err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	if err = key.Set(jwk.KeyUsageKey, jwk.ForSignature); err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return key, nil
+}
+
+// Sign returns the token with the claims signed by the active key, the key id is set in the token header.
+func (k *JWTKeys) Sign(claims map[string]interface{}) (string, error) {
+	const op = "sign"
+
+	token := jwt.New()
+	for name, value := range claims {
+		if err := token.Set(name, value); err != nil {
+			return "", errors.Wrap(err, op)
+		}
+	}
+
+	signed, err := jwt.Sign(token, jwt.WithKey(k.alg, k.signKey))
+	if err != nil {
+		return "", errors.Wrap(err, op)
+	}
+
+	return string(signed), nil
+}
+
+// Verify parses the token and checks its signature and expiry.
+func (k *JWTKeys) Verify(token string) (jwt.Token, error) {
+	const op = "verify"
+
+	t, err := jwt.Parse([]byte(token), k.verify, jwt.WithValidate(true))
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return t, nil
+}
+
+// PublicKeys returns the keys clients verify tokens with.
+func (k *JWTKeys) PublicKeys() jwk.Set {
+	return k.public
+}
@@ -0,0 +1,222 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/config"
+)
+
+func TestNewJWTKeys(t *testing.T) {
+	t.Run("hs256 sign key is not set", func(t *testing.T) {
+		cfg := config.JWTAuthConfig{Alg: config.HS256}
+
+		_, err := NewJWTKeys(cfg)
+
+		require.ErrorIs(t, err, ErrSignKeyNotSet)
+	})
+	t.Run("hs256 sign key is sample key", func(t *testing.T) {
+		cfg := config.JWTAuthConfig{Alg: config.HS256, SignKey: "supersecret"}
+
+		_, err := NewJWTKeys(cfg)
+
+		require.ErrorIs(t, err, ErrSampleSignKey)
+	})
+	t.Run("unknown algorithm", func(t *testing.T) {
+		cfg := config.JWTAuthConfig{Alg: "ES256"}
+
+		_, err := NewJWTKeys(cfg)
+
+		require.ErrorIs(t, err, ErrUnknownJWTAlg)
+	})
+	t.Run("active key not found", func(t *testing.T) {
+		cfg := config.JWTAuthConfig{
+			Alg:       config.EdDSA,
+			ActiveKey: "2",
+			Keys:      []config.JWTKeyConfig{newEd25519KeyFile(t, "1", true)},
+		}
+
+		_, err := NewJWTKeys(cfg)
+
+		require.ErrorIs(t, err, ErrActiveKeyNotFound)
+	})
+	t.Run("active key is public key", func(t *testing.T) {
+		cfg := config.JWTAuthConfig{
+			Alg:       config.EdDSA,
+			ActiveKey: "1",
+			Keys:      []config.JWTKeyConfig{newEd25519KeyFile(t, "1", false)},
+		}
+
+		_, err := NewJWTKeys(cfg)
+
+		require.ErrorIs(t, err, ErrActiveKeyNotPrivate)
+	})
+	t.Run("key does not match algorithm", func(t *testing.T) {
+		cfg := config.JWTAuthConfig{
+			Alg:       config.EdDSA,
+			ActiveKey: "1",
+			Keys:      []config.JWTKeyConfig{newRSAKeyFile(t, "1")},
+		}
+
+		_, err := NewJWTKeys(cfg)
+
+		require.ErrorIs(t, err, ErrKeyAlgMismatch)
+	})
+	t.Run("key file not found", func(t *testing.T) {
+		cfg := config.JWTAuthConfig{
+			Alg:       config.EdDSA,
+			ActiveKey: "1",
+			Keys:      []config.JWTKeyConfig{{ID: "1", File: filepath.Join(t.TempDir(), "missing.pem")}},
+		}
+
+		_, err := NewJWTKeys(cfg)
+
+		require.Error(t, err)
+	})
+}
+
+func TestJWTKeys(t *testing.T) {
+	claims := map[string]interface{}{
+		UserIDClaim: "user",
+		"exp":       time.Now().Add(time.Minute),
+	}
+
+	t.Run("hs256 token is verified", func(t *testing.T) {
+		sut, err := NewJWTKeys(config.JWTAuthConfig{SignKey: "secret"})
+		require.NoError(t, err)
+		token, err := sut.Sign(claims)
+		require.NoError(t, err)
+
+		got, err := sut.Verify(token)
+
+		require.NoError(t, err)
+		assert.Equal(t, "user", got.PrivateClaims()[UserIDClaim])
+		assert.Equal(t, 0, sut.PublicKeys().Len())
+	})
+	t.Run("rs256 token is verified", func(t *testing.T) {
+		cfg := config.JWTAuthConfig{
+			Alg:       config.RS256,
+			ActiveKey: "1",
+			Keys:      []config.JWTKeyConfig{newRSAKeyFile(t, "1")},
+		}
+		sut, err := NewJWTKeys(cfg)
+		require.NoError(t, err)
+		token, err := sut.Sign(claims)
+		require.NoError(t, err)
+
+		_, err = sut.Verify(token)
+
+		require.NoError(t, err)
+		assertKeyID(t, "1", token)
+	})
+	t.Run("token of previous key is verified after rotation", func(t *testing.T) {
+		previous := newEd25519KeyFile(t, "1", true)
+		cfg := config.JWTAuthConfig{
+			Alg:       config.EdDSA,
+			ActiveKey: "1",
+			Keys:      []config.JWTKeyConfig{previous},
+		}
+		keys, err := NewJWTKeys(cfg)
+		require.NoError(t, err)
+		token, err := keys.Sign(claims)
+		require.NoError(t, err)
+		cfg.ActiveKey = "2"
+		cfg.Keys = []config.JWTKeyConfig{newEd25519KeyFile(t, "2", true), previous}
+		sut, err := NewJWTKeys(cfg)
+		require.NoError(t, err)
+
+		_, err = sut.Verify(token)
+
+		require.NoError(t, err)
+		assertKeyID(t, "1", token)
+		assert.Equal(t, 2, sut.PublicKeys().Len())
+	})
+	t.Run("token of removed key is rejected", func(t *testing.T) {
+		cfg := config.JWTAuthConfig{
+			Alg:       config.EdDSA,
+			ActiveKey: "1",
+			Keys:      []config.JWTKeyConfig{newEd25519KeyFile(t, "1", true)},
+		}
+		keys, err := NewJWTKeys(cfg)
+		require.NoError(t, err)
+		token, err := keys.Sign(claims)
+		require.NoError(t, err)
+		cfg.ActiveKey = "2"
+		cfg.Keys = []config.JWTKeyConfig{newEd25519KeyFile(t, "2", true)}
+		sut, err := NewJWTKeys(cfg)
+		require.NoError(t, err)
+
+		_, err = sut.Verify(token)
+
+		require.Error(t, err)
+	})
+	t.Run("expired token is rejected", func(t *testing.T) {
+		sut, err := NewJWTKeys(config.JWTAuthConfig{SignKey: "secret"})
+		require.NoError(t, err)
+		token, err := sut.Sign(map[string]interface{}{"exp": time.Now().Add(-time.Minute)})
+		require.NoError(t, err)
+
+		_, err = sut.Verify(token)
+
+		require.Error(t, err)
+	})
+}
+
+func newEd25519KeyFile(t *testing.T, id string, private bool) config.JWTKeyConfig {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	if private {
+		return writeKeyFile(t, id, priv)
+	}
+	return writeKeyFile(t, id, pub)
+}
+
+func newRSAKeyFile(t *testing.T, id string) config.JWTKeyConfig {
+	t.Helper()
+
+	const bits = 2048
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	require.NoError(t, err)
+	return writeKeyFile(t, id, key)
+}
+
+func writeKeyFile(t *testing.T, id string, key crypto.PublicKey) config.JWTKeyConfig {
+	t.Helper()
+
+	block := &pem.Block{Type: "PRIVATE KEY"}
+	var err error
+	if pub, ok := key.(ed25519.PublicKey); ok {
+		block.Type = "PUBLIC KEY"
+		block.Bytes, err = x509.MarshalPKIXPublicKey(pub)
+	} else {
+		block.Bytes, err = x509.MarshalPKCS8PrivateKey(key)
+	}
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), id+".pem")
+	err = os.WriteFile(file, pem.EncodeToMemory(block), 0o600)
+	require.NoError(t, err)
+	return config.JWTKeyConfig{ID: id, File: file}
+}
+
+func assertKeyID(t *testing.T, want, token string) {
+	t.Helper()
+
+	msg, err := jws.Parse([]byte(token))
+	require.NoError(t, err)
+	assert.Equal(t, want, msg.Signatures()[0].ProtectedHeaders().KeyID())
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"testing"
 	"time"
@@ -11,6 +12,8 @@ import (
 	"github.com/ory/dockertest/v3"
 	"github.com/ory/dockertest/v3/docker"
 	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/config"
 )
 
 type PGSQLRepositoryTestHelper struct {
@@ -93,3 +96,80 @@ func (h *PGSQLRepositoryTestHelper) Run(m *testing.M) {
 
 	os.Exit(code)
 }
+
+type MinIOTestHelper struct {
+	S3Config config.S3Config
+}
+
+func (h *MinIOTestHelper) Run(m *testing.M) {
+	const (
+		accessKey = "minio"
+		secretKey = "minio-secret"
+		hostPort  = "9000/tcp"
+		bucket    = "goph-keeper"
+	)
+
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Fatalf("could not construct pool: %s", err)
+	}
+
+	err = pool.Client.Ping()
+	if err != nil {
+		log.Fatalf("could not connect to Docker: %s", err)
+	}
+
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository: "minio/minio",
+		Tag:        "latest",
+		Cmd:        []string{"server", "/data"},
+		Env: []string{
+			"MINIO_ROOT_USER=" + accessKey,
+			"MINIO_ROOT_PASSWORD=" + secretKey,
+		},
+	}, func(config *docker.HostConfig) {
+		config.AutoRemove = true
+		config.RestartPolicy = docker.RestartPolicy{Name: "no"}
+	})
+	if err != nil {
+		log.Fatalf("could not start resource: %s", err)
+	}
+
+	endpoint := resource.GetHostPort(hostPort)
+
+	const seconds = 120
+	_ = resource.Expire(seconds)
+
+	pool.MaxWait = seconds * time.Second
+	if err = pool.Retry(func() error {
+		const op = "retry"
+		resp, err := http.Get(fmt.Sprintf("http://%s/minio/health/live", endpoint))
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+		_ = resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return errors.Errorf("%s: status %d", op, resp.StatusCode)
+		}
+
+		return nil
+	}); err != nil {
+		log.Fatalf("could not connect to docker: %s", err)
+	}
+
+	h.S3Config = config.S3Config{
+		Endpoint:  endpoint,
+		Bucket:    bucket,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+	}
+
+	code := m.Run()
+
+	if err := pool.Purge(resource); err != nil {
+		log.Fatalf("could not purge resource: %s", err)
+	}
+
+	os.Exit(code)
+}
@@ -0,0 +1,42 @@
+package vault
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrVaultNotFound = errors.New("vault not found")
+	ErrAccessDenied  = errors.New("access to vault denied")
+)
+
+// Access is a level of access of a user to a shared vault.
+type Access int
+
+const (
+	AccessNone Access = iota
+	AccessRead
+	AccessWrite
+)
+
+// AccessPolicy decides on access of users to shared vaults.
+type AccessPolicy interface {
+	// VaultAccess returns access of the user to the shared vault.
+	VaultAccess(ctx context.Context, vaultID, userID uuid.UUID) (Access, error)
+}
+
+type vaultKey struct{}
+
+// ContextWithVault returns context of a request to the shared vault.
+// Requests without the vault are served by personal vault of the user.
+func ContextWithVault(ctx context.Context, vaultID uuid.UUID) context.Context {
+	return context.WithValue(ctx, vaultKey{}, vaultID)
+}
+
+// VaultFromContext returns the shared vault of the request, if any.
+func VaultFromContext(ctx context.Context) (uuid.UUID, bool) {
+	vaultID, ok := ctx.Value(vaultKey{}).(uuid.UUID)
+	return vaultID, ok
+}
@@ -0,0 +1,22 @@
+package vault
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBlobNotFound         = errors.New("blob not found")
+	ErrBlobChecksumMismatch = errors.New("blob checksum mismatch")
+	ErrBlobStoreNotSet      = errors.New("blob store is not set")
+)
+
+// BlobStore keeps sealed data of large secrets out of the secret repository.
+type BlobStore interface {
+	PutBlob(ctx context.Context, blobID uuid.UUID, data []byte) error
+	GetBlob(ctx context.Context, blobID uuid.UUID) ([]byte, error)
+	// DeleteBlob deletes the blob. Deleting a blob that does not exist is not an error.
+	DeleteBlob(ctx context.Context, blobID uuid.UUID) error
+}
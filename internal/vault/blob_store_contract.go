@@ -0,0 +1,69 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type BlobStoreContract struct {
+	NewBlobStore func() (BlobStore, func())
+}
+
+func (c BlobStoreContract) Test(t *testing.T) {
+	t.Run("get put blob", func(t *testing.T) {
+		sut, tearDown := c.NewBlobStore()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		blobID := uuid.New()
+		want := bytes.Repeat([]byte("data"), 1024)
+		err := sut.PutBlob(ctx, blobID, want)
+		require.NoError(t, err)
+
+		got, err := sut.GetBlob(ctx, blobID)
+
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+	t.Run("get blob that does not exist", func(t *testing.T) {
+		sut, tearDown := c.NewBlobStore()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+
+		_, err := sut.GetBlob(ctx, uuid.New())
+
+		require.ErrorIs(t, err, ErrBlobNotFound)
+	})
+	t.Run("delete blob", func(t *testing.T) {
+		sut, tearDown := c.NewBlobStore()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		blobID := uuid.New()
+		err := sut.PutBlob(ctx, blobID, []byte("data"))
+		require.NoError(t, err)
+		anotherBlobID := uuid.New()
+		err = sut.PutBlob(ctx, anotherBlobID, []byte("another data"))
+		require.NoError(t, err)
+
+		err = sut.DeleteBlob(ctx, blobID)
+
+		require.NoError(t, err)
+		_, err = sut.GetBlob(ctx, blobID)
+		require.ErrorIs(t, err, ErrBlobNotFound)
+		_, err = sut.GetBlob(ctx, anotherBlobID)
+		require.NoError(t, err)
+	})
+	t.Run("delete blob that does not exist", func(t *testing.T) {
+		sut, tearDown := c.NewBlobStore()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+
+		err := sut.DeleteBlob(ctx, uuid.New())
+
+		require.NoError(t, err)
+	})
+}
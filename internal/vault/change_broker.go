@@ -0,0 +1,16 @@
+package vault
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/vault/model"
+)
+
+// ChangeBroker delivers changes of secrets to the clients watching the vault of the secrets.
+type ChangeBroker interface {
+	// Publish sends the change of the secret to the subscribers of the vault of the owner.
+	Publish(ownerID uuid.UUID, change *model.Change)
+	// Subscribe returns changes of the secrets of the owner and the function that cancels the subscription.
+	// The channel is closed when the subscription is canceled.
+	Subscribe(ownerID uuid.UUID) (<-chan *model.Change, func())
+}
@@ -8,4 +8,6 @@ type VaultHandlers interface {
 	UpdateSecret() http.HandlerFunc
 	GetSecret() http.HandlerFunc
 	DeleteSecret() http.HandlerFunc
+	Sync() http.HandlerFunc
+	Events() http.HandlerFunc
 }
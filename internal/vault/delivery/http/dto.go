@@ -1,9 +1,10 @@
 package http
 
 type Secret struct {
-	ID   string `json:"id,omitempty"`
-	Name string `json:"name,omitempty"`
-	Data string `json:"data,omitempty"`
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Data     string `json:"data,omitempty"`
+	Revision int64  `json:"revision,omitempty"`
 }
 
 type ListSecretsResponse struct {
@@ -25,3 +26,16 @@ type GetSecretResponse struct {
 type UpdateSecretRequest struct {
 	Secret Secret `json:"secret"`
 }
+
+// Change is the latest change of a secret, deleted secrets have only ID and revision.
+type Change struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Revision int64  `json:"revision,omitempty"`
+	Deleted  bool   `json:"deleted,omitempty"`
+}
+
+type SyncResponse struct {
+	Changes []Change `json:"changes,omitempty"`
+	Cursor  int64    `json:"cursor"`
+}
@@ -0,0 +1,34 @@
+package http
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ETagHeader    = "ETag"
+	IfMatchHeader = "If-Match"
+)
+
+// FormatETag returns the secret revision as a strong entity tag.
+func FormatETag(revision int64) string {
+	return strconv.Quote(strconv.FormatInt(revision, 10))
+}
+
+// ParseETag returns the secret revision of the strong entity tag.
+func ParseETag(tag string) (int64, error) {
+	const op = "parse etag"
+
+	value, err := strconv.Unquote(tag)
+	if err != nil {
+		return 0, errors.Wrap(err, op)
+	}
+
+	revision, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, errors.Wrap(err, op)
+	}
+
+	return revision, nil
+}
@@ -1,13 +1,19 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 
+	"github.com/nestjam/goph-keeper/internal/audit"
+	modelAudit "github.com/nestjam/goph-keeper/internal/audit/model"
 	"github.com/nestjam/goph-keeper/internal/config"
 	"github.com/nestjam/goph-keeper/internal/utils"
 	"github.com/nestjam/goph-keeper/internal/vault"
@@ -18,22 +24,60 @@ const (
 	contentTypeHeader = "Content-Type"
 	applicationJSON   = "application/json"
 	secretParam       = "secret"
+	vaultParam        = "vault"
+	sinceParam        = "since"
+	textEventStream   = "text/event-stream"
+	changeEvent       = "change"
+	// heartbeatInterval is the interval of comments that keep the idle event stream open.
+	heartbeatInterval = 30 * time.Second
+)
+
+var (
+	errIfMatchMissing = errors.New("if-match header is missing")
+	errInvalidCursor  = errors.New("invalid cursor")
 )
 
 type VaultHandlers struct {
-	service    vault.VaultService
-	authConfig config.JWTAuthConfig
+	service           vault.VaultService
+	recorder          audit.Recorder
+	shutdown          context.Context
+	authConfig        config.JWTAuthConfig
+	heartbeatInterval time.Duration
+}
+
+type VaultHandlersOption func(*VaultHandlers)
+
+// WithAuditRecorder makes the handlers record every access to secrets to the audit log.
+func WithAuditRecorder(recorder audit.Recorder) VaultHandlersOption {
+	return func(h *VaultHandlers) {
+		h.recorder = recorder
+	}
+}
+
+// WithShutdownContext makes event streams end when the context is done,
+// so the streams do not keep the server from shutting down.
+func WithShutdownContext(ctx context.Context) VaultHandlersOption {
+	return func(h *VaultHandlers) {
+		h.shutdown = ctx
+	}
 }
 
-func NewVaultHandlers(service vault.VaultService, authConfig config.JWTAuthConfig) vault.VaultHandlers {
-	return &VaultHandlers{
-		service:    service,
-		authConfig: authConfig,
+func NewVaultHandlers(service vault.VaultService, authConfig config.JWTAuthConfig,
+	opts ...VaultHandlersOption) vault.VaultHandlers {
+	h := &VaultHandlers{
+		service:           service,
+		authConfig:        authConfig,
+		shutdown:          context.Background(),
+		heartbeatInterval: heartbeatInterval,
+	}
+	for _, opt := range opts {
+		opt(h)
 	}
+	return h
 }
 
 func (h *VaultHandlers) ListSecrets() http.HandlerFunc {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	return h.audited(modelAudit.ActionListSecrets, func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		userID, err := utils.UserFromContext(ctx)
 		if err != nil {
@@ -43,7 +87,7 @@ func (h *VaultHandlers) ListSecrets() http.HandlerFunc {
 
 		secrets, err := h.service.ListSecrets(ctx, userID)
 		if err != nil {
-			writeInternalServerError(w)
+			writeServiceError(w, err)
 			return
 		}
 
@@ -57,7 +101,7 @@ func (h *VaultHandlers) ListSecrets() http.HandlerFunc {
 }
 
 func (h *VaultHandlers) AddSecret() http.HandlerFunc {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	return h.audited(modelAudit.ActionAddSecret, func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		userID, err := utils.UserFromContext(ctx)
 		if err != nil {
@@ -73,11 +117,13 @@ func (h *VaultHandlers) AddSecret() http.HandlerFunc {
 
 		secretID, err := h.service.AddSecret(ctx, secret, userID)
 		if err != nil {
-			writeInternalServerError(w)
+			writeServiceError(w, err)
 			return
 		}
+		audit.SetSecret(ctx, secretID)
 
-		resp := newAddSecretResponse(secretID)
+		resp := newAddSecretResponse(secretID, secret.Revision)
+		setETag(w, secret.Revision)
 		err = writeJSON(w, http.StatusCreated, resp)
 		if err != nil {
 			writeInternalServerError(w)
@@ -87,13 +133,20 @@ func (h *VaultHandlers) AddSecret() http.HandlerFunc {
 }
 
 func (h *VaultHandlers) UpdateSecret() http.HandlerFunc {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	return h.audited(modelAudit.ActionUpdateSecret, func(w http.ResponseWriter, r *http.Request) {
 		key := chi.URLParam(r, secretParam)
 		secretID, err := uuid.Parse(key)
 		if err != nil {
 			writeBadRequest(w)
 			return
 		}
+		audit.SetSecret(r.Context(), secretID)
+
+		revision, err := revisionFromIfMatch(r)
+		if err != nil {
+			writePreconditionError(w, err)
+			return
+		}
 
 		ctx := r.Context()
 		userID, err := utils.UserFromContext(ctx)
@@ -108,29 +161,28 @@ func (h *VaultHandlers) UpdateSecret() http.HandlerFunc {
 			return
 		}
 		secret.ID = secretID
+		secret.Revision = revision
 
 		err = h.service.UpdateSecret(ctx, secret, userID)
-		if errors.Is(err, vault.ErrSecretNotFound) {
-			writeNotFound(w)
-			return
-		}
 		if err != nil {
-			writeInternalServerError(w)
+			writeServiceError(w, err)
 			return
 		}
 
+		setETag(w, secret.Revision)
 		w.WriteHeader(http.StatusOK)
 	})
 }
 
 func (h *VaultHandlers) GetSecret() http.HandlerFunc {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	return h.audited(modelAudit.ActionGetSecret, func(w http.ResponseWriter, r *http.Request) {
 		key := chi.URLParam(r, secretParam)
 		secretID, err := uuid.Parse(key)
 		if err != nil {
 			writeBadRequest(w)
 			return
 		}
+		audit.SetSecret(r.Context(), secretID)
 
 		ctx := r.Context()
 		userID, err := utils.UserFromContext(ctx)
@@ -140,16 +192,13 @@ func (h *VaultHandlers) GetSecret() http.HandlerFunc {
 		}
 
 		secret, err := h.service.GetSecret(ctx, secretID, userID)
-		if errors.Is(err, vault.ErrSecretNotFound) {
-			writeNotFound(w)
-			return
-		}
 		if err != nil {
-			writeInternalServerError(w)
+			writeServiceError(w, err)
 			return
 		}
 
 		resp := newGetSecretResponse(secret)
+		setETag(w, secret.Revision)
 		err = writeJSON(w, http.StatusOK, resp)
 		if err != nil {
 			writeInternalServerError(w)
@@ -159,13 +208,20 @@ func (h *VaultHandlers) GetSecret() http.HandlerFunc {
 }
 
 func (h *VaultHandlers) DeleteSecret() http.HandlerFunc {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	return h.audited(modelAudit.ActionDeleteSecret, func(w http.ResponseWriter, r *http.Request) {
 		key := chi.URLParam(r, secretParam)
 		secretID, err := uuid.Parse(key)
 		if err != nil {
 			writeBadRequest(w)
 			return
 		}
+		audit.SetSecret(r.Context(), secretID)
+
+		revision, err := revisionFromIfMatch(r)
+		if err != nil {
+			writePreconditionError(w, err)
+			return
+		}
 
 		ctx := r.Context()
 		userID, err := utils.UserFromContext(ctx)
@@ -174,9 +230,9 @@ func (h *VaultHandlers) DeleteSecret() http.HandlerFunc {
 			return
 		}
 
-		err = h.service.DeleteSecret(ctx, secretID, userID)
+		err = h.service.DeleteSecret(ctx, secretID, userID, revision)
 		if err != nil {
-			writeInternalServerError(w)
+			writeServiceError(w, err)
 			return
 		}
 
@@ -184,12 +240,118 @@ func (h *VaultHandlers) DeleteSecret() http.HandlerFunc {
 	})
 }
 
+// Sync returns changes of the secrets made after the cursor set by query parameter since.
+// The response holds the cursor to request the following changes with.
+func (h *VaultHandlers) Sync() http.HandlerFunc {
+	return h.audited(modelAudit.ActionSyncSecrets, func(w http.ResponseWriter, r *http.Request) {
+		since, err := cursorFromQuery(r)
+		if err != nil {
+			writeBadRequest(w)
+			return
+		}
+
+		ctx := r.Context()
+		userID, err := utils.UserFromContext(ctx)
+		if err != nil {
+			writeInternalServerError(w)
+			return
+		}
+
+		changes, err := h.service.ListChanges(ctx, userID, since)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+
+		resp := newSyncResponse(changes, since)
+		err = writeJSON(w, http.StatusOK, resp)
+		if err != nil {
+			writeInternalServerError(w)
+			return
+		}
+	})
+}
+
+// Events streams changes of the secrets to the client as server-sent events.
+// The stream lasts until the client disconnects.
+func (h *VaultHandlers) Events() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeInternalServerError(w)
+			return
+		}
+
+		ctx := r.Context()
+		userID, err := utils.UserFromContext(ctx)
+		if err != nil {
+			writeInternalServerError(w)
+			return
+		}
+
+		changes, cancel, err := h.service.WatchChanges(ctx, userID)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		defer cancel()
+
+		w.Header().Set(contentTypeHeader, textEventStream)
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(h.heartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-h.shutdown.Done():
+				return
+			case <-heartbeat.C:
+				err = h.heartbeat(ctx, w, userID)
+			case c, ok := <-changes:
+				if !ok {
+					return
+				}
+				err = writeEvent(w, changeEvent, newChange(c))
+			}
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// heartbeat keeps the idle event stream open while the user can read the secrets.
+func (h *VaultHandlers) heartbeat(ctx context.Context, w http.ResponseWriter, userID uuid.UUID) error {
+	err := h.service.VerifyAccess(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprint(w, ": heartbeat\n\n")
+	return err
+}
+
+// audited records the action of the handler to the audit log if the recorder is set.
+func (h *VaultHandlers) audited(action modelAudit.Action, next http.HandlerFunc) http.HandlerFunc {
+	if h.recorder == nil {
+		return next
+	}
+	return audit.Handler(h.recorder, action, next)
+}
+
 func newGetSecretResponse(secret *model.Secret) GetSecretResponse {
 	return GetSecretResponse{
 		Secret: Secret{
-			ID:   secret.ID.String(),
-			Name: secret.Name,
-			Data: string(secret.Data),
+			ID:       secret.ID.String(),
+			Name:     secret.Name,
+			Data:     string(secret.Data),
+			Revision: secret.Revision,
 		},
 	}
 }
@@ -242,6 +404,80 @@ func writeJSON(w http.ResponseWriter, statusCode int, v any) error {
 	return nil
 }
 
+// writeEvent writes the server-sent event with the data encoded to JSON.
+func writeEvent(w http.ResponseWriter, event string, v any) error {
+	const op = "write event"
+
+	content, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, content)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	return nil
+}
+
+func setETag(w http.ResponseWriter, revision int64) {
+	w.Header().Set(ETagHeader, FormatETag(revision))
+}
+
+// cursorFromQuery returns the cursor of the changes the client has seen, no changes are seen by default.
+func cursorFromQuery(r *http.Request) (int64, error) {
+	s := r.URL.Query().Get(sinceParam)
+	if s == "" {
+		return 0, nil
+	}
+
+	since, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, errors.Wrap(err, "parse cursor")
+	}
+	if since < 0 {
+		return 0, errInvalidCursor
+	}
+
+	return since, nil
+}
+
+// revisionFromIfMatch returns the secret revision the client expects to change.
+func revisionFromIfMatch(r *http.Request) (int64, error) {
+	tag := r.Header.Get(IfMatchHeader)
+	if tag == "" {
+		return 0, errIfMatchMissing
+	}
+
+	return ParseETag(tag)
+}
+
+func writePreconditionError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errIfMatchMissing) {
+		w.WriteHeader(http.StatusPreconditionRequired)
+		return
+	}
+	writeBadRequest(w)
+}
+
+// writeServiceError writes the status of the error returned by the vault service.
+func writeServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, vault.ErrVaultNotFound), errors.Is(err, vault.ErrSecretNotFound):
+		writeNotFound(w)
+	case errors.Is(err, vault.ErrAccessDenied):
+		writeForbidden(w)
+	case errors.Is(err, vault.ErrSecretRevisionMismatch):
+		writePreconditionFailed(w)
+	default:
+		writeInternalServerError(w)
+	}
+}
+
+func writePreconditionFailed(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusPreconditionFailed)
+}
+
 func writeBadRequest(w http.ResponseWriter) {
 	w.WriteHeader(http.StatusBadRequest)
 }
@@ -250,6 +486,10 @@ func writeInternalServerError(w http.ResponseWriter) {
 	w.WriteHeader(http.StatusInternalServerError)
 }
 
+func writeForbidden(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusForbidden)
+}
+
 func writeNotFound(w http.ResponseWriter) {
 	w.WriteHeader(http.StatusNotFound)
 }
@@ -262,18 +502,46 @@ func newListSecretsResponse(secrets []*model.Secret) *ListSecretsResponse {
 	for i := 0; i < len(secrets); i++ {
 		s := secrets[i]
 		resp.List[i] = Secret{
-			ID:   s.ID.String(),
-			Name: s.Name,
+			ID:       s.ID.String(),
+			Name:     s.Name,
+			Revision: s.Revision,
 		}
 	}
 
 	return resp
 }
 
-func newAddSecretResponse(secretID uuid.UUID) AddSecretResponse {
+// newSyncResponse returns the changes with the cursor of the last one,
+// the cursor is kept if there are no changes.
+func newSyncResponse(changes []*model.Change, since int64) *SyncResponse {
+	resp := &SyncResponse{
+		Changes: make([]Change, len(changes)),
+		Cursor:  since,
+	}
+
+	for i := 0; i < len(changes); i++ {
+		c := changes[i]
+		resp.Changes[i] = newChange(c)
+		resp.Cursor = max(resp.Cursor, c.Seq)
+	}
+
+	return resp
+}
+
+func newChange(c *model.Change) Change {
+	return Change{
+		ID:       c.SecretID.String(),
+		Name:     c.Name,
+		Revision: c.Revision,
+		Deleted:  c.Deleted,
+	}
+}
+
+func newAddSecretResponse(secretID uuid.UUID, revision int64) AddSecretResponse {
 	return AddSecretResponse{
 		Secret: Secret{
-			ID: secretID.String(),
+			ID:       secretID.String(),
+			Revision: revision,
 		},
 	}
 }
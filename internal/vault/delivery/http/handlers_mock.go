@@ -4,6 +4,9 @@ import (
 	"net/http"
 
 	"github.com/go-chi/jwtauth/v5"
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/vault"
 )
 
 type vaultHandlersSpy struct {
@@ -13,12 +16,16 @@ type vaultHandlersSpy struct {
 	getSecretCallsCount    int
 	deleteSecretCallsCount int
 	updateSecretCallsCount int
+	syncCallsCount         int
+	eventsCallsCount       int
+	vaultID                uuid.UUID
 }
 
 func (m *vaultHandlersSpy) ListSecrets() http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		m.listSecretsCallsCount++
 		_, m.claims, _ = jwtauth.FromContext(r.Context())
+		m.vaultID, _ = vault.VaultFromContext(r.Context())
 	})
 }
 
@@ -47,3 +54,17 @@ func (m *vaultHandlersSpy) DeleteSecret() http.HandlerFunc {
 		m.deleteSecretCallsCount++
 	})
 }
+
+func (m *vaultHandlersSpy) Sync() http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.syncCallsCount++
+		m.vaultID, _ = vault.VaultFromContext(r.Context())
+	})
+}
+
+func (m *vaultHandlersSpy) Events() http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.eventsCallsCount++
+		m.vaultID, _ = vault.VaultFromContext(r.Context())
+	})
+}
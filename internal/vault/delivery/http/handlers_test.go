@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -18,7 +19,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	modelAudit "github.com/nestjam/goph-keeper/internal/audit/model"
+	auditMemory "github.com/nestjam/goph-keeper/internal/audit/repository/inmemory"
+	auditService "github.com/nestjam/goph-keeper/internal/audit/service"
 	"github.com/nestjam/goph-keeper/internal/config"
+	"github.com/nestjam/goph-keeper/internal/storage/memory"
 	"github.com/nestjam/goph-keeper/internal/utils"
 	"github.com/nestjam/goph-keeper/internal/vault"
 	"github.com/nestjam/goph-keeper/internal/vault/model"
@@ -33,7 +38,7 @@ func TestListSecrets(t *testing.T) {
 	t.Run("empty list", func(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
-		service := service.NewVaultService(secretRepo, keyRepo, rootKey)
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		sut := NewVaultHandlers(service, config)
 		userID := uuid.New()
 		r := newListSecretsRequestWithUser(t, userID)
@@ -49,7 +54,7 @@ func TestListSecrets(t *testing.T) {
 	t.Run("secrets", func(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
-		service := service.NewVaultService(secretRepo, keyRepo, rootKey)
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		sut := NewVaultHandlers(service, config)
 		ctx := context.Background()
 		userID := uuid.New()
@@ -61,8 +66,9 @@ func TestListSecrets(t *testing.T) {
 		w := httptest.NewRecorder()
 		want := []Secret{
 			{
-				ID:   secret.ID.String(),
-				Name: secret.Name,
+				ID:       secret.ID.String(),
+				Name:     secret.Name,
+				Revision: model.FirstRevision,
 			},
 		}
 
@@ -75,7 +81,7 @@ func TestListSecrets(t *testing.T) {
 	t.Run("user not found in context", func(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
-		service := service.NewVaultService(secretRepo, keyRepo, rootKey)
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		sut := NewVaultHandlers(service, config)
 		r := newListSecretsRequest(t, "/")
 		r = addAuthError(t, r, errors.New("failed"))
@@ -100,6 +106,20 @@ func TestListSecrets(t *testing.T) {
 
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
 	})
+	t.Run("shared vault not found", func(t *testing.T) {
+		service := &vaultServiceMock{
+			ListSecretsFunc: func(ctx context.Context, userID uuid.UUID) ([]*model.Secret, error) {
+				return nil, vault.ErrVaultNotFound
+			},
+		}
+		sut := NewVaultHandlers(service, config)
+		r := newListSecretsRequestWithUser(t, uuid.New())
+		w := httptest.NewRecorder()
+
+		sut.ListSecrets().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
 }
 
 func TestAddSecret(t *testing.T) {
@@ -109,7 +129,7 @@ func TestAddSecret(t *testing.T) {
 	t.Run("add secret", func(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
-		service := service.NewVaultService(secretRepo, keyRepo, rootKey)
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		sut := NewVaultHandlers(service, config)
 		const (
 			wantData = "sensitive data"
@@ -126,6 +146,7 @@ func TestAddSecret(t *testing.T) {
 		sut.AddSecret().ServeHTTP(w, r)
 
 		assert.Equal(t, http.StatusCreated, w.Code)
+		assertETag(t, model.FirstRevision, w)
 		ctx := context.Background()
 		secrets, err := secretRepo.ListSecrets(ctx, userID)
 		require.NoError(t, err)
@@ -142,7 +163,7 @@ func TestAddSecret(t *testing.T) {
 	t.Run("user not found in context", func(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
-		service := service.NewVaultService(secretRepo, keyRepo, rootKey)
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		sut := NewVaultHandlers(service, config)
 		secret := Secret{}
 		r := newAddSecretRequest(t, "/", secret)
@@ -153,6 +174,20 @@ func TestAddSecret(t *testing.T) {
 
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
 	})
+	t.Run("access to shared vault denied", func(t *testing.T) {
+		service := &vaultServiceMock{
+			AddSecretFunc: func(ctx context.Context, secret *model.Secret, userID uuid.UUID) (uuid.UUID, error) {
+				return uuid.Nil, vault.ErrAccessDenied
+			},
+		}
+		sut := NewVaultHandlers(service, config)
+		r := newAddSecretRequestWithUser(t, Secret{}, uuid.New())
+		w := httptest.NewRecorder()
+
+		sut.AddSecret().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
 	t.Run("failed to add secret", func(t *testing.T) {
 		service := &vaultServiceMock{
 			AddSecretFunc: func(ctx context.Context, secret *model.Secret, userID uuid.UUID) (uuid.UUID, error) {
@@ -172,7 +207,7 @@ func TestAddSecret(t *testing.T) {
 	t.Run("invalid json", func(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
-		service := service.NewVaultService(secretRepo, keyRepo, rootKey)
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		sut := NewVaultHandlers(service, config)
 		userID := uuid.New()
 		r := newInvalidAddSecretRequestWithUser(t, userID)
@@ -191,7 +226,7 @@ func TestUpdateSecret(t *testing.T) {
 	t.Run("update secret", func(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
-		service := service.NewVaultService(secretRepo, keyRepo, rootKey)
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		ctx := context.Background()
 		s := &model.Secret{}
 		userID := uuid.New()
@@ -203,9 +238,10 @@ func TestUpdateSecret(t *testing.T) {
 			wantData = "edited text"
 		)
 		secret := Secret{
-			ID:   s.ID.String(),
-			Name: wantName,
-			Data: wantData,
+			ID:       s.ID.String(),
+			Name:     wantName,
+			Data:     wantData,
+			Revision: s.Revision,
 		}
 		r := newUpdateSecretRequestWithUser(t, secret, userID)
 		w := httptest.NewRecorder()
@@ -214,15 +250,71 @@ func TestUpdateSecret(t *testing.T) {
 		updateSecret(sut, w, r)
 
 		assert.Equal(t, http.StatusOK, w.Code)
+		assertETag(t, s.Revision+1, w)
 		got, err := service.GetSecret(ctx, s.ID, userID)
 		require.NoError(t, err)
 		assert.Equal(t, wantData, string(got.Data))
 		assert.Equal(t, wantName, got.Name)
 	})
+	t.Run("secret was updated by another client", func(t *testing.T) {
+		keyRepo := inmemory.NewDataKeyRepository()
+		secretRepo := inmemory.NewSecretRepository()
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
+		ctx := context.Background()
+		s := &model.Secret{Data: []byte("text")}
+		userID := uuid.New()
+		var err error
+		s.ID, err = service.AddSecret(ctx, s, userID)
+		require.NoError(t, err)
+		staleRevision := s.Revision
+		err = service.UpdateSecret(ctx, s, userID)
+		require.NoError(t, err)
+		secret := Secret{
+			ID:       s.ID.String(),
+			Data:     "edited text",
+			Revision: staleRevision,
+		}
+		r := newUpdateSecretRequestWithUser(t, secret, userID)
+		w := httptest.NewRecorder()
+		sut := NewVaultHandlers(service, config)
+
+		updateSecret(sut, w, r)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		got, err := service.GetSecret(ctx, s.ID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, "text", string(got.Data))
+	})
+	t.Run("if-match header is missing", func(t *testing.T) {
+		service := &vaultServiceMock{}
+		sut := NewVaultHandlers(service, config)
+		userID := uuid.New()
+		secret := Secret{ID: uuid.NewString()}
+		r := newUpdateSecretRequestWithUser(t, secret, userID)
+		r.Header.Del(IfMatchHeader)
+		w := httptest.NewRecorder()
+
+		updateSecret(sut, w, r)
+
+		assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	})
+	t.Run("invalid if-match header", func(t *testing.T) {
+		service := &vaultServiceMock{}
+		sut := NewVaultHandlers(service, config)
+		userID := uuid.New()
+		secret := Secret{ID: uuid.NewString()}
+		r := newUpdateSecretRequestWithUser(t, secret, userID)
+		r.Header.Set(IfMatchHeader, "W/\"1\"")
+		w := httptest.NewRecorder()
+
+		updateSecret(sut, w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
 	t.Run("updating secret not found", func(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
-		service := service.NewVaultService(secretRepo, keyRepo, rootKey)
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		userID := uuid.New()
 		const wantData = "edited text"
 		secret := Secret{ID: uuid.NewString(), Data: wantData}
@@ -237,7 +329,7 @@ func TestUpdateSecret(t *testing.T) {
 	t.Run("user not found in context", func(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
-		service := service.NewVaultService(secretRepo, keyRepo, rootKey)
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		sut := NewVaultHandlers(service, config)
 		secret := Secret{ID: uuid.NewString()}
 		r := newUpdateSecretRequest(t, "", secret)
@@ -251,7 +343,7 @@ func TestUpdateSecret(t *testing.T) {
 	t.Run("invalid secret id", func(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
-		service := service.NewVaultService(secretRepo, keyRepo, rootKey)
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		sut := NewVaultHandlers(service, config)
 		r := newInvalidIDUpdateSecretRequest(t)
 		w := httptest.NewRecorder()
@@ -263,11 +355,12 @@ func TestUpdateSecret(t *testing.T) {
 	t.Run("invalid json", func(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
-		service := service.NewVaultService(secretRepo, keyRepo, rootKey)
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		sut := NewVaultHandlers(service, config)
 		userID := uuid.New()
 		secretID := uuid.NewString()
 		r := newInvalidUpdateSecretRequestWithUser(t, secretID, userID)
+		r.Header.Set(IfMatchHeader, FormatETag(model.FirstRevision))
 		w := httptest.NewRecorder()
 
 		updateSecret(sut, w, r)
@@ -299,7 +392,7 @@ func TestGetSecret(t *testing.T) {
 	t.Run("get secret", func(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
-		service := service.NewVaultService(secretRepo, keyRepo, rootKey)
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		sut := NewVaultHandlers(service, config)
 		ctx := context.Background()
 		userID := uuid.New()
@@ -320,15 +413,17 @@ func TestGetSecret(t *testing.T) {
 		getSecret(sut, w, r)
 
 		assert.Equal(t, http.StatusOK, w.Code)
+		assertETag(t, model.FirstRevision, w)
 		resp := secretFromResponse(t, w.Body)
 		assert.Equal(t, secret.ID.String(), resp.ID)
 		assert.Equal(t, wantData, resp.Data)
 		assert.Equal(t, wantName, resp.Name)
+		assert.Equal(t, model.FirstRevision, resp.Revision)
 	})
 	t.Run("secret not found", func(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
-		service := service.NewVaultService(secretRepo, keyRepo, rootKey)
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		sut := NewVaultHandlers(service, config)
 		userID := uuid.New()
 		secretID := uuid.New()
@@ -342,7 +437,7 @@ func TestGetSecret(t *testing.T) {
 	t.Run("invalid secret id", func(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
-		service := service.NewVaultService(secretRepo, keyRepo, rootKey)
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		sut := NewVaultHandlers(service, config)
 		r := newInvalidIDGetSecretRequest(t)
 		w := httptest.NewRecorder()
@@ -354,7 +449,7 @@ func TestGetSecret(t *testing.T) {
 	t.Run("user not found in context", func(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
-		service := service.NewVaultService(secretRepo, keyRepo, rootKey)
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		sut := NewVaultHandlers(service, config)
 		ctx := context.Background()
 		userID := uuid.New()
@@ -395,7 +490,7 @@ func TestDeleteSecret(t *testing.T) {
 	t.Run("delete secret", func(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
-		service := service.NewVaultService(secretRepo, keyRepo, rootKey)
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		sut := NewVaultHandlers(service, config)
 		ctx := context.Background()
 		userID := uuid.New()
@@ -403,17 +498,85 @@ func TestDeleteSecret(t *testing.T) {
 		var err error
 		secret.ID, err = secretRepo.AddSecret(ctx, secret, userID)
 		require.NoError(t, err)
-		r := newDeleteSecretRequestWithUser(t, secret.ID, userID)
+		r := newDeleteSecretRequestWithUser(t, secret.ID, secret.Revision, userID)
 		w := httptest.NewRecorder()
 
 		deleteSecret(sut, w, r)
 
 		assert.Equal(t, http.StatusOK, w.Code)
 	})
+	t.Run("deleting secret not found", func(t *testing.T) {
+		keyRepo := inmemory.NewDataKeyRepository()
+		secretRepo := inmemory.NewSecretRepository()
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
+		sut := NewVaultHandlers(service, config)
+		userID := uuid.New()
+		r := newDeleteSecretRequestWithUser(t, uuid.New(), model.FirstRevision, userID)
+		w := httptest.NewRecorder()
+
+		deleteSecret(sut, w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+	t.Run("delete secret of another user", func(t *testing.T) {
+		keyRepo := inmemory.NewDataKeyRepository()
+		secretRepo := inmemory.NewSecretRepository()
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
+		sut := NewVaultHandlers(service, config)
+		ctx := context.Background()
+		ownerID := uuid.New()
+		secret := &model.Secret{}
+		var err error
+		secret.ID, err = secretRepo.AddSecret(ctx, secret, ownerID)
+		require.NoError(t, err)
+		r := newDeleteSecretRequestWithUser(t, secret.ID, secret.Revision, uuid.New())
+		w := httptest.NewRecorder()
+
+		deleteSecret(sut, w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		_, err = secretRepo.GetSecret(ctx, secret.ID, ownerID)
+		require.NoError(t, err)
+	})
+	t.Run("secret was updated by another client", func(t *testing.T) {
+		keyRepo := inmemory.NewDataKeyRepository()
+		secretRepo := inmemory.NewSecretRepository()
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
+		sut := NewVaultHandlers(service, config)
+		ctx := context.Background()
+		userID := uuid.New()
+		secret := &model.Secret{}
+		var err error
+		secret.ID, err = secretRepo.AddSecret(ctx, secret, userID)
+		require.NoError(t, err)
+		staleRevision := secret.Revision
+		err = secretRepo.UpdateSecret(ctx, secret, userID)
+		require.NoError(t, err)
+		r := newDeleteSecretRequestWithUser(t, secret.ID, staleRevision, userID)
+		w := httptest.NewRecorder()
+
+		deleteSecret(sut, w, r)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		_, err = secretRepo.GetSecret(ctx, secret.ID, userID)
+		require.NoError(t, err)
+	})
+	t.Run("if-match header is missing", func(t *testing.T) {
+		service := &vaultServiceMock{}
+		sut := NewVaultHandlers(service, config)
+		userID := uuid.New()
+		r := newDeleteSecretRequestWithUser(t, uuid.New(), model.FirstRevision, userID)
+		r.Header.Del(IfMatchHeader)
+		w := httptest.NewRecorder()
+
+		deleteSecret(sut, w, r)
+
+		assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	})
 	t.Run("invalid secret id", func(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
-		service := service.NewVaultService(secretRepo, keyRepo, rootKey)
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		sut := NewVaultHandlers(service, config)
 		r := newInvalidIDDeleteSecretRequest(t)
 		w := httptest.NewRecorder()
@@ -425,10 +588,10 @@ func TestDeleteSecret(t *testing.T) {
 	t.Run("user not found in context", func(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
-		service := service.NewVaultService(secretRepo, keyRepo, rootKey)
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		sut := NewVaultHandlers(service, config)
 		secretID := uuid.New()
-		r := newDeleteSecretRequest(t, "", secretID)
+		r := newDeleteSecretRequest(t, "", secretID, model.FirstRevision)
 		r = addAuthError(t, r, errors.New("failed"))
 		w := httptest.NewRecorder()
 
@@ -438,14 +601,14 @@ func TestDeleteSecret(t *testing.T) {
 	})
 	t.Run("failed to delete secret", func(t *testing.T) {
 		service := &vaultServiceMock{
-			DeleteSecretFunc: func(ctx context.Context, secretID, userID uuid.UUID) error {
+			DeleteSecretFunc: func(ctx context.Context, secretID, userID uuid.UUID, revision int64) error {
 				return errors.New("failed")
 			},
 		}
 		sut := NewVaultHandlers(service, config)
 		userID := uuid.New()
 		secretID := uuid.New()
-		r := newDeleteSecretRequestWithUser(t, secretID, userID)
+		r := newDeleteSecretRequestWithUser(t, secretID, model.FirstRevision, userID)
 		w := httptest.NewRecorder()
 
 		deleteSecret(sut, w, r)
@@ -466,6 +629,168 @@ func deleteSecret(sut vault.VaultHandlers, w *httptest.ResponseRecorder, r *http
 	router.ServeHTTP(w, r)
 }
 
+func TestSync(t *testing.T) {
+	config := newConfig()
+	rootKey := randomMasterKey(t)
+
+	t.Run("changes since cursor", func(t *testing.T) {
+		keyRepo := inmemory.NewDataKeyRepository()
+		secretRepo := inmemory.NewSecretRepository()
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
+		sut := NewVaultHandlers(service, config)
+		ctx := context.Background()
+		userID := uuid.New()
+		_, err := secretRepo.AddSecret(ctx, &model.Secret{Name: "first"}, userID)
+		require.NoError(t, err)
+		r := newSyncRequestWithUser(t, "/sync", userID)
+		w := httptest.NewRecorder()
+		sut.Sync().ServeHTTP(w, r)
+		cursor := syncResponseFromBody(t, w.Body).Cursor
+		secretID, err := secretRepo.AddSecret(ctx, &model.Secret{Name: "second"}, userID)
+		require.NoError(t, err)
+		r = newSyncRequestWithUser(t, "/sync?since="+strconv.FormatInt(cursor, 10), userID)
+		w = httptest.NewRecorder()
+
+		sut.Sync().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assertContentType(t, applicationJSON, w)
+		got := syncResponseFromBody(t, w.Body)
+		want := []Change{{ID: secretID.String(), Name: "second", Revision: model.FirstRevision}}
+		assert.Equal(t, want, got.Changes)
+		assert.Greater(t, got.Cursor, cursor)
+	})
+	t.Run("deleted secret", func(t *testing.T) {
+		keyRepo := inmemory.NewDataKeyRepository()
+		secretRepo := inmemory.NewSecretRepository()
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
+		sut := NewVaultHandlers(service, config)
+		ctx := context.Background()
+		userID := uuid.New()
+		secretID, err := secretRepo.AddSecret(ctx, &model.Secret{Name: "secret"}, userID)
+		require.NoError(t, err)
+		err = secretRepo.DeleteSecret(ctx, secretID, userID, model.FirstRevision)
+		require.NoError(t, err)
+		r := newSyncRequestWithUser(t, "/sync", userID)
+		w := httptest.NewRecorder()
+
+		sut.Sync().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		got := syncResponseFromBody(t, w.Body)
+		want := []Change{{ID: secretID.String(), Revision: model.FirstRevision, Deleted: true}}
+		assert.Equal(t, want, got.Changes)
+	})
+	t.Run("cursor is kept if there are no changes", func(t *testing.T) {
+		keyRepo := inmemory.NewDataKeyRepository()
+		secretRepo := inmemory.NewSecretRepository()
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
+		sut := NewVaultHandlers(service, config)
+		r := newSyncRequestWithUser(t, "/sync?since=42", uuid.New())
+		w := httptest.NewRecorder()
+
+		sut.Sync().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		got := syncResponseFromBody(t, w.Body)
+		assert.Empty(t, got.Changes)
+		assert.Equal(t, int64(42), got.Cursor)
+	})
+	t.Run("invalid cursor", func(t *testing.T) {
+		service := &vaultServiceMock{}
+		sut := NewVaultHandlers(service, config)
+		tests := []string{"abc", "-1"}
+
+		for _, since := range tests {
+			r := newSyncRequestWithUser(t, "/sync?since="+since, uuid.New())
+			w := httptest.NewRecorder()
+
+			sut.Sync().ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, since)
+		}
+	})
+	t.Run("failed to list changes", func(t *testing.T) {
+		service := &vaultServiceMock{
+			ListChangesFunc: func(ctx context.Context, userID uuid.UUID, since int64) ([]*model.Change, error) {
+				return nil, errors.New("failed")
+			},
+		}
+		sut := NewVaultHandlers(service, config)
+		r := newSyncRequestWithUser(t, "/sync", uuid.New())
+		w := httptest.NewRecorder()
+
+		sut.Sync().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+	t.Run("shared vault not found", func(t *testing.T) {
+		service := &vaultServiceMock{
+			ListChangesFunc: func(ctx context.Context, userID uuid.UUID, since int64) ([]*model.Change, error) {
+				return nil, vault.ErrVaultNotFound
+			},
+		}
+		sut := NewVaultHandlers(service, config)
+		r := newSyncRequestWithUser(t, "/sync", uuid.New())
+		w := httptest.NewRecorder()
+
+		sut.Sync().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestVaultHandlers_Audit(t *testing.T) {
+	config := newConfig()
+	rootKey := randomMasterKey(t)
+
+	t.Run("secret access is recorded", func(t *testing.T) {
+		keyRepo := inmemory.NewDataKeyRepository()
+		secretRepo := inmemory.NewSecretRepository()
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
+		events := auditMemory.NewEventRepository()
+		sut := NewVaultHandlers(service, config, WithAuditRecorder(auditService.NewAuditService(events)))
+		ctx := context.Background()
+		userID := uuid.New()
+		secretID, err := service.AddSecret(ctx, &model.Secret{Name: "secret"}, userID)
+		require.NoError(t, err)
+		r := newGetSecretRequestWithUser(t, secretID, userID)
+		w := httptest.NewRecorder()
+
+		getSecret(sut, w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		got, err := events.ListEvents(ctx, modelAudit.EventFilter{})
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, modelAudit.ActionGetSecret, got[0].Action)
+		assert.Equal(t, modelAudit.OutcomeSuccess, got[0].Outcome)
+		assert.Equal(t, userID, got[0].UserID)
+		assert.Equal(t, secretID, got[0].SecretID)
+	})
+	t.Run("failed access is recorded", func(t *testing.T) {
+		keyRepo := inmemory.NewDataKeyRepository()
+		secretRepo := inmemory.NewSecretRepository()
+		service := service.NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
+		events := auditMemory.NewEventRepository()
+		sut := NewVaultHandlers(service, config, WithAuditRecorder(auditService.NewAuditService(events)))
+		userID := uuid.New()
+		secretID := uuid.New()
+		r := newDeleteSecretRequestWithUser(t, secretID, model.FirstRevision, userID)
+		w := httptest.NewRecorder()
+
+		deleteSecret(sut, w, r)
+
+		require.Equal(t, http.StatusNotFound, w.Code)
+		got, err := events.ListEvents(context.Background(), modelAudit.EventFilter{})
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, modelAudit.ActionDeleteSecret, got[0].Action)
+		assert.Equal(t, modelAudit.OutcomeFailure, got[0].Outcome)
+		assert.Equal(t, secretID, got[0].SecretID)
+	})
+}
+
 func getSecret(sut vault.VaultHandlers, w *httptest.ResponseRecorder, r *http.Request) {
 	router := chi.NewRouter()
 	router.Get("/{secret}", sut.GetSecret())
@@ -482,10 +807,10 @@ func secretFromResponse(t *testing.T, r io.Reader) Secret {
 	return resp.Secret
 }
 
-func newDeleteSecretRequestWithUser(t *testing.T, secretID, userID uuid.UUID) *http.Request {
+func newDeleteSecretRequestWithUser(t *testing.T, secretID uuid.UUID, revision int64, userID uuid.UUID) *http.Request {
 	t.Helper()
 
-	r := newDeleteSecretRequest(t, "", secretID)
+	r := newDeleteSecretRequest(t, "", secretID, revision)
 	r = addAuthToken(t, r, userID)
 	return r
 }
@@ -579,6 +904,117 @@ func listSecretsFromResponse(t *testing.T, r io.Reader) []Secret {
 	return resp.List
 }
 
+func TestEvents(t *testing.T) {
+	config := newConfig()
+
+	t.Run("change is sent as event", func(t *testing.T) {
+		secretID := uuid.New()
+		changes := make(chan *model.Change, 1)
+		changes <- &model.Change{SecretID: secretID, Name: "secret", Revision: 2}
+		close(changes)
+		canceled := false
+		service := &vaultServiceMock{
+			WatchChangesFunc: func(ctx context.Context, userID uuid.UUID) (<-chan *model.Change, func(), error) {
+				return changes, func() { canceled = true }, nil
+			},
+		}
+		sut := NewVaultHandlers(service, config)
+		r := newSyncRequestWithUser(t, "/events", uuid.New())
+		w := httptest.NewRecorder()
+
+		sut.Events().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assertContentType(t, textEventStream, w)
+		want := "event: change\ndata: {\"id\":\"" + secretID.String() + "\",\"name\":\"secret\",\"revision\":2}\n\n"
+		assert.Equal(t, want, w.Body.String())
+		assert.True(t, canceled)
+	})
+	t.Run("stream ends on shutdown", func(t *testing.T) {
+		service := &vaultServiceMock{
+			WatchChangesFunc: func(ctx context.Context, userID uuid.UUID) (<-chan *model.Change, func(), error) {
+				return make(chan *model.Change), func() {}, nil
+			},
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		sut := NewVaultHandlers(service, config, WithShutdownContext(ctx))
+		r := newSyncRequestWithUser(t, "/events", uuid.New())
+		w := httptest.NewRecorder()
+
+		sut.Events().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+	t.Run("heartbeat is sent while user has access", func(t *testing.T) {
+		verified := 0
+		service := &vaultServiceMock{
+			WatchChangesFunc: func(ctx context.Context, userID uuid.UUID) (<-chan *model.Change, func(), error) {
+				return make(chan *model.Change), func() {}, nil
+			},
+			VerifyAccessFunc: func(ctx context.Context, userID uuid.UUID) error {
+				verified++
+				if verified > 1 {
+					return vault.ErrAccessDenied
+				}
+				return nil
+			},
+		}
+		sut := NewVaultHandlers(service, config)
+		sut.(*VaultHandlers).heartbeatInterval = time.Millisecond
+		r := newSyncRequestWithUser(t, "/events", uuid.New())
+		w := httptest.NewRecorder()
+
+		sut.Events().ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, ": heartbeat\n\n", w.Body.String())
+	})
+	t.Run("failed to watch changes", func(t *testing.T) {
+		tests := []struct {
+			err  error
+			want int
+		}{
+			{vault.ErrVaultNotFound, http.StatusNotFound},
+			{vault.ErrAccessDenied, http.StatusForbidden},
+			{errors.New("failed"), http.StatusInternalServerError},
+		}
+
+		for _, tt := range tests {
+			service := &vaultServiceMock{
+				WatchChangesFunc: func(ctx context.Context, userID uuid.UUID) (<-chan *model.Change, func(), error) {
+					return nil, nil, tt.err
+				},
+			}
+			sut := NewVaultHandlers(service, config)
+			r := newSyncRequestWithUser(t, "/events", uuid.New())
+			w := httptest.NewRecorder()
+
+			sut.Events().ServeHTTP(w, r)
+
+			assert.Equal(t, tt.want, w.Code, tt.err.Error())
+		}
+	})
+}
+
+func newSyncRequestWithUser(t *testing.T, target string, userID uuid.UUID) *http.Request {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	r = addAuthToken(t, r, userID)
+	return r
+}
+
+func syncResponseFromBody(t *testing.T, r io.Reader) SyncResponse {
+	t.Helper()
+
+	var resp SyncResponse
+	err := json.NewDecoder(r).Decode(&resp)
+	require.NoError(t, err)
+	return resp
+}
+
 func getAddSecretResponse(t *testing.T, r io.Reader) AddSecretResponse {
 	t.Helper()
 
@@ -609,6 +1045,13 @@ func addAuthError(t *testing.T, r *http.Request, err error) *http.Request {
 	return r.WithContext(ctx)
 }
 
+func assertETag(t *testing.T, wantRevision int64, r *httptest.ResponseRecorder) {
+	t.Helper()
+
+	want := FormatETag(wantRevision)
+	assert.Equal(t, want, r.Header().Get(ETagHeader))
+}
+
 func assertContentType(t *testing.T, want string, r *httptest.ResponseRecorder) {
 	t.Helper()
 
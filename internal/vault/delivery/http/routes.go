@@ -1,41 +1,98 @@
 package http
 
 import (
+	"net/http"
+
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/jwtauth/v5"
+	"github.com/google/uuid"
 
 	"github.com/nestjam/goph-keeper/internal/config"
 	"github.com/nestjam/goph-keeper/internal/utils"
 	"github.com/nestjam/goph-keeper/internal/vault"
 )
 
-func MapVaultRoutes(r chi.Router, h vault.VaultHandlers, cfg config.JWTAuthConfig) {
+// MapVaultRoutes maps routes to secrets of personal vault of the user and
+// the same routes under /vaults/{vault} to secrets of shared vaults.
+// Personal access tokens are accepted if the options have their verifier.
+func MapVaultRoutes(r chi.Router, h vault.VaultHandlers, cfg config.JWTAuthConfig,
+	opts ...utils.AuthCookieBakerOption) {
+	const sharedVaultPath = "/vaults/{" + vaultParam + "}"
+
+	cookieBaker := utils.NewAuthCookieBaker(cfg, opts...)
+
+	mapSecretRoutes(r, h, cookieBaker)
+	r.Route(sharedVaultPath, func(r chi.Router) {
+		r.Use(sharedVault)
+		mapSecretRoutes(r, h, cookieBaker)
+	})
+}
+
+func mapSecretRoutes(r chi.Router, h vault.VaultHandlers, cookieBaker *utils.AuthCookieBaker) {
 	const (
 		secretsPath   = "/secrets"
 		secretPattern = "/{secret}"
+		syncPath      = "/sync"
+		eventsPath    = "/events"
 	)
 
-	cookieBaker := utils.NewAuthCookieBaker(cfg)
-	jwtAuth := cookieBaker.JWTAuth()
-
 	r.Group(func(r chi.Router) {
-		useJWTAuth(r, jwtAuth)
+		r.Use(cookieBaker.Middlewares()...)
+		r.Use(tokenScope)
 
 		r.Get(secretsPath, h.ListSecrets())
 		r.Get(secretsPath+secretPattern, h.GetSecret())
 		r.Delete(secretsPath+secretPattern, h.DeleteSecret())
+		r.Get(syncPath, h.Sync())
+		r.With(cookieBaker.Reauthenticated(heartbeatInterval)).Get(eventsPath, h.Events())
 	})
 	r.Group(func(r chi.Router) {
 		r.Use(middleware.AllowContentType(applicationJSON))
-		useJWTAuth(r, jwtAuth)
+		r.Use(cookieBaker.Middlewares()...)
+		r.Use(tokenScope)
 
 		r.Post(secretsPath, h.AddSecret())
 		r.Patch(secretsPath+secretPattern, h.UpdateSecret())
 	})
 }
 
-func useJWTAuth(r chi.Router, jwtAuth *jwtauth.JWTAuth) {
-	r.Use(jwtauth.Verifier(jwtAuth))
-	r.Use(jwtauth.Authenticator(jwtAuth))
+// sharedVault makes the request served by the shared vault set in the URL.
+func sharedVault(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vaultID, err := uuid.Parse(chi.URLParam(r, vaultParam))
+		if err != nil {
+			writeBadRequest(w)
+			return
+		}
+
+		ctx := vault.ContextWithVault(r.Context(), vaultID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// tokenScope rejects requests of personal access tokens out of their scope: read-only tokens only read secrets
+// and the token limited to a shared vault is accepted in that vault only.
+func tokenScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		grant, ok := utils.AccessGrantFromContext(ctx)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if grant.ReadOnly && r.Method != http.MethodGet {
+			writeForbidden(w)
+			return
+		}
+		if grant.VaultID != uuid.Nil {
+			vaultID, shared := vault.VaultFromContext(ctx)
+			if !shared || vaultID != grant.VaultID {
+				writeForbidden(w)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
 }
@@ -2,6 +2,7 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/nestjam/goph-keeper/internal/config"
 	"github.com/nestjam/goph-keeper/internal/utils"
+	"github.com/nestjam/goph-keeper/internal/vault/model"
 )
 
 func TestMapVaultRoutes(t *testing.T) {
@@ -111,7 +113,7 @@ func TestMapVaultRoutes(t *testing.T) {
 
 		MapVaultRoutes(sut, spy, config)
 		secretID := uuid.New()
-		r := newDeleteSecretRequest(t, secretsPath, secretID)
+		r := newDeleteSecretRequest(t, secretsPath, secretID, model.FirstRevision)
 		userID := uuid.New()
 		setAuthCookie(t, r, config, userID)
 		w := httptest.NewRecorder()
@@ -138,6 +140,179 @@ func TestMapVaultRoutes(t *testing.T) {
 			assert.Equal(t, 1, spy.updateSecretCallsCount)
 		})
 	})
+
+	t.Run("sync", func(t *testing.T) {
+		tests := []string{"/sync", "/vaults/" + uuid.New().String() + "/sync"}
+
+		for _, path := range tests {
+			spy := &vaultHandlersSpy{}
+			sut := chi.NewRouter()
+			MapVaultRoutes(sut, spy, config)
+			r := newListSecretsRequest(t, path+"?since=1")
+			setAuthCookie(t, r, config, uuid.New())
+			w := httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, 1, spy.syncCallsCount, path)
+		}
+	})
+
+	t.Run("events", func(t *testing.T) {
+		tests := []string{"/events", "/vaults/" + uuid.New().String() + "/events"}
+
+		for _, path := range tests {
+			spy := &vaultHandlersSpy{}
+			sut := chi.NewRouter()
+			MapVaultRoutes(sut, spy, config)
+			r := newListSecretsRequest(t, path)
+			setAuthCookie(t, r, config, uuid.New())
+			w := httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, 1, spy.eventsCallsCount, path)
+		}
+	})
+
+	t.Run("shared vault", func(t *testing.T) {
+		t.Run("list secrets of shared vault", func(t *testing.T) {
+			spy := &vaultHandlersSpy{}
+			sut := chi.NewRouter()
+
+			MapVaultRoutes(sut, spy, config)
+			vaultID := uuid.New()
+			r := newListSecretsRequest(t, "/vaults/"+vaultID.String()+secretsPath)
+			setAuthCookie(t, r, config, uuid.New())
+			w := httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, 1, spy.listSecretsCallsCount)
+			assert.Equal(t, vaultID, spy.vaultID)
+		})
+		t.Run("personal vault", func(t *testing.T) {
+			spy := &vaultHandlersSpy{}
+			sut := chi.NewRouter()
+
+			MapVaultRoutes(sut, spy, config)
+			r := newListSecretsRequest(t, secretsPath)
+			setAuthCookie(t, r, config, uuid.New())
+			w := httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, 1, spy.listSecretsCallsCount)
+			assert.Equal(t, uuid.Nil, spy.vaultID)
+		})
+		t.Run("invalid vault id", func(t *testing.T) {
+			spy := &vaultHandlersSpy{}
+			sut := chi.NewRouter()
+
+			MapVaultRoutes(sut, spy, config)
+			r := newListSecretsRequest(t, "/vaults/abc"+secretsPath)
+			setAuthCookie(t, r, config, uuid.New())
+			w := httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, 0, spy.listSecretsCallsCount)
+		})
+	})
+	t.Run("personal access token", func(t *testing.T) {
+		t.Run("read only token lists secrets", func(t *testing.T) {
+			spy := &vaultHandlersSpy{}
+			sut := chi.NewRouter()
+			userID := uuid.New()
+			grant := &utils.AccessGrant{UserID: userID, ReadOnly: true}
+
+			MapVaultRoutes(sut, spy, config, withAccessGrant(grant))
+			r := newListSecretsRequest(t, secretsPath)
+			setAccessToken(r)
+			w := httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, 1, spy.listSecretsCallsCount)
+			assertUserIDFromToken(t, userID, spy)
+		})
+		t.Run("read only token does not add secret", func(t *testing.T) {
+			spy := &vaultHandlersSpy{}
+			sut := chi.NewRouter()
+			grant := &utils.AccessGrant{UserID: uuid.New(), ReadOnly: true}
+
+			MapVaultRoutes(sut, spy, config, withAccessGrant(grant))
+			r := newAddSecretRequest(t, secretsPath, Secret{})
+			setAccessToken(r)
+			w := httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusForbidden, w.Code)
+			assert.Equal(t, 0, spy.addSecretCallsCount)
+		})
+		t.Run("read write token adds secret", func(t *testing.T) {
+			spy := &vaultHandlersSpy{}
+			sut := chi.NewRouter()
+			grant := &utils.AccessGrant{UserID: uuid.New()}
+
+			MapVaultRoutes(sut, spy, config, withAccessGrant(grant))
+			r := newAddSecretRequest(t, secretsPath, Secret{})
+			setAccessToken(r)
+			w := httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, 1, spy.addSecretCallsCount)
+		})
+		t.Run("token of shared vault lists secrets of the vault", func(t *testing.T) {
+			spy := &vaultHandlersSpy{}
+			sut := chi.NewRouter()
+			vaultID := uuid.New()
+			grant := &utils.AccessGrant{UserID: uuid.New(), VaultID: vaultID, ReadOnly: true}
+
+			MapVaultRoutes(sut, spy, config, withAccessGrant(grant))
+			r := newListSecretsRequest(t, "/vaults/"+vaultID.String()+secretsPath)
+			setAccessToken(r)
+			w := httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, 1, spy.listSecretsCallsCount)
+			assert.Equal(t, vaultID, spy.vaultID)
+		})
+		t.Run("token of shared vault does not list secrets of another vault", func(t *testing.T) {
+			spy := &vaultHandlersSpy{}
+			sut := chi.NewRouter()
+			grant := &utils.AccessGrant{UserID: uuid.New(), VaultID: uuid.New(), ReadOnly: true}
+
+			MapVaultRoutes(sut, spy, config, withAccessGrant(grant))
+			r := newListSecretsRequest(t, "/vaults/"+uuid.New().String()+secretsPath)
+			setAccessToken(r)
+			w := httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusForbidden, w.Code)
+			assert.Equal(t, 0, spy.listSecretsCallsCount)
+		})
+		t.Run("token of shared vault does not list personal secrets", func(t *testing.T) {
+			spy := &vaultHandlersSpy{}
+			sut := chi.NewRouter()
+			grant := &utils.AccessGrant{UserID: uuid.New(), VaultID: uuid.New(), ReadOnly: true}
+
+			MapVaultRoutes(sut, spy, config, withAccessGrant(grant))
+			r := newListSecretsRequest(t, secretsPath)
+			setAccessToken(r)
+			w := httptest.NewRecorder()
+
+			sut.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusForbidden, w.Code)
+			assert.Equal(t, 0, spy.listSecretsCallsCount)
+		})
+	})
 }
 
 func newUpdateSecretRequest(t *testing.T, path string, secret Secret) *http.Request {
@@ -148,13 +323,16 @@ func newUpdateSecretRequest(t *testing.T, path string, secret Secret) *http.Requ
 	require.NoError(t, err)
 	r := httptest.NewRequest(http.MethodPatch, path+"/"+secret.ID, bytes.NewReader(content))
 	r.Header.Set(contentTypeHeader, applicationJSON)
+	r.Header.Set(IfMatchHeader, FormatETag(secret.Revision))
 	return r
 }
 
-func newDeleteSecretRequest(t *testing.T, path string, secretID uuid.UUID) *http.Request {
+func newDeleteSecretRequest(t *testing.T, path string, secretID uuid.UUID, revision int64) *http.Request {
 	t.Helper()
 
-	return httptest.NewRequest(http.MethodDelete, path+"/"+secretID.String(), nil)
+	r := httptest.NewRequest(http.MethodDelete, path+"/"+secretID.String(), nil)
+	r.Header.Set(IfMatchHeader, FormatETag(revision))
+	return r
 }
 
 func newGetSecretRequest(t *testing.T, path string, secretID uuid.UUID) *http.Request {
@@ -180,11 +358,28 @@ func assertUserIDFromToken(t *testing.T, userID uuid.UUID, spy *vaultHandlersSpy
 	assert.Equal(t, userID.String(), got)
 }
 
+type accessTokenVerifierFunc func(ctx context.Context, token string) (*utils.AccessGrant, error)
+
+func (f accessTokenVerifierFunc) VerifyAccessToken(ctx context.Context, token string) (*utils.AccessGrant, error) {
+	return f(ctx, token)
+}
+
+func withAccessGrant(grant *utils.AccessGrant) utils.AuthCookieBakerOption {
+	return utils.WithAccessTokenVerifier(accessTokenVerifierFunc(
+		func(ctx context.Context, token string) (*utils.AccessGrant, error) {
+			return grant, nil
+		}))
+}
+
+func setAccessToken(r *http.Request) {
+	r.Header.Set("Authorization", "Bearer "+utils.AccessTokenPrefix+"token")
+}
+
 func setAuthCookie(t *testing.T, r *http.Request, cfg config.JWTAuthConfig, id uuid.UUID) {
 	t.Helper()
 
 	baker := utils.NewAuthCookieBaker(cfg)
-	cookie, err := baker.BakeCookie(id)
+	cookie, err := baker.BakeCookie(id, uuid.New())
 	require.NoError(t, err)
 	r.AddCookie(cookie)
 }
@@ -9,11 +9,15 @@ import (
 )
 
 type vaultServiceMock struct {
-	ListSecretsFunc  func(ctx context.Context, userID uuid.UUID) ([]*model.Secret, error)
-	AddSecretFunc    func(ctx context.Context, secret *model.Secret, userID uuid.UUID) (uuid.UUID, error)
-	UpdateSecretFunc func(ctx context.Context, secret *model.Secret, userID uuid.UUID) error
-	GetSecretFunc    func(ctx context.Context, secretID, userID uuid.UUID) (*model.Secret, error)
-	DeleteSecretFunc func(ctx context.Context, secretID, userID uuid.UUID) error
+	ListSecretsFunc    func(ctx context.Context, userID uuid.UUID) ([]*model.Secret, error)
+	AddSecretFunc      func(ctx context.Context, secret *model.Secret, userID uuid.UUID) (uuid.UUID, error)
+	UpdateSecretFunc   func(ctx context.Context, secret *model.Secret, userID uuid.UUID) error
+	GetSecretFunc      func(ctx context.Context, secretID, userID uuid.UUID) (*model.Secret, error)
+	DeleteSecretFunc   func(ctx context.Context, secretID, userID uuid.UUID, revision int64) error
+	DeleteUserDataFunc func(ctx context.Context, userID uuid.UUID) (func(ctx context.Context) error, error)
+	ListChangesFunc    func(ctx context.Context, userID uuid.UUID, since int64) ([]*model.Change, error)
+	VerifyAccessFunc   func(ctx context.Context, userID uuid.UUID) error
+	WatchChangesFunc   func(ctx context.Context, userID uuid.UUID) (<-chan *model.Change, func(), error)
 }
 
 func (m *vaultServiceMock) ListSecrets(ctx context.Context, userID uuid.UUID) ([]*model.Secret, error) {
@@ -32,6 +36,24 @@ func (m *vaultServiceMock) GetSecret(ctx context.Context, secretID, userID uuid.
 	return m.GetSecretFunc(ctx, secretID, userID)
 }
 
-func (m *vaultServiceMock) DeleteSecret(ctx context.Context, secretID, userID uuid.UUID) error {
-	return m.DeleteSecretFunc(ctx, secretID, userID)
+func (m *vaultServiceMock) DeleteSecret(ctx context.Context, secretID, userID uuid.UUID, revision int64) error {
+	return m.DeleteSecretFunc(ctx, secretID, userID, revision)
+}
+
+func (m *vaultServiceMock) DeleteUserData(ctx context.Context, userID uuid.UUID) (func(ctx context.Context) error,
+	error) {
+	return m.DeleteUserDataFunc(ctx, userID)
+}
+
+func (m *vaultServiceMock) ListChanges(ctx context.Context, userID uuid.UUID, since int64) ([]*model.Change, error) {
+	return m.ListChangesFunc(ctx, userID, since)
+}
+
+func (m *vaultServiceMock) VerifyAccess(ctx context.Context, userID uuid.UUID) error {
+	return m.VerifyAccessFunc(ctx, userID)
+}
+
+func (m *vaultServiceMock) WatchChanges(ctx context.Context, userID uuid.UUID) (<-chan *model.Change, func(),
+	error) {
+	return m.WatchChangesFunc(ctx, userID)
 }
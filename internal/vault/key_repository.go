@@ -14,8 +14,9 @@ var (
 )
 
 type DataKeyRepository interface {
-	RotateKey(ctx context.Context, key *model.DataKey) (*model.DataKey, error)
-	GetKey(ctx context.Context) (*model.DataKey, error)
+	RotateKey(ctx context.Context, key *model.DataKey, userID uuid.UUID) (*model.DataKey, error)
+	GetKey(ctx context.Context, userID uuid.UUID) (*model.DataKey, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*model.DataKey, error)
 	UpdateStats(ctx context.Context, id uuid.UUID, dataSize int64) error
+	DeleteUserKeys(ctx context.Context, userID uuid.UUID) error
 }
@@ -10,51 +10,75 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+type DataKeyTestData struct {
+	Users uuid.UUIDs
+}
+
 type DataKeyRepositoryContract struct {
-	NewDataKeyRepository func() (DataKeyRepository, func())
+	NewDataKeyRepository func() (DataKeyRepository, func(), DataKeyTestData)
 }
 
 func (c DataKeyRepositoryContract) Test(t *testing.T) {
 	t.Run("add key", func(t *testing.T) {
-		sut, tearDown := c.NewDataKeyRepository()
+		sut, tearDown, td := c.NewDataKeyRepository()
 		t.Cleanup(tearDown)
 		ctx := context.Background()
+		userID := td.Users[0]
 		key, err := model.NewDataKey()
 		require.NoError(t, err)
 
-		key, err = sut.RotateKey(ctx, key)
+		key, err = sut.RotateKey(ctx, key, userID)
 
 		require.NoError(t, err)
 		assert.NotEqual(t, uuid.Nil, key.ID)
-
-		got, err := sut.GetKey(ctx)
+		got, err := sut.GetKey(ctx, userID)
 		require.NoError(t, err)
 		assert.Equal(t, key, got)
-
 		got, err = sut.GetByID(ctx, key.ID)
 		require.NoError(t, err)
 		assert.Equal(t, key, got)
 	})
 	t.Run("rotate key", func(t *testing.T) {
-		sut, tearDown := c.NewDataKeyRepository()
+		sut, tearDown, td := c.NewDataKeyRepository()
 		t.Cleanup(tearDown)
 		ctx := context.Background()
+		userID := td.Users[0]
 		key, err := model.NewDataKey()
 		require.NoError(t, err)
-		_, err = sut.RotateKey(ctx, key)
+		_, err = sut.RotateKey(ctx, key, userID)
 		require.NoError(t, err)
 		key2, err := model.NewDataKey()
 		require.NoError(t, err)
 
-		key2, err = sut.RotateKey(ctx, key2)
+		key2, err = sut.RotateKey(ctx, key2, userID)
 
 		require.NoError(t, err)
-		got, err := sut.GetKey(ctx)
+		got, err := sut.GetKey(ctx, userID)
 		require.NoError(t, err)
 		assert.Equal(t, key2, got)
 	})
+	t.Run("rotate key of another user", func(t *testing.T) {
+		sut, tearDown, td := c.NewDataKeyRepository()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		userID := td.Users[0]
+		user2ID := td.Users[1]
+		key, err := model.NewDataKey()
+		require.NoError(t, err)
+		key, err = sut.RotateKey(ctx, key, userID)
+		require.NoError(t, err)
+		key2, err := model.NewDataKey()
+		require.NoError(t, err)
+
+		_, err = sut.RotateKey(ctx, key2, user2ID)
+
+		require.NoError(t, err)
+		got, err := sut.GetKey(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, key, got)
+	})
 	t.Run("key not found by id", func(t *testing.T) {
-		sut, tearDown := c.NewDataKeyRepository()
+		sut, tearDown, _ := c.NewDataKeyRepository()
 		t.Cleanup(tearDown)
 		ctx := context.Background()
 		id := uuid.New()
@@ -64,22 +88,22 @@ func (c DataKeyRepositoryContract) Test(t *testing.T) {
 		require.ErrorIs(t, err, ErrKeyNotFound)
 	})
 	t.Run("active key is not set", func(t *testing.T) {
-		sut, tearDown := c.NewDataKeyRepository()
+		sut, tearDown, td := c.NewDataKeyRepository()
 		t.Cleanup(tearDown)
 		ctx := context.Background()
 
-		key, err := sut.GetKey(ctx)
+		key, err := sut.GetKey(ctx, td.Users[0])
 
 		require.NoError(t, err)
 		assert.Nil(t, key)
 	})
 	t.Run("update key stats", func(t *testing.T) {
 		t.Run("update data size encrypted by key", func(t *testing.T) {
-			sut, tearDown := c.NewDataKeyRepository()
+			sut, tearDown, td := c.NewDataKeyRepository()
 			t.Cleanup(tearDown)
 			ctx := context.Background()
 			key, _ := model.NewDataKey()
-			key, err := sut.RotateKey(ctx, key)
+			key, err := sut.RotateKey(ctx, key, td.Users[0])
 			require.NoError(t, err)
 			const (
 				dataSize              int64 = 100
@@ -104,7 +128,7 @@ func (c DataKeyRepositoryContract) Test(t *testing.T) {
 			assert.Equal(t, wantEncryptionsCount, key.EncryptionsCount)
 		})
 		t.Run("key not found", func(t *testing.T) {
-			sut, tearDown := c.NewDataKeyRepository()
+			sut, tearDown, _ := c.NewDataKeyRepository()
 			t.Cleanup(tearDown)
 			ctx := context.Background()
 			key, err := model.NewDataKey()
@@ -116,4 +140,44 @@ func (c DataKeyRepositoryContract) Test(t *testing.T) {
 			require.ErrorIs(t, err, ErrKeyNotFound)
 		})
 	})
+	t.Run("delete user keys", func(t *testing.T) {
+		t.Run("delete all keys of user", func(t *testing.T) {
+			sut, tearDown, td := c.NewDataKeyRepository()
+			t.Cleanup(tearDown)
+			ctx := context.Background()
+			userID := td.Users[0]
+			key, _ := model.NewDataKey()
+			key, err := sut.RotateKey(ctx, key, userID)
+			require.NoError(t, err)
+			key2, _ := model.NewDataKey()
+			key2, err = sut.RotateKey(ctx, key2, userID)
+			require.NoError(t, err)
+
+			err = sut.DeleteUserKeys(ctx, userID)
+
+			require.NoError(t, err)
+			_, err = sut.GetByID(ctx, key.ID)
+			require.ErrorIs(t, err, ErrKeyNotFound)
+			_, err = sut.GetByID(ctx, key2.ID)
+			require.ErrorIs(t, err, ErrKeyNotFound)
+			got, err := sut.GetKey(ctx, userID)
+			require.NoError(t, err)
+			assert.Nil(t, got)
+		})
+		t.Run("keep keys of another user", func(t *testing.T) {
+			sut, tearDown, td := c.NewDataKeyRepository()
+			t.Cleanup(tearDown)
+			ctx := context.Background()
+			key, _ := model.NewDataKey()
+			key, err := sut.RotateKey(ctx, key, td.Users[1])
+			require.NoError(t, err)
+
+			err = sut.DeleteUserKeys(ctx, td.Users[0])
+
+			require.NoError(t, err)
+			got, err := sut.GetByID(ctx, key.ID)
+			require.NoError(t, err)
+			assert.Equal(t, key, got)
+		})
+	})
 }
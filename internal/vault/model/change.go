@@ -0,0 +1,16 @@
+package model
+
+import "github.com/google/uuid"
+
+// Change is the latest change of a secret in the change log of its owner.
+// Every change of the owner secrets gets the next sequence number, so clients
+// keep their replica up to date requesting changes after the last seen number.
+type Change struct {
+	// Name is the name of the secret, it is empty for deleted secrets.
+	Name     string
+	Seq      int64
+	Revision int64
+	SecretID uuid.UUID
+	// Deleted marks the tombstone of the deleted secret.
+	Deleted bool
+}
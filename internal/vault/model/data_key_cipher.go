@@ -18,14 +18,19 @@ func NewDataKeyCipher(dataKey *DataKey) *DataKeyCipher {
 func (c *DataKeyCipher) Seal(unsealed *Secret) (*Secret, error) {
 	const op = "seal"
 
+	plaintext, err := pack(unsealed.Data)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
 	cipher := utils.NewBlockCipher(c.dataKey.Key)
-	ciphertext, err := cipher.Seal(unsealed.Data)
+	ciphertext, err := cipher.Seal(plaintext)
 	if err != nil {
 		return nil, errors.Wrap(err, op)
 	}
 
 	sealed := unsealed.Copy()
-	sealed.Data = ciphertext
+	sealed.Data = append([]byte{envelopeVersion}, ciphertext...)
 	sealed.KeyID = c.dataKey.ID
 
 	return sealed, nil
@@ -34,15 +39,34 @@ func (c *DataKeyCipher) Seal(unsealed *Secret) (*Secret, error) {
 func (c *DataKeyCipher) Unseal(sealed *Secret) (unsealed *Secret, err error) {
 	const op = "unseal"
 
-	cipher := utils.NewBlockCipher(c.dataKey.Key)
-	plaintext, err := cipher.Unseal(sealed.Data)
+	data, err := c.open(sealed.Data)
 	if err != nil {
 		return nil, errors.Wrap(err, op)
 	}
 
 	unsealed = sealed.Copy()
 	unsealed.KeyID = uuid.Nil
-	unsealed.Data = plaintext
+	unsealed.Data = data
 
 	return unsealed, nil
 }
+
+func (c *DataKeyCipher) open(data []byte) ([]byte, error) {
+	const op = "open"
+
+	cipher := utils.NewBlockCipher(c.dataKey.Key)
+	if len(data) > 0 && data[0] == envelopeVersion {
+		plaintext, err := cipher.Unseal(data[1:])
+		if err == nil {
+			return unpack(plaintext)
+		}
+	}
+
+	// legacy data may start with the version byte by chance, so it is tried as well
+	plaintext, err := cipher.Unseal(data)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return plaintext, nil
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -46,6 +47,55 @@ sed do eiusmod tempor incididunt ut labore et dolore magna aliqua.`
 
 		assert.Equal(t, want, unsealed)
 	})
+	t.Run("compress large data", func(t *testing.T) {
+		key, err := NewDataKey()
+		require.NoError(t, err)
+		sut := NewDataKeyCipher(key)
+		want := &Secret{
+			ID:   uuid.New(),
+			Data: []byte(strings.Repeat("-----BEGIN CERTIFICATE-----\n", 100)),
+		}
+
+		sealed, err := sut.Seal(want)
+
+		require.NoError(t, err)
+		assert.Less(t, len(sealed.Data), len(want.Data))
+		assert.Less(t, EncryptedDataSize(sealed), int64(len(want.Data)))
+
+		unsealed, err := sut.Unseal(sealed)
+
+		require.NoError(t, err)
+		assert.Equal(t, want, unsealed)
+	})
+	t.Run("skip compression of small data", func(t *testing.T) {
+		key, err := NewDataKey()
+		require.NoError(t, err)
+		sut := NewDataKeyCipher(key)
+		want := &Secret{Data: []byte(strings.Repeat("a", compressionThreshold-1))}
+
+		sealed, err := sut.Seal(want)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(want.Data)), EncryptedDataSize(sealed))
+	})
+	t.Run("skip compression of incompressible data", func(t *testing.T) {
+		key, err := NewDataKey()
+		require.NoError(t, err)
+		sut := NewDataKeyCipher(key)
+		data, err := utils.GenerateRandom(2 * compressionThreshold)
+		require.NoError(t, err)
+		want := &Secret{Data: data}
+
+		sealed, err := sut.Seal(want)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(want.Data)), EncryptedDataSize(sealed))
+
+		unsealed, err := sut.Unseal(sealed)
+
+		require.NoError(t, err)
+		assert.Equal(t, want, unsealed)
+	})
 	t.Run("seal with invalid key size", func(t *testing.T) {
 		want := &Secret{
 			ID:   uuid.New(),
@@ -64,6 +114,40 @@ sed do eiusmod tempor incididunt ut labore et dolore magna aliqua.`
 }
 
 func TestDataKey_Unseal(t *testing.T) {
+	t.Run("unseal data sealed without envelope", func(t *testing.T) {
+		key, err := NewDataKey()
+		require.NoError(t, err)
+		sut := NewDataKeyCipher(key)
+		want := []byte("data")
+		ciphertext, err := utils.NewBlockCipher(key.Key).Seal(want)
+		require.NoError(t, err)
+		sealed := &Secret{Data: ciphertext}
+
+		got, err := sut.Unseal(sealed)
+
+		require.NoError(t, err)
+		assert.Equal(t, want, got.Data)
+	})
+	t.Run("unseal tampered data", func(t *testing.T) {
+		key, err := NewDataKey()
+		require.NoError(t, err)
+		sut := NewDataKeyCipher(key)
+		sealed, err := sut.Seal(&Secret{Data: []byte("data")})
+		require.NoError(t, err)
+		sealed.Data[len(sealed.Data)-1] ^= 0xff
+
+		_, err = sut.Unseal(sealed)
+
+		require.Error(t, err)
+	})
+	t.Run("unseal data decompressed beyond limit", func(t *testing.T) {
+		compressed, err := compress(make([]byte, maxDecompressedSize+1))
+		require.NoError(t, err)
+
+		_, err = unpack(append([]byte{flagCompressed}, compressed...))
+
+		require.ErrorIs(t, err, errDecompressedTooBig)
+	})
 	t.Run("unseal data with invalid key", func(t *testing.T) {
 		secret := &Secret{
 			ID:   uuid.New(),
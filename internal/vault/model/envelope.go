@@ -0,0 +1,120 @@
+package model
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Sealed secret data has the following layout:
+//
+//	version | nonce | seal(flags | payload) | tag
+//
+// Flags are sealed along with the payload, so they can not be tampered with.
+// Data sealed before the envelope was introduced has no version and flags.
+const (
+	envelopeVersion byte = 1
+	flagCompressed  byte = 1 << 0
+
+	gcmNonceSize     = 12
+	gcmTagSize       = 16
+	envelopeOverhead = 1 + gcmNonceSize + 1 + gcmTagSize
+
+	compressionThreshold = 1024 // smaller data is not worth compressing
+	// maxDecompressedSize bounds the memory a tampered payload decompresses to,
+	// larger data is never compressed.
+	maxDecompressedSize = 64 << 20
+)
+
+var (
+	errInvalidEnvelope    = errors.New("invalid envelope")
+	errDecompressedTooBig = errors.New("decompressed data is too large")
+)
+
+// EncryptedDataSize returns size of the payload encrypted to seal the secret.
+// It is less than the size of secret data when the data was compressed.
+func EncryptedDataSize(sealed *Secret) int64 {
+	size := len(sealed.Data) - envelopeOverhead
+	if size < 0 {
+		return 0
+	}
+	return int64(size)
+}
+
+func pack(data []byte) ([]byte, error) {
+	const op = "pack"
+
+	if len(data) >= compressionThreshold && len(data) <= maxDecompressedSize {
+		compressed, err := compress(data)
+		if err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+
+		if len(compressed) < len(data) {
+			return append([]byte{flagCompressed}, compressed...), nil
+		}
+	}
+
+	return append([]byte{0}, data...), nil
+}
+
+func unpack(packed []byte) ([]byte, error) {
+	const op = "unpack"
+
+	if len(packed) == 0 {
+		return nil, errors.Wrap(errInvalidEnvelope, op)
+	}
+
+	flags, payload := packed[0], packed[1:]
+	if flags&flagCompressed == 0 {
+		return payload, nil
+	}
+
+	data, err := decompress(payload)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return data, nil
+}
+
+func compress(data []byte) ([]byte, error) {
+	const op = "compress"
+
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	_, err = w.Write(data)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	err = w.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decompress(data []byte) ([]byte, error) {
+	const op = "decompress"
+
+	r := flate.NewReader(bytes.NewReader(data))
+	defer func() { _ = r.Close() }()
+
+	decompressed, err := io.ReadAll(io.LimitReader(r, maxDecompressedSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	if len(decompressed) > maxDecompressedSize {
+		return nil, errors.Wrap(errDecompressedTooBig, op)
+	}
+
+	return decompressed, nil
+}
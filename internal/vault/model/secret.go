@@ -2,18 +2,35 @@ package model
 
 import "github.com/google/uuid"
 
+// FirstRevision is the revision of a newly added secret.
+// Every update of the secret increments its revision by one.
+const FirstRevision int64 = 1
+
 type Secret struct {
-	Name  string
-	Data  []byte
-	ID    uuid.UUID
-	KeyID uuid.UUID
+	Name string
+	Data []byte
+	// BlobChecksum is SHA-256 checksum of the sealed data kept in the blob store.
+	BlobChecksum []byte
+	ID           uuid.UUID
+	KeyID        uuid.UUID
+	// BlobID refers to the sealed data kept in the blob store instead of the secret itself.
+	BlobID   uuid.UUID
+	Revision int64
 }
 
 func (s *Secret) Copy() *Secret {
 	return &Secret{
-		ID:    s.ID,
-		Name:  s.Name,
-		Data:  s.Data,
-		KeyID: s.KeyID,
+		ID:           s.ID,
+		Name:         s.Name,
+		Data:         s.Data,
+		KeyID:        s.KeyID,
+		Revision:     s.Revision,
+		BlobID:       s.BlobID,
+		BlobChecksum: s.BlobChecksum,
 	}
 }
+
+// HasBlob reports whether the sealed data of the secret is kept in the blob store.
+func (s *Secret) HasBlob() bool {
+	return s.BlobID != uuid.Nil
+}
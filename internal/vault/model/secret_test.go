@@ -9,10 +9,11 @@ import (
 
 func TestCopy(t *testing.T) {
 	sut := &Secret{
-		ID:    uuid.New(),
-		Name:  "secret",
-		Data:  []byte("data"),
-		KeyID: uuid.New(),
+		ID:       uuid.New(),
+		Name:     "secret",
+		Data:     []byte("data"),
+		KeyID:    uuid.New(),
+		Revision: 2,
 	}
 
 	got := sut.Copy()
@@ -0,0 +1,23 @@
+package vault
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrOwnerKeyNotFound = errors.New("owner key not found")
+)
+
+// OwnerKeyStore keeps key-encryption keys of the vault owners apart from the storage.
+// Data keys of the owner are sealed by the key, and backups of the storage never include it,
+// so deleting the key makes the secrets of the owner unrecoverable even from old backups.
+type OwnerKeyStore interface {
+	GetOwnerKey(ctx context.Context, ownerID uuid.UUID) ([]byte, error)
+	// AddOwnerKey stores the key unless the owner has one already, and returns the stored key.
+	AddOwnerKey(ctx context.Context, ownerID uuid.UUID, key []byte) ([]byte, error)
+	// DeleteOwnerKey deletes the key. Deleting a key that does not exist is not an error.
+	DeleteOwnerKey(ctx context.Context, ownerID uuid.UUID) error
+}
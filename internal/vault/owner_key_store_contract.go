@@ -0,0 +1,86 @@
+package vault
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type OwnerKeyStoreContract struct {
+	NewOwnerKeyStore func() (OwnerKeyStore, func())
+}
+
+func (c OwnerKeyStoreContract) Test(t *testing.T) {
+	t.Run("add get owner key", func(t *testing.T) {
+		sut, tearDown := c.NewOwnerKeyStore()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		ownerID := uuid.New()
+		want := []byte("key")
+
+		added, err := sut.AddOwnerKey(ctx, ownerID, want)
+
+		require.NoError(t, err)
+		assert.Equal(t, want, added)
+		got, err := sut.GetOwnerKey(ctx, ownerID)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+	t.Run("add key of owner that has one", func(t *testing.T) {
+		sut, tearDown := c.NewOwnerKeyStore()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		ownerID := uuid.New()
+		want := []byte("key")
+		_, err := sut.AddOwnerKey(ctx, ownerID, want)
+		require.NoError(t, err)
+
+		added, err := sut.AddOwnerKey(ctx, ownerID, []byte("another key"))
+
+		require.NoError(t, err)
+		assert.Equal(t, want, added)
+		got, err := sut.GetOwnerKey(ctx, ownerID)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+	t.Run("get key that does not exist", func(t *testing.T) {
+		sut, tearDown := c.NewOwnerKeyStore()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+
+		_, err := sut.GetOwnerKey(ctx, uuid.New())
+
+		require.ErrorIs(t, err, ErrOwnerKeyNotFound)
+	})
+	t.Run("delete owner key", func(t *testing.T) {
+		sut, tearDown := c.NewOwnerKeyStore()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+		ownerID := uuid.New()
+		_, err := sut.AddOwnerKey(ctx, ownerID, []byte("key"))
+		require.NoError(t, err)
+		anotherOwnerID := uuid.New()
+		_, err = sut.AddOwnerKey(ctx, anotherOwnerID, []byte("another key"))
+		require.NoError(t, err)
+
+		err = sut.DeleteOwnerKey(ctx, ownerID)
+
+		require.NoError(t, err)
+		_, err = sut.GetOwnerKey(ctx, ownerID)
+		require.ErrorIs(t, err, ErrOwnerKeyNotFound)
+		_, err = sut.GetOwnerKey(ctx, anotherOwnerID)
+		require.NoError(t, err)
+	})
+	t.Run("delete key that does not exist", func(t *testing.T) {
+		sut, tearDown := c.NewOwnerKeyStore()
+		t.Cleanup(tearDown)
+		ctx := context.Background()
+
+		err := sut.DeleteOwnerKey(ctx, uuid.New())
+
+		require.NoError(t, err)
+	})
+}
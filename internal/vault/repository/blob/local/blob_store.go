@@ -0,0 +1,104 @@
+package local
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/vault"
+)
+
+const (
+	dirMode  = 0o700
+	fileMode = 0o600
+)
+
+type blobStore struct {
+	root string
+}
+
+// NewBlobStore creates a store that keeps blobs as files under the root directory.
+func NewBlobStore(root string) (*blobStore, error) {
+	const op = "new blob store"
+
+	err := os.MkdirAll(root, dirMode)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return &blobStore{root}, nil
+}
+
+func (s *blobStore) PutBlob(ctx context.Context, blobID uuid.UUID, data []byte) error {
+	const op = "put blob"
+
+	name := s.path(blobID)
+	err := os.MkdirAll(filepath.Dir(name), dirMode)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	// the blob is written to a temporary file first, so that it is never read partially written
+	tmp, err := os.CreateTemp(filepath.Dir(name), blobID.String()+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	_, err = tmp.Write(data)
+	if err != nil {
+		_ = tmp.Close()
+		return errors.Wrap(err, op)
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return errors.Wrap(err, op)
+	}
+	if err = tmp.Close(); err != nil {
+		return errors.Wrap(err, op)
+	}
+	if err = os.Chmod(tmp.Name(), fileMode); err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = os.Rename(tmp.Name(), name)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (s *blobStore) GetBlob(ctx context.Context, blobID uuid.UUID) ([]byte, error) {
+	const op = "get blob"
+
+	data, err := os.ReadFile(s.path(blobID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, vault.ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return data, nil
+}
+
+func (s *blobStore) DeleteBlob(ctx context.Context, blobID uuid.UUID) error {
+	const op = "delete blob"
+
+	err := os.Remove(s.path(blobID))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// path spreads blobs over subdirectories named after the first two characters of the blob id.
+func (s *blobStore) path(blobID uuid.UUID) string {
+	id := blobID.String()
+	return filepath.Join(s.root, id[:2], id)
+}
@@ -0,0 +1,22 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/vault"
+)
+
+func TestBlobStore(t *testing.T) {
+	vault.BlobStoreContract{
+		NewBlobStore: func() (vault.BlobStore, func()) {
+			t.Helper()
+
+			s, err := NewBlobStore(t.TempDir())
+			require.NoError(t, err)
+
+			return s, func() {}
+		},
+	}.Test(t)
+}
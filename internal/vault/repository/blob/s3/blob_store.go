@@ -0,0 +1,110 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/config"
+	"github.com/nestjam/goph-keeper/internal/vault"
+)
+
+const (
+	contentType   = "application/octet-stream"
+	noSuchKeyCode = "NoSuchKey"
+)
+
+type blobStore struct {
+	client    *minio.Client
+	transport *http.Transport
+	bucket    string
+}
+
+// NewBlobStore creates a store that keeps blobs as objects of the S3 compatible bucket.
+// The bucket is created if it does not exist.
+func NewBlobStore(ctx context.Context, conf config.S3Config) (*blobStore, error) {
+	const op = "new blob store"
+
+	transport, err := minio.DefaultTransport(conf.UseSSL)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	client, err := minio.New(conf.Endpoint, &minio.Options{
+		Creds:     credentials.NewStaticV4(conf.AccessKey, conf.SecretKey, ""),
+		Secure:    conf.UseSSL,
+		Region:    conf.Region,
+		Transport: transport,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	exists, err := client.BucketExists(ctx, conf.Bucket)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	if !exists {
+		err = client.MakeBucket(ctx, conf.Bucket, minio.MakeBucketOptions{Region: conf.Region})
+		if err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+	}
+
+	return &blobStore{client, transport, conf.Bucket}, nil
+}
+
+// Close closes the idle connections to the storage.
+func (s *blobStore) Close() {
+	s.transport.CloseIdleConnections()
+}
+
+func (s *blobStore) PutBlob(ctx context.Context, blobID uuid.UUID, data []byte) error {
+	const op = "put blob"
+
+	_, err := s.client.PutObject(ctx, s.bucket, blobID.String(), bytes.NewReader(data), int64(len(data)),
+		minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (s *blobStore) GetBlob(ctx context.Context, blobID uuid.UUID) ([]byte, error) {
+	const op = "get blob"
+
+	obj, err := s.client.GetObject(ctx, s.bucket, blobID.String(), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	defer func() { _ = obj.Close() }()
+
+	// the object is requested on the first read
+	data, err := io.ReadAll(obj)
+	if minio.ToErrorResponse(err).Code == noSuchKeyCode {
+		return nil, vault.ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return data, nil
+}
+
+func (s *blobStore) DeleteBlob(ctx context.Context, blobID uuid.UUID) error {
+	const op = "delete blob"
+
+	err := s.client.RemoveObject(ctx, s.bucket, blobID.String(), minio.RemoveObjectOptions{})
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
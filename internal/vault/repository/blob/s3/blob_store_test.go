@@ -0,0 +1,36 @@
+//go:build integration
+
+package s3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/utils"
+	"github.com/nestjam/goph-keeper/internal/vault"
+)
+
+var h *utils.MinIOTestHelper
+
+func TestMain(m *testing.M) {
+	h = &utils.MinIOTestHelper{}
+	h.Run(m)
+}
+
+func TestBlobStore(t *testing.T) {
+	vault.BlobStoreContract{
+		NewBlobStore: func() (vault.BlobStore, func()) {
+			t.Helper()
+
+			conf := h.S3Config
+			conf.Bucket = "blobs-" + uuid.NewString()[:8]
+			s, err := NewBlobStore(context.Background(), conf)
+			require.NoError(t, err)
+
+			return s, func() {}
+		},
+	}.Test(t)
+}
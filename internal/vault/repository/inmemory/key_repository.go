@@ -11,18 +11,25 @@ import (
 )
 
 type dataKeyRepository struct {
-	keys map[uuid.UUID]*model.DataKey
-	key  *model.DataKey
-	mu   sync.Mutex
+	keys      map[uuid.UUID]*model.DataKey
+	userKeys  map[uuid.UUID]*model.DataKey
+	keyOwners map[uuid.UUID]uuid.UUID
+	mu        sync.Mutex
 }
 
 func NewDataKeyRepository() vault.DataKeyRepository {
 	return &dataKeyRepository{
-		keys: make(map[uuid.UUID]*model.DataKey),
+		keys:      make(map[uuid.UUID]*model.DataKey),
+		userKeys:  make(map[uuid.UUID]*model.DataKey),
+		keyOwners: make(map[uuid.UUID]uuid.UUID),
 	}
 }
 
-func (r *dataKeyRepository) RotateKey(ctx context.Context, key *model.DataKey) (*model.DataKey, error) {
+func (r *dataKeyRepository) RotateKey(
+	ctx context.Context,
+	key *model.DataKey,
+	userID uuid.UUID,
+) (*model.DataKey, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -31,16 +38,17 @@ func (r *dataKeyRepository) RotateKey(ctx context.Context, key *model.DataKey) (
 	newKey.ID = id
 
 	r.keys[id] = newKey
-	r.key = newKey
+	r.keyOwners[id] = userID
+	r.userKeys[userID] = newKey
 
 	return newKey, nil
 }
 
-func (r *dataKeyRepository) GetKey(ctx context.Context) (*model.DataKey, error) {
+func (r *dataKeyRepository) GetKey(ctx context.Context, userID uuid.UUID) (*model.DataKey, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	return r.key, nil
+	return r.userKeys[userID], nil
 }
 
 func (r *dataKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.DataKey, error) {
@@ -68,3 +76,18 @@ func (r *dataKeyRepository) UpdateStats(ctx context.Context, id uuid.UUID, dataS
 
 	return nil
 }
+
+func (r *dataKeyRepository) DeleteUserKeys(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, owner := range r.keyOwners {
+		if owner == userID {
+			delete(r.keys, id)
+			delete(r.keyOwners, id)
+		}
+	}
+	delete(r.userKeys, userID)
+
+	return nil
+}
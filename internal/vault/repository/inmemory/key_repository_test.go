@@ -3,17 +3,22 @@ package inmemory
 import (
 	"testing"
 
+	"github.com/google/uuid"
+
 	"github.com/nestjam/goph-keeper/internal/vault"
 )
 
 func TestDataKeyRepository(t *testing.T) {
 	vault.DataKeyRepositoryContract{
-		NewDataKeyRepository: func() (vault.DataKeyRepository, func()) {
+		NewDataKeyRepository: func() (vault.DataKeyRepository, func(), vault.DataKeyTestData) {
 			t.Helper()
 
 			r := NewDataKeyRepository()
-			return r, func() {
+			closer := func() {}
+			testData := vault.DataKeyTestData{
+				Users: uuid.UUIDs{uuid.New(), uuid.New()},
 			}
+			return r, closer, testData
 		},
 	}.Test(t)
 }
@@ -0,0 +1,52 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/vault"
+)
+
+type ownerKeyStore struct {
+	keys map[uuid.UUID][]byte
+	mu   sync.Mutex
+}
+
+func NewOwnerKeyStore() vault.OwnerKeyStore {
+	return &ownerKeyStore{
+		keys: make(map[uuid.UUID][]byte),
+	}
+}
+
+func (s *ownerKeyStore) GetOwnerKey(ctx context.Context, ownerID uuid.UUID) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.keys[ownerID]; ok {
+		return key, nil
+	}
+
+	return nil, vault.ErrOwnerKeyNotFound
+}
+
+func (s *ownerKeyStore) AddOwnerKey(ctx context.Context, ownerID uuid.UUID, key []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored, ok := s.keys[ownerID]; ok {
+		return stored, nil
+	}
+
+	s.keys[ownerID] = key
+	return key, nil
+}
+
+func (s *ownerKeyStore) DeleteOwnerKey(ctx context.Context, ownerID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, ownerID)
+	return nil
+}
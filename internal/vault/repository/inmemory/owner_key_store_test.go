@@ -0,0 +1,15 @@
+package inmemory
+
+import (
+	"testing"
+
+	"github.com/nestjam/goph-keeper/internal/vault"
+)
+
+func TestOwnerKeyStore(t *testing.T) {
+	vault.OwnerKeyStoreContract{
+		NewOwnerKeyStore: func() (vault.OwnerKeyStore, func()) {
+			return NewOwnerKeyStore(), func() {}
+		},
+	}.Test(t)
+}
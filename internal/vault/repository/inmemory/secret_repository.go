@@ -2,6 +2,7 @@ package inmemory
 
 import (
 	"context"
+	"sort"
 	"sync"
 
 	"github.com/google/uuid"
@@ -12,14 +13,20 @@ import (
 
 type userSecrets map[uuid.UUID]*model.Secret
 
+// userChanges are the latest changes of the user secrets by secret.
+type userChanges map[uuid.UUID]*model.Change
+
 type secretRepository struct {
 	userSecrets map[uuid.UUID]userSecrets
+	userChanges map[uuid.UUID]userChanges
+	seq         int64
 	mu          sync.Mutex
 }
 
 func NewSecretRepository() vault.SecretRepository {
 	return &secretRepository{
 		userSecrets: make(map[uuid.UUID]userSecrets),
+		userChanges: make(map[uuid.UUID]userChanges),
 	}
 }
 
@@ -34,6 +41,7 @@ func (r *secretRepository) ListSecrets(ctx context.Context, userID uuid.UUID) ([
 	for _, secret := range userSecrets {
 		secrets[i] = secret.Copy()
 		secrets[i].Data = nil
+		secrets[i].BlobChecksum = nil
 		secrets[i].KeyID = uuid.Nil
 		i++
 	}
@@ -47,13 +55,16 @@ func (r *secretRepository) AddSecret(ctx context.Context, s *model.Secret, userI
 
 	secret := s.Copy()
 	secret.ID = uuid.New()
+	secret.Revision = model.FirstRevision
 
 	if _, ok := r.userSecrets[userID]; !ok {
 		r.userSecrets[userID] = make(userSecrets)
 	}
 	secrets := r.userSecrets[userID]
 	secrets[secret.ID] = secret
+	r.appendChange(userID, &model.Change{SecretID: secret.ID, Name: secret.Name, Revision: secret.Revision})
 
+	s.Revision = secret.Revision
 	return secret.ID, nil
 }
 
@@ -68,11 +79,18 @@ func (r *secretRepository) UpdateSecret(ctx context.Context, s *model.Secret, us
 	}
 	secrets := r.userSecrets[userID]
 
-	if _, ok := secrets[secret.ID]; !ok {
+	stored, ok := secrets[secret.ID]
+	if !ok {
 		return vault.ErrSecretNotFound
 	}
+	if stored.Revision != secret.Revision {
+		return vault.ErrSecretRevisionMismatch
+	}
+	secret.Revision++
 	secrets[secret.ID] = secret
+	r.appendChange(userID, &model.Change{SecretID: secret.ID, Name: secret.Name, Revision: secret.Revision})
 
+	s.Revision = secret.Revision
 	return nil
 }
 
@@ -93,16 +111,63 @@ func (r *secretRepository) GetSecret(ctx context.Context, secretID, userID uuid.
 	return secret, nil
 }
 
-func (r *secretRepository) DeleteSecret(ctx context.Context, secretID, userID uuid.UUID) error {
+func (r *secretRepository) DeleteSecret(ctx context.Context, secretID, userID uuid.UUID, revision int64) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	userSecrets, ok := r.userSecrets[userID]
 	if !ok {
-		return nil
+		return vault.ErrSecretNotFound
+	}
+
+	secret, ok := userSecrets[secretID]
+	if !ok {
+		return vault.ErrSecretNotFound
+	}
+	if secret.Revision != revision {
+		return vault.ErrSecretRevisionMismatch
 	}
 
 	delete(userSecrets, secretID)
+	r.appendChange(userID, &model.Change{SecretID: secretID, Revision: secret.Revision, Deleted: true})
 
 	return nil
 }
+
+func (r *secretRepository) DeleteUserSecrets(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.userSecrets, userID)
+	delete(r.userChanges, userID)
+
+	return nil
+}
+
+func (r *secretRepository) ListChanges(ctx context.Context, userID uuid.UUID, since int64) ([]*model.Change, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var changes []*model.Change
+	for _, change := range r.userChanges[userID] {
+		if change.Seq > since {
+			c := *change
+			changes = append(changes, &c)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Seq < changes[j].Seq
+	})
+
+	return changes, nil
+}
+
+// appendChange replaces the latest change of the secret.
+func (r *secretRepository) appendChange(userID uuid.UUID, change *model.Change) {
+	if _, ok := r.userChanges[userID]; !ok {
+		r.userChanges[userID] = make(userChanges)
+	}
+	r.seq++
+	change.Seq = r.seq
+	r.userChanges[userID][change.SecretID] = change
+}
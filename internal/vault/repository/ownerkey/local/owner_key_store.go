@@ -0,0 +1,97 @@
+package local
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/vault"
+)
+
+const (
+	dirMode  = 0o700
+	fileMode = 0o600
+)
+
+type ownerKeyStore struct {
+	root string
+}
+
+// NewOwnerKeyStore creates a store that keeps keys of the owners as files under the root directory.
+// Servers that share the storage must share the directory too.
+func NewOwnerKeyStore(root string) (*ownerKeyStore, error) {
+	const op = "new owner key store"
+
+	err := os.MkdirAll(root, dirMode)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return &ownerKeyStore{root}, nil
+}
+
+func (s *ownerKeyStore) GetOwnerKey(ctx context.Context, ownerID uuid.UUID) ([]byte, error) {
+	const op = "get owner key"
+
+	key, err := os.ReadFile(s.path(ownerID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, vault.ErrOwnerKeyNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return key, nil
+}
+
+func (s *ownerKeyStore) AddOwnerKey(ctx context.Context, ownerID uuid.UUID, key []byte) ([]byte, error) {
+	const op = "add owner key"
+
+	tmp, err := os.CreateTemp(s.root, ownerID.String()+".*.tmp")
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	_, err = tmp.Write(key)
+	if err != nil {
+		_ = tmp.Close()
+		return nil, errors.Wrap(err, op)
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return nil, errors.Wrap(err, op)
+	}
+	if err = tmp.Close(); err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	// unlike rename, link fails if the owner has a key already, so that the key is never replaced
+	err = os.Link(tmp.Name(), s.path(ownerID))
+	if errors.Is(err, os.ErrExist) {
+		return s.GetOwnerKey(ctx, ownerID)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return key, nil
+}
+
+func (s *ownerKeyStore) DeleteOwnerKey(ctx context.Context, ownerID uuid.UUID) error {
+	const op = "delete owner key"
+
+	err := os.Remove(s.path(ownerID))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (s *ownerKeyStore) path(ownerID uuid.UUID) string {
+	return filepath.Join(s.root, ownerID.String())
+}
@@ -0,0 +1,22 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/vault"
+)
+
+func TestOwnerKeyStore(t *testing.T) {
+	vault.OwnerKeyStoreContract{
+		NewOwnerKeyStore: func() (vault.OwnerKeyStore, func()) {
+			t.Helper()
+
+			s, err := NewOwnerKeyStore(t.TempDir())
+			require.NoError(t, err)
+
+			return s, func() {}
+		},
+	}.Test(t)
+}
@@ -8,61 +8,39 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/pkg/errors"
 
+	pgstorage "github.com/nestjam/goph-keeper/internal/storage/pgsql"
 	"github.com/nestjam/goph-keeper/internal/vault"
 	"github.com/nestjam/goph-keeper/internal/vault/model"
 )
 
 type dataKeyRepository struct {
-	pool       *pgxpool.Pool
-	connString string
+	pool *pgxpool.Pool
 }
 
-func NewDataKeyRepository(ctx context.Context, connString string) (*dataKeyRepository, error) {
-	const op = "new user repository"
-
-	var err error
-	pool, err := initPool(ctx, connString)
-	if err != nil {
-		return nil, errors.Wrapf(err, op)
-	}
-
-	r := &dataKeyRepository{
-		pool,
-		connString,
-	}
-	return r, nil
-}
-
-func (r *dataKeyRepository) Close() {
-	if r.pool == nil {
-		return
-	}
-	r.pool.Close()
+func NewDataKeyRepository(pool *pgxpool.Pool) *dataKeyRepository {
+	return &dataKeyRepository{pool}
 }
 
-func (r *dataKeyRepository) RotateKey(ctx context.Context, key *model.DataKey) (*model.DataKey, error) {
+func (r *dataKeyRepository) RotateKey(
+	ctx context.Context,
+	key *model.DataKey,
+	userID uuid.UUID,
+) (*model.DataKey, error) {
 	const op = "rotate key"
 
-	conn, err := r.pool.Acquire(ctx)
-	if err != nil {
-		return nil, errors.Wrap(err, op)
-	}
-	defer conn.Release()
-
-	var txOptions pgx.TxOptions
-	tx, err := conn.BeginTx(ctx, txOptions)
+	tx, err := pgstorage.BeginTx(ctx, r.pool)
 	if err != nil {
 		return nil, errors.Wrap(err, op)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	_, err = tx.Exec(ctx, `UPDATE keys SET is_disposed = 'true'`)
+	_, err = tx.Exec(ctx, `UPDATE keys SET is_disposed = 'true' WHERE user_id=$1`, userID)
 	if err != nil {
 		return nil, errors.Wrap(err, op)
 	}
 
-	const sql = `INSERT INTO keys (key_data) VALUES ($1) RETURNING key_id;`
-	row := tx.QueryRow(ctx, sql, key.Key)
+	const sql = `INSERT INTO keys (key_data, user_id) VALUES ($1, $2) RETURNING key_id;`
+	row := tx.QueryRow(ctx, sql, key.Key, userID)
 	err = row.Scan(&key.ID)
 	if err != nil {
 		return nil, errors.Wrap(err, op)
@@ -76,20 +54,16 @@ func (r *dataKeyRepository) RotateKey(ctx context.Context, key *model.DataKey) (
 	return key, nil
 }
 
-func (r *dataKeyRepository) GetKey(ctx context.Context) (*model.DataKey, error) {
+func (r *dataKeyRepository) GetKey(ctx context.Context, userID uuid.UUID) (*model.DataKey, error) {
 	const op = "get key"
 
-	conn, err := r.pool.Acquire(ctx)
-	if err != nil {
-		return nil, errors.Wrap(err, op)
-	}
-	defer conn.Release()
+	conn := pgstorage.QuerierFromContext(ctx, r.pool)
 
 	key := &model.DataKey{}
 	const sql = `SELECT key_id, key_data, COALESCE(encriptions_count, 0), COALESCE(encrypted_data_size, 0)
-FROM keys WHERE is_disposed='false'`
-	row := conn.QueryRow(ctx, sql)
-	err = row.Scan(&key.ID, &key.Key, &key.EncryptionsCount, &key.EncryptedDataSize)
+FROM keys WHERE is_disposed='false' AND user_id=$1`
+	row := conn.QueryRow(ctx, sql, userID)
+	err := row.Scan(&key.ID, &key.Key, &key.EncryptionsCount, &key.EncryptedDataSize)
 	if errors.Is(err, pgx.ErrNoRows) {
 		var k *model.DataKey
 		return k, nil
@@ -104,17 +78,13 @@ FROM keys WHERE is_disposed='false'`
 func (r *dataKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.DataKey, error) {
 	const op = "get by id"
 
-	conn, err := r.pool.Acquire(ctx)
-	if err != nil {
-		return nil, errors.Wrap(err, op)
-	}
-	defer conn.Release()
+	conn := pgstorage.QuerierFromContext(ctx, r.pool)
 
 	key := &model.DataKey{}
 	const sql = `SELECT key_id, key_data, COALESCE(encriptions_count, 0), COALESCE(encrypted_data_size, 0)
 FROM keys WHERE key_id=$1`
 	row := conn.QueryRow(ctx, sql, id)
-	err = row.Scan(&key.ID, &key.Key, &key.EncryptionsCount, &key.EncryptedDataSize)
+	err := row.Scan(&key.ID, &key.Key, &key.EncryptionsCount, &key.EncryptedDataSize)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, vault.ErrKeyNotFound
 	}
@@ -128,14 +98,7 @@ FROM keys WHERE key_id=$1`
 func (r *dataKeyRepository) UpdateStats(ctx context.Context, id uuid.UUID, dataSize int64) error {
 	const op = "update stats"
 
-	conn, err := r.pool.Acquire(ctx)
-	if err != nil {
-		return errors.Wrap(err, op)
-	}
-	defer conn.Release()
-
-	var txOptions pgx.TxOptions
-	tx, err := conn.BeginTx(ctx, txOptions)
+	tx, err := pgstorage.BeginTx(ctx, r.pool)
 	if err != nil {
 		return errors.Wrap(err, op)
 	}
@@ -169,22 +132,24 @@ FROM keys WHERE key_id=$1`
 	return nil
 }
 
-func initPool(ctx context.Context, connString string) (*pgxpool.Pool, error) {
-	const op = "init pool"
+func (r *dataKeyRepository) DeleteUserKeys(ctx context.Context, userID uuid.UUID) error {
+	const op = "delete user keys"
 
-	poolCfg, err := pgxpool.ParseConfig(connString)
+	tx, err := pgstorage.BeginTx(ctx, r.pool)
 	if err != nil {
-		return nil, errors.Wrap(err, op)
+		return errors.Wrap(err, op)
 	}
+	defer func() { _ = tx.Rollback(ctx) }()
 
-	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	_, err = tx.Exec(ctx, `DELETE FROM keys WHERE user_id=$1`, userID)
 	if err != nil {
-		return nil, errors.Wrap(err, op)
+		return errors.Wrap(err, op)
 	}
 
-	if err := pool.Ping(ctx); err != nil {
-		return nil, errors.Wrap(err, op)
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.Wrap(err, op)
 	}
 
-	return pool, nil
+	return nil
 }
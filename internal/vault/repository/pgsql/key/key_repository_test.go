@@ -6,9 +6,15 @@ import (
 	"context"
 	"testing"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 
+	modelAuth "github.com/nestjam/goph-keeper/internal/auth/model"
+	"github.com/nestjam/goph-keeper/internal/auth/repository/pgsql"
+	"github.com/nestjam/goph-keeper/internal/config"
+	pgstorage "github.com/nestjam/goph-keeper/internal/storage/pgsql"
 	"github.com/nestjam/goph-keeper/internal/utils"
 	"github.com/nestjam/goph-keeper/internal/vault"
 	"github.com/nestjam/goph-keeper/migration"
@@ -23,7 +29,7 @@ func TestMain(m *testing.M) {
 
 func TestKeyRepository(t *testing.T) {
 	vault.DataKeyRepositoryContract{
-		NewDataKeyRepository: func() (vault.DataKeyRepository, func()) {
+		NewDataKeyRepository: func() (vault.DataKeyRepository, func(), vault.DataKeyTestData) {
 			t.Helper()
 
 			dsn := h.DataSourceName
@@ -32,15 +38,78 @@ func TestKeyRepository(t *testing.T) {
 			require.NoError(t, err)
 
 			ctx := context.Background()
-			r, err := NewDataKeyRepository(ctx, dsn)
+			pool, err := pgstorage.NewPool(ctx, config.PostgresConfig{DataSourceName: dsn})
 			require.NoError(t, err)
+			r := NewDataKeyRepository(pool)
 
-			return r, func() {
-				r.Close()
+			closer := func() {
+				pool.Close()
 
 				migrator := migration.NewDatabaseMigrator(dsn)
 				_ = migrator.Drop()
 			}
+
+			testData := vault.DataKeyTestData{
+				Users: setupUsers(t, pool),
+			}
+			return r, closer, testData
 		},
 	}.Test(t)
 }
+
+func TestLegacyKeyOwners(t *testing.T) {
+	dsn := h.DataSourceName
+	migrator := migration.NewDatabaseMigrator(dsn)
+	require.NoError(t, migrator.Up())
+	require.NoError(t, migrator.Down(1))
+	ctx := context.Background()
+	pool, err := pgstorage.NewPool(ctx, config.PostgresConfig{DataSourceName: dsn})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		pool.Close()
+		_ = migrator.Drop()
+	})
+	users := setupUsers(t, pool)
+	var keyID uuid.UUID
+	err = pool.QueryRow(ctx, `INSERT INTO keys (key_data) VALUES ($1) RETURNING key_id`, []byte("key")).Scan(&keyID)
+	require.NoError(t, err)
+	for _, userID := range users {
+		_, err = pool.Exec(ctx, `INSERT INTO secrets (user_id, key_id, name, data) VALUES ($1, $2, 'name', 'data')`,
+			userID, keyID)
+		require.NoError(t, err)
+	}
+
+	err = migrator.Up()
+
+	require.NoError(t, err)
+	for _, userID := range users {
+		var owner uuid.UUID
+		var key []byte
+		err = pool.QueryRow(ctx, `SELECT k.user_id, k.key_data FROM secrets s JOIN keys k ON k.key_id = s.key_id
+WHERE s.user_id = $1`, userID).Scan(&owner, &key)
+		require.NoError(t, err)
+		require.Equal(t, userID, owner)
+		require.Equal(t, []byte("key"), key)
+	}
+	var legacy int
+	require.NoError(t, pool.QueryRow(ctx, `SELECT COUNT(*) FROM keys WHERE user_id IS NULL`).Scan(&legacy))
+	require.Zero(t, legacy)
+	r := NewDataKeyRepository(pool)
+	active, err := r.GetKey(ctx, users[0])
+	require.NoError(t, err)
+	require.Nil(t, active)
+}
+
+func setupUsers(t *testing.T, pool *pgxpool.Pool) uuid.UUIDs {
+	t.Helper()
+
+	ctx := context.Background()
+	r := pgsql.NewUserRepository(pool)
+
+	userID, err := r.Register(ctx, &modelAuth.User{Email: "user@email.com", Password: "1"})
+	require.NoError(t, err)
+	user2ID, err := r.Register(ctx, &modelAuth.User{Email: "user2@email.com", Password: "2"})
+	require.NoError(t, err)
+
+	return uuid.UUIDs{userID, user2ID}
+}
@@ -8,48 +8,25 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/pkg/errors"
 
+	pgstorage "github.com/nestjam/goph-keeper/internal/storage/pgsql"
 	"github.com/nestjam/goph-keeper/internal/vault"
 	"github.com/nestjam/goph-keeper/internal/vault/model"
 )
 
 type secretRepository struct {
-	pool       *pgxpool.Pool
-	connString string
+	pool *pgxpool.Pool
 }
 
-func NewSecretRepository(ctx context.Context, connString string) (*secretRepository, error) {
-	const op = "new secret repository"
-
-	var err error
-	pool, err := initPool(ctx, connString)
-	if err != nil {
-		return nil, errors.Wrapf(err, op)
-	}
-
-	r := &secretRepository{
-		pool,
-		connString,
-	}
-	return r, nil
-}
-
-func (r *secretRepository) Close() {
-	if r.pool == nil {
-		return
-	}
-	r.pool.Close()
+func NewSecretRepository(pool *pgxpool.Pool) *secretRepository {
+	return &secretRepository{pool}
 }
 
 func (r *secretRepository) ListSecrets(ctx context.Context, userID uuid.UUID) ([]*model.Secret, error) {
 	const op = "list secrets"
 
-	conn, err := r.pool.Acquire(ctx)
-	if err != nil {
-		return nil, errors.Wrap(err, op)
-	}
-	defer conn.Release()
+	conn := pgstorage.QuerierFromContext(ctx, r.pool)
 
-	const sql = "SELECT secret_id, name FROM secrets WHERE user_id=$1"
+	const sql = "SELECT secret_id, name, revision, blob_id FROM secrets WHERE user_id=$1"
 	rows, err := conn.Query(ctx, sql, userID)
 	if err != nil {
 		return nil, errors.Wrap(err, op)
@@ -59,10 +36,12 @@ func (r *secretRepository) ListSecrets(ctx context.Context, userID uuid.UUID) ([
 	var secrets []*model.Secret
 	for rows.Next() {
 		secret := &model.Secret{}
-		err := rows.Scan(&secret.ID, &secret.Name)
+		var blobID uuid.NullUUID
+		err := rows.Scan(&secret.ID, &secret.Name, &secret.Revision, &blobID)
 		if err != nil {
 			return nil, errors.Wrap(err, op)
 		}
+		secret.BlobID = blobID.UUID
 
 		secrets = append(secrets, secret)
 	}
@@ -76,23 +55,23 @@ func (r *secretRepository) ListSecrets(ctx context.Context, userID uuid.UUID) ([
 func (r *secretRepository) AddSecret(ctx context.Context, secret *model.Secret, userID uuid.UUID) (uuid.UUID, error) {
 	const op = "add secret"
 
-	conn, err := r.pool.Acquire(ctx)
+	tx, err := pgstorage.BeginTx(ctx, r.pool)
 	if err != nil {
 		return uuid.Nil, errors.Wrap(err, op)
 	}
-	defer conn.Release()
+	defer func() { _ = tx.Rollback(ctx) }()
 
-	var txOptions pgx.TxOptions
-	tx, err := conn.BeginTx(ctx, txOptions)
+	const sql = `INSERT INTO secrets (user_id, key_id, name, data, revision, blob_id, blob_checksum)
+VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING secret_id;`
+	row := tx.QueryRow(ctx, sql, userID, secret.KeyID, secret.Name, secret.Data, model.FirstRevision,
+		nullBlobID(secret), secret.BlobChecksum)
+	var id uuid.UUID
+	err = row.Scan(&id)
 	if err != nil {
 		return uuid.Nil, errors.Wrap(err, op)
 	}
-	defer func() { _ = tx.Rollback(ctx) }()
 
-	const sql = `INSERT INTO secrets (user_id, key_id, name, data) VALUES ($1, $2, $3, $4) RETURNING secret_id;`
-	row := tx.QueryRow(ctx, sql, userID, secret.KeyID, secret.Name, secret.Data)
-	var id uuid.UUID
-	err = row.Scan(&id)
+	err = appendChange(ctx, tx, userID, &model.Change{SecretID: id, Name: secret.Name, Revision: model.FirstRevision})
 	if err != nil {
 		return uuid.Nil, errors.Wrap(err, op)
 	}
@@ -102,30 +81,33 @@ func (r *secretRepository) AddSecret(ctx context.Context, secret *model.Secret,
 		return uuid.Nil, errors.Wrap(err, op)
 	}
 
+	secret.Revision = model.FirstRevision
 	return id, nil
 }
 
 func (r *secretRepository) UpdateSecret(ctx context.Context, secret *model.Secret, userID uuid.UUID) error {
 	const op = "update secret"
 
-	conn, err := r.pool.Acquire(ctx)
+	tx, err := pgstorage.BeginTx(ctx, r.pool)
 	if err != nil {
 		return errors.Wrap(err, op)
 	}
-	defer conn.Release()
+	defer func() { _ = tx.Rollback(ctx) }()
 
-	var txOptions pgx.TxOptions
-	tx, err := conn.BeginTx(ctx, txOptions)
+	const sql = `UPDATE secrets SET name=$1, data=$2, blob_id=$3, blob_checksum=$4, revision=revision+1
+WHERE secret_id=$5 AND user_id=$6 AND revision=$7 RETURNING revision;`
+	row := tx.QueryRow(ctx, sql, secret.Name, secret.Data, nullBlobID(secret), secret.BlobChecksum,
+		secret.ID, userID, secret.Revision)
+	var revision int64
+	err = row.Scan(&revision)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return revisionMismatch(ctx, tx, secret.ID, userID)
+	}
 	if err != nil {
 		return errors.Wrap(err, op)
 	}
-	defer func() { _ = tx.Rollback(ctx) }()
 
-	const sql = `UPDATE secrets SET name=$1, data=$2 WHERE secret_id=$3;`
-	tag, err := tx.Exec(ctx, sql, secret.Name, secret.Data, secret.ID)
-	if tag.RowsAffected() == 0 {
-		return vault.ErrSecretNotFound
-	}
+	err = appendChange(ctx, tx, userID, &model.Change{SecretID: secret.ID, Name: secret.Name, Revision: revision})
 	if err != nil {
 		return errors.Wrap(err, op)
 	}
@@ -135,22 +117,21 @@ func (r *secretRepository) UpdateSecret(ctx context.Context, secret *model.Secre
 		return errors.Wrap(err, op)
 	}
 
+	secret.Revision = revision
 	return nil
 }
 
 func (r *secretRepository) GetSecret(ctx context.Context, secretID, userID uuid.UUID) (*model.Secret, error) {
 	const op = "get secret"
 
-	conn, err := r.pool.Acquire(ctx)
-	if err != nil {
-		return nil, errors.Wrap(err, op)
-	}
-	defer conn.Release()
+	conn := pgstorage.QuerierFromContext(ctx, r.pool)
 
 	secret := &model.Secret{ID: secretID}
-	const sql = `SELECT key_id, name, data FROM secrets WHERE secret_id=$1 AND user_id=$2`
+	const sql = `SELECT key_id, name, data, revision, blob_id, blob_checksum FROM secrets
+WHERE secret_id=$1 AND user_id=$2`
 	row := conn.QueryRow(ctx, sql, secretID, userID)
-	err = row.Scan(&secret.KeyID, &secret.Name, &secret.Data)
+	var blobID uuid.NullUUID
+	err := row.Scan(&secret.KeyID, &secret.Name, &secret.Data, &secret.Revision, &blobID, &secret.BlobChecksum)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, vault.ErrSecretNotFound
 	}
@@ -158,27 +139,56 @@ func (r *secretRepository) GetSecret(ctx context.Context, secretID, userID uuid.
 		return nil, errors.Wrap(err, op)
 	}
 
+	secret.BlobID = blobID.UUID
 	return secret, nil
 }
 
-func (r *secretRepository) DeleteSecret(ctx context.Context, secretID, userID uuid.UUID) error {
+func (r *secretRepository) DeleteSecret(ctx context.Context, secretID, userID uuid.UUID, revision int64) error {
 	const op = "delete secret"
 
-	conn, err := r.pool.Acquire(ctx)
+	tx, err := pgstorage.BeginTx(ctx, r.pool)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	const sql = `DELETE FROM secrets WHERE secret_id=$1 AND user_id=$2 AND revision=$3;`
+	tag, err := tx.Exec(ctx, sql, secretID, userID, revision)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if tag.RowsAffected() == 0 {
+		return revisionMismatch(ctx, tx, secretID, userID)
+	}
+
+	err = appendChange(ctx, tx, userID, &model.Change{SecretID: secretID, Revision: revision, Deleted: true})
 	if err != nil {
 		return errors.Wrap(err, op)
 	}
-	defer conn.Release()
 
-	var txOptions pgx.TxOptions
-	tx, err := conn.BeginTx(ctx, txOptions)
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (r *secretRepository) DeleteUserSecrets(ctx context.Context, userID uuid.UUID) error {
+	const op = "delete user secrets"
+
+	tx, err := pgstorage.BeginTx(ctx, r.pool)
 	if err != nil {
 		return errors.Wrap(err, op)
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	const sql = `DELETE FROM secrets WHERE secret_id=$1;`
-	_, err = tx.Exec(ctx, sql, secretID)
+	_, err = tx.Exec(ctx, `DELETE FROM secrets WHERE user_id=$1;`, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM secret_changes WHERE user_id=$1;`, userID)
 	if err != nil {
 		return errors.Wrap(err, op)
 	}
@@ -191,22 +201,66 @@ func (r *secretRepository) DeleteSecret(ctx context.Context, secretID, userID uu
 	return nil
 }
 
-func initPool(ctx context.Context, connString string) (*pgxpool.Pool, error) {
-	const op = "init pool"
+func (r *secretRepository) ListChanges(ctx context.Context, userID uuid.UUID, since int64) ([]*model.Change, error) {
+	const op = "list changes"
+
+	conn := pgstorage.QuerierFromContext(ctx, r.pool)
 
-	poolCfg, err := pgxpool.ParseConfig(connString)
+	const sql = `SELECT seq, secret_id, COALESCE(name, ''), revision, deleted FROM secret_changes
+WHERE user_id=$1 AND seq>$2 ORDER BY seq`
+	rows, _ := conn.Query(ctx, sql, userID, since)
+	changes, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.Change, error) {
+		var c model.Change
+		err := row.Scan(&c.Seq, &c.SecretID, &c.Name, &c.Revision, &c.Deleted)
+		return &c, err
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, op)
 	}
 
-	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	return changes, nil
+}
+
+// appendChange replaces the latest change of the secret. Changes of the owner are serialized,
+// so they are committed in order of their sequence numbers and clients never skip any of them.
+func appendChange(ctx context.Context, tx pgx.Tx, userID uuid.UUID, change *model.Change) error {
+	const op = "append change"
+
+	_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1::text, 0))`, userID)
 	if err != nil {
-		return nil, errors.Wrap(err, op)
+		return errors.Wrap(err, op)
 	}
 
-	if err := pool.Ping(ctx); err != nil {
-		return nil, errors.Wrap(err, op)
+	const sql = `INSERT INTO secret_changes (user_id, secret_id, name, revision, deleted)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (secret_id) DO UPDATE SET seq=excluded.seq, name=excluded.name, revision=excluded.revision,
+deleted=excluded.deleted`
+	_, err = tx.Exec(ctx, sql, userID, change.SecretID, change.Name, change.Revision, change.Deleted)
+	if err != nil {
+		return errors.Wrap(err, op)
 	}
 
-	return pool, nil
+	return nil
+}
+
+// revisionMismatch returns ErrSecretRevisionMismatch if the user has the secret and ErrSecretNotFound otherwise.
+func revisionMismatch(ctx context.Context, tx pgx.Tx, secretID, userID uuid.UUID) error {
+	const op = "check secret revision"
+
+	var exists bool
+	const sql = `SELECT EXISTS(SELECT 1 FROM secrets WHERE secret_id=$1 AND user_id=$2)`
+	err := tx.QueryRow(ctx, sql, secretID, userID).Scan(&exists)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if exists {
+		return vault.ErrSecretRevisionMismatch
+	}
+
+	return vault.ErrSecretNotFound
+}
+
+// nullBlobID stores no blob reference for secrets kept along with their data.
+func nullBlobID(secret *model.Secret) uuid.NullUUID {
+	return uuid.NullUUID{UUID: secret.BlobID, Valid: secret.HasBlob()}
 }
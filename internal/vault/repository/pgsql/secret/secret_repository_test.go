@@ -7,11 +7,15 @@ import (
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 
 	modelAuth "github.com/nestjam/goph-keeper/internal/auth/model"
 	"github.com/nestjam/goph-keeper/internal/auth/repository/pgsql"
+	"github.com/nestjam/goph-keeper/internal/backup"
+	"github.com/nestjam/goph-keeper/internal/config"
+	pgstorage "github.com/nestjam/goph-keeper/internal/storage/pgsql"
 	"github.com/nestjam/goph-keeper/internal/utils"
 	"github.com/nestjam/goph-keeper/internal/vault"
 	modelVault "github.com/nestjam/goph-keeper/internal/vault/model"
@@ -37,31 +41,54 @@ func TestSecretRepository(t *testing.T) {
 			require.NoError(t, err)
 
 			ctx := context.Background()
-			r, err := NewSecretRepository(ctx, dsn)
+			pool, err := pgstorage.NewPool(ctx, config.PostgresConfig{DataSourceName: dsn})
 			require.NoError(t, err)
+			r := NewSecretRepository(pool)
 
 			closer := func() {
-				r.Close()
+				pool.Close()
 
 				migrator := migration.NewDatabaseMigrator(dsn)
 				_ = migrator.Drop()
 			}
 
+			users := setupUsers(t, pool)
 			testData := vault.SecretTestData{
-				Users: setupUsers(t),
-				Keys:  setupKeys(t),
+				Users: users,
+				Keys:  setupKeys(t, pool, users[0]),
 			}
 			return r, closer, testData
 		},
+		NewRestoredSecretRepository: func(snapshot *backup.Snapshot) (vault.SecretRepository, func()) {
+			t.Helper()
+
+			dsn := h.DataSourceName
+			migrator := migration.NewDatabaseMigrator(dsn)
+			err := migrator.Up()
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			pool, err := pgstorage.NewPool(ctx, config.PostgresConfig{DataSourceName: dsn})
+			require.NoError(t, err)
+			err = pgstorage.NewBackupStore(pool).Import(ctx, snapshot)
+			require.NoError(t, err)
+
+			closer := func() {
+				pool.Close()
+
+				migrator := migration.NewDatabaseMigrator(dsn)
+				_ = migrator.Drop()
+			}
+			return NewSecretRepository(pool), closer
+		},
 	}.Test(t)
 }
 
-func setupUsers(t *testing.T) uuid.UUIDs {
+func setupUsers(t *testing.T, pool *pgxpool.Pool) uuid.UUIDs {
 	t.Helper()
 
 	ctx := context.Background()
-	r, err := pgsql.NewUserRepository(ctx, h.DataSourceName)
-	require.NoError(t, err)
+	r := pgsql.NewUserRepository(pool)
 
 	userID, err := r.Register(ctx, &modelAuth.User{Email: "user@email.com", Password: "1"})
 	require.NoError(t, err)
@@ -71,14 +98,13 @@ func setupUsers(t *testing.T) uuid.UUIDs {
 	return uuid.UUIDs{userID, user2ID}
 }
 
-func setupKeys(t *testing.T) uuid.UUIDs {
+func setupKeys(t *testing.T, pool *pgxpool.Pool, userID uuid.UUID) uuid.UUIDs {
 	t.Helper()
 
 	ctx := context.Background()
-	r, err := key.NewDataKeyRepository(ctx, h.DataSourceName)
-	require.NoError(t, err)
+	r := key.NewDataKeyRepository(pool)
 
-	key, err := r.RotateKey(ctx, &modelVault.DataKey{})
+	key, err := r.RotateKey(ctx, &modelVault.DataKey{}, userID)
 	require.NoError(t, err)
 
 	return uuid.UUIDs{key.ID}
@@ -0,0 +1,128 @@
+package key
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	sqlitestorage "github.com/nestjam/goph-keeper/internal/storage/sqlite"
+	"github.com/nestjam/goph-keeper/internal/vault"
+	"github.com/nestjam/goph-keeper/internal/vault/model"
+)
+
+type dataKeyRepository struct {
+	db *sql.DB
+}
+
+func NewDataKeyRepository(db *sql.DB) *dataKeyRepository {
+	return &dataKeyRepository{db}
+}
+
+func (r *dataKeyRepository) RotateKey(
+	ctx context.Context,
+	key *model.DataKey,
+	userID uuid.UUID,
+) (*model.DataKey, error) {
+	const op = "rotate key"
+
+	tx, err := sqlitestorage.BeginTx(ctx, r.db)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(ctx, `UPDATE keys SET is_disposed=1 WHERE user_id=?`, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	keyID := uuid.New()
+	const query = `INSERT INTO keys (key_id, key_data, user_id) VALUES (?, ?, ?);`
+	_, err = tx.ExecContext(ctx, query, keyID, key.Key, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	key.ID = keyID
+	return key, nil
+}
+
+func (r *dataKeyRepository) GetKey(ctx context.Context, userID uuid.UUID) (*model.DataKey, error) {
+	const op = "get key"
+
+	key := &model.DataKey{}
+	const query = `SELECT key_id, key_data, encriptions_count, encrypted_data_size
+FROM keys WHERE is_disposed=0 AND user_id=?`
+	row := r.executor(ctx).QueryRowContext(ctx, query, userID)
+	err := row.Scan(&key.ID, &key.Key, &key.EncryptionsCount, &key.EncryptedDataSize)
+	if errors.Is(err, sql.ErrNoRows) {
+		var k *model.DataKey
+		return k, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return key, nil
+}
+
+func (r *dataKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.DataKey, error) {
+	const op = "get by id"
+
+	key := &model.DataKey{}
+	const query = `SELECT key_id, key_data, encriptions_count, encrypted_data_size
+FROM keys WHERE key_id=?`
+	row := r.executor(ctx).QueryRowContext(ctx, query, id)
+	err := row.Scan(&key.ID, &key.Key, &key.EncryptionsCount, &key.EncryptedDataSize)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, vault.ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return key, nil
+}
+
+func (r *dataKeyRepository) UpdateStats(ctx context.Context, id uuid.UUID, dataSize int64) error {
+	const op = "update stats"
+
+	const query = `UPDATE keys SET encriptions_count=encriptions_count+1, encrypted_data_size=encrypted_data_size+?
+WHERE key_id=?`
+	res, err := r.executor(ctx).ExecContext(ctx, query, dataSize, id)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if n == 0 {
+		return vault.ErrKeyNotFound
+	}
+
+	return nil
+}
+
+func (r *dataKeyRepository) DeleteUserKeys(ctx context.Context, userID uuid.UUID) error {
+	const op = "delete user keys"
+
+	_, err := r.executor(ctx).ExecContext(ctx, `DELETE FROM keys WHERE user_id=?`, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// executor returns transaction of the context if any.
+func (r *dataKeyRepository) executor(ctx context.Context) sqlitestorage.Executor {
+	return sqlitestorage.ExecutorFromContext(ctx, r.db)
+}
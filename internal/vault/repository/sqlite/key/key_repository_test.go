@@ -0,0 +1,60 @@
+package key
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	modelAuth "github.com/nestjam/goph-keeper/internal/auth/model"
+	"github.com/nestjam/goph-keeper/internal/auth/repository/sqlite"
+	sqlitestorage "github.com/nestjam/goph-keeper/internal/storage/sqlite"
+	"github.com/nestjam/goph-keeper/internal/vault"
+	"github.com/nestjam/goph-keeper/migration"
+)
+
+func TestKeyRepository(t *testing.T) {
+	vault.DataKeyRepositoryContract{
+		NewDataKeyRepository: func() (vault.DataKeyRepository, func(), vault.DataKeyTestData) {
+			t.Helper()
+
+			path := filepath.Join(t.TempDir(), "goph-keeper.db")
+			migrator := migration.NewSQLiteMigrator(path)
+			err := migrator.Up()
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			db, err := sqlitestorage.Open(ctx, path)
+			require.NoError(t, err)
+			r := NewDataKeyRepository(db)
+
+			closer := func() {
+				_ = db.Close()
+			}
+
+			testData := vault.DataKeyTestData{
+				Users: setupUsers(t, path),
+			}
+			return r, closer, testData
+		},
+	}.Test(t)
+}
+
+func setupUsers(t *testing.T, path string) uuid.UUIDs {
+	t.Helper()
+
+	ctx := context.Background()
+	db, err := sqlitestorage.Open(ctx, path)
+	require.NoError(t, err)
+	r := sqlite.NewUserRepository(db)
+	defer func() { _ = db.Close() }()
+
+	userID, err := r.Register(ctx, &modelAuth.User{Email: "user@email.com", Password: "1"})
+	require.NoError(t, err)
+	user2ID, err := r.Register(ctx, &modelAuth.User{Email: "user2@email.com", Password: "2"})
+	require.NoError(t, err)
+
+	return uuid.UUIDs{userID, user2ID}
+}
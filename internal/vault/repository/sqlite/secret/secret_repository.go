@@ -0,0 +1,274 @@
+package secret
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	sqlitestorage "github.com/nestjam/goph-keeper/internal/storage/sqlite"
+	"github.com/nestjam/goph-keeper/internal/vault"
+	"github.com/nestjam/goph-keeper/internal/vault/model"
+)
+
+type secretRepository struct {
+	db *sql.DB
+}
+
+func NewSecretRepository(db *sql.DB) *secretRepository {
+	return &secretRepository{db}
+}
+
+func (r *secretRepository) ListSecrets(ctx context.Context, userID uuid.UUID) ([]*model.Secret, error) {
+	const op = "list secrets"
+
+	const query = "SELECT secret_id, name, revision, blob_id FROM secrets WHERE user_id=?"
+	rows, err := r.executor(ctx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var secrets []*model.Secret
+	for rows.Next() {
+		secret := &model.Secret{}
+		var blobID uuid.NullUUID
+		err := rows.Scan(&secret.ID, &secret.Name, &secret.Revision, &blobID)
+		if err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+		secret.BlobID = blobID.UUID
+
+		secrets = append(secrets, secret)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return secrets, nil
+}
+
+func (r *secretRepository) AddSecret(ctx context.Context, secret *model.Secret, userID uuid.UUID) (uuid.UUID, error) {
+	const op = "add secret"
+
+	tx, err := sqlitestorage.BeginTx(ctx, r.db)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	id := uuid.New()
+	const query = `INSERT INTO secrets (secret_id, user_id, key_id, name, data, revision, blob_id, blob_checksum)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?);`
+	_, err = tx.ExecContext(ctx, query,
+		id, userID, secret.KeyID, secret.Name, secret.Data, model.FirstRevision, nullBlobID(secret), secret.BlobChecksum)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	err = appendChange(ctx, tx, userID, &model.Change{SecretID: id, Name: secret.Name, Revision: model.FirstRevision})
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	secret.Revision = model.FirstRevision
+	return id, nil
+}
+
+func (r *secretRepository) UpdateSecret(ctx context.Context, secret *model.Secret, userID uuid.UUID) error {
+	const op = "update secret"
+
+	tx, err := sqlitestorage.BeginTx(ctx, r.db)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	const query = `UPDATE secrets SET name=?, data=?, blob_id=?, blob_checksum=?, revision=revision+1
+WHERE secret_id=? AND user_id=? AND revision=? RETURNING revision;`
+	row := tx.QueryRowContext(ctx, query, secret.Name, secret.Data,
+		nullBlobID(secret), secret.BlobChecksum, secret.ID, userID, secret.Revision)
+	var revision int64
+	err = row.Scan(&revision)
+	if errors.Is(err, sql.ErrNoRows) {
+		return revisionMismatch(ctx, tx, secret.ID, userID)
+	}
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = appendChange(ctx, tx, userID, &model.Change{SecretID: secret.ID, Name: secret.Name, Revision: revision})
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	secret.Revision = revision
+	return nil
+}
+
+func (r *secretRepository) GetSecret(ctx context.Context, secretID, userID uuid.UUID) (*model.Secret, error) {
+	const op = "get secret"
+
+	secret := &model.Secret{ID: secretID}
+	const query = `SELECT key_id, name, data, revision, blob_id, blob_checksum FROM secrets
+WHERE secret_id=? AND user_id=?`
+	row := r.executor(ctx).QueryRowContext(ctx, query, secretID, userID)
+	var blobID uuid.NullUUID
+	err := row.Scan(&secret.KeyID, &secret.Name, &secret.Data, &secret.Revision, &blobID, &secret.BlobChecksum)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, vault.ErrSecretNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	secret.BlobID = blobID.UUID
+	return secret, nil
+}
+
+func (r *secretRepository) DeleteSecret(ctx context.Context, secretID, userID uuid.UUID, revision int64) error {
+	const op = "delete secret"
+
+	tx, err := sqlitestorage.BeginTx(ctx, r.db)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	const query = `DELETE FROM secrets WHERE secret_id=? AND user_id=? AND revision=?;`
+	res, err := tx.ExecContext(ctx, query, secretID, userID, revision)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if n == 0 {
+		return revisionMismatch(ctx, tx, secretID, userID)
+	}
+
+	err = appendChange(ctx, tx, userID, &model.Change{SecretID: secretID, Revision: revision, Deleted: true})
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (r *secretRepository) DeleteUserSecrets(ctx context.Context, userID uuid.UUID) error {
+	const op = "delete user secrets"
+
+	tx, err := sqlitestorage.BeginTx(ctx, r.db)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM secrets WHERE user_id=?;`, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM secret_changes WHERE user_id=?;`, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (r *secretRepository) ListChanges(ctx context.Context, userID uuid.UUID, since int64) ([]*model.Change, error) {
+	const op = "list changes"
+
+	const query = `SELECT seq, secret_id, COALESCE(name, ''), revision, deleted FROM secret_changes
+WHERE user_id=? AND seq>? ORDER BY seq`
+	rows, err := r.executor(ctx).QueryContext(ctx, query, userID, since)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var changes []*model.Change
+	for rows.Next() {
+		var c model.Change
+		if err = rows.Scan(&c.Seq, &c.SecretID, &c.Name, &c.Revision, &c.Deleted); err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+		changes = append(changes, &c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return changes, nil
+}
+
+// appendChange replaces the latest change of the secret. The sequence numbers are never reused,
+// so the change gets the number greater than any number seen by clients.
+func appendChange(ctx context.Context, e sqlitestorage.Executor, userID uuid.UUID, change *model.Change) error {
+	const op = "append change"
+
+	_, err := e.ExecContext(ctx, `DELETE FROM secret_changes WHERE secret_id=?`, change.SecretID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	const query = `INSERT INTO secret_changes (user_id, secret_id, name, revision, deleted)
+VALUES (?, ?, ?, ?, ?)`
+	_, err = e.ExecContext(ctx, query, userID, change.SecretID, change.Name, change.Revision, change.Deleted)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// revisionMismatch returns ErrSecretRevisionMismatch if the user has the secret and ErrSecretNotFound otherwise.
+func revisionMismatch(ctx context.Context, e sqlitestorage.Executor, secretID, userID uuid.UUID) error {
+	const op = "check secret revision"
+
+	var exists bool
+	const query = `SELECT EXISTS(SELECT 1 FROM secrets WHERE secret_id=? AND user_id=?)`
+	row := e.QueryRowContext(ctx, query, secretID, userID)
+	err := row.Scan(&exists)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if exists {
+		return vault.ErrSecretRevisionMismatch
+	}
+
+	return vault.ErrSecretNotFound
+}
+
+// nullBlobID stores no blob reference for secrets kept along with their data.
+func nullBlobID(secret *model.Secret) uuid.NullUUID {
+	return uuid.NullUUID{UUID: secret.BlobID, Valid: secret.HasBlob()}
+}
+
+// executor returns transaction of the context if any.
+func (r *secretRepository) executor(ctx context.Context) sqlitestorage.Executor {
+	return sqlitestorage.ExecutorFromContext(ctx, r.db)
+}
@@ -0,0 +1,99 @@
+package secret
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	modelAuth "github.com/nestjam/goph-keeper/internal/auth/model"
+	"github.com/nestjam/goph-keeper/internal/auth/repository/sqlite"
+	"github.com/nestjam/goph-keeper/internal/backup"
+	sqlitestorage "github.com/nestjam/goph-keeper/internal/storage/sqlite"
+	"github.com/nestjam/goph-keeper/internal/vault"
+	modelVault "github.com/nestjam/goph-keeper/internal/vault/model"
+	"github.com/nestjam/goph-keeper/internal/vault/repository/sqlite/key"
+	"github.com/nestjam/goph-keeper/migration"
+)
+
+func TestSecretRepository(t *testing.T) {
+	vault.SecretRepositoryContract{
+		NewSecretRepository: func() (vault.SecretRepository, func(), vault.SecretTestData) {
+			t.Helper()
+
+			path := filepath.Join(t.TempDir(), "goph-keeper.db")
+			migrator := migration.NewSQLiteMigrator(path)
+			err := migrator.Up()
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			db, err := sqlitestorage.Open(ctx, path)
+			require.NoError(t, err)
+			r := NewSecretRepository(db)
+
+			closer := func() {
+				_ = db.Close()
+			}
+
+			users := setupUsers(t, path)
+			testData := vault.SecretTestData{
+				Users: users,
+				Keys:  setupKeys(t, path, users[0]),
+			}
+			return r, closer, testData
+		},
+		NewRestoredSecretRepository: func(snapshot *backup.Snapshot) (vault.SecretRepository, func()) {
+			t.Helper()
+
+			path := filepath.Join(t.TempDir(), "goph-keeper.db")
+			migrator := migration.NewSQLiteMigrator(path)
+			err := migrator.Up()
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			db, err := sqlitestorage.Open(ctx, path)
+			require.NoError(t, err)
+			store, err := sqlitestorage.NewBackupStore(ctx, path)
+			require.NoError(t, err)
+			defer store.Close()
+			err = store.Import(ctx, snapshot)
+			require.NoError(t, err)
+
+			return NewSecretRepository(db), func() { _ = db.Close() }
+		},
+	}.Test(t)
+}
+
+func setupUsers(t *testing.T, path string) uuid.UUIDs {
+	t.Helper()
+
+	ctx := context.Background()
+	db, err := sqlitestorage.Open(ctx, path)
+	require.NoError(t, err)
+	r := sqlite.NewUserRepository(db)
+	defer func() { _ = db.Close() }()
+
+	userID, err := r.Register(ctx, &modelAuth.User{Email: "user@email.com", Password: "1"})
+	require.NoError(t, err)
+	user2ID, err := r.Register(ctx, &modelAuth.User{Email: "user2@email.com", Password: "2"})
+	require.NoError(t, err)
+
+	return uuid.UUIDs{userID, user2ID}
+}
+
+func setupKeys(t *testing.T, path string, userID uuid.UUID) uuid.UUIDs {
+	t.Helper()
+
+	ctx := context.Background()
+	db, err := sqlitestorage.Open(ctx, path)
+	require.NoError(t, err)
+	r := key.NewDataKeyRepository(db)
+	defer func() { _ = db.Close() }()
+
+	key, err := r.RotateKey(ctx, &modelVault.DataKey{}, userID)
+	require.NoError(t, err)
+
+	return uuid.UUIDs{key.ID}
+}
@@ -10,14 +10,21 @@ import (
 )
 
 var (
-	ErrSecretNotFound = errors.New("secret not found")
+	ErrSecretNotFound         = errors.New("secret not found")
+	ErrSecretRevisionMismatch = errors.New("secret revision mismatch")
 )
 
 //nolint:dupl // SecretRepository is not duplicate of VaultService
 type SecretRepository interface {
 	ListSecrets(ctx context.Context, userID uuid.UUID) ([]*model.Secret, error)
 	AddSecret(ctx context.Context, secret *model.Secret, userID uuid.UUID) (uuid.UUID, error)
+	// UpdateSecret updates the secret if its revision matches the stored one and increments the revision.
 	UpdateSecret(ctx context.Context, secret *model.Secret, userID uuid.UUID) error
 	GetSecret(ctx context.Context, secretID, userID uuid.UUID) (*model.Secret, error)
-	DeleteSecret(ctx context.Context, secretID, userID uuid.UUID) error
+	DeleteSecret(ctx context.Context, secretID, userID uuid.UUID, revision int64) error
+	// DeleteUserSecrets deletes secrets of the user along with their change log.
+	DeleteUserSecrets(ctx context.Context, userID uuid.UUID) error
+	// ListChanges returns the latest changes of the user secrets made after the change with sequence number since.
+	// Changes are ordered by sequence number. Deleted secrets are returned as tombstones.
+	ListChanges(ctx context.Context, userID uuid.UUID, since int64) ([]*model.Change, error)
 }
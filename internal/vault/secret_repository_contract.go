@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/nestjam/goph-keeper/internal/backup"
 	"github.com/nestjam/goph-keeper/internal/vault/model"
 )
 
@@ -18,9 +19,43 @@ type SecretTestData struct {
 
 type SecretRepositoryContract struct {
 	NewSecretRepository func() (SecretRepository, func(), SecretTestData)
+	// NewRestoredSecretRepository restores the snapshot into the empty storage and returns its repository.
+	// It is not set if the storage has no backups.
+	NewRestoredSecretRepository func(snapshot *backup.Snapshot) (SecretRepository, func())
 }
 
 func (c SecretRepositoryContract) Test(t *testing.T) {
+	t.Run("list changes of restored secrets", func(t *testing.T) {
+		if c.NewRestoredSecretRepository == nil {
+			t.Skip("storage has no backups")
+		}
+		ownerID := uuid.New()
+		userID := uuid.New()
+		keyID := uuid.New()
+		secretID := uuid.New()
+		snapshot := &backup.Snapshot{
+			Users: []backup.User{
+				{ID: ownerID, Email: "owner@email.com", Password: "1"},
+				{ID: userID, Email: "user@email.com", Password: "2"},
+			},
+			Keys: []backup.DataKey{{ID: keyID, UserID: &ownerID, Key: []byte("key")}},
+			Secrets: []backup.Secret{
+				{ID: secretID, UserID: ownerID, KeyID: keyID, Name: "secret", Data: []byte("data"), Revision: 3},
+				{ID: uuid.New(), UserID: userID, KeyID: keyID, Name: "another", Data: []byte("data"), Revision: 1},
+			},
+		}
+		sut, tearDown := c.NewRestoredSecretRepository(snapshot)
+		t.Cleanup(tearDown)
+
+		got, err := sut.ListChanges(context.Background(), ownerID, 0)
+
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, secretID, got[0].SecretID)
+		assert.Equal(t, "secret", got[0].Name)
+		assert.Equal(t, int64(3), got[0].Revision)
+		assert.False(t, got[0].Deleted)
+	})
 	t.Run("add secret", func(t *testing.T) {
 		sut, tearDown, td := c.NewSecretRepository()
 		t.Cleanup(tearDown)
@@ -35,6 +70,7 @@ func (c SecretRepositoryContract) Test(t *testing.T) {
 
 		require.NoError(t, err)
 		assert.NotEqual(t, uuid.Nil, got)
+		assert.Equal(t, model.FirstRevision, secret.Revision)
 	})
 
 	t.Run("update secret", func(t *testing.T) {
@@ -52,9 +88,80 @@ func (c SecretRepositoryContract) Test(t *testing.T) {
 		err = sut.UpdateSecret(ctx, secret, userID)
 
 		require.NoError(t, err)
+		assert.Equal(t, model.FirstRevision+1, secret.Revision)
 		got, _ := sut.GetSecret(ctx, secret.ID, userID)
 		assert.Equal(t, secret, got)
 	})
+	t.Run("add secret with data in blob store", func(t *testing.T) {
+		sut, tearDown, td := c.NewSecretRepository()
+		t.Cleanup(tearDown)
+		secret := &model.Secret{
+			Name:         "secret",
+			KeyID:        td.Keys[0],
+			BlobID:       uuid.New(),
+			BlobChecksum: []byte("checksum"),
+		}
+		userID := td.Users[0]
+		ctx := context.Background()
+		var err error
+		secret.ID, err = sut.AddSecret(ctx, secret, userID)
+		require.NoError(t, err)
+
+		got, err := sut.GetSecret(ctx, secret.ID, userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, secret.BlobID, got.BlobID)
+		assert.Equal(t, secret.BlobChecksum, got.BlobChecksum)
+		assert.Empty(t, got.Data)
+	})
+	t.Run("update secret moving data to blob store", func(t *testing.T) {
+		sut, tearDown, td := c.NewSecretRepository()
+		t.Cleanup(tearDown)
+		secret := &model.Secret{KeyID: td.Keys[0], Data: []byte("text")}
+		userID := td.Users[0]
+		ctx := context.Background()
+		var err error
+		secret.ID, err = sut.AddSecret(ctx, secret, userID)
+		require.NoError(t, err)
+		secret.Data = nil
+		secret.BlobID = uuid.New()
+		secret.BlobChecksum = []byte("checksum")
+
+		err = sut.UpdateSecret(ctx, secret, userID)
+
+		require.NoError(t, err)
+		got, err := sut.GetSecret(ctx, secret.ID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, secret.BlobID, got.BlobID)
+		assert.Equal(t, secret.BlobChecksum, got.BlobChecksum)
+		assert.Empty(t, got.Data)
+		list, err := sut.ListSecrets(ctx, userID)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, secret.BlobID, list[0].BlobID)
+	})
+	t.Run("update secret with stale revision", func(t *testing.T) {
+		sut, tearDown, td := c.NewSecretRepository()
+		t.Cleanup(tearDown)
+		userID := td.Users[0]
+		ctx := context.Background()
+		secret := &model.Secret{KeyID: td.Keys[0], Data: []byte("text")}
+		var err error
+		secret.ID, err = sut.AddSecret(ctx, secret, userID)
+		require.NoError(t, err)
+		stale := secret.Copy()
+		secret.Data = []byte("edited on one device")
+		err = sut.UpdateSecret(ctx, secret, userID)
+		require.NoError(t, err)
+		stale.Data = []byte("edited on another device")
+
+		err = sut.UpdateSecret(ctx, stale, userID)
+
+		require.ErrorIs(t, err, ErrSecretRevisionMismatch)
+		got, err := sut.GetSecret(ctx, secret.ID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, secret, got)
+	})
 	t.Run("update secret that does not exist", func(t *testing.T) {
 		sut, tearDown, _ := c.NewSecretRepository()
 		t.Cleanup(tearDown)
@@ -64,7 +171,27 @@ func (c SecretRepositoryContract) Test(t *testing.T) {
 
 		err := sut.UpdateSecret(ctx, secret, userID)
 
-		require.Error(t, err)
+		require.ErrorIs(t, err, ErrSecretNotFound)
+	})
+	t.Run("update secret of another user", func(t *testing.T) {
+		sut, tearDown, td := c.NewSecretRepository()
+		t.Cleanup(tearDown)
+		ownerID := td.Users[0]
+		anotherUserID := td.Users[1]
+		ctx := context.Background()
+		secret := &model.Secret{KeyID: td.Keys[0], Data: []byte("text")}
+		var err error
+		secret.ID, err = sut.AddSecret(ctx, secret, ownerID)
+		require.NoError(t, err)
+		want := secret.Copy()
+		secret.Data = []byte("overwritten text")
+
+		err = sut.UpdateSecret(ctx, secret, anotherUserID)
+
+		require.ErrorIs(t, err, ErrSecretNotFound)
+		got, err := sut.GetSecret(ctx, want.ID, ownerID)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
 	})
 
 	t.Run("list secrets", func(t *testing.T) {
@@ -94,8 +221,9 @@ func (c SecretRepositoryContract) Test(t *testing.T) {
 			require.NoError(t, err)
 			want := []*model.Secret{
 				{
-					ID:   secret.ID,
-					Name: secret.Name,
+					ID:       secret.ID,
+					Name:     secret.Name,
+					Revision: secret.Revision,
 				},
 			}
 
@@ -121,8 +249,8 @@ func (c SecretRepositoryContract) Test(t *testing.T) {
 			_, err = sut.AddSecret(ctx, s3, user2ID)
 			require.NoError(t, err)
 			want := []*model.Secret{
-				{ID: s1.ID},
-				{ID: s2.ID},
+				{ID: s1.ID, Revision: s1.Revision},
+				{ID: s2.ID, Revision: s2.Revision},
 			}
 
 			got, err := sut.ListSecrets(ctx, userID)
@@ -189,8 +317,29 @@ func (c SecretRepositoryContract) Test(t *testing.T) {
 				want.ID, err = sut.AddSecret(ctx, want, userID)
 				require.NoError(t, err)
 
-				err = sut.DeleteSecret(ctx, want.ID, userID)
+				err = sut.DeleteSecret(ctx, want.ID, userID, want.Revision)
+
+				require.NoError(t, err)
+				_, err = sut.GetSecret(ctx, want.ID, userID)
+				require.ErrorIs(t, err, ErrSecretNotFound)
+			})
+			t.Run("delete secret with stale revision", func(t *testing.T) {
+				sut, tearDown, td := c.NewSecretRepository()
+				t.Cleanup(tearDown)
+				userID := td.Users[0]
+				ctx := context.Background()
+				secret := &model.Secret{KeyID: td.Keys[0]}
+				var err error
+				secret.ID, err = sut.AddSecret(ctx, secret, userID)
+				require.NoError(t, err)
+				staleRevision := secret.Revision
+				err = sut.UpdateSecret(ctx, secret, userID)
+				require.NoError(t, err)
+
+				err = sut.DeleteSecret(ctx, secret.ID, userID, staleRevision)
 
+				require.ErrorIs(t, err, ErrSecretRevisionMismatch)
+				_, err = sut.GetSecret(ctx, secret.ID, userID)
 				require.NoError(t, err)
 			})
 			t.Run("user does not have the secret (on delete secret)", func(t *testing.T) {
@@ -203,9 +352,9 @@ func (c SecretRepositoryContract) Test(t *testing.T) {
 				require.NoError(t, err)
 				anotherSecretID := uuid.New()
 
-				err = sut.DeleteSecret(ctx, anotherSecretID, userID)
+				err = sut.DeleteSecret(ctx, anotherSecretID, userID, model.FirstRevision)
 
-				require.NoError(t, err)
+				require.ErrorIs(t, err, ErrSecretNotFound)
 			})
 			t.Run("user with id does not exist (on delete secret)", func(t *testing.T) {
 				sut, tearDown, _ := c.NewSecretRepository()
@@ -214,10 +363,144 @@ func (c SecretRepositoryContract) Test(t *testing.T) {
 				ctx := context.Background()
 				secretID := uuid.New()
 
-				err := sut.DeleteSecret(ctx, secretID, userID)
+				err := sut.DeleteSecret(ctx, secretID, userID, model.FirstRevision)
 
+				require.ErrorIs(t, err, ErrSecretNotFound)
+			})
+			t.Run("delete secret of another user", func(t *testing.T) {
+				sut, tearDown, td := c.NewSecretRepository()
+				t.Cleanup(tearDown)
+				ownerID := td.Users[0]
+				anotherUserID := td.Users[1]
+				ctx := context.Background()
+				secret := &model.Secret{KeyID: td.Keys[0]}
+				var err error
+				secret.ID, err = sut.AddSecret(ctx, secret, ownerID)
+				require.NoError(t, err)
+
+				err = sut.DeleteSecret(ctx, secret.ID, anotherUserID, secret.Revision)
+
+				require.ErrorIs(t, err, ErrSecretNotFound)
+				_, err = sut.GetSecret(ctx, secret.ID, ownerID)
 				require.NoError(t, err)
 			})
 		})
 	})
+	t.Run("delete user secrets", func(t *testing.T) {
+		sut, tearDown, td := c.NewSecretRepository()
+		t.Cleanup(tearDown)
+		userID := td.Users[0]
+		user2ID := td.Users[1]
+		ctx := context.Background()
+		s1 := &model.Secret{KeyID: td.Keys[0]}
+		var err error
+		s1.ID, err = sut.AddSecret(ctx, s1, userID)
+		require.NoError(t, err)
+		s2 := &model.Secret{KeyID: td.Keys[0]}
+		s2.ID, err = sut.AddSecret(ctx, s2, userID)
+		require.NoError(t, err)
+		s3 := &model.Secret{KeyID: td.Keys[0]}
+		s3.ID, err = sut.AddSecret(ctx, s3, user2ID)
+		require.NoError(t, err)
+
+		err = sut.DeleteUserSecrets(ctx, userID)
+
+		require.NoError(t, err)
+		got, err := sut.ListSecrets(ctx, userID)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+		_, err = sut.GetSecret(ctx, s1.ID, userID)
+		require.ErrorIs(t, err, ErrSecretNotFound)
+		_, err = sut.GetSecret(ctx, s3.ID, user2ID)
+		require.NoError(t, err)
+		changes, err := sut.ListChanges(ctx, userID, 0)
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+	t.Run("list changes", func(t *testing.T) {
+		t.Run("user has no changes", func(t *testing.T) {
+			sut, tearDown, td := c.NewSecretRepository()
+			t.Cleanup(tearDown)
+
+			got, err := sut.ListChanges(context.Background(), td.Users[0], 0)
+
+			require.NoError(t, err)
+			assert.Empty(t, got)
+		})
+		t.Run("latest changes of user secrets", func(t *testing.T) {
+			sut, tearDown, td := c.NewSecretRepository()
+			t.Cleanup(tearDown)
+			userID := td.Users[0]
+			ctx := context.Background()
+			updated := &model.Secret{KeyID: td.Keys[0], Name: "updated"}
+			var err error
+			updated.ID, err = sut.AddSecret(ctx, updated, userID)
+			require.NoError(t, err)
+			deleted := &model.Secret{KeyID: td.Keys[0], Name: "deleted"}
+			deleted.ID, err = sut.AddSecret(ctx, deleted, userID)
+			require.NoError(t, err)
+			_, err = sut.AddSecret(ctx, &model.Secret{KeyID: td.Keys[0]}, td.Users[1])
+			require.NoError(t, err)
+			updated.Name = "renamed"
+			err = sut.UpdateSecret(ctx, updated, userID)
+			require.NoError(t, err)
+			err = sut.DeleteSecret(ctx, deleted.ID, userID, deleted.Revision)
+			require.NoError(t, err)
+
+			got, err := sut.ListChanges(ctx, userID, 0)
+
+			require.NoError(t, err)
+			require.Len(t, got, 2)
+			assert.Equal(t, updated.ID, got[0].SecretID)
+			assert.Equal(t, "renamed", got[0].Name)
+			assert.Equal(t, model.FirstRevision+1, got[0].Revision)
+			assert.False(t, got[0].Deleted)
+			assert.Equal(t, deleted.ID, got[1].SecretID)
+			assert.True(t, got[1].Deleted)
+			assert.Less(t, got[0].Seq, got[1].Seq)
+		})
+		t.Run("changes after cursor", func(t *testing.T) {
+			sut, tearDown, td := c.NewSecretRepository()
+			t.Cleanup(tearDown)
+			userID := td.Users[0]
+			ctx := context.Background()
+			_, err := sut.AddSecret(ctx, &model.Secret{KeyID: td.Keys[0], Name: "seen"}, userID)
+			require.NoError(t, err)
+			seen, err := sut.ListChanges(ctx, userID, 0)
+			require.NoError(t, err)
+			require.Len(t, seen, 1)
+			secret := &model.Secret{KeyID: td.Keys[0], Name: "new"}
+			secret.ID, err = sut.AddSecret(ctx, secret, userID)
+			require.NoError(t, err)
+
+			got, err := sut.ListChanges(ctx, userID, seen[0].Seq)
+
+			require.NoError(t, err)
+			require.Len(t, got, 1)
+			assert.Equal(t, secret.ID, got[0].SecretID)
+			assert.Equal(t, model.FirstRevision, got[0].Revision)
+			assert.Greater(t, got[0].Seq, seen[0].Seq)
+		})
+		t.Run("failed update does not change secret", func(t *testing.T) {
+			sut, tearDown, td := c.NewSecretRepository()
+			t.Cleanup(tearDown)
+			userID := td.Users[0]
+			ctx := context.Background()
+			secret := &model.Secret{KeyID: td.Keys[0]}
+			var err error
+			secret.ID, err = sut.AddSecret(ctx, secret, userID)
+			require.NoError(t, err)
+			seen, err := sut.ListChanges(ctx, userID, 0)
+			require.NoError(t, err)
+			stale := secret.Copy()
+			stale.Revision = model.FirstRevision + 1
+			err = sut.UpdateSecret(ctx, stale, userID)
+			require.ErrorIs(t, err, ErrSecretRevisionMismatch)
+
+			got, err := sut.ListChanges(ctx, userID, seen[0].Seq)
+
+			require.NoError(t, err)
+			assert.Empty(t, got)
+		})
+	})
 }
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/vault"
+)
+
+type accessPolicyMock struct {
+	VaultAccessFunc func(ctx context.Context, vaultID, userID uuid.UUID) (vault.Access, error)
+}
+
+func (m *accessPolicyMock) VaultAccess(ctx context.Context, vaultID, userID uuid.UUID) (vault.Access, error) {
+	return m.VaultAccessFunc(ctx, vaultID, userID)
+}
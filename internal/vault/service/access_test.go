@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/storage/memory"
+	"github.com/nestjam/goph-keeper/internal/vault"
+	"github.com/nestjam/goph-keeper/internal/vault/model"
+	"github.com/nestjam/goph-keeper/internal/vault/repository/inmemory"
+)
+
+func TestVaultService_SharedVault(t *testing.T) {
+	t.Run("members share secrets of vault", func(t *testing.T) {
+		vaultID := uuid.New()
+		editorID := uuid.New()
+		viewerID := uuid.New()
+		sut := newSharedVaultService(t, map[uuid.UUID]vault.Access{
+			editorID: vault.AccessWrite,
+			viewerID: vault.AccessRead,
+		})
+		ctx := vault.ContextWithVault(context.Background(), vaultID)
+		secret := &model.Secret{Name: "server", Data: []byte("password")}
+		secretID, err := sut.AddSecret(ctx, secret, editorID)
+		require.NoError(t, err)
+
+		got, err := sut.GetSecret(ctx, secretID, viewerID)
+
+		require.NoError(t, err)
+		assert.Equal(t, secret.Data, got.Data)
+	})
+	t.Run("secrets of vault are not in personal vault", func(t *testing.T) {
+		userID := uuid.New()
+		sut := newSharedVaultService(t, map[uuid.UUID]vault.Access{userID: vault.AccessWrite})
+		ctx := vault.ContextWithVault(context.Background(), uuid.New())
+		_, err := sut.AddSecret(ctx, &model.Secret{Name: "server"}, userID)
+		require.NoError(t, err)
+
+		got, err := sut.ListSecrets(context.Background(), userID)
+
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+	t.Run("viewer can not change secrets", func(t *testing.T) {
+		vaultID := uuid.New()
+		editorID := uuid.New()
+		viewerID := uuid.New()
+		sut := newSharedVaultService(t, map[uuid.UUID]vault.Access{
+			editorID: vault.AccessWrite,
+			viewerID: vault.AccessRead,
+		})
+		ctx := vault.ContextWithVault(context.Background(), vaultID)
+		secret := &model.Secret{Name: "server"}
+		var err error
+		secret.ID, err = sut.AddSecret(ctx, secret, editorID)
+		require.NoError(t, err)
+
+		_, err = sut.AddSecret(ctx, &model.Secret{}, viewerID)
+		require.ErrorIs(t, err, vault.ErrAccessDenied)
+		err = sut.UpdateSecret(ctx, secret.Copy(), viewerID)
+		require.ErrorIs(t, err, vault.ErrAccessDenied)
+		err = sut.DeleteSecret(ctx, secret.ID, viewerID, secret.Revision)
+		require.ErrorIs(t, err, vault.ErrAccessDenied)
+	})
+	t.Run("members sync changes of vault", func(t *testing.T) {
+		vaultID := uuid.New()
+		editorID := uuid.New()
+		viewerID := uuid.New()
+		sut := newSharedVaultService(t, map[uuid.UUID]vault.Access{
+			editorID: vault.AccessWrite,
+			viewerID: vault.AccessRead,
+		})
+		ctx := vault.ContextWithVault(context.Background(), vaultID)
+		secretID, err := sut.AddSecret(ctx, &model.Secret{Name: "server"}, editorID)
+		require.NoError(t, err)
+
+		got, err := sut.ListChanges(ctx, viewerID, 0)
+
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, secretID, got[0].SecretID)
+	})
+	t.Run("members watch changes of vault", func(t *testing.T) {
+		vaultID := uuid.New()
+		editorID := uuid.New()
+		viewerID := uuid.New()
+		sut := newSharedVaultService(t, map[uuid.UUID]vault.Access{
+			editorID: vault.AccessWrite,
+			viewerID: vault.AccessRead,
+		})
+		ctx := vault.ContextWithVault(context.Background(), vaultID)
+		changes, cancel, err := sut.WatchChanges(ctx, viewerID)
+		require.NoError(t, err)
+		t.Cleanup(cancel)
+
+		secretID, err := sut.AddSecret(ctx, &model.Secret{Name: "server"}, editorID)
+
+		require.NoError(t, err)
+		got := <-changes
+		assert.Equal(t, secretID, got.SecretID)
+	})
+	t.Run("user who is not member can not watch changes", func(t *testing.T) {
+		sut := newSharedVaultService(t, map[uuid.UUID]vault.Access{})
+		ctx := vault.ContextWithVault(context.Background(), uuid.New())
+
+		_, _, err := sut.WatchChanges(ctx, uuid.New())
+
+		require.ErrorIs(t, err, vault.ErrVaultNotFound)
+	})
+	t.Run("access of member is verified", func(t *testing.T) {
+		viewerID := uuid.New()
+		sut := newSharedVaultService(t, map[uuid.UUID]vault.Access{viewerID: vault.AccessRead})
+		ctx := vault.ContextWithVault(context.Background(), uuid.New())
+
+		err := sut.VerifyAccess(ctx, viewerID)
+
+		require.NoError(t, err)
+	})
+	t.Run("access of user who left vault is not verified", func(t *testing.T) {
+		sut := newSharedVaultService(t, map[uuid.UUID]vault.Access{})
+		ctx := vault.ContextWithVault(context.Background(), uuid.New())
+
+		err := sut.VerifyAccess(ctx, uuid.New())
+
+		require.ErrorIs(t, err, vault.ErrVaultNotFound)
+	})
+	t.Run("user who is not member", func(t *testing.T) {
+		sut := newSharedVaultService(t, map[uuid.UUID]vault.Access{})
+		ctx := vault.ContextWithVault(context.Background(), uuid.New())
+
+		_, err := sut.ListSecrets(ctx, uuid.New())
+
+		require.ErrorIs(t, err, vault.ErrVaultNotFound)
+	})
+	t.Run("access policy is not set", func(t *testing.T) {
+		sut := NewVaultService(inmemory.NewSecretRepository(), inmemory.NewDataKeyRepository(),
+			memory.NewTransactor(), randomMasterKey(t))
+		ctx := vault.ContextWithVault(context.Background(), uuid.New())
+
+		_, err := sut.ListSecrets(ctx, uuid.New())
+
+		require.ErrorIs(t, err, vault.ErrVaultNotFound)
+	})
+}
+
+func newSharedVaultService(t *testing.T, members map[uuid.UUID]vault.Access) vault.VaultService {
+	t.Helper()
+
+	access := &accessPolicyMock{
+		VaultAccessFunc: func(_ context.Context, _, userID uuid.UUID) (vault.Access, error) {
+			a, ok := members[userID]
+			if !ok {
+				return vault.AccessNone, vault.ErrVaultNotFound
+			}
+			return a, nil
+		},
+	}
+	return NewVaultService(inmemory.NewSecretRepository(), inmemory.NewDataKeyRepository(), memory.NewTransactor(),
+		randomMasterKey(t), WithAccessPolicy(access))
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/nestjam/goph-keeper/internal/vault"
+	"github.com/nestjam/goph-keeper/internal/vault/model"
+)
+
+// putBlob moves sealed data of the threshold size or larger to the blob store,
+// leaving the blob reference and checksum in the secret instead.
+func (s *vaultService) putBlob(ctx context.Context, sealed *model.Secret) error {
+	const op = "put blob"
+
+	sealed.BlobID = uuid.Nil
+	sealed.BlobChecksum = nil
+	if s.blobs == nil || int64(len(sealed.Data)) < s.blobThreshold {
+		return nil
+	}
+
+	// every write gets a new blob, so that the blob of the stored secret is intact
+	// until the transaction is committed
+	blobID := uuid.New()
+	err := s.blobs.PutBlob(ctx, blobID, sealed.Data)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	checksum := sha256.Sum256(sealed.Data)
+	sealed.BlobID = blobID
+	sealed.BlobChecksum = checksum[:]
+	sealed.Data = nil
+	return nil
+}
+
+// getBlob returns the secret with sealed data loaded from the blob store, if it is kept there.
+func (s *vaultService) getBlob(ctx context.Context, secret *model.Secret) (*model.Secret, error) {
+	const op = "get blob"
+
+	if !secret.HasBlob() {
+		return secret, nil
+	}
+	if s.blobs == nil {
+		return nil, errors.Wrap(vault.ErrBlobStoreNotSet, op)
+	}
+
+	data, err := s.blobs.GetBlob(ctx, secret.BlobID)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	checksum := sha256.Sum256(data)
+	if !bytes.Equal(checksum[:], secret.BlobChecksum) {
+		return nil, errors.Wrap(vault.ErrBlobChecksumMismatch, op)
+	}
+
+	sealed := secret.Copy()
+	sealed.Data = data
+	sealed.BlobID = uuid.Nil
+	sealed.BlobChecksum = nil
+	return sealed, nil
+}
+
+// storedBlobID returns reference to the blob of the stored secret, if the service has blob store.
+func (s *vaultService) storedBlobID(ctx context.Context, secretID, userID uuid.UUID) (uuid.UUID, error) {
+	const op = "stored blob id"
+
+	if s.blobs == nil {
+		return uuid.Nil, nil
+	}
+
+	secret, err := s.secretRepo.GetSecret(ctx, secretID, userID)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+
+	return secret.BlobID, nil
+}
+
+// userBlobIDs returns references to the blobs of the user secrets, if the service has blob store.
+func (s *vaultService) userBlobIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	const op = "user blob ids"
+
+	if s.blobs == nil {
+		return nil, nil
+	}
+
+	secrets, err := s.secretRepo.ListSecrets(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	var blobIDs []uuid.UUID
+	for _, secret := range secrets {
+		if secret.HasBlob() {
+			blobIDs = append(blobIDs, secret.BlobID)
+		}
+	}
+
+	return blobIDs, nil
+}
+
+// deleteBlobs deletes blobs that are no longer referred by secrets. A blob left
+// after a failure is not referred by any secret, so the failure is only logged
+// to let the blob be deleted by hand.
+func (s *vaultService) deleteBlobs(ctx context.Context, blobIDs ...uuid.UUID) {
+	if s.blobs == nil {
+		return
+	}
+
+	for _, blobID := range blobIDs {
+		if blobID != uuid.Nil {
+			if err := s.blobs.DeleteBlob(ctx, blobID); err != nil {
+				log.Printf("delete blob %s: %v", blobID, err)
+			}
+		}
+	}
+}
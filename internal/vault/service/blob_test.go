@@ -0,0 +1,198 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nestjam/goph-keeper/internal/storage/memory"
+	"github.com/nestjam/goph-keeper/internal/utils"
+	"github.com/nestjam/goph-keeper/internal/vault"
+	"github.com/nestjam/goph-keeper/internal/vault/model"
+	"github.com/nestjam/goph-keeper/internal/vault/repository/blob/local"
+	"github.com/nestjam/goph-keeper/internal/vault/repository/inmemory"
+)
+
+const testBlobThreshold = 1024
+
+func TestVaultService_BlobStore(t *testing.T) {
+	t.Run("large secret data is kept in blob store", func(t *testing.T) {
+		ctx := context.Background()
+		secretRepo := inmemory.NewSecretRepository()
+		blobs := newBlobStore(t)
+		sut := newBlobVaultService(t, secretRepo, blobs)
+		userID := uuid.New()
+		want := randomData(t, 2*testBlobThreshold)
+		secret := &model.Secret{Name: "file", Data: want}
+
+		secretID, err := sut.AddSecret(ctx, secret, userID)
+
+		require.NoError(t, err)
+		stored, err := secretRepo.GetSecret(ctx, secretID, userID)
+		require.NoError(t, err)
+		assert.True(t, stored.HasBlob())
+		assert.Empty(t, stored.Data)
+		got, err := sut.GetSecret(ctx, secretID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, want, got.Data)
+		assert.False(t, got.HasBlob())
+	})
+	t.Run("small secret data is kept along with secret", func(t *testing.T) {
+		ctx := context.Background()
+		secretRepo := inmemory.NewSecretRepository()
+		sut := newBlobVaultService(t, secretRepo, newBlobStore(t))
+		userID := uuid.New()
+		secret := &model.Secret{Data: []byte("text")}
+
+		secretID, err := sut.AddSecret(ctx, secret, userID)
+
+		require.NoError(t, err)
+		stored, err := secretRepo.GetSecret(ctx, secretID, userID)
+		require.NoError(t, err)
+		assert.False(t, stored.HasBlob())
+		assert.NotEmpty(t, stored.Data)
+	})
+	t.Run("update replaces blob of secret", func(t *testing.T) {
+		ctx := context.Background()
+		secretRepo := inmemory.NewSecretRepository()
+		blobs := newBlobStore(t)
+		sut := newBlobVaultService(t, secretRepo, blobs)
+		userID := uuid.New()
+		secret := &model.Secret{Data: randomData(t, 2*testBlobThreshold)}
+		var err error
+		secret.ID, err = sut.AddSecret(ctx, secret, userID)
+		require.NoError(t, err)
+		stored, err := secretRepo.GetSecret(ctx, secret.ID, userID)
+		require.NoError(t, err)
+		oldBlobID := stored.BlobID
+		want := randomData(t, 3*testBlobThreshold)
+		secret.Data = want
+
+		err = sut.UpdateSecret(ctx, secret, userID)
+
+		require.NoError(t, err)
+		_, err = blobs.GetBlob(ctx, oldBlobID)
+		require.ErrorIs(t, err, vault.ErrBlobNotFound)
+		got, err := sut.GetSecret(ctx, secret.ID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, want, got.Data)
+	})
+	t.Run("update with stale revision keeps blob of secret", func(t *testing.T) {
+		ctx := context.Background()
+		secretRepo := inmemory.NewSecretRepository()
+		blobs := newBlobStore(t)
+		sut := newBlobVaultService(t, secretRepo, blobs)
+		userID := uuid.New()
+		want := randomData(t, 2*testBlobThreshold)
+		secret := &model.Secret{Data: want}
+		var err error
+		secret.ID, err = sut.AddSecret(ctx, secret, userID)
+		require.NoError(t, err)
+		stale := secret.Copy()
+		stale.Revision--
+		stale.Data = randomData(t, 2*testBlobThreshold)
+
+		err = sut.UpdateSecret(ctx, stale, userID)
+
+		require.ErrorIs(t, err, vault.ErrSecretRevisionMismatch)
+		got, err := sut.GetSecret(ctx, secret.ID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, want, got.Data)
+	})
+	t.Run("delete secret deletes its blob", func(t *testing.T) {
+		ctx := context.Background()
+		secretRepo := inmemory.NewSecretRepository()
+		blobs := newBlobStore(t)
+		sut := newBlobVaultService(t, secretRepo, blobs)
+		userID := uuid.New()
+		secret := &model.Secret{Data: randomData(t, 2*testBlobThreshold)}
+		var err error
+		secret.ID, err = sut.AddSecret(ctx, secret, userID)
+		require.NoError(t, err)
+		stored, err := secretRepo.GetSecret(ctx, secret.ID, userID)
+		require.NoError(t, err)
+
+		err = sut.DeleteSecret(ctx, secret.ID, userID, secret.Revision)
+
+		require.NoError(t, err)
+		_, err = blobs.GetBlob(ctx, stored.BlobID)
+		require.ErrorIs(t, err, vault.ErrBlobNotFound)
+	})
+	t.Run("delete user data deletes blobs", func(t *testing.T) {
+		ctx := context.Background()
+		secretRepo := inmemory.NewSecretRepository()
+		blobs := newBlobStore(t)
+		sut := newBlobVaultService(t, secretRepo, blobs)
+		userID := uuid.New()
+		secretID, err := sut.AddSecret(ctx, &model.Secret{Data: randomData(t, 2*testBlobThreshold)}, userID)
+		require.NoError(t, err)
+		stored, err := secretRepo.GetSecret(ctx, secretID, userID)
+		require.NoError(t, err)
+
+		cleanup, err := sut.DeleteUserData(ctx, userID)
+
+		require.NoError(t, err)
+		require.NoError(t, cleanup(ctx))
+		_, err = blobs.GetBlob(ctx, stored.BlobID)
+		require.ErrorIs(t, err, vault.ErrBlobNotFound)
+	})
+	t.Run("blob with checksum mismatch", func(t *testing.T) {
+		ctx := context.Background()
+		secretRepo := inmemory.NewSecretRepository()
+		blobs := newBlobStore(t)
+		sut := newBlobVaultService(t, secretRepo, blobs)
+		userID := uuid.New()
+		secretID, err := sut.AddSecret(ctx, &model.Secret{Data: randomData(t, 2*testBlobThreshold)}, userID)
+		require.NoError(t, err)
+		stored, err := secretRepo.GetSecret(ctx, secretID, userID)
+		require.NoError(t, err)
+		err = blobs.PutBlob(ctx, stored.BlobID, bytes.Repeat([]byte{1}, 2*testBlobThreshold))
+		require.NoError(t, err)
+
+		_, err = sut.GetSecret(ctx, secretID, userID)
+
+		require.ErrorIs(t, err, vault.ErrBlobChecksumMismatch)
+	})
+	t.Run("secret with blob when blob store is not set", func(t *testing.T) {
+		ctx := context.Background()
+		secretRepo := inmemory.NewSecretRepository()
+		userID := uuid.New()
+		secretID, err := newBlobVaultService(t, secretRepo, newBlobStore(t)).
+			AddSecret(ctx, &model.Secret{Data: randomData(t, 2*testBlobThreshold)}, userID)
+		require.NoError(t, err)
+		sut := NewVaultService(secretRepo, inmemory.NewDataKeyRepository(), memory.NewTransactor(),
+			randomMasterKey(t))
+
+		_, err = sut.GetSecret(ctx, secretID, userID)
+
+		require.ErrorIs(t, err, vault.ErrBlobStoreNotSet)
+	})
+}
+
+func newBlobStore(t *testing.T) vault.BlobStore {
+	t.Helper()
+
+	blobs, err := local.NewBlobStore(t.TempDir())
+	require.NoError(t, err)
+	return blobs
+}
+
+func newBlobVaultService(t *testing.T, secretRepo vault.SecretRepository, blobs vault.BlobStore) vault.VaultService {
+	t.Helper()
+
+	return NewVaultService(secretRepo, inmemory.NewDataKeyRepository(), memory.NewTransactor(), randomMasterKey(t),
+		WithBlobStore(blobs, testBlobThreshold))
+}
+
+// randomData returns data that does not shrink when it is sealed.
+func randomData(t *testing.T, size int) []byte {
+	t.Helper()
+
+	data, err := utils.GenerateRandom(size)
+	require.NoError(t, err)
+	return data
+}
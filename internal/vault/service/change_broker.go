@@ -0,0 +1,70 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/nestjam/goph-keeper/internal/vault"
+	"github.com/nestjam/goph-keeper/internal/vault/model"
+)
+
+// subscriptionBuffer is the number of changes kept for the subscriber that is busy.
+const subscriptionBuffer = 16
+
+type subscription chan *model.Change
+
+// changeBroker delivers changes to the subscribers of the same server.
+type changeBroker struct {
+	subscriptions map[uuid.UUID]map[subscription]struct{}
+	mu            sync.Mutex
+}
+
+// NewChangeBroker creates the broker of the single server. Subscribers of other instances of the server
+// are not notified of the changes published by it.
+func NewChangeBroker() vault.ChangeBroker {
+	return &changeBroker{
+		subscriptions: make(map[uuid.UUID]map[subscription]struct{}),
+	}
+}
+
+func (b *changeBroker) Publish(ownerID uuid.UUID, change *model.Change) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for s := range b.subscriptions[ownerID] {
+		select {
+		case s <- change:
+		default:
+			// the subscriber has changes it has not read yet, so it syncs this change along with them
+		}
+	}
+}
+
+func (b *changeBroker) Subscribe(ownerID uuid.UUID) (<-chan *model.Change, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := make(subscription, subscriptionBuffer)
+	if b.subscriptions[ownerID] == nil {
+		b.subscriptions[ownerID] = make(map[subscription]struct{})
+	}
+	b.subscriptions[ownerID][s] = struct{}{}
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() { b.unsubscribe(ownerID, s) })
+	}
+	return s, cancel
+}
+
+func (b *changeBroker) unsubscribe(ownerID uuid.UUID, s subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subscriptions[ownerID], s)
+	if len(b.subscriptions[ownerID]) == 0 {
+		delete(b.subscriptions, ownerID)
+	}
+	close(s)
+}
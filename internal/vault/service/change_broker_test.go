@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nestjam/goph-keeper/internal/vault/model"
+)
+
+func TestChangeBroker(t *testing.T) {
+	t.Run("subscriber receives change", func(t *testing.T) {
+		sut := NewChangeBroker()
+		ownerID := uuid.New()
+		changes, cancel := sut.Subscribe(ownerID)
+		t.Cleanup(cancel)
+		change := &model.Change{SecretID: uuid.New(), Revision: 2}
+
+		sut.Publish(ownerID, change)
+
+		assert.Equal(t, change, <-changes)
+	})
+	t.Run("change of another owner is not received", func(t *testing.T) {
+		sut := NewChangeBroker()
+		changes, cancel := sut.Subscribe(uuid.New())
+		t.Cleanup(cancel)
+
+		sut.Publish(uuid.New(), &model.Change{SecretID: uuid.New()})
+
+		assert.Empty(t, changes)
+	})
+	t.Run("busy subscriber does not block publisher", func(t *testing.T) {
+		sut := NewChangeBroker()
+		ownerID := uuid.New()
+		changes, cancel := sut.Subscribe(ownerID)
+		t.Cleanup(cancel)
+
+		for i := 0; i < subscriptionBuffer+1; i++ {
+			sut.Publish(ownerID, &model.Change{SecretID: uuid.New()})
+		}
+
+		assert.Len(t, changes, subscriptionBuffer)
+	})
+	t.Run("channel is closed when subscription is canceled", func(t *testing.T) {
+		sut := NewChangeBroker()
+		ownerID := uuid.New()
+		changes, cancel := sut.Subscribe(ownerID)
+
+		cancel()
+		cancel()
+
+		_, ok := <-changes
+		assert.False(t, ok)
+		sut.Publish(ownerID, &model.Change{SecretID: uuid.New()})
+	})
+}
@@ -9,18 +9,19 @@ import (
 )
 
 type keyRepositoryMock struct {
-	RotateKeyFunc   func(ctx context.Context, key *model.DataKey) (*model.DataKey, error)
-	GetKeyFunc      func(ctx context.Context) (*model.DataKey, error)
-	GetByIDFunc     func(ctx context.Context, id uuid.UUID) (*model.DataKey, error)
-	UpdateStatsFunc func(ctx context.Context, id uuid.UUID, dataSize int64) error
+	RotateKeyFunc      func(ctx context.Context, key *model.DataKey, userID uuid.UUID) (*model.DataKey, error)
+	GetKeyFunc         func(ctx context.Context, userID uuid.UUID) (*model.DataKey, error)
+	GetByIDFunc        func(ctx context.Context, id uuid.UUID) (*model.DataKey, error)
+	UpdateStatsFunc    func(ctx context.Context, id uuid.UUID, dataSize int64) error
+	DeleteUserKeysFunc func(ctx context.Context, userID uuid.UUID) error
 }
 
-func (m *keyRepositoryMock) RotateKey(ctx context.Context, key *model.DataKey) (*model.DataKey, error) {
-	return m.RotateKeyFunc(ctx, key)
+func (m *keyRepositoryMock) RotateKey(ctx context.Context, key *model.DataKey, u uuid.UUID) (*model.DataKey, error) {
+	return m.RotateKeyFunc(ctx, key, u)
 }
 
-func (m *keyRepositoryMock) GetKey(ctx context.Context) (*model.DataKey, error) {
-	return m.GetKeyFunc(ctx)
+func (m *keyRepositoryMock) GetKey(ctx context.Context, userID uuid.UUID) (*model.DataKey, error) {
+	return m.GetKeyFunc(ctx, userID)
 }
 
 func (m *keyRepositoryMock) GetByID(ctx context.Context, id uuid.UUID) (*model.DataKey, error) {
@@ -30,3 +31,7 @@ func (m *keyRepositoryMock) GetByID(ctx context.Context, id uuid.UUID) (*model.D
 func (m *keyRepositoryMock) UpdateStats(ctx context.Context, id uuid.UUID, dataSize int64) error {
 	return m.UpdateStatsFunc(ctx, id, dataSize)
 }
+
+func (m *keyRepositoryMock) DeleteUserKeys(ctx context.Context, userID uuid.UUID) error {
+	return m.DeleteUserKeysFunc(ctx, userID)
+}
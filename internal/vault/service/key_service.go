@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 
 	"github.com/nestjam/goph-keeper/internal/vault"
@@ -27,9 +28,10 @@ func NewKeyRotationConfig() KeyRotationConfig {
 }
 
 type keyService struct {
-	keyRepo vault.DataKeyRepository
-	cipher  *model.MasterKeyCipher
-	config  KeyRotationConfig
+	keyRepo   vault.DataKeyRepository
+	ownerKeys vault.OwnerKeyStore
+	cipher    *model.MasterKeyCipher
+	config    KeyRotationConfig
 }
 
 func NewKeyService(keyRepo vault.DataKeyRepository, config KeyRotationConfig, rootKey *model.MasterKey) *keyService {
@@ -40,26 +42,35 @@ func NewKeyService(keyRepo vault.DataKeyRepository, config KeyRotationConfig, ro
 	}
 }
 
-func (k *keyService) Seal(ctx context.Context, secret *model.Secret) (*model.Secret, error) {
+func (k *keyService) Seal(ctx context.Context, secret *model.Secret, userID uuid.UUID) (*model.Secret, error) {
 	const op = "seal"
 
-	key, err := k.keyRepo.GetKey(ctx)
+	keyCipher, err := k.ownerCipher(ctx, userID, true)
 	if err != nil {
 		return nil, errors.Wrap(err, op)
 	}
 
-	if key == nil ||
-		key.EncryptedDataSize >= k.config.EncryptedDataSizeThreshold ||
-		key.EncryptionsCount >= k.config.EncryptionsCountThreshold {
-		key, err = k.rotateKey(ctx)
+	key, err := k.keyRepo.GetKey(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	var legacy bool
+	if key != nil {
+		key, legacy, err = k.unsealKey(keyCipher, key)
 		if err != nil {
 			return nil, errors.Wrap(err, op)
 		}
 	}
 
-	key, err = k.cipher.Unseal(key)
-	if err != nil {
-		return nil, errors.Wrap(err, op)
+	// the key sealed by the master key is rotated, so that new secrets are shredded along with the owner key
+	if key == nil || legacy ||
+		key.EncryptedDataSize >= k.config.EncryptedDataSizeThreshold ||
+		key.EncryptionsCount >= k.config.EncryptionsCountThreshold {
+		key, err = k.rotateKey(ctx, keyCipher, userID)
+		if err != nil {
+			return nil, errors.Wrap(err, op)
+		}
 	}
 
 	cipher := model.NewDataKeyCipher(key)
@@ -68,7 +79,7 @@ func (k *keyService) Seal(ctx context.Context, secret *model.Secret) (*model.Sec
 		return nil, errors.Wrap(err, op)
 	}
 
-	dataSize := int64(len(secret.Data))
+	dataSize := model.EncryptedDataSize(sealed)
 	err = k.keyRepo.UpdateStats(ctx, key.ID, dataSize)
 	if err != nil {
 		return nil, errors.Wrap(err, op)
@@ -77,7 +88,10 @@ func (k *keyService) Seal(ctx context.Context, secret *model.Secret) (*model.Sec
 	return sealed, nil
 }
 
-func (k *keyService) rotateKey(ctx context.Context) (*model.DataKey, error) {
+// rotateKey returns unsealed new data key of the user.
+func (k *keyService) rotateKey(ctx context.Context,
+	keyCipher *model.MasterKeyCipher,
+	userID uuid.UUID) (*model.DataKey, error) {
 	const op = "rotate data key"
 
 	key, err := model.NewDataKey()
@@ -85,28 +99,35 @@ func (k *keyService) rotateKey(ctx context.Context) (*model.DataKey, error) {
 		return nil, errors.Wrap(err, op)
 	}
 
-	key, err = k.cipher.Seal(key)
+	sealed, err := keyCipher.Seal(key)
 	if err != nil {
 		return nil, errors.Wrap(err, op)
 	}
 
-	key, err = k.keyRepo.RotateKey(ctx, key)
+	sealed, err = k.keyRepo.RotateKey(ctx, sealed, userID)
 	if err != nil {
 		return nil, errors.Wrap(err, op)
 	}
 
-	return key, nil
+	unsealed := sealed.Copy()
+	unsealed.Key = key.Key
+	return unsealed, nil
 }
 
-func (k *keyService) Unseal(ctx context.Context, secret *model.Secret) (*model.Secret, error) {
+func (k *keyService) Unseal(ctx context.Context, secret *model.Secret, userID uuid.UUID) (*model.Secret, error) {
 	const op = "unseal"
 
+	keyCipher, err := k.ownerCipher(ctx, userID, false)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
 	key, err := k.keyRepo.GetByID(ctx, secret.KeyID)
 	if err != nil {
 		return nil, errors.Wrap(err, op)
 	}
 
-	key, err = k.cipher.Unseal(key)
+	key, _, err = k.unsealKey(keyCipher, key)
 	if err != nil {
 		return nil, errors.Wrap(err, op)
 	}
@@ -119,3 +140,104 @@ func (k *keyService) Unseal(ctx context.Context, secret *model.Secret) (*model.S
 
 	return unsealed, nil
 }
+
+// DeleteKeys destroys all data keys of the user. Secrets sealed by these keys
+// can not be unsealed afterwards.
+func (k *keyService) DeleteKeys(ctx context.Context, userID uuid.UUID) error {
+	const op = "delete keys"
+
+	err := k.keyRepo.DeleteUserKeys(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// DeleteOwnerKey destroys the key the data keys of the user are sealed by, so that the data keys
+// kept in backups of the storage can not be unsealed either.
+func (k *keyService) DeleteOwnerKey(ctx context.Context, userID uuid.UUID) error {
+	const op = "delete owner key"
+
+	if k.ownerKeys == nil {
+		return nil
+	}
+
+	err := k.ownerKeys.DeleteOwnerKey(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+// ownerCipher returns the cipher the data keys of the user are sealed by. It is the cipher of the master key
+// if the service does not keep owner keys, or the user has no key and create is not set.
+func (k *keyService) ownerCipher(ctx context.Context, userID uuid.UUID, create bool) (*model.MasterKeyCipher, error) {
+	const op = "owner cipher"
+
+	if k.ownerKeys == nil {
+		return k.cipher, nil
+	}
+
+	sealed, err := k.ownerKeys.GetOwnerKey(ctx, userID)
+	if errors.Is(err, vault.ErrOwnerKeyNotFound) && !create {
+		return k.cipher, nil
+	}
+	if errors.Is(err, vault.ErrOwnerKeyNotFound) {
+		sealed, err = k.addOwnerKey(ctx, userID)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	// the owner key is kept sealed by the master key, as data keys are
+	key, err := k.cipher.Unseal(&model.DataKey{Key: sealed})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return model.NewMasterKeyCipher(model.NewMasterKey(key.Key)), nil
+}
+
+func (k *keyService) addOwnerKey(ctx context.Context, userID uuid.UUID) ([]byte, error) {
+	const op = "add owner key"
+
+	key, err := model.NewDataKey()
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	key, err = k.cipher.Seal(key)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	sealed, err := k.ownerKeys.AddOwnerKey(ctx, userID, key.Key)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return sealed, nil
+}
+
+// unsealKey unseals the data key by the owner cipher. Keys created before the owners got their keys
+// are sealed by the master key, they are reported as legacy.
+func (k *keyService) unsealKey(keyCipher *model.MasterKeyCipher, key *model.DataKey) (*model.DataKey, bool, error) {
+	const op = "unseal key"
+
+	unsealed, err := keyCipher.Unseal(key)
+	if err == nil {
+		return unsealed, false, nil
+	}
+	if keyCipher == k.cipher {
+		return nil, false, errors.Wrap(err, op)
+	}
+
+	unsealed, err = k.cipher.Unseal(key)
+	if err != nil {
+		return nil, false, errors.Wrap(err, op)
+	}
+
+	return unsealed, true, nil
+}
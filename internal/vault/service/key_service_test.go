@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -18,15 +19,16 @@ import (
 func TestKeyService_Seal(t *testing.T) {
 	config := NewKeyRotationConfig()
 	rootKey := randomMasterKey(t)
+	userID := uuid.New()
 
 	t.Run("seal secret data", func(t *testing.T) {
 		ctx := context.Background()
 		keyRepo := inmemory.NewDataKeyRepository()
-		key := setKey(t, ctx, rootKey, keyRepo)
+		key := setKey(t, ctx, rootKey, keyRepo, userID)
 		sut := NewKeyService(keyRepo, config, rootKey)
 		secret := &model.Secret{Data: []byte("data")}
 
-		got, err := sut.Seal(ctx, secret)
+		got, err := sut.Seal(ctx, secret, userID)
 
 		require.NoError(t, err)
 		assert.Equal(t, secret.ID, got.ID)
@@ -38,41 +40,41 @@ func TestKeyService_Seal(t *testing.T) {
 		sut := NewKeyService(keyRepo, config, rootKey)
 		secret := &model.Secret{Data: []byte("data")}
 
-		got, err := sut.Seal(ctx, secret)
+		got, err := sut.Seal(ctx, secret, userID)
 
 		require.NoError(t, err)
 		assert.Equal(t, secret.ID, got.ID)
-		key, _ := keyRepo.GetKey(ctx)
+		key, _ := keyRepo.GetKey(ctx, userID)
 		assert.Equal(t, key.ID, got.KeyID)
 	})
 	t.Run("key rotation failed", func(t *testing.T) {
 		ctx := context.Background()
 		keyRepo := &keyRepositoryMock{
-			GetKeyFunc: func(ctx context.Context) (*model.DataKey, error) {
+			GetKeyFunc: func(ctx context.Context, userID uuid.UUID) (*model.DataKey, error) {
 				return nil, vault.ErrKeyNotFound
 			},
-			RotateKeyFunc: func(ctx context.Context, key *model.DataKey) (*model.DataKey, error) {
+			RotateKeyFunc: func(ctx context.Context, key *model.DataKey, userID uuid.UUID) (*model.DataKey, error) {
 				return nil, errors.New("failed")
 			},
 		}
 		sut := NewKeyService(keyRepo, config, rootKey)
 		secret := &model.Secret{Data: []byte("data")}
 
-		_, err := sut.Seal(ctx, secret)
+		_, err := sut.Seal(ctx, secret, userID)
 
 		require.Error(t, err)
 	})
 	t.Run("failed to get key", func(t *testing.T) {
 		ctx := context.Background()
 		keyRepo := &keyRepositoryMock{
-			GetKeyFunc: func(ctx context.Context) (*model.DataKey, error) {
+			GetKeyFunc: func(ctx context.Context, userID uuid.UUID) (*model.DataKey, error) {
 				return nil, errors.New("failed")
 			},
 		}
 		sut := NewKeyService(keyRepo, config, rootKey)
 		secret := &model.Secret{Data: []byte("data")}
 
-		_, err := sut.Seal(ctx, secret)
+		_, err := sut.Seal(ctx, secret, userID)
 
 		require.Error(t, err)
 	})
@@ -82,17 +84,17 @@ func TestKeyService_Seal(t *testing.T) {
 		config.EncryptedDataSizeThreshold = 5
 		sut := NewKeyService(keyRepo, config, rootKey)
 		secret := &model.Secret{Data: []byte("12345")} // 5 bytes
-		sealed, err := sut.Seal(ctx, secret)
+		sealed, err := sut.Seal(ctx, secret, userID)
 		require.NoError(t, err)
 		keyID := sealed.KeyID
 
 		secret = &model.Secret{Data: []byte("")} // 0 bytes
-		sealed, err = sut.Seal(ctx, secret)
+		sealed, err = sut.Seal(ctx, secret, userID)
 		require.NoError(t, err)
 		key2ID := sealed.KeyID
 
 		assert.NotEqual(t, keyID, key2ID)
-		key, _ := keyRepo.GetKey(ctx)
+		key, _ := keyRepo.GetKey(ctx, userID)
 		assert.Equal(t, key.ID, key2ID)
 	})
 	t.Run("rotate key after n encryptions are done", func(t *testing.T) {
@@ -101,26 +103,38 @@ func TestKeyService_Seal(t *testing.T) {
 		config.EncryptionsCountThreshold = 1
 		sut := NewKeyService(keyRepo, config, rootKey)
 		secret := &model.Secret{}
-		sealed, err := sut.Seal(ctx, secret)
+		sealed, err := sut.Seal(ctx, secret, userID)
 		require.NoError(t, err)
 		keyID := sealed.KeyID
 
 		secret = &model.Secret{}
-		sealed, err = sut.Seal(ctx, secret)
+		sealed, err = sut.Seal(ctx, secret, userID)
 		require.NoError(t, err)
 		key2ID := sealed.KeyID
 
 		assert.NotEqual(t, keyID, key2ID)
-		key, _ := keyRepo.GetKey(ctx)
+		key, _ := keyRepo.GetKey(ctx, userID)
 		assert.Equal(t, key.ID, key2ID)
 	})
+	t.Run("account compressed size of data", func(t *testing.T) {
+		ctx := context.Background()
+		keyRepo := inmemory.NewDataKeyRepository()
+		sut := NewKeyService(keyRepo, config, rootKey)
+		secret := &model.Secret{Data: []byte(strings.Repeat("text", 1024))}
+
+		_, err := sut.Seal(ctx, secret, userID)
+
+		require.NoError(t, err)
+		key, _ := keyRepo.GetKey(ctx, userID)
+		assert.Less(t, key.EncryptedDataSize, int64(len(secret.Data)))
+	})
 	t.Run("failed to update key stats", func(t *testing.T) {
 		ctx := context.Background()
 		keyRepo := &keyRepositoryMock{
-			GetKeyFunc: func(ctx context.Context) (*model.DataKey, error) {
+			GetKeyFunc: func(ctx context.Context, userID uuid.UUID) (*model.DataKey, error) {
 				return nil, vault.ErrKeyNotFound
 			},
-			RotateKeyFunc: func(ctx context.Context, key *model.DataKey) (*model.DataKey, error) {
+			RotateKeyFunc: func(ctx context.Context, key *model.DataKey, userID uuid.UUID) (*model.DataKey, error) {
 				return key, nil
 			},
 			UpdateStatsFunc: func(ctx context.Context, id uuid.UUID, dataSize int64) error {
@@ -130,7 +144,7 @@ func TestKeyService_Seal(t *testing.T) {
 		sut := NewKeyService(keyRepo, config, rootKey)
 		secret := &model.Secret{Data: []byte("data")}
 
-		_, err := sut.Seal(ctx, secret)
+		_, err := sut.Seal(ctx, secret, userID)
 
 		require.Error(t, err)
 	})
@@ -139,17 +153,18 @@ func TestKeyService_Seal(t *testing.T) {
 func TestKeyService_Unseal(t *testing.T) {
 	config := NewKeyRotationConfig()
 	rootKey := randomMasterKey(t)
+	userID := uuid.New()
 
 	t.Run("unseal secret data", func(t *testing.T) {
 		ctx := context.Background()
 		keyRepo := inmemory.NewDataKeyRepository()
-		_ = setKey(t, ctx, rootKey, keyRepo)
+		_ = setKey(t, ctx, rootKey, keyRepo, userID)
 		sut := NewKeyService(keyRepo, config, rootKey)
 		want := &model.Secret{Data: []byte("data")}
-		secret, err := sut.Seal(ctx, want)
+		secret, err := sut.Seal(ctx, want, userID)
 		require.NoError(t, err)
 
-		got, err := sut.Unseal(ctx, secret)
+		got, err := sut.Unseal(ctx, secret, userID)
 
 		require.NoError(t, err)
 		assert.Equal(t, want, got)
@@ -160,20 +175,80 @@ func TestKeyService_Unseal(t *testing.T) {
 		sut := NewKeyService(keyRepo, config, rootKey)
 		secret := &model.Secret{KeyID: uuid.New()}
 
-		_, err := sut.Unseal(ctx, secret)
+		_, err := sut.Unseal(ctx, secret, userID)
+
+		require.Error(t, err)
+	})
+}
+
+func TestKeyService_OwnerKeys(t *testing.T) {
+	config := NewKeyRotationConfig()
+	rootKey := randomMasterKey(t)
+
+	t.Run("data key is sealed by owner key", func(t *testing.T) {
+		ctx := context.Background()
+		keyRepo := inmemory.NewDataKeyRepository()
+		sut := NewKeyService(keyRepo, config, rootKey)
+		sut.ownerKeys = inmemory.NewOwnerKeyStore()
+		userID := uuid.New()
+		want := &model.Secret{Data: []byte("data")}
+		secret, err := sut.Seal(ctx, want, userID)
+		require.NoError(t, err)
+
+		got, err := sut.Unseal(ctx, secret, userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		key, err := keyRepo.GetByID(ctx, secret.KeyID)
+		require.NoError(t, err)
+		_, err = model.NewMasterKeyCipher(rootKey).Unseal(key)
+		require.Error(t, err)
+	})
+	t.Run("data key sealed by master key is rotated", func(t *testing.T) {
+		ctx := context.Background()
+		keyRepo := inmemory.NewDataKeyRepository()
+		userID := uuid.New()
+		legacy := NewKeyService(keyRepo, config, rootKey)
+		want := &model.Secret{Data: []byte("data")}
+		legacySecret, err := legacy.Seal(ctx, want, userID)
+		require.NoError(t, err)
+		sut := NewKeyService(keyRepo, config, rootKey)
+		sut.ownerKeys = inmemory.NewOwnerKeyStore()
+
+		secret, err := sut.Seal(ctx, &model.Secret{Data: []byte("data")}, userID)
 
+		require.NoError(t, err)
+		assert.NotEqual(t, legacySecret.KeyID, secret.KeyID)
+		got, err := sut.Unseal(ctx, legacySecret, userID)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+	t.Run("secret can not be unsealed after owner key is deleted", func(t *testing.T) {
+		ctx := context.Background()
+		keyRepo := inmemory.NewDataKeyRepository()
+		sut := NewKeyService(keyRepo, config, rootKey)
+		sut.ownerKeys = inmemory.NewOwnerKeyStore()
+		userID := uuid.New()
+		secret, err := sut.Seal(ctx, &model.Secret{Data: []byte("data")}, userID)
+		require.NoError(t, err)
+
+		err = sut.DeleteOwnerKey(ctx, userID)
+
+		require.NoError(t, err)
+		_, err = sut.Unseal(ctx, secret, userID)
 		require.Error(t, err)
 	})
 }
 
-func setKey(t *testing.T, ctx context.Context, k *model.MasterKey, r vault.DataKeyRepository) *model.DataKey {
+func setKey(t *testing.T, ctx context.Context, k *model.MasterKey, r vault.DataKeyRepository,
+	userID uuid.UUID) *model.DataKey {
 	t.Helper()
 
 	key, _ := model.NewDataKey()
 	cipher := model.NewMasterKeyCipher(k)
 	key, err := cipher.Seal(key)
 	require.NoError(t, err)
-	key, err = r.RotateKey(ctx, key)
+	key, err = r.RotateKey(ctx, key, userID)
 	require.NoError(t, err)
 
 	return key
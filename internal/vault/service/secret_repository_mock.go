@@ -9,11 +9,13 @@ import (
 )
 
 type secretRepositoryMock struct {
-	ListSecretsFunc  func(ctx context.Context, userID uuid.UUID) ([]*model.Secret, error)
-	AddSecretFunc    func(ctx context.Context, secret *model.Secret, userID uuid.UUID) (uuid.UUID, error)
-	UpdateSecretFunc func(ctx context.Context, secret *model.Secret, userID uuid.UUID) error
-	GetSecretFunc    func(ctx context.Context, secretID, userID uuid.UUID) (*model.Secret, error)
-	DeleteSecretFunc func(ctx context.Context, secretID, userID uuid.UUID) error
+	ListSecretsFunc       func(ctx context.Context, userID uuid.UUID) ([]*model.Secret, error)
+	AddSecretFunc         func(ctx context.Context, secret *model.Secret, userID uuid.UUID) (uuid.UUID, error)
+	UpdateSecretFunc      func(ctx context.Context, secret *model.Secret, userID uuid.UUID) error
+	GetSecretFunc         func(ctx context.Context, secretID, userID uuid.UUID) (*model.Secret, error)
+	DeleteSecretFunc      func(ctx context.Context, secretID, userID uuid.UUID, revision int64) error
+	DeleteUserSecretsFunc func(ctx context.Context, userID uuid.UUID) error
+	ListChangesFunc       func(ctx context.Context, userID uuid.UUID, since int64) ([]*model.Change, error)
 }
 
 func (m *secretRepositoryMock) ListSecrets(ctx context.Context, userID uuid.UUID) ([]*model.Secret, error) {
@@ -32,6 +34,14 @@ func (m *secretRepositoryMock) GetSecret(ctx context.Context, secretID, userID u
 	return m.GetSecretFunc(ctx, secretID, userID)
 }
 
-func (m *secretRepositoryMock) DeleteSecret(ctx context.Context, secretID, userID uuid.UUID) error {
-	return m.DeleteSecretFunc(ctx, secretID, userID)
+func (m *secretRepositoryMock) DeleteSecret(ctx context.Context, secretID, userID uuid.UUID, revision int64) error {
+	return m.DeleteSecretFunc(ctx, secretID, userID, revision)
+}
+
+func (m *secretRepositoryMock) DeleteUserSecrets(ctx context.Context, userID uuid.UUID) error {
+	return m.DeleteUserSecretsFunc(ctx, userID)
+}
+
+func (m *secretRepositoryMock) ListChanges(ctx context.Context, u uuid.UUID, since int64) ([]*model.Change, error) {
+	return m.ListChangesFunc(ctx, u, since)
 }
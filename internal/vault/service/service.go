@@ -11,23 +11,76 @@ import (
 )
 
 type vaultService struct {
-	secretRepo vault.SecretRepository
-	keyring    *keyService
+	secretRepo    vault.SecretRepository
+	transactor    vault.Transactor
+	blobs         vault.BlobStore
+	access        vault.AccessPolicy
+	changes       vault.ChangeBroker
+	keyring       *keyService
+	blobThreshold int64
+}
+
+type VaultServiceOption func(*vaultService)
+
+// WithBlobStore makes the service keep sealed data of the threshold size or larger in the blob store.
+func WithBlobStore(blobs vault.BlobStore, threshold int64) VaultServiceOption {
+	return func(s *vaultService) {
+		s.blobs = blobs
+		s.blobThreshold = threshold
+	}
+}
+
+// WithChangeBroker makes the service deliver changes of secrets by the broker.
+// The changes are delivered to the clients of the same server by default, so when several instances
+// of the server share the storage, clients of the other instances see the change on their next sync only.
+func WithChangeBroker(changes vault.ChangeBroker) VaultServiceOption {
+	return func(s *vaultService) {
+		s.changes = changes
+	}
+}
+
+// WithOwnerKeys makes the service seal data keys of every owner by the key of the owner kept in the store,
+// so that deleting the data of the user destroys the key and the data can not be recovered from backups.
+// Data keys are sealed by the master key only by default.
+func WithOwnerKeys(keys vault.OwnerKeyStore) VaultServiceOption {
+	return func(s *vaultService) {
+		s.keyring.ownerKeys = keys
+	}
+}
+
+// WithAccessPolicy makes the service serve requests to vaults shared with users by the policy.
+func WithAccessPolicy(access vault.AccessPolicy) VaultServiceOption {
+	return func(s *vaultService) {
+		s.access = access
+	}
 }
 
 func NewVaultService(secretRepo vault.SecretRepository,
 	keyRepo vault.DataKeyRepository,
-	rootKey *model.MasterKey) vault.VaultService {
-	return &vaultService{
+	transactor vault.Transactor,
+	rootKey *model.MasterKey,
+	opts ...VaultServiceOption) vault.VaultService {
+	s := &vaultService{
 		secretRepo: secretRepo,
 		keyring:    NewKeyService(keyRepo, NewKeyRotationConfig(), rootKey),
+		transactor: transactor,
+		changes:    NewChangeBroker(),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 func (s *vaultService) ListSecrets(ctx context.Context, userID uuid.UUID) ([]*model.Secret, error) {
 	const op = "list secrets"
 
-	secrets, err := s.secretRepo.ListSecrets(ctx, userID)
+	ownerID, err := s.owner(ctx, userID, vault.AccessRead)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	secrets, err := s.secretRepo.ListSecrets(ctx, ownerID)
 	if err != nil {
 		return nil, errors.Wrap(err, op)
 	}
@@ -35,47 +88,143 @@ func (s *vaultService) ListSecrets(ctx context.Context, userID uuid.UUID) ([]*mo
 	return secrets, nil
 }
 
+func (s *vaultService) ListChanges(ctx context.Context, userID uuid.UUID, since int64) ([]*model.Change, error) {
+	const op = "list changes"
+
+	ownerID, err := s.owner(ctx, userID, vault.AccessRead)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	changes, err := s.secretRepo.ListChanges(ctx, ownerID, since)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	return changes, nil
+}
+
+func (s *vaultService) VerifyAccess(ctx context.Context, userID uuid.UUID) error {
+	const op = "verify access"
+
+	_, err := s.owner(ctx, userID, vault.AccessRead)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	return nil
+}
+
+func (s *vaultService) WatchChanges(ctx context.Context, userID uuid.UUID) (<-chan *model.Change, func(), error) {
+	const op = "watch changes"
+
+	ownerID, err := s.owner(ctx, userID, vault.AccessRead)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, op)
+	}
+
+	changes, cancel := s.changes.Subscribe(ownerID)
+	return changes, cancel, nil
+}
+
 func (s *vaultService) AddSecret(ctx context.Context, secret *model.Secret, userID uuid.UUID) (uuid.UUID, error) {
 	const op = "add secret"
 
-	sealed, err := s.keyring.Seal(ctx, secret)
+	ownerID, err := s.owner(ctx, userID, vault.AccessWrite)
 	if err != nil {
 		return uuid.Nil, errors.Wrap(err, op)
 	}
 
-	id, err := s.secretRepo.AddSecret(ctx, sealed, userID)
+	var id, blobID uuid.UUID
+	var revision int64
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		sealed, err := s.keyring.Seal(ctx, secret, ownerID)
+		if err != nil {
+			return err
+		}
+
+		err = s.putBlob(ctx, sealed)
+		if err != nil {
+			return err
+		}
+		blobID = sealed.BlobID
+
+		id, err = s.secretRepo.AddSecret(ctx, sealed, ownerID)
+		revision = sealed.Revision
+		return err
+	})
 	if err != nil {
+		s.deleteBlobs(ctx, blobID)
 		return uuid.Nil, errors.Wrap(err, op)
 	}
 
+	secret.Revision = revision
+	s.changes.Publish(ownerID, &model.Change{SecretID: id, Name: secret.Name, Revision: revision})
 	return id, nil
 }
 
 func (s *vaultService) UpdateSecret(ctx context.Context, secret *model.Secret, userID uuid.UUID) error {
 	const op = "update secret"
 
-	sealed, err := s.keyring.Seal(ctx, secret)
+	ownerID, err := s.owner(ctx, userID, vault.AccessWrite)
 	if err != nil {
 		return errors.Wrap(err, op)
 	}
 
-	err = s.secretRepo.UpdateSecret(ctx, sealed, userID)
+	var revision int64
+	var blobID, oldBlobID uuid.UUID
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		oldBlobID, err = s.storedBlobID(ctx, secret.ID, ownerID)
+		if err != nil {
+			return err
+		}
+
+		sealed, err := s.keyring.Seal(ctx, secret, ownerID)
+		if err != nil {
+			return err
+		}
+
+		err = s.putBlob(ctx, sealed)
+		if err != nil {
+			return err
+		}
+		blobID = sealed.BlobID
+
+		err = s.secretRepo.UpdateSecret(ctx, sealed, ownerID)
+		revision = sealed.Revision
+		return err
+	})
 	if err != nil {
+		s.deleteBlobs(ctx, blobID)
 		return errors.Wrap(err, op)
 	}
 
+	s.deleteBlobs(ctx, oldBlobID)
+	secret.Revision = revision
+	s.changes.Publish(ownerID, &model.Change{SecretID: secret.ID, Name: secret.Name, Revision: revision})
 	return nil
 }
 
 func (s *vaultService) GetSecret(ctx context.Context, secretID, userID uuid.UUID) (*model.Secret, error) {
 	const op = "get secret"
 
-	secret, err := s.secretRepo.GetSecret(ctx, secretID, userID)
+	ownerID, err := s.owner(ctx, userID, vault.AccessRead)
 	if err != nil {
 		return nil, errors.Wrap(err, op)
 	}
 
-	unsealed, err := s.keyring.Unseal(ctx, secret)
+	secret, err := s.secretRepo.GetSecret(ctx, secretID, ownerID)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	secret, err = s.getBlob(ctx, secret)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	unsealed, err := s.keyring.Unseal(ctx, secret, ownerID)
 	if err != nil {
 		return nil, errors.Wrap(err, op)
 	}
@@ -83,13 +232,89 @@ func (s *vaultService) GetSecret(ctx context.Context, secretID, userID uuid.UUID
 	return unsealed, nil
 }
 
-func (s *vaultService) DeleteSecret(ctx context.Context, secretID, userID uuid.UUID) error {
+func (s *vaultService) DeleteSecret(ctx context.Context, secretID, userID uuid.UUID, revision int64) error {
 	const op = "delete secret"
 
-	err := s.secretRepo.DeleteSecret(ctx, secretID, userID)
+	ownerID, err := s.owner(ctx, userID, vault.AccessWrite)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+
+	var blobID uuid.UUID
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		blobID, err = s.storedBlobID(ctx, secretID, ownerID)
+		if err != nil {
+			return err
+		}
+
+		return s.secretRepo.DeleteSecret(ctx, secretID, ownerID, revision)
+	})
 	if err != nil {
 		return errors.Wrap(err, op)
 	}
 
+	s.deleteBlobs(ctx, blobID)
+	s.changes.Publish(ownerID, &model.Change{SecretID: secretID, Revision: revision, Deleted: true})
 	return nil
 }
+
+func (s *vaultService) DeleteUserData(ctx context.Context, userID uuid.UUID) (func(ctx context.Context) error, error) {
+	const op = "delete user data"
+
+	var blobIDs []uuid.UUID
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		blobIDs, err = s.userBlobIDs(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		// secrets refer to data keys, so they go first
+		err = s.secretRepo.DeleteUserSecrets(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		return s.keyring.DeleteKeys(ctx, userID)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+
+	// the owner key is destroyed after the commit, so that a failed deletion leaves the secrets readable
+	return func(ctx context.Context) error {
+		s.deleteBlobs(ctx, blobIDs...)
+
+		err := s.keyring.DeleteOwnerKey(ctx, userID)
+		if err != nil {
+			return errors.Wrap(err, op)
+		}
+
+		return nil
+	}, nil
+}
+
+// owner returns the vault the request is served by: the shared vault set in the context
+// if the user has the access to it, or personal vault of the user otherwise.
+func (s *vaultService) owner(ctx context.Context, userID uuid.UUID, want vault.Access) (uuid.UUID, error) {
+	const op = "owner"
+
+	vaultID, ok := vault.VaultFromContext(ctx)
+	if !ok {
+		return userID, nil
+	}
+	if s.access == nil {
+		return uuid.Nil, errors.Wrap(vault.ErrVaultNotFound, op)
+	}
+
+	access, err := s.access.VaultAccess(ctx, vaultID, userID)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, op)
+	}
+	if access < want {
+		return uuid.Nil, errors.Wrap(vault.ErrAccessDenied, op)
+	}
+
+	return vaultID, nil
+}
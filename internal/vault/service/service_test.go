@@ -3,16 +3,25 @@ package service
 import (
 	"context"
 	"errors"
+	"path/filepath"
 	"testing"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	modelAuth "github.com/nestjam/goph-keeper/internal/auth/model"
+	"github.com/nestjam/goph-keeper/internal/auth/repository/sqlite"
+	"github.com/nestjam/goph-keeper/internal/backup"
+	"github.com/nestjam/goph-keeper/internal/storage/memory"
+	sqlitestorage "github.com/nestjam/goph-keeper/internal/storage/sqlite"
 	"github.com/nestjam/goph-keeper/internal/utils"
 	"github.com/nestjam/goph-keeper/internal/vault"
 	"github.com/nestjam/goph-keeper/internal/vault/model"
 	"github.com/nestjam/goph-keeper/internal/vault/repository/inmemory"
+	"github.com/nestjam/goph-keeper/internal/vault/repository/sqlite/key"
+	"github.com/nestjam/goph-keeper/internal/vault/repository/sqlite/secret"
+	"github.com/nestjam/goph-keeper/migration"
 )
 
 func TestAddSecret(t *testing.T) {
@@ -21,13 +30,14 @@ func TestAddSecret(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
 		rootKey := randomMasterKey(t)
-		sut := NewVaultService(secretRepo, keyRepo, rootKey)
+		sut := NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		secret := &model.Secret{Data: []byte("text")}
 		userID := uuid.New()
 
 		got, err := sut.AddSecret(ctx, secret, userID)
 
 		require.NoError(t, err)
+		assert.Equal(t, model.FirstRevision, secret.Revision)
 		_, err = secretRepo.GetSecret(ctx, got, userID)
 		require.NoError(t, err)
 	})
@@ -36,12 +46,12 @@ func TestAddSecret(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		rootKey := randomMasterKey(t)
 		cipher := model.NewMasterKeyCipher(rootKey)
-		setInvalidDataKey(t, ctx, cipher, keyRepo)
+		userID := uuid.New()
+		setInvalidDataKey(t, ctx, cipher, keyRepo, userID)
 		secretRepo := inmemory.NewSecretRepository()
 
-		sut := NewVaultService(secretRepo, keyRepo, rootKey)
+		sut := NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		secret := &model.Secret{}
-		userID := uuid.New()
 
 		_, err := sut.AddSecret(ctx, secret, userID)
 
@@ -49,13 +59,98 @@ func TestAddSecret(t *testing.T) {
 	})
 }
 
+func TestAddSecret_Atomicity(t *testing.T) {
+	t.Run("key stats are kept when secret is not stored", func(t *testing.T) {
+		ctx := context.Background()
+		path := filepath.Join(t.TempDir(), "goph-keeper.db")
+		err := migration.NewSQLiteMigrator(path).Up()
+		require.NoError(t, err)
+		userID := registerSQLiteUser(t, path)
+		db, err := sqlitestorage.Open(ctx, path)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		keyRepo := key.NewDataKeyRepository(db)
+		transactor := sqlitestorage.NewTransactor(db)
+		secretRepo := &secretRepositoryMock{
+			AddSecretFunc: func(ctx context.Context, secret *model.Secret, userID uuid.UUID) (uuid.UUID, error) {
+				return uuid.Nil, errors.New("failed")
+			},
+		}
+		rootKey := randomMasterKey(t)
+		sut := NewVaultService(secretRepo, keyRepo, transactor, rootKey)
+		secret := &model.Secret{Data: []byte("text")}
+
+		_, err = sut.AddSecret(ctx, secret, userID)
+
+		require.Error(t, err)
+		got, err := keyRepo.GetKey(ctx, userID)
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+	t.Run("seal and store secret in one transaction", func(t *testing.T) {
+		type txKey struct{}
+		ctx := context.Background()
+		transactor := &transactorMock{
+			WithinTransactionFunc: func(ctx context.Context, fn func(ctx context.Context) error) error {
+				return fn(context.WithValue(ctx, txKey{}, true))
+			},
+		}
+		inTx := func(ctx context.Context) bool {
+			_, ok := ctx.Value(txKey{}).(bool)
+			return ok
+		}
+		keyRepo := &keyRepositoryMock{
+			GetKeyFunc: func(ctx context.Context, userID uuid.UUID) (*model.DataKey, error) {
+				assert.True(t, inTx(ctx))
+				return nil, nil
+			},
+			RotateKeyFunc: func(ctx context.Context, key *model.DataKey, userID uuid.UUID) (*model.DataKey, error) {
+				assert.True(t, inTx(ctx))
+				return key, nil
+			},
+			UpdateStatsFunc: func(ctx context.Context, id uuid.UUID, dataSize int64) error {
+				assert.True(t, inTx(ctx))
+				return nil
+			},
+		}
+		secretRepo := &secretRepositoryMock{
+			AddSecretFunc: func(ctx context.Context, secret *model.Secret, userID uuid.UUID) (uuid.UUID, error) {
+				assert.True(t, inTx(ctx))
+				return uuid.New(), nil
+			},
+		}
+		rootKey := randomMasterKey(t)
+		sut := NewVaultService(secretRepo, keyRepo, transactor, rootKey)
+		secret := &model.Secret{Data: []byte("text")}
+
+		_, err := sut.AddSecret(ctx, secret, uuid.New())
+
+		require.NoError(t, err)
+	})
+	t.Run("failed to start transaction", func(t *testing.T) {
+		ctx := context.Background()
+		transactor := &transactorMock{
+			WithinTransactionFunc: func(ctx context.Context, fn func(ctx context.Context) error) error {
+				return errors.New("failed")
+			},
+		}
+		rootKey := randomMasterKey(t)
+		sut := NewVaultService(&secretRepositoryMock{}, &keyRepositoryMock{}, transactor, rootKey)
+		secret := &model.Secret{Data: []byte("text")}
+
+		_, err := sut.AddSecret(ctx, secret, uuid.New())
+
+		require.Error(t, err)
+	})
+}
+
 func TestUpdateSecret(t *testing.T) {
 	t.Run("update secret", func(t *testing.T) {
 		ctx := context.Background()
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
 		rootKey := randomMasterKey(t)
-		sut := NewVaultService(secretRepo, keyRepo, rootKey)
+		sut := NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		userID := uuid.New()
 		secret := &model.Secret{}
 		var err error
@@ -66,21 +161,42 @@ func TestUpdateSecret(t *testing.T) {
 		err = sut.UpdateSecret(ctx, secret, userID)
 
 		require.NoError(t, err)
+		assert.Equal(t, model.FirstRevision+1, secret.Revision)
 		got, err := sut.GetSecret(ctx, secret.ID, userID)
 		require.NoError(t, err)
 		assert.Equal(t, secret, got)
 	})
+	t.Run("secret was updated by another client", func(t *testing.T) {
+		ctx := context.Background()
+		keyRepo := inmemory.NewDataKeyRepository()
+		secretRepo := inmemory.NewSecretRepository()
+		rootKey := randomMasterKey(t)
+		sut := NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
+		userID := uuid.New()
+		secret := &model.Secret{}
+		var err error
+		secret.ID, err = sut.AddSecret(ctx, secret, userID)
+		require.NoError(t, err)
+		stale := secret.Copy()
+		err = sut.UpdateSecret(ctx, secret, userID)
+		require.NoError(t, err)
+
+		err = sut.UpdateSecret(ctx, stale, userID)
+
+		require.ErrorIs(t, err, vault.ErrSecretRevisionMismatch)
+		assert.Equal(t, model.FirstRevision, stale.Revision)
+	})
 	t.Run("invalid data key", func(t *testing.T) {
 		ctx := context.Background()
 		keyRepo := inmemory.NewDataKeyRepository()
 		rootKey := randomMasterKey(t)
 		cipher := model.NewMasterKeyCipher(rootKey)
-		setInvalidDataKey(t, ctx, cipher, keyRepo)
+		userID := uuid.New()
+		setInvalidDataKey(t, ctx, cipher, keyRepo, userID)
 		secretRepo := inmemory.NewSecretRepository()
 
-		sut := NewVaultService(secretRepo, keyRepo, rootKey)
+		sut := NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		secret := &model.Secret{}
-		userID := uuid.New()
 		_, err := secretRepo.AddSecret(ctx, secret, userID)
 		require.NoError(t, err)
 
@@ -96,7 +212,7 @@ func TestGetSecret(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
 		rootKey := randomMasterKey(t)
-		sut := NewVaultService(secretRepo, keyRepo, rootKey)
+		sut := NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		userID := uuid.New()
 		want := &model.Secret{Data: []byte("text")}
 		var err error
@@ -113,7 +229,7 @@ func TestGetSecret(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
 		rootKey := randomMasterKey(t)
-		sut := NewVaultService(secretRepo, keyRepo, rootKey)
+		sut := NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		secret := &model.Secret{
 			Data:  []byte("text"),
 			KeyID: uuid.New(),
@@ -135,14 +251,14 @@ func TestDeleteSecret(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
 		rootKey := randomMasterKey(t)
-		sut := NewVaultService(secretRepo, keyRepo, rootKey)
+		sut := NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		userID := uuid.New()
 		secret := &model.Secret{}
 		var err error
 		secret.ID, err = secretRepo.AddSecret(ctx, secret, userID)
 		require.NoError(t, err)
 
-		err = sut.DeleteSecret(ctx, secret.ID, userID)
+		err = sut.DeleteSecret(ctx, secret.ID, userID, secret.Revision)
 
 		require.NoError(t, err)
 		_, err = secretRepo.GetSecret(ctx, secret.ID, userID)
@@ -152,16 +268,16 @@ func TestDeleteSecret(t *testing.T) {
 		ctx := context.Background()
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := &secretRepositoryMock{
-			DeleteSecretFunc: func(ctx context.Context, secretID, userID uuid.UUID) error {
+			DeleteSecretFunc: func(ctx context.Context, secretID, userID uuid.UUID, revision int64) error {
 				return errors.New("failed")
 			},
 		}
 		rootKey := randomMasterKey(t)
-		sut := NewVaultService(secretRepo, keyRepo, rootKey)
+		sut := NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		userID := uuid.New()
 		secretID := uuid.New()
 
-		err := sut.DeleteSecret(ctx, secretID, userID)
+		err := sut.DeleteSecret(ctx, secretID, userID, model.FirstRevision)
 
 		require.Error(t, err)
 	})
@@ -173,7 +289,7 @@ func TestListSecrets(t *testing.T) {
 		keyRepo := inmemory.NewDataKeyRepository()
 		secretRepo := inmemory.NewSecretRepository()
 		rootKey := randomMasterKey(t)
-		sut := NewVaultService(secretRepo, keyRepo, rootKey)
+		sut := NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		userID := uuid.New()
 		s := &model.Secret{}
 		s.ID, _ = secretRepo.AddSecret(ctx, s, userID)
@@ -202,7 +318,7 @@ func TestListSecrets(t *testing.T) {
 			},
 		}
 		rootKey := randomMasterKey(t)
-		sut := NewVaultService(secretRepo, keyRepo, rootKey)
+		sut := NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
 		userID := uuid.New()
 
 		_, err := sut.ListSecrets(ctx, userID)
@@ -211,7 +327,8 @@ func TestListSecrets(t *testing.T) {
 	})
 }
 
-func setInvalidDataKey(t *testing.T, ctx context.Context, cipher *model.MasterKeyCipher, r vault.DataKeyRepository) {
+func setInvalidDataKey(t *testing.T, ctx context.Context, cipher *model.MasterKeyCipher, r vault.DataKeyRepository,
+	userID uuid.UUID) {
 	t.Helper()
 
 	const keySize = 8 // should be 32
@@ -219,6 +336,258 @@ func setInvalidDataKey(t *testing.T, ctx context.Context, cipher *model.MasterKe
 	dataKey := &model.DataKey{Key: key}
 	dataKey, err := cipher.Seal(dataKey)
 	require.NoError(t, err)
-	_, err = r.RotateKey(ctx, dataKey)
+	_, err = r.RotateKey(ctx, dataKey, userID)
+	require.NoError(t, err)
+}
+
+func TestListChanges(t *testing.T) {
+	t.Run("changes of user secrets", func(t *testing.T) {
+		ctx := context.Background()
+		sut := NewVaultService(inmemory.NewSecretRepository(), inmemory.NewDataKeyRepository(),
+			memory.NewTransactor(), randomMasterKey(t))
+		userID := uuid.New()
+		secret := &model.Secret{Name: "secret", Data: []byte("data")}
+		var err error
+		secret.ID, err = sut.AddSecret(ctx, secret, userID)
+		require.NoError(t, err)
+		err = sut.DeleteSecret(ctx, secret.ID, userID, secret.Revision)
+		require.NoError(t, err)
+
+		got, err := sut.ListChanges(ctx, userID, 0)
+
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, secret.ID, got[0].SecretID)
+		assert.True(t, got[0].Deleted)
+	})
+	t.Run("failed to list changes", func(t *testing.T) {
+		secretRepo := &secretRepositoryMock{
+			ListChangesFunc: func(ctx context.Context, userID uuid.UUID, since int64) ([]*model.Change, error) {
+				return nil, errors.New("failed")
+			},
+		}
+		sut := NewVaultService(secretRepo, inmemory.NewDataKeyRepository(), memory.NewTransactor(), randomMasterKey(t))
+
+		_, err := sut.ListChanges(context.Background(), uuid.New(), 0)
+
+		require.Error(t, err)
+	})
+}
+
+func TestWatchChanges(t *testing.T) {
+	t.Run("changes of user secrets", func(t *testing.T) {
+		ctx := context.Background()
+		sut := NewVaultService(inmemory.NewSecretRepository(), inmemory.NewDataKeyRepository(),
+			memory.NewTransactor(), randomMasterKey(t))
+		userID := uuid.New()
+		changes, cancel, err := sut.WatchChanges(ctx, userID)
+		require.NoError(t, err)
+		t.Cleanup(cancel)
+
+		secret := &model.Secret{Name: "secret", Data: []byte("data")}
+		secret.ID, err = sut.AddSecret(ctx, secret, userID)
+		require.NoError(t, err)
+		secret.Name = "renamed"
+		err = sut.UpdateSecret(ctx, secret, userID)
+		require.NoError(t, err)
+		err = sut.DeleteSecret(ctx, secret.ID, userID, secret.Revision)
+		require.NoError(t, err)
+
+		added, updated, deleted := <-changes, <-changes, <-changes
+		assert.Equal(t, &model.Change{SecretID: secret.ID, Name: "secret", Revision: model.FirstRevision}, added)
+		assert.Equal(t, &model.Change{SecretID: secret.ID, Name: "renamed", Revision: secret.Revision}, updated)
+		assert.True(t, deleted.Deleted)
+	})
+	t.Run("changes of another user", func(t *testing.T) {
+		ctx := context.Background()
+		sut := NewVaultService(inmemory.NewSecretRepository(), inmemory.NewDataKeyRepository(),
+			memory.NewTransactor(), randomMasterKey(t))
+		changes, cancel, err := sut.WatchChanges(ctx, uuid.New())
+		require.NoError(t, err)
+		t.Cleanup(cancel)
+
+		_, err = sut.AddSecret(ctx, &model.Secret{Name: "secret"}, uuid.New())
+
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+	t.Run("failed change is not delivered", func(t *testing.T) {
+		ctx := context.Background()
+		secretRepo := &secretRepositoryMock{
+			DeleteSecretFunc: func(ctx context.Context, secretID, userID uuid.UUID, revision int64) error {
+				return errors.New("failed")
+			},
+		}
+		broker := NewChangeBroker()
+		sut := NewVaultService(secretRepo, inmemory.NewDataKeyRepository(), memory.NewTransactor(),
+			randomMasterKey(t), WithChangeBroker(broker))
+		userID := uuid.New()
+		changes, cancel := broker.Subscribe(userID)
+		t.Cleanup(cancel)
+
+		err := sut.DeleteSecret(ctx, uuid.New(), userID, model.FirstRevision)
+
+		require.Error(t, err)
+		assert.Empty(t, changes)
+	})
+}
+
+func TestDeleteUserData(t *testing.T) {
+	t.Run("sealed secrets can not be unsealed after user data is deleted", func(t *testing.T) {
+		ctx := context.Background()
+		keyRepo := inmemory.NewDataKeyRepository()
+		secretRepo := inmemory.NewSecretRepository()
+		rootKey := randomMasterKey(t)
+		sut := NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
+		userID := uuid.New()
+		secretID, err := sut.AddSecret(ctx, &model.Secret{Data: []byte("text")}, userID)
+		require.NoError(t, err)
+		sealed, err := secretRepo.GetSecret(ctx, secretID, userID) // e.g. restored from backup
+		require.NoError(t, err)
+
+		cleanup, err := sut.DeleteUserData(ctx, userID)
+
+		require.NoError(t, err)
+		require.NoError(t, cleanup(ctx))
+		_, err = secretRepo.GetSecret(ctx, secretID, userID)
+		assert.ErrorIs(t, err, vault.ErrSecretNotFound)
+		keyring := NewKeyService(keyRepo, NewKeyRotationConfig(), rootKey)
+		_, err = keyring.Unseal(ctx, sealed, userID)
+		assert.ErrorIs(t, err, vault.ErrKeyNotFound)
+	})
+	t.Run("secrets restored from backup taken before deletion can not be unsealed", func(t *testing.T) {
+		ctx := context.Background()
+		path := filepath.Join(t.TempDir(), "goph-keeper.db")
+		err := migration.NewSQLiteMigrator(path).Up()
+		require.NoError(t, err)
+		userID := registerSQLiteUser(t, path)
+		ownerKeys := inmemory.NewOwnerKeyStore()
+		rootKey := randomMasterKey(t)
+		sut := newSQLiteVaultService(t, path, rootKey, WithOwnerKeys(ownerKeys))
+		secretID, err := sut.AddSecret(ctx, &model.Secret{Data: []byte("text")}, userID)
+		require.NoError(t, err)
+		snapshot := exportSQLite(t, path)
+
+		cleanup, err := sut.DeleteUserData(ctx, userID)
+
+		require.NoError(t, err)
+		require.NoError(t, cleanup(ctx))
+		restoredPath := filepath.Join(t.TempDir(), "restored.db")
+		err = migration.NewSQLiteMigrator(restoredPath).Up()
+		require.NoError(t, err)
+		importSQLite(t, restoredPath, snapshot)
+		restored := newSQLiteVaultService(t, restoredPath, rootKey, WithOwnerKeys(ownerKeys))
+		_, err = restored.GetSecret(ctx, secretID, userID)
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, vault.ErrSecretNotFound)
+	})
+	t.Run("keep secrets of another user", func(t *testing.T) {
+		ctx := context.Background()
+		keyRepo := inmemory.NewDataKeyRepository()
+		secretRepo := inmemory.NewSecretRepository()
+		rootKey := randomMasterKey(t)
+		sut := NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
+		userID := uuid.New()
+		_, err := sut.AddSecret(ctx, &model.Secret{Data: []byte("text")}, userID)
+		require.NoError(t, err)
+		user2ID := uuid.New()
+		want := &model.Secret{Data: []byte("text2")}
+		want.ID, err = sut.AddSecret(ctx, want, user2ID)
+		require.NoError(t, err)
+
+		cleanup, err := sut.DeleteUserData(ctx, userID)
+
+		require.NoError(t, err)
+		require.NoError(t, cleanup(ctx))
+		got, err := sut.GetSecret(ctx, want.ID, user2ID)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+	t.Run("failed to delete secrets", func(t *testing.T) {
+		ctx := context.Background()
+		keyRepo := &keyRepositoryMock{
+			DeleteUserKeysFunc: func(ctx context.Context, userID uuid.UUID) error {
+				return nil
+			},
+		}
+		secretRepo := &secretRepositoryMock{
+			DeleteUserSecretsFunc: func(ctx context.Context, userID uuid.UUID) error {
+				return errors.New("failed")
+			},
+		}
+		rootKey := randomMasterKey(t)
+		sut := NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
+
+		_, err := sut.DeleteUserData(ctx, uuid.New())
+
+		require.Error(t, err)
+	})
+	t.Run("failed to delete keys", func(t *testing.T) {
+		ctx := context.Background()
+		keyRepo := &keyRepositoryMock{
+			DeleteUserKeysFunc: func(ctx context.Context, userID uuid.UUID) error {
+				return errors.New("failed")
+			},
+		}
+		secretRepo := inmemory.NewSecretRepository()
+		rootKey := randomMasterKey(t)
+		sut := NewVaultService(secretRepo, keyRepo, memory.NewTransactor(), rootKey)
+
+		_, err := sut.DeleteUserData(ctx, uuid.New())
+
+		require.Error(t, err)
+	})
+}
+
+func newSQLiteVaultService(t *testing.T, path string, rootKey *model.MasterKey,
+	opts ...VaultServiceOption) vault.VaultService {
+	t.Helper()
+
+	db, err := sqlitestorage.Open(context.Background(), path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	keyRepo := key.NewDataKeyRepository(db)
+	secretRepo := secret.NewSecretRepository(db)
+	transactor := sqlitestorage.NewTransactor(db)
+
+	return NewVaultService(secretRepo, keyRepo, transactor, rootKey, opts...)
+}
+
+func exportSQLite(t *testing.T, path string) *backup.Snapshot {
+	t.Helper()
+
+	store, err := sqlitestorage.NewBackupStore(context.Background(), path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	snapshot, err := store.Export(context.Background())
+	require.NoError(t, err)
+
+	return snapshot
+}
+
+func importSQLite(t *testing.T, path string, snapshot *backup.Snapshot) {
+	t.Helper()
+
+	store, err := sqlitestorage.NewBackupStore(context.Background(), path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	err = store.Import(context.Background(), snapshot)
+	require.NoError(t, err)
+}
+
+func registerSQLiteUser(t *testing.T, path string) uuid.UUID {
+	t.Helper()
+
+	ctx := context.Background()
+	db, err := sqlitestorage.Open(ctx, path)
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+	r := sqlite.NewUserRepository(db)
+
+	userID, err := r.Register(ctx, &modelAuth.User{Email: "user@email.com", Password: "1"})
 	require.NoError(t, err)
+
+	return userID
 }
@@ -0,0 +1,11 @@
+package service
+
+import "context"
+
+type transactorMock struct {
+	WithinTransactionFunc func(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+func (m *transactorMock) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.WithinTransactionFunc(ctx, fn)
+}
@@ -0,0 +1,9 @@
+package vault
+
+import "context"
+
+// Transactor runs a function in a transaction shared by repositories.
+// Repositories take the transaction from the context passed to the function.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -12,7 +12,19 @@ import (
 type VaultService interface {
 	ListSecrets(ctx context.Context, userID uuid.UUID) ([]*model.Secret, error)
 	AddSecret(ctx context.Context, secret *model.Secret, userID uuid.UUID) (uuid.UUID, error)
+	// UpdateSecret updates the secret if its revision matches the stored one and increments the revision.
 	UpdateSecret(ctx context.Context, secret *model.Secret, userID uuid.UUID) error
 	GetSecret(ctx context.Context, secretID, userID uuid.UUID) (*model.Secret, error)
-	DeleteSecret(ctx context.Context, secretID, userID uuid.UUID) error
+	DeleteSecret(ctx context.Context, secretID, userID uuid.UUID, revision int64) error
+	// DeleteUserData deletes the secrets and the data keys of the user within the transaction of the context.
+	// The returned function destroys the blobs and the owner key once the transaction is committed.
+	DeleteUserData(ctx context.Context, userID uuid.UUID) (func(ctx context.Context) error, error)
+	// ListChanges returns changes of the secrets made after the change with sequence number since.
+	ListChanges(ctx context.Context, userID uuid.UUID, since int64) ([]*model.Change, error)
+	// VerifyAccess fails with ErrVaultNotFound or ErrAccessDenied if the user can not read the secrets any more,
+	// e.g. the user has left the shared vault.
+	VerifyAccess(ctx context.Context, userID uuid.UUID) error
+	// WatchChanges subscribes the user to changes of the secrets made from now on.
+	// The changes are delivered until the returned function is called.
+	WatchChanges(ctx context.Context, userID uuid.UUID) (<-chan *model.Change, func(), error)
 }
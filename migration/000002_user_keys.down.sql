@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS keys_user_id_idx;
+
+ALTER TABLE keys DROP COLUMN IF EXISTS user_id;
+
+END;
\ No newline at end of file
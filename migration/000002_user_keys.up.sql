@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE keys ADD COLUMN user_id UUID REFERENCES users (user_id);
+
+CREATE INDEX keys_user_id_idx ON keys (user_id);
+
+END;
\ No newline at end of file
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE secrets ADD COLUMN revision BIGINT NOT NULL DEFAULT 1;
+
+END;
\ No newline at end of file
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE secrets DROP COLUMN IF EXISTS blob_id, DROP COLUMN IF EXISTS blob_checksum;
+
+END;
\ No newline at end of file
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE secrets ADD COLUMN blob_id UUID, ADD COLUMN blob_checksum BYTEA;
+
+END;
\ No newline at end of file
@@ -0,0 +1,30 @@
+BEGIN;
+
+CREATE TABLE audit_events(
+    seq             BIGINT PRIMARY KEY,
+    user_id         UUID,
+    action          TEXT            NOT NULL,
+    secret_id       UUID,
+    created_at      TIMESTAMPTZ     NOT NULL,
+    client_ip       TEXT            NOT NULL DEFAULT '',
+    user_agent      TEXT            NOT NULL DEFAULT '',
+    outcome         TEXT            NOT NULL,
+    prev_hash       BYTEA,
+    hash            BYTEA           NOT NULL
+);
+
+CREATE INDEX audit_events_user_id_idx ON audit_events (user_id, seq);
+
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_no_change BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TRIGGER audit_events_no_truncate BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
+
+END;
\ No newline at end of file
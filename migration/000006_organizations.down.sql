@@ -0,0 +1,12 @@
+BEGIN;
+
+DELETE FROM secrets WHERE user_id NOT IN (SELECT user_id FROM users);
+DELETE FROM keys WHERE user_id IS NOT NULL AND user_id NOT IN (SELECT user_id FROM users);
+ALTER TABLE secrets ADD CONSTRAINT secrets_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (user_id);
+ALTER TABLE keys ADD CONSTRAINT keys_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (user_id);
+
+DROP TABLE IF EXISTS vaults;
+DROP TABLE IF EXISTS org_members;
+DROP TABLE IF EXISTS organizations;
+
+END;
\ No newline at end of file
@@ -0,0 +1,29 @@
+BEGIN;
+
+CREATE TABLE organizations(
+    org_id          UUID PRIMARY KEY        DEFAULT gen_random_uuid(),
+    name            TEXT                    NOT NULL CHECK ( name <> '' )
+);
+
+CREATE TABLE org_members(
+    org_id          UUID                    NOT NULL REFERENCES organizations (org_id) ON DELETE CASCADE,
+    user_id         UUID                    NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    role            TEXT                    NOT NULL CHECK ( role IN ('owner', 'editor', 'viewer') ),
+    PRIMARY KEY (org_id, user_id)
+);
+
+CREATE INDEX org_members_user_id_idx ON org_members (user_id);
+
+CREATE TABLE vaults(
+    vault_id        UUID PRIMARY KEY        DEFAULT gen_random_uuid(),
+    org_id          UUID                    NOT NULL REFERENCES organizations (org_id),
+    name            TEXT                    NOT NULL CHECK ( name <> '' )
+);
+
+CREATE INDEX vaults_org_id_idx ON vaults (org_id);
+
+-- secrets and keys are owned either by a user or by a team vault
+ALTER TABLE secrets DROP CONSTRAINT secrets_user_id_fkey;
+ALTER TABLE keys DROP CONSTRAINT keys_user_id_fkey;
+
+END;
\ No newline at end of file
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS secret_changes;
+
+END;
\ No newline at end of file
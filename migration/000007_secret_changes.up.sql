@@ -0,0 +1,18 @@
+BEGIN;
+
+CREATE TABLE secret_changes(
+    seq             BIGSERIAL PRIMARY KEY,
+    user_id         UUID                    NOT NULL,
+    secret_id       UUID                    NOT NULL UNIQUE,
+    name            TEXT,
+    revision        BIGINT                  NOT NULL,
+    deleted         BOOLEAN                 NOT NULL DEFAULT false
+);
+
+CREATE INDEX secret_changes_user_id_seq_idx ON secret_changes (user_id, seq);
+
+-- existing secrets are changes clients have not seen yet
+INSERT INTO secret_changes (user_id, secret_id, name, revision)
+SELECT user_id, secret_id, name, revision FROM secrets ORDER BY secret_id;
+
+END;
\ No newline at end of file
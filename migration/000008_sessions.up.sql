@@ -0,0 +1,22 @@
+BEGIN;
+
+CREATE TABLE sessions(
+    session_id      UUID PRIMARY KEY        DEFAULT gen_random_uuid(),
+    user_id         UUID                    NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    created_at      TIMESTAMPTZ             NOT NULL DEFAULT now(),
+    revoked         BOOLEAN                 NOT NULL DEFAULT false
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions (user_id);
+
+-- only hashes of refresh tokens are stored, a used token is kept to detect its reuse
+CREATE TABLE refresh_tokens(
+    token_hash      BYTEA PRIMARY KEY,
+    session_id      UUID                    NOT NULL REFERENCES sessions (session_id) ON DELETE CASCADE,
+    expires_at      TIMESTAMPTZ             NOT NULL,
+    used            BOOLEAN                 NOT NULL DEFAULT false
+);
+
+CREATE INDEX refresh_tokens_session_id_idx ON refresh_tokens (session_id);
+
+END;
\ No newline at end of file
@@ -0,0 +1,17 @@
+BEGIN;
+
+-- the secret of the second factor is kept to check the codes of the authenticator app
+CREATE TABLE two_factor(
+    user_id         UUID PRIMARY KEY        REFERENCES users (user_id) ON DELETE CASCADE,
+    secret          TEXT                    NOT NULL,
+    enabled         BOOLEAN                 NOT NULL DEFAULT false
+);
+
+-- only hashes of recovery codes are stored, a used code is deleted
+CREATE TABLE recovery_codes(
+    user_id         UUID                    NOT NULL REFERENCES two_factor (user_id) ON DELETE CASCADE,
+    code_hash       BYTEA                   NOT NULL,
+    PRIMARY KEY (user_id, code_hash)
+);
+
+END;
\ No newline at end of file
@@ -0,0 +1,17 @@
+BEGIN;
+
+-- personal access tokens of automation, only hashes of the tokens are stored
+CREATE TABLE access_tokens(
+    token_id        UUID PRIMARY KEY,
+    user_id         UUID                    NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    name            TEXT                    NOT NULL CHECK ( name <> '' ),
+    token_hash      BYTEA                   NOT NULL UNIQUE,
+    scope           TEXT                    NOT NULL,
+    vault_id        UUID,
+    created_at      TIMESTAMPTZ             NOT NULL,
+    expires_at      TIMESTAMPTZ
+);
+
+CREATE INDEX access_tokens_user_id_idx ON access_tokens (user_id);
+
+END;
\ No newline at end of file
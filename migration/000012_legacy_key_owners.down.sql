@@ -0,0 +1,5 @@
+BEGIN;
+
+-- the legacy keys are not restored, the copies owned by the users keep sealing their secrets
+
+END;
\ No newline at end of file
@@ -0,0 +1,21 @@
+BEGIN;
+
+-- keys created before keys had owners sealed secrets of several users. Each user gets its own copy of such a key,
+-- so deleting the user deletes its copy along with its secrets. The copies are disposed, new secrets get new keys.
+CREATE TEMPORARY TABLE legacy_key_owners ON COMMIT DROP AS
+SELECT key_id, user_id, gen_random_uuid() AS owned_key_id
+FROM (SELECT DISTINCT s.key_id, s.user_id
+      FROM secrets s JOIN keys k ON k.key_id = s.key_id
+      WHERE k.user_id IS NULL) AS legacy;
+
+INSERT INTO keys (key_id, user_id, key_data, encriptions_count, encrypted_data_size, is_disposed)
+SELECT o.owned_key_id, o.user_id, k.key_data, k.encriptions_count, k.encrypted_data_size, TRUE
+FROM legacy_key_owners o JOIN keys k ON k.key_id = o.key_id;
+
+UPDATE secrets s SET key_id = o.owned_key_id
+FROM legacy_key_owners o
+WHERE s.key_id = o.key_id AND s.user_id = o.user_id;
+
+DELETE FROM keys WHERE user_id IS NULL;
+
+END;
\ No newline at end of file
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE two_factor DROP COLUMN IF EXISTS last_time_step;
+
+END;
\ No newline at end of file
@@ -0,0 +1,6 @@
+BEGIN;
+
+-- the time step of the last accepted code, the codes of this and earlier steps are not accepted again
+ALTER TABLE two_factor ADD COLUMN last_time_step BIGINT NOT NULL DEFAULT 0;
+
+END;
\ No newline at end of file
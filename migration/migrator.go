@@ -7,27 +7,47 @@ import (
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
 )
 
-//go:embed *.sql
+//go:embed *.sql sqlite/*.sql
 var migrationsDir embed.FS
 
+const (
+	postgresMigrationsPath = "."
+	sqliteMigrationsPath   = "sqlite"
+	sqliteScheme           = "sqlite://"
+)
+
 type DatabaseMigrator struct {
-	connString string
+	connString     string
+	migrationsPath string
 }
 
 func NewDatabaseMigrator(connString string) *DatabaseMigrator {
-	return &DatabaseMigrator{connString: connString}
+	return &DatabaseMigrator{
+		connString:     connString,
+		migrationsPath: postgresMigrationsPath,
+	}
+}
+
+// NewSQLiteMigrator returns migrator of SQLite database stored in the file.
+func NewSQLiteMigrator(path string) *DatabaseMigrator {
+	return &DatabaseMigrator{
+		connString:     sqliteScheme + path,
+		migrationsPath: sqliteMigrationsPath,
+	}
 }
 
 func (p *DatabaseMigrator) Up() error {
 	const op = "migrate up"
 
-	m, err := createMigrate(p.connString)
+	m, err := createMigrate(p.connString, p.migrationsPath)
 	if err != nil {
 		return errors.Wrapf(err, op)
 	}
+	defer closeMigrate(m)
 
 	if err := m.Up(); err != nil {
 		if !errors.Is(err, migrate.ErrNoChange) {
@@ -38,11 +58,72 @@ func (p *DatabaseMigrator) Up() error {
 	return nil
 }
 
-func createMigrate(connString string) (*migrate.Migrate, error) {
-	const (
-		op             = "create migrate"
-		migrationsPath = "."
-	)
+// Down rolls back the given number of applied migrations.
+func (p *DatabaseMigrator) Down(steps int) error {
+	const op = "migrate down"
+
+	m, err := createMigrate(p.connString, p.migrationsPath)
+	if err != nil {
+		return errors.Wrapf(err, op)
+	}
+	defer closeMigrate(m)
+
+	if err := m.Steps(-steps); err != nil {
+		if !errors.Is(err, migrate.ErrNoChange) {
+			return errors.Wrapf(err, op)
+		}
+	}
+
+	return nil
+}
+
+// Version returns the version of the last applied migration. Zero version
+// means that no migrations are applied. Dirty database has a migration that
+// failed and has to be fixed and forced manually.
+func (p *DatabaseMigrator) Version() (version uint, dirty bool, err error) {
+	const op = "migration version"
+
+	m, err := createMigrate(p.connString, p.migrationsPath)
+	if err != nil {
+		return 0, false, errors.Wrapf(err, op)
+	}
+	defer closeMigrate(m)
+
+	version, dirty, err = m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, errors.Wrapf(err, op)
+	}
+
+	return version, dirty, nil
+}
+
+// Force sets the version without running migrations and clears the dirty state.
+func (p *DatabaseMigrator) Force(version int) error {
+	const op = "force version"
+
+	m, err := createMigrate(p.connString, p.migrationsPath)
+	if err != nil {
+		return errors.Wrapf(err, op)
+	}
+	defer closeMigrate(m)
+
+	if err := m.Force(version); err != nil {
+		return errors.Wrapf(err, op)
+	}
+
+	return nil
+}
+
+func closeMigrate(m *migrate.Migrate) {
+	_, _ = m.Close()
+}
+
+func createMigrate(connString, migrationsPath string) (*migrate.Migrate, error) {
+	const op = "create migrate"
+
 	d, err := iofs.New(migrationsDir, migrationsPath)
 	if err != nil {
 		return nil, errors.Wrapf(err, op)
@@ -58,10 +139,11 @@ func createMigrate(connString string) (*migrate.Migrate, error) {
 
 func (p *DatabaseMigrator) Drop() error {
 	const op = "drop"
-	m, err := createMigrate(p.connString)
+	m, err := createMigrate(p.connString, p.migrationsPath)
 	if err != nil {
 		return errors.Wrapf(err, op)
 	}
+	defer closeMigrate(m)
 
 	err = m.Drop()
 	if err != nil {
@@ -0,0 +1,89 @@
+package migration
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabaseMigrator(t *testing.T) {
+	latest := latestVersion(t, sqliteMigrationsPath)
+
+	t.Run("no migrations are applied", func(t *testing.T) {
+		sut := NewSQLiteMigrator(filepath.Join(t.TempDir(), "goph-keeper.db"))
+
+		version, dirty, err := sut.Version()
+
+		require.NoError(t, err)
+		assert.Zero(t, version)
+		assert.False(t, dirty)
+	})
+	t.Run("migrate up", func(t *testing.T) {
+		sut := NewSQLiteMigrator(filepath.Join(t.TempDir(), "goph-keeper.db"))
+
+		err := sut.Up()
+
+		require.NoError(t, err)
+		version, _, err := sut.Version()
+		require.NoError(t, err)
+		assert.Equal(t, latest, version)
+	})
+	t.Run("migrate down", func(t *testing.T) {
+		sut := NewSQLiteMigrator(filepath.Join(t.TempDir(), "goph-keeper.db"))
+		err := sut.Up()
+		require.NoError(t, err)
+
+		err = sut.Down(1)
+
+		require.NoError(t, err)
+		version, _, err := sut.Version()
+		require.NoError(t, err)
+		assert.Equal(t, latest-1, version)
+	})
+	t.Run("force version", func(t *testing.T) {
+		sut := NewSQLiteMigrator(filepath.Join(t.TempDir(), "goph-keeper.db"))
+		err := sut.Up()
+		require.NoError(t, err)
+
+		err = sut.Force(1)
+
+		require.NoError(t, err)
+		version, dirty, err := sut.Version()
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), version)
+		assert.False(t, dirty)
+	})
+	t.Run("migrate down more steps than applied", func(t *testing.T) {
+		sut := NewSQLiteMigrator(filepath.Join(t.TempDir(), "goph-keeper.db"))
+		err := sut.Up()
+		require.NoError(t, err)
+
+		err = sut.Down(int(latest) + 1)
+
+		require.Error(t, err)
+	})
+}
+
+// latestVersion returns the version of the last embedded migration, so the tests do not change with new migrations.
+func latestVersion(t *testing.T, dir string) uint {
+	t.Helper()
+
+	files, err := fs.Glob(migrationsDir, path.Join(dir, "*.up.sql"))
+	require.NoError(t, err)
+	var latest uint
+	for _, file := range files {
+		prefix, _, _ := strings.Cut(path.Base(file), "_")
+		version, err := strconv.ParseUint(prefix, 10, 0)
+		require.NoError(t, err)
+		latest = max(latest, uint(version))
+	}
+	require.NotZero(t, latest)
+
+	return latest
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS secrets;
+DROP TABLE IF EXISTS keys;
+DROP TABLE IF EXISTS users;
\ No newline at end of file
@@ -0,0 +1,24 @@
+CREATE TABLE users(
+    user_id      TEXT PRIMARY KEY,
+    email        TEXT UNIQUE     NOT NULL CONSTRAINT users_email_check CHECK ( email <> '' ),
+    password     TEXT            NOT NULL CONSTRAINT users_password_check CHECK ( length(password) <> 0 )
+);
+
+CREATE TABLE keys(
+    key_id                  TEXT PRIMARY KEY,
+    user_id                 TEXT                    REFERENCES users (user_id),
+    key_data                BLOB,
+    encriptions_count       INTEGER                 NOT NULL DEFAULT 0,
+    encrypted_data_size     INTEGER                 NOT NULL DEFAULT 0,
+    is_disposed             INTEGER                 NOT NULL DEFAULT 0
+);
+
+CREATE INDEX keys_user_id_idx ON keys (user_id);
+
+CREATE TABLE secrets(
+    secret_id       TEXT PRIMARY KEY,
+    user_id         TEXT                    NOT NULL REFERENCES users (user_id),
+    key_id          TEXT                    NOT NULL REFERENCES keys (key_id),
+    name            TEXT,
+    data            BLOB
+);
\ No newline at end of file
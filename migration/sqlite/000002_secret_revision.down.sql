@@ -0,0 +1 @@
+ALTER TABLE secrets DROP COLUMN revision;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE secrets ADD COLUMN revision INTEGER NOT NULL DEFAULT 1;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE secrets DROP COLUMN blob_checksum;
+ALTER TABLE secrets DROP COLUMN blob_id;
\ No newline at end of file
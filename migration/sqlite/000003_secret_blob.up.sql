@@ -0,0 +1,2 @@
+ALTER TABLE secrets ADD COLUMN blob_id TEXT;
+ALTER TABLE secrets ADD COLUMN blob_checksum BLOB;
\ No newline at end of file
@@ -0,0 +1,24 @@
+CREATE TABLE audit_events(
+    seq             INTEGER PRIMARY KEY,
+    user_id         TEXT,
+    action          TEXT            NOT NULL,
+    secret_id       TEXT,
+    created_at      INTEGER         NOT NULL,
+    client_ip       TEXT            NOT NULL DEFAULT '',
+    user_agent      TEXT            NOT NULL DEFAULT '',
+    outcome         TEXT            NOT NULL,
+    prev_hash       BLOB,
+    hash            BLOB            NOT NULL
+);
+
+CREATE INDEX audit_events_user_id_idx ON audit_events (user_id, seq);
+
+CREATE TRIGGER audit_events_no_update BEFORE UPDATE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
+
+CREATE TRIGGER audit_events_no_delete BEFORE DELETE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
\ No newline at end of file
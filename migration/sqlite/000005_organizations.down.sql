@@ -0,0 +1,37 @@
+DELETE FROM secrets WHERE user_id NOT IN (SELECT user_id FROM users);
+DELETE FROM keys WHERE user_id IS NOT NULL AND user_id NOT IN (SELECT user_id FROM users);
+
+CREATE TABLE keys_users(
+    key_id                  TEXT PRIMARY KEY,
+    user_id                 TEXT                    REFERENCES users (user_id),
+    key_data                BLOB,
+    encriptions_count       INTEGER                 NOT NULL DEFAULT 0,
+    encrypted_data_size     INTEGER                 NOT NULL DEFAULT 0,
+    is_disposed             INTEGER                 NOT NULL DEFAULT 0
+);
+
+INSERT INTO keys_users SELECT key_id, user_id, key_data, encriptions_count, encrypted_data_size, is_disposed FROM keys;
+
+CREATE TABLE secrets_users(
+    secret_id       TEXT PRIMARY KEY,
+    user_id         TEXT                    NOT NULL REFERENCES users (user_id),
+    key_id          TEXT                    NOT NULL REFERENCES keys (key_id),
+    name            TEXT,
+    data            BLOB,
+    revision        INTEGER                 NOT NULL DEFAULT 1,
+    blob_id         TEXT,
+    blob_checksum   BLOB
+);
+
+INSERT INTO secrets_users SELECT secret_id, user_id, key_id, name, data, revision, blob_id, blob_checksum FROM secrets;
+
+DROP TABLE secrets;
+DROP TABLE keys;
+ALTER TABLE keys_users RENAME TO keys;
+ALTER TABLE secrets_users RENAME TO secrets;
+
+CREATE INDEX keys_user_id_idx ON keys (user_id);
+
+DROP TABLE vaults;
+DROP TABLE org_members;
+DROP TABLE organizations;
\ No newline at end of file
@@ -0,0 +1,54 @@
+CREATE TABLE organizations(
+    org_id          TEXT PRIMARY KEY,
+    name            TEXT                    NOT NULL CHECK ( name <> '' )
+);
+
+CREATE TABLE org_members(
+    org_id          TEXT                    NOT NULL REFERENCES organizations (org_id) ON DELETE CASCADE,
+    user_id         TEXT                    NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    role            TEXT                    NOT NULL CHECK ( role IN ('owner', 'editor', 'viewer') ),
+    PRIMARY KEY (org_id, user_id)
+);
+
+CREATE INDEX org_members_user_id_idx ON org_members (user_id);
+
+CREATE TABLE vaults(
+    vault_id        TEXT PRIMARY KEY,
+    org_id          TEXT                    NOT NULL REFERENCES organizations (org_id),
+    name            TEXT                    NOT NULL CHECK ( name <> '' )
+);
+
+CREATE INDEX vaults_org_id_idx ON vaults (org_id);
+
+-- secrets and keys are owned either by a user or by a team vault,
+-- SQLite can not drop the constraints, so the tables are rebuilt
+CREATE TABLE keys_owned(
+    key_id                  TEXT PRIMARY KEY,
+    user_id                 TEXT,
+    key_data                BLOB,
+    encriptions_count       INTEGER                 NOT NULL DEFAULT 0,
+    encrypted_data_size     INTEGER                 NOT NULL DEFAULT 0,
+    is_disposed             INTEGER                 NOT NULL DEFAULT 0
+);
+
+INSERT INTO keys_owned SELECT key_id, user_id, key_data, encriptions_count, encrypted_data_size, is_disposed FROM keys;
+
+CREATE TABLE secrets_owned(
+    secret_id       TEXT PRIMARY KEY,
+    user_id         TEXT                    NOT NULL,
+    key_id          TEXT                    NOT NULL REFERENCES keys (key_id),
+    name            TEXT,
+    data            BLOB,
+    revision        INTEGER                 NOT NULL DEFAULT 1,
+    blob_id         TEXT,
+    blob_checksum   BLOB
+);
+
+INSERT INTO secrets_owned SELECT secret_id, user_id, key_id, name, data, revision, blob_id, blob_checksum FROM secrets;
+
+DROP TABLE secrets;
+DROP TABLE keys;
+ALTER TABLE keys_owned RENAME TO keys;
+ALTER TABLE secrets_owned RENAME TO secrets;
+
+CREATE INDEX keys_user_id_idx ON keys (user_id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS secret_changes;
\ No newline at end of file
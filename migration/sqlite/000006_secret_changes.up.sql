@@ -0,0 +1,14 @@
+CREATE TABLE secret_changes(
+    seq             INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id         TEXT                    NOT NULL,
+    secret_id       TEXT                    NOT NULL UNIQUE,
+    name            TEXT,
+    revision        INTEGER                 NOT NULL,
+    deleted         INTEGER                 NOT NULL DEFAULT 0
+);
+
+CREATE INDEX secret_changes_user_id_seq_idx ON secret_changes (user_id, seq);
+
+-- existing secrets are changes clients have not seen yet
+INSERT INTO secret_changes (user_id, secret_id, name, revision)
+SELECT user_id, secret_id, name, revision FROM secrets ORDER BY secret_id;
\ No newline at end of file
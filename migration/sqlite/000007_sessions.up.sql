@@ -0,0 +1,18 @@
+CREATE TABLE sessions(
+    session_id      TEXT PRIMARY KEY,
+    user_id         TEXT                    NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    created_at      INTEGER                 NOT NULL,
+    revoked         INTEGER                 NOT NULL DEFAULT 0
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions (user_id);
+
+-- only hashes of refresh tokens are stored, a used token is kept to detect its reuse
+CREATE TABLE refresh_tokens(
+    token_hash      BLOB PRIMARY KEY,
+    session_id      TEXT                    NOT NULL REFERENCES sessions (session_id) ON DELETE CASCADE,
+    expires_at      INTEGER                 NOT NULL,
+    used            INTEGER                 NOT NULL DEFAULT 0
+);
+
+CREATE INDEX refresh_tokens_session_id_idx ON refresh_tokens (session_id);
\ No newline at end of file
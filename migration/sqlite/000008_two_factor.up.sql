@@ -0,0 +1,13 @@
+-- the secret of the second factor is kept to check the codes of the authenticator app
+CREATE TABLE two_factor(
+    user_id         TEXT PRIMARY KEY        REFERENCES users (user_id) ON DELETE CASCADE,
+    secret          TEXT                    NOT NULL,
+    enabled         INTEGER                 NOT NULL DEFAULT 0
+);
+
+-- only hashes of recovery codes are stored, a used code is deleted
+CREATE TABLE recovery_codes(
+    user_id         TEXT                    NOT NULL REFERENCES two_factor (user_id) ON DELETE CASCADE,
+    code_hash       BLOB                    NOT NULL,
+    PRIMARY KEY (user_id, code_hash)
+);
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- failed logins of an account or a client address, the login is locked for a while after too many failures
+CREATE TABLE login_attempts(
+    attempt_key     TEXT PRIMARY KEY,
+    failures        INTEGER                 NOT NULL,
+    last_failure    INTEGER                 NOT NULL,
+    locked_until    INTEGER
+);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS access_tokens;
\ No newline at end of file
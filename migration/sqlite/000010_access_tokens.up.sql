@@ -0,0 +1,13 @@
+-- personal access tokens of automation, only hashes of the tokens are stored
+CREATE TABLE access_tokens(
+    token_id        TEXT PRIMARY KEY,
+    user_id         TEXT                    NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    name            TEXT                    NOT NULL CHECK ( name <> '' ),
+    token_hash      BLOB                    NOT NULL UNIQUE,
+    scope           TEXT                    NOT NULL,
+    vault_id        TEXT,
+    created_at      INTEGER                 NOT NULL,
+    expires_at      INTEGER
+);
+
+CREATE INDEX access_tokens_user_id_idx ON access_tokens (user_id);
\ No newline at end of file
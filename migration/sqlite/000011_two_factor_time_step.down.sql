@@ -0,0 +1 @@
+ALTER TABLE two_factor DROP COLUMN last_time_step;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- the time step of the last accepted code, the codes of this and earlier steps are not accepted again
+ALTER TABLE two_factor ADD COLUMN last_time_step INTEGER NOT NULL DEFAULT 0;
\ No newline at end of file